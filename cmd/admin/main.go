@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jwebster45206/story-engine/internal/handlers"
+)
+
+type AdminConfig struct {
+	APIBaseURL string
+	APIKey     string // Optional key selecting the server profile, sent as X-API-Key
+	AdminKey   string // Admin key matching the server's admin_key, sent as X-Admin-Key
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	cfg := &AdminConfig{
+		APIBaseURL: getEnv("API_BASE_URL", "http://localhost:8080"),
+		APIKey:     getEnv("API_KEY", ""),
+		AdminKey:   getEnv("ADMIN_KEY", ""),
+	}
+	if cfg.AdminKey == "" {
+		fmt.Fprintln(os.Stderr, "ADMIN_KEY environment variable is not set")
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "cleanup":
+		err = cleanup(cfg, os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "  cleanup   Delete ended or stale games")
+}
+
+// cleanup bulk deletes the games matching the flags, or counts them with -dry-run
+func cleanup(cfg *AdminConfig, args []string) error {
+	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	ended := fs.Bool("ended", false, "only games that have ended")
+	olderThan := fs.String("older-than", "", "only games not updated within this long, e.g. 30d or 12h")
+	dryRun := fs.Bool("dry-run", false, "count the matching games without deleting them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*ended && *olderThan == "" {
+		return fmt.Errorf("cleanup requires -ended and/or -older-than")
+	}
+
+	query := url.Values{}
+	if *ended {
+		query.Set("ended", "true")
+	}
+	if *olderThan != "" {
+		query.Set("older_than", *olderThan)
+	}
+	if *dryRun {
+		query.Set("dry_run", "true")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, cfg.APIBaseURL+"/v1/gamestate?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Admin-Key", cfg.AdminKey)
+	if cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", cfg.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	var result handlers.CleanupResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.DryRun {
+		fmt.Printf("Dry run: %s would be deleted\n", games(result.Matched))
+		return nil
+	}
+	fmt.Printf("Deleted %s", games(result.Deleted))
+	if result.Deleted < result.Matched {
+		fmt.Printf(" (%d failed; see the API logs)", result.Matched-result.Deleted)
+	}
+	fmt.Println()
+	return nil
+}
+
+func games(n int) string {
+	if n == 1 {
+		return "1 game"
+	}
+	return strconv.Itoa(n) + " games"
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
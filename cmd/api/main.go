@@ -6,16 +6,20 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
+	"github.com/jwebster45206/story-engine/internal/app"
 	"github.com/jwebster45206/story-engine/internal/config"
 	"github.com/jwebster45206/story-engine/internal/handlers"
 	"github.com/jwebster45206/story-engine/internal/logger"
 	"github.com/jwebster45206/story-engine/internal/middleware"
 	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/internal/services/analytics"
+	"github.com/jwebster45206/story-engine/internal/services/events"
+	"github.com/jwebster45206/story-engine/internal/services/privacy"
 	"github.com/jwebster45206/story-engine/internal/services/queue"
+	"github.com/jwebster45206/story-engine/internal/services/usage"
 	"github.com/jwebster45206/story-engine/internal/storage"
 )
 
@@ -33,31 +37,52 @@ func main() {
 		"llm_provider", cfg.LLMProvider,
 		"model_name", cfg.ModelName)
 
-	var llmService services.LLMService
-	switch strings.ToLower(cfg.LLMProvider) {
-	case "anthropic":
-		// Initialize Anthropic LLM service
-		if cfg.AnthropicAPIKey == "" {
-			log.Error("Anthropic API key is required when using anthropic provider")
+	llmService, err := services.NewLLMService(cfg, cfg.LLMProvider, cfg.ModelName, cfg.BackendModelName, log)
+	if err != nil {
+		log.Error("Failed to create LLM service", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Using LLM provider", "provider", cfg.LLMProvider)
+	if cfg.CaptureDir != "" {
+		log.Warn("Capturing every LLM request and response", "dir", cfg.CaptureDir)
+	}
+
+	storageService := storage.NewRedisStorage(cfg.RedisURL, "./data", logger.Module(log, logger.ModuleStorage)).
+		WithEventSourcing(cfg.EventSourcing).
+		WithScenariosInRedis(cfg.ScenarioStore == config.ScenarioStoreRedis)
+	var encryptor *storage.Encryptor
+	if cfg.Encryption.Enabled() {
+		keys, err := cfg.Encryption.DecodedKeys()
+		if err != nil {
+			log.Error("Invalid encryption keys", "error", err)
 			os.Exit(1)
 		}
-		llmService = services.NewAnthropicService(cfg.AnthropicAPIKey, cfg.ModelName, cfg.BackendModelName, log)
-		log.Info("Using Anthropic LLM provider")
-	case "venice":
-		// Initialize Venice LLM service
-		if cfg.VeniceAPIKey == "" {
-			log.Error("Venice API key is required when using venice provider")
+		encryptor, err = storage.NewEncryptor(keys, cfg.Encryption.ActiveKey)
+		if err != nil {
+			log.Error("Failed to set up encryption", "error", err)
 			os.Exit(1)
 		}
-		llmService = services.NewVeniceService(cfg.VeniceAPIKey, cfg.ModelName, cfg.BackendModelName)
-		log.Info("Using Venice LLM provider")
-	// case "ollama": // TODO: Support for Ollama self-hosted LLM
-	default:
-		log.Error("Invalid LLM provider specified", "provider", cfg.LLMProvider, "supported", []string{"anthropic", "venice"})
-		os.Exit(1)
+		storageService = storageService.WithEncryption(encryptor)
+		log.Info("Game state encryption enabled", "active_key", cfg.Encryption.ActiveKey)
+	}
+	if cfg.ContentIntegrity.Enabled() {
+		publicKey, err := cfg.ContentIntegrity.DecodedPublicKey()
+		if err != nil {
+			log.Error("Invalid content integrity key", "error", err)
+			os.Exit(1)
+		}
+		manifest, err := storage.LoadContentManifest(cfg.ContentIntegrity.Manifest, publicKey)
+		if err != nil {
+			log.Error("Failed to load content manifest", "error", err)
+			os.Exit(1)
+		}
+		// Files that fail now are refused when loaded; report them early
+		for _, problem := range manifest.CheckDir("./data") {
+			log.Error("Content file failed integrity check", "error", problem)
+		}
+		storageService = storageService.WithContentManifest(manifest)
+		log.Info("Content integrity verification enabled", "manifest", cfg.ContentIntegrity.Manifest, "files", len(manifest.Files), "signed", publicKey != nil)
 	}
-
-	storageService := storage.NewRedisStorage(cfg.RedisURL, "./data", log)
 	storageCtx, storageCancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer storageCancel()
 
@@ -67,8 +92,13 @@ func main() {
 	}
 	log.Info("Storage connection established successfully")
 
+	if err := app.PreflightScenarios(storageCtx, &storageService.FileStore, cfg.Preflight, log); err != nil {
+		log.Error("Scenario preflight failed", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize queue service for story events
-	queueClient, err := queue.NewClient(cfg.RedisURL, log)
+	queueClient, err := queue.NewClient(cfg.RedisURL, logger.Module(log, logger.ModuleQueue))
 	if err != nil {
 		log.Error("Failed to create queue client", "error", err)
 		os.Exit(1)
@@ -96,34 +126,64 @@ func main() {
 
 	mux := http.NewServeMux()
 
-	healthHandler := handlers.NewHealthHandler(log, storageService, llmService)
+	// The worker filters narration with the same configured word lists; config.Load has validated them
+	profanity, err := cfg.TextFilter.ProfanityFilter()
+	if err != nil {
+		log.Error("Failed to set up text filter", "error", err)
+		os.Exit(1)
+	}
+	healthHandler := handlers.NewHealthHandler(log, storageService, llmService).
+		WithCapabilities(handlers.Capabilities{
+			TextFilter: &handlers.TextFilterCapability{
+				Narration: true,
+				Leetspeak: profanity.Leetspeak(),
+				Locales:   profanity.Locales(),
+			},
+		})
 	mux.Handle("/health", healthHandler)
 
-	chatHandler := handlers.NewChatHandler(chatQueue, log)
-	mux.Handle("/v1/chat", chatHandler)
-
-	eventsHandler := handlers.NewEventsHandler(redisClient, log)
-	mux.Handle("/v1/events/gamestate/", eventsHandler)
-
-	gameStateHandler := handlers.NewGameStateHandler(log, cfg.ModelName, storageService)
-	mux.Handle("/v1/gamestate", gameStateHandler)
-	mux.Handle("/v1/gamestate/", gameStateHandler)
-
-	scenarioHandler := handlers.NewScenarioHandler(log, storageService)
-	mux.Handle("/v1/scenarios", scenarioHandler)
-	mux.Handle("/v1/scenarios/", scenarioHandler)
-
-	pcHandler := handlers.NewPCHandler(log, storageService)
-	mux.Handle("/v1/pcs", pcHandler)
-	mux.Handle("/v1/pcs/", pcHandler)
-
-	narratorHandler := handlers.NewNarratorHandler(log, storageService)
-	mux.Handle("/v1/narrators", narratorHandler)
-	mux.Handle("/v1/narrators/", narratorHandler)
-
-	monsterHandler := handlers.NewMonsterHandler(log, storageService)
-	mux.Handle("/v1/monsters", monsterHandler)
-	mux.Handle("/v1/monsters/", monsterHandler)
+	// Spectators of public games need no API key; the feed only carries delayed narration
+	spectatorHandler := handlers.NewSpectatorHandler(events.NewSpectatorFeed(redisClient, log), cfg.SpectatorLag(), logger.Module(log, logger.ModuleHandlers)).
+		WithHeartbeat(cfg.HeartbeatInterval())
+	mux.Handle("/v1/spectate/", spectatorHandler)
+
+	// Every other route is served per profile, selected by API key
+	modelRegistry := cfg.ModelRegistry()
+	ledger := usage.NewLedger(redisClient, modelRegistry, cfg.Budgets, log)
+	analyticsStore := analytics.NewStore(redisClient)
+	dataJobs := privacy.NewStore(redisClient, log).WithEncryption(encryptor)
+	router := middleware.NewProfileRouter(cfg.APIKeys, log)
+	deps := &app.Dependencies{
+		Config:        cfg,
+		Queue:         chatQueue,
+		RedisClient:   redisClient,
+		ModelRegistry: modelRegistry,
+		Ledger:        ledger,
+		Profanity:     profanity,
+		Logger:        log,
+	}
+	// Narrators and PCs are shared, so deleting one checks games in every profile
+	for _, name := range cfg.ProfileNames() {
+		profile, _ := cfg.Profile(name)
+		deps.GameStorages = append(deps.GameStorages, storageService.WithKeyPrefix(profile.StoragePrefix))
+	}
+	for _, name := range cfg.ProfileNames() {
+		profile, _ := cfg.Profile(name)
+		profileLLM := llmService
+		if name != "" {
+			profileLLM, err = services.NewLLMService(cfg, profile.LLMProvider, profile.ModelName, profile.BackendModelName, log)
+			if err != nil {
+				log.Error("Failed to create LLM service for profile", "profile", name, "error", err)
+				os.Exit(1)
+			}
+		}
+		profileStorage := storageService.WithKeyPrefix(profile.StoragePrefix)
+		profileAnalytics := analyticsStore.WithKeyPrefix(profile.StoragePrefix)
+		profileDataJobs := dataJobs.WithKeyPrefix(profile.StoragePrefix)
+		router.Handle(profile, deps.ProfileMux(profile, profileStorage, profileLLM, profileAnalytics, profileDataJobs))
+		log.Info("Profile configured", "profile", name, "provider", profile.LLMProvider, "model", profile.ModelName)
+	}
+	mux.Handle("/", router)
 
 	handler := middleware.Logger(mux)
 	server := &http.Server{
@@ -149,13 +209,8 @@ func main() {
 
 	log.Info("Server is shutting down...")
 
-	// Close storage connection
-	if err := storageService.Close(); err != nil {
-		log.Error("Error closing storage connection", "error", err)
-	}
-
-	// Graceful shutdown with timeout
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Graceful shutdown with timeout; storage stays open until requests in flight finish
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownDrain())
 	defer shutdownCancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
@@ -163,5 +218,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Close storage connection
+	if err := storageService.Close(); err != nil {
+		log.Error("Error closing storage connection", "error", err)
+	}
+
 	log.Info("Server exited")
 }
@@ -5,11 +5,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
@@ -56,6 +60,120 @@ func getGameState(client *http.Client, baseURL string, gameStateID uuid.UUID) (*
 	return &gameState, nil
 }
 
+// CodexEntry is a lore entry the player has discovered, as returned by the codex endpoint
+type CodexEntry struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// getCodex fetches the lore entries the player has discovered, in the order found
+func getCodex(client *http.Client, baseURL string, gameStateID uuid.UUID) ([]CodexEntry, error) {
+	resp, err := client.Get(fmt.Sprintf("%s/v1/gamestate/%s/codex", baseURL, gameStateID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close() // Ignore error in defer
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp ErrorResponse
+		if err := json.Unmarshal(body, &errorResp); err != nil {
+			return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		}
+		return nil, fmt.Errorf("failed to get codex: %s", errorResp.Error)
+	}
+
+	var codex struct {
+		Entries []CodexEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(body, &codex); err != nil {
+		return nil, fmt.Errorf("failed to parse codex response: %w", err)
+	}
+	return codex.Entries, nil
+}
+
+// saveSnapshot saves the game on the server as a named snapshot, replacing any with that name
+func saveSnapshot(client *http.Client, baseURL string, gameStateID uuid.UUID, name string) (*state.Snapshot, error) {
+	jsonData, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	var snap state.Snapshot
+	if err := postJSON(client, fmt.Sprintf("%s/v1/gamestate/%s/snapshots", baseURL, gameStateID), jsonData, http.StatusCreated, "save snapshot", &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// listSnapshots fetches the game's snapshots, oldest first
+func listSnapshots(client *http.Client, baseURL string, gameStateID uuid.UUID) ([]state.Snapshot, error) {
+	resp, err := client.Get(fmt.Sprintf("%s/v1/gamestate/%s/snapshots", baseURL, gameStateID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close() // Ignore error in defer
+	}()
+
+	var list struct {
+		Snapshots []state.Snapshot `json:"snapshots"`
+	}
+	if err := decodeResponse(resp, http.StatusOK, "list snapshots", &list); err != nil {
+		return nil, err
+	}
+	return list.Snapshots, nil
+}
+
+// restoreSnapshot rolls the game back to a named snapshot and returns the restored game
+func restoreSnapshot(client *http.Client, baseURL string, gameStateID uuid.UUID, name string) (*state.GameState, error) {
+	var gs state.GameState
+	if err := postJSON(client, fmt.Sprintf("%s/v1/gamestate/%s/restore/%s", baseURL, gameStateID, url.PathEscape(name)), nil, http.StatusOK, "restore snapshot", &gs); err != nil {
+		return nil, err
+	}
+	return &gs, nil
+}
+
+// postJSON posts a JSON body and decodes the response into out
+func postJSON(client *http.Client, endpoint string, body []byte, wantStatus int, action string, out any) error {
+	resp, err := client.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close() // Ignore error in defer
+	}()
+	return decodeResponse(resp, wantStatus, action, out)
+}
+
+// decodeResponse decodes a response with the wanted status into out, or returns the
+// API's error
+func decodeResponse(resp *http.Response, wantStatus int, action string, out any) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != wantStatus {
+		var errorResp ErrorResponse
+		if err := json.Unmarshal(body, &errorResp); err != nil {
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		}
+		return fmt.Errorf("failed to %s: %s", action, errorResp.Error)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
 // CreateGameStateRequest matches the API request structure
 type CreateGameStateRequest struct {
 	Scenario   string `json:"scenario"`
@@ -107,35 +225,55 @@ func createGameState(client *http.Client, baseURL string, scenarioFile string, p
 	return &createdGameState, nil
 }
 
-func listScenarios(client *http.Client, baseURL string) ([]string, map[string]string, error) {
-	resp, err := client.Get(baseURL + "/v1/scenarios")
-	if err != nil {
-		return nil, nil, err
-	}
-	defer func() {
-		_ = resp.Body.Close() // Ignore error in defer
-	}()
+// ScenarioListEntry matches one entry of the API's scenario listing
+type ScenarioListEntry struct {
+	scenario.ScenarioSummary
+	Compatible bool `json:"compatible"` // Whether the server's model can run this scenario's rating
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
+// ScenarioListResponse matches a page of the API's scenario listing
+type ScenarioListResponse struct {
+	Scenarios []ScenarioListEntry `json:"scenarios"`
+	Total     int                 `json:"total"`
+	Page      int                 `json:"page"`
+	PageSize  int                 `json:"page_size"`
+}
 
-	var scenarioMap map[string]string
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, nil, err
-	}
+// listScenarios fetches every page of the scenario listing.
+// Returns display names in server order, a name to filename map, and the entries by name.
+func listScenarios(client *http.Client, baseURL string) ([]string, map[string]string, map[string]ScenarioListEntry, error) {
+	var names []string
+	scenarioMap := make(map[string]string)
+	entries := make(map[string]ScenarioListEntry)
 
-	if err := json.Unmarshal(body, &scenarioMap); err != nil {
-		return nil, nil, err
-	}
+	for page := 1; ; page++ {
+		resp, err := client.Get(fmt.Sprintf("%s/v1/scenarios?page=%d&page_size=100", baseURL, page))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, nil, nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		}
 
-	var names []string
-	for name := range scenarioMap {
-		names = append(names, name)
+		var list ScenarioListResponse
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, nil, nil, err
+		}
+		for _, e := range list.Scenarios {
+			names = append(names, e.Name)
+			scenarioMap[e.Name] = e.FileName
+			entries[e.Name] = e
+		}
+		if len(list.Scenarios) == 0 || len(names) >= list.Total {
+			break
+		}
 	}
-	sort.Strings(names)
-	return names, scenarioMap, nil
+	return names, scenarioMap, entries, nil
 }
 
 func getScenario(client *http.Client, baseURL string, scenarioFile string) (*scenario.Scenario, error) {
@@ -267,27 +405,132 @@ func sendChatAsync(client *http.Client, baseURL string, gameStateID uuid.UUID, m
 	return chatResp.RequestID, nil
 }
 
+// continueNarration asks the server to narrate more of the last narration and returns
+// the request ID. Progress arrives on the event stream like a chat's.
+func continueNarration(client *http.Client, baseURL string, gameStateID uuid.UUID) (string, error) {
+	resp, err := client.Post(fmt.Sprintf("%s/v1/gamestate/%s/continue", baseURL, gameStateID), "application/json", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusAccepted {
+		var errorResp ErrorResponse
+		if err := json.Unmarshal(body, &errorResp); err != nil {
+			return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		}
+		return "", fmt.Errorf("failed to continue: %s", errorResp.Error)
+	}
+
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return chatResp.RequestID, nil
+}
+
 // SSEEvent represents an event from the SSE stream
 type SSEEvent struct {
 	Type string                 `json:"type"`
 	Data map[string]interface{} `json:"data"`
 }
 
-// listenToSSE connects to the SSE endpoint and streams events to a channel
-func listenToSSE(ctx context.Context, client *http.Client, baseURL string, gameStateID uuid.UUID, eventChan chan<- SSEEvent) error {
+const (
+	// sseMaxRetries is how many reconnections in a row followSSE makes before giving up
+	sseMaxRetries = 5
+	// sseRetryDelay is the wait before reconnecting when the server gives no Retry-After
+	sseRetryDelay = 2 * time.Second
+	// sseMissedHeartbeats is how many heartbeat intervals may pass without a byte from
+	// the server before the stream is treated as dropped
+	sseMissedHeartbeats = 3
+	// sseDefaultIdleTimeout applies until the server says how often it sends heartbeats
+	sseDefaultIdleTimeout = 90 * time.Second
+)
+
+// sseStatusError is returned by listenToSSE when the server refuses the connection.
+// retryAfter is set when it is only refused for now.
+type sseStatusError struct {
+	status     int
+	body       string
+	retryAfter time.Duration
+}
+
+func (e *sseStatusError) Error() string {
+	return fmt.Sprintf("SSE connection failed with status %d: %s", e.status, e.body)
+}
+
+// followSSE streams events to a channel, reconnecting when the connection drops. Each
+// reconnection sends the last event ID received, so the server replays the narration
+// chunks missed in between.
+func followSSE(ctx context.Context, client *http.Client, baseURL string, gameStateID uuid.UUID, eventChan chan<- SSEEvent) error {
+	lastEventID := ""
+	failures := 0
+	for {
+		received, err := listenToSSE(ctx, client, baseURL, gameStateID, &lastEventID, eventChan)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			failures = 0
+		}
+		failures++
+		if failures > sseMaxRetries {
+			return err
+		}
+
+		delay := sseRetryDelay
+		var statusErr *sseStatusError
+		if errors.As(err, &statusErr) {
+			if statusErr.retryAfter > 0 {
+				delay = statusErr.retryAfter
+			} else if statusErr.status < http.StatusInternalServerError {
+				// Refused for good, e.g. a bad game ID
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// listenToSSE connects to the SSE endpoint and streams events to a channel. It resumes
+// after *lastEventID when set, and keeps it up to date. received reports whether any
+// event arrived before the connection ended. A stream that misses several heartbeats
+// is closed, since a proxy may have dropped it without telling either end.
+func listenToSSE(ctx context.Context, client *http.Client, baseURL string, gameStateID uuid.UUID, lastEventID *string, eventChan chan<- SSEEvent) (received bool, err error) {
 	url := fmt.Sprintf("%s/v1/events/gamestate/%s", baseURL, gameStateID.String())
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	idleTimeout := sseDefaultIdleTimeout
+	idle := time.AfterFunc(idleTimeout, cancel)
+	defer idle.Stop()
+
+	req, err := http.NewRequestWithContext(connCtx, "GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
+	if *lastEventID != "" {
+		req.Header.Set("Last-Event-ID", *lastEventID)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SSE: %w", err)
+		return false, fmt.Errorf("failed to connect to SSE: %w", err)
 	}
 	defer func() {
 		_ = resp.Body.Close()
@@ -295,32 +538,54 @@ func listenToSSE(ctx context.Context, client *http.Client, baseURL string, gameS
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("SSE connection failed with status %d: %s", resp.StatusCode, string(body))
+		statusErr := &sseStatusError{status: resp.StatusCode, body: string(body)}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			statusErr.retryAfter = sseRetryDelay
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				statusErr.retryAfter = time.Duration(seconds) * time.Second
+			}
+		}
+		return false, statusErr
 	}
 
 	scanner := bufio.NewScanner(resp.Body)
 	var currentEvent SSEEvent
+	currentID := ""
 
 	for scanner.Scan() {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return received, ctx.Err()
 		default:
 		}
 
 		line := scanner.Text()
+		idle.Reset(idleTimeout)
 
 		if line == "" {
 			// Empty line signals end of event
+			if currentEvent.Type == "connected" {
+				if seconds, ok := currentEvent.Data["heartbeat_seconds"].(float64); ok && seconds > 0 {
+					idleTimeout = sseMissedHeartbeats * time.Duration(seconds) * time.Second
+					idle.Reset(idleTimeout)
+				}
+			}
 			if currentEvent.Type != "" {
 				eventChan <- currentEvent
+				received = true
+				if currentID != "" {
+					*lastEventID = currentID
+				}
 				currentEvent = SSEEvent{}
+				currentID = ""
 			}
 			continue
 		}
 
 		// Parse SSE format
-		if strings.HasPrefix(line, "event: ") {
+		if strings.HasPrefix(line, "id: ") {
+			currentID = strings.TrimPrefix(line, "id: ")
+		} else if strings.HasPrefix(line, "event: ") {
 			currentEvent.Type = strings.TrimPrefix(line, "event: ")
 		} else if strings.HasPrefix(line, "data: ") {
 			dataJSON := strings.TrimPrefix(line, "data: ")
@@ -331,9 +596,12 @@ func listenToSSE(ctx context.Context, client *http.Client, baseURL string, gameS
 		}
 	}
 
+	if ctx.Err() == nil && connCtx.Err() != nil {
+		return received, fmt.Errorf("SSE stream silent for %s", idleTimeout)
+	}
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading SSE stream: %w", err)
+		return received, fmt.Errorf("error reading SSE stream: %w", err)
 	}
 
-	return nil
+	return received, nil
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds the console's remappable key bindings.
+// Key names use bubbletea's format, e.g. "ctrl+n", "alt+pgup", "f5".
+type KeyMap struct {
+	Quit       key.Binding
+	NewGame    key.Binding
+	CopyID     key.Binding
+	ClearInput key.Binding
+	Export     key.Binding
+	Save       key.Binding
+	Rerender   key.Binding
+	Send       key.Binding
+
+	ChatUp       key.Binding
+	ChatDown     key.Binding
+	ChatPageUp   key.Binding
+	ChatPageDown key.Binding
+	ChatTop      key.Binding
+	ChatBottom   key.Binding
+
+	SidebarPageUp   key.Binding
+	SidebarPageDown key.Binding
+}
+
+// DefaultKeyMap returns the built-in key bindings
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Quit:       key.NewBinding(key.WithKeys("ctrl+c", "esc"), key.WithHelp("ctrl+c", "Quit")),
+		NewGame:    key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "New Game")),
+		CopyID:     key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "Copy Game ID")),
+		ClearInput: key.NewBinding(key.WithKeys("ctrl+z"), key.WithHelp("ctrl+z", "Clear Input")),
+		Export:     key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "Export Chat")),
+		Save:       key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "Save State")),
+		Rerender:   key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "Re-render")),
+		Send:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "Send")),
+
+		ChatUp:       key.NewBinding(key.WithKeys("up"), key.WithHelp("up", "Scroll Chat Up")),
+		ChatDown:     key.NewBinding(key.WithKeys("down"), key.WithHelp("down", "Scroll Chat Down")),
+		ChatPageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "Chat Page Up")),
+		ChatPageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "Chat Page Down")),
+		ChatTop:      key.NewBinding(key.WithKeys("home"), key.WithHelp("home", "Chat Top")),
+		ChatBottom:   key.NewBinding(key.WithKeys("end"), key.WithHelp("end", "Chat Bottom")),
+
+		SidebarPageUp:   key.NewBinding(key.WithKeys("alt+pgup", "ctrl+up"), key.WithHelp("alt+pgup", "Sidebar Page Up")),
+		SidebarPageDown: key.NewBinding(key.WithKeys("alt+pgdown", "ctrl+down"), key.WithHelp("alt+pgdown", "Sidebar Page Down")),
+	}
+}
+
+// namedBindings pairs each binding with the action name used in key config files,
+// in the order they are listed by /keys.
+func (k *KeyMap) namedBindings() []struct {
+	name    string
+	binding *key.Binding
+} {
+	return []struct {
+		name    string
+		binding *key.Binding
+	}{
+		{"quit", &k.Quit},
+		{"new_game", &k.NewGame},
+		{"copy_id", &k.CopyID},
+		{"clear_input", &k.ClearInput},
+		{"export", &k.Export},
+		{"save", &k.Save},
+		{"rerender", &k.Rerender},
+		{"send", &k.Send},
+		{"chat_up", &k.ChatUp},
+		{"chat_down", &k.ChatDown},
+		{"chat_page_up", &k.ChatPageUp},
+		{"chat_page_down", &k.ChatPageDown},
+		{"chat_top", &k.ChatTop},
+		{"chat_bottom", &k.ChatBottom},
+		{"sidebar_page_up", &k.SidebarPageUp},
+		{"sidebar_page_down", &k.SidebarPageDown},
+	}
+}
+
+// loadKeyMap returns the default bindings with any overrides from a JSON key config file.
+// The file maps action names to lists of keys, e.g. {"new_game": ["ctrl+g"]}.
+// Actions not listed keep their defaults.
+func loadKeyMap(path string) (KeyMap, error) {
+	km := DefaultKeyMap()
+	if path == "" {
+		return km, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return km, fmt.Errorf("failed to read key config %s: %w", path, err)
+	}
+	var overrides map[string][]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return km, fmt.Errorf("failed to parse key config %s: %w", path, err)
+	}
+
+	bindings := km.namedBindings()
+	for action, keys := range overrides {
+		found := false
+		for _, nb := range bindings {
+			if nb.name != action {
+				continue
+			}
+			found = true
+			if len(keys) == 0 {
+				return km, fmt.Errorf("key config %s: action %q has no keys", path, action)
+			}
+			nb.binding.SetKeys(keys...)
+			nb.binding.SetHelp(keys[0], nb.binding.Help().Desc)
+		}
+		if !found {
+			return km, fmt.Errorf("key config %s: unknown action %q", path, action)
+		}
+	}
+	return km, nil
+}
+
+// formatKeyName renders a bubbletea key name for display, e.g. "ctrl+n" as "Ctrl+N"
+func formatKeyName(name string) string {
+	parts := strings.Split(name, "+")
+	for i, p := range parts {
+		if len(p) == 1 {
+			parts[i] = strings.ToUpper(p)
+		} else if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// keyHelp lists every binding as "Keys: Action" lines for the /keys view
+func (k *KeyMap) keyHelp() []string {
+	var lines []string
+	for _, nb := range k.namedBindings() {
+		names := make([]string, 0, len(nb.binding.Keys()))
+		for _, name := range nb.binding.Keys() {
+			names = append(names, formatKeyName(name))
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s (%s)", strings.Join(names, ", "), nb.binding.Help().Desc, nb.name))
+	}
+	return lines
+}
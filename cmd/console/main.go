@@ -1,9 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,7 +13,19 @@ import (
 
 type ConsoleConfig struct {
 	APIBaseURL string
+	APIKey     string // Optional key selecting the server profile, sent as X-API-Key
 	Timeout    time.Duration
+	Theme      string // Preset name (dark, light, high-contrast) or path to a JSON theme file
+	KeysFile   string // Optional JSON file remapping key bindings
+	Keys       KeyMap
+
+	// Offline mode: run an in-process engine with a canned narrator instead of the API
+	Offline bool
+	DataDir string // scenarios and PCs for offline mode
+
+	// Accessibility mode: line-oriented output with no color or animation
+	Plain                bool
+	ScreenReaderPrefixes bool // prefix each line with its role, e.g. "Narrator: "
 }
 
 type ErrorResponse struct {
@@ -21,22 +35,73 @@ type ErrorResponse struct {
 func main() {
 	cfg := &ConsoleConfig{
 		APIBaseURL: getEnv("API_BASE_URL", "http://localhost:8080"),
-		Timeout:    0, // No timeout - SSE connections are long-lived, server has 30s keepalive
+		APIKey:     getEnv("API_KEY", ""),
+		Timeout:    0, // No timeout - SSE connections are long-lived and watched for missed heartbeats instead
+	}
+	flag.BoolVar(&cfg.Plain, "plain", getEnvBool("CONSOLE_PLAIN", os.Getenv("TERM") == "dumb"),
+		"plain line-oriented output for screen readers, dumb terminals and logs (env CONSOLE_PLAIN)")
+	flag.BoolVar(&cfg.ScreenReaderPrefixes, "sr-prefixes", getEnvBool("CONSOLE_SR_PREFIXES", false),
+		"in plain mode, prefix each line with its speaker or role (env CONSOLE_SR_PREFIXES)")
+	flag.StringVar(&cfg.Theme, "theme", getEnv("CONSOLE_THEME", defaultThemeName),
+		"color theme: dark, light, high-contrast, or path to a JSON theme file (env CONSOLE_THEME)")
+	flag.StringVar(&cfg.KeysFile, "keys", getEnv("CONSOLE_KEYS", ""),
+		"path to a JSON file remapping key bindings (env CONSOLE_KEYS)")
+	flag.BoolVar(&cfg.Offline, "offline", getEnvBool("CONSOLE_OFFLINE", false),
+		"run an in-process engine with a canned narrator; no API, Redis or LLM keys needed (env CONSOLE_OFFLINE)")
+	flag.StringVar(&cfg.DataDir, "data", getEnv("CONSOLE_DATA_DIR", "./data"),
+		"in offline mode, directory holding scenarios and PCs (env CONSOLE_DATA_DIR)")
+	flag.Parse()
+
+	theme, err := loadTheme(cfg.Theme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading theme: %v\n", err)
+		os.Exit(1)
+	}
+	applyTheme(theme)
+
+	cfg.Keys, err = loadKeyMap(cfg.KeysFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading key bindings: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Offline games are served by the in-process engine, which ignores API keys
+	if cfg.Offline {
+		engine, baseURL, err := startOffline(cfg.DataDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting offline engine: %v\n", err)
+			os.Exit(1)
+		}
+		defer engine.Close()
+		cfg.APIBaseURL = baseURL
+		cfg.APIKey = ""
 	}
 
 	client := &http.Client{
 		Timeout: cfg.Timeout,
 	}
+	if cfg.APIKey != "" {
+		client.Transport = &apiKeyTransport{key: cfg.APIKey, next: http.DefaultTransport}
+	}
 
 	if !testConnection(client, cfg.APIBaseURL) {
 		fmt.Fprintf(os.Stderr, "Could not connect to API. Please ensure the API is running.\nTry: docker-compose up -d\n")
 		os.Exit(1)
 	}
 
+	if cfg.Plain {
+		if err := runPlain(cfg, client, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	p := tea.NewProgram(NewConsoleUI(cfg, client),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
-		tea.WithMouseAllMotion())
+		tea.WithMouseAllMotion(),
+		tea.WithReportFocus())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
@@ -49,3 +114,24 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// apiKeyTransport adds the API key header to every request
+type apiKeyTransport struct {
+	key  string
+	next http.RoundTripper
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-API-Key", t.key)
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/handlers"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/internal/services/queue"
+	"github.com/jwebster45206/story-engine/internal/storage"
+	"github.com/jwebster45206/story-engine/internal/worker"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// offlineModel is the model name games are created with in offline mode
+const offlineModel = "offline"
+
+// offlineEngine is an API and worker running in-process against in-memory Redis,
+// with a canned LLM, so the console can run without Docker, Redis or API keys
+type offlineEngine struct {
+	redis    *miniredis.Miniredis
+	queue    *queue.Client
+	storage  *storage.RedisStorage
+	worker   *worker.Worker
+	server   *http.Server
+	listener net.Listener
+}
+
+// startOffline starts an offline engine serving scenarios and PCs from dataDir,
+// and returns it with the base URL to point the console at
+func startOffline(dataDir string) (*offlineEngine, string, error) {
+	// Engine logs would draw over the UI, so they're dropped
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	slog.SetDefault(log)
+
+	e := &offlineEngine{}
+	var err error
+	e.redis, err = miniredis.Run()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start in-memory redis: %w", err)
+	}
+
+	e.queue, err = queue.NewClient(e.redis.Addr(), log)
+	if err != nil {
+		e.Close()
+		return nil, "", fmt.Errorf("failed to create queue client: %w", err)
+	}
+	chatQueue := queue.NewChatQueue(e.queue)
+	redisClient := e.queue.GetRedisClient()
+
+	e.storage = storage.NewRedisStorage(e.redis.Addr(), dataDir, log)
+	llm := offlineLLM{}
+	models := config.NewModelRegistry(nil)
+	profile := config.Profile{LLMProvider: offlineModel, ModelName: offlineModel}
+
+	processor := worker.NewChatProcessor(e.storage, llm, chatQueue, log, 0).
+		WithModelRegistry(models).
+		WithBackendModel(offlineModel)
+	e.worker = worker.New(chatQueue, processor, redisClient, log, "offline")
+	go func() {
+		if err := e.worker.Start(); err != nil {
+			log.Error("Offline worker error", "error", err)
+		}
+	}()
+
+	mux := http.NewServeMux()
+	chatHandler := handlers.NewChatHandler(chatQueue, log).
+		WithProfile(profile).
+		WithStorage(e.storage)
+	mux.Handle("/v1/chat", chatHandler)
+	mux.Handle("/v1/events/gamestate/", handlers.NewEventsHandler(redisClient, log))
+	mux.Handle("/v1/ws", handlers.NewWebSocketHandler(chatHandler, redisClient, log))
+	gameStateHandler := handlers.NewGameStateHandler(log, offlineModel, e.storage).
+		WithLLMService(llm).
+		WithModelRegistry(models).
+		WithProfile(profile).
+		WithQueue(chatQueue)
+	mux.Handle("/v1/gamestate", gameStateHandler)
+	mux.Handle("/v1/gamestate/", gameStateHandler)
+	scenarioHandler := handlers.NewScenarioHandler(log, e.storage).
+		WithModelRegistry(models).
+		WithProfile(profile)
+	mux.Handle("/v1/scenarios", scenarioHandler)
+	mux.Handle("/v1/scenarios/", scenarioHandler)
+	pcHandler := handlers.NewPCHandler(log, e.storage)
+	mux.Handle("/v1/pcs", pcHandler)
+	mux.Handle("/v1/pcs/", pcHandler)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	e.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		e.Close()
+		return nil, "", fmt.Errorf("failed to listen: %w", err)
+	}
+	e.server = &http.Server{Handler: middleware.Logger(mux)}
+	go func() {
+		if err := e.server.Serve(e.listener); err != nil && err != http.ErrServerClosed {
+			log.Error("Offline server error", "error", err)
+		}
+	}()
+
+	return e, "http://" + e.listener.Addr().String(), nil
+}
+
+// Close stops the engine. Games played offline are lost.
+func (e *offlineEngine) Close() {
+	if e.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = e.server.Shutdown(ctx)
+	}
+	if e.worker != nil {
+		e.worker.Stop()
+	}
+	if e.storage != nil {
+		_ = e.storage.Close()
+	}
+	if e.queue != nil {
+		_ = e.queue.Close()
+	}
+	if e.redis != nil {
+		e.redis.Close()
+	}
+}
+
+// offlineLLM narrates from a fixed set of lines. It never changes game state, so
+// scenarios only advance through commands and conditionals.
+type offlineLLM struct{}
+
+func (offlineLLM) InitModel(ctx context.Context, modelName string) error {
+	return nil
+}
+
+func (offlineLLM) Chat(ctx context.Context, messages []chat.ChatMessage, temperature float64) (*chat.ChatResponse, error) {
+	return &chat.ChatResponse{Message: offlineNarration(messages)}, nil
+}
+
+// ChatStream streams the narration a word at a time, so the UI's streaming can be seen
+func (offlineLLM) ChatStream(ctx context.Context, messages []chat.ChatMessage, temperature float64) (<-chan services.StreamChunk, error) {
+	words := strings.SplitAfter(offlineNarration(messages), " ")
+	ch := make(chan services.StreamChunk, len(words)+1)
+	go func() {
+		defer close(ch)
+		for _, word := range words {
+			select {
+			case <-ctx.Done():
+				ch <- services.StreamChunk{Error: ctx.Err(), Done: true}
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+			ch <- services.StreamChunk{Content: word}
+		}
+		ch <- services.StreamChunk{Done: true}
+	}()
+	return ch, nil
+}
+
+func (offlineLLM) DeltaUpdate(ctx context.Context, messages []chat.ChatMessage) (*conditionals.GameStateDelta, string, error) {
+	return &conditionals.GameStateDelta{}, offlineModel, nil
+}
+
+func (offlineLLM) SuggestChoices(ctx context.Context, messages []chat.ChatMessage) ([]string, error) {
+	return nil, nil
+}
+
+func (offlineLLM) CheckNarration(ctx context.Context, messages []chat.ChatMessage) ([]state.NarrationIssue, error) {
+	return nil, nil
+}
+
+// offlineNarrations are the canned narrations, used in turn
+var offlineNarrations = []string{
+	"The world holds still around you. In offline mode the narrator is silent, but commands and game state work as they do online.",
+	"Time passes. Nothing answers but the engine itself.",
+	"You wait, and the scene waits with you.",
+	"The moment stretches on, quiet and unchanged.",
+}
+
+// offlineNarration picks a narration by the number of player messages, so the same
+// game always reads the same way
+func offlineNarration(messages []chat.ChatMessage) string {
+	turns := 0
+	for _, m := range messages {
+		if m.Role == chat.ChatRoleUser {
+			turns++
+		}
+	}
+	return offlineNarrations[turns%len(offlineNarrations)]
+}
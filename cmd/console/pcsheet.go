@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// pcSheetLines formats the game's PC as a plain-text character sheet for the /pc command.
+// The first line is the character's name. Inventory comes from the game, since the
+// PC spec only holds the starting gear.
+func pcSheetLines(gs *state.GameState) []string {
+	if gs == nil || gs.PC == nil {
+		return []string{"No character in this game."}
+	}
+	profile := gs.PC.Profile()
+
+	name := profile.Name
+	if profile.Pronouns != "" {
+		name += " (" + profile.Pronouns + ")"
+	}
+	lines := []string{name}
+	if profile.Summary != "" {
+		lines = append(lines, profile.Summary)
+	}
+	lines = append(lines, fmt.Sprintf("HP %d/%d  AC %d", profile.HP, profile.MaxHP, profile.AC))
+
+	abilities := make([]string, 0, len(profile.Abilities))
+	for _, a := range profile.Abilities {
+		abilities = append(abilities, fmt.Sprintf("%s %d (%s)", a.Abbrev, a.Score, actor.FormatModifier(a.Modifier)))
+	}
+	lines = append(lines, strings.Join(abilities, "  "))
+
+	if len(profile.Skills) > 0 {
+		lines = append(lines, "Skills: "+joinNamedValues(profile.Skills))
+	}
+	if len(profile.Modifiers) > 0 {
+		lines = append(lines, "Combat modifiers: "+joinNamedValues(profile.Modifiers))
+	}
+	if len(gs.Inventory) == 0 {
+		lines = append(lines, "Inventory: none")
+	} else {
+		lines = append(lines, "Inventory: "+strings.Join(gs.DisplayItems(gs.Inventory), ", "))
+	}
+
+	for _, field := range []struct{ label, text string }{
+		{"Description", profile.Description},
+		{"Appearance", profile.Appearance},
+		{"Personality", profile.Personality},
+		{"Motivation", profile.Motivation},
+		{"Background", profile.Background},
+	} {
+		if field.text != "" {
+			lines = append(lines, field.label+": "+field.text)
+		}
+	}
+	if profile.Portrait != "" {
+		lines = append(lines, "Portrait: "+profile.Portrait)
+	}
+	return lines
+}
+
+func joinNamedValues(values []actor.NamedValue) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, v.Name+" "+actor.FormatModifier(v.Value))
+	}
+	return strings.Join(parts, ", ")
+}
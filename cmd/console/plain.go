@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
+)
+
+// plainConsole is a line-oriented client for screen readers, dumb terminals and CI logs.
+// It uses no colors, cursor movement or animation: every message is printed once as
+// complete lines, and input is read one line at a time.
+type plainConsole struct {
+	config  *ConsoleConfig
+	client  *http.Client
+	in      *bufio.Scanner
+	out     io.Writer
+	filter  *textfilter.ProfanityFilter
+	rating  string
+	choices []string
+}
+
+// runPlain runs the console in accessibility (plain output) mode until the user quits
+// or input is exhausted.
+func runPlain(cfg *ConsoleConfig, client *http.Client, in io.Reader, out io.Writer) error {
+	p := &plainConsole{
+		config: cfg,
+		client: client,
+		in:     bufio.NewScanner(in),
+		out:    out,
+		filter: textfilter.NewProfanityFilter(),
+	}
+
+	scenarioFile, err := p.selectScenario()
+	if err != nil {
+		return err
+	}
+	if scenarioFile == "" {
+		return nil
+	}
+
+	defaultPC := ""
+	if s, err := getScenario(client, cfg.APIBaseURL, scenarioFile); err == nil {
+		defaultPC = s.DefaultPC
+		p.rating = s.Rating
+	}
+
+	pcID, err := p.selectPC(defaultPC)
+	if err != nil {
+		return err
+	}
+
+	gs, err := createGameState(client, cfg.APIBaseURL, scenarioFile, pcID)
+	if err != nil {
+		return err
+	}
+	for _, msg := range gs.ChatHistory {
+		if msg.Role == chat.ChatRoleAgent {
+			p.println(p.prefix("Narrator") + msg.Content)
+		}
+	}
+	p.println("Type /help for commands.")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan SSEEvent, 10)
+	go func() {
+		_ = followSSE(ctx, client, cfg.APIBaseURL, gs.ID, events)
+		close(events)
+	}()
+
+	for {
+		line, ok := p.prompt("> ")
+		if !ok {
+			return nil
+		}
+		if line == "" {
+			continue
+		}
+		line = p.filter.FilterText(line, p.rating)
+
+		var err error
+		if strings.EqualFold(line, "/continue") {
+			// The narrator picks up where it left off; only the new text is printed
+			p.choices = nil
+			_, err = continueNarration(client, cfg.APIBaseURL, gs.ID)
+		} else {
+			if strings.HasPrefix(line, "/") && !isServerCommand(line) {
+				if quit := p.handleCommand(line, gs); quit {
+					return nil
+				}
+				continue
+			}
+
+			if choice, ok := selectChoice(line, p.choices); ok {
+				line = choice
+				p.println(p.prefix("You") + line)
+			}
+			p.choices = nil
+			_, err = sendChatAsync(client, cfg.APIBaseURL, gs.ID, line)
+		}
+		if err != nil {
+			p.println(p.prefix("Error") + err.Error())
+			continue
+		}
+		if err := p.awaitResponse(events); err != nil {
+			return err
+		}
+
+		if latest, err := getGameState(client, cfg.APIBaseURL, gs.ID); err == nil {
+			gs = latest
+		}
+		if gs.IsEnded {
+			p.println(p.prefix("Status") + "The game has ended.")
+			return nil
+		}
+	}
+}
+
+// awaitResponse consumes SSE events until the current request completes or fails,
+// then prints the full narrator response and any suggested actions.
+func (p *plainConsole) awaitResponse(events <-chan SSEEvent) error {
+	var response strings.Builder
+	for event := range events {
+		switch event.Type {
+		case "chat.chunk":
+			if content, ok := event.Data["content"].(string); ok {
+				response.WriteString(content)
+			}
+		case "request.completed":
+			segments := parseSegments(event.Data)
+			if segments == nil {
+				segments = chat.ParseSegments(response.String(), nil)
+			}
+			for _, seg := range segments {
+				if seg.Kind == chat.SegmentDialogue {
+					p.println(seg.Speaker + ": " + seg.Text)
+				} else {
+					p.println(p.prefix("Narrator") + seg.Text)
+				}
+			}
+			if parseIncomplete(event.Data) {
+				p.println(p.prefix("Status") + incompleteNotice)
+			}
+			p.choices = parseChoices(event.Data)
+			for i, c := range p.choices {
+				p.println(fmt.Sprintf("%s%d. %s", p.prefix("Choice"), i+1, c))
+			}
+			return nil
+		case "request.failed":
+			errorMsg := "Request failed"
+			if errStr, ok := event.Data["error"].(string); ok {
+				errorMsg = errStr
+			}
+			p.println(p.prefix("Error") + errorMsg)
+			return nil
+		}
+	}
+	return fmt.Errorf("event stream closed")
+}
+
+// handleCommand runs a slash command and reports whether the user asked to quit
+func (p *plainConsole) handleCommand(cmd string, gs *state.GameState) bool {
+	command, arg, _ := strings.Cut(strings.TrimSpace(cmd), " ")
+	arg = strings.TrimSpace(arg)
+	switch command = strings.ToLower(command); command {
+	case "/quit", "/exit":
+		return true
+	case "/status":
+		latest, err := getGameState(p.client, p.config.APIBaseURL, gs.ID)
+		if err != nil {
+			p.println(p.prefix("Error") + err.Error())
+			return false
+		}
+		p.printStatus(latest)
+	case "/pc":
+		latest, err := getGameState(p.client, p.config.APIBaseURL, gs.ID)
+		if err != nil {
+			p.println(p.prefix("Error") + err.Error())
+			return false
+		}
+		for _, line := range pcSheetLines(latest) {
+			p.println(p.prefix("Character") + line)
+		}
+	case "/codex":
+		entries, err := getCodex(p.client, p.config.APIBaseURL, gs.ID)
+		if err != nil {
+			p.println(p.prefix("Error") + err.Error())
+			return false
+		}
+		if len(entries) == 0 {
+			p.println(p.prefix("Codex") + "You haven't discovered any lore yet.")
+		}
+		for _, e := range entries {
+			p.println(p.prefix("Codex") + e.Title + ": " + e.Text)
+		}
+	case "/save", "/load":
+		if arg == "" {
+			p.println(p.prefix("Error") + "Usage: " + command + " <name>")
+			return false
+		}
+		if command == "/save" {
+			snap, err := saveSnapshot(p.client, p.config.APIBaseURL, gs.ID, arg)
+			if err != nil {
+				p.println(p.prefix("Error") + err.Error())
+				return false
+			}
+			p.println(p.prefix("Status") + fmt.Sprintf("Game saved as %q (turn %d).", snap.Name, snap.Turn))
+			return false
+		}
+		restored, err := restoreSnapshot(p.client, p.config.APIBaseURL, gs.ID, arg)
+		if err != nil {
+			p.println(p.prefix("Error") + err.Error())
+			return false
+		}
+		p.choices = nil
+		p.println(p.prefix("Status") + fmt.Sprintf("Game loaded from %q.", arg))
+		for i := len(restored.ChatHistory) - 1; i >= 0; i-- {
+			if restored.ChatHistory[i].Role == chat.ChatRoleAgent {
+				p.println(p.prefix("Narrator") + restored.ChatHistory[i].Content)
+				break
+			}
+		}
+	case "/saves":
+		snapshots, err := listSnapshots(p.client, p.config.APIBaseURL, gs.ID)
+		if err != nil {
+			p.println(p.prefix("Error") + err.Error())
+			return false
+		}
+		if len(snapshots) == 0 {
+			p.println(p.prefix("Saves") + "No saved games yet. Save with /save <name>.")
+		}
+		for _, s := range snapshots {
+			p.println(p.prefix("Saves") + fmt.Sprintf("%s (turn %d, %s)", s.Name, s.Turn, s.CreatedAt.Local().Format("Jan 2 15:04")))
+		}
+	default:
+		p.println("Commands: /status shows location, inventory and recent events. /pc shows your character sheet. /codex lists the lore you have discovered. /save <name> saves the game, /saves lists saved games, and /load <name> goes back to one. /inventory and /examine <item> describe your gear. /talk <name> starts a conversation with someone nearby and /leave ends it. /hint gives a hint when you're stuck. /continue asks the narrator for more of the last narration. /quit exits.")
+	}
+	return false
+}
+
+// printStatus prints the same details the interactive client shows in its sidebar
+func (p *plainConsole) printStatus(gs *state.GameState) {
+	location := gs.Location
+	if loc, ok := gs.WorldLocations[gs.Location]; ok && loc.Name != "" {
+		location = loc.Name
+	}
+	if gs.SceneName != "" {
+		p.println(p.prefix("Status") + "Scene: " + gs.SceneName)
+	}
+	p.println(p.prefix("Status") + "Location: " + location)
+	p.println(fmt.Sprintf("%sTurn: %d", p.prefix("Status"), gs.TurnCounter))
+	if len(gs.Inventory) == 0 {
+		p.println(p.prefix("Status") + "Inventory: none")
+	} else {
+		p.println(p.prefix("Status") + "Inventory: " + strings.Join(gs.DisplayItems(gs.Inventory), ", "))
+	}
+	for _, e := range recentEvents(gs, maxSidebarEvents) {
+		p.println(p.prefix("Event") + e)
+	}
+}
+
+// selectScenario lists scenarios and returns the chosen scenario file
+func (p *plainConsole) selectScenario() (string, error) {
+	names, scenarioMap, entries, err := listScenarios(p.client, p.config.APIBaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to load scenarios: %w", err)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no scenarios available")
+	}
+
+	p.println("Choose a scenario:")
+	for i, name := range names {
+		label := fmt.Sprintf("%d. %s", i+1, name)
+		if e, ok := entries[name]; ok && !e.Compatible {
+			label += " (not supported by the server's model)"
+		}
+		p.println(label)
+	}
+	idx, ok := p.promptIndex(len(names), 0)
+	if !ok {
+		return "", nil
+	}
+	return scenarioMap[names[idx]], nil
+}
+
+// selectPC lists characters and returns the chosen PC ID, defaulting to the scenario's PC
+func (p *plainConsole) selectPC(defaultPC string) (string, error) {
+	names, pcMap, err := listPCs(p.client, p.config.APIBaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to load characters: %w", err)
+	}
+	if len(names) == 0 {
+		return defaultPC, nil
+	}
+
+	defaultIdx := 0
+	p.println("Choose a character:")
+	for i, name := range names {
+		label := fmt.Sprintf("%d. %s", i+1, name)
+		if pcMap[name] == defaultPC {
+			defaultIdx = i
+			label += " (default)"
+		}
+		p.println(label)
+	}
+	idx, ok := p.promptIndex(len(names), defaultIdx)
+	if !ok {
+		return defaultPC, nil
+	}
+	return pcMap[names[idx]], nil
+}
+
+// promptIndex reads a 1-based selection, returning the zero-based index.
+// An empty line selects defaultIdx. Returns false when input is exhausted.
+func (p *plainConsole) promptIndex(count, defaultIdx int) (int, bool) {
+	for {
+		line, ok := p.prompt(fmt.Sprintf("Enter 1-%d [%d]: ", count, defaultIdx+1))
+		if !ok {
+			return 0, false
+		}
+		if line == "" {
+			return defaultIdx, true
+		}
+		n, err := strconv.Atoi(line)
+		if err == nil && n >= 1 && n <= count {
+			return n - 1, true
+		}
+		p.println(fmt.Sprintf("Please enter a number from 1 to %d.", count))
+	}
+}
+
+// prompt writes the prompt text and reads one trimmed line of input
+func (p *plainConsole) prompt(text string) (string, bool) {
+	_, _ = fmt.Fprint(p.out, text)
+	if !p.in.Scan() {
+		return "", false
+	}
+	return strings.TrimSpace(p.in.Text()), true
+}
+
+// prefix returns a "Role: " label when screen reader prefixes are enabled
+func (p *plainConsole) prefix(role string) string {
+	if !p.config.ScreenReaderPrefixes {
+		if role == "Error" {
+			return "Error: "
+		}
+		return ""
+	}
+	return role + ": "
+}
+
+func (p *plainConsole) println(s string) {
+	_, _ = fmt.Fprintln(p.out, s)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+const (
+	pollInterval     = 1 * time.Second  // between polls while waiting for an updated game state
+	pollMaxInterval  = 16 * time.Second // longest backoff while the server is slow or failing
+	idlePollInterval = 30 * time.Second // between polls when not waiting for anything
+	pollJitter       = 0.2              // fraction of the interval polls are spread by
+	pushPollInterval = 10 * time.Second // between fallback polls while the server pushes game.state_updated
+)
+
+// nextPollDelay doubles the polling interval, up to pollMaxInterval
+func nextPollDelay(d time.Duration) time.Duration {
+	return min(max(d, pollInterval)*2, pollMaxInterval)
+}
+
+// activePollInterval is the polling interval while waiting for an updated game state.
+// Servers that push game.state_updated only need an occasional poll in case it's missed.
+func (m *ConsoleUI) activePollInterval() time.Duration {
+	if m.pushUpdates {
+		return pushPollInterval
+	}
+	return pollInterval
+}
+
+// schedulePoll returns a command that triggers a pollTickMsg after about d, spread by
+// pollJitter so consoles that backed off together don't poll in step
+func schedulePoll(d time.Duration) tea.Cmd {
+	d += time.Duration((rand.Float64()*2 - 1) * pollJitter * float64(d))
+	return tea.Tick(d, func(time.Time) tea.Msg { return pollTickMsg{} })
+}
+
+// beginPoll cancels any poll still in flight and starts a new one
+func (m *ConsoleUI) beginPoll() tea.Cmd {
+	m.cancelPoll()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.pollCancel = cancel
+	m.pollSeq++
+	m.activePollSeq = m.pollSeq
+	m.pollInFlight = true
+	return startPoll(ctx, m.activePollSeq, m.client, m.config.APIBaseURL, m.gameState.ID)
+}
+
+// cancelPoll cancels the poll in flight, if any; its result will be ignored
+func (m *ConsoleUI) cancelPoll() {
+	if m.pollCancel != nil {
+		m.pollCancel()
+		m.pollCancel = nil
+	}
+	m.pollInFlight = false
+}
+
+// startPoll begins an HTTP fetch for the latest game state; old sequences are ignored
+func startPoll(ctx context.Context, seq int, client *http.Client, baseURL string, id uuid.UUID) tea.Cmd {
+	return func() tea.Msg {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/gamestate/%s", baseURL, id), nil)
+		if err != nil {
+			return pollResultMsg{seq: seq, gameState: nil, err: err}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return pollResultMsg{seq: seq, gameState: nil, err: err}
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return pollResultMsg{seq: seq, gameState: nil, err: err}
+		}
+		if resp.StatusCode != http.StatusOK {
+			return pollResultMsg{seq: seq, gameState: nil, err: fmt.Errorf("poll status %d", resp.StatusCode)}
+		}
+		var gs state.GameState
+		if err := json.Unmarshal(body, &gs); err != nil {
+			return pollResultMsg{seq: seq, gameState: nil, err: err}
+		}
+		return pollResultMsg{seq: seq, gameState: &gs, err: nil}
+	}
+}
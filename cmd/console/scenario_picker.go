@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+// ratingFilters are the rating filter options cycled with Tab in the scenario picker.
+// The empty string means all ratings.
+var ratingFilters = []string{"", scenario.RatingG, scenario.RatingPG, scenario.RatingPG13, scenario.RatingR}
+
+// filterScenarios returns the scenario names matching the search query, rating and tag filters.
+// The query matches case-insensitively against the name, synopsis, author, and tags.
+func filterScenarios(names []string, details map[string]ScenarioListEntry, query, rating, tag string) []string {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var filtered []string
+	for _, name := range names {
+		s, ok := details[name]
+		if rating != "" && (!ok || s.Rating != rating) {
+			continue
+		}
+		if tag != "" && (!ok || !slices.Contains(s.Tags, tag)) {
+			continue
+		}
+		if query != "" && !scenarioMatches(name, s, query) {
+			continue
+		}
+		filtered = append(filtered, name)
+	}
+	return filtered
+}
+
+func scenarioMatches(name string, s ScenarioListEntry, query string) bool {
+	if strings.Contains(strings.ToLower(name), query) {
+		return true
+	}
+	if strings.Contains(strings.ToLower(s.Synopsis), query) ||
+		strings.Contains(strings.ToLower(s.Author), query) {
+		return true
+	}
+	for _, t := range s.Tags {
+		if strings.Contains(strings.ToLower(t), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// scenarioTags returns the sorted, unique tags across all loaded scenarios
+func scenarioTags(details map[string]ScenarioListEntry) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, s := range details {
+		for _, t := range s.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// nextFilter returns the option after current, wrapping around
+func nextFilter(options []string, current string) string {
+	i := slices.Index(options, current)
+	return options[(i+1)%len(options)]
+}
+
+// filterLabel renders an empty filter value as "All"
+func filterLabel(v string) string {
+	if v == "" {
+		return "All"
+	}
+	return v
+}
+
+// renderScenarioDetail formats the detail pane for the highlighted scenario
+func renderScenarioDetail(s ScenarioListEntry, ok bool, width int) string {
+	if !ok {
+		return promptStyle.Render("No details available.")
+	}
+	var content strings.Builder
+	if s.Synopsis != "" {
+		content.WriteString(modalItemStyle.Render(wrapText(s.Synopsis, width)) + "\n\n")
+	}
+	rating := s.Rating
+	if rating == "" {
+		rating = "Unrated"
+	}
+	content.WriteString(metaStyle.Render("Rating: ") + modalItemStyle.Render(rating) + "\n")
+	if len(s.Tags) > 0 {
+		content.WriteString(metaStyle.Render("Tags: ") + modalItemStyle.Render(wrapText(strings.Join(s.Tags, ", "), width)) + "\n")
+	}
+	if s.EstimatedTurns > 0 {
+		content.WriteString(metaStyle.Render("Length: ") + modalItemStyle.Render(fmt.Sprintf("about %d turns", s.EstimatedTurns)) + "\n")
+	}
+	if s.Author != "" {
+		byline := s.Author
+		if s.Version != "" {
+			byline += " (v" + s.Version + ")"
+		}
+		content.WriteString(metaStyle.Render("Author: ") + modalItemStyle.Render(byline) + "\n")
+	}
+	if !s.Compatible {
+		content.WriteString(errorStyle.Render(wrapText("The server's model does not support this scenario's rating.", width)) + "\n")
+	}
+	return content.String()
+}
+
+// selectedScenarioName returns the highlighted scenario in the filtered list, if any
+func (m *ConsoleUI) selectedScenarioName() (string, bool) {
+	filtered := filterScenarios(m.scenarios, m.scenarioDetails, m.scenarioQuery, m.ratingFilter, m.tagFilter)
+	if m.selectedScenario < 0 || m.selectedScenario >= len(filtered) {
+		return "", false
+	}
+	return filtered[m.selectedScenario], true
+}
+
+// filterSummary describes the active scenario picker filters
+func (m *ConsoleUI) filterSummary() string {
+	return fmt.Sprintf("Rating: %s   Tag: %s", filterLabel(m.ratingFilter), filterLabel(m.tagFilter))
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultThemeName = "dark"
+
+// Theme holds the console colors for each display role. Values are lipgloss colors:
+// ANSI 256 codes like "205" or hex values like "#ff79c6".
+type Theme struct {
+	Base string `json:"base,omitempty"` // Preset to start from when loading a theme file; defaults to "dark"
+
+	Title                   string `json:"title,omitempty"`    // Banner, scenario name, modal titles
+	Speaker                 string `json:"speaker,omitempty"`  // Speaker names in narration
+	Narrator                string `json:"narrator,omitempty"` // Narrator text
+	Meta                    string `json:"meta,omitempty"`     // Sidebar labels
+	User                    string `json:"user,omitempty"`     // Player text and input
+	Error                   string `json:"error,omitempty"`
+	Loading                 string `json:"loading,omitempty"`
+	Prompt                  string `json:"prompt,omitempty"` // Hints, notices, and secondary text
+	Separator               string `json:"separator,omitempty"`
+	ModalBorder             string `json:"modal_border,omitempty"`
+	ModalBackground         string `json:"modal_background,omitempty"`
+	ModalForeground         string `json:"modal_foreground,omitempty"`
+	ModalSelectedForeground string `json:"modal_selected_foreground,omitempty"`
+	ModalSelectedBackground string `json:"modal_selected_background,omitempty"`
+}
+
+// presetThemes are the built-in themes selectable by name
+var presetThemes = map[string]Theme{
+	"dark": {
+		Title:                   "205", // pink
+		Speaker:                 "212", // purple
+		Narrator:                "86",  // green
+		Meta:                    "86",
+		User:                    "39",  // teal
+		Error:                   "203", // lighter red/pink for better visibility on black
+		Loading:                 "214", // yellow
+		Prompt:                  "240", // dark grey
+		Separator:               "240",
+		ModalBorder:             "62",
+		ModalBackground:         "235",
+		ModalForeground:         "255",
+		ModalSelectedForeground: "0",
+		ModalSelectedBackground: "205",
+	},
+	"light": {
+		Title:                   "162",
+		Speaker:                 "91",
+		Narrator:                "22",
+		Meta:                    "22",
+		User:                    "25",
+		Error:                   "160",
+		Loading:                 "130",
+		Prompt:                  "243",
+		Separator:               "248",
+		ModalBorder:             "62",
+		ModalBackground:         "254",
+		ModalForeground:         "235",
+		ModalSelectedForeground: "255",
+		ModalSelectedBackground: "162",
+	},
+	"high-contrast": {
+		Title:                   "11", // bright yellow
+		Speaker:                 "14", // bright cyan
+		Narrator:                "15", // bright white
+		Meta:                    "11",
+		User:                    "14",
+		Error:                   "9",
+		Loading:                 "11",
+		Prompt:                  "7",
+		Separator:               "7",
+		ModalBorder:             "15",
+		ModalBackground:         "0",
+		ModalForeground:         "15",
+		ModalSelectedForeground: "0",
+		ModalSelectedBackground: "11",
+	},
+}
+
+// loadTheme resolves a theme by preset name or by path to a JSON theme file.
+// Theme files only need to set the roles they override; the rest come from their base preset.
+func loadTheme(nameOrPath string) (Theme, error) {
+	if nameOrPath == "" {
+		nameOrPath = defaultThemeName
+	}
+	if t, ok := presetThemes[strings.ToLower(nameOrPath)]; ok {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return Theme{}, fmt.Errorf("unknown theme %q (presets: %s): %w", nameOrPath, strings.Join(presetThemeNames(), ", "), err)
+	}
+	var overrides Theme
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme file %s: %w", nameOrPath, err)
+	}
+
+	baseName := overrides.Base
+	if baseName == "" {
+		baseName = defaultThemeName
+	}
+	base, ok := presetThemes[strings.ToLower(baseName)]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme file %s has unknown base %q", nameOrPath, baseName)
+	}
+	return base.merge(overrides), nil
+}
+
+// merge returns a copy of t with every non-empty color in overrides applied
+func (t Theme) merge(overrides Theme) Theme {
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&t.Title, overrides.Title)
+	set(&t.Speaker, overrides.Speaker)
+	set(&t.Narrator, overrides.Narrator)
+	set(&t.Meta, overrides.Meta)
+	set(&t.User, overrides.User)
+	set(&t.Error, overrides.Error)
+	set(&t.Loading, overrides.Loading)
+	set(&t.Prompt, overrides.Prompt)
+	set(&t.Separator, overrides.Separator)
+	set(&t.ModalBorder, overrides.ModalBorder)
+	set(&t.ModalBackground, overrides.ModalBackground)
+	set(&t.ModalForeground, overrides.ModalForeground)
+	set(&t.ModalSelectedForeground, overrides.ModalSelectedForeground)
+	set(&t.ModalSelectedBackground, overrides.ModalSelectedBackground)
+	return t
+}
+
+func presetThemeNames() []string {
+	names := make([]string, 0, len(presetThemes))
+	for name := range presetThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyTheme rebuilds the package-level styles used across chat, sidebar, and modals
+func applyTheme(t Theme) {
+	activeTheme = t
+
+	titleStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Title)).
+		Bold(true)
+
+	speakerStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Speaker)).
+		Bold(true)
+
+	narratorStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Narrator))
+
+	metaStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Meta))
+
+	userStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.User))
+
+	errorStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Error))
+
+	loadingStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Loading))
+
+	promptStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Prompt))
+
+	separatorStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Separator))
+
+	modalStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(t.ModalBorder)).
+		Padding(1, 2).
+		Background(lipgloss.Color(t.ModalBackground)).
+		Foreground(lipgloss.Color(t.ModalForeground))
+
+	modalTitleStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Title)).
+		Bold(true).
+		Align(lipgloss.Center)
+
+	modalItemStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.ModalForeground))
+
+	modalSelectedItemStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.ModalSelectedForeground)).
+		Background(lipgloss.Color(t.ModalSelectedBackground)).
+		Bold(true)
+}
+
+func init() {
+	applyTheme(presetThemes[defaultThemeName])
+}
@@ -4,16 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/atotto/clipboard"
 
-	"github.com/google/uuid"
-
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -21,50 +20,14 @@ import (
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/textfilter"
-	"github.com/muesli/reflow/wordwrap"
 )
 
 const (
 	AgentName       = "Narrator"
 	PlaceHolderText = "Type your message here...\nExamples: Look around. Get the key. Talk to the guard."
-)
 
-// smartWrap wraps text at natural break points including spaces, slashes, and dashes
-// func smartWrap(text string, width int) []string {
-// 	if width <= 0 {
-// 		return []string{text}
-// 	}
-
-// 	// For simple cases like URLs, use a simpler approach
-// 	if !strings.Contains(text, " ") {
-// 		// This is likely a URL or similar - split on / and -
-// 		var lines []string
-// 		var currentLine strings.Builder
-
-// 		for _, char := range text {
-// 			currentLine.WriteRune(char)
-
-// 			// Check if we should break after certain characters
-// 			if (char == '/' || char == '-') && currentLine.Len() >= width/2 {
-// 				lines = append(lines, currentLine.String())
-// 				currentLine.Reset()
-// 			} else if currentLine.Len() >= width {
-// 				lines = append(lines, currentLine.String())
-// 				currentLine.Reset()
-// 			}
-// 		}
-
-// 		if currentLine.Len() > 0 {
-// 			lines = append(lines, currentLine.String())
-// 		}
-
-// 		return lines
-// 	}
-
-// 	// For text with spaces, use the existing wordwrap
-// 	wrapped := wordwrap.String(text, width)
-// 	return strings.Split(wrapped, "\n")
-// }
+	maxSidebarEvents = 8 // number of recent turn receipt events shown in the sidebar
+)
 
 // calculateAverageLatency computes the average latency from a slice of latencies
 func calculateAverageLatency(latencies []float64) float64 {
@@ -97,9 +60,13 @@ type ConsoleUI struct {
 	showScenarioModal bool
 	scenarios         []string
 	scenarioMap       map[string]string
-	selectedScenario  int
+	selectedScenario  int // index into the filtered scenario list
 	loadingScenarios  bool
 	contentRating     string
+	scenarioDetails   map[string]ScenarioListEntry // listing metadata by display name
+	scenarioQuery     string                       // search-as-you-type text
+	ratingFilter      string                       // "" means all ratings
+	tagFilter         string                       // "" means all tags
 
 	// PC selection state
 	showPCModal          bool
@@ -126,11 +93,17 @@ type ConsoleUI struct {
 	userPinned bool // true when user has scrolled away from bottom
 
 	// Polling state
-	pollSeq          int       // incrementing sequence for polls
-	activePollSeq    int       // sequence number of poll in flight
-	pollInFlight     bool      // whether a poll HTTP request is active
-	pollingActive    bool      // whether we're actively waiting for an updated gamestate
-	pollingStartedAt time.Time // timestamp when we started waiting for updates
+	pollSeq          int                // incrementing sequence for polls
+	activePollSeq    int                // sequence number of poll in flight
+	pollInFlight     bool               // whether a poll HTTP request is active
+	pollingActive    bool               // whether we're actively waiting for an updated gamestate
+	pollingStartedAt time.Time          // timestamp when we started waiting for updates
+	pollCancel       context.CancelFunc // cancels the poll in flight
+	pollDelay        time.Duration      // interval between active polls; backs off while the server is slow
+	blurred          bool               // terminal window is unfocused, so polling pauses
+	pollSuspended    bool               // a poll came due while blurred; focus restarts polling
+	pushUpdates      bool               // the server publishes game.state_updated, so active polling is only a fallback
+	stateUpdatedAt   time.Time          // when the last game.state_updated event arrived
 
 	// Game ending state
 	finalMessageSent bool // whether we've already sent the final message after game end
@@ -148,12 +121,17 @@ type ConsoleUI struct {
 	isStreaming         bool   // whether we're currently receiving a streaming response
 	streamingContent    string // accumulated content from streaming chunks
 	streamingMessageIdx int    // index of the message being streamed in ChatHistory
+	continuing          bool   // the request in progress continues the last narration
+	continuedFrom       string // the narration being continued, which streamed content is joined to
 
 	// SSE event channel for async request updates
 	eventChan <-chan SSEEvent // channel for receiving SSE events from the server
 
 	// Force a full chat re-render on next gameStateMsg (used by Ctrl+R)
 	forceRerender bool
+
+	// Suggested next actions from the last completed turn (choices mode)
+	choices []string
 }
 
 // mergeServerGameState reconciles the authoritative server game state with any locally
@@ -179,11 +157,13 @@ func (m *ConsoleUI) mergeServerGameState(serverGS *state.GameState) {
 		m.gameState.WorldLocations = serverGS.WorldLocations
 		m.gameState.Location = serverGS.Location
 		m.gameState.Inventory = serverGS.Inventory
+		m.gameState.ItemNames = serverGS.ItemNames
 		m.gameState.TurnCounter = serverGS.TurnCounter
 		m.gameState.SceneTurnCounter = serverGS.SceneTurnCounter
 		m.gameState.Vars = serverGS.Vars
 		m.gameState.IsEnded = serverGS.IsEnded
 		m.gameState.ContingencyPrompts = serverGS.ContingencyPrompts
+		m.gameState.TurnReceipts = serverGS.TurnReceipts
 		m.gameState.ChatHistory = make([]chat.ChatMessage, len(serverGS.ChatHistory))
 		copy(m.gameState.ChatHistory, serverGS.ChatHistory)
 
@@ -243,6 +223,7 @@ type gameStateMsg struct {
 type scenariosLoadedMsg struct {
 	scenarios   []string
 	scenarioMap map[string]string
+	details     map[string]ScenarioListEntry
 	err         error
 }
 
@@ -286,55 +267,23 @@ var (
 			PaddingLeft(0).
 			PaddingRight(2)
 
-	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")). // pink
-			Bold(true)
-
-	speakerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("212")). // purple
-			Bold(true)
-
-	narratorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("86")) // green
-
-	metaStyle = narratorStyle // copy narrator style for now
-
-	userStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39")) // teal
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("203")) // lighter red/pink for better visibility on black
-
-	loadingStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("214")) // yellow
-
-	promptStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")) // dark grey
-
-	modalStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("62")).
-			Padding(1, 2).
-			Background(lipgloss.Color("235")).
-			Foreground(lipgloss.Color("255"))
-
-	modalTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Bold(true).
-			Align(lipgloss.Center)
-
-	modalItemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("255"))
-
-	modalSelectedItemStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("0")).
-				Background(lipgloss.Color("205")).
-				Bold(true)
+	// Color styles are set from the active theme; see applyTheme
+	activeTheme            Theme
+	titleStyle             lipgloss.Style
+	speakerStyle           lipgloss.Style
+	narratorStyle          lipgloss.Style
+	metaStyle              lipgloss.Style
+	userStyle              lipgloss.Style
+	errorStyle             lipgloss.Style
+	loadingStyle           lipgloss.Style
+	promptStyle            lipgloss.Style
+	separatorStyle         lipgloss.Style
+	modalStyle             lipgloss.Style
+	modalTitleStyle        lipgloss.Style
+	modalItemStyle         lipgloss.Style
+	modalSelectedItemStyle lipgloss.Style
 )
 
-var separatorStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("240")) // dark grey
-
 // findDefaultPCIndex finds the index of the default PC in the PC list
 // Returns the index if found, or the index of "classic" as fallback, or 0 if neither found
 func (m *ConsoleUI) findDefaultPCIndex() int {
@@ -368,18 +317,18 @@ func NewConsoleUI(cfg *ConsoleConfig, client *http.Client) ConsoleUI {
 	ta.SetHeight(3)
 	ta.ShowLineNumbers = false
 
-	// Style the textarea to match user text color (teal)
-	tealColor := lipgloss.Color("39")
-	ta.FocusedStyle.Text = ta.FocusedStyle.Text.Foreground(tealColor)
-	ta.BlurredStyle.Text = ta.BlurredStyle.Text.Foreground(tealColor)
-	ta.FocusedStyle.Base = ta.FocusedStyle.Base.Foreground(tealColor)
-	ta.BlurredStyle.Base = ta.BlurredStyle.Base.Foreground(tealColor)
+	// Style the textarea to match user text color
+	userColor := lipgloss.Color(activeTheme.User)
+	ta.FocusedStyle.Text = ta.FocusedStyle.Text.Foreground(userColor)
+	ta.BlurredStyle.Text = ta.BlurredStyle.Text.Foreground(userColor)
+	ta.FocusedStyle.Base = ta.FocusedStyle.Base.Foreground(userColor)
+	ta.BlurredStyle.Base = ta.BlurredStyle.Base.Foreground(userColor)
 
 	chatVp := viewport.New(50, 20)
 	chatVp.MouseWheelEnabled = false // mouse scroll handled manually below
 
 	metaVp := viewport.New(20, 20)
-	metaVp.MouseWheelEnabled = false // sidebar is locked at top
+	metaVp.MouseWheelEnabled = false // sidebar scrolls only via the sidebar paging keys
 
 	return ConsoleUI{
 		config:            cfg,
@@ -423,7 +372,7 @@ func (m *ConsoleUI) scenarioDisplayName() string {
 	return file // fallback to file name
 }
 
-func writeSidebar(gs *state.GameState, width int, scenarioDisplay string, pollingActive bool, chatLatencies []float64) string {
+func writeSidebar(gs *state.GameState, width int, scenarioDisplay string, pollingActive bool, chatLatencies []float64, keys KeyMap) string {
 	var content strings.Builder
 
 	//castle := " _   |>  _\n[_]--'--[_]\n|'|\"\"`\"\"|'|\n| | /^\\ | |\n|_|_|I|_|_|"
@@ -435,18 +384,17 @@ func writeSidebar(gs *state.GameState, width int, scenarioDisplay string, pollin
 
 	content.WriteString("\n" + titleStyle.Render(castle) + "\n\n")
 
-	content.WriteString(scenarioDisplay + "\n")
+	width = max(width, 8) // min width of 8
+	content.WriteString(wrapText(scenarioDisplay, width) + "\n")
 	if gs.SceneName != "" {
-		content.WriteString(metaStyle.Render("Scene: "))
-		content.WriteString(gs.SceneName + "\n")
+		content.WriteString(wrapText(metaStyle.Render("Scene: ")+gs.SceneName, width) + "\n")
 	}
-	content.WriteString(metaStyle.Render("Location: "))
 	// Display location name instead of key
+	locationName := gs.Location
 	if loc, ok := gs.WorldLocations[gs.Location]; ok && loc.Name != "" {
-		content.WriteString(loc.Name + "\n")
-	} else {
-		content.WriteString(gs.Location + "\n")
+		locationName = loc.Name
 	}
+	content.WriteString(wrapText(metaStyle.Render("Location: ")+locationName, width) + "\n")
 	content.WriteString(metaStyle.Render("Turn: "))
 	content.WriteString(fmt.Sprintf("%d", gs.TurnCounter) + "\n\n")
 
@@ -455,17 +403,28 @@ func writeSidebar(gs *state.GameState, width int, scenarioDisplay string, pollin
 		content.WriteString("None\n\n")
 	} else {
 		for i := range gs.Inventory {
-			fmt.Fprintf(&content, "• %s\n", gs.Inventory[i])
+			content.WriteString(wrapText("• "+gs.ItemName(gs.Inventory[i]), width) + "\n")
+		}
+	}
+
+	if events := recentEvents(gs, maxSidebarEvents); len(events) > 0 {
+		content.WriteString("\n" + metaStyle.Render("Recent Events:") + "\n")
+		for _, e := range events {
+			content.WriteString(wrapText(e, width) + "\n")
 		}
 	}
 
 	content.WriteString("\n")
 	content.WriteString(metaStyle.Render("Commands:") + "\n")
-	content.WriteString("• Ctrl+C: Quit\n")
-	content.WriteString("• Ctrl+N: New Game\n")
-	content.WriteString("• Ctrl+E: Export Chat\n")
-	content.WriteString("• Ctrl+S: Save State\n")
-	content.WriteString("• Ctrl+R: Re-render\n")
+	for _, b := range []key.Binding{keys.Quit, keys.NewGame, keys.Export, keys.Save, keys.Rerender} {
+		fmt.Fprintf(&content, "• %s: %s\n", formatKeyName(b.Help().Key), b.Help().Desc)
+	}
+	content.WriteString("• /pc: Character Sheet\n")
+	content.WriteString("• /codex: Discovered Lore\n")
+	content.WriteString("• /hint: Ask for a Hint\n")
+	content.WriteString("• /continue: More Narration\n")
+	content.WriteString("• /save, /load: Save Slots\n")
+	content.WriteString("• /keys: Key Bindings\n")
 
 	if gs.IsEnded {
 		content.WriteString("\n" + titleStyle.Render("GAME ENDED") + "\n")
@@ -484,17 +443,7 @@ func writeSidebar(gs *state.GameState, width int, scenarioDisplay string, pollin
 	}
 
 	content.WriteString("\n")
-	content.WriteString(promptStyle.Render(gs.ModelName) + "\n\n")
-	// width = max(8, width) // min width of 8
-
-	// // Format the UUID to wrap nicely
-	// idStr := gs.ID.String()
-	// wrappedIDLines := smartWrap(idStr, width)
-	// for _, line := range wrappedIDLines {
-	// 	content.WriteString(promptStyle.Render(line) + "\n")
-	// }
-	// content.WriteString("\n")
-
+	content.WriteString(promptStyle.Render(wrapText(gs.ModelName, width)) + "\n\n")
 	content.WriteString(promptStyle.Render("© 2025 Joseph Webster"))
 
 	return content.String()
@@ -529,6 +478,9 @@ func (m *ConsoleUI) writeChatContent() {
 		case "assistant":
 			formattedMsg := formatNarratorResponse(msg.Content, chatWidth)
 			content.WriteString(formattedMsg + "\n\n")
+			if msg.Incomplete {
+				content.WriteString(promptStyle.Render(incompleteNotice) + "\n\n")
+			}
 		case "system":
 			// Check if this is an error message (already styled) or regular system message
 			if strings.Contains(msg.Content, "Error:") && strings.Contains(msg.Content, "\x1b[") {
@@ -540,11 +492,40 @@ func (m *ConsoleUI) writeChatContent() {
 				content.WriteString(formattedMsg + "\n\n")
 			}
 		case "user":
-			userMsg := userStyle.Render(wordwrap.String(msg.Content, chatWidth-3))
+			userMsg := userStyle.Render(wrapText(msg.Content, chatWidth-3))
 			content.WriteString(userMsg + "\n\n")
 		}
 	}
 
+	// A side conversation is shown after the story it branched from
+	if conv := m.gameState.Conversation; conv != nil {
+		label := "Talking with " + m.gameState.NPCName(conv.NPCID) + " (/leave to end)"
+		content.WriteString(promptStyle.Render(label) + "\n\n")
+		for _, msg := range conv.History {
+			if msg.Role == "user" {
+				content.WriteString(userStyle.Render(wrapText(msg.Content, chatWidth-3)) + "\n\n")
+			} else {
+				content.WriteString(formatNarratorResponse(msg.Content, chatWidth) + "\n\n")
+				if msg.Incomplete {
+					content.WriteString(promptStyle.Render(incompleteNotice) + "\n\n")
+				}
+			}
+		}
+	}
+
+	if !m.loading && !m.isStreaming {
+		if notices := formatReceiptNotices(m.gameState, latestReceipt(m.gameState)); len(notices) > 0 {
+			for _, notice := range notices {
+				content.WriteString(promptStyle.Render(wrapText(notice, chatWidth-3)) + "\n")
+			}
+			content.WriteString("\n")
+		}
+	}
+
+	if len(m.choices) > 0 && !m.loading && !m.isStreaming {
+		content.WriteString(renderChoices(m.choices, chatWidth))
+	}
+
 	if m.loading {
 		content.WriteString(m.renderProgressBar())
 	}
@@ -563,10 +544,24 @@ func (m ConsoleUI) Init() tea.Cmd {
 		return m.loadScenarios()
 	}
 	// Start polling even before game state; scheduler will requeue until game state exists
-	return tea.Batch(textarea.Blink, schedulePoll())
+	return tea.Batch(textarea.Blink, schedulePoll(pollInterval))
 }
 
 func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Track terminal focus whatever is on screen, so polling pauses while unfocused
+	switch msg.(type) {
+	case tea.BlurMsg:
+		m.blurred = true
+		return m, nil
+	case tea.FocusMsg:
+		m.blurred = false
+		if m.pollSuspended {
+			m.pollSuspended = false
+			return m, func() tea.Msg { return pollTickMsg{} }
+		}
+		return m, nil
+	}
+
 	// Handle scenario modal first
 	if m.showScenarioModal {
 		return m.updateScenarioModal(msg)
@@ -587,11 +582,7 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateNewGameModal(msg)
 	}
 
-	var (
-		tiCmd tea.Cmd
-		vpCmd tea.Cmd
-		mvCmd tea.Cmd
-	)
+	var tiCmd tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -621,46 +612,47 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Update metadata panel content as well
 			if m.gameState != nil {
-				m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies))
+				m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies, m.config.Keys))
 			}
 		}
 
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
+		keys := m.config.Keys
+		switch {
+		case key.Matches(msg, keys.Quit):
 			m.err = nil // Clear any stale errors when opening quit modal
 			m.showQuitModal = true
 			return m, nil
 
-		case tea.KeyCtrlN:
+		case key.Matches(msg, keys.NewGame):
 			// Show new game confirmation modal
 			m.err = nil // Clear any stale errors when opening new game modal
 			m.showNewGameModal = true
 			return m, nil
 
-		case tea.KeyCtrlY:
+		case key.Matches(msg, keys.CopyID):
 			// Copy GameState ID to system clipboard (assume non-nil per user instruction)
 			if m.gameState != nil {
 				_ = clipboard.WriteAll(m.gameState.ID.String())
 				// Optionally append a tiny notice to metadata (non-intrusive)
-				m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies))
+				m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies, m.config.Keys))
 			}
 			return m, nil
 
-		case tea.KeyCtrlZ:
+		case key.Matches(msg, keys.ClearInput):
 			// Clear the text area
 			m.textarea.Reset()
 			return m, nil
 
-		case tea.KeyCtrlE:
+		case key.Matches(msg, keys.Export):
 			// Export chat history to markdown
 			return m.handleExport()
 
-		case tea.KeyCtrlS:
+		case key.Matches(msg, keys.Save):
 			// Save game state JSON to working directory
 			return m.handleSave()
 
-		case tea.KeyCtrlR:
+		case key.Matches(msg, keys.Rerender):
 			// Fetch fresh game state from server, then force a full chat re-render
 			if m.gameState != nil {
 				m.forceRerender = true
@@ -668,7 +660,7 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case tea.KeyEnter:
+		case key.Matches(msg, keys.Send):
 			if m.loading || m.isStreaming {
 				return m, nil
 			}
@@ -684,10 +676,16 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Apply profanity filtering based on the scenario's content rating
 			input = m.profanityFilter.FilterText(input, m.contentRating)
 
-			if strings.HasPrefix(input, "/") {
+			if strings.HasPrefix(input, "/") && !isServerCommand(input) {
 				return m.handleCommand(input)
 			}
 
+			// In choices mode, a bare number selects one of the suggested actions
+			if choice, ok := selectChoice(input, m.choices); ok {
+				input = choice
+			}
+			m.choices = nil
+
 			// Prevent multiple messages after game end
 			if m.gameState != nil && m.gameState.IsEnded && m.finalMessageSent {
 				return m, nil
@@ -710,25 +708,29 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.chatRequestStartTime = time.Now()
 
 			return m, tea.Batch(m.sendChatMessage(input), progressTick())
+
+		case key.Matches(msg, keys.SidebarPageUp):
+			m.metaViewport.PageUp()
+			return m, nil
+
+		case key.Matches(msg, keys.SidebarPageDown):
+			m.metaViewport.PageDown()
+			return m, nil
 		}
 
 		// scrolling/navigation keys for the chat viewport
-		keyStr := msg.String()
-		if keyStr == "up" || keyStr == "down" || keyStr == "pgup" || keyStr == "pgdown" || keyStr == "home" || keyStr == "end" {
-			prevAtBottom := m.chatViewport.AtBottom()
-			prevOffset := m.chatViewport.YOffset
-			m.chatViewport, vpCmd = m.chatViewport.Update(msg)
-			if m.chatViewport.YOffset != prevOffset { // user navigated
-				if !m.chatViewport.AtBottom() {
-					m.userPinned = true
-				} else if !prevAtBottom && m.chatViewport.AtBottom() {
-					m.userPinned = false
-				}
+		prevAtBottom := m.chatViewport.AtBottom()
+		prevOffset := m.chatViewport.YOffset
+		if scrollChat(&m.chatViewport, keys, msg) && m.chatViewport.YOffset != prevOffset { // user navigated
+			if !m.chatViewport.AtBottom() {
+				m.userPinned = true
+			} else if !prevAtBottom && m.chatViewport.AtBottom() {
+				m.userPinned = false
 			}
 		}
 
 	case tea.MouseMsg:
-		// Route mouse scroll wheel to the chat viewport only; sidebar is intentionally locked.
+		// Route mouse scroll wheel to the chat viewport only; the sidebar pages via keys.
 		switch msg.Button {
 		case tea.MouseButtonWheelUp:
 			m.chatViewport.ScrollUp(3)
@@ -780,44 +782,48 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.gameState != nil && m.gameState.IsEnded {
 			return m, nil
 		}
+		// Pause while the terminal is unfocused; focus picks polling back up
+		if m.blurred {
+			m.pollSuspended = true
+			return m, nil
+		}
 
 		// Time to initiate a poll (if we have a game state and are actively waiting for updates)
 		if m.gameState != nil && m.pollingActive {
 			if m.pollInFlight {
-				// Start a fresh poll by bumping the sequence; result from older poll will be ignored when it arrives
-				m.pollSeq++
-				m.activePollSeq = m.pollSeq
-				m.pollInFlight = true
-				return m, tea.Batch(startPoll(m.activePollSeq, m.client, m.config.APIBaseURL, m.gameState.ID), schedulePoll())
-			}
-			// No poll in flight; start one
-			m.pollSeq++
-			m.activePollSeq = m.pollSeq
-			m.pollInFlight = true
-			return m, tea.Batch(startPoll(m.activePollSeq, m.client, m.config.APIBaseURL, m.gameState.ID), schedulePoll())
+				// The last poll hasn't answered: the server is slow, so cancel it and back off
+				m.pollDelay = nextPollDelay(m.pollDelay)
+			}
+			return m, tea.Batch(m.beginPoll(), schedulePoll(m.pollDelay))
 		} else if m.gameState != nil {
 			// We have a game state but aren't actively waiting - reschedule with a longer interval
-			return m, tea.Tick(30*time.Second, func(time.Time) tea.Msg { return pollTickMsg{} })
+			return m, schedulePoll(idlePollInterval)
 		}
 		// No game state yet; just reschedule
-		return m, schedulePoll()
+		return m, schedulePoll(pollInterval)
 
 	case pollResultMsg:
 		// Only apply if this is the latest active sequence
 		if msg.seq == m.activePollSeq {
+			m.pollCancel = nil
 			m.pollInFlight = false
+			if msg.err != nil {
+				m.pollDelay = nextPollDelay(m.pollDelay)
+			} else {
+				m.pollDelay = m.activePollInterval()
+			}
 			if msg.err == nil && msg.gameState != nil && m.gameState != nil {
 				// Check if the game has ended and stop polling
 				if msg.gameState.IsEnded {
 					m.pollingActive = false
 					m.mergeServerGameState(msg.gameState)
-					m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies))
+					m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies, m.config.Keys))
 				} else if m.pollingActive && msg.gameState.UpdatedAt.After(m.pollingStartedAt) {
 					// Check if we got an updated timestamp and should stop active polling
 					m.pollingActive = false
 					// Apply the full updated gamestate
 					m.mergeServerGameState(msg.gameState)
-					m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies))
+					m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies, m.config.Keys))
 				} else {
 					// Just refresh metadata fields to avoid reordering chat mid-turn
 					m.gameState.ID = msg.gameState.ID
@@ -828,13 +834,15 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.gameState.WorldLocations = msg.gameState.WorldLocations
 					m.gameState.Location = msg.gameState.Location
 					m.gameState.Inventory = msg.gameState.Inventory
+					m.gameState.ItemNames = msg.gameState.ItemNames
 					m.gameState.TurnCounter = msg.gameState.TurnCounter
 					m.gameState.SceneTurnCounter = msg.gameState.SceneTurnCounter
 					m.gameState.Vars = msg.gameState.Vars
 					m.gameState.IsEnded = msg.gameState.IsEnded
 					m.gameState.ContingencyPrompts = msg.gameState.ContingencyPrompts
+					m.gameState.TurnReceipts = msg.gameState.TurnReceipts
 					m.gameState.UpdatedAt = msg.gameState.UpdatedAt
-					m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies))
+					m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies, m.config.Keys))
 				}
 			}
 		}
@@ -843,9 +851,25 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case sseEventMsg:
 		// Handle SSE events from the async request processing
 		switch msg.event.Type {
+		case "connected":
+			m.pushUpdates, _ = msg.event.Data["state_updates"].(bool)
+
+		case "game.state_updated":
+			// The turn's gamestate delta was saved: fetch it now instead of waiting for a poll
+			m.stateUpdatedAt = time.Now()
+			if m.gameState != nil && !m.blurred {
+				var sseCmd tea.Cmd
+				if m.eventChan != nil {
+					sseCmd = m.consumeSSEEvents(m.eventChan)
+				}
+				return m, tea.Batch(m.beginPoll(), sseCmd)
+			}
+
 		case "request.processing":
 			// Request has been picked up by worker - can stop showing progress bar
 			m.loading = false
+			requestType, _ := msg.event.Data["type"].(string)
+			m.continuing = requestType == "continue"
 
 			// Add the user message from the event data (if present)
 			if userMsg, ok := msg.event.Data["user_message"].(string); ok && userMsg != "" {
@@ -868,18 +892,25 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			content, ok := msg.event.Data["content"].(string)
 			if ok {
 				// If we're not currently streaming, start a new assistant message
+				// A continuation streams onto the end of the last narration instead
 				if !m.isStreaming {
 					m.isStreaming = true
 					m.streamingContent = ""
-					assistantMessage := chat.ChatMessage{Role: "assistant", Content: ""}
-					m.gameState.ChatHistory = append(m.gameState.ChatHistory, assistantMessage)
-					m.streamingMessageIdx = len(m.gameState.ChatHistory) - 1
+					m.continuedFrom = ""
+					if last := len(m.gameState.ChatHistory) - 1; m.continuing && last >= 0 && m.gameState.ChatHistory[last].Role == "assistant" {
+						m.streamingMessageIdx = last
+						m.continuedFrom = m.gameState.ChatHistory[last].Content
+					} else {
+						assistantMessage := chat.ChatMessage{Role: "assistant", Content: ""}
+						m.gameState.ChatHistory = append(m.gameState.ChatHistory, assistantMessage)
+						m.streamingMessageIdx = len(m.gameState.ChatHistory) - 1
+					}
 				}
 
 				// Append content to current streaming response
 				m.streamingContent += content
 				if m.streamingMessageIdx < len(m.gameState.ChatHistory) {
-					m.gameState.ChatHistory[m.streamingMessageIdx].Content = m.streamingContent
+					m.gameState.ChatHistory[m.streamingMessageIdx].Content = state.JoinNarration(m.continuedFrom, m.streamingContent)
 				}
 				// Refresh display with new content
 				m.writeChatContent()
@@ -893,6 +924,23 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.isStreaming = false
 			m.loading = false
 
+			// A continued narration keeps its place in the history, so the refresh below
+			// won't replace it; whether it's still cut off comes from the event
+			if m.continuing && m.streamingMessageIdx >= 0 && m.streamingMessageIdx < len(m.gameState.ChatHistory) {
+				m.gameState.ChatHistory[m.streamingMessageIdx].Incomplete = parseIncomplete(msg.event.Data)
+				m.writeChatContent()
+			}
+			m.continuing = false
+
+			// Pick up suggested next actions when the game is in choices mode
+			m.choices = parseChoices(msg.event.Data)
+			if len(m.choices) > 0 {
+				m.writeChatContent()
+				if !m.userPinned {
+					m.chatViewport.GotoBottom()
+				}
+			}
+
 			// Calculate latency
 			if !m.chatRequestStartTime.IsZero() {
 				m.lastChatLatency = time.Since(m.chatRequestStartTime).Seconds()
@@ -906,13 +954,17 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.pollingActive = true
 				m.pollingStartedAt = time.Now()
 
-				if !wasPollingActive {
-					startPollingCmd = schedulePoll()
+				if m.pushUpdates && m.stateUpdatedAt.After(m.chatRequestStartTime) {
+					// The turn's state update already arrived; the refresh below picks it up
+					m.pollingActive = false
+				} else if !wasPollingActive {
+					m.pollDelay = m.activePollInterval()
+					startPollingCmd = schedulePoll(m.pollDelay)
 				}
 			}
 
 			// Update metadata to show polling indicator
-			m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies))
+			m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies, m.config.Keys))
 
 			// Continue consuming SSE events while also refreshing gamestate
 			var sseCmd tea.Cmd
@@ -925,6 +977,7 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Request failed
 			m.isStreaming = false
 			m.loading = false
+			m.continuing = false
 
 			// Get error message from the data map
 			errorMsg := "Request failed"
@@ -966,7 +1019,7 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case gameStateMsg:
 		if msg.err == nil && msg.gameState != nil {
 			m.mergeServerGameState(msg.gameState)
-			m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies))
+			m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies, m.config.Keys))
 			if m.forceRerender {
 				m.forceRerender = false
 				m.writeChatContent()
@@ -982,65 +1035,219 @@ func (m ConsoleUI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	// Update components for non-mouse events (textarea & metadata). Chat viewport already handled for key scroll above.
-	// Mouse events are handled above and return early, so metaViewport.Update is never called for them.
+	// Update the textarea for non-mouse events. Chat and sidebar viewports are scrolled
+	// explicitly via the key map above, so they never see raw key messages.
 	m.textarea, tiCmd = m.textarea.Update(msg)
-	m.metaViewport, mvCmd = m.metaViewport.Update(msg)
-	m.metaViewport.YOffset = 0 // keep sidebar locked at top
-	return m, tea.Batch(tiCmd, vpCmd, mvCmd)
+	return m, tiCmd
 }
 
-func formatNarratorResponse(response string, width int) string {
-	// Check if response already has a speaker prefix
-	hasPrefix := false
-	if idx := strings.Index(response, ":"); idx > 0 && idx <= 20 {
-		speaker := response[:idx]
-		if len(strings.Fields(speaker)) <= 2 {
-			hasPrefix = true
+// scrollChat applies the chat navigation bindings to the chat viewport.
+// Returns true if msg was a chat navigation key.
+func scrollChat(vp *viewport.Model, keys KeyMap, msg tea.KeyMsg) bool {
+	switch {
+	case key.Matches(msg, keys.ChatUp):
+		vp.ScrollUp(1)
+	case key.Matches(msg, keys.ChatDown):
+		vp.ScrollDown(1)
+	case key.Matches(msg, keys.ChatPageUp):
+		vp.PageUp()
+	case key.Matches(msg, keys.ChatPageDown):
+		vp.PageDown()
+	case key.Matches(msg, keys.ChatTop):
+		vp.GotoTop()
+	case key.Matches(msg, keys.ChatBottom):
+		vp.GotoBottom()
+	default:
+		return false
+	}
+	return true
+}
+
+// latestReceipt returns the turn receipt for the current turn, if one was recorded
+func latestReceipt(gs *state.GameState) *state.TurnReceipt {
+	if gs == nil {
+		return nil
+	}
+	r, ok := gs.GetTurnReceipt(gs.TurnCounter)
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+// formatReceiptNotices renders the player-visible parts of a turn receipt as short notices.
+// Vars and conditionals are omitted since they are engine internals and may spoil the story.
+func formatReceiptNotices(gs *state.GameState, r *state.TurnReceipt) []string {
+	if r == nil {
+		return nil
+	}
+	var notices []string
+	for _, item := range r.ItemsGained {
+		notices = append(notices, "✦ Acquired: "+item)
+	}
+	for _, item := range r.ItemsLost {
+		notices = append(notices, "✦ Lost: "+item)
+	}
+	if r.LocationChanged != "" {
+		name := r.LocationChanged
+		if loc, ok := gs.WorldLocations[name]; ok && loc.Name != "" {
+			name = loc.Name
 		}
+		notices = append(notices, "✦ Arrived: "+name)
+	}
+	if r.SceneChanged != "" {
+		notices = append(notices, "✦ New scene: "+r.SceneChanged)
+	}
+	switch n := len(r.LoreDiscovered); {
+	case n == 1:
+		notices = append(notices, "✦ New codex entry (see /codex)")
+	case n > 1:
+		notices = append(notices, fmt.Sprintf("✦ %d new codex entries (see /codex)", n))
 	}
+	return notices
+}
 
-	// If no prefix, we'll add "Narrator: " so reduce available width
-	wrapWidth := width
-	if !hasPrefix {
-		narratorPrefix := AgentName + ": "
-		wrapWidth = width - len(narratorPrefix)
+// recentEvents flattens the game's turn receipts into a rolling log of player-visible
+// events, oldest first, keeping only the last limit entries.
+func recentEvents(gs *state.GameState, limit int) []string {
+	if gs == nil {
+		return nil
 	}
+	var events []string
+	for i := range gs.TurnReceipts {
+		r := &gs.TurnReceipts[i]
+		notices := formatReceiptNotices(gs, r)
+		if r.GameEnded {
+			notices = append(notices, "✦ The story has ended")
+		}
+		for _, n := range notices {
+			events = append(events, fmt.Sprintf("T%d %s", r.Turn, n))
+		}
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events
+}
+
+// incompleteNotice follows a narration that was cut off by an LLM error
+const incompleteNotice = "(The narration was cut off.)"
 
-	// Wrap the text to the available width
-	wrappedResponse := wordwrap.String(response, wrapWidth)
-	lines := strings.Split(wrappedResponse, "\n")
-	var formattedLines []string
+// parseIncomplete reports whether a request.completed event's narration was cut off
+func parseIncomplete(data map[string]interface{}) bool {
+	result, ok := data["result"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	incomplete, _ := result["incomplete"].(bool)
+	return incomplete
+}
 
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" {
-			formattedLines = append(formattedLines, "")
+// parseChoices extracts suggested actions from a request.completed event payload
+func parseChoices(data map[string]interface{}) []string {
+	result, ok := data["result"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	raw, ok := result["choices"].([]interface{})
+	if !ok {
+		return nil
+	}
+	choices := make([]string, 0, len(raw))
+	for _, c := range raw {
+		if s, ok := c.(string); ok && s != "" {
+			choices = append(choices, s)
+		}
+	}
+	return choices
+}
+
+// parseSegments extracts the narration and dialogue segments from a request.completed event
+func parseSegments(data map[string]interface{}) []chat.Segment {
+	result, ok := data["result"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	raw, ok := result["segments"].([]interface{})
+	if !ok {
+		return nil
+	}
+	segments := make([]chat.Segment, 0, len(raw))
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
 			continue
 		}
+		kind, _ := m["kind"].(string)
+		speaker, _ := m["speaker"].(string)
+		text, _ := m["text"].(string)
+		segments = append(segments, chat.Segment{Kind: kind, Speaker: speaker, Text: text})
+	}
+	return segments
+}
+
+// selectChoice maps a bare numeric input like "2" to the matching suggested action
+func selectChoice(input string, choices []string) (string, bool) {
+	n, err := strconv.Atoi(input)
+	if err != nil || n < 1 || n > len(choices) {
+		return "", false
+	}
+	return choices[n-1], true
+}
+
+// renderChoices formats suggested actions as a numbered list below the chat
+func renderChoices(choices []string, width int) string {
+	var sb strings.Builder
+	sb.WriteString(promptStyle.Render("Suggested actions (type a number, or anything else):") + "\n")
+	for i, c := range choices {
+		sb.WriteString(userStyle.Render(wrapText(fmt.Sprintf("  %d. %s", i+1, c), width-3)) + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func formatNarratorResponse(response string, width int) string {
+	// The server normalizes speaker prefixes, so "Name: " at the start of a line marks dialogue
+	var sb strings.Builder
+	segments := chat.ParseSegments(response, nil)
+	for i, seg := range segments {
+		if i > 0 {
+			// Keep dialogue lines together and set prose apart as its own paragraph
+			sb.WriteString("\n")
+			if seg.Kind == chat.SegmentNarration || segments[i-1].Kind == chat.SegmentNarration {
+				sb.WriteString("\n")
+			}
+		}
 
-		if idx := strings.Index(trimmed, ":"); idx > 0 && idx <= 20 {
-			speaker := trimmed[:idx]
-			rest := trimmed[idx+1:]
-			if len(strings.Fields(speaker)) <= 2 {
-				formattedLines = append(formattedLines, speakerStyle.Render(speaker+":")+rest)
+		label, style := seg.Speaker+": ", speakerStyle
+		if seg.Kind == chat.SegmentNarration {
+			if i > 0 {
+				sb.WriteString(wrapText(seg.Text, width))
 				continue
 			}
+			label, style = AgentName+": ", narratorStyle
 		}
-
-		formattedLines = append(formattedLines, line)
+		sb.WriteString(style.Render(strings.TrimSpace(label)) + " ")
+		sb.WriteString(wrapText(seg.Text, width-displayWidth(label)))
 	}
+	return sb.String()
+}
 
-	result := strings.Join(formattedLines, "\n")
-	if !hasPrefix && !strings.HasPrefix(strings.TrimSpace(result), speakerStyle.Render("")) {
-		result = narratorStyle.Render(AgentName+": ") + result
+// isServerCommand reports whether input is a command the server answers, such as
+// /inventory, so it's sent as a chat message instead of handled locally
+func isServerCommand(input string) bool {
+	command, _, _ := strings.Cut(strings.TrimSpace(input), " ")
+	switch strings.ToLower(command) {
+	case "/inventory", "/inv", "/examine", "/x", "/talk", "/leave", "/hint":
+		return true
 	}
-
-	return result
+	return false
 }
 
 func (m ConsoleUI) handleCommand(input string) (tea.Model, tea.Cmd) {
-	cmd := strings.ToLower(strings.TrimSpace(input))
+	command, arg, _ := strings.Cut(strings.TrimSpace(input), " ")
+	cmd := strings.ToLower(command)
+	arg = strings.TrimSpace(arg)
 
 	switch cmd {
 	case "/vars":
@@ -1058,6 +1265,111 @@ func (m ConsoleUI) handleCommand(input string) (tea.Model, tea.Cmd) {
 		currentContent := m.chatViewport.View()
 		m.chatViewport.SetContent(currentContent + varsText.String())
 		m.chatViewport.GotoBottom()
+
+	case "/pc":
+		var sheetText strings.Builder
+		for i, line := range pcSheetLines(m.gameState) {
+			if i == 0 {
+				sheetText.WriteString(titleStyle.Render(line) + "\n")
+				continue
+			}
+			sheetText.WriteString(wrapText(line, m.chatViewport.Width-6) + "\n")
+		}
+		sheetText.WriteString("\n")
+
+		currentContent := m.chatViewport.View()
+		m.chatViewport.SetContent(currentContent + sheetText.String())
+		m.chatViewport.GotoBottom()
+
+	case "/codex":
+		var codexText strings.Builder
+		codexText.WriteString(titleStyle.Render("Codex:") + "\n")
+		entries, err := getCodex(m.client, m.config.APIBaseURL, m.gameState.ID)
+		switch {
+		case err != nil:
+			codexText.WriteString(errorStyle.Render("Error: "+err.Error()) + "\n")
+		case len(entries) == 0:
+			codexText.WriteString("You haven't discovered any lore yet.\n")
+		default:
+			for _, e := range entries {
+				codexText.WriteString(speakerStyle.Render(e.Title) + "\n")
+				codexText.WriteString(wrapText(e.Text, m.chatViewport.Width-6) + "\n")
+			}
+		}
+		codexText.WriteString("\n")
+
+		currentContent := m.chatViewport.View()
+		m.chatViewport.SetContent(currentContent + codexText.String())
+		m.chatViewport.GotoBottom()
+
+	case "/save", "/saves", "/load":
+		if m.gameState == nil {
+			break
+		}
+		var saveText strings.Builder
+		switch {
+		case cmd != "/saves" && arg == "":
+			saveText.WriteString(errorStyle.Render("Usage: "+cmd+" <name>") + "\n")
+		case cmd == "/save":
+			if snap, err := saveSnapshot(m.client, m.config.APIBaseURL, m.gameState.ID, arg); err != nil {
+				saveText.WriteString(errorStyle.Render("Error: "+err.Error()) + "\n")
+			} else {
+				saveText.WriteString(narratorStyle.Render(fmt.Sprintf("✓ Game saved as %q (turn %d)", snap.Name, snap.Turn)) + "\n")
+			}
+		case cmd == "/load":
+			restored, err := restoreSnapshot(m.client, m.config.APIBaseURL, m.gameState.ID, arg)
+			if err != nil {
+				saveText.WriteString(errorStyle.Render("Error: "+err.Error()) + "\n")
+				break
+			}
+			m.gameState = restored
+			m.pendingUserMessages = nil
+			m.choices = nil
+			m.writeChatContent()
+			saveText.WriteString(narratorStyle.Render(fmt.Sprintf("✓ Game loaded from %q", arg)) + "\n")
+		default:
+			saveText.WriteString(titleStyle.Render("Saved Games:") + "\n")
+			snapshots, err := listSnapshots(m.client, m.config.APIBaseURL, m.gameState.ID)
+			switch {
+			case err != nil:
+				saveText.WriteString(errorStyle.Render("Error: "+err.Error()) + "\n")
+			case len(snapshots) == 0:
+				saveText.WriteString("No saved games yet. Save with /save <name>.\n")
+			default:
+				for _, s := range snapshots {
+					fmt.Fprintf(&saveText, "• %s (turn %d, %s)\n", s.Name, s.Turn, s.CreatedAt.Local().Format("Jan 2 15:04"))
+				}
+			}
+		}
+		saveText.WriteString("\n")
+
+		currentContent := m.chatViewport.View()
+		m.chatViewport.SetContent(currentContent + saveText.String())
+		m.chatViewport.GotoBottom()
+
+	case "/continue":
+		if m.gameState == nil || m.loading || m.isStreaming {
+			break
+		}
+		m.textarea.Reset()
+		m.loading = true
+		m.progressTick = 0
+		m.userPinned = false
+		m.choices = nil
+		m.chatRequestStartTime = time.Now()
+		return m, tea.Batch(m.continueNarration(), progressTick())
+
+	case "/keys":
+		var keysText strings.Builder
+		keysText.WriteString(titleStyle.Render("Key Bindings:") + "\n")
+		for _, line := range m.config.Keys.keyHelp() {
+			keysText.WriteString(wrapText("• "+line, m.chatViewport.Width-6) + "\n")
+		}
+		keysText.WriteString(promptStyle.Render("Remap with a JSON key config: -keys file.json or CONSOLE_KEYS") + "\n\n")
+
+		currentContent := m.chatViewport.View()
+		m.chatViewport.SetContent(currentContent + keysText.String())
+		m.chatViewport.GotoBottom()
 	}
 
 	m.textarea.Reset()
@@ -1239,6 +1551,15 @@ func (m ConsoleUI) sendChatMessage(message string) tea.Cmd {
 		return nil
 	}
 }
+func (m ConsoleUI) continueNarration() tea.Cmd {
+	return func() tea.Msg {
+		if _, err := continueNarration(m.client, m.config.APIBaseURL, m.gameState.ID); err != nil {
+			return chatErrorMsg{err: fmt.Errorf("failed to continue narration: %w", err)}
+		}
+		return nil
+	}
+}
+
 func (m ConsoleUI) refreshGameState() tea.Cmd {
 	return func() tea.Msg {
 		gs, err := getGameState(m.client, m.config.APIBaseURL, m.gameState.ID)
@@ -1259,8 +1580,8 @@ func (m ConsoleUI) consumeSSEEvents(events <-chan SSEEvent) tea.Cmd {
 
 func (m ConsoleUI) loadScenarios() tea.Cmd {
 	return func() tea.Msg {
-		orderedNames, scenarioMap, err := listScenarios(m.client, m.config.APIBaseURL)
-		return scenariosLoadedMsg{orderedNames, scenarioMap, err}
+		orderedNames, scenarioMap, details, err := listScenarios(m.client, m.config.APIBaseURL)
+		return scenariosLoadedMsg{orderedNames, scenarioMap, details, err}
 	}
 }
 
@@ -1291,6 +1612,7 @@ func (m ConsoleUI) updateScenarioModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.scenarios = msg.scenarios
 			m.scenarioMap = msg.scenarioMap
+			m.scenarioDetails = msg.details
 		}
 
 	case tea.KeyMsg:
@@ -1323,12 +1645,26 @@ func (m ConsoleUI) updateScenarioModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.selectedScenario--
 			}
 		case tea.KeyDown:
-			if m.selectedScenario < len(m.scenarios)-1 {
+			filtered := filterScenarios(m.scenarios, m.scenarioDetails, m.scenarioQuery, m.ratingFilter, m.tagFilter)
+			if m.selectedScenario < len(filtered)-1 {
 				m.selectedScenario++
 			}
+		case tea.KeyTab:
+			m.ratingFilter = nextFilter(ratingFilters, m.ratingFilter)
+			m.selectedScenario = 0
+		case tea.KeyShiftTab:
+			m.tagFilter = nextFilter(append([]string{""}, scenarioTags(m.scenarioDetails)...), m.tagFilter)
+			m.selectedScenario = 0
+		case tea.KeyBackspace:
+			if r := []rune(m.scenarioQuery); len(r) > 0 {
+				m.scenarioQuery = string(r[:len(r)-1])
+				m.selectedScenario = 0
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.scenarioQuery += string(msg.Runes)
+			m.selectedScenario = 0
 		case tea.KeyEnter:
-			if len(m.scenarios) > 0 {
-				scenarioName := m.scenarios[m.selectedScenario]
+			if scenarioName, ok := m.selectedScenarioName(); ok {
 				scenarioFile := m.scenarioMap[scenarioName]
 				// First fetch scenario details to get the content rating
 				s, err := getScenario(m.client, m.config.APIBaseURL, scenarioFile)
@@ -1389,7 +1725,7 @@ func (m ConsoleUI) updatePCModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			// Use display name instead of raw file name
 			m.chatViewport.SetContent(writeInitialContent(m.gameState, m.scenarioDisplayName(), m.chatViewport.Width-6))
-			m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies))
+			m.metaViewport.SetContent(writeSidebar(m.gameState, m.metaViewport.Width, m.scenarioDisplayName(), m.pollingActive, m.chatLatencies, m.config.Keys))
 			m.textarea.Focus() // Ensure textarea gets focus when modal closes
 			m.ready = true
 
@@ -1398,9 +1734,9 @@ func (m ConsoleUI) updatePCModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.eventChan = eventChan
 			go func() {
 				ctx := context.Background()
-				// listenToSSE blocks until connection closes or error occurs
+				// followSSE blocks until the connection can't be restored
 				// When it returns, just close the channel gracefully
-				_ = listenToSSE(ctx, m.client, m.config.APIBaseURL, m.gameState.ID, eventChan)
+				_ = followSSE(ctx, m.client, m.config.APIBaseURL, m.gameState.ID, eventChan)
 				close(eventChan)
 			}()
 			return m, tea.Batch(textarea.Blink, m.consumeSSEEvents(eventChan))
@@ -1535,6 +1871,10 @@ func (m *ConsoleUI) startNewGame() (tea.Model, tea.Cmd) {
 	m.loadingScenarios = true
 	m.scenarios = nil
 	m.scenarioMap = nil
+	m.scenarioDetails = nil
+	m.scenarioQuery = ""
+	m.ratingFilter = ""
+	m.tagFilter = ""
 	m.selectedScenario = 0
 	// Reset PC selection state
 	m.showPCModal = false
@@ -1545,16 +1885,18 @@ func (m *ConsoleUI) startNewGame() (tea.Model, tea.Cmd) {
 	m.selectedScenarioFile = ""
 	m.defaultPCID = ""
 	// Reset polling state
-	m.pollSeq = 0
+	// pollSeq keeps counting, so a cancelled poll's result can't match a new poll
+	m.cancelPoll()
 	m.activePollSeq = 0
-	m.pollInFlight = false
 	m.pollingActive = false
 	m.pollingStartedAt = time.Time{}
+	m.stateUpdatedAt = time.Time{}
 	m.finalMessageSent = false
 	// Reset latency tracking
 	m.lastChatLatency = 0
 	m.chatLatencies = nil
 	m.chatRequestStartTime = time.Time{}
+	m.choices = nil
 	m.err = nil // Clear any stale errors when starting new game
 	return m, m.loadScenarios()
 }
@@ -1616,18 +1958,30 @@ func (m ConsoleUI) renderScenarioModal() string {
 	} else {
 		content.WriteString(modalTitleStyle.Render("Select a Scenario"))
 		content.WriteString("\n\n")
+		content.WriteString(metaStyle.Render("Search: ") + modalItemStyle.Render(m.scenarioQuery+"▏") + "\n")
+		content.WriteString(promptStyle.Render(m.filterSummary()) + "\n\n")
 
-		for i, scenario := range m.scenarios {
+		filtered := filterScenarios(m.scenarios, m.scenarioDetails, m.scenarioQuery, m.ratingFilter, m.tagFilter)
+		if len(filtered) == 0 {
+			content.WriteString(promptStyle.Render("No scenarios match.") + "\n")
+		}
+		for i, name := range filtered {
 			if i == m.selectedScenario {
-				content.WriteString(modalSelectedItemStyle.Render(fmt.Sprintf("▶ %s", scenario)))
+				content.WriteString(modalSelectedItemStyle.Render(fmt.Sprintf("▶ %s", name)))
 			} else {
-				content.WriteString(modalItemStyle.Render(fmt.Sprintf("  %s", scenario)))
+				content.WriteString(modalItemStyle.Render(fmt.Sprintf("  %s", name)))
 			}
 			content.WriteString("\n")
 		}
 
+		if name, ok := m.selectedScenarioName(); ok {
+			content.WriteString("\n" + separatorStyle.Render(strings.Repeat("─", 56)) + "\n")
+			entry, ok := m.scenarioDetails[name]
+			content.WriteString(renderScenarioDetail(entry, ok, 56))
+		}
+
 		content.WriteString("\n")
-		content.WriteString(promptStyle.Render("Use ↑/↓ to navigate, Enter to select, Ctrl+C to force quit"))
+		content.WriteString(promptStyle.Render("Type to search, Tab: rating, Shift+Tab: tag, ↑/↓ to navigate, Enter to select, Ctrl+C to force quit"))
 	}
 
 	// Create the modal
@@ -1759,31 +2113,3 @@ func progressTick() tea.Cmd {
 		return progressTickMsg{}
 	})
 }
-
-// schedulePoll returns a command that triggers a pollTickMsg after the interval
-func schedulePoll() tea.Cmd {
-	return tea.Tick(1*time.Second, func(time.Time) tea.Msg { return pollTickMsg{} })
-}
-
-// startPoll begins an HTTP fetch for the latest game state; old sequences are ignored
-func startPoll(seq int, client *http.Client, baseURL string, id uuid.UUID) tea.Cmd {
-	return func() tea.Msg {
-		resp, err := client.Get(fmt.Sprintf("%s/v1/gamestate/%s", baseURL, id))
-		if err != nil {
-			return pollResultMsg{seq: seq, gameState: nil, err: err}
-		}
-		defer func() { _ = resp.Body.Close() }()
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return pollResultMsg{seq: seq, gameState: nil, err: err}
-		}
-		if resp.StatusCode != http.StatusOK {
-			return pollResultMsg{seq: seq, gameState: nil, err: fmt.Errorf("poll status %d", resp.StatusCode)}
-		}
-		var gs state.GameState
-		if err := json.Unmarshal(body, &gs); err != nil {
-			return pollResultMsg{seq: seq, gameState: nil, err: err}
-		}
-		return pollResultMsg{seq: seq, gameState: &gs, err: nil}
-	}
-}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
+)
+
+// wrapText wraps text to the given display width. Widths are measured in terminal
+// cells rather than bytes, so wide runes (CJK, emoji) are counted correctly.
+// Words are wrapped at spaces and hyphens; any word still wider than the limit
+// (a URL, a long ID) is hard-wrapped so it can't overflow the panel.
+func wrapText(text string, width int) string {
+	text = normalizeNewlines(text)
+	if width <= 0 {
+		return text
+	}
+	return wrap.String(wordwrap.String(text, width), width)
+}
+
+// normalizeNewlines converts Windows (\r\n) and bare \r line endings to \n.
+// A stray \r moves the cursor to column zero and corrupts the layout.
+func normalizeNewlines(text string) string {
+	if !strings.Contains(text, "\r") {
+		return text
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// displayWidth returns the number of terminal cells s occupies
+func displayWidth(s string) int {
+	return runewidth.StringWidth(s)
+}
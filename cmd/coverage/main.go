@@ -0,0 +1,125 @@
+// Command coverage reports which of a scenario's scenes, conditionals, and story events
+// a set of recorded playthroughs reached
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func main() {
+	scenarioFile := flag.String("scenario", "", "scenario file the playthroughs were played with (required)")
+	format := flag.String("format", "text", "output format: text, or json for the full report")
+	minPercent := flag.Float64("min", 0, "exit 1 when conditional coverage is below this percent")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -scenario <scenario.json> [-format text|json] [-min percent] <playthrough.json | dir | dir/... | pattern>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *scenarioFile == "" || flag.NArg() == 0 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s, err := loadScenario(*scenarioFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
+	files, err := expandArgs(flag.Args())
+	if err == nil && len(files) == 0 {
+		err = fmt.Errorf("no playthrough files found in %s", strings.Join(flag.Args(), " "))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
+	// Playthroughs of other scenarios are skipped, so a whole directory of saves can be passed
+	var games []*state.GameState
+	for _, filename := range files {
+		gs, err := loadGame(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(2)
+		}
+		if gs.Scenario != s.FileName {
+			fmt.Fprintf(os.Stderr, "Skipping %s: played with %s\n", filename, gs.Scenario)
+			continue
+		}
+		games = append(games, gs)
+	}
+	if len(games) == 0 {
+		fmt.Fprintf(os.Stderr, "No playthroughs of %s found\n", s.FileName)
+		os.Exit(2)
+	}
+
+	cov := state.NewCoverage(s, games...)
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cov); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			os.Exit(2)
+		}
+	} else {
+		writeText(os.Stdout, s, cov)
+	}
+
+	if cov.Conditionals.Percent() < *minPercent {
+		fmt.Fprintf(os.Stderr, "Conditional coverage %.1f%% is below the minimum %.1f%%\n", cov.Conditionals.Percent(), *minPercent)
+		os.Exit(1)
+	}
+}
+
+// loadScenario reads a scenario file the way storage does, normalizing its keys
+func loadScenario(filename string) (*scenario.Scenario, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario %s: %w", filename, err)
+	}
+	var s scenario.Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", filename, err)
+	}
+	s.Normalize()
+	s.FileName = filepath.Base(filename)
+	return &s, nil
+}
+
+// loadGame reads a playthrough: a save file from GET /v1/gamestate/{id}/export, or a
+// game state as returned by GET /v1/gamestate/{id}
+func loadGame(filename string) (*state.GameState, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playthrough %s: %w", filename, err)
+	}
+
+	var save state.SaveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, fmt.Errorf("failed to parse playthrough %s: %w", filename, err)
+	}
+	if save.GameState != nil {
+		if err := save.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid save file %s: %w", filename, err)
+		}
+		return save.GameState, nil
+	}
+
+	var gs state.GameState
+	if err := json.Unmarshal(data, &gs); err != nil {
+		return nil, fmt.Errorf("failed to parse playthrough %s: %w", filename, err)
+	}
+	if gs.Scenario == "" {
+		return nil, fmt.Errorf("%s is not a save file or game state: it has no scenario", filename)
+	}
+	return &gs, nil
+}
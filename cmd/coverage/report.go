@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// expandArgs turns the command's arguments into playthrough files. An argument can be a
+// file, a directory (its .json files), a directory followed by /... (its .json files
+// at any depth), or a glob pattern.
+func expandArgs(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if dir, ok := strings.CutSuffix(arg, "/..."); ok {
+			err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && filepath.Ext(path) == ".json" {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk %s: %w", dir, err)
+			}
+			continue
+		}
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			matches, err := filepath.Glob(filepath.Join(arg, "*.json"))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+			continue
+		}
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", arg)
+			}
+			files = append(files, matches...)
+			continue
+		}
+		files = append(files, arg)
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// writeText prints the coverage of each kind of content, then everything never hit
+func writeText(out io.Writer, s *scenario.Scenario, cov *state.Coverage) {
+	kinds := []struct {
+		name string
+		kind state.CoverageKind
+	}{
+		{"Scenes", cov.Scenes},
+		{"Conditionals", cov.Conditionals},
+		{"Story events", cov.StoryEvents},
+	}
+
+	plural := "s"
+	if cov.Games == 1 {
+		plural = ""
+	}
+	fmt.Fprintf(out, "Coverage of %s by %d playthrough%s\n\n", s.FileName, cov.Games, plural)
+	for _, k := range kinds {
+		fmt.Fprintf(out, "  %-14s %d/%d (%.1f%%)\n", k.name+":", k.kind.Hit, k.kind.Total, k.kind.Percent())
+	}
+
+	var missed strings.Builder
+	for _, k := range kinds {
+		ids := k.kind.Missed()
+		if len(ids) == 0 {
+			continue
+		}
+		fmt.Fprintf(&missed, "  %s:\n", k.name)
+		for _, id := range ids {
+			fmt.Fprintf(&missed, "    - %s\n", id)
+		}
+	}
+	if missed.Len() == 0 {
+		fmt.Fprintln(out, "\nEvery scene, conditional, and story event was hit.")
+		return
+	}
+	fmt.Fprintf(out, "\nNever hit:\n%s", missed.String())
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/integration/runner"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// Eval runs the case battery against each model through the API, scoring every turn
+type Eval struct {
+	cfg    *EvalConfig
+	client *http.Client
+	judge  *Judge
+	logger *slog.Logger
+	scores []StepScore
+}
+
+// StepScore is one scored turn of one case, played by one model
+type StepScore struct {
+	Model       string
+	Case        string
+	Step        string
+	DeltaPassed int      // State expectations the delta met
+	DeltaTotal  int      // State expectations checked
+	Misses      []string // State expectations the delta missed
+	Narration   int      // Judge's score from 1 to 5; 0 when not judged
+	Reason      string   // Judge's reason for the score
+	Duration    time.Duration
+	Err         string
+}
+
+// RunCase seeds a game for the case on the given model and plays its steps in order.
+// Resets aren't scored. A failed step is recorded and the case continues.
+func (ev *Eval) RunCase(ctx context.Context, model string, job runner.TestJob) {
+	r := runner.NewRunner(ev.cfg.APIBaseURL)
+	r.Client = ev.client
+	r.ModelOverride = model
+
+	seed := job.Suite.SeedGameState
+	seed.Scenario = job.Suite.Scenario
+	gameStateID, err := r.SeedGameState(ctx, seed)
+	if err != nil {
+		ev.scores = append(ev.scores, StepScore{Model: model, Case: job.Name, Step: "seed", Err: err.Error()})
+		return
+	}
+
+	for _, step := range job.Suite.Steps {
+		if step.UserPrompt == runner.ResetGameStatePrompt {
+			if err := r.ResetGameState(ctx, gameStateID, &job.Suite.SeedGameState); err != nil {
+				ev.scores = append(ev.scores, StepScore{Model: model, Case: job.Name, Step: step.Name, Err: err.Error()})
+				return
+			}
+			continue
+		}
+		score := ev.playStep(ctx, gameStateID, step)
+		score.Model, score.Case, score.Step = model, job.Name, step.Name
+		ev.scores = append(ev.scores, score)
+	}
+}
+
+// playStep sends the step's prompt, or waits for a story event, then scores the delta
+// against the step's expectations and the narration with the judge
+func (ev *Eval) playStep(ctx context.Context, gameStateID uuid.UUID, step runner.TestStep) (score StepScore) {
+	start := time.Now()
+	defer func() { score.Duration = time.Since(start) }()
+
+	pre, err := runner.GetGameState(ctx, ev.client, ev.cfg.APIBaseURL, gameStateID)
+	if err != nil {
+		score.Err = err.Error()
+		return score
+	}
+
+	action := step.UserPrompt
+	if step.UserPrompt == runner.WaitForStoryEventPrompt {
+		action = "(no action; a story event is due)"
+	} else if _, err := runner.PostChatAsync(ctx, ev.client, ev.cfg.APIBaseURL, gameStateID, step.UserPrompt); err != nil {
+		score.Err = err.Error()
+		return score
+	}
+
+	afterChat, narration, err := runner.PollForChatResponse(ctx, ev.client, ev.cfg.APIBaseURL, gameStateID, len(pre.ChatHistory))
+	if err != nil {
+		score.Err = err.Error()
+		return score
+	}
+	post, err := runner.PollForDeltaWorkerCompletion(ctx, ev.client, ev.cfg.APIBaseURL, gameStateID, afterChat)
+	if err != nil {
+		// Score what the delta managed before the timeout
+		score.Err = err.Error()
+		post = afterChat
+	}
+
+	score.DeltaPassed, score.DeltaTotal, score.Misses = scoreDelta(step.Expectations, post)
+	score.Narration, score.Reason, err = ev.judge.Score(ctx, pre, action, narration)
+	if err != nil {
+		ev.logger.Warn("Failed to judge narration", "error", err, "game_state_id", post.ID.String())
+	}
+	return score
+}
+
+// scoreDelta counts the step's state expectations that the game state meets. Each field,
+// variable and NPC location is one check; the inventory is one check, order independent.
+// Narration expectations such as response_contains are left to the judge.
+func scoreDelta(exp runner.Expectations, gs *state.GameState) (passed, total int, misses []string) {
+	check := func(ok bool, format string, args ...any) {
+		total++
+		if ok {
+			passed++
+			return
+		}
+		misses = append(misses, fmt.Sprintf(format, args...))
+	}
+
+	if exp.Location != nil {
+		check(gs.Location == *exp.Location, "location %q, got %q", *exp.Location, gs.Location)
+	}
+	if exp.SceneName != nil {
+		check(gs.SceneName == *exp.SceneName, "scene %q, got %q", *exp.SceneName, gs.SceneName)
+	}
+	if len(exp.Inventory) > 0 {
+		expected, actual := gs.ItemIDs(exp.Inventory), slices.Clone(gs.Inventory)
+		slices.Sort(expected)
+		slices.Sort(actual)
+		check(slices.Equal(slices.Compact(expected), slices.Compact(actual)), "inventory %v, got %v", exp.Inventory, gs.Inventory)
+	}
+	for _, key := range slices.Sorted(maps.Keys(exp.Vars)) {
+		check(gs.Vars[key] == exp.Vars[key], "var %s=%q, got %q", key, exp.Vars[key], gs.Vars[key])
+	}
+	for _, name := range slices.Sorted(maps.Keys(exp.NPCLocations)) {
+		var location string
+		if npc, ok := gs.NPCs[name]; ok {
+			location = npc.Location
+		}
+		check(location == exp.NPCLocations[name], "NPC %s at %q, got %q", name, exp.NPCLocations[name], location)
+	}
+	if exp.TurnCounter != nil {
+		check(gs.TurnCounter == *exp.TurnCounter, "turn_counter %d, got %d", *exp.TurnCounter, gs.TurnCounter)
+	}
+	if exp.SceneTurnCounter != nil {
+		check(gs.SceneTurnCounter == *exp.SceneTurnCounter, "scene_turn_counter %d, got %d", *exp.SceneTurnCounter, gs.SceneTurnCounter)
+	}
+	if exp.IsEnded != nil {
+		check(gs.IsEnded == *exp.IsEnded, "is_ended %t, got %t", *exp.IsEnded, gs.IsEnded)
+	}
+	return passed, total, misses
+}
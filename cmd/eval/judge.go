@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+const judgeSystemPrompt = `You are judging the narrator of an interactive text adventure. Score one narration from 1 to 5:
+
+5 - Answers the player's action directly, stays true to the story so far and the player's situation, and reads well.
+4 - Good, with a small lapse in focus, continuity, or prose.
+3 - Acceptable, but vague, padded, or loosely connected to the action.
+2 - Ignores much of the action, or contradicts the story or the player's situation.
+1 - Off-topic, incoherent, or takes the player's turn for them.
+
+Reply with only JSON: {"score": <1-5>, "reason": "<one sentence>"}`
+
+// Judge scores narration quality with an LLM
+type Judge struct {
+	llm services.LLMService
+}
+
+// Score asks the judge model to rate a narration of the player's action, given the game
+// state before the turn. Returns a score from 1 to 5 and the judge's reason.
+func (j *Judge) Score(ctx context.Context, before *state.GameState, action, narration string) (int, string, error) {
+	inventory := "empty"
+	if len(before.Inventory) > 0 {
+		inventory = strings.Join(before.DisplayItems(before.Inventory), ", ")
+	}
+	var previous string
+	for _, msg := range before.ChatHistory {
+		if msg.Role == chat.ChatRoleAgent {
+			previous = msg.Content
+		}
+	}
+
+	messages := []chat.ChatMessage{
+		{
+			Role:    chat.ChatRoleSystem,
+			Content: judgeSystemPrompt,
+		},
+		{
+			Role: chat.ChatRoleUser,
+			Content: fmt.Sprintf("Player's location: %s\nPlayer's inventory: %s\n\nPrevious narration:\n%s\n\nPlayer's action:\n%s\n\nNarration to score:\n%s",
+				before.Location, inventory, previous, action, narration),
+		},
+	}
+
+	resp, err := j.llm.Chat(ctx, messages, 0.0)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get judge score: %w", err)
+	}
+	return parseJudgeScore(resp.Message)
+}
+
+// parseJudgeScore reads the judge's JSON reply, ignoring any text around it
+func parseJudgeScore(reply string) (int, string, error) {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return 0, "", fmt.Errorf("judge reply has no JSON: %q", reply)
+	}
+	var verdict struct {
+		Score  int    `json:"score"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &verdict); err != nil {
+		return 0, "", fmt.Errorf("failed to parse judge reply: %w", err)
+	}
+	if verdict.Score < 1 || verdict.Score > 5 {
+		return 0, "", fmt.Errorf("judge score %d is out of range", verdict.Score)
+	}
+	return verdict.Score, verdict.Reason, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jwebster45206/story-engine/integration/runner"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/logger"
+	"github.com/jwebster45206/story-engine/internal/services"
+)
+
+type EvalConfig struct {
+	APIBaseURL string
+	APIKey     string   // Optional key selecting the server profile, sent as X-API-Key
+	Cases      string   // Case or sequence file from integration/cases
+	Models     []string // Models to compare; each must be available on the server
+	Judge      string   // Model that scores narration quality
+	Out        string   // File for the markdown report; stdout when empty
+}
+
+func main() {
+	cfg := &EvalConfig{
+		APIBaseURL: getEnv("API_BASE_URL", "http://localhost:8080"),
+		APIKey:     getEnv("API_KEY", ""),
+	}
+	var models string
+	flag.StringVar(&models, "models", "", "comma-separated models to compare, e.g. claude-sonnet-4-5,llama-3.3-70b (required)")
+	flag.StringVar(&cfg.Cases, "cases", "integration/cases/integ_all.json", "case or sequence file to run against each model")
+	flag.StringVar(&cfg.Judge, "judge", "", "model that scores narration quality (default: the config's backend model, or its model)")
+	flag.StringVar(&cfg.Out, "out", "", "file to write the markdown report (default: print to stdout)")
+	flag.Parse()
+
+	for _, m := range strings.Split(models, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			cfg.Models = append(cfg.Models, m)
+		}
+	}
+	if len(cfg.Models) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// The judge model comes from the same config file as the API and worker
+	gameCfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	log := logger.Setup(gameCfg)
+
+	if cfg.Judge == "" {
+		cfg.Judge = gameCfg.BackendModelName
+	}
+	if cfg.Judge == "" {
+		cfg.Judge = gameCfg.ModelName
+	}
+	judgeLLM, err := services.NewLLMService(gameCfg, gameCfg.LLMProvider, cfg.Judge, "", log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating LLM service: %v\n", err)
+		os.Exit(1)
+	}
+
+	jobs, err := runner.LoadTestSuiteWithExpansion(cfg.Cases, filepath.Dir(cfg.Cases))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading cases: %v\n", err)
+		os.Exit(1)
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	if cfg.APIKey != "" {
+		client.Transport = &apiKeyTransport{key: cfg.APIKey, next: http.DefaultTransport}
+	}
+
+	ev := &Eval{
+		cfg:    cfg,
+		client: client,
+		judge:  &Judge{llm: judgeLLM},
+		logger: log,
+	}
+
+	ctx := context.Background()
+	for _, model := range cfg.Models {
+		for _, job := range jobs {
+			log.Info("Evaluating case", "model", model, "case", job.Name)
+			ev.RunCase(ctx, model, job)
+		}
+	}
+
+	report := ev.Report()
+	if cfg.Out == "" {
+		fmt.Println(report)
+		return
+	}
+	if err := os.WriteFile(cfg.Out, []byte(report), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", cfg.Out, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote report to %s\n", cfg.Out)
+}
+
+// apiKeyTransport adds the X-API-Key header to every request
+type apiKeyTransport struct {
+	key  string
+	next http.RoundTripper
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-API-Key", t.key)
+	return t.next.RoundTrip(req)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
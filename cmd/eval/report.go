@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jwebster45206/story-engine/integration/runner"
+)
+
+// modelTotals sums one model's scores, over all cases or one case
+type modelTotals struct {
+	steps       int
+	deltaPassed int
+	deltaTotal  int
+	judged      int
+	narration   int
+	errors      int
+	durations   []time.Duration
+}
+
+func (t *modelTotals) add(s StepScore) {
+	t.steps++
+	t.deltaPassed += s.DeltaPassed
+	t.deltaTotal += s.DeltaTotal
+	if s.Narration > 0 {
+		t.judged++
+		t.narration += s.Narration
+	}
+	if s.Err != "" {
+		t.errors++
+	}
+	if s.Duration > 0 {
+		t.durations = append(t.durations, s.Duration)
+	}
+}
+
+func (t *modelTotals) deltaAccuracy() string {
+	if t.deltaTotal == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%% (%d/%d)", 100*float64(t.deltaPassed)/float64(t.deltaTotal), t.deltaPassed, t.deltaTotal)
+}
+
+func (t *modelTotals) narrationScore() string {
+	if t.judged == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", float64(t.narration)/float64(t.judged))
+}
+
+// Report renders the comparison table, a per-case breakdown, and every miss and error
+func (ev *Eval) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Model Evaluation: %s\n\n", ev.cfg.Cases)
+	fmt.Fprintf(&b, "- **Models:** %s\n", strings.Join(ev.cfg.Models, ", "))
+	fmt.Fprintf(&b, "- **Judge:** %s\n\n", ev.cfg.Judge)
+
+	totals := make(map[string]*modelTotals)
+	byCase := make(map[string]map[string]*modelTotals)
+	var cases []string
+	for _, s := range ev.scores {
+		if totals[s.Model] == nil {
+			totals[s.Model] = &modelTotals{}
+		}
+		totals[s.Model].add(s)
+		if byCase[s.Case] == nil {
+			byCase[s.Case] = make(map[string]*modelTotals)
+			cases = append(cases, s.Case)
+		}
+		if byCase[s.Case][s.Model] == nil {
+			byCase[s.Case][s.Model] = &modelTotals{}
+		}
+		byCase[s.Case][s.Model].add(s)
+	}
+
+	b.WriteString("## Summary\n\n")
+	b.WriteString("| Model | Steps | Delta accuracy | Narration (1-5) | Errors | Median step |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, model := range ev.cfg.Models {
+		t := totals[model]
+		if t == nil {
+			t = &modelTotals{}
+		}
+		median := runner.NewLatencyStats(t.durations).P50.Round(time.Millisecond)
+		fmt.Fprintf(&b, "| %s | %d | %s | %s | %d | %v |\n", model, t.steps, t.deltaAccuracy(), t.narrationScore(), t.errors, median)
+	}
+
+	b.WriteString("\n## Delta Accuracy by Case\n\n")
+	fmt.Fprintf(&b, "| Case | %s |\n", strings.Join(ev.cfg.Models, " | "))
+	fmt.Fprintf(&b, "|---|%s\n", strings.Repeat("---|", len(ev.cfg.Models)))
+	for _, c := range cases {
+		row := make([]string, len(ev.cfg.Models))
+		for i, model := range ev.cfg.Models {
+			row[i] = "-"
+			if t := byCase[c][model]; t != nil {
+				row[i] = t.deltaAccuracy()
+			}
+		}
+		fmt.Fprintf(&b, "| %s | %s |\n", c, strings.Join(row, " | "))
+	}
+
+	b.WriteString("\n## Misses\n\n")
+	var misses int
+	for _, s := range ev.scores {
+		if len(s.Misses) == 0 && s.Err == "" && (s.Narration == 0 || s.Narration > 2) {
+			continue
+		}
+		misses++
+		fmt.Fprintf(&b, "- **%s** %s / %s:", s.Model, s.Case, s.Step)
+		if len(s.Misses) > 0 {
+			fmt.Fprintf(&b, " expected %s.", strings.Join(s.Misses, "; "))
+		}
+		if s.Narration > 0 && s.Narration <= 2 {
+			fmt.Fprintf(&b, " Narration scored %d: %s", s.Narration, s.Reason)
+		}
+		if s.Err != "" {
+			fmt.Fprintf(&b, " Error: %s", s.Err)
+		}
+		b.WriteString("\n")
+	}
+	if misses == 0 {
+		b.WriteString("None.\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jwebster45206/story-engine/internal/storage"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen()
+	case "build":
+		err = build(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "  keygen    Create an Ed25519 key pair for signing manifests")
+	fmt.Fprintln(os.Stderr, "  build     Write a checksum manifest of the content files")
+	fmt.Fprintln(os.Stderr, "  verify    Check the content files against a manifest")
+}
+
+// keygen prints a new signing key pair, base64-encoded
+func keygen() error {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	fmt.Printf("MANIFEST_SIGNING_KEY=%s\n", base64.StdEncoding.EncodeToString(privateKey))
+	fmt.Printf("public_key: %s\n", base64.StdEncoding.EncodeToString(publicKey))
+	return nil
+}
+
+// build checksums the content files and writes the manifest, signed when
+// MANIFEST_SIGNING_KEY is set
+func build(args []string) error {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	dataDir := fs.String("data", "./data", "data directory holding the content files")
+	out := fs.String("out", "./data/manifest.json", "manifest file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	manifest, err := storage.BuildContentManifest(*dataDir)
+	if err != nil {
+		return err
+	}
+	signed := false
+	if encoded := os.Getenv("MANIFEST_SIGNING_KEY"); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != ed25519.PrivateKeySize {
+			return fmt.Errorf("MANIFEST_SIGNING_KEY must be a base64-encoded %d-byte Ed25519 private key", ed25519.PrivateKeySize)
+		}
+		if err := manifest.Sign(ed25519.PrivateKey(key)); err != nil {
+			return err
+		}
+		signed = true
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if signed {
+		fmt.Printf("Wrote signed manifest of %d files to %s\n", len(manifest.Files), *out)
+	} else {
+		fmt.Printf("Wrote unsigned manifest of %d files to %s\n", len(manifest.Files), *out)
+	}
+	return nil
+}
+
+// verify checks the manifest's signature, when a public key is given, and every content file
+func verify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	dataDir := fs.String("data", "./data", "data directory holding the content files")
+	path := fs.String("manifest", "./data/manifest.json", "manifest file to check against")
+	encoded := fs.String("public-key", "", "base64-encoded Ed25519 public key the manifest must be signed with")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var publicKey ed25519.PublicKey
+	if *encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(*encoded)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("-public-key must be a base64-encoded %d-byte Ed25519 public key", ed25519.PublicKeySize)
+		}
+		publicKey = key
+	}
+
+	manifest, err := storage.LoadContentManifest(*path, publicKey)
+	if err != nil {
+		return err
+	}
+	problems := manifest.CheckDir(*dataDir)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d content files failed verification", len(problems))
+	}
+	fmt.Printf("All %d content files match the manifest\n", len(manifest.Files))
+	return nil
+}
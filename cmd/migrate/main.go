@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func main() {
+	scenarioFile := flag.String("scenario", "", "scenario file the save was played with, at its current version (required to migrate a save)")
+	backfill := flag.Bool("backfill-ids", false, "write out the stable ids of a scenario's locations, NPCs, and items, instead of migrating a save")
+	out := flag.String("out", "", "file to write the migrated save or scenario to (default: standard output)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -scenario <scenario.json> [-out <file>] <save.json>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s -backfill-ids [-out <file>] <scenario.json>\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || (*scenarioFile == "") != *backfill {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var err error
+	if *backfill {
+		err = backfillIDs(flag.Arg(0), *out)
+	} else {
+		err = migrate(flag.Arg(0), *scenarioFile, *out)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// migrate rewrites an exported save file's references to locations, NPCs, and items as
+// the scenario's current IDs, following anything it has renamed since, then checks the
+// result against the scenario
+func migrate(saveFile, scenarioFile, out string) error {
+	var save state.SaveFile
+	if err := readJSON(saveFile, &save); err != nil {
+		return err
+	}
+	if err := save.Validate(); err != nil {
+		return err
+	}
+	var s scenario.Scenario
+	if err := readJSON(scenarioFile, &s); err != nil {
+		return err
+	}
+	for _, c := range s.Normalize() {
+		fmt.Fprintf(os.Stderr, "Warning: %ss %v share the key or name '%s'\n", c.Kind, c.Keys, c.Key)
+	}
+
+	if save.GameState.MigrateIDs(&s) {
+		fmt.Fprintln(os.Stderr, "Migrated references to current IDs")
+	} else {
+		fmt.Fprintln(os.Stderr, "Nothing to migrate")
+	}
+	save.Scenario.Name = s.Name
+	save.Scenario.Version = s.Version
+
+	fieldErrors := save.GameState.ValidateAgainstScenario(&s)
+	for _, fe := range fieldErrors {
+		fmt.Fprintf(os.Stderr, "  - %s\n", fe.Error())
+	}
+
+	if err := writeJSON(out, save); err != nil {
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+	if len(fieldErrors) > 0 {
+		return fmt.Errorf("migrated save still doesn't match the scenario (%d problems); it won't import", len(fieldErrors))
+	}
+	return nil
+}
+
+// backfillIDs writes out the ids a scenario's locations, NPCs, and items have implicitly
+// (see scenario.Scenario.BackfillIDs), so the scenario can rename them from then on
+// without a renamed table
+func backfillIDs(scenarioFile, out string) error {
+	var s scenario.Scenario
+	if err := readJSON(scenarioFile, &s); err != nil {
+		return err
+	}
+	for _, names := range s.BackfillIDs() {
+		fmt.Fprintf(os.Stderr, "Warning: item %s not catalogued; its id is taken, or it differs from another only in case or punctuation\n", strings.Join(names, ", "))
+	}
+	fmt.Fprintf(os.Stderr, "Backfilled ids: %d locations, %d NPCs, and %d catalogued items\n", len(s.Locations), len(s.NPCs), len(s.ItemCatalog))
+	if err := writeJSON(out, s); err != nil {
+		return fmt.Errorf("failed to write scenario: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes v as indented JSON to the file, or to standard output if it's empty
+func writeJSON(filename string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if filename == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(filename, data, 0o644)
+}
+
+func readJSON(filename string, v any) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return nil
+}
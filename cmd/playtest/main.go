@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/integration/runner"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/logger"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+type PlaytestConfig struct {
+	APIBaseURL string
+	APIKey     string // Optional key selecting the server profile, sent as X-API-Key
+	Scenario   string // Scenario filename, e.g. "pirate.json"
+	Turns      int    // Maximum turns to play
+	Goal       string // Goal preset name or free-text goal for the player
+	OutDir     string // Directory for the transcript and report; stdout when empty
+	StallTurns int    // Turns without any state change before a dead-end is reported
+}
+
+func main() {
+	cfg := &PlaytestConfig{
+		APIBaseURL: getEnv("API_BASE_URL", "http://localhost:8080"),
+		APIKey:     getEnv("API_KEY", ""),
+	}
+	flag.StringVar(&cfg.Scenario, "scenario", "", "scenario filename to play, e.g. pirate.json (required)")
+	flag.IntVar(&cfg.Turns, "turns", 20, "maximum number of turns to play")
+	flag.StringVar(&cfg.Goal, "goal", goalFinish, "player goal: "+strings.Join(goalNames(), ", ")+", or free text")
+	flag.StringVar(&cfg.OutDir, "out", "", "directory to write transcript.md, report.md, and save.json (default: print to stdout)")
+	flag.IntVar(&cfg.StallTurns, "stall", 5, "turns without any state change before reporting a dead-end")
+	flag.Parse()
+
+	if cfg.Scenario == "" || cfg.Turns < 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// The player model comes from the same config file as the API and worker
+	gameCfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	log := logger.Setup(gameCfg)
+
+	llmService, err := services.NewLLMService(gameCfg, gameCfg.LLMProvider, gameCfg.ModelName, gameCfg.BackendModelName, log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating LLM service: %v\n", err)
+		os.Exit(1)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	if cfg.APIKey != "" {
+		client.Transport = &apiKeyTransport{key: cfg.APIKey, next: http.DefaultTransport}
+	}
+
+	pt := &Playtest{
+		cfg:    cfg,
+		client: client,
+		player: &Player{llm: llmService, goal: goalPrompt(cfg.Goal)},
+		logger: log,
+	}
+
+	ctx := context.Background()
+	if err := pt.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Playtest failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	transcript, report := pt.Transcript(), pt.Report()
+	if cfg.OutDir == "" {
+		fmt.Println(transcript)
+		fmt.Println(report)
+		return
+	}
+	if err := os.MkdirAll(cfg.OutDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+	save, err := pt.SaveFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error building save file: %v\n", err)
+		os.Exit(1)
+	}
+	for name, content := range map[string]string{"transcript.md": transcript, "report.md": report, "save.json": save} {
+		if err := os.WriteFile(filepath.Join(cfg.OutDir, name), []byte(content), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Wrote transcript.md, report.md, and save.json to %s\n", cfg.OutDir)
+}
+
+// Playtest drives one game through the API, with the player's actions chosen by an LLM
+type Playtest struct {
+	cfg      *PlaytestConfig
+	client   *http.Client
+	player   *Player
+	logger   *slog.Logger
+	scenario *scenario.Scenario
+	start    *state.GameState // Game as created, before the first turn
+	gs       *state.GameState // Game as of the last turn
+	turns    []TurnRecord
+	errors   []string
+}
+
+// TurnRecord is one played turn, for the transcript and report
+type TurnRecord struct {
+	Turn      int
+	Action    string
+	Narration string
+	Scene     string
+	Location  string
+	Receipt   *state.TurnReceipt
+	Err       string
+}
+
+// maxConsecutiveErrors stops a playtest whose API or player model keeps failing
+const maxConsecutiveErrors = 3
+
+// Run creates a game and plays it until it ends, the turn limit is reached, or requests keep failing
+func (pt *Playtest) Run(ctx context.Context) error {
+	s, err := pt.getScenario(ctx)
+	if err != nil {
+		return err
+	}
+	pt.scenario = s
+
+	pt.start, err = pt.createGame(ctx)
+	if err != nil {
+		return err
+	}
+	pt.gs = pt.start
+	pt.logger.Info("Playtest started", "game_state_id", pt.gs.ID.String(), "scenario", pt.cfg.Scenario, "turns", pt.cfg.Turns)
+
+	consecutiveErrors := 0
+	for range pt.cfg.Turns {
+		if pt.gs.IsEnded {
+			break
+		}
+
+		record := pt.playTurn(ctx)
+		pt.turns = append(pt.turns, record)
+		if record.Err == "" {
+			consecutiveErrors = 0
+			continue
+		}
+
+		pt.errors = append(pt.errors, fmt.Sprintf("turn %d: %s", record.Turn, record.Err))
+		consecutiveErrors++
+		if consecutiveErrors >= maxConsecutiveErrors {
+			pt.errors = append(pt.errors, fmt.Sprintf("stopped after %d consecutive errors", consecutiveErrors))
+			break
+		}
+	}
+	return nil
+}
+
+// playTurn asks the player model for an action, sends it, and waits for the narration and the delta
+func (pt *Playtest) playTurn(ctx context.Context) TurnRecord {
+	record := TurnRecord{Turn: pt.gs.TurnCounter + 1}
+
+	action, err := pt.player.NextAction(ctx, pt.gs)
+	if err != nil {
+		record.Err = fmt.Sprintf("player model: %v", err)
+		return record
+	}
+	record.Action = action
+
+	historyLen := len(pt.gs.ChatHistory)
+	if _, err := runner.PostChatAsync(ctx, pt.client, pt.cfg.APIBaseURL, pt.gs.ID, action); err != nil {
+		record.Err = err.Error()
+		return record
+	}
+	afterChat, narration, err := runner.PollForChatResponse(ctx, pt.client, pt.cfg.APIBaseURL, pt.gs.ID, historyLen)
+	if err != nil {
+		record.Err = err.Error()
+		return record
+	}
+	record.Narration = narration
+	if strings.TrimSpace(narration) == "" {
+		record.Err = "empty narration"
+	}
+
+	gs, err := runner.PollForDeltaWorkerCompletion(ctx, pt.client, pt.cfg.APIBaseURL, pt.gs.ID, afterChat)
+	if err != nil {
+		// The narration arrived, so keep playing from it
+		pt.gs = afterChat
+		record.Err = err.Error()
+		return record
+	}
+	pt.gs = gs
+
+	record.Turn = gs.TurnCounter
+	record.Scene = gs.SceneName
+	record.Location = gs.Location
+	if receipt, ok := gs.GetTurnReceipt(gs.TurnCounter); ok {
+		record.Receipt = receipt
+	}
+	return record
+}
+
+func (pt *Playtest) getScenario(ctx context.Context) (*scenario.Scenario, error) {
+	var s scenario.Scenario
+	if err := pt.doJSON(ctx, http.MethodGet, "/v1/scenarios/"+pt.cfg.Scenario, nil, http.StatusOK, &s); err != nil {
+		return nil, fmt.Errorf("failed to load scenario: %w", err)
+	}
+	return &s, nil
+}
+
+func (pt *Playtest) createGame(ctx context.Context) (*state.GameState, error) {
+	var gs state.GameState
+	body := map[string]string{"scenario": pt.cfg.Scenario}
+	if err := pt.doJSON(ctx, http.MethodPost, "/v1/gamestate", body, http.StatusCreated, &gs); err != nil {
+		return nil, fmt.Errorf("failed to create game: %w", err)
+	}
+	if gs.ID == uuid.Nil {
+		return nil, fmt.Errorf("failed to create game: response has no game state ID")
+	}
+	return &gs, nil
+}
+
+// doJSON sends an optional JSON body and decodes the JSON response, which must have the expected status
+func (pt *Playtest) doJSON(ctx context.Context, method, path string, body any, expectedStatus int, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, pt.cfg.APIBaseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := pt.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != expectedStatus {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// apiKeyTransport adds the X-API-Key header to every request
+type apiKeyTransport struct {
+	key  string
+	next http.RoundTripper
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-API-Key", t.key)
+	return t.next.RoundTrip(req)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// Goal presets
+const (
+	goalFinish     = "finish"
+	goalBreakItems = "break-items"
+	goalExplore    = "explore"
+)
+
+var goalPrompts = map[string]string{
+	goalFinish:     "Try to reach the end of the story as directly as you can. Pursue the main objective and follow up on hints from the narrator.",
+	goalBreakItems: "Try to break the game's item logic. Use items you don't have, give the same item away twice, pick up scenery, drop things and pick them back up somewhere else, and trade with characters who have nothing to trade.",
+	goalExplore:    "Explore as much as you can. Visit every location, talk to every character, and examine everything the narrator mentions.",
+}
+
+// goalNames returns the goal presets in a stable order, for help text
+func goalNames() []string {
+	return slices.Sorted(maps.Keys(goalPrompts))
+}
+
+// goalPrompt expands a goal preset; any other text is used as the goal itself
+func goalPrompt(goal string) string {
+	if prompt, ok := goalPrompts[goal]; ok {
+		return prompt
+	}
+	return goal
+}
+
+// playerHistoryLimit is how many recent chat messages the player model sees
+const playerHistoryLimit = 20
+
+// playerTemperature keeps the player inventive without drifting off the goal
+const playerTemperature = 0.9
+
+const playerSystemPrompt = `You are playtesting an interactive text adventure. The narrator's messages are the story so far; your replies are the player's actions.
+
+Goal: %s
+
+Current state: you are at %s. Inventory: %s.
+
+Reply with only the player's next action, in the first person, in one or two sentences. Do not narrate outcomes, and do not explain your reasoning.`
+
+// Player chooses the player's actions with an LLM
+type Player struct {
+	llm  services.LLMService
+	goal string
+}
+
+// NextAction asks the player model for the next action, given the game so far
+func (p *Player) NextAction(ctx context.Context, gs *state.GameState) (string, error) {
+	inventory := "empty"
+	if len(gs.Inventory) > 0 {
+		inventory = strings.Join(gs.DisplayItems(gs.Inventory), ", ")
+	}
+	location := gs.Location
+	if loc, ok := gs.WorldLocations[gs.Location]; ok && loc.Name != "" {
+		location = loc.Name
+	}
+
+	messages := []chat.ChatMessage{{
+		Role:    chat.ChatRoleSystem,
+		Content: fmt.Sprintf(playerSystemPrompt, p.goal, location, inventory),
+	}}
+	messages = append(messages, playerHistory(gs.ChatHistory)...)
+
+	resp, err := p.llm.Chat(ctx, messages, playerTemperature)
+	if err != nil {
+		return "", fmt.Errorf("failed to get player action: %w", err)
+	}
+	action := strings.TrimSpace(resp.Message)
+	if action == "" {
+		return "", fmt.Errorf("player model returned an empty action")
+	}
+	return action, nil
+}
+
+// playerHistory swaps roles so the narrator is the player model's "user" and the player's
+// own actions are its replies. Roles alternate, starting with a narrator message, as providers require.
+func playerHistory(history []chat.ChatMessage) []chat.ChatMessage {
+	var messages []chat.ChatMessage
+	for _, msg := range history {
+		var role string
+		switch msg.Role {
+		case chat.ChatRoleAgent:
+			role = chat.ChatRoleUser
+		case chat.ChatRoleUser:
+			role = chat.ChatRoleAgent
+		default:
+			continue
+		}
+		// Story events can leave several narrator messages in a row
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			messages[n-1].Content += "\n\n" + msg.Content
+			continue
+		}
+		messages = append(messages, chat.ChatMessage{Role: role, Content: msg.Content})
+	}
+
+	if len(messages) > playerHistoryLimit {
+		messages = messages[len(messages)-playerHistoryLimit:]
+	}
+	for len(messages) > 0 && messages[0].Role != chat.ChatRoleUser {
+		messages = messages[1:]
+	}
+	if len(messages) == 0 {
+		messages = append(messages, chat.ChatMessage{Role: chat.ChatRoleUser, Content: "The story begins. What do you do?"})
+	}
+	return messages
+}
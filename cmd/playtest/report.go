@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// Transcript renders the opening and every played turn as markdown
+func (pt *Playtest) Transcript() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Playtest Transcript: %s\n\n", pt.cfg.Scenario)
+	fmt.Fprintf(&b, "**Goal:** %s\n\n", goalPrompt(pt.cfg.Goal))
+
+	b.WriteString("## Opening\n\n")
+	for _, msg := range pt.start.ChatHistory {
+		if msg.Role == chat.ChatRoleAgent {
+			fmt.Fprintf(&b, "%s\n\n", msg.Content)
+		}
+	}
+
+	for _, t := range pt.turns {
+		fmt.Fprintf(&b, "## Turn %d\n\n", t.Turn)
+		if t.Action != "" {
+			fmt.Fprintf(&b, "**Player:** %s\n\n", t.Action)
+		}
+		if t.Narration != "" {
+			fmt.Fprintf(&b, "**Narrator:** %s\n\n", t.Narration)
+		}
+		if changes := receiptSummary(t.Receipt); changes != "" {
+			fmt.Fprintf(&b, "_Changes: %s_\n\n", changes)
+		}
+		if t.Err != "" {
+			fmt.Fprintf(&b, "_Error: %s_\n\n", t.Err)
+		}
+	}
+	return b.String()
+}
+
+// SaveFile returns the game as of the last turn as a save file, for the coverage tool
+func (pt *Playtest) SaveFile() (string, error) {
+	save, err := state.NewSaveFile(pt.gs, pt.scenario)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(save, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode save file: %w", err)
+	}
+	return string(data), nil
+}
+
+// Report lists errors, delta issues, dead-ends, and content the playthrough never reached
+func (pt *Playtest) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Playtest Report: %s\n\n", pt.cfg.Scenario)
+	fmt.Fprintf(&b, "- **Game:** %s\n", pt.start.ID)
+	fmt.Fprintf(&b, "- **Goal:** %s\n", pt.cfg.Goal)
+	fmt.Fprintf(&b, "- **Turns played:** %d of %d\n", len(pt.turns), pt.cfg.Turns)
+	fmt.Fprintf(&b, "- **Outcome:** %s\n\n", pt.outcome())
+
+	writeSection(&b, "Errors", pt.errors)
+	writeSection(&b, "Delta Issues", pt.deltaIssues())
+	writeSection(&b, "Dead-ends", pt.deadEnds())
+
+	b.WriteString("## Unreached Content\n\n")
+	b.WriteString("Content this playthrough never reached. One run can't prove content is unreachable, but anything listed across several runs deserves a look.\n\n")
+	unreached := pt.unreached()
+	if len(unreached) == 0 {
+		b.WriteString("None.\n\n")
+	}
+	for _, kind := range []string{"Scenes", "Locations", "Conditionals", "Items"} {
+		if ids := unreached[kind]; len(ids) > 0 {
+			fmt.Fprintf(&b, "- **%s:** %s\n", kind, strings.Join(ids, ", "))
+		}
+	}
+	return b.String()
+}
+
+func (pt *Playtest) outcome() string {
+	switch {
+	case pt.gs.IsEnded:
+		return fmt.Sprintf("game ended on turn %d", pt.gs.TurnCounter)
+	case len(pt.turns) < pt.cfg.Turns:
+		return "stopped early"
+	default:
+		return "turn limit reached without ending the game"
+	}
+}
+
+// deltaIssues lists the parts of the reducer's deltas that failed validation
+func (pt *Playtest) deltaIssues() []string {
+	var issues []string
+	for _, t := range pt.turns {
+		if t.Receipt == nil {
+			continue
+		}
+		for _, issue := range t.Receipt.DeltaIssues {
+			issues = append(issues, fmt.Sprintf("turn %d: %s %q: %s (%s)", t.Turn, issue.Path, issue.Value, issue.Reason, issue.Fix))
+		}
+	}
+	return issues
+}
+
+// deadEnds finds runs of at least StallTurns turns in which nothing in the game state changed
+func (pt *Playtest) deadEnds() []string {
+	var deadEnds []string
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end-start >= pt.cfg.StallTurns {
+			first, last := pt.turns[start], pt.turns[end-1]
+			deadEnds = append(deadEnds, fmt.Sprintf("turns %d-%d: no state changes in scene %q at location %q", first.Turn, last.Turn, last.Scene, last.Location))
+		}
+		start = -1
+	}
+
+	for i, t := range pt.turns {
+		stalled := t.Err == "" && (t.Receipt == nil || t.Receipt.IsEmpty())
+		if stalled && start < 0 {
+			start = i
+		} else if !stalled {
+			flush(i)
+		}
+	}
+	flush(len(pt.turns))
+	return deadEnds
+}
+
+// unreached returns, by kind, the scenario's scenes, locations, conditionals, and items the player never reached
+func (pt *Playtest) unreached() map[string][]string {
+	scenes := map[string]bool{pt.start.SceneName: true}
+	locations := map[string]bool{pt.start.Location: true}
+	items := make(map[string]bool)
+	fired := make(map[string]bool)
+	for _, item := range pt.start.Inventory {
+		items[item] = true
+	}
+	for _, t := range pt.turns {
+		scenes[t.Scene] = true
+		locations[t.Location] = true
+		if t.Receipt == nil {
+			continue
+		}
+		for _, item := range t.Receipt.ItemsGained {
+			items[item] = true
+		}
+		for _, id := range t.Receipt.ConditionalsFired {
+			fired[id] = true
+		}
+	}
+
+	s := pt.scenario
+	allLocations := slices.Collect(maps.Keys(s.Locations))
+	var allItems, allConditionals []string
+	for _, loc := range s.Locations {
+		allItems = append(allItems, loc.Items...)
+	}
+	for _, npc := range s.NPCs {
+		allItems = append(allItems, npc.Items...)
+	}
+	for sceneID, scene := range s.Scenes {
+		allLocations = append(allLocations, slices.Collect(maps.Keys(scene.Locations))...)
+		for _, loc := range scene.Locations {
+			allItems = append(allItems, loc.Items...)
+		}
+		for _, npc := range scene.NPCs {
+			allItems = append(allItems, npc.Items...)
+		}
+		for id := range scene.Conditionals {
+			if !fired[id] {
+				allConditionals = append(allConditionals, sceneID+"/"+id)
+			}
+		}
+	}
+
+	missing := func(all []string, seen map[string]bool) []string {
+		var out []string
+		for _, id := range all {
+			if !seen[id] && !slices.Contains(out, id) {
+				out = append(out, id)
+			}
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	unreached := make(map[string][]string)
+	for kind, ids := range map[string][]string{
+		"Scenes":       missing(slices.Collect(maps.Keys(s.Scenes)), scenes),
+		"Locations":    missing(allLocations, locations),
+		"Conditionals": missing(allConditionals, nil),
+		"Items":        missing(allItems, items),
+	} {
+		if len(ids) > 0 {
+			unreached[kind] = ids
+		}
+	}
+	return unreached
+}
+
+// receiptSummary describes a turn's changes in one line
+func receiptSummary(r *state.TurnReceipt) string {
+	if r == nil || r.IsEmpty() {
+		return ""
+	}
+	var parts []string
+	if r.SceneChanged != "" {
+		parts = append(parts, "scene "+r.SceneChanged)
+	}
+	if r.LocationChanged != "" {
+		parts = append(parts, "moved to "+r.LocationChanged)
+	}
+	if len(r.ItemsGained) > 0 {
+		parts = append(parts, "gained "+strings.Join(r.ItemsGained, ", "))
+	}
+	if len(r.ItemsLost) > 0 {
+		parts = append(parts, "lost "+strings.Join(r.ItemsLost, ", "))
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.VarsChanged)) {
+		parts = append(parts, k+"="+r.VarsChanged[k])
+	}
+	for _, id := range slices.Sorted(maps.Keys(r.NPCsMoved)) {
+		parts = append(parts, id+" moved to "+r.NPCsMoved[id])
+	}
+	if len(r.ConditionalsFired) > 0 {
+		parts = append(parts, "fired "+strings.Join(r.ConditionalsFired, ", "))
+	}
+	if len(r.DeltaIssues) > 0 {
+		parts = append(parts, fmt.Sprintf("%d delta issue(s)", len(r.DeltaIssues)))
+	}
+	if r.GameEnded {
+		parts = append(parts, "game ended")
+	}
+	return strings.Join(parts, "; ")
+}
+
+func writeSection(b *strings.Builder, title string, lines []string) {
+	fmt.Fprintf(b, "## %s\n\n", title)
+	if len(lines) == 0 {
+		b.WriteString("None.\n\n")
+		return
+	}
+	for _, line := range lines {
+		fmt.Fprintf(b, "- %s\n", line)
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// Script is a scripted playthrough: one delta per turn, standing in for the model's reducer output
+type Script struct {
+	Turns []ScriptTurn `json:"turns"`
+}
+
+// ScriptTurn is a single simulated turn
+type ScriptTurn struct {
+	Note       string                      `json:"note,omitempty"`        // Printed with the turn, e.g. "player hires the shipwright"
+	Delta      conditionals.GameStateDelta `json:"delta,omitempty"`       // Changes the model would extract this turn; empty just advances the turn
+	FreeAction bool                        `json:"free_action,omitempty"` // Apply the delta without advancing the turn counters
+}
+
+func main() {
+	dataDir := flag.String("data", "data", "data directory, for monster templates")
+	verbose := flag.Bool("v", false, "log engine info, such as conditional conflict resolution, to stderr")
+	savePath := flag.String("save", "", "write the final game as a save file, for the coverage tool")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <scenario.json> <script.json>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelInfo
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	sim, err := newSimulator(flag.Arg(0), *dataDir, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	script, err := loadScript(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := sim.run(script, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *savePath != "" {
+		if err := sim.save(*savePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+type Simulator struct {
+	scenario *scenario.Scenario
+	gs       *state.GameState
+	monsters *fileMonsterStorage
+	events   *eventRecorder
+	logger   *slog.Logger
+}
+
+// newSimulator starts a game the way the API does, without a PC or narrator
+func newSimulator(scenarioFile, dataDir string, logger *slog.Logger) (*Simulator, error) {
+	data, err := os.ReadFile(scenarioFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario %s: %w", scenarioFile, err)
+	}
+	var s scenario.Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", scenarioFile, err)
+	}
+	s.Normalize() // keyed by ID, as storage loads it
+
+	gs := state.NewGameState(filepath.Base(scenarioFile), nil, "simulate")
+	gs.NPCs = s.NPCs
+	gs.Location = s.OpeningLocation
+	gs.WorldLocations = s.Locations
+	gs.Vars = s.Vars
+	gs.Inventory = slices.Clone(s.OpeningInventory)
+	gs.ItemNames = s.ItemNames()
+
+	// The game must not share maps and slices with the scenario it loads scenes from
+	gs, err = gs.DeepCopy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy game state: %w", err)
+	}
+	if gs.Vars == nil {
+		gs.Vars = make(map[string]string)
+	}
+
+	if s.OpeningScene != "" {
+		if err := gs.LoadScene(&s, s.OpeningScene); err != nil {
+			return nil, fmt.Errorf("failed to load opening scene: %w", err)
+		}
+	}
+
+	return &Simulator{
+		scenario: &s,
+		gs:       gs,
+		monsters: &fileMonsterStorage{dir: filepath.Join(dataDir, "monsters")},
+		events:   &eventRecorder{},
+		logger:   logger,
+	}, nil
+}
+
+func loadScript(filename string) (*Script, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script %s: %w", filename, err)
+	}
+	var script Script
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&script); err != nil {
+		return nil, fmt.Errorf("failed to parse script %s: %w", filename, err)
+	}
+	return &script, nil
+}
+
+// run plays every scripted turn and prints what changed, stopping early if the game ends
+func (sim *Simulator) run(script *Script, w io.Writer) error {
+	fmt.Fprintf(w, "Start: %s\n\n", sim.stateLine())
+
+	for i, turn := range script.Turns {
+		if sim.gs.IsEnded {
+			fmt.Fprintf(w, "Game ended; skipping %d remaining turn(s)\n", len(script.Turns)-i)
+			break
+		}
+
+		kind := state.TurnPlayer
+		if turn.FreeAction {
+			kind = state.TurnFree
+		}
+		receipt, err := sim.step(turn.Delta, kind)
+		if err != nil {
+			return fmt.Errorf("turn %d: %w", i+1, err)
+		}
+
+		header := fmt.Sprintf("Turn %d", sim.gs.TurnCounter)
+		if turn.FreeAction {
+			header += " (free action)"
+		}
+		if turn.Note != "" {
+			header += ": " + turn.Note
+		}
+		fmt.Fprintln(w, header)
+		printReceipt(w, receipt)
+		for _, prompt := range sim.events.take() {
+			fmt.Fprintf(w, "  story event: %s\n", prompt)
+		}
+		fmt.Fprintf(w, "  state: %s\n\n", sim.stateLine())
+	}
+
+	fmt.Fprintln(w, "Final vars:")
+	for _, k := range slices.Sorted(maps.Keys(sim.gs.Vars)) {
+		fmt.Fprintf(w, "  %s = %s\n", k, sim.gs.Vars[k])
+	}
+	return nil
+}
+
+// step applies one turn's delta the way the worker applies the model's delta:
+// validate, apply, then cascade conditionals until none fire.
+func (sim *Simulator) step(delta conditionals.GameStateDelta, kind state.TurnKind) (*state.TurnReceipt, error) {
+	before, err := sim.gs.DeepCopy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot game state: %w", err)
+	}
+
+	if kind.AdvancesTurn() {
+		sim.gs.IncrementTurnCounters()
+	}
+	worker := state.NewDeltaWorker(sim.gs, &delta, sim.scenario, sim.logger).
+		WithQueue(sim.events).
+		WithStorage(sim.monsters).
+		WithContext(context.Background())
+
+	issues := worker.Validate()
+	worker.ApplyVars()
+	if err := worker.Apply(); err != nil {
+		return nil, fmt.Errorf("failed to apply delta: %w", err)
+	}
+
+	// Mirrors ChatProcessor.applyConditionalsCascade
+	const maxConditionalIterations = 10
+	var fired []string
+	for range maxConditionalIterations {
+		triggered := worker.MergeConditionals()
+		if len(triggered) == 0 {
+			break
+		}
+		fired = append(fired, slices.Sorted(maps.Keys(triggered))...)
+		worker.ApplyVars()
+		if err := worker.Apply(); err != nil {
+			return nil, fmt.Errorf("failed to apply conditional delta: %w", err)
+		}
+	}
+
+	receipt := state.NewTurnReceipt(before, sim.gs, fired)
+	receipt.DeltaIssues = issues
+	return receipt, nil
+}
+
+// stateLine summarizes where the player is and what they carry
+func (sim *Simulator) stateLine() string {
+	scene := sim.gs.SceneName
+	if scene == "" {
+		scene = "-"
+	}
+	inventory := "empty"
+	if len(sim.gs.Inventory) > 0 {
+		inventory = strings.Join(sim.gs.DisplayItems(sim.gs.Inventory), ", ")
+	}
+	return fmt.Sprintf("scene=%s location=%s inventory=[%s]", scene, sim.gs.Location, inventory)
+}
+
+// save writes the game as it ended as a save file
+func (sim *Simulator) save(filename string) error {
+	save, err := state.NewSaveFile(sim.gs, sim.scenario)
+	if err != nil {
+		return fmt.Errorf("failed to build save file: %w", err)
+	}
+	data, err := json.MarshalIndent(save, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode save file: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write save file %s: %w", filename, err)
+	}
+	return nil
+}
+
+func printReceipt(w io.Writer, r *state.TurnReceipt) {
+	for _, issue := range r.DeltaIssues {
+		fmt.Fprintf(w, "  delta issue: %s %q: %s (%s)\n", issue.Path, issue.Value, issue.Reason, issue.Fix)
+	}
+	if len(r.ConditionalsFired) > 0 {
+		fmt.Fprintf(w, "  conditionals fired: %s\n", strings.Join(r.ConditionalsFired, ", "))
+	}
+	if r.SceneChanged != "" {
+		fmt.Fprintf(w, "  scene changed: %s\n", r.SceneChanged)
+	}
+	if r.LocationChanged != "" {
+		fmt.Fprintf(w, "  location changed: %s\n", r.LocationChanged)
+	}
+	if len(r.ItemsGained) > 0 {
+		fmt.Fprintf(w, "  items gained: %s\n", strings.Join(r.ItemsGained, ", "))
+	}
+	if len(r.ItemsLost) > 0 {
+		fmt.Fprintf(w, "  items lost: %s\n", strings.Join(r.ItemsLost, ", "))
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.VarsChanged)) {
+		fmt.Fprintf(w, "  var: %s = %s\n", k, r.VarsChanged[k])
+	}
+	for _, id := range slices.Sorted(maps.Keys(r.NPCsMoved)) {
+		fmt.Fprintf(w, "  npc moved: %s -> %s\n", id, r.NPCsMoved[id])
+	}
+	if r.GameEnded {
+		fmt.Fprintln(w, "  game ended")
+	}
+}
+
+// eventRecorder stands in for the chat queue, collecting story events instead of narrating them
+type eventRecorder struct {
+	prompts []string
+}
+
+func (q *eventRecorder) GetFormattedEvents(ctx context.Context, gameID uuid.UUID) (string, error) {
+	return strings.Join(q.prompts, "\n"), nil
+}
+
+func (q *eventRecorder) Clear(ctx context.Context, gameID uuid.UUID) error {
+	q.prompts = nil
+	return nil
+}
+
+func (q *eventRecorder) EnqueueRequest(ctx context.Context, req *queue.Request) error {
+	if req.Type == queue.RequestTypeStoryEvent {
+		q.prompts = append(q.prompts, req.EventPrompt)
+	}
+	return nil
+}
+
+// take returns the recorded story events and clears them
+func (q *eventRecorder) take() []string {
+	prompts := q.prompts
+	q.prompts = nil
+	return prompts
+}
+
+// fileMonsterStorage loads monster templates straight from the data directory
+type fileMonsterStorage struct {
+	dir string
+}
+
+func (m *fileMonsterStorage) GetMonster(ctx context.Context, templateID string) (*actor.Monster, error) {
+	data, err := os.ReadFile(filepath.Join(m.dir, templateID+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("monster template not found: %s", templateID)
+	}
+	var monster actor.Monster
+	if err := json.Unmarshal(data, &monster); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal monster template: %w", err)
+	}
+	return &monster, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/jwebster45206/story-engine/internal/app"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/handlers"
+	"github.com/jwebster45206/story-engine/internal/logger"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/internal/services/analytics"
+	"github.com/jwebster45206/story-engine/internal/services/events"
+	"github.com/jwebster45206/story-engine/internal/services/privacy"
+	"github.com/jwebster45206/story-engine/internal/services/queue"
+	"github.com/jwebster45206/story-engine/internal/services/usage"
+	"github.com/jwebster45206/story-engine/internal/storage"
+	"github.com/jwebster45206/story-engine/internal/worker"
+	"github.com/redis/go-redis/v9"
+)
+
+// The standalone server runs the API and a worker in one process, with game state
+// and the chat queue held in memory and an embedded Redis for the rest. Nothing
+// outlives the process.
+func main() {
+	dataDir := flag.String("data", "./data", "directory holding scenarios, PCs, narrators, and monsters")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log := logger.Setup(cfg)
+
+	log.Info("Starting Story Engine (standalone)",
+		"port", cfg.Port,
+		"environment", cfg.Environment,
+		"llm_provider", cfg.LLMProvider,
+		"model_name", cfg.ModelName)
+
+	// Game states and the chat queue live in memory. An embedded Redis backs the rest:
+	// event pub/sub, game locks, budgets, analytics, and data jobs.
+	mem, err := miniredis.Run()
+	if err != nil {
+		log.Error("Failed to start in-memory redis", "error", err)
+		os.Exit(1)
+	}
+	defer mem.Close()
+	if cfg.RedisURL != "" {
+		log.Info("Ignoring redis_url; standalone keeps everything in memory", "redis_url", cfg.RedisURL)
+	}
+	log.Warn("Games are held in memory and are lost when the server stops")
+
+	if cfg.EventSourcing {
+		log.Warn("Ignoring event_sourcing; standalone storage keeps no event log")
+	}
+	if cfg.ScenarioStore != config.ScenarioStoreFile {
+		log.Warn("Ignoring scenario_store; standalone saves scenarios in the data directory", "scenario_store", cfg.ScenarioStore)
+	}
+	storageService := storage.NewMemoryStorage(*dataDir, logger.Module(log, logger.ModuleStorage))
+	if cfg.ContentIntegrity.Enabled() {
+		publicKey, err := cfg.ContentIntegrity.DecodedPublicKey()
+		if err != nil {
+			log.Error("Invalid content integrity key", "error", err)
+			os.Exit(1)
+		}
+		manifest, err := storage.LoadContentManifest(cfg.ContentIntegrity.Manifest, publicKey)
+		if err != nil {
+			log.Error("Failed to load content manifest", "error", err)
+			os.Exit(1)
+		}
+		// Files that fail now are refused when loaded; report them early
+		for _, problem := range manifest.CheckDir(*dataDir) {
+			log.Error("Content file failed integrity check", "error", problem)
+		}
+		storageService = storageService.WithContentManifest(manifest)
+		log.Info("Content integrity verification enabled", "manifest", cfg.ContentIntegrity.Manifest, "files", len(manifest.Files), "signed", publicKey != nil)
+	}
+	if err := app.PreflightScenarios(context.Background(), &storageService.FileStore, cfg.Preflight, log); err != nil {
+		log.Error("Scenario preflight failed", "error", err)
+		os.Exit(1)
+	}
+	chatQueue := queue.NewMemoryQueue()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: mem.Addr()})
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			log.Error("Failed to close Redis client", "error", err)
+		}
+	}()
+
+	if cfg.CaptureDir != "" {
+		log.Warn("Capturing every LLM request and response", "dir", cfg.CaptureDir)
+	}
+
+	profanity, err := cfg.TextFilter.ProfanityFilter()
+	if err != nil {
+		log.Error("Failed to set up text filter", "error", err)
+		os.Exit(1)
+	}
+	modelRegistry := cfg.ModelRegistry()
+	ledger := usage.NewLedger(redisClient, modelRegistry, cfg.Budgets, log)
+	analyticsStore := analytics.NewStore(redisClient)
+	dataJobs := privacy.NewStore(redisClient, log)
+	deps := &app.Dependencies{
+		Config:        cfg,
+		Queue:         chatQueue,
+		RedisClient:   redisClient,
+		ModelRegistry: modelRegistry,
+		Ledger:        ledger,
+		Profanity:     profanity,
+		Logger:        log,
+	}
+
+	// Narrators and PCs are shared, so deleting one checks games in every profile
+	for _, name := range cfg.ProfileNames() {
+		profile, _ := cfg.Profile(name)
+		deps.GameStorages = append(deps.GameStorages, storageService.WithKeyPrefix(profile.StoragePrefix))
+	}
+
+	llmService, err := services.NewLLMService(cfg, cfg.LLMProvider, cfg.ModelName, cfg.BackendModelName, log)
+	if err != nil {
+		log.Error("Failed to create LLM service", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Using LLM provider", "provider", cfg.LLMProvider)
+	initCtx, initCancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer initCancel()
+	if err := llmService.InitModel(initCtx, cfg.ModelName); err != nil {
+		log.Error("Failed to initialize LLM model", "error", err, "model", cfg.ModelName)
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/health", handlers.NewHealthHandler(log, storageService, llmService).
+		WithCapabilities(handlers.Capabilities{
+			TextFilter: &handlers.TextFilterCapability{
+				Narration: true,
+				Leetspeak: profanity.Leetspeak(),
+				Locales:   profanity.Locales(),
+			},
+		}))
+
+	processor := deps.ChatProcessor(storageService, llmService, cfg.BackendModelName, analyticsStore)
+
+	// Each named profile gets its own LLM service, shared by its routes and its processor
+	router := middleware.NewProfileRouter(cfg.APIKeys, log)
+	profileProcessors := make(map[string]*worker.ChatProcessor)
+	for _, name := range cfg.ProfileNames() {
+		profile, _ := cfg.Profile(name)
+		profileLLM := llmService
+		profileStorage := storageService.WithKeyPrefix(profile.StoragePrefix)
+		profileAnalytics := analyticsStore.WithKeyPrefix(profile.StoragePrefix)
+		if name != "" {
+			profileLLM, err = services.NewLLMService(cfg, profile.LLMProvider, profile.ModelName, profile.BackendModelName, log)
+			if err != nil {
+				log.Error("Failed to create LLM service for profile", "profile", name, "error", err)
+				os.Exit(1)
+			}
+			profileProcessors[name] = deps.ChatProcessor(profileStorage, profileLLM, profile.BackendModelName, profileAnalytics)
+		}
+		router.Handle(profile, deps.ProfileMux(profile, profileStorage, profileLLM, profileAnalytics, dataJobs.WithKeyPrefix(profile.StoragePrefix)))
+		log.Info("Profile configured", "profile", name, "provider", profile.LLMProvider, "model", profile.ModelName)
+	}
+
+	spectatorHandler := handlers.NewSpectatorHandler(events.NewSpectatorFeed(redisClient, log), cfg.SpectatorLag(), logger.Module(log, logger.ModuleHandlers)).
+		WithHeartbeat(cfg.HeartbeatInterval())
+	mux.Handle("/v1/spectate/", spectatorHandler)
+	mux.Handle("/", router)
+
+	w := worker.New(chatQueue, processor, redisClient, logger.Module(log, logger.ModuleWorker), "standalone").
+		WithProfileProcessors(profileProcessors).
+		WithProfanityFilter(profanity)
+	go func() {
+		if err := w.Start(); err != nil {
+			log.Error("Worker error", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	server := &http.Server{
+		Addr:        ":" + cfg.Port,
+		Handler:     middleware.Logger(mux),
+		ReadTimeout: 15 * time.Second,
+		// No WriteTimeout, so chat and event streams stay open
+		IdleTimeout: 60 * time.Second,
+	}
+
+	go func() {
+		log.Info("Server starting", "addr", server.Addr, "data_dir", *dataDir)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error("Server failed to start", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Info("Server is shutting down...")
+
+	// Requests, the worker's current request, and background gamestate deltas share
+	// one deadline
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownDrain())
+	defer shutdownCancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("Server forced to shutdown", "error", err)
+	}
+	if err := w.Shutdown(shutdownCtx); err != nil {
+		log.Error("Worker forced to shut down", "error", err)
+	}
+
+	log.Info("Server exited")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario/validate"
+)
+
+// Summary counts the files checked and what was found
+type Summary struct {
+	Files    int `json:"files"`
+	Invalid  int `json:"invalid"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+}
+
+// Report is the output of -format json
+type Report struct {
+	Summary  Summary            `json:"summary"`
+	Findings []validate.Finding `json:"findings"`
+}
+
+// expandArgs turns the command's arguments into scenario files. An argument can be a
+// file, a directory (its .json files), a directory followed by /... (its .json files
+// at any depth), or a glob pattern.
+func expandArgs(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if dir, ok := strings.CutSuffix(arg, "/..."); ok {
+			err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && filepath.Ext(path) == ".json" {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk %s: %w", dir, err)
+			}
+			continue
+		}
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			matches, err := filepath.Glob(filepath.Join(arg, "*.json"))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+			continue
+		}
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", arg)
+			}
+			files = append(files, matches...)
+			continue
+		}
+		files = append(files, arg)
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// writeText prints one file's findings: warnings and the verdict to out, errors to errOut
+func writeText(out, errOut io.Writer, filename string, result *validate.Result) {
+	if len(result.Warnings) > 0 {
+		fmt.Fprintf(out, "Warnings:\n%s\n", formatFindings(result.Warnings))
+	}
+	if !result.Valid() {
+		fmt.Fprintf(errOut, "Validation failed: validation errors in %s:\n%s\n", filename, formatFindings(result.Errors))
+		return
+	}
+	fmt.Fprintln(out, "Scenario file is valid!")
+}
+
+func formatFindings(findings []validate.Finding) string {
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = "  - " + f.Message
+		if f.Line > 0 {
+			lines[i] = fmt.Sprintf("  - line %d: %s", f.Line, f.Message)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -5,14 +5,16 @@ import (
 	"log"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
+	"github.com/jwebster45206/story-engine/internal/app"
 	"github.com/jwebster45206/story-engine/internal/config"
 	"github.com/jwebster45206/story-engine/internal/logger"
 	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/internal/services/analytics"
 	"github.com/jwebster45206/story-engine/internal/services/queue"
+	"github.com/jwebster45206/story-engine/internal/services/usage"
 	"github.com/jwebster45206/story-engine/internal/storage"
 	"github.com/jwebster45206/story-engine/internal/worker"
 	"github.com/redis/go-redis/v9"
@@ -31,7 +33,7 @@ func main() {
 		"redis_url", cfg.RedisURL)
 
 	// Initialize queue service
-	queueClient, err := queue.NewClient(cfg.RedisURL, log)
+	queueClient, err := queue.NewClient(cfg.RedisURL, logger.Module(log, logger.ModuleQueue))
 	if err != nil {
 		log.Error("Failed to create queue client", "error", err)
 		os.Exit(1)
@@ -47,7 +49,41 @@ func main() {
 	log.Info("Queue service initialized successfully")
 
 	// Initialize storage service
-	storageService := storage.NewRedisStorage(cfg.RedisURL, "./data", log)
+	storageService := storage.NewRedisStorage(cfg.RedisURL, "./data", logger.Module(log, logger.ModuleStorage)).
+		WithEventSourcing(cfg.EventSourcing).
+		WithScenariosInRedis(cfg.ScenarioStore == config.ScenarioStoreRedis)
+	if cfg.Encryption.Enabled() {
+		keys, err := cfg.Encryption.DecodedKeys()
+		if err != nil {
+			log.Error("Invalid encryption keys", "error", err)
+			os.Exit(1)
+		}
+		encryptor, err := storage.NewEncryptor(keys, cfg.Encryption.ActiveKey)
+		if err != nil {
+			log.Error("Failed to set up encryption", "error", err)
+			os.Exit(1)
+		}
+		storageService = storageService.WithEncryption(encryptor)
+		log.Info("Game state encryption enabled", "active_key", cfg.Encryption.ActiveKey)
+	}
+	if cfg.ContentIntegrity.Enabled() {
+		publicKey, err := cfg.ContentIntegrity.DecodedPublicKey()
+		if err != nil {
+			log.Error("Invalid content integrity key", "error", err)
+			os.Exit(1)
+		}
+		manifest, err := storage.LoadContentManifest(cfg.ContentIntegrity.Manifest, publicKey)
+		if err != nil {
+			log.Error("Failed to load content manifest", "error", err)
+			os.Exit(1)
+		}
+		// Files that fail now are refused when loaded; report them early
+		for _, problem := range manifest.CheckDir("./data") {
+			log.Error("Content file failed integrity check", "error", problem)
+		}
+		storageService = storageService.WithContentManifest(manifest)
+		log.Info("Content integrity verification enabled", "manifest", cfg.ContentIntegrity.Manifest, "files", len(manifest.Files), "signed", publicKey != nil)
+	}
 	storageCtx, storageCancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer storageCancel()
 
@@ -58,26 +94,15 @@ func main() {
 	log.Info("Storage service initialized successfully")
 
 	// Initialize LLM service
-	var llmService services.LLMService
-	switch strings.ToLower(cfg.LLMProvider) {
-	case "anthropic":
-		if cfg.AnthropicAPIKey == "" {
-			log.Error("Anthropic API key is required when using anthropic provider")
-			os.Exit(1)
-		}
-		llmService = services.NewAnthropicService(cfg.AnthropicAPIKey, cfg.ModelName, cfg.BackendModelName, log)
-		log.Info("Using Anthropic LLM provider")
-	case "venice":
-		if cfg.VeniceAPIKey == "" {
-			log.Error("Venice API key is required when using venice provider")
-			os.Exit(1)
-		}
-		llmService = services.NewVeniceService(cfg.VeniceAPIKey, cfg.ModelName, cfg.BackendModelName)
-		log.Info("Using Venice LLM provider")
-	default:
-		log.Error("Invalid LLM provider specified", "provider", cfg.LLMProvider, "supported", []string{"anthropic", "venice"})
+	llmService, err := services.NewLLMService(cfg, cfg.LLMProvider, cfg.ModelName, cfg.BackendModelName, log)
+	if err != nil {
+		log.Error("Failed to create LLM service", "error", err)
 		os.Exit(1)
 	}
+	log.Info("Using LLM provider", "provider", cfg.LLMProvider)
+	if cfg.CaptureDir != "" {
+		log.Warn("Capturing every LLM request and response", "dir", cfg.CaptureDir)
+	}
 
 	// Initialize the model
 	initCtx, initCancel := context.WithTimeout(context.Background(), 10*time.Minute)
@@ -89,8 +114,36 @@ func main() {
 	log.Info("LLM service initialized successfully", "model", cfg.ModelName)
 
 	// Create ChatProcessor
-	processor := worker.NewChatProcessor(storageService, llmService, chatQueue, log, cfg.ChatHistoryLimit)
-	log.Info("Chat processor initialized successfully")
+	profanity, err := cfg.TextFilter.ProfanityFilter()
+	if err != nil {
+		log.Error("Failed to set up text filter", "error", err)
+		os.Exit(1)
+	}
+	modelRegistry := cfg.ModelRegistry()
+	ledger := usage.NewLedger(queueClient.GetRedisClient(), modelRegistry, cfg.Budgets, log)
+	analyticsStore := analytics.NewStore(queueClient.GetRedisClient())
+	deps := &app.Dependencies{
+		Config:        cfg,
+		Queue:         chatQueue,
+		ModelRegistry: modelRegistry,
+		Ledger:        ledger,
+		Profanity:     profanity,
+		Logger:        log,
+	}
+	processor := deps.ChatProcessor(storageService, llmService, cfg.BackendModelName, analyticsStore)
+
+	// Each named profile gets its own processor, with its own storage prefix and LLM service
+	profileProcessors := make(map[string]*worker.ChatProcessor)
+	for _, p := range cfg.Profiles {
+		profile, _ := cfg.Profile(p.Name)
+		profileLLM, err := services.NewLLMService(cfg, profile.LLMProvider, profile.ModelName, profile.BackendModelName, log)
+		if err != nil {
+			log.Error("Failed to create LLM service for profile", "profile", profile.Name, "error", err)
+			os.Exit(1)
+		}
+		profileProcessors[profile.Name] = deps.ChatProcessor(storageService.WithKeyPrefix(profile.StoragePrefix), profileLLM, profile.BackendModelName, analyticsStore.WithKeyPrefix(profile.StoragePrefix))
+	}
+	log.Info("Chat processor initialized successfully", "profiles", len(profileProcessors))
 
 	// Create a separate Redis client for worker locking
 	// (separate from queue client to avoid connection conflicts)
@@ -114,7 +167,9 @@ func main() {
 	log.Info("Redis connection established successfully")
 
 	// Create and start worker with processor
-	w := worker.New(chatQueue, processor, redisClient, log, os.Getenv("WORKER_ID"))
+	w := worker.New(chatQueue, processor, redisClient, logger.Module(log, logger.ModuleWorker), os.Getenv("WORKER_ID")).
+		WithProfileProcessors(profileProcessors).
+		WithProfanityFilter(profanity)
 
 	// Handle graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -134,11 +189,13 @@ func main() {
 	<-quit
 	log.Info("Worker shutdown signal received")
 
-	// Stop worker
-	w.Stop()
-
-	// Give worker time to finish current request
-	time.Sleep(2 * time.Second)
+	// Stop taking requests, and wait for the current one and any gamestate deltas still
+	// running in the background, so a deploy doesn't lose the last turns' state changes
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownDrain())
+	defer shutdownCancel()
+	if err := w.Shutdown(shutdownCtx); err != nil {
+		log.Error("Worker forced to shut down", "error", err)
+	}
 
 	log.Info("Worker exited")
 }
@@ -97,6 +97,7 @@ func TestIntegrationSuites(t *testing.T) {
 
 	var failed []string
 	var passed []string
+	var runResults []runner.TestRunResult
 
 	for i, job := range jobs {
 		t.Logf("[%d/%d] Starting test suite: %s (%d steps)", i+1, len(jobs), job.Name, len(job.Suite.Steps))
@@ -106,6 +107,7 @@ func TestIntegrationSuites(t *testing.T) {
 			result.Error = err
 		}
 		result.Job = job
+		runResults = append(runResults, result)
 
 		// Process result immediately for real-time feedback
 		t.Logf("GameState ID: %s", result.GameState.String())
@@ -140,6 +142,10 @@ func TestIntegrationSuites(t *testing.T) {
 	t.Logf("   Passed: %d", len(passed))
 	t.Logf("   Failed: %d", len(failed))
 
+	if report := runner.BuildLatencyReport(runResults); report != "" {
+		t.Log(report)
+	}
+
 	if len(failed) > 0 {
 		t.Logf("\nFailed tests:")
 		for _, failure := range failed {
@@ -233,6 +239,9 @@ func TestSingleSuite(t *testing.T) {
 	// Track detailed failures per case and step
 	var allFailures []failureDetail
 
+	// Collect results across runs for the latency report
+	var runResults []runner.TestRunResult
+
 	// Run test suites multiple times
 	for run := 1; run <= runs; run++ {
 		if runs > 1 {
@@ -265,6 +274,7 @@ func TestSingleSuite(t *testing.T) {
 					result.Error = err
 				}
 				result.Job = job
+				runResults = append(runResults, result)
 
 				// Log detailed results
 				t.Logf("GameState ID: %s", result.GameState.String())
@@ -347,6 +357,11 @@ func TestSingleSuite(t *testing.T) {
 		t.Log(summary)
 	}
 
+	// Latency percentiles per step, across runs
+	if report := runner.BuildLatencyReport(runResults); report != "" {
+		t.Log(report)
+	}
+
 	// Detailed failure report
 	if len(allFailures) > 0 {
 		t.Log(buildFailureReport(allFailures, totalTests, totalPasses, totalFailures))
@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LatencyStats summarizes step durations across runs
+type LatencyStats struct {
+	Samples int
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// StepLatency holds latency percentiles for one step of one case, across runs
+type StepLatency struct {
+	CaseName string
+	StepName string
+	Total    LatencyStats // Whole step, prompt to DeltaWorker completion
+	Chat     LatencyStats // Prompt to narration
+	Delta    LatencyStats // Narration to DeltaWorker completion
+}
+
+// NewLatencyStats computes nearest-rank percentiles over the given durations
+func NewLatencyStats(durations []time.Duration) LatencyStats {
+	if len(durations) == 0 {
+		return LatencyStats{}
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	return LatencyStats{
+		Samples: len(sorted),
+		P50:     percentile(sorted, 50),
+		P90:     percentile(sorted, 90),
+		P99:     percentile(sorted, 99),
+		Max:     sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// SummarizeLatency groups the results of one or more runs by case and step and computes
+// percentiles for each. Reset steps and steps that never reached the narration are skipped.
+// Steps are returned in the order they were first seen.
+func SummarizeLatency(runs []TestRunResult) []StepLatency {
+	type samples struct {
+		total, chat, delta []time.Duration
+	}
+	type stepKey struct {
+		caseName, stepName string
+	}
+
+	var order []stepKey
+	byStep := make(map[stepKey]*samples)
+	for _, run := range runs {
+		for _, result := range run.Results {
+			if result.IsReset || result.ChatDuration == 0 {
+				continue
+			}
+			key := stepKey{run.Job.Name, result.StepName}
+			s, ok := byStep[key]
+			if !ok {
+				s = &samples{}
+				byStep[key] = s
+				order = append(order, key)
+			}
+			s.total = append(s.total, result.Duration)
+			s.chat = append(s.chat, result.ChatDuration)
+			if result.DeltaDuration > 0 {
+				s.delta = append(s.delta, result.DeltaDuration)
+			}
+		}
+	}
+
+	latencies := make([]StepLatency, 0, len(order))
+	for _, key := range order {
+		s := byStep[key]
+		latencies = append(latencies, StepLatency{
+			CaseName: key.caseName,
+			StepName: key.stepName,
+			Total:    NewLatencyStats(s.total),
+			Chat:     NewLatencyStats(s.chat),
+			Delta:    NewLatencyStats(s.delta),
+		})
+	}
+	return latencies
+}
+
+// BuildLatencyReport renders per-step latency percentiles across runs
+func BuildLatencyReport(runs []TestRunResult) string {
+	latencies := SummarizeLatency(runs)
+	if len(latencies) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n========================================\n")
+	sb.WriteString("Latency Report\n")
+	sb.WriteString("========================================\n")
+
+	var allTotal, allChat, allDelta []time.Duration
+	for _, run := range runs {
+		for _, result := range run.Results {
+			if result.IsReset || result.ChatDuration == 0 {
+				continue
+			}
+			allTotal = append(allTotal, result.Duration)
+			allChat = append(allChat, result.ChatDuration)
+			if result.DeltaDuration > 0 {
+				allDelta = append(allDelta, result.DeltaDuration)
+			}
+		}
+	}
+	sb.WriteString("\nAll steps:\n")
+	writeLatencyStats(&sb, "total", NewLatencyStats(allTotal))
+	writeLatencyStats(&sb, "chat", NewLatencyStats(allChat))
+	writeLatencyStats(&sb, "delta", NewLatencyStats(allDelta))
+
+	caseName := ""
+	for _, l := range latencies {
+		if l.CaseName != caseName {
+			caseName = l.CaseName
+			sb.WriteString(fmt.Sprintf("\n%s:\n", caseName))
+		}
+		sb.WriteString(fmt.Sprintf("  %s (%d samples)\n", l.StepName, l.Total.Samples))
+		writeLatencyStats(&sb, "total", l.Total)
+		writeLatencyStats(&sb, "chat", l.Chat)
+		writeLatencyStats(&sb, "delta", l.Delta)
+	}
+
+	return sb.String()
+}
+
+func writeLatencyStats(sb *strings.Builder, label string, stats LatencyStats) {
+	if stats.Samples == 0 {
+		return
+	}
+	round := func(d time.Duration) time.Duration { return d.Round(10 * time.Millisecond) }
+	sb.WriteString(fmt.Sprintf("    %-5s p50=%v p90=%v p99=%v max=%v\n",
+		label, round(stats.P50), round(stats.P90), round(stats.P99), round(stats.Max)))
+}
@@ -32,6 +32,7 @@ type Runner struct {
 	Logger            func(format string, args ...interface{})
 	ErrorHandlingMode ErrorHandlingMode
 	ScenarioOverride  string // If set, overrides the scenario for all test cases
+	ModelOverride     string // If set, games are created with this model instead of the server's default
 }
 
 // NewRunner creates a new test runner
@@ -117,7 +118,7 @@ func (r *Runner) RunSuite(ctx context.Context, suite TestSuite) (TestRunResult,
 	} else {
 		seedData.Scenario = suite.Scenario
 	}
-	actualGameStateID, err := r.seedGameState(ctx, seedData)
+	actualGameStateID, err := r.SeedGameState(ctx, seedData)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to seed gamestate: %w", err)
 		result.Duration = time.Since(start)
@@ -170,12 +171,13 @@ func (r *Runner) RunSuite(ctx context.Context, suite TestSuite) (TestRunResult,
 	return result, result.Error
 }
 
-// seedGameState creates a new gamestate and then patches it with seed data
-func (r *Runner) seedGameState(ctx context.Context, seed state.GameState) (uuid.UUID, error) {
+// SeedGameState creates a new gamestate and then patches it with seed data
+func (r *Runner) SeedGameState(ctx context.Context, seed state.GameState) (uuid.UUID, error) {
 	// Step 1: Create a basic gamestate via POST /v1/gamestate
-	// ModelName is set by the handler from its configuration, Scenario is set from request
+	// ModelName is set by the handler from its configuration unless overridden, Scenario is set from request
 	createReq := state.GameState{
-		Scenario: seed.Scenario,
+		Scenario:  seed.Scenario,
+		ModelName: r.ModelOverride,
 	}
 
 	createBody, err := json.Marshal(createReq)
@@ -207,8 +209,8 @@ func (r *Runner) seedGameState(ctx context.Context, seed state.GameState) (uuid.
 		return uuid.UUID{}, fmt.Errorf("failed to decode created gamestate: %w", err)
 	}
 
-	// Step 2: Use resetGameState to apply all seed data consistently
-	if err := r.resetGameState(ctx, createdGS.ID, &seed); err != nil {
+	// Step 2: Use ResetGameState to apply all seed data consistently
+	if err := r.ResetGameState(ctx, createdGS.ID, &seed); err != nil {
 		return uuid.UUID{}, fmt.Errorf("failed to seed gamestate: %w", err)
 	}
 
@@ -216,9 +218,9 @@ func (r *Runner) seedGameState(ctx context.Context, seed state.GameState) (uuid.
 	return createdGS.ID, nil
 }
 
-// resetGameState resets the gamestate to the original seed data
-func (r *Runner) resetGameState(ctx context.Context, gameStateID uuid.UUID, seedState *state.GameState) error {
-	// Use the same PATCH logic as seedGameState, but exclude immutable fields
+// ResetGameState resets the gamestate to the original seed data
+func (r *Runner) ResetGameState(ctx context.Context, gameStateID uuid.UUID, seedState *state.GameState) error {
+	// Use the same PATCH logic as SeedGameState, but exclude immutable fields
 	patchData := state.GameState{
 		SceneName:          seedState.SceneName,
 		Location:           seedState.Location,
@@ -231,6 +233,7 @@ func (r *Runner) resetGameState(ctx context.Context, gameStateID uuid.UUID, seed
 		NPCs:               seedState.NPCs,
 		WorldLocations:     seedState.WorldLocations,
 		ContingencyPrompts: seedState.ContingencyPrompts,
+		Seed:               seedState.Seed,
 	}
 
 	patchBody, err := json.Marshal(patchData)
@@ -301,7 +304,7 @@ func (r *Runner) executeStep(ctx context.Context, gameStateID uuid.UUID, step Te
 
 	// Check if this is a reset step
 	if step.UserPrompt == ResetGameStatePrompt {
-		err := r.resetGameState(ctx, gameStateID, seedState)
+		err := r.ResetGameState(ctx, gameStateID, seedState)
 		if err != nil {
 			result.Error = fmt.Errorf("failed to reset gamestate: %w", err)
 			result.Duration = time.Since(start)
@@ -367,6 +370,7 @@ func (r *Runner) executeStep(ctx context.Context, gameStateID uuid.UUID, step Te
 		result.StoryEventText = assertedStoryEvent.Content
 		result.ResponseText = assertedResponse.Content
 		result.IsStoryEventWait = true
+		result.ChatDuration = time.Since(start)
 
 		// Poll for DeltaWorker completion
 		postGameState, err := PollForDeltaWorkerCompletion(ctx, r.Client, r.BaseURL, gameStateID, afterChatState)
@@ -375,6 +379,7 @@ func (r *Runner) executeStep(ctx context.Context, gameStateID uuid.UUID, step Te
 			result.Duration = time.Since(start)
 			return result
 		}
+		result.DeltaDuration = time.Since(start) - result.ChatDuration
 
 		// Check expectations (including story event specific ones)
 		if err := r.checkExpectations(step.Expectations, preGameState, postGameState, prevTurnCounter, prevInventory, assistantResponse); err != nil {
@@ -390,8 +395,12 @@ func (r *Runner) executeStep(ctx context.Context, gameStateID uuid.UUID, step Te
 			return result
 		}
 
-		result.Success = true
 		result.Duration = time.Since(start)
+		if err := checkLatency(step.Expectations, result.Duration); err != nil {
+			result.Error = fmt.Errorf("latency expectation failed: %w", err)
+			return result
+		}
+		result.Success = true
 		return result
 	}
 
@@ -422,6 +431,7 @@ func (r *Runner) executeStep(ctx context.Context, gameStateID uuid.UUID, step Te
 		return result
 	}
 	result.ResponseText = assistantResponse
+	result.ChatDuration = time.Since(start)
 
 	// Poll for DeltaWorker completion (wait for meta fields to update)
 	postGameState, err := PollForDeltaWorkerCompletion(ctx, r.Client, r.BaseURL, gameStateID, afterChatState)
@@ -430,6 +440,7 @@ func (r *Runner) executeStep(ctx context.Context, gameStateID uuid.UUID, step Te
 		result.Duration = time.Since(start)
 		return result
 	}
+	result.DeltaDuration = time.Since(start) - result.ChatDuration
 
 	// Check expectations
 	if err := r.checkExpectations(step.Expectations, preGameState, postGameState, prevTurnCounter, prevInventory, assistantResponse); err != nil {
@@ -438,11 +449,30 @@ func (r *Runner) executeStep(ctx context.Context, gameStateID uuid.UUID, step Te
 		return result
 	}
 
-	result.Success = true
 	result.Duration = time.Since(start)
+	if err := checkLatency(step.Expectations, result.Duration); err != nil {
+		result.Error = fmt.Errorf("latency expectation failed: %w", err)
+		return result
+	}
+	result.Success = true
 	return result
 }
 
+// checkLatency fails a step that took longer than its max_duration expectation
+func checkLatency(exp Expectations, duration time.Duration) error {
+	if exp.MaxDuration == "" {
+		return nil
+	}
+	maxDuration, err := time.ParseDuration(exp.MaxDuration)
+	if err != nil {
+		return fmt.Errorf("invalid max_duration %q: %w", exp.MaxDuration, err)
+	}
+	if duration > maxDuration {
+		return fmt.Errorf("step took %v, exceeding max_duration %v", duration.Round(time.Millisecond), maxDuration)
+	}
+	return nil
+}
+
 // getGameState retrieves the current gamestate
 func (r *Runner) getGameState(ctx context.Context, gameStateID uuid.UUID) (*state.GameState, error) {
 	return GetGameState(ctx, r.Client, r.BaseURL, gameStateID)
@@ -469,7 +499,7 @@ func (r *Runner) checkExpectations(exp Expectations, preState, postState *state.
 		// Create maps for efficient comparison
 		expected := make(map[string]bool)
 		for _, item := range exp.Inventory {
-			expected[item] = true
+			expected[postState.ItemID(item)] = true
 		}
 
 		actual := make(map[string]bool)
@@ -61,6 +61,9 @@ type Expectations struct {
 	StoryEventContains    []string `json:"story_event_contains,omitempty"`     // Story event message must contain these strings
 	StoryEventNotContains []string `json:"story_event_not_contains,omitempty"` // Story event message must NOT contain these strings
 	StoryEventExact       *string  `json:"story_event_exact,omitempty"`        // Exact story event message text
+
+	// Latency
+	MaxDuration string `json:"max_duration,omitempty"` // Maximum step duration, e.g. "20s" (Go duration syntax)
 }
 
 // TestResult contains the outcome of running a test step
@@ -71,6 +74,8 @@ type TestResult struct {
 	Success          bool
 	Error            error
 	Duration         time.Duration
+	ChatDuration     time.Duration // From sending the prompt to the narration arriving
+	DeltaDuration    time.Duration // From the narration arriving to the DeltaWorker finishing
 	ResponseText     string
 	StoryEventText   string // Story event message text (for WAIT_FOR_STORY_EVENT steps)
 	IsReset          bool   // True if this was a RESET_GAMESTATE step (should not count toward pass/fail metrics)
@@ -0,0 +1,157 @@
+// Package app builds the routes and chat processors that cmd/api, cmd/worker, and
+// cmd/standalone share, so each entry point wires them the same way whatever storage
+// and queue it runs on
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/handlers"
+	"github.com/jwebster45206/story-engine/internal/logger"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/internal/services/analytics"
+	"github.com/jwebster45206/story-engine/internal/services/privacy"
+	"github.com/jwebster45206/story-engine/internal/services/usage"
+	"github.com/jwebster45206/story-engine/internal/storage"
+	"github.com/jwebster45206/story-engine/internal/worker"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	pkgstorage "github.com/jwebster45206/story-engine/pkg/storage"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
+	"github.com/redis/go-redis/v9"
+)
+
+// Dependencies are the services shared by every profile's routes and chat processor.
+// Fields an entry point doesn't use may be left nil: the worker serves no routes.
+type Dependencies struct {
+	Config        *config.Config
+	Queue         state.ChatQueue
+	RedisClient   *redis.Client // event pub/sub for the event stream and WebSocket routes
+	ModelRegistry *config.ModelRegistry
+	Ledger        *usage.Ledger
+	Profanity     *textfilter.ProfanityFilter
+	GameStorages  []pkgstorage.GameStateStore // every profile's games, checked before shared content is deleted
+	Logger        *slog.Logger
+}
+
+// ChatProcessor creates the chat processor that plays turns for games in store
+func (d *Dependencies) ChatProcessor(store pkgstorage.Storage, llmService services.LLMService, backendModelName string, analyticsStore *analytics.Store) *worker.ChatProcessor {
+	cfg := d.Config
+	return worker.NewChatProcessor(store, llmService, d.Queue, logger.Module(d.Logger, logger.ModuleWorker), cfg.ChatHistoryLimit).
+		WithModelRegistry(d.ModelRegistry).
+		WithBackendModel(backendModelName).
+		WithUsageLedger(d.Ledger).
+		WithAnalytics(analyticsStore).
+		WithInputTranslation(cfg.TranslateInput).
+		WithCompactDelta(cfg.CompactDeltaAt).
+		WithMaxNarrationChars(cfg.MaxNarration).
+		WithProfanityFilter(d.Profanity).
+		WithConsistencyCheck(cfg.ConsistencyCheck).
+		WithNarrationMeta(cfg.NarrationMeta)
+}
+
+// ProfileMux builds the API routes for one profile, whose games are held in store
+func (d *Dependencies) ProfileMux(profile config.Profile, store pkgstorage.Storage, llmService services.LLMService, analyticsStore *analytics.Store, dataJobs *privacy.Store) *http.ServeMux {
+	cfg := d.Config
+	log := logger.Module(d.Logger, logger.ModuleHandlers)
+	heartbeat := cfg.HeartbeatInterval()
+	mux := http.NewServeMux()
+
+	chatHandler := handlers.NewChatHandler(d.Queue, log).
+		WithProfile(profile).
+		WithBudget(d.Ledger).
+		WithStorage(store)
+	mux.Handle("/v1/chat", chatHandler)
+
+	eventsHandler := handlers.NewEventsHandler(d.RedisClient, log).WithHeartbeat(heartbeat)
+	mux.Handle("/v1/events/gamestate/", eventsHandler)
+
+	webSocketHandler := handlers.NewWebSocketHandler(chatHandler, d.RedisClient, log).
+		WithHeartbeat(heartbeat).
+		WithAllowedOrigins(cfg.WebSocketOrigins...)
+	mux.Handle("/v1/ws", webSocketHandler)
+
+	gameStateHandler := handlers.NewGameStateHandler(log, profile.ModelName, store).
+		WithLLMService(llmService).
+		WithModelRegistry(d.ModelRegistry).
+		WithProfile(profile).
+		WithBudget(d.Ledger).
+		WithQueue(d.Queue).
+		WithAnalytics(analyticsStore).
+		WithAdminKey(cfg.AdminKey)
+	mux.Handle("/v1/gamestate", gameStateHandler)
+	mux.Handle("/v1/gamestate/", gameStateHandler)
+
+	scenarioHandler := handlers.NewScenarioHandler(log, store).
+		WithModelRegistry(d.ModelRegistry).
+		WithProfile(profile).
+		WithAdminKey(cfg.AdminKey).
+		WithGameStorages(d.GameStorages...)
+	mux.Handle("/v1/scenarios", scenarioHandler)
+	mux.Handle("/v1/scenarios/", scenarioHandler)
+
+	analyticsHandler := handlers.NewAnalyticsHandler(log, store, analyticsStore)
+	mux.Handle("/v1/analytics/scenarios/", analyticsHandler)
+
+	dataHandler := handlers.NewDataHandler(log, store, dataJobs).
+		WithAdminKey(cfg.AdminKey)
+	mux.Handle("/v1/data/", dataHandler)
+
+	pcHandler := handlers.NewPCHandler(log, store).
+		WithAdminKey(cfg.AdminKey).
+		WithGameStorages(d.GameStorages...)
+	mux.Handle("/v1/pcs", pcHandler)
+	mux.Handle("/v1/pcs/", pcHandler)
+
+	narratorHandler := handlers.NewNarratorHandler(log, store).
+		WithAdminKey(cfg.AdminKey).
+		WithGameStorages(d.GameStorages...)
+	mux.Handle("/v1/narrators", narratorHandler)
+	mux.Handle("/v1/narrators/", narratorHandler)
+
+	monsterHandler := handlers.NewMonsterHandler(log, store)
+	mux.Handle("/v1/monsters", monsterHandler)
+	mux.Handle("/v1/monsters/", monsterHandler)
+
+	return mux
+}
+
+// PreflightScenarios validates every scenario at startup and logs each error found.
+// Depending on mode, invalid scenarios are hidden from listings, or an error is
+// returned so the server doesn't start.
+func PreflightScenarios(ctx context.Context, store *storage.FileStore, mode string, log *slog.Logger) error {
+	results, err := store.PreflightScenarios(ctx)
+	if err != nil {
+		return err
+	}
+	var invalid []string
+	warnings := 0
+	for _, filename := range slices.Sorted(maps.Keys(results)) {
+		result := results[filename]
+		warnings += len(result.Warnings)
+		if result.Valid() {
+			continue
+		}
+		invalid = append(invalid, filename)
+		for _, f := range result.Errors {
+			log.Error("Scenario failed validation", "filename", filename, "line", f.Line, "path", f.Path, "rule", f.Rule, "error", f.Message)
+		}
+	}
+	log.Info("Scenario preflight complete", "scenarios", len(results), "invalid", len(invalid), "warnings", warnings)
+
+	switch {
+	case len(invalid) == 0:
+	case mode == config.PreflightRefuse:
+		return fmt.Errorf("%d invalid scenarios: %s", len(invalid), strings.Join(invalid, ", "))
+	case mode == config.PreflightHide:
+		store.HideScenarios(invalid...)
+		log.Warn("Invalid scenarios hidden from listings", "scenarios", invalid)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import "fmt"
+
+// softCapRatio is the share of a hard cap at which warnings start when no soft cap is set
+const softCapRatio = 0.8
+
+// BudgetCap limits token usage and estimated spend. Zero fields are unlimited.
+// Reaching a soft cap adds warning headers and alerts operators; reaching a hard cap
+// refuses further chat requests.
+type BudgetCap struct {
+	SoftTokens int64   `json:"soft_tokens,omitempty"`
+	HardTokens int64   `json:"hard_tokens,omitempty"`
+	SoftUSD    float64 `json:"soft_usd,omitempty"` // requires model pricing; see ModelCapabilities
+	HardUSD    float64 `json:"hard_usd,omitempty"`
+}
+
+// Budgets configures spend caps and operator alerts
+type Budgets struct {
+	Game         BudgetCap `json:"game"`                    // per game, over its lifetime
+	APIKey       BudgetCap `json:"api_key"`                 // per API key, per UTC calendar month
+	AlertWebhook string    `json:"alert_webhook,omitempty"` // URL that receives a POST when a cap is reached
+}
+
+// Enabled reports whether any cap is set
+func (c BudgetCap) Enabled() bool {
+	return c.HardTokens > 0 || c.HardUSD > 0 || c.SoftTokens > 0 || c.SoftUSD > 0
+}
+
+// SoftTokenCap returns the token count that triggers warnings,
+// defaulting to 80% of the hard cap when no soft cap is set
+func (c BudgetCap) SoftTokenCap() int64 {
+	if c.SoftTokens > 0 {
+		return c.SoftTokens
+	}
+	return int64(float64(c.HardTokens) * softCapRatio)
+}
+
+// SoftUSDCap returns the spend that triggers warnings,
+// defaulting to 80% of the hard cap when no soft cap is set
+func (c BudgetCap) SoftUSDCap() float64 {
+	if c.SoftUSD > 0 {
+		return c.SoftUSD
+	}
+	return c.HardUSD * softCapRatio
+}
+
+func (c BudgetCap) validate(name string) error {
+	if c.SoftTokens < 0 || c.HardTokens < 0 || c.SoftUSD < 0 || c.HardUSD < 0 {
+		return fmt.Errorf("budgets.%s caps must not be negative", name)
+	}
+	if c.HardTokens > 0 && c.SoftTokens > c.HardTokens {
+		return fmt.Errorf("budgets.%s soft_tokens must not exceed hard_tokens", name)
+	}
+	if c.HardUSD > 0 && c.SoftUSD > c.HardUSD {
+		return fmt.Errorf("budgets.%s soft_usd must not exceed hard_usd", name)
+	}
+	return nil
+}
+
+// validateBudgets checks that caps are non-negative and soft caps sit below hard caps
+func (c *Config) validateBudgets() error {
+	if err := c.Budgets.Game.validate("game"); err != nil {
+		return err
+	}
+	return c.Budgets.APIKey.validate("api_key")
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBudgetCap_SoftCaps(t *testing.T) {
+	tests := []struct {
+		name           string
+		cap            BudgetCap
+		expectedTokens int64
+		expectedUSD    float64
+	}{
+		{"explicit soft caps", BudgetCap{SoftTokens: 500, HardTokens: 1000, SoftUSD: 2, HardUSD: 5}, 500, 2},
+		{"defaults to 80% of hard caps", BudgetCap{HardTokens: 1000, HardUSD: 5}, 800, 4},
+		{"no caps", BudgetCap{}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cap.SoftTokenCap(); got != tt.expectedTokens {
+				t.Errorf("Expected soft token cap %d, got %d", tt.expectedTokens, got)
+			}
+			if got := tt.cap.SoftUSDCap(); got != tt.expectedUSD {
+				t.Errorf("Expected soft USD cap %v, got %v", tt.expectedUSD, got)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateBudgets(t *testing.T) {
+	tests := []struct {
+		name        string
+		budgets     Budgets
+		expectedErr string
+	}{
+		{"no budgets", Budgets{}, ""},
+		{"valid caps", Budgets{Game: BudgetCap{SoftTokens: 100, HardTokens: 200}, APIKey: BudgetCap{HardUSD: 10}}, ""},
+		{"soft cap without hard cap", Budgets{Game: BudgetCap{SoftUSD: 3}}, ""},
+		{"negative cap", Budgets{APIKey: BudgetCap{HardTokens: -1}}, "must not be negative"},
+		{"soft tokens above hard", Budgets{Game: BudgetCap{SoftTokens: 300, HardTokens: 200}}, "soft_tokens must not exceed hard_tokens"},
+		{"soft USD above hard", Budgets{APIKey: BudgetCap{SoftUSD: 11, HardUSD: 10}}, "soft_usd must not exceed hard_usd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Budgets: tt.budgets}
+			err := cfg.validateBudgets()
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
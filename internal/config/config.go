@@ -9,18 +9,40 @@ import (
 )
 
 type Config struct {
-	Port             string     `json:"port"`
-	Environment      string     `json:"environment"`
-	LogLevel         slog.Level `json:"-"`
-	LogLevelStr      string     `json:"log_level"`
-	LLMProvider      string     `json:"llm_provider"` // "anthropic" or "venice"
-	OllamaURL        string     `json:"ollama_url"`
-	VeniceAPIKey     string     `json:"venice_api_key"`
-	AnthropicAPIKey  string     `json:"anthropic_api_key"`
-	ModelName        string     `json:"model_name"`         // model name for LLM provider
-	BackendModelName string     `json:"backend_model_name"` // optional model for backend operations like MetaUpdate
-	RedisURL         string     `json:"redis_url"`
-	ChatHistoryLimit int        `json:"chat_history_limit"` // max number of past messages sent to LLM per request (0 = use default)
+	Port             string              `json:"port"`
+	Environment      string              `json:"environment"`
+	LogLevel         slog.Level          `json:"-"`
+	LogLevelStr      string              `json:"log_level"`
+	LLMProvider      string              `json:"llm_provider"` // "anthropic", "venice", or "ollama"
+	OllamaURL        string              `json:"ollama_url"`   // Ollama address for the ollama provider (default http://localhost:11434)
+	VeniceAPIKey     string              `json:"venice_api_key"`
+	AnthropicAPIKey  string              `json:"anthropic_api_key"`
+	ModelName        string              `json:"model_name"`         // model name for LLM provider
+	BackendModelName string              `json:"backend_model_name"` // optional model for backend operations like MetaUpdate
+	RedisURL         string              `json:"redis_url"`
+	ChatHistoryLimit int                 `json:"chat_history_limit"`  // max number of past messages sent to LLM per request (0 = use default)
+	Models           []ModelCapabilities `json:"models,omitempty"`    // model capability overrides; see DefaultModels
+	Profiles         []Profile           `json:"profiles,omitempty"`  // named tenant profiles; see Profile
+	APIKeys          map[string]string   `json:"api_keys,omitempty"`  // API key -> profile name; required when profiles are set
+	Budgets          Budgets             `json:"budgets"`             // token and spend caps; see Budgets
+	AdminKey         string              `json:"admin_key,omitempty"` // enables admin-only endpoints, sent as X-Admin-Key
+	TranslateInput   bool                `json:"translate_input"`     // translate non-English player messages before the gamestate delta
+	CompactDeltaAt   int                 `json:"compact_delta_chars"` // narrations this long or longer get a compact before-state in the gamestate delta (0 = never)
+	MaxNarration     int                 `json:"max_narration_chars"` // narration is cut at the last sentence end before this many characters (0 = no limit)
+	EventSourcing    bool                `json:"event_sourcing"`      // record every game state save in an append-only event log
+	Encryption       Encryption          `json:"encryption"`          // encryption of game states at rest; see Encryption
+	SpectatorDelay   int                 `json:"spectator_delay"`     // seconds the spectator feed lags behind public games (0 = 30)
+	SSEHeartbeat     int                 `json:"heartbeat_interval"`  // seconds between heartbeats on idle SSE streams (0 = 15)
+	WebSocketOrigins []string            `json:"websocket_origins"`   // browser origins besides the API's own that may open /v1/ws
+	ShutdownTimeout  int                 `json:"shutdown_timeout"`    // seconds to wait on shutdown for in-flight requests and gamestate deltas (0 = 30)
+	TextFilter       TextFilter          `json:"text_filter"`         // profanity word lists for narration; see TextFilter
+	ConsistencyCheck string              `json:"consistency_check"`   // check narration against the game state: "", "annotate", or "regenerate"
+	NarrationMeta    bool                `json:"narration_meta"`      // ask the narrator for a trailer of mood, NPCs present, and choices, stored with each turn
+	CaptureDir       string              `json:"capture_dir"`         // dev only: write every LLM request and response under this directory, per game and turn
+	Logging          Logging             `json:"logging"`             // log file, per-module levels, and sampling; see Logging
+	ContentIntegrity ContentIntegrity    `json:"content_integrity"`   // verify content files against a checksum manifest; see ContentIntegrity
+	Preflight        string              `json:"scenario_preflight"`  // on startup, log invalid scenarios (""), "hide" them from listings, or "refuse" to start
+	ScenarioStore    string              `json:"scenario_store"`      // where scenarios saved through the API go: the data directory ("") or "redis"
 }
 
 func Load() (*Config, error) {
@@ -41,6 +63,49 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file %s: %v", configFile, err)
 	}
 
+	if err := config.validateProfiles(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateBudgets(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateEncryption(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateSpectators(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateHeartbeat(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateWebSocketOrigins(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateShutdown(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateTextFilter(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateReducerPrompts(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateConsistencyCheck(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateLogging(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateContentIntegrity(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validatePreflight(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+	if err := config.validateScenarioStore(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+
 	// Parse log level from string
 	config.LogLevel = parseLogLevel(config.LogLevelStr)
 	return &config, nil
@@ -0,0 +1,19 @@
+package config
+
+import "fmt"
+
+// Modes of the narration consistency check
+const (
+	ConsistencyOff        = ""           // narration isn't checked
+	ConsistencyAnnotate   = "annotate"   // inconsistencies are recorded in the turn receipt
+	ConsistencyRegenerate = "regenerate" // a narration with inconsistencies is written again, once
+)
+
+// validateConsistencyCheck checks that consistency_check is a known mode
+func (c *Config) validateConsistencyCheck() error {
+	switch c.ConsistencyCheck {
+	case ConsistencyOff, ConsistencyAnnotate, ConsistencyRegenerate:
+		return nil
+	}
+	return fmt.Errorf("consistency_check must be %q or %q, got %q", ConsistencyAnnotate, ConsistencyRegenerate, c.ConsistencyCheck)
+}
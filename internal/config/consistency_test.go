@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestConfig_ValidateConsistencyCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		expectErr bool
+	}{
+		{"off", ConsistencyOff, false},
+		{"annotate", ConsistencyAnnotate, false},
+		{"regenerate", ConsistencyRegenerate, false},
+		{"unknown", "strict", true},
+		{"wrong case", "Annotate", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{ConsistencyCheck: tt.mode}
+			err := cfg.validateConsistencyCheck()
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected an error for %q", tt.mode)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error for %q, got %v", tt.mode, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Encryption configures AES-GCM encryption of game states at rest. Keys are
+// base64-encoded and 16, 24 or 32 bytes long, for AES-128, AES-192 or AES-256.
+// Games are saved with the active key and read with whichever key saved them, so
+// to rotate, add a new key, make it active, and keep the old one until every game
+// saved with it has been saved again or expired.
+type Encryption struct {
+	Keys      map[string]string `json:"keys,omitempty"`       // key ID -> base64-encoded key
+	ActiveKey string            `json:"active_key,omitempty"` // ID of the key new saves are encrypted with
+}
+
+// Enabled reports whether new saves are encrypted
+func (e Encryption) Enabled() bool {
+	return e.ActiveKey != ""
+}
+
+// DecodedKeys returns the keys by ID, decoded from base64
+func (e Encryption) DecodedKeys() (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(e.Keys))
+	for id, encoded := range e.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q is not valid base64: %w", id, err)
+		}
+		if n := len(key); n != 16 && n != 24 && n != 32 {
+			return nil, fmt.Errorf("encryption key %q is %d bytes; must be 16, 24 or 32", id, n)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// validateEncryption checks that every key decodes and the active key is one of them
+func (c *Config) validateEncryption() error {
+	if _, err := c.Encryption.DecodedKeys(); err != nil {
+		return err
+	}
+	if c.Encryption.ActiveKey == "" {
+		if len(c.Encryption.Keys) > 0 {
+			return fmt.Errorf("encryption.active_key is required when encryption keys are set")
+		}
+		return nil
+	}
+	if _, ok := c.Encryption.Keys[c.Encryption.ActiveKey]; !ok {
+		return fmt.Errorf("encryption.active_key %q is not one of encryption.keys", c.Encryption.ActiveKey)
+	}
+	return nil
+}
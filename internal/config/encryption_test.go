@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateEncryption(t *testing.T) {
+	key128 := "MDEyMzQ1Njc4OWFiY2RlZg=="                     // 16 bytes
+	key256 := "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // 32 bytes
+
+	tests := []struct {
+		name        string
+		encryption  Encryption
+		expectedErr string
+	}{
+		{"no encryption", Encryption{}, ""},
+		{"active key", Encryption{Keys: map[string]string{"k1": key128, "k2": key256}, ActiveKey: "k2"}, ""},
+		{"unknown active key", Encryption{Keys: map[string]string{"k1": key128}, ActiveKey: "k2"}, "is not one of encryption.keys"},
+		{"keys without an active key", Encryption{Keys: map[string]string{"k1": key128}}, "active_key is required"},
+		{"invalid base64", Encryption{Keys: map[string]string{"k1": "not base64!"}, ActiveKey: "k1"}, "not valid base64"},
+		{"wrong key length", Encryption{Keys: map[string]string{"k1": "c2hvcnQ="}, ActiveKey: "k1"}, "must be 16, 24 or 32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Encryption: tt.encryption}
+			err := cfg.validateEncryption()
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultHeartbeatInterval is how often SSE streams send a heartbeat when
+	// heartbeat_interval isn't set
+	DefaultHeartbeatInterval = 15 * time.Second
+	// maxHeartbeatInterval keeps heartbeats frequent enough for common proxy idle timeouts
+	maxHeartbeatInterval = 5 * time.Minute
+)
+
+// HeartbeatInterval returns how often SSE streams send a heartbeat while no other events are sent
+func (c *Config) HeartbeatInterval() time.Duration {
+	if c.SSEHeartbeat == 0 {
+		return DefaultHeartbeatInterval
+	}
+	return time.Duration(c.SSEHeartbeat) * time.Second
+}
+
+// validateHeartbeat checks that the heartbeat interval is positive and not too long
+func (c *Config) validateHeartbeat() error {
+	if c.SSEHeartbeat < 0 {
+		return fmt.Errorf("heartbeat_interval must not be negative")
+	}
+	if c.HeartbeatInterval() > maxHeartbeatInterval {
+		return fmt.Errorf("heartbeat_interval must be at most %d seconds", int(maxHeartbeatInterval.Seconds()))
+	}
+	return nil
+}
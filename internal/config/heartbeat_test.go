@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_Heartbeat(t *testing.T) {
+	tests := []struct {
+		name             string
+		seconds          int
+		expectedInterval time.Duration
+		expectErr        bool
+	}{
+		{"default", 0, DefaultHeartbeatInterval, false},
+		{"configured", 5, 5 * time.Second, false},
+		{"longest", 300, 5 * time.Minute, false},
+		{"too long", 301, 0, true},
+		{"negative", -1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{SSEHeartbeat: tt.seconds}
+			err := cfg.validateHeartbeat()
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error for %d seconds", tt.seconds)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if got := cfg.HeartbeatInterval(); got != tt.expectedInterval {
+				t.Errorf("Expected interval %v, got %v", tt.expectedInterval, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+)
+
+// ContentIntegrity configures verification of content files (scenarios, narrators,
+// PCs, monsters, and NPCs) against a checksum manifest built with cmd/manifest.
+// Files that are missing from the manifest or don't match it are refused when loaded.
+// With a public key, the manifest itself must be signed with the matching private key,
+// so only its holder can approve content changes.
+type ContentIntegrity struct {
+	Manifest  string `json:"manifest,omitempty"`   // path of the checksum manifest; enables verification
+	PublicKey string `json:"public_key,omitempty"` // optional base64-encoded Ed25519 public key the manifest must be signed with
+}
+
+// Enabled reports whether content files are verified
+func (c ContentIntegrity) Enabled() bool {
+	return c.Manifest != ""
+}
+
+// DecodedPublicKey returns the public key decoded from base64, or nil if none is set
+func (c ContentIntegrity) DecodedPublicKey() (ed25519.PublicKey, error) {
+	if c.PublicKey == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(c.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("content_integrity.public_key is not valid base64: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("content_integrity.public_key is %d bytes; must be %d", len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// validateContentIntegrity checks that the public key decodes and comes with a manifest
+func (c *Config) validateContentIntegrity() error {
+	if _, err := c.ContentIntegrity.DecodedPublicKey(); err != nil {
+		return err
+	}
+	if c.ContentIntegrity.PublicKey != "" && c.ContentIntegrity.Manifest == "" {
+		return fmt.Errorf("content_integrity.manifest is required when a public key is set")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateContentIntegrity(t *testing.T) {
+	publicKey := "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=" // 32 bytes
+
+	tests := []struct {
+		name        string
+		integrity   ContentIntegrity
+		expectedErr string
+	}{
+		{"off", ContentIntegrity{}, ""},
+		{"unsigned manifest", ContentIntegrity{Manifest: "./data/manifest.json"}, ""},
+		{"signed manifest", ContentIntegrity{Manifest: "./data/manifest.json", PublicKey: publicKey}, ""},
+		{"key without a manifest", ContentIntegrity{PublicKey: publicKey}, "manifest is required"},
+		{"invalid base64", ContentIntegrity{Manifest: "m.json", PublicKey: "not base64!"}, "not valid base64"},
+		{"wrong key length", ContentIntegrity{Manifest: "m.json", PublicKey: "c2hvcnQ="}, "must be 32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{ContentIntegrity: tt.integrity}
+			err := cfg.validateContentIntegrity()
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
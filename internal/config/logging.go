@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Default rotation for the log file
+const (
+	DefaultLogMaxSizeMB  = 100
+	DefaultLogMaxBackups = 5
+)
+
+// Logging configures where logs go besides stdout, and how much is kept. Modules
+// are the parts of the service that tag their logs, e.g. "handlers", "worker",
+// "storage", "queue", or "llm"; their levels override log_level. Sample keeps one
+// in every N records logged with a category, such as "prompt" for full prompt dumps.
+type Logging struct {
+	File       string            `json:"file,omitempty"`        // also write JSON logs to this file, rotated by size
+	MaxSizeMB  int               `json:"max_size_mb,omitempty"` // rotate the file when it reaches this size (0 = 100)
+	MaxBackups int               `json:"max_backups,omitempty"` // rotated files kept as file.1 to file.N (0 = 5)
+	Modules    map[string]string `json:"modules,omitempty"`     // module -> level
+	Sample     map[string]int    `json:"sample,omitempty"`      // category -> keep 1 in N records
+}
+
+// ModuleLevels returns the level of each module with an override
+func (l Logging) ModuleLevels() map[string]slog.Level {
+	levels := make(map[string]slog.Level, len(l.Modules))
+	for module, level := range l.Modules {
+		levels[module] = parseLogLevel(level)
+	}
+	return levels
+}
+
+// FileMaxBytes returns the size at which the log file is rotated
+func (l Logging) FileMaxBytes() int64 {
+	if l.MaxSizeMB == 0 {
+		return DefaultLogMaxSizeMB << 20
+	}
+	return int64(l.MaxSizeMB) << 20
+}
+
+// FileMaxBackups returns how many rotated log files are kept
+func (l Logging) FileMaxBackups() int {
+	if l.MaxBackups == 0 {
+		return DefaultLogMaxBackups
+	}
+	return l.MaxBackups
+}
+
+// validateLogging checks the module levels, sample rates and rotation sizes
+func (c *Config) validateLogging() error {
+	if c.Logging.MaxSizeMB < 0 || c.Logging.MaxBackups < 0 {
+		return fmt.Errorf("logging.max_size_mb and logging.max_backups must not be negative")
+	}
+	for _, module := range slices.Sorted(maps.Keys(c.Logging.Modules)) {
+		switch strings.ToLower(c.Logging.Modules[module]) {
+		case "debug", "info", "warn", "warning", "error":
+		default:
+			return fmt.Errorf("logging.modules.%s: unknown level %q (supported: debug, info, warn, error)", module, c.Logging.Modules[module])
+		}
+	}
+	for _, category := range slices.Sorted(maps.Keys(c.Logging.Sample)) {
+		if c.Logging.Sample[category] < 1 {
+			return fmt.Errorf("logging.sample.%s must be at least 1", category)
+		}
+	}
+	return nil
+}
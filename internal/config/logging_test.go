@@ -0,0 +1,56 @@
+package config
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateLogging(t *testing.T) {
+	tests := []struct {
+		name        string
+		logging     Logging
+		expectedErr string
+	}{
+		{"defaults", Logging{}, ""},
+		{"file and overrides", Logging{
+			File:       "story-engine.log",
+			MaxSizeMB:  10,
+			MaxBackups: 2,
+			Modules:    map[string]string{"storage": "warn", "worker": "DEBUG"},
+			Sample:     map[string]int{"prompt": 10},
+		}, ""},
+		{"negative size", Logging{MaxSizeMB: -1}, "must not be negative"},
+		{"unknown level", Logging{Modules: map[string]string{"handlers": "verbose"}}, "logging.modules.handlers"},
+		{"zero sample", Logging{Sample: map[string]int{"prompt": 0}}, "logging.sample.prompt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Logging: tt.logging}
+			err := cfg.validateLogging()
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLogging_Defaults(t *testing.T) {
+	l := Logging{Modules: map[string]string{"storage": "warn"}}
+	if got := l.FileMaxBytes(); got != DefaultLogMaxSizeMB<<20 {
+		t.Errorf("FileMaxBytes() = %d", got)
+	}
+	if got := l.FileMaxBackups(); got != DefaultLogMaxBackups {
+		t.Errorf("FileMaxBackups() = %d", got)
+	}
+	if got := l.ModuleLevels()["storage"]; got != slog.LevelWarn {
+		t.Errorf("ModuleLevels()[storage] = %v", got)
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// ModelCapabilities describes what a model supports.
+// Name is an exact model name or a pattern with a leading and/or trailing "*",
+// e.g. "claude*" or "*gpt*". Matching is case-insensitive.
+type ModelCapabilities struct {
+	Name          string         `json:"name"`
+	Ratings       []string       `json:"ratings,omitempty"`             // allowed scenario ratings; omitted allows all
+	ContextWindow int            `json:"context_window,omitempty"`      // in tokens; 0 if unknown
+	Tools         bool           `json:"tools"`                         // supports tool/function calling
+	Streaming     bool           `json:"streaming"`                     // supports streamed responses
+	InputUSDPerM  float64        `json:"input_usd_per_mtok,omitempty"`  // price per million input tokens, for spend caps
+	OutputUSDPerM float64        `json:"output_usd_per_mtok,omitempty"` // price per million output tokens
+	Reducer       *ReducerPrompt `json:"reducer,omitempty"`             // replaces the built-in reducer prompt; see ReducerPrompt
+}
+
+// censoredRatings are the ratings allowed for hosted models with content policies
+var censoredRatings = []string{"G", "PG", "PG-13"}
+
+// DefaultModels are the built-in capability entries. Configured entries take precedence.
+var DefaultModels = []ModelCapabilities{
+	{Name: "claude*", Ratings: censoredRatings, ContextWindow: 200000, Tools: true, Streaming: true},
+	{Name: "*anthropic*", Ratings: censoredRatings, ContextWindow: 200000, Tools: true, Streaming: true},
+	{Name: "*gpt*", Ratings: censoredRatings, ContextWindow: 128000, Tools: true, Streaming: true},
+	{Name: "*openai*", Ratings: censoredRatings, ContextWindow: 128000, Tools: true, Streaming: true},
+	{Name: "text-davinci*", Ratings: censoredRatings, ContextWindow: 4096, Streaming: true},
+	{Name: "text-curie*", Ratings: censoredRatings, ContextWindow: 2048, Streaming: true},
+	{Name: "text-babbage*", Ratings: censoredRatings, ContextWindow: 2048, Streaming: true},
+	{Name: "text-ada*", Ratings: censoredRatings, ContextWindow: 2048, Streaming: true},
+}
+
+// ModelRegistry resolves capabilities by model name
+type ModelRegistry struct {
+	models     []ModelCapabilities
+	configured int // number of leading entries that came from config
+}
+
+// NewModelRegistry builds a registry from configured entries followed by DefaultModels.
+// Entries are matched in order, so configured models override the defaults.
+func NewModelRegistry(models []ModelCapabilities) *ModelRegistry {
+	all := make([]ModelCapabilities, 0, len(models)+len(DefaultModels))
+	all = append(all, models...)
+	all = append(all, DefaultModels...)
+	return &ModelRegistry{models: all, configured: len(models)}
+}
+
+// ModelRegistry returns a registry for the models in this config
+func (c *Config) ModelRegistry() *ModelRegistry {
+	return NewModelRegistry(c.Models)
+}
+
+// Lookup returns the capabilities of the first entry matching modelName.
+// Unknown models are assumed to support all ratings and streaming.
+func (r *ModelRegistry) Lookup(modelName string) ModelCapabilities {
+	for _, m := range r.models {
+		if matchModelName(m.Name, modelName) {
+			return m
+		}
+	}
+	return ModelCapabilities{Name: modelName, Streaming: true}
+}
+
+// IsConfigured reports whether modelName matches an entry from config rather than a default
+func (r *ModelRegistry) IsConfigured(modelName string) bool {
+	for _, m := range r.models[:r.configured] {
+		if matchModelName(m.Name, modelName) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfiguredNames returns the names (or patterns) of the configured entries
+func (r *ModelRegistry) ConfiguredNames() []string {
+	names := make([]string, 0, r.configured)
+	for _, m := range r.models[:r.configured] {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// AllowsRating reports whether the model may run a scenario with the given content rating
+func (c ModelCapabilities) AllowsRating(rating string) bool {
+	if c.Ratings == nil {
+		return true
+	}
+	return slices.ContainsFunc(c.Ratings, func(r string) bool {
+		return normalizeRating(r) == normalizeRating(rating)
+	})
+}
+
+// Cost returns the estimated spend in USD for the given token counts.
+// Models without pricing cost nothing.
+func (c ModelCapabilities) Cost(inputTokens, outputTokens int64) float64 {
+	return (float64(inputTokens)*c.InputUSDPerM + float64(outputTokens)*c.OutputUSDPerM) / 1e6
+}
+
+// normalizeRating treats "PG13" and "pg-13" as "PG-13"
+func normalizeRating(rating string) string {
+	rating = strings.ToUpper(strings.TrimSpace(rating))
+	if rating == "PG13" {
+		return "PG-13"
+	}
+	return rating
+}
+
+func matchModelName(pattern, modelName string) bool {
+	pattern = strings.ToLower(pattern)
+	modelName = strings.ToLower(modelName)
+	prefix := strings.HasSuffix(pattern, "*")
+	suffix := strings.HasPrefix(pattern, "*")
+	core := strings.Trim(pattern, "*")
+	switch {
+	case prefix && suffix:
+		return strings.Contains(modelName, core)
+	case prefix:
+		return strings.HasPrefix(modelName, core)
+	case suffix:
+		return strings.HasSuffix(modelName, core)
+	default:
+		return modelName == core
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import "testing"
+
+func TestModelRegistry_Lookup(t *testing.T) {
+	registry := NewModelRegistry([]ModelCapabilities{
+		{Name: "claude-custom", ContextWindow: 1000, Streaming: false},
+		{Name: "*-mini", Ratings: []string{"G", "PG"}, Streaming: true},
+	})
+
+	tests := []struct {
+		name          string
+		model         string
+		expectedName  string
+		allowsR       bool
+		allowsPG13    bool
+		expectStreams bool
+	}{
+		{"configured exact match overrides default", "claude-custom", "claude-custom", true, true, false},
+		{"default prefix match", "Claude-Sonnet-4", "claude*", false, true, true},
+		{"default contains match", "openai/gpt-4o", "*gpt*", false, true, true},
+		{"configured suffix match", "venice-mini", "*-mini", false, false, true},
+		{"unknown model allows everything", "llama-3.3-70b", "llama-3.3-70b", true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := registry.Lookup(tt.model)
+			if caps.Name != tt.expectedName {
+				t.Errorf("Expected entry %q, got %q", tt.expectedName, caps.Name)
+			}
+			if got := caps.AllowsRating("R"); got != tt.allowsR {
+				t.Errorf("AllowsRating(R) = %v, want %v", got, tt.allowsR)
+			}
+			if got := caps.AllowsRating("PG13"); got != tt.allowsPG13 {
+				t.Errorf("AllowsRating(PG13) = %v, want %v", got, tt.allowsPG13)
+			}
+			if caps.Streaming != tt.expectStreams {
+				t.Errorf("Streaming = %v, want %v", caps.Streaming, tt.expectStreams)
+			}
+		})
+	}
+}
+
+func TestModelCapabilities_Cost(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    ModelCapabilities
+		input    int64
+		output   int64
+		expected float64
+	}{
+		{"priced model", ModelCapabilities{InputUSDPerM: 3, OutputUSDPerM: 15}, 1_000_000, 200_000, 6},
+		{"unpriced model", ModelCapabilities{}, 1_000_000, 200_000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.model.Cost(tt.input, tt.output); got != tt.expected {
+				t.Errorf("Expected cost %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import "fmt"
+
+// What the API does at startup with scenarios that fail validation
+const (
+	PreflightLog    = ""       // invalid scenarios are logged and still listed
+	PreflightHide   = "hide"   // invalid scenarios are logged and left out of listings
+	PreflightRefuse = "refuse" // the server won't start while any scenario is invalid
+)
+
+// validatePreflight checks that scenario_preflight is a known mode
+func (c *Config) validatePreflight() error {
+	switch c.Preflight {
+	case PreflightLog, PreflightHide, PreflightRefuse:
+		return nil
+	}
+	return fmt.Errorf("scenario_preflight must be %q or %q, got %q", PreflightHide, PreflightRefuse, c.Preflight)
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestConfig_ValidatePreflight(t *testing.T) {
+	tests := []struct {
+		mode      string
+		expectErr bool
+	}{
+		{PreflightLog, false},
+		{PreflightHide, false},
+		{PreflightRefuse, false},
+		{"strict", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := &Config{Preflight: tt.mode}
+			if err := cfg.validatePreflight(); (err != nil) != tt.expectErr {
+				t.Errorf("validatePreflight(%q) error = %v, expectErr %v", tt.mode, err, tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Profile is a named configuration for one community or app sharing a deployment.
+// Requests select a profile by API key (see Config.APIKeys). Empty fields inherit
+// the top-level config.
+type Profile struct {
+	Name             string `json:"name"`
+	LLMProvider      string `json:"llm_provider,omitempty"`       // "anthropic", "venice", or "ollama"
+	ModelName        string `json:"model_name,omitempty"`         // default model for games created under this profile
+	BackendModelName string `json:"backend_model_name,omitempty"` // optional model for backend operations
+	MaxRating        string `json:"max_rating,omitempty"`         // highest scenario rating allowed, e.g. "PG-13"; empty allows all
+	RateLimit        int    `json:"rate_limit,omitempty"`         // max API requests per minute; 0 = unlimited
+	DailyChatLimit   int    `json:"daily_chat_limit,omitempty"`   // max chat requests per UTC day; 0 = unlimited
+	StoragePrefix    string `json:"storage_prefix,omitempty"`     // prefix for this profile's game state keys
+}
+
+// ratingOrder lists scenario ratings from least to most restricted
+var ratingOrder = []string{"G", "PG", "PG-13", "R"}
+
+// Profile returns the named profile with empty fields filled from the top-level config.
+// The empty name returns the default profile built from the top-level config alone.
+func (c *Config) Profile(name string) (Profile, bool) {
+	p := Profile{}
+	if name != "" {
+		i := slices.IndexFunc(c.Profiles, func(p Profile) bool { return p.Name == name })
+		if i < 0 {
+			return Profile{}, false
+		}
+		p = c.Profiles[i]
+	}
+	if p.LLMProvider == "" {
+		p.LLMProvider = c.LLMProvider
+	}
+	if p.ModelName == "" {
+		p.ModelName = c.ModelName
+		if p.BackendModelName == "" {
+			p.BackendModelName = c.BackendModelName
+		}
+	}
+	return p, true
+}
+
+// ProfileNames returns the names of all configured profiles, or just the default
+// profile ("") when none are configured
+func (c *Config) ProfileNames() []string {
+	if len(c.Profiles) == 0 {
+		return []string{""}
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for _, p := range c.Profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// validateProfiles checks that profiles are uniquely named and that every API key
+// maps to a configured profile
+func (c *Config) validateProfiles() error {
+	seen := make(map[string]bool)
+	for _, p := range c.Profiles {
+		if p.Name == "" {
+			return fmt.Errorf("profile name is required")
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate profile name %q", p.Name)
+		}
+		seen[p.Name] = true
+		if p.MaxRating != "" && !slices.Contains(ratingOrder, normalizeRating(p.MaxRating)) {
+			return fmt.Errorf("profile %q has invalid max_rating %q (supported: %s)", p.Name, p.MaxRating, strings.Join(ratingOrder, ", "))
+		}
+		if p.RateLimit < 0 || p.DailyChatLimit < 0 {
+			return fmt.Errorf("profile %q limits must not be negative", p.Name)
+		}
+	}
+	for key, name := range c.APIKeys {
+		if key == "" {
+			return fmt.Errorf("api_keys contains an empty key")
+		}
+		if !seen[name] {
+			return fmt.Errorf("api key is mapped to unknown profile %q", name)
+		}
+	}
+	if len(c.Profiles) > 0 && len(c.APIKeys) == 0 {
+		return fmt.Errorf("profiles are configured but no api_keys select them")
+	}
+	return nil
+}
+
+// LimitRatings returns a copy of the capabilities allowing only ratings up to maxRating.
+// An empty maxRating leaves the capabilities unchanged.
+func (c ModelCapabilities) LimitRatings(maxRating string) ModelCapabilities {
+	if maxRating == "" {
+		return c
+	}
+	limit := slices.Index(ratingOrder, normalizeRating(maxRating))
+	if limit < 0 {
+		return c
+	}
+	var allowed []string
+	for _, r := range ratingOrder[:limit+1] {
+		if c.AllowsRating(r) {
+			allowed = append(allowed, r)
+		}
+	}
+	if allowed == nil {
+		allowed = []string{}
+	}
+	c.Ratings = allowed
+	return c
+}
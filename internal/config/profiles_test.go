@@ -0,0 +1,103 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_Profile(t *testing.T) {
+	cfg := &Config{
+		LLMProvider:      "anthropic",
+		ModelName:        "claude-sonnet-4-6",
+		BackendModelName: "claude-haiku-4-5",
+		Profiles: []Profile{
+			{Name: "kids", MaxRating: "PG"},
+			{Name: "horror", LLMProvider: "venice", ModelName: "llama-3.3-70b"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		profile          string
+		expectedFound    bool
+		expectedProvider string
+		expectedModel    string
+		expectedBackend  string
+	}{
+		{"default profile", "", true, "anthropic", "claude-sonnet-4-6", "claude-haiku-4-5"},
+		{"inherits provider and models", "kids", true, "anthropic", "claude-sonnet-4-6", "claude-haiku-4-5"},
+		{"own model does not inherit backend model", "horror", true, "venice", "llama-3.3-70b", ""},
+		{"unknown profile", "nope", false, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := cfg.Profile(tt.profile)
+			if ok != tt.expectedFound {
+				t.Fatalf("Expected found=%v, got %v", tt.expectedFound, ok)
+			}
+			if p.LLMProvider != tt.expectedProvider || p.ModelName != tt.expectedModel || p.BackendModelName != tt.expectedBackend {
+				t.Errorf("Expected %s/%s/%s, got %s/%s/%s", tt.expectedProvider, tt.expectedModel, tt.expectedBackend,
+					p.LLMProvider, p.ModelName, p.BackendModelName)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateProfiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           Config
+		expectedError string
+	}{
+		{"no profiles", Config{}, ""},
+		{"valid", Config{Profiles: []Profile{{Name: "a", MaxRating: "pg13"}}, APIKeys: map[string]string{"k": "a"}}, ""},
+		{"missing name", Config{Profiles: []Profile{{}}, APIKeys: map[string]string{"k": ""}}, "profile name is required"},
+		{"duplicate name", Config{Profiles: []Profile{{Name: "a"}, {Name: "a"}}, APIKeys: map[string]string{"k": "a"}}, "duplicate profile name"},
+		{"bad rating", Config{Profiles: []Profile{{Name: "a", MaxRating: "NC-17"}}, APIKeys: map[string]string{"k": "a"}}, "invalid max_rating"},
+		{"negative limit", Config{Profiles: []Profile{{Name: "a", RateLimit: -1}}, APIKeys: map[string]string{"k": "a"}}, "must not be negative"},
+		{"unknown profile key", Config{Profiles: []Profile{{Name: "a"}}, APIKeys: map[string]string{"k": "b"}}, "unknown profile"},
+		{"profiles without keys", Config{Profiles: []Profile{{Name: "a"}}}, "no api_keys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateProfiles()
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestModelCapabilities_LimitRatings(t *testing.T) {
+	censored := ModelCapabilities{Ratings: []string{"G", "PG", "PG-13"}}
+	uncensored := ModelCapabilities{}
+
+	tests := []struct {
+		name      string
+		caps      ModelCapabilities
+		maxRating string
+		expected  string
+	}{
+		{"no limit", uncensored, "", ""},
+		{"limit uncensored model", uncensored, "PG-13", "G,PG,PG-13"},
+		{"limit below model ratings", censored, "PG", "G,PG"},
+		{"limit above model ratings", censored, "R", "G,PG,PG-13"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(tt.caps.LimitRatings(tt.maxRating).Ratings, ",")
+			if got != tt.expected {
+				t.Errorf("Expected ratings %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContingencyRulesVar is replaced in a reducer prompt template with the scenario's
+// contingency rules, one per line
+const ContingencyRulesVar = "{{contingency_rules}}"
+
+// ReducerPrompt replaces the built-in reducer prompt for the models an entry matches, e.g.
+// to phrase the rules more plainly for a small local model. A pattern such as "llama*"
+// covers a model family. The version is recorded on each delta the prompt produces.
+type ReducerPrompt struct {
+	Version  string `json:"version"`  // e.g. "llama-2"; bump it when the template changes
+	Template string `json:"template"` // prompt text; see ContingencyRulesVar
+}
+
+// Render fills in the template's contingency rules
+func (r *ReducerPrompt) Render(contingencyRules []string) string {
+	return strings.ReplaceAll(r.Template, ContingencyRulesVar, strings.Join(contingencyRules, "\n- "))
+}
+
+// validateReducerPrompts checks that every reducer prompt override has a version and a template
+func (c *Config) validateReducerPrompts() error {
+	for _, m := range c.Models {
+		if m.Reducer == nil {
+			continue
+		}
+		if strings.TrimSpace(m.Reducer.Version) == "" {
+			return fmt.Errorf("models: %s: reducer needs a version", m.Name)
+		}
+		if strings.TrimSpace(m.Reducer.Template) == "" {
+			return fmt.Errorf("models: %s: reducer needs a template", m.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestConfig_ValidateReducerPrompts(t *testing.T) {
+	tests := []struct {
+		name      string
+		reducer   *ReducerPrompt
+		expectErr bool
+	}{
+		{"no override", nil, false},
+		{"override", &ReducerPrompt{Version: "llama-1", Template: "Rules:\n- " + ContingencyRulesVar}, false},
+		{"override without rules", &ReducerPrompt{Version: "llama-1", Template: "Output JSON only."}, false},
+		{"missing version", &ReducerPrompt{Template: "Output JSON only."}, true},
+		{"missing template", &ReducerPrompt{Version: "llama-1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Models: []ModelCapabilities{{Name: "llama*", Reducer: tt.reducer}}}
+			err := cfg.validateReducerPrompts()
+			if tt.expectErr && err == nil {
+				t.Error("Expected an error")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReducerPrompt_Render(t *testing.T) {
+	r := &ReducerPrompt{Version: "llama-1", Template: "Apply these rules:\n- " + ContingencyRulesVar + "\nOutput JSON only."}
+	got := r.Render([]string{"Rule one.", "Rule two."})
+	want := "Apply these rules:\n- Rule one.\n- Rule two.\nOutput JSON only."
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
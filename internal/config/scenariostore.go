@@ -0,0 +1,18 @@
+package config
+
+import "fmt"
+
+// Where scenarios created or edited through the API are stored
+const (
+	ScenarioStoreFile  = ""      // in the data directory of the API instance that saved them
+	ScenarioStoreRedis = "redis" // in Redis, shared by every API instance and worker
+)
+
+// validateScenarioStore checks that scenario_store is a known store
+func (c *Config) validateScenarioStore() error {
+	switch c.ScenarioStore {
+	case ScenarioStoreFile, ScenarioStoreRedis:
+		return nil
+	}
+	return fmt.Errorf("scenario_store must be %q or empty, got %q", ScenarioStoreRedis, c.ScenarioStore)
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestConfig_ValidateScenarioStore(t *testing.T) {
+	tests := []struct {
+		store     string
+		expectErr bool
+	}{
+		{ScenarioStoreFile, false},
+		{ScenarioStoreRedis, false},
+		{"postgres", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.store, func(t *testing.T) {
+			cfg := &Config{ScenarioStore: tt.store}
+			if err := cfg.validateScenarioStore(); (err != nil) != tt.expectErr {
+				t.Errorf("validateScenarioStore(%q) error = %v, expectErr %v", tt.store, err, tt.expectErr)
+			}
+		})
+	}
+}
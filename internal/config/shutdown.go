@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long a process waits, once told to stop, for in-flight
+// requests and background gamestate deltas when shutdown_timeout isn't set
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ShutdownDrain returns how long to wait on shutdown for in-flight work to finish
+func (c *Config) ShutdownDrain() time.Duration {
+	if c.ShutdownTimeout == 0 {
+		return DefaultShutdownTimeout
+	}
+	return time.Duration(c.ShutdownTimeout) * time.Second
+}
+
+// validateShutdown checks that the shutdown timeout is not negative
+func (c *Config) validateShutdown() error {
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("shutdown_timeout must not be negative")
+	}
+	return nil
+}
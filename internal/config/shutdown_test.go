@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_ShutdownDrain(t *testing.T) {
+	tests := []struct {
+		name          string
+		seconds       int
+		expectedDrain time.Duration
+		expectErr     bool
+	}{
+		{"default", 0, DefaultShutdownTimeout, false},
+		{"configured", 120, 2 * time.Minute, false},
+		{"negative", -1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{ShutdownTimeout: tt.seconds}
+			err := cfg.validateShutdown()
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error for %d seconds", tt.seconds)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if got := cfg.ShutdownDrain(); got != tt.expectedDrain {
+				t.Errorf("Expected drain %v, got %v", tt.expectedDrain, got)
+			}
+		})
+	}
+}
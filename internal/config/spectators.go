@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultSpectatorDelay is how far the spectator feed lags behind the game when
+// spectator_delay isn't set
+const DefaultSpectatorDelay = 30 * time.Second
+
+// SpectatorLag returns how long narration is held back before spectators see it
+func (c *Config) SpectatorLag() time.Duration {
+	if c.SpectatorDelay == 0 {
+		return DefaultSpectatorDelay
+	}
+	return time.Duration(c.SpectatorDelay) * time.Second
+}
+
+// validateSpectators checks that the spectator delay is not negative
+func (c *Config) validateSpectators() error {
+	if c.SpectatorDelay < 0 {
+		return fmt.Errorf("spectator_delay must not be negative")
+	}
+	return nil
+}
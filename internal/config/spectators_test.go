@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_SpectatorLag(t *testing.T) {
+	tests := []struct {
+		name          string
+		seconds       int
+		expectedDelay time.Duration
+		expectErr     bool
+	}{
+		{"default", 0, DefaultSpectatorDelay, false},
+		{"configured", 90, 90 * time.Second, false},
+		{"negative", -1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{SpectatorDelay: tt.seconds}
+			err := cfg.validateSpectators()
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error for %d seconds", tt.seconds)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if got := cfg.SpectatorLag(); got != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
+)
+
+// TextFilter configures the profanity filter applied to narration. The built-in English
+// list applies in every locale. Words lists more words by locale, e.g. "en" or "es",
+// then by the rating they're filtered at: a word listed for PG13 is also filtered at
+// PG and G. Each word maps to its replacement, or "" to mask it. Allow lifts built-in
+// or listed words at a rating and the milder ones. Narration is filtered with the
+// lists of the language the player writes in.
+type TextFilter struct {
+	Words     map[string]map[string]map[string]string `json:"words,omitempty"`     // locale -> rating -> word -> replacement
+	Allow     map[string]map[string][]string          `json:"allow,omitempty"`     // locale -> rating -> words
+	Leetspeak bool                                    `json:"leetspeak,omitempty"` // also catch words spelled with digits and symbols, e.g. "sh1t"
+}
+
+// ProfanityFilter builds the configured filter
+func (t TextFilter) ProfanityFilter() (*textfilter.ProfanityFilter, error) {
+	filter := textfilter.NewProfanityFilter().WithLeetspeak(t.Leetspeak)
+	for _, locale := range slices.Sorted(maps.Keys(t.Words)) {
+		for _, rating := range slices.Sorted(maps.Keys(t.Words[locale])) {
+			if err := filter.AddWords(locale, rating, t.Words[locale][rating]); err != nil {
+				return nil, fmt.Errorf("text_filter.words.%s.%s: %w", locale, rating, err)
+			}
+		}
+	}
+	for _, locale := range slices.Sorted(maps.Keys(t.Allow)) {
+		for _, rating := range slices.Sorted(maps.Keys(t.Allow[locale])) {
+			if err := filter.AllowWords(locale, rating, t.Allow[locale][rating]); err != nil {
+				return nil, fmt.Errorf("text_filter.allow.%s.%s: %w", locale, rating, err)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// validateTextFilter checks that the word lists name filtered ratings
+func (c *Config) validateTextFilter() error {
+	_, err := c.TextFilter.ProfanityFilter()
+	return err
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfig_ValidateTextFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		textFilter  TextFilter
+		expectedErr string
+	}{
+		{"no lists", TextFilter{}, ""},
+		{"word lists", TextFilter{
+			Words: map[string]map[string]map[string]string{"es": {"PG13": {"mierda": "caramba"}}},
+			Allow: map[string]map[string][]string{"en": {"PG": {"crap"}}},
+		}, ""},
+		{"unfiltered rating", TextFilter{Words: map[string]map[string]map[string]string{"en": {"R": {"zounds": ""}}}}, "text_filter.words.en.R"},
+		{"unknown allow rating", TextFilter{Allow: map[string]map[string][]string{"en": {"NC17": {"crap"}}}}, "text_filter.allow.en.NC17"},
+		{"empty word", TextFilter{Words: map[string]map[string]map[string]string{"en": {"G": {" ": ""}}}}, "empty word"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{TextFilter: tt.textFilter}
+			err := cfg.validateTextFilter()
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestTextFilter_ProfanityFilter(t *testing.T) {
+	filter, err := TextFilter{
+		Words:     map[string]map[string]map[string]string{"es": {"PG": {"mierda": "caramba"}}},
+		Leetspeak: true,
+	}.ProfanityFilter()
+	if err != nil {
+		t.Fatalf("ProfanityFilter() error = %v", err)
+	}
+	if got := filter.ForLocale("es").FilterText("¡Mierda! Sh1t!", "G"); got != "¡Caramba! Shoot!" {
+		t.Errorf("FilterText() = %q", got)
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// validateWebSocketOrigins checks that each allowed WebSocket origin is a bare
+// scheme://host[:port], as browsers send it in the Origin header
+func (c *Config) validateWebSocketOrigins() error {
+	for _, origin := range c.WebSocketOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("websocket_origins: %q is not an origin such as \"https://play.example.com\"", origin)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestConfig_WebSocketOrigins(t *testing.T) {
+	tests := []struct {
+		name      string
+		origins   []string
+		expectErr bool
+	}{
+		{"none", nil, false},
+		{"https origin", []string{"https://play.example.com"}, false},
+		{"with port", []string{"http://localhost:5173"}, false},
+		{"missing scheme", []string{"play.example.com"}, true},
+		{"with path", []string{"https://example.com/play"}, true},
+		{"wildcard", []string{"*"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{WebSocketOrigins: tt.origins}
+			err := cfg.validateWebSocketOrigins()
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected an error for %v", tt.origins)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/internal/services/analytics"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// Analytics records and reports anonymized play statistics per scenario.
+// It is implemented by analytics.Store.
+type Analytics interface {
+	RecordGameStarted(ctx context.Context, gs *state.GameState) error
+	Report(ctx context.Context, file string, s *scenario.Scenario) (*analytics.Report, error)
+}
+
+// AnalyticsHandler serves per-scenario play statistics, so authors can find scenes
+// where players get stuck or give up
+type AnalyticsHandler struct {
+	log       *slog.Logger
+	storage   storage.ContentStore
+	analytics Analytics
+}
+
+func NewAnalyticsHandler(log *slog.Logger, storage storage.ContentStore, analytics Analytics) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		log:       log,
+		storage:   storage,
+		analytics: analytics,
+	}
+}
+
+// ServeHTTP handles HTTP requests for scenario analytics
+// Routes:
+// GET /v1/analytics/scenarios/{file} - Play statistics for a scenario
+func (h *AnalyticsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filename := strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/v1/analytics/scenarios/"))
+	if filename == "" || filename == r.URL.Path {
+		http.Error(w, "filename is required in URL path (e.g., /v1/analytics/scenarios/pirate.json)", http.StatusBadRequest)
+		return
+	}
+	if strings.Contains(filename, "..") || strings.Contains(filename, "/") {
+		http.Error(w, "Invalid filename", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	s, err := h.storage.GetScenario(ctx, filename)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, "Scenario not found", http.StatusNotFound)
+			return
+		}
+		h.log.Error("Failed to get scenario for analytics", "error", err, "filename", filename)
+		http.Error(w, "Failed to retrieve scenario", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := h.analytics.Report(ctx, filename, s)
+	if err != nil {
+		h.log.Error("Failed to load scenario analytics", "error", err, "filename", filename)
+		http.Error(w, "Failed to load analytics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		h.log.Error("Failed to encode analytics response", "error", err, "filename", filename)
+	}
+}
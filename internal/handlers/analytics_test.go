@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/internal/services/analytics"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// stubAnalytics remembers the games it was told about and reports them as started
+type stubAnalytics struct {
+	started []string
+}
+
+func (a *stubAnalytics) RecordGameStarted(_ context.Context, gs *state.GameState) error {
+	a.started = append(a.started, gs.Scenario)
+	return nil
+}
+
+func (a *stubAnalytics) Report(_ context.Context, file string, _ *scenario.Scenario) (*analytics.Report, error) {
+	var n int64
+	for _, f := range a.started {
+		if f == file {
+			n++
+		}
+	}
+	return &analytics.Report{Scenario: file, GamesStarted: n}, nil
+}
+
+func TestAnalyticsHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	mockStorage := storage.NewMockStorage()
+	mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{
+		Name:            "Foo",
+		OpeningLocation: "start",
+		Locations: map[string]scenario.Location{
+			"start": {Name: "start", Description: "Starting location"},
+		},
+	})
+
+	stats := &stubAnalytics{}
+	games := NewGameStateHandler(logger, "foo_model", mockStorage).WithAnalytics(stats)
+	req := httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(`{"scenario":"foo_scenario.json"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	games.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Response body: %s", rr.Code, rr.Body.String())
+	}
+
+	handler := NewAnalyticsHandler(logger, mockStorage, stats)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedGames  int64
+	}{
+		{"report", http.MethodGet, "/v1/analytics/scenarios/foo_scenario.json", http.StatusOK, 1},
+		{"unknown scenario", http.MethodGet, "/v1/analytics/scenarios/missing.json", http.StatusNotFound, 0},
+		{"missing filename", http.MethodGet, "/v1/analytics/scenarios/", http.StatusBadRequest, 0},
+		{"invalid filename", http.MethodGet, "/v1/analytics/scenarios/a/b.json", http.StatusBadRequest, 0},
+		{"wrong method", http.MethodPost, "/v1/analytics/scenarios/foo_scenario.json", http.StatusMethodNotAllowed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if rr.Code != http.StatusOK {
+				return
+			}
+			var report analytics.Report
+			if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if report.GamesStarted != tt.expectedGames {
+				t.Errorf("Expected %d games started, got %d", tt.expectedGames, report.GamesStarted)
+			}
+		})
+	}
+}
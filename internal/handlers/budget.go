@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/internal/services/usage"
+)
+
+// budgetWarningHeader carries a usage summary on responses once a soft cap is reached
+const budgetWarningHeader = "X-Budget-Warning"
+
+// BudgetChecker reports token spend against budget caps and records LLM usage.
+// It is implemented by usage.Ledger.
+type BudgetChecker interface {
+	Check(ctx context.Context, gameID uuid.UUID, keyID string) ([]usage.Status, error)
+	Recorder(gameID uuid.UUID, keyID string) services.UsageRecorder
+}
+
+// checkBudget adds warning headers for subjects past their soft cap and writes a
+// 402 response if any is past its hard cap, returning false in that case.
+// Budgets that can't be read are logged and allowed.
+func checkBudget(w http.ResponseWriter, r *http.Request, budget BudgetChecker, logger *slog.Logger, gameID uuid.UUID, keyID string) bool {
+	warnings, exceeded := budgetStatus(r.Context(), budget, logger, gameID, keyID)
+	for _, warning := range warnings {
+		w.Header().Add(budgetWarningHeader, warning)
+	}
+	if exceeded != "" {
+		w.WriteHeader(http.StatusPaymentRequired)
+		response := newErrorResponse(w, "Budget exceeded: "+exceeded)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			logger.Error("Error encoding error response", "error", err)
+		}
+		return false
+	}
+	return true
+}
+
+// budgetStatus returns usage summaries for subjects past their soft cap, and for the
+// first subject past its hard cap, if any. Budgets that can't be read are logged and allowed.
+func budgetStatus(ctx context.Context, budget BudgetChecker, logger *slog.Logger, gameID uuid.UUID, keyID string) (warnings []string, exceeded string) {
+	statuses, err := budget.Check(ctx, gameID, keyID)
+	if err != nil {
+		logger.Error("Failed to check budget, allowing request", "error", err, "game_state_id", gameID.String())
+		return nil, ""
+	}
+	for _, s := range statuses {
+		switch s.Level {
+		case usage.LevelExceeded:
+			logger.Warn("Budget exceeded, refusing request", "subject", s.Subject, "id", s.ID, "usage", s.Message())
+			return warnings, s.Message()
+		case usage.LevelWarning:
+			warnings = append(warnings, s.Message())
+		}
+	}
+	return warnings, ""
+}
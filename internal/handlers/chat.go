@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
@@ -8,15 +9,22 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/logger"
+	"github.com/jwebster45206/story-engine/internal/middleware"
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	"github.com/jwebster45206/story-engine/pkg/queue"
 	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
 )
 
 // ChatHandler handles chat HTTP requests by enqueuing them for async processing
 type ChatHandler struct {
 	chatQueue state.ChatQueue
 	logger    *slog.Logger
+	profile   string                 // profile stamped on queued requests
+	budget    BudgetChecker          // optional; refuses chats past their budget caps
+	storage   storage.GameStateStore // optional; refuses chats to paused games up front
 }
 
 // NewChatHandler creates a new chat handler
@@ -27,6 +35,27 @@ func NewChatHandler(chatQueue state.ChatQueue, logger *slog.Logger) *ChatHandler
 	}
 }
 
+// WithProfile stamps queued requests with the profile, so the worker
+// processes them with that profile's storage and model
+func (h *ChatHandler) WithProfile(profile config.Profile) *ChatHandler {
+	h.profile = profile.Name
+	return h
+}
+
+// WithBudget enforces token budgets: chats past a hard cap are refused with 402,
+// and responses carry X-Budget-Warning headers once a soft cap is reached
+func (h *ChatHandler) WithBudget(budget BudgetChecker) *ChatHandler {
+	h.budget = budget
+	return h
+}
+
+// WithStorage refuses chats to paused games with 409 instead of queueing them.
+// The worker rejects them either way; this just tells the client sooner.
+func (h *ChatHandler) WithStorage(storage storage.GameStateStore) *ChatHandler {
+	h.storage = storage
+	return h
+}
+
 // ChatResponse is the response format for async chat requests
 type ChatResponse struct {
 	RequestID string `json:"request_id"`
@@ -44,9 +73,7 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			"remote_addr", r.RemoteAddr)
 
 		w.WriteHeader(http.StatusMethodNotAllowed)
-		response := ErrorResponse{
-			Error: "Method not allowed. Only POST is supported at /v1/chat.",
-		}
+		response := newErrorResponse(w, "Method not allowed. Only POST is supported at /v1/chat.")
 
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Error encoding chat error response",
@@ -61,9 +88,7 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		h.logger.Warn("Invalid request body", "error", err)
 		w.WriteHeader(http.StatusBadRequest)
-		response := ErrorResponse{
-			Error: "Invalid request body. Expected JSON with 'message' field.",
-		}
+		response := newErrorResponse(w, "Invalid request body. Expected JSON with 'message' field.")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Error encoding error response", "error", err)
 		}
@@ -74,37 +99,21 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if err := request.Validate(); err != nil {
 		h.logger.Warn("Invalid chat request", "error", err)
 		w.WriteHeader(http.StatusBadRequest)
-		response := ErrorResponse{
-			Error: "Invalid request: " + err.Error(),
-		}
+		response := newErrorResponse(w, "Invalid request: "+err.Error())
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Error encoding error response", "error", err)
 		}
 		return
 	}
 
-	// Create queue request
-	requestID := uuid.New().String()
-	queueReq := &queue.Request{
-		RequestID:   requestID,
-		Type:        queue.RequestTypeChat,
-		GameStateID: request.GameStateID,
-		Message:     request.Message,
-		EnqueuedAt:  time.Now(),
+	requestID := queueRequestID(r)
+	warnings, refusal := h.queueChat(r.Context(), request, requestID, middleware.APIKeyID(r.Context()))
+	for _, warning := range warnings {
+		w.Header().Add(budgetWarningHeader, warning)
 	}
-
-	// Enqueue for async processing
-	// TODO: Future enhancement - Check if reducer is still processing story events
-	// for this game before allowing new chat messages. This would prevent race
-	// conditions where new messages are processed before queued story events.
-	// See docs/QUEUE-REFACTOR.md "Known Issues" for details.
-	if err := h.chatQueue.EnqueueRequest(r.Context(), queueReq); err != nil {
-		h.logger.Error("Failed to enqueue chat request", "error", err, "request_id", requestID)
-		w.WriteHeader(http.StatusInternalServerError)
-		response := ErrorResponse{
-			Error: "Failed to enqueue request for processing.",
-		}
-		if err := json.NewEncoder(w).Encode(response); err != nil {
+	if refusal != nil {
+		w.WriteHeader(refusal.status)
+		if err := json.NewEncoder(w).Encode(newErrorResponse(w, refusal.message)); err != nil {
 			h.logger.Error("Error encoding error response", "error", err)
 		}
 		return
@@ -112,7 +121,8 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Info("Chat request enqueued",
 		"request_id", requestID,
-		"game_state_id", request.GameStateID.String())
+		"game_state_id", request.GameStateID.String(),
+		"free_action", request.FreeAction)
 
 	// Return request ID for client to poll status
 	w.WriteHeader(http.StatusAccepted)
@@ -124,3 +134,75 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.logger.Error("Error encoding chat response", "error", err)
 	}
 }
+
+// chatRefusal is why a chat wasn't queued, with the HTTP status that reports it
+type chatRefusal struct {
+	status  int
+	message string
+}
+
+// queueChat checks that a validated chat can be played, then queues it under requestID.
+// Budget warnings for subjects past their soft cap are returned whether or not it was
+// queued.
+func (h *ChatHandler) queueChat(ctx context.Context, request chat.ChatRequest, requestID, keyID string) ([]string, *chatRefusal) {
+	var warnings []string
+	if h.budget != nil {
+		var exceeded string
+		warnings, exceeded = budgetStatus(ctx, h.budget, h.logger, request.GameStateID, keyID)
+		if exceeded != "" {
+			return warnings, &chatRefusal{http.StatusPaymentRequired, "Budget exceeded: " + exceeded}
+		}
+	}
+
+	if h.storage != nil {
+		gs, err := h.storage.LoadGameState(ctx, request.GameStateID)
+		if err != nil {
+			h.logger.Warn("Failed to load game state for pause and preview checks", "error", err, "game_state_id", request.GameStateID.String())
+		} else if gs != nil && gs.Paused {
+			h.logger.Info("Chat rejected: game is paused", "game_state_id", request.GameStateID.String())
+			return warnings, &chatRefusal{http.StatusConflict, gs.PausedMessage()}
+		} else if gs != nil && gs.TrialExhausted() {
+			h.logger.Info("Chat rejected: preview has used all its turns", "game_state_id", request.GameStateID.String(), "max_turns", gs.Trial.MaxTurns)
+			return warnings, &chatRefusal{http.StatusForbidden, gs.Trial.EndedMessage()}
+		}
+	}
+
+	// Charged last, so a chat refused for any other reason doesn't use up the budget
+	if !middleware.SpendChat(ctx) {
+		return warnings, &chatRefusal{http.StatusTooManyRequests, "Daily chat budget exhausted"}
+	}
+
+	queueReq := &queue.Request{
+		RequestID:    requestID,
+		Type:         queue.RequestTypeChat,
+		GameStateID:  request.GameStateID,
+		Profile:      h.profile,
+		Message:      request.Message,
+		FreeAction:   request.FreeAction,
+		Embellish:    request.Embellish,
+		Variants:     request.Variants,
+		MaxTokens:    request.MaxTokens,
+		MaxSentences: request.MaxSentences,
+		EnqueuedAt:   time.Now(),
+	}
+
+	// Enqueue for async processing
+	// TODO: Future enhancement - Check if reducer is still processing story events
+	// for this game before allowing new chat messages. This would prevent race
+	// conditions where new messages are processed before queued story events.
+	// See docs/QUEUE-REFACTOR.md "Known Issues" for details.
+	if err := h.chatQueue.EnqueueRequest(ctx, queueReq); err != nil {
+		h.logger.Error("Failed to enqueue chat request", "error", err, "request_id", requestID)
+		return warnings, &chatRefusal{http.StatusInternalServerError, "Failed to enqueue request for processing."}
+	}
+	return warnings, nil
+}
+
+// queueRequestID returns the ID for a request queued by r: the HTTP request's own ID,
+// so the API's and the worker's logs for it share a request_id, or a new one
+func queueRequestID(r *http.Request) string {
+	if id := logger.RequestIDFromContext(r.Context()); id != "" {
+		return id
+	}
+	return uuid.New().String()
+}
@@ -1,10 +1,144 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/internal/services/usage"
+	"github.com/jwebster45206/story-engine/pkg/queue"
 )
 
 // Placeholder test - handler tests will be rewritten for async architecture
 func TestPlaceholder(t *testing.T) {
 	t.Skip("Chat handler tests need rewriting for async architecture")
 }
+
+type stubChatQueue struct {
+	requests []*queue.Request
+}
+
+func (q *stubChatQueue) GetFormattedEvents(ctx context.Context, gameID uuid.UUID) (string, error) {
+	return "", nil
+}
+
+func (q *stubChatQueue) Clear(ctx context.Context, gameID uuid.UUID) error {
+	return nil
+}
+
+func (q *stubChatQueue) EnqueueRequest(ctx context.Context, req *queue.Request) error {
+	q.requests = append(q.requests, req)
+	return nil
+}
+
+type stubBudget struct {
+	statuses []usage.Status
+}
+
+func (b *stubBudget) Check(ctx context.Context, gameID uuid.UUID, keyID string) ([]usage.Status, error) {
+	return b.statuses, nil
+}
+
+func (b *stubBudget) Recorder(gameID uuid.UUID, keyID string) services.UsageRecorder {
+	return nil
+}
+
+func TestChatHandler_Budget(t *testing.T) {
+	gameCap := config.BudgetCap{HardTokens: 1000}
+
+	tests := []struct {
+		name            string
+		statuses        []usage.Status
+		expectedStatus  int
+		expectedWarning string
+		expectedQueued  int
+	}{
+		{
+			name:           "under budget",
+			statuses:       []usage.Status{{Subject: usage.SubjectGame, Level: usage.LevelOK, Cap: gameCap}},
+			expectedStatus: http.StatusAccepted,
+			expectedQueued: 1,
+		},
+		{
+			name:            "near cap warns",
+			statuses:        []usage.Status{{Subject: usage.SubjectGame, Level: usage.LevelWarning, Usage: usage.Totals{InputTokens: 850}, Cap: gameCap}},
+			expectedStatus:  http.StatusAccepted,
+			expectedWarning: "game usage 850 of 1000 tokens",
+			expectedQueued:  1,
+		},
+		{
+			name: "over cap refused",
+			statuses: []usage.Status{
+				{Subject: usage.SubjectGame, Level: usage.LevelWarning, Usage: usage.Totals{InputTokens: 900}, Cap: gameCap},
+				{Subject: usage.SubjectAPIKey, Level: usage.LevelExceeded, Usage: usage.Totals{CostUSD: 5.5}, Cap: config.BudgetCap{HardUSD: 5}},
+			},
+			expectedStatus:  http.StatusPaymentRequired,
+			expectedWarning: "game usage 900 of 1000 tokens",
+			expectedQueued:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &stubChatQueue{}
+			handler := NewChatHandler(q, slog.New(slog.NewTextHandler(io.Discard, nil))).
+				WithBudget(&stubBudget{statuses: tt.statuses})
+
+			body, _ := json.Marshal(map[string]string{"gamestate_id": uuid.New().String(), "message": "Look around"})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat", bytes.NewReader(body)))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get(budgetWarningHeader); got != tt.expectedWarning {
+				t.Errorf("Expected warning header %q, got %q", tt.expectedWarning, got)
+			}
+			if len(q.requests) != tt.expectedQueued {
+				t.Errorf("Expected %d queued requests, got %d", tt.expectedQueued, len(q.requests))
+			}
+		})
+	}
+}
+
+func TestChatHandler_RequestID(t *testing.T) {
+	q := &stubChatQueue{}
+	handler := middleware.Logger(NewChatHandler(q, slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	// A queued chat carries the HTTP request's ID to the worker
+	body, _ := json.Marshal(map[string]string{"gamestate_id": uuid.New().String(), "message": "Look around"})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat", bytes.NewReader(body)))
+
+	requestID := w.Header().Get(middleware.RequestIDHeader)
+	if w.Code != http.StatusAccepted || requestID == "" {
+		t.Fatalf("Expected 202 with a request ID, got %d %q", w.Code, requestID)
+	}
+	var resp ChatResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.RequestID != requestID || len(q.requests) != 1 || q.requests[0].RequestID != requestID {
+		t.Errorf("Expected the response and queued request to carry %q, got %q and %+v", requestID, resp.RequestID, q.requests)
+	}
+
+	// Error bodies carry it too
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat", bytes.NewReader([]byte("not json"))))
+	var errResp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if w.Code != http.StatusBadRequest || errResp.RequestID == "" || errResp.RequestID != w.Header().Get(middleware.RequestIDHeader) {
+		t.Errorf("Expected a 400 error body with the request ID, got %d %+v", w.Code, errResp)
+	}
+}
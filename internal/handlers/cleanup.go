@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CleanupResponse reports the result of a bulk game state delete
+type CleanupResponse struct {
+	Matched int  `json:"matched"` // Games matching the filters
+	Deleted int  `json:"deleted"` // Games deleted; 0 on a dry run
+	DryRun  bool `json:"dry_run"`
+}
+
+// cleanupFilter selects the games removed by a bulk delete
+type cleanupFilter struct {
+	ended     bool          // only games that have ended
+	olderThan time.Duration // only games not updated within this long; 0 for any age
+}
+
+// WithAdminKey enables admin-only operations such as bulk delete, authenticated
+// by the X-Admin-Key header. Without it those operations are refused.
+func (h *GameStateHandler) WithAdminKey(adminKey string) *GameStateHandler {
+	h.adminKey = adminKey
+	return h
+}
+
+// handleBulkDelete deletes every game state in the handler's profile matching the
+// query filters, e.g. DELETE /v1/gamestate?ended=true&older_than=30d. With
+// dry_run=true the matching games are counted but not deleted.
+func (h *GameStateHandler) handleBulkDelete(w http.ResponseWriter, r *http.Request) {
+	if !hasAdminKey(r, h.adminKey) {
+		h.logger.Warn("Bulk delete refused: missing or invalid admin key")
+		h.writeError(w, http.StatusUnauthorized, "A valid admin key is required for bulk delete")
+		return
+	}
+
+	filter, dryRun, err := parseCleanupQuery(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ids, err := h.storage.ListGameStateIDs(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list game states", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list game states")
+		return
+	}
+
+	cutoff := time.Now().Add(-filter.olderThan)
+	resp := CleanupResponse{DryRun: dryRun}
+	for _, id := range ids {
+		gs, err := h.loadOwnedGameState(r.Context(), id)
+		if err != nil {
+			h.logger.Error("Failed to load game state", "error", err, "id", id.String())
+			continue
+		}
+		if gs == nil || (filter.ended && !gs.IsEnded) || (filter.olderThan > 0 && !gs.UpdatedAt.Before(cutoff)) {
+			continue
+		}
+		resp.Matched++
+		if dryRun {
+			continue
+		}
+		if err := h.storage.DeleteGameState(r.Context(), id); err != nil {
+			h.logger.Error("Failed to delete game state", "error", err, "id", id.String())
+			continue
+		}
+		resp.Deleted++
+	}
+
+	h.logger.Info("Bulk delete completed", "profile", h.profile, "ended", filter.ended,
+		"older_than", filter.olderThan, "dry_run", dryRun, "matched", resp.Matched, "deleted", resp.Deleted)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode bulk delete response", "error", err)
+	}
+}
+
+// parseCleanupQuery reads the bulk delete filters. At least one filter is required
+// so that a bare DELETE /v1/gamestate can't wipe every game.
+func parseCleanupQuery(r *http.Request) (cleanupFilter, bool, error) {
+	var filter cleanupFilter
+	query := r.URL.Query()
+
+	if v := query.Get("ended"); v != "" {
+		ended, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, false, fmt.Errorf("invalid ended value %q", v)
+		}
+		filter.ended = ended
+	}
+	if v := query.Get("older_than"); v != "" {
+		age, err := parseAge(v)
+		if err != nil || age <= 0 {
+			return filter, false, fmt.Errorf("invalid older_than value %q: use a duration like 30d or 12h", v)
+		}
+		filter.olderThan = age
+	}
+	dryRun := false
+	if v := query.Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return filter, false, fmt.Errorf("invalid dry_run value %q", v)
+		}
+	}
+
+	if !filter.ended && filter.olderThan == 0 {
+		return filter, false, fmt.Errorf("bulk delete requires a filter: ended=true and/or older_than")
+	}
+	return filter, dryRun, nil
+}
+
+// parseAge parses a Go duration, also accepting whole days such as "30d"
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_BulkDelete(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	const adminKey = "admin-secret"
+
+	tests := []struct {
+		name            string
+		query           string
+		adminKey        string
+		handlerKey      string
+		expectedStatus  int
+		expectedMatched int
+		expectedLeft    int
+	}{
+		{
+			name:            "ended games",
+			query:           "?ended=true",
+			adminKey:        adminKey,
+			handlerKey:      adminKey,
+			expectedStatus:  http.StatusOK,
+			expectedMatched: 2,
+			expectedLeft:    3,
+		},
+		{
+			name:            "older than 30 days",
+			query:           "?older_than=30d",
+			adminKey:        adminKey,
+			handlerKey:      adminKey,
+			expectedStatus:  http.StatusOK,
+			expectedMatched: 2,
+			expectedLeft:    3,
+		},
+		{
+			name:            "ended and older than 30 days",
+			query:           "?ended=true&older_than=30d",
+			adminKey:        adminKey,
+			handlerKey:      adminKey,
+			expectedStatus:  http.StatusOK,
+			expectedMatched: 1,
+			expectedLeft:    4,
+		},
+		{
+			name:            "go duration",
+			query:           "?older_than=720h",
+			adminKey:        adminKey,
+			handlerKey:      adminKey,
+			expectedStatus:  http.StatusOK,
+			expectedMatched: 2,
+			expectedLeft:    3,
+		},
+		{
+			name:            "dry run deletes nothing",
+			query:           "?ended=true&dry_run=true",
+			adminKey:        adminKey,
+			handlerKey:      adminKey,
+			expectedStatus:  http.StatusOK,
+			expectedMatched: 2,
+			expectedLeft:    5,
+		},
+		{
+			name:           "filter required",
+			query:          "?dry_run=true",
+			adminKey:       adminKey,
+			handlerKey:     adminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedLeft:   5,
+		},
+		{
+			name:           "invalid older_than",
+			query:          "?older_than=soon",
+			adminKey:       adminKey,
+			handlerKey:     adminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedLeft:   5,
+		},
+		{
+			name:           "wrong admin key",
+			query:          "?ended=true",
+			adminKey:       "guess",
+			handlerKey:     adminKey,
+			expectedStatus: http.StatusUnauthorized,
+			expectedLeft:   5,
+		},
+		{
+			name:           "no admin key configured",
+			query:          "?ended=true",
+			expectedStatus: http.StatusUnauthorized,
+			expectedLeft:   5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			old := time.Now().Add(-45 * 24 * time.Hour)
+			games := []struct {
+				ended   bool
+				updated time.Time
+				profile string
+			}{
+				{ended: true, updated: old},
+				{ended: true, updated: time.Now()},
+				{ended: false, updated: old},
+				{ended: false, updated: time.Now()},
+				{ended: true, updated: old, profile: "kids"}, // another profile's game is never touched
+			}
+			for _, g := range games {
+				gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+				gs.IsEnded = g.ended
+				gs.UpdatedAt = g.updated
+				gs.Profile = g.profile
+				if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+					t.Fatalf("Failed to save game state: %v", err)
+				}
+			}
+
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage).WithAdminKey(tt.handlerKey)
+			req := httptest.NewRequest(http.MethodDelete, "/v1/gamestate"+tt.query, nil)
+			if tt.adminKey != "" {
+				req.Header.Set("X-Admin-Key", tt.adminKey)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK {
+				var resp CleanupResponse
+				if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.Matched != tt.expectedMatched {
+					t.Errorf("Expected %d matched, got %d", tt.expectedMatched, resp.Matched)
+				}
+				expectedDeleted := tt.expectedMatched
+				if resp.DryRun {
+					expectedDeleted = 0
+				}
+				if resp.Deleted != expectedDeleted {
+					t.Errorf("Expected %d deleted, got %d", expectedDeleted, resp.Deleted)
+				}
+			}
+
+			ids, _ := mockStorage.ListGameStateIDs(ctx)
+			if len(ids) != tt.expectedLeft {
+				t.Errorf("Expected %d games left, got %d", tt.expectedLeft, len(ids))
+			}
+		})
+	}
+
+	t.Run("profile handler only deletes its own games", func(t *testing.T) {
+		mockStorage := storage.NewMockStorage()
+		for _, profile := range []string{"", "kids"} {
+			gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+			gs.IsEnded = true
+			gs.Profile = profile
+			if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+		}
+
+		handler := NewGameStateHandler(logger, "foo_model", mockStorage).
+			WithProfile(config.Profile{Name: "kids"}).
+			WithAdminKey(adminKey)
+		req := httptest.NewRequest(http.MethodDelete, "/v1/gamestate?ended=true", nil)
+		req.Header.Set("X-Admin-Key", adminKey)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var resp CleanupResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp.Deleted != 1 {
+			t.Errorf("Expected 1 deleted, got %d", resp.Deleted)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// CodexEntry is a scenario lore entry the player has discovered
+type CodexEntry struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// CodexResponse lists the lore entries a player has discovered, in the order found
+type CodexResponse struct {
+	GameStateID uuid.UUID    `json:"gamestate_id"`
+	Entries     []CodexEntry `json:"entries"`
+}
+
+// handleCodex returns the scenario lore entries the player has discovered
+func (h *GameStateHandler) handleCodex(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+	s, err := h.storage.GetScenario(r.Context(), gs.Scenario)
+	if err != nil {
+		h.logger.Error("Failed to load scenario for codex", "error", err, "scenario", gs.Scenario)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load scenario")
+		return
+	}
+
+	entries := []CodexEntry{}
+	for _, id := range gs.DiscoveredLore {
+		// Entries removed from the scenario since they were found are skipped
+		if entry, ok := s.Lore[id]; ok {
+			entries = append(entries, CodexEntry{ID: id, Title: entry.Title, Text: entry.Text})
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(CodexResponse{GameStateID: gs.ID, Entries: entries}); err != nil {
+		h.logger.Error("Failed to encode codex response", "error", err)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_Codex(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	mockStorage := storage.NewMockStorage()
+	mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{
+		Name: "Foo",
+		Lore: map[string]scenario.LoreEntry{
+			"black_pearl": {Title: "The Black Pearl", Keywords: []string{"black pearl"}, Text: "A ship with black sails."},
+			"tortuga":     {Title: "Tortuga", Keywords: []string{"tortuga"}, Text: "A pirate haven."},
+			"kraken":      {Title: "The Kraken", Keywords: []string{"kraken"}, Text: "A sea monster."},
+		},
+	})
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	gs.DiscoverLore("tortuga", "removed_entry", "black_pearl")
+	if err := mockStorage.SaveGameState(context.Background(), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+
+	handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"list codex", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/codex", http.StatusOK},
+		{"sub path", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/codex/tortuga", http.StatusMethodNotAllowed},
+		{"wrong method", http.MethodPost, "/v1/gamestate/" + gs.ID.String() + "/codex", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("list body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/gamestate/"+gs.ID.String()+"/codex", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var response CodexResponse
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		want := []CodexEntry{
+			{ID: "tortuga", Title: "Tortuga", Text: "A pirate haven."},
+			{ID: "black_pearl", Title: "The Black Pearl", Text: "A ship with black sails."},
+		}
+		if !reflect.DeepEqual(response.Entries, want) {
+			t.Errorf("Entries = %+v, want %+v", response.Entries, want)
+		}
+	})
+}
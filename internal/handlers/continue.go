@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+)
+
+// handleContinue queues a continuation of the game's last narration, for one cut off by
+// the model's token limit or an error, or when the player wants more of it. The worker
+// narrates on from where it stopped and adds the text to that narration, without a new
+// turn, so the turn counters don't advance. Progress is reported on the game's event
+// stream like any chat.
+func (h *GameStateHandler) handleContinue(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	if h.chatQueue == nil {
+		h.logger.Error("Cannot continue narration: no queue configured", "id", gameStateID.String())
+		h.writeError(w, http.StatusServiceUnavailable, "Cannot continue narration: chat queue unavailable")
+		return
+	}
+
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+	if gs.Paused {
+		h.writeError(w, http.StatusConflict, gs.PausedMessage())
+		return
+	}
+	if gs.LastNarration() == nil {
+		h.writeError(w, http.StatusConflict, "There is no narration to continue")
+		return
+	}
+	if h.budget != nil && !checkBudget(w, r, h.budget, h.logger, gs.ID, middleware.APIKeyID(r.Context())) {
+		return
+	}
+
+	h.enqueueTurn(w, r, &queue.Request{
+		Type:        queue.RequestTypeContinue,
+		GameStateID: gs.ID,
+		Profile:     gs.Profile,
+	})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_Continue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	narrated := []chat.ChatMessage{
+		{Role: chat.ChatRoleUser, Content: "I look around"},
+		{Role: chat.ChatRoleAgent, Content: "The fog lifts, and", Incomplete: true},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		history        []chat.ChatMessage
+		paused         bool
+		noQueue        bool
+		expectedStatus int
+		expectQueued   bool
+	}{
+		{name: "queues a continuation", method: http.MethodPost, history: narrated, expectedStatus: http.StatusAccepted, expectQueued: true},
+		{name: "no narration yet", method: http.MethodPost, expectedStatus: http.StatusConflict},
+		{name: "player message last", method: http.MethodPost, history: narrated[:1], expectedStatus: http.StatusConflict},
+		{name: "paused game", method: http.MethodPost, history: narrated, paused: true, expectedStatus: http.StatusConflict},
+		{name: "no queue", method: http.MethodPost, history: narrated, noQueue: true, expectedStatus: http.StatusServiceUnavailable},
+		{name: "wrong method", method: http.MethodGet, history: narrated, expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+			gs.ChatHistory = tt.history
+			if tt.paused {
+				gs.Pause("")
+			}
+			if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+
+			q := &stubChatQueue{}
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+			if !tt.noQueue {
+				handler = handler.WithQueue(q)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tt.method, "/v1/gamestate/"+gs.ID.String()+"/continue", nil))
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+
+			if !tt.expectQueued {
+				if len(q.requests) != 0 {
+					t.Errorf("Expected nothing queued, got %+v", q.requests)
+				}
+				return
+			}
+			if len(q.requests) != 1 {
+				t.Fatalf("Expected one queued request, got %d", len(q.requests))
+			}
+			if r := q.requests[0]; r.Type != queue.RequestTypeContinue || r.GameStateID != gs.ID || r.RequestID == "" {
+				t.Errorf("Unexpected queued request: %+v", r)
+			}
+		})
+	}
+}
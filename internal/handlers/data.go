@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/internal/services/privacy"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// DataJobs runs and tracks exports and deletions of an owner's data.
+// It is implemented by privacy.Store.
+type DataJobs interface {
+	CreateJob(ctx context.Context, kind, owner string) (*privacy.Job, error)
+	GetJob(ctx context.Context, id string) (*privacy.Job, error)
+	GetExport(ctx context.Context, id string) ([]byte, error)
+	Run(ctx context.Context, games storage.GameStateStore, job *privacy.Job)
+}
+
+// DataRequest is the request body for starting a data export or deletion
+type DataRequest struct {
+	Owner   string `json:"owner,omitempty"` // API key ID to act for; admin only, defaults to the caller's key
+	Confirm bool   `json:"confirm"`         // Must be true for a deletion
+}
+
+// DataHandler lets a player export or permanently delete everything stored about them.
+// The owner of a game is the API key that created it, so requests need an API key.
+type DataHandler struct {
+	log      *slog.Logger
+	storage  storage.GameStateStore
+	jobs     DataJobs
+	adminKey string       // optional; lets an admin act for any owner
+	start    func(func()) // runs a job in the background
+}
+
+func NewDataHandler(log *slog.Logger, storage storage.GameStateStore, jobs DataJobs) *DataHandler {
+	return &DataHandler{
+		log:     log,
+		storage: storage,
+		jobs:    jobs,
+		start:   func(run func()) { go run() },
+	}
+}
+
+// WithAdminKey lets requests with the X-Admin-Key header export or delete the data
+// of any owner, and read any job
+func (h *DataHandler) WithAdminKey(adminKey string) *DataHandler {
+	h.adminKey = adminKey
+	return h
+}
+
+// ServeHTTP handles HTTP requests for player data
+// Routes:
+// POST /v1/data/export - Start exporting the caller's games, transcripts and event logs
+// POST /v1/data/delete - Start permanently deleting them
+// GET /v1/data/jobs/{id} - Status of an export or deletion
+// GET /v1/data/jobs/{id}/export - Download a completed export
+func (h *DataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/data/")
+	switch {
+	case path == privacy.JobExport || path == privacy.JobDelete:
+		if r.Method != http.MethodPost {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.handleStart(w, r, path)
+	case strings.HasPrefix(path, "jobs/"):
+		if r.Method != http.MethodGet {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		id, sub, _ := strings.Cut(strings.TrimPrefix(path, "jobs/"), "/")
+		switch sub {
+		case "":
+			h.handleGetJob(w, r, id)
+		case privacy.JobExport:
+			h.handleGetExport(w, r, id)
+		default:
+			h.writeError(w, http.StatusNotFound, "Not found")
+		}
+	default:
+		h.writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// handleStart creates a job and runs it in the background, returning 202 with the job
+// to poll
+func (h *DataHandler) handleStart(w http.ResponseWriter, r *http.Request, kind string) {
+	var req DataRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid JSON in request body")
+			return
+		}
+	}
+
+	owner := middleware.APIKeyID(r.Context())
+	if req.Owner != "" && req.Owner != owner {
+		if !hasAdminKey(r, h.adminKey) {
+			h.log.Warn("Data request for another owner refused: missing or invalid admin key")
+			h.writeError(w, http.StatusForbidden, "A valid admin key is required to act for another owner")
+			return
+		}
+		owner = req.Owner
+	}
+	if owner == "" {
+		h.writeError(w, http.StatusBadRequest, "Data requests need an API key to identify the owner")
+		return
+	}
+	if kind == privacy.JobDelete && !req.Confirm {
+		h.writeError(w, http.StatusBadRequest, `Deletion is permanent; set "confirm": true to proceed`)
+		return
+	}
+
+	job, err := h.jobs.CreateJob(r.Context(), kind, owner)
+	if err != nil {
+		h.log.Error("Failed to create data job", "error", err, "kind", kind)
+		h.writeError(w, http.StatusInternalServerError, "Failed to start data request")
+		return
+	}
+	h.start(func() { h.jobs.Run(context.Background(), h.storage, job) })
+
+	h.log.Info("Data job started", "job", job.ID, "kind", kind, "owner", owner)
+	w.Header().Set("Location", "/v1/data/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		h.log.Error("Failed to encode data job response", "error", err)
+	}
+}
+
+func (h *DataHandler) handleGetJob(w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := h.loadJob(w, r, id)
+	if !ok {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		h.log.Error("Failed to encode data job response", "error", err)
+	}
+}
+
+func (h *DataHandler) handleGetExport(w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := h.loadJob(w, r, id)
+	if !ok {
+		return
+	}
+	if job.Kind != privacy.JobExport {
+		h.writeError(w, http.StatusNotFound, "Job is not an export")
+		return
+	}
+	if !job.Done() {
+		h.writeError(w, http.StatusConflict, "Export is still in progress")
+		return
+	}
+
+	data, err := h.jobs.GetExport(r.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to load export", "error", err, "job", id)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load export")
+		return
+	}
+	if data == nil {
+		h.writeError(w, http.StatusNotFound, "Export not found")
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="export-`+id+`.json"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		h.log.Error("Failed to write export response", "error", err)
+	}
+}
+
+// loadJob loads a job the caller may see, writing an error response if there is none.
+// Jobs of other owners are reported as not found.
+func (h *DataHandler) loadJob(w http.ResponseWriter, r *http.Request, id string) (*privacy.Job, bool) {
+	job, err := h.jobs.GetJob(r.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to load data job", "error", err, "job", id)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load data job")
+		return nil, false
+	}
+	if job == nil || (job.Owner != middleware.APIKeyID(r.Context()) && !hasAdminKey(r, h.adminKey)) {
+		h.writeError(w, http.StatusNotFound, "Data job not found")
+		return nil, false
+	}
+	return job, true
+}
+
+func (h *DataHandler) writeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(newErrorResponse(w, message)); err != nil {
+		h.log.Error("Failed to encode error response", "error", err)
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/internal/services/privacy"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// stubDataJobs keeps jobs in memory and completes them when run
+type stubDataJobs struct {
+	jobs    map[string]*privacy.Job
+	exports map[string][]byte
+	ran     []string
+}
+
+func newStubDataJobs() *stubDataJobs {
+	return &stubDataJobs{jobs: make(map[string]*privacy.Job), exports: make(map[string][]byte)}
+}
+
+func (s *stubDataJobs) CreateJob(_ context.Context, kind, owner string) (*privacy.Job, error) {
+	job := &privacy.Job{ID: kind + "-" + owner, Kind: kind, Owner: owner, Status: privacy.StatusPending}
+	s.jobs[job.ID] = job
+	return job, nil
+}
+
+func (s *stubDataJobs) GetJob(_ context.Context, id string) (*privacy.Job, error) {
+	return s.jobs[id], nil
+}
+
+func (s *stubDataJobs) GetExport(_ context.Context, id string) ([]byte, error) {
+	return s.exports[id], nil
+}
+
+func (s *stubDataJobs) Run(_ context.Context, _ storage.GameStateStore, job *privacy.Job) {
+	s.ran = append(s.ran, job.ID)
+	job.Status = privacy.StatusCompleted
+	if job.Kind == privacy.JobExport {
+		s.exports[job.ID] = []byte(`{"owner":"` + job.Owner + `","games":[]}`)
+	}
+}
+
+func TestDataHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	jobs := newStubDataJobs()
+	jobs.jobs["running"] = &privacy.Job{ID: "running", Kind: privacy.JobExport, Owner: "key-1", Status: privacy.StatusRunning}
+	jobs.jobs["deletion"] = &privacy.Job{ID: "deletion", Kind: privacy.JobDelete, Owner: "key-1", Status: privacy.StatusCompleted}
+	handler := NewDataHandler(logger, storage.NewMockStorage(), jobs).WithAdminKey("secret")
+	handler.start = func(run func()) { run() }
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		keyID          string
+		adminKey       string
+		expectedStatus int
+		expectedJob    string
+	}{
+		{name: "start export", method: http.MethodPost, path: "/v1/data/export", keyID: "key-1", expectedStatus: http.StatusAccepted, expectedJob: "export-key-1"},
+		{name: "export status", method: http.MethodGet, path: "/v1/data/jobs/export-key-1", keyID: "key-1", expectedStatus: http.StatusOK, expectedJob: "export-key-1"},
+		{name: "download export", method: http.MethodGet, path: "/v1/data/jobs/export-key-1/export", keyID: "key-1", expectedStatus: http.StatusOK},
+		{name: "export in progress", method: http.MethodGet, path: "/v1/data/jobs/running/export", keyID: "key-1", expectedStatus: http.StatusConflict},
+		{name: "deletion has no export", method: http.MethodGet, path: "/v1/data/jobs/deletion/export", keyID: "key-1", expectedStatus: http.StatusNotFound},
+		{name: "another owner's job", method: http.MethodGet, path: "/v1/data/jobs/export-key-1", keyID: "key-2", expectedStatus: http.StatusNotFound},
+		{name: "admin reads any job", method: http.MethodGet, path: "/v1/data/jobs/export-key-1", adminKey: "secret", expectedStatus: http.StatusOK, expectedJob: "export-key-1"},
+		{name: "unknown job", method: http.MethodGet, path: "/v1/data/jobs/missing", keyID: "key-1", expectedStatus: http.StatusNotFound},
+		{name: "delete without confirm", method: http.MethodPost, path: "/v1/data/delete", keyID: "key-1", expectedStatus: http.StatusBadRequest},
+		{name: "delete", method: http.MethodPost, path: "/v1/data/delete", body: `{"confirm": true}`, keyID: "key-1", expectedStatus: http.StatusAccepted, expectedJob: "delete-key-1"},
+		{name: "no API key", method: http.MethodPost, path: "/v1/data/export", expectedStatus: http.StatusBadRequest},
+		{name: "other owner without admin key", method: http.MethodPost, path: "/v1/data/export", body: `{"owner": "key-2"}`, keyID: "key-1", expectedStatus: http.StatusForbidden},
+		{name: "admin acts for owner", method: http.MethodPost, path: "/v1/data/export", body: `{"owner": "key-2"}`, adminKey: "secret", expectedStatus: http.StatusAccepted, expectedJob: "export-key-2"},
+		{name: "invalid body", method: http.MethodPost, path: "/v1/data/delete", body: `{"confirm":`, keyID: "key-1", expectedStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodGet, path: "/v1/data/export", keyID: "key-1", expectedStatus: http.StatusMethodNotAllowed},
+		{name: "unknown route", method: http.MethodPost, path: "/v1/data/archive", keyID: "key-1", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.keyID != "" {
+				req = req.WithContext(middleware.WithAPIKeyID(req.Context(), tt.keyID))
+			}
+			if tt.adminKey != "" {
+				req.Header.Set("X-Admin-Key", tt.adminKey)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedJob != "" {
+				var job privacy.Job
+				if err := json.Unmarshal(rr.Body.Bytes(), &job); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if job.ID != tt.expectedJob {
+					t.Errorf("Expected job %q, got %q", tt.expectedJob, job.ID)
+				}
+			}
+		})
+	}
+
+	if len(jobs.ran) != 3 {
+		t.Errorf("Expected 3 jobs to run, got %v", jobs.ran)
+	}
+}
@@ -1,21 +1,33 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
 	"github.com/jwebster45206/story-engine/internal/services/events"
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// maxResumes caps how many times a game's event stream can be resumed per resumeWindow,
+	// since each resume replays buffered chunks
+	maxResumes   = 10
+	resumeWindow = time.Minute
+)
+
 // EventsHandler handles Server-Sent Events (SSE) for real-time game updates
 type EventsHandler struct {
 	redisClient *redis.Client
+	chunks      *events.ChunkBuffer
+	heartbeat   time.Duration // how often a ping event is sent
 	logger      *slog.Logger
 }
 
@@ -23,21 +35,31 @@ type EventsHandler struct {
 func NewEventsHandler(redisClient *redis.Client, logger *slog.Logger) *EventsHandler {
 	return &EventsHandler{
 		redisClient: redisClient,
+		chunks:      events.NewChunkBuffer(redisClient),
+		heartbeat:   config.DefaultHeartbeatInterval,
 		logger:      logger,
 	}
 }
 
+// WithHeartbeat sets how often a ping event is sent
+func (h *EventsHandler) WithHeartbeat(interval time.Duration) *EventsHandler {
+	h.heartbeat = interval
+	return h
+}
+
 // ServeHTTP handles SSE requests for game events
-// GET /v1/events/games/{gameStateID}
+// GET /v1/events/gamestate/{gameStateID}
+// chat.chunk events carry IDs. A reconnecting client's Last-Event-ID header replays the
+// chunks buffered after that one before live events resume, so a turn's narration isn't
+// lost to a dropped connection. Resumes are rate limited per game. A ping event is sent
+// every heartbeat interval, so clients can treat a silent stream as dropped.
 func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		h.logger.Warn("Method not allowed for events endpoint",
 			"method", r.Method,
 			"path", r.URL.Path)
 		w.WriteHeader(http.StatusMethodNotAllowed)
-		if err := json.NewEncoder(w).Encode(ErrorResponse{
-			Error: "Method not allowed. Only GET is supported.",
-		}); err != nil {
+		if err := json.NewEncoder(w).Encode(newErrorResponse(w, "Method not allowed. Only GET is supported.")); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
 		return
@@ -48,9 +70,7 @@ func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
 	if len(pathParts) != 4 || pathParts[0] != "v1" || pathParts[1] != "events" || pathParts[2] != "gamestate" {
 		w.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(w).Encode(ErrorResponse{
-			Error: "Invalid path. Expected /v1/events/gamestate/{gameStateID}",
-		}); err != nil {
+		if err := json.NewEncoder(w).Encode(newErrorResponse(w, "Invalid path. Expected /v1/events/gamestate/{gameStateID}")); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
 		return
@@ -60,17 +80,33 @@ func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	gameStateID, err := uuid.Parse(gameStateIDStr)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(w).Encode(ErrorResponse{
-			Error: "Invalid game state ID format.",
-		}); err != nil {
+		if err := json.NewEncoder(w).Encode(newErrorResponse(w, "Invalid game state ID format.")); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
 		return
 	}
 
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID != "" && !events.ValidChunkID(lastEventID) {
+		h.logger.Debug("Ignoring unknown Last-Event-ID", "last_event_id", lastEventID)
+		lastEventID = ""
+	}
+	if lastEventID != "" {
+		if retryAfter, ok := h.allowResume(r.Context(), gameStateID); !ok {
+			h.logger.Warn("SSE resume rate limited", "game_state_id", gameStateID.String())
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			w.WriteHeader(http.StatusTooManyRequests)
+			if err := json.NewEncoder(w).Encode(newErrorResponse(w, "Too many reconnections. Retry later.")); err != nil {
+				h.logger.Error("Failed to encode error response", "error", err)
+			}
+			return
+		}
+	}
+
 	h.logger.Info("SSE connection established",
 		"game_state_id", gameStateID.String(),
-		"remote_addr", r.RemoteAddr)
+		"remote_addr", r.RemoteAddr,
+		"resumed", lastEventID != "")
 
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -92,21 +128,44 @@ func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	// Wait for the subscription, so no chunk published during the replay is missed
+	if lastEventID != "" {
+		if _, err := pubsub.Receive(r.Context()); err != nil {
+			h.logger.Error("Failed to subscribe to channel", "error", err, "channel", channel)
+			return
+		}
+	}
+
 	h.logger.Debug("Subscribed to channel", "channel", channel)
 
 	// Create message channel
 	msgChan := pubsub.Channel()
 
-	// Keepalive ticker (30 seconds)
-	keepaliveTicker := time.NewTicker(30 * time.Second)
-	defer keepaliveTicker.Stop()
+	// Heartbeats keep proxies and clients from closing the stream while the LLM thinks
+	heartbeatTicker := time.NewTicker(h.heartbeat)
+	defer heartbeatTicker.Stop()
 
 	// Send initial connection event
-	h.sendSSE(w, "connected", map[string]interface{}{
-		"game_id": gameStateID.String(),
-		"message": "Connected to event stream",
+	h.sendSSE(w, "", "connected", map[string]interface{}{
+		"game_id":           gameStateID.String(),
+		"message":           "Connected to event stream",
+		"heartbeat_seconds": int(h.heartbeat.Seconds()),
+		"state_updates":     true,
 	})
 
+	// Replay the chunks the client missed. Live chunks up to the last replayed one were
+	// also published while the client was away, and are skipped.
+	if lastEventID != "" {
+		missed, err := h.chunks.Since(r.Context(), gameStateID, lastEventID)
+		if err != nil {
+			h.logger.Error("Failed to read chat chunk buffer", "error", err, "game_state_id", gameStateID.String())
+		}
+		for _, event := range missed {
+			h.sendSSE(w, event.ID, string(event.Type), event.Data)
+			lastEventID = event.ID
+		}
+	}
+
 	for {
 		select {
 		case <-r.Context().Done():
@@ -123,30 +182,54 @@ func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				continue
 			}
 
+			if event.ID != "" && lastEventID != "" && !events.ChunkIDAfter(event.ID, lastEventID) {
+				continue
+			}
+
 			// Forward event to client
-			h.sendSSE(w, string(event.Type), event.Data)
+			h.sendSSE(w, event.ID, string(event.Type), event.Data)
 
-		case <-keepaliveTicker.C:
-			// Send keepalive comment
-			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
-				h.logger.Error("Failed to write keepalive", "error", err)
-				return
-			}
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
-			}
+		case <-heartbeatTicker.C:
+			h.sendSSE(w, "", "ping", map[string]interface{}{
+				"time": time.Now().UTC().Format(time.RFC3339),
+			})
 		}
 	}
 }
 
-// sendSSE sends a Server-Sent Event to the client
-func (h *EventsHandler) sendSSE(w http.ResponseWriter, eventType string, data interface{}) {
+// allowResume counts a resume against the game's limit, returning how long until the
+// window resets when the limit is reached. Resumes are allowed if Redis can't be reached.
+func (h *EventsHandler) allowResume(ctx context.Context, gameStateID uuid.UUID) (time.Duration, bool) {
+	key := "sse-resumes:" + gameStateID.String()
+	pipe := h.redisClient.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, resumeWindow)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		h.logger.Error("Failed to count SSE resume", "error", err, "game_state_id", gameStateID.String())
+		return 0, true
+	}
+	if count.Val() > maxResumes {
+		return ttl.Val(), false
+	}
+	return 0, true
+}
+
+// sendSSE sends a Server-Sent Event to the client, with an ID when there is one
+func (h *EventsHandler) sendSSE(w http.ResponseWriter, id, eventType string, data interface{}) {
 	dataJSON, err := json.Marshal(data)
 	if err != nil {
 		h.logger.Error("Failed to marshal SSE data", "error", err)
 		return
 	}
 
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			h.logger.Error("Failed to write event ID", "error", err)
+			return
+		}
+	}
+
 	if _, err := fmt.Fprintf(w, "event: %s\n", eventType); err != nil {
 		h.logger.Error("Failed to write event type", "error", err)
 		return
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/services/events"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestEventsHandler_Resume(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	handler := NewEventsHandler(client, logger)
+
+	gameID := uuid.New()
+	broadcaster := events.NewBroadcaster(client, logger)
+	for _, content := range []string{"The fog ", "lifts ", "slowly."} {
+		if err := broadcaster.PublishChatChunk(context.Background(), gameID, "req-1", content, false); err != nil {
+			t.Fatalf("Failed to publish chunk: %v", err)
+		}
+	}
+	chunks, err := events.NewChunkBuffer(client).Since(context.Background(), gameID, "0-0")
+	if err != nil || len(chunks) != 3 {
+		t.Fatalf("Expected 3 buffered chunks, got %v (%v)", chunks, err)
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		lastEventID    string
+		expectedStatus int
+		expected       []string
+		unexpected     []string
+	}{
+		{
+			name:           "new connection",
+			path:           "/v1/events/gamestate/" + gameID.String(),
+			expectedStatus: http.StatusOK,
+			expected:       []string{"event: connected"},
+			unexpected:     []string{"The fog"},
+		},
+		{
+			name:           "resume after last chunk",
+			path:           "/v1/events/gamestate/" + gameID.String(),
+			lastEventID:    chunks[0].ID,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"event: connected", "id: " + chunks[1].ID, "lifts ", "id: " + chunks[2].ID, "slowly."},
+			unexpected:     []string{"The fog"},
+		},
+		{
+			name:           "unknown last event ID",
+			path:           "/v1/events/gamestate/" + gameID.String(),
+			lastEventID:    "not-an-id",
+			expectedStatus: http.StatusOK,
+			expected:       []string{"event: connected"},
+			unexpected:     []string{"The fog", "lifts "},
+		},
+		{name: "invalid game ID", path: "/v1/events/gamestate/not-a-uuid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil).WithContext(ctx)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			body := rr.Body.String()
+			for _, want := range tt.expected {
+				if !strings.Contains(body, want) {
+					t.Errorf("Expected stream to contain %q, got %s", want, body)
+				}
+			}
+			for _, unwanted := range tt.unexpected {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("Expected stream not to contain %q, got %s", unwanted, body)
+				}
+			}
+		})
+	}
+}
+
+func TestEventsHandler_ResumeRateLimit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	handler := NewEventsHandler(client, logger)
+	path := "/v1/events/gamestate/" + uuid.New().String()
+
+	resume := func() *httptest.ResponseRecorder {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
+		req.Header.Set("Last-Event-ID", "1700000000000-0")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for i := range maxResumes {
+		if rr := resume(); rr.Code != http.StatusOK {
+			t.Fatalf("Expected resume %d to be allowed, got status %d", i, rr.Code)
+		}
+	}
+	rr := resume()
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429 past the limit, got %d", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected a Retry-After header")
+	}
+
+	// New connections aren't limited
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	fresh := httptest.NewRecorder()
+	handler.ServeHTTP(fresh, httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx))
+	if fresh.Code != http.StatusOK {
+		t.Errorf("Expected a new connection to be allowed, got status %d", fresh.Code)
+	}
+
+	mr.FastForward(resumeWindow)
+	if rr := resume(); rr.Code != http.StatusOK {
+		t.Errorf("Expected resumes to be allowed again after the window, got status %d", rr.Code)
+	}
+}
+
+func TestEventsHandler_Heartbeat(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	handler := NewEventsHandler(client, logger).WithHeartbeat(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/v1/events/gamestate/"+uuid.New().String(), nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	if !strings.Contains(body, `"heartbeat_seconds":0`) {
+		t.Errorf("Expected the connected event to carry the heartbeat interval, got %s", body)
+	}
+	if strings.Count(body, "event: ping") < 2 {
+		t.Errorf("Expected repeated ping events, got %s", body)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// GameEventsResponse lists a game state's event log
+type GameEventsResponse struct {
+	GameStateID uuid.UUID         `json:"gamestate_id"`
+	Events      []state.GameEvent `json:"events"`
+}
+
+// GameEventResponse is one event from a game state's log, with the state as it was
+// right after the event
+type GameEventResponse struct {
+	Event state.GameEvent  `json:"event"`
+	State *state.GameState `json:"state"`
+}
+
+// ForkRequest is the optional body for forking a game
+type ForkRequest struct {
+	Seq int `json:"seq,omitempty"` // Event to fork after; defaults to the latest
+}
+
+// loadGameEvents loads a game state owned by the handler's profile and its event log,
+// writing a 404 response if the game or its log doesn't exist
+func (h *GameStateHandler) loadGameEvents(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) (*state.GameState, storage.EventLog, []state.GameEvent, bool) {
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	eventLog, ok := h.storage.(storage.EventLog)
+	if !ok {
+		h.writeError(w, http.StatusNotFound, "No event log for this game state. Event sourcing is not supported by this server.")
+		return nil, nil, nil, false
+	}
+	events, err := eventLog.ListGameEvents(r.Context(), gameStateID)
+	if err != nil {
+		h.logger.Error("Failed to load game events", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to load game events")
+		return nil, nil, nil, false
+	}
+	if len(events) == 0 {
+		h.writeError(w, http.StatusNotFound, "No event log for this game state. Event sourcing may be turned off.")
+		return nil, nil, nil, false
+	}
+	return gs, eventLog, events, true
+}
+
+// handleEvents returns a game state's event log, or a single event with the state
+// rebuilt as of that event
+func (h *GameStateHandler) handleEvents(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID, seqStr string) {
+	_, _, events, ok := h.loadGameEvents(w, r, gameStateID)
+	if !ok {
+		return
+	}
+
+	if seqStr == "" {
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(GameEventsResponse{GameStateID: gameStateID, Events: events}); err != nil {
+			h.logger.Error("Failed to encode events response", "error", err)
+		}
+		return
+	}
+
+	seq, err := strconv.Atoi(seqStr)
+	if err != nil || seq < 1 {
+		h.writeError(w, http.StatusBadRequest, "Invalid event number: "+seqStr)
+		return
+	}
+	if seq > len(events) {
+		h.writeError(w, http.StatusNotFound, "No event "+seqStr+" in this game's log")
+		return
+	}
+	gs, err := state.Project(events[:seq])
+	if err != nil {
+		h.logger.Error("Failed to project game state", "error", err, "id", gameStateID.String(), "seq", seq)
+		h.writeError(w, http.StatusInternalServerError, "Failed to rebuild game state")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(GameEventResponse{Event: events[seq-1], State: gs}); err != nil {
+		h.logger.Error("Failed to encode event response", "error", err)
+	}
+}
+
+// handleFork starts a new game from a game state as it was after one of its events.
+// The new game keeps the original's history in its own event log.
+func (h *GameStateHandler) handleFork(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	var req ForkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Warn("Invalid JSON in fork request body", "error", err)
+		h.writeError(w, http.StatusBadRequest, "Invalid JSON in request body")
+		return
+	}
+
+	current, eventLog, events, ok := h.loadGameEvents(w, r, gameStateID)
+	if !ok || h.rejectTrial(w, current, "forked") {
+		return
+	}
+	seq := req.Seq
+	if seq == 0 {
+		seq = len(events)
+	}
+	if seq < 1 || seq > len(events) {
+		h.writeError(w, http.StatusBadRequest, "Invalid event number: "+strconv.Itoa(req.Seq))
+		return
+	}
+
+	gs, err := eventLog.ForkGameState(r.Context(), gameStateID, seq, uuid.New())
+	if err != nil {
+		h.logger.Error("Failed to fork game state", "error", err, "id", gameStateID.String(), "seq", seq)
+		h.writeError(w, http.StatusInternalServerError, "Failed to fork game state")
+		return
+	}
+	if h.analytics != nil {
+		if err := h.analytics.RecordGameStarted(r.Context(), gs); err != nil {
+			h.logger.Warn("Failed to record game start analytics", "error", err, "id", gs.ID.String())
+		}
+	}
+
+	h.logger.Info("Game forked", "id", gameStateID.String(), "seq", seq, "fork_id", gs.ID.String())
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(gs); err != nil {
+		h.logger.Error("Failed to encode forked game state response", "error", err)
+	}
+}
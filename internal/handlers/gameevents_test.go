@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/jsonpatch"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// eventLogStorage is a mock storage that records an event for every save
+type eventLogStorage struct {
+	*storage.MockStorage
+	events map[uuid.UUID][]state.GameEvent
+	states map[uuid.UUID][]byte
+}
+
+func newEventLogStorage() *eventLogStorage {
+	return &eventLogStorage{
+		MockStorage: storage.NewMockStorage(),
+		events:      make(map[uuid.UUID][]state.GameEvent),
+		states:      make(map[uuid.UUID][]byte),
+	}
+}
+
+func (s *eventLogStorage) SaveGameState(ctx context.Context, id uuid.UUID, gs *state.GameState) error {
+	data, err := json.Marshal(gs)
+	if err != nil {
+		return err
+	}
+	prev, ok := s.states[id]
+	if !ok {
+		prev = []byte("{}")
+	}
+	ev := state.GameEventFromContext(ctx)
+	ev.Seq = len(s.events[id]) + 1
+	ev.Turn = gs.TurnCounter
+	if ev.Patch, err = jsonpatch.CreateMergePatch(prev, data); err != nil {
+		return err
+	}
+	s.events[id] = append(s.events[id], ev)
+	s.states[id] = data
+	copied, err := gs.DeepCopy()
+	if err != nil {
+		return err
+	}
+	return s.MockStorage.SaveGameState(ctx, id, copied)
+}
+
+func (s *eventLogStorage) ListGameEvents(_ context.Context, id uuid.UUID) ([]state.GameEvent, error) {
+	return s.events[id], nil
+}
+
+func (s *eventLogStorage) ForkGameState(ctx context.Context, id uuid.UUID, seq int, newID uuid.UUID) (*state.GameState, error) {
+	gs, err := state.Project(s.events[id][:seq])
+	if err != nil {
+		return nil, err
+	}
+	gs.ID = newID
+	return gs, s.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventForked, ForkedFrom: id.String()}), newID, gs)
+}
+
+func TestGameStateHandler_Events(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	ctx := context.Background()
+
+	st := newEventLogStorage()
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	gs.Location = "hall"
+	if err := st.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventCreated}), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+	gs.IncrementTurnCounters()
+	gs.Location = "cellar"
+	if err := st.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventDelta}), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+
+	// Games saved without event sourcing have no log
+	unlogged := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	if err := st.MockStorage.SaveGameState(ctx, unlogged.ID, unlogged); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+
+	handler := NewGameStateHandler(logger, "foo_model", st)
+	base := "/v1/gamestate/" + gs.ID.String()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		check          func(t *testing.T, body []byte)
+	}{
+		{
+			name: "list events", method: http.MethodGet, path: base + "/events", expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp GameEventsResponse
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if len(resp.Events) != 2 || resp.Events[0].Kind != state.EventCreated || resp.Events[1].Kind != state.EventDelta {
+					t.Errorf("Expected created and delta events, got %+v", resp.Events)
+				}
+			},
+		},
+		{
+			name: "state at an event", method: http.MethodGet, path: base + "/events/1", expectedStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp GameEventResponse
+				if err := json.Unmarshal(body, &resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if resp.Event.Seq != 1 || resp.State == nil || resp.State.Location != "hall" {
+					t.Errorf("Expected the state after event 1 to be in the hall, got %+v", resp.State)
+				}
+			},
+		},
+		{name: "event past the end", method: http.MethodGet, path: base + "/events/3", expectedStatus: http.StatusNotFound},
+		{name: "invalid event number", method: http.MethodGet, path: base + "/events/zero", expectedStatus: http.StatusBadRequest},
+		{name: "no event log", method: http.MethodGet, path: "/v1/gamestate/" + unlogged.ID.String() + "/events", expectedStatus: http.StatusNotFound},
+		{name: "unknown game", method: http.MethodGet, path: "/v1/gamestate/" + uuid.New().String() + "/events", expectedStatus: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPost, path: base + "/events", expectedStatus: http.StatusMethodNotAllowed},
+		{
+			name: "fork at an event", method: http.MethodPost, path: base + "/fork", body: `{"seq": 1}`, expectedStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				var fork state.GameState
+				if err := json.Unmarshal(body, &fork); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if fork.ID == gs.ID || fork.Location != "hall" {
+					t.Errorf("Expected a new game in the hall, got %+v", fork)
+				}
+				if loaded, _ := st.LoadGameState(ctx, fork.ID); loaded == nil {
+					t.Error("Expected the fork to be saved")
+				}
+			},
+		},
+		{
+			name: "fork latest", method: http.MethodPost, path: base + "/fork", expectedStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				var fork state.GameState
+				if err := json.Unmarshal(body, &fork); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if fork.Location != "cellar" || fork.TurnCounter != 1 {
+					t.Errorf("Expected a copy of the latest state, got %+v", fork)
+				}
+			},
+		},
+		{name: "fork past the end", method: http.MethodPost, path: base + "/fork", body: `{"seq": 9}`, expectedStatus: http.StatusBadRequest},
+		{name: "fork invalid body", method: http.MethodPost, path: base + "/fork", body: `{"seq":`, expectedStatus: http.StatusBadRequest},
+		{name: "fork wrong method", method: http.MethodGet, path: base + "/fork", expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
@@ -1,62 +1,161 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"mime"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/internal/services"
 	"github.com/jwebster45206/story-engine/pkg/actor"
 	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/jsonpatch"
+	"github.com/jwebster45206/story-engine/pkg/prompts"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
 	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
 )
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"` // Quote this when reporting a problem
 }
 
+// newErrorResponse returns an error body carrying the request ID that the logger
+// middleware set on the response
+func newErrorResponse(w http.ResponseWriter, message string) ErrorResponse {
+	return ErrorResponse{Error: message, RequestID: w.Header().Get(middleware.RequestIDHeader)}
+}
+
+// openingIntroTimeout bounds the LLM call for a personalized opening intro
+const openingIntroTimeout = 30 * time.Second
+
 type GameStateHandler struct {
-	storage   storage.Storage
-	logger    *slog.Logger
-	modelName string
+	storage    storage.Storage
+	logger     *slog.Logger
+	modelName  string
+	models     *config.ModelRegistry
+	profile    string              // owning profile stamped on created games
+	maxRating  string              // highest scenario rating the profile allows
+	llmService services.LLMService // optional; enables LLM-generated opening intros
+	budget     BudgetChecker       // optional; refuses new games past the API key's budget cap
+	analytics  Analytics           // optional; counts new games in the scenario's play statistics
+	chatQueue  state.ChatQueue     // optional; re-queues held story events and queues regenerated and chosen turns
+	adminKey   string              // optional; enables admin-only bulk delete
 }
 
 func NewGameStateHandler(logger *slog.Logger, modelName string, storage storage.Storage) *GameStateHandler {
 	return &GameStateHandler{
 		logger:    logger,
 		modelName: modelName,
+		models:    config.NewModelRegistry(nil),
 		storage:   storage,
 	}
 }
 
+// WithLLMService enables LLM-backed features at game creation, such as
+// personalized opening intros. Without it those steps are skipped.
+func (h *GameStateHandler) WithLLMService(llmService services.LLMService) *GameStateHandler {
+	h.llmService = llmService
+	return h
+}
+
+// WithProfile scopes the handler to a configuration profile: created games are
+// stamped with the profile, games from other profiles are not visible, and
+// scenarios above the profile's max rating are refused
+func (h *GameStateHandler) WithProfile(profile config.Profile) *GameStateHandler {
+	h.profile = profile.Name
+	h.maxRating = profile.MaxRating
+	return h
+}
+
+// WithBudget refuses game creation once the API key is past its hard budget cap
+// and charges opening intro LLM calls to the new game
+func (h *GameStateHandler) WithBudget(budget BudgetChecker) *GameStateHandler {
+	h.budget = budget
+	return h
+}
+
+// WithAnalytics counts each new game in its scenario's play statistics
+func (h *GameStateHandler) WithAnalytics(analytics Analytics) *GameStateHandler {
+	h.analytics = analytics
+	return h
+}
+
+// WithQueue lets resuming a paused game queue the story events held while it was paused,
+// and enables regenerating the last turn and choosing narration variants
+func (h *GameStateHandler) WithQueue(chatQueue state.ChatQueue) *GameStateHandler {
+	h.chatQueue = chatQueue
+	return h
+}
+
+// WithModelRegistry sets the model capabilities checked when creating games
+func (h *GameStateHandler) WithModelRegistry(models *config.ModelRegistry) *GameStateHandler {
+	h.models = models
+	return h
+}
+
 // ServeHTTP handles HTTP requests for game state operations
 // Routes:
-// POST /gamestate        - Create new game state
-// GET /gamestate/{id}    - Read game state by ID
-// PATCH /gamestate/{id}  - Update game state
-// DELETE /gamestate/{id} - Delete game state by ID
+// POST /gamestate                     - Create new game state
+// GET /gamestate/{id}                 - Read game state by ID
+// PATCH /gamestate/{id}               - Update game state
+// DELETE /gamestate/{id}              - Delete game state by ID
+// DELETE /gamestate?ended=true         - Bulk delete matching game states (admin only)
+// GET /gamestate/{id}/receipts        - List recent turn receipts
+// GET /gamestate/{id}/receipts/{turn} - Read the turn receipt for a turn
+// GET /gamestate/{id}/codex           - List the lore entries the player has discovered
+// GET /gamestate/{id}/hints           - List the hints given in the current scene
+// POST /gamestate/import              - Restore a game from a save file
+// GET /gamestate/{id}/export          - Download the game as a portable save file
+// GET /gamestate/{id}/events          - List the event log, when event sourcing is on
+// GET /gamestate/{id}/events/{seq}    - Read an event with the game state as of that event
+// POST /gamestate/{id}/fork           - Start a new game from the state after an event
+// POST /gamestate/{id}/pause          - Pause a game
+// POST /gamestate/{id}/resume         - Resume a paused game
+// POST /gamestate/{id}/regenerate     - Narrate the last player turn again
+// POST /gamestate/{id}/continue       - Narrate more of the last narration
+// POST /gamestate/{id}/choose         - Choose which narration variant becomes canon
+// POST /gamestate/{id}/narrator       - Switch the game's narrator
+// GET /gamestate/{id}/snapshots       - List the game's named snapshots
+// POST /gamestate/{id}/snapshots      - Save a named snapshot of the game
+// POST /gamestate/{id}/restore/{name} - Roll the game back to a named snapshot
 func (h *GameStateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Parse the path to extract ID for GET/DELETE operations
 	path := strings.TrimPrefix(r.URL.Path, "/v1/gamestate")
+	if strings.Trim(path, "/") == "import" {
+		if r.Method != http.MethodPost {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: POST")
+			return
+		}
+		h.handleImport(w, r)
+		return
+	}
 	var gameStateID uuid.UUID
+	var subPath string
 	var err error
 
 	if path != "" && path != "/" {
-		// Extract ID from path like "/uuid" or "/{uuid}"
-		idStr := strings.Trim(path, "/")
+		// Extract ID from path like "/uuid" or "/{uuid}", with an optional sub-resource after it
+		idStr, rest, _ := strings.Cut(strings.Trim(path, "/"), "/")
+		subPath = rest
 		gameStateID, err = uuid.Parse(idStr)
 		if err != nil {
 			h.logger.Warn("Invalid game state ID", "id", idStr, "error", err)
 			w.WriteHeader(http.StatusBadRequest)
-			response := ErrorResponse{
-				Error: "Invalid game state ID format",
-			}
+			response := newErrorResponse(w, "Invalid game state ID format")
 			if err := json.NewEncoder(w).Encode(response); err != nil {
 				h.logger.Error("Failed to encode error response", "error", err)
 			}
@@ -64,6 +163,11 @@ func (h *GameStateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if subPath != "" {
+		h.serveSubresource(w, r, gameStateID, subPath)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodPost:
 		h.handleCreate(w, r)
@@ -72,9 +176,7 @@ func (h *GameStateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if gameStateID == uuid.Nil {
 			h.logger.Warn("GET request without game state ID")
 			w.WriteHeader(http.StatusBadRequest)
-			response := ErrorResponse{
-				Error: "Game state ID is required for GET requests",
-			}
+			response := newErrorResponse(w, "Game state ID is required for GET requests")
 			if err := json.NewEncoder(w).Encode(response); err != nil {
 				h.logger.Error("Failed to encode error response", "error", err)
 			}
@@ -85,9 +187,7 @@ func (h *GameStateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPatch:
 		if gameStateID == uuid.Nil {
 			w.WriteHeader(http.StatusBadRequest)
-			response := ErrorResponse{
-				Error: "Game state ID is required for PATCH requests",
-			}
+			response := newErrorResponse(w, "Game state ID is required for PATCH requests")
 			if err := json.NewEncoder(w).Encode(response); err != nil {
 				h.logger.Error("Failed to encode error response", "error", err)
 			}
@@ -96,12 +196,14 @@ func (h *GameStateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handlePatch(w, r, gameStateID)
 
 	case http.MethodDelete:
+		if gameStateID == uuid.Nil && r.URL.RawQuery != "" {
+			h.handleBulkDelete(w, r)
+			return
+		}
 		if gameStateID == uuid.Nil {
 			h.logger.Warn("DELETE request without game state ID")
 			w.WriteHeader(http.StatusBadRequest)
-			response := ErrorResponse{
-				Error: "Game state ID is required for DELETE requests",
-			}
+			response := newErrorResponse(w, "Game state ID is required for DELETE requests")
 			if err := json.NewEncoder(w).Encode(response); err != nil {
 				h.logger.Error("Failed to encode error response", "error", err)
 			}
@@ -112,35 +214,86 @@ func (h *GameStateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	default:
 		h.logger.Warn("Method not allowed for game state endpoint", "method", r.Method)
 		w.WriteHeader(http.StatusMethodNotAllowed)
-		response := ErrorResponse{
-			Error: "Method not allowed. Supported methods: POST, GET, PATCH, DELETE",
-		}
+		response := newErrorResponse(w, "Method not allowed. Supported methods: POST, GET, PATCH, DELETE")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
 	}
 }
 
-func isCensoredModel(modelName string) bool {
-	modelLower := strings.ToLower(modelName)
-	if strings.Contains(modelLower, "gpt") ||
-		strings.Contains(modelLower, "claude") ||
-		strings.HasPrefix(modelLower, "text-davinci") ||
-		strings.HasPrefix(modelLower, "text-curie") ||
-		strings.HasPrefix(modelLower, "text-babbage") ||
-		strings.HasPrefix(modelLower, "text-ada") ||
-		strings.Contains(modelLower, "openai") ||
-		strings.Contains(modelLower, "anthropic") {
-		return true
+// checkModelAvailable returns an error unless modelName is the server's default
+// model or matches a model in the configured registry
+func (h *GameStateHandler) checkModelAvailable(modelName string) error {
+	if modelName == h.modelName || h.models.IsConfigured(modelName) {
+		return nil
 	}
-	return false
+	available := append([]string{h.modelName}, h.models.ConfiguredNames()...)
+	return fmt.Errorf("Model %s is not available. Available models: %s", modelName, strings.Join(available, ", "))
+}
+
+// checkModelSwap validates switching a running game to another model:
+// the model must be available and support the game's scenario rating
+func (h *GameStateHandler) checkModelSwap(ctx context.Context, gs *state.GameState, modelName string) error {
+	if err := h.checkModelAvailable(modelName); err != nil {
+		return err
+	}
+	s, err := h.storage.GetScenario(ctx, gs.Scenario)
+	if err != nil {
+		return fmt.Errorf("failed to load scenario: %w", err)
+	}
+	return h.checkModelCompatibility(ctx, modelName, s)
+}
+
+// compatibleScenarios returns the sorted names of scenarios whose rating the model allows
+func (h *GameStateHandler) compatibleScenarios(ctx context.Context, caps config.ModelCapabilities) []string {
+	scenarios, err := h.storage.ListScenarios(ctx)
+	if err != nil {
+		h.logger.Warn("Failed to list scenarios", "error", err)
+		return nil
+	}
+	var names []string
+	for name, filename := range scenarios {
+		s, err := h.storage.GetScenario(ctx, filename)
+		if err != nil {
+			continue
+		}
+		if caps.AllowsRating(s.Rating) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkModelCompatibility returns an error describing why the model can't run the scenario,
+// listing the scenarios it can run instead
+func (h *GameStateHandler) checkModelCompatibility(ctx context.Context, modelName string, s *scenario.Scenario) error {
+	caps := h.models.Lookup(modelName).LimitRatings(h.maxRating)
+	if caps.AllowsRating(s.Rating) {
+		return nil
+	}
+	allowed := "none"
+	if len(caps.Ratings) > 0 {
+		allowed = strings.Join(caps.Ratings, ", ")
+	}
+	msg := fmt.Sprintf("Model %s does not support scenarios rated %s (allowed ratings: %s)",
+		modelName, s.Rating, allowed)
+	if names := h.compatibleScenarios(ctx, caps); len(names) > 0 {
+		msg += ". Compatible scenarios: " + strings.Join(names, ", ")
+	}
+	return errors.New(msg)
 }
 
 // CreateGameStateRequest defines the request body for creating a new game state
 type CreateGameStateRequest struct {
-	Scenario   string `json:"scenario"`              // Required: scenario filename
-	NarratorID string `json:"narrator_id,omitempty"` // Optional: override scenario's narrator
-	PCID       string `json:"pc_id,omitempty"`       // Optional: override scenario's default PC
+	Scenario    string `json:"scenario"`               // Required: scenario filename
+	NarratorID  string `json:"narrator_id,omitempty"`  // Optional: override scenario's narrator
+	PCID        string `json:"pc_id,omitempty"`        // Optional: override scenario's default PC
+	ChoicesMode *bool  `json:"choices_mode,omitempty"` // Optional: override scenario's choices mode
+	ModelName   string `json:"model_name,omitempty"`   // Optional: override the server's default model
+	Seed        *int64 `json:"seed,omitempty"`         // Optional: random seed, to reproduce a game
+	TrialTurns  int    `json:"trial_turns,omitempty"`  // Optional: make a preview game limited to this many turns
+	Spectators  bool   `json:"spectators,omitempty"`   // Optional: mirror narration to the public spectator feed
 }
 
 // normalizeID converts a string to lowercase snake_case for consistent IDs.
@@ -218,9 +371,7 @@ func (h *GameStateHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Warn("Invalid JSON in request body", "error", err)
 		w.WriteHeader(http.StatusBadRequest)
-		response := ErrorResponse{
-			Error: "Invalid JSON in request body",
-		}
+		response := newErrorResponse(w, "Invalid JSON in request body")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
@@ -234,40 +385,56 @@ func (h *GameStateHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 	if req.Scenario == "" {
 		h.logger.Warn("Missing required field: scenario")
 		w.WriteHeader(http.StatusBadRequest)
-		response := ErrorResponse{
-			Error: "scenario field is required",
-		}
+		response := newErrorResponse(w, "scenario field is required")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
 		return
 	}
 
+	if req.TrialTurns < 0 {
+		h.logger.Warn("Invalid trial_turns", "trial_turns", req.TrialTurns)
+		h.writeError(w, http.StatusBadRequest, "trial_turns must be positive")
+		return
+	}
+
+	keyID := middleware.APIKeyID(r.Context())
+	if h.budget != nil && !checkBudget(w, r, h.budget, h.logger, uuid.Nil, keyID) {
+		return
+	}
+
 	// Get initial gamestate values from scenario
 	s, err := h.storage.GetScenario(r.Context(), req.Scenario)
 	if err != nil {
 		h.logger.Warn("Failed to load scenario", "error", err)
 		w.WriteHeader(http.StatusBadRequest)
-		response := ErrorResponse{
-			Error: "Failed to load scenario: " + err.Error(),
-		}
+		response := newErrorResponse(w, "Failed to load scenario: "+err.Error())
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
 		return
 	}
 
-	// If using a censored model, check the scenario for compatibility
-	if isCensoredModel(h.modelName) &&
-		s.Rating != scenario.RatingG &&
-		s.Rating != scenario.RatingPG &&
-		s.Rating != scenario.RatingPG13 &&
-		s.Rating != "PG13" {
-		h.logger.Error("Attempt to use censored model with wrong scenario rating", "model", h.modelName, "rating", s.Rating)
-		w.WriteHeader(http.StatusBadRequest)
-		response := ErrorResponse{
-			Error: "Censored model cannot be used with this scenario rating: " + s.Rating,
+	// Use the requested model if it's available, otherwise the server default
+	modelName := h.modelName
+	if req.ModelName != "" {
+		if err := h.checkModelAvailable(req.ModelName); err != nil {
+			h.logger.Warn("Requested model not available", "model", req.ModelName)
+			w.WriteHeader(http.StatusBadRequest)
+			response := newErrorResponse(w, err.Error())
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				h.logger.Error("Failed to encode error response", "error", err)
+			}
+			return
 		}
+		modelName = req.ModelName
+	}
+
+	// Check the scenario's rating against the model's capabilities
+	if err := h.checkModelCompatibility(r.Context(), modelName, s); err != nil {
+		h.logger.Warn("Scenario rating not supported by model", "model", modelName, "rating", s.Rating)
+		w.WriteHeader(http.StatusBadRequest)
+		response := newErrorResponse(w, err.Error())
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
@@ -290,13 +457,27 @@ func (h *GameStateHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Create a new GameState with embedded narrator
-	gs := state.NewGameState(req.Scenario, narrator, h.modelName)
+	gs := state.NewGameState(req.Scenario, narrator, modelName)
+	gs.Profile = h.profile
+	gs.APIKeyID = keyID
+	if req.Seed != nil {
+		gs.Seed = *req.Seed
+	}
+	if req.TrialTurns > 0 {
+		gs.Trial = &state.Trial{MaxTurns: req.TrialTurns}
+	}
+	gs.Spectators = req.Spectators
 
 	// Initialize game state with scenario-level values
 	gs.NPCs = s.NPCs
 	gs.Location = s.OpeningLocation
 	gs.WorldLocations = s.Locations
+	gs.ItemNames = s.ItemNames()
 	gs.Vars = s.Vars
+	gs.ChoicesMode = s.ChoicesMode
+	if req.ChoicesMode != nil {
+		gs.ChoicesMode = *req.ChoicesMode
+	}
 	// ContingencyPrompts field is for runtime-added custom prompts only
 	// Scenario-level prompts are already filtered and added in GetContingencyPrompts()
 	// so we don't copy them here to avoid duplication
@@ -345,7 +526,7 @@ func (h *GameStateHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 	// Add PC starting inventory (if PC loaded)
 	if loadedPC != nil && loadedPC.Spec != nil && loadedPC.Spec.Inventory != nil {
 		for _, item := range loadedPC.Spec.Inventory {
-			inventoryMap[item] = true
+			inventoryMap[s.ItemID(item)] = true
 		}
 	}
 
@@ -361,32 +542,13 @@ func (h *GameStateHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 		gs.PC.Spec.Inventory = nil
 	}
 
-	// Add the opening prompt to chat history
-	// If the scenario opening prompt contains %s and PC has an opening_prompt, inject it
-	if s.OpeningPrompt != "" {
-		openingPrompt := s.OpeningPrompt
-
-		// Check if scenario prompt has placeholder and PC has opening prompt
-		// Use gs.PC instead of loadedPC since that's the canonical reference
-		if strings.Contains(openingPrompt, "%s") && gs.PC != nil && gs.PC.Spec != nil && gs.PC.Spec.OpeningPrompt != "" {
-			openingPrompt = fmt.Sprintf(openingPrompt, gs.PC.Spec.OpeningPrompt)
-		}
-
-		gs.ChatHistory = append(gs.ChatHistory, chat.ChatMessage{
-			Role:    chat.ChatRoleAgent,
-			Content: openingPrompt,
-		})
-	}
-
 	// If scenes are used, load the first scene
 	if s.OpeningScene != "" {
 		err = gs.LoadScene(s, s.OpeningScene)
 		if err != nil {
 			h.logger.Warn("Failed to load opening scene", "error", err)
 			w.WriteHeader(http.StatusBadRequest)
-			response := ErrorResponse{
-				Error: "Failed to load opening scene: " + err.Error(),
-			}
+			response := newErrorResponse(w, "Failed to load opening scene: "+err.Error())
 			if err := json.NewEncoder(w).Encode(response); err != nil {
 				h.logger.Error("Failed to encode error response", "error", err)
 			}
@@ -415,6 +577,11 @@ func (h *GameStateHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 		gs.NPCs[npcKey] = *merged
 		h.logger.Debug("Loaded NPC from template", "npc_key", npcKey, "template_id", npc.TemplateID, "name", merged.Name)
 	}
+	// NPCs with hit points start at full health unless the scenario says otherwise
+	for npcKey, npc := range gs.NPCs {
+		npc.InitHP()
+		gs.NPCs[npcKey] = npc
+	}
 
 	// Load monster templates for any pre-placed monsters
 	// Iterate through all locations and populate monsters that only have template_id set
@@ -449,33 +616,134 @@ func (h *GameStateHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 		gs.WorldLocations[locName] = loc
 	}
 
-	if err := h.storage.SaveGameState(r.Context(), gs.ID, gs); err != nil {
+	// Add the opening prompt to chat history, once the opening scene has set the
+	// starting location and vars its placeholders may refer to.
+	// If the scenario opening prompt contains %s and PC has an opening_prompt, inject it
+	if s.OpeningPrompt != "" {
+		openingPrompt := s.OpeningPrompt
+
+		// Check if scenario prompt has placeholder and PC has opening prompt
+		// Use gs.PC instead of loadedPC since that's the canonical reference
+		if strings.Contains(openingPrompt, "%s") && gs.PC != nil && gs.PC.Spec != nil && gs.PC.Spec.OpeningPrompt != "" {
+			openingPrompt = fmt.Sprintf(openingPrompt, gs.PC.Spec.OpeningPrompt)
+		}
+
+		gs.ChatHistory = append(gs.ChatHistory, chat.ChatMessage{
+			Role:    chat.ChatRoleAgent,
+			Content: gs.Interpolate(openingPrompt),
+		})
+	}
+
+	// Render the opening sequence after the scene, NPCs, and monsters are in place
+	// so any personalized intro sees the complete starting world state
+	if s.OpeningSequence.HasContent() {
+		gs.ChatHistory = append(gs.ChatHistory, h.buildOpeningSequence(r.Context(), s, gs)...)
+	}
+
+	if err := h.storage.SaveGameState(state.WithGameEvent(r.Context(), state.GameEvent{Kind: state.EventCreated}), gs.ID, gs); err != nil {
 		h.logger.Error("Failed to save new game state", "error", err, "id", gs.ID.String())
 		w.WriteHeader(http.StatusInternalServerError)
-		response := ErrorResponse{
-			Error: "Failed to create game state",
-		}
+		response := newErrorResponse(w, "Failed to create game state")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
 		return
 	}
 
-	h.logger.Debug("Game state created successfully", "id", gs.ID.String())
+	if h.analytics != nil {
+		if err := h.analytics.RecordGameStarted(r.Context(), gs); err != nil {
+			h.logger.Warn("Failed to record game start analytics", "error", err, "id", gs.ID.String())
+		}
+	}
+
+	h.logger.Debug("Game state created successfully", "id", gs.ID.String(), "seed", gs.Seed)
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(gs); err != nil {
 		h.logger.Error("Failed to encode game state response", "error", err)
 	}
 }
 
+// buildOpeningSequence renders the scenario's opening sequence into assistant messages.
+// A personalized intro that fails to generate is logged and skipped so game creation still succeeds.
+func (h *GameStateHandler) buildOpeningSequence(ctx context.Context, s *scenario.Scenario, gs *state.GameState) []chat.ChatMessage {
+	seq := s.OpeningSequence
+	messages := make([]chat.ChatMessage, 0, len(seq.Messages)+2)
+
+	for _, msg := range seq.Messages {
+		if strings.TrimSpace(msg) == "" {
+			continue
+		}
+		messages = append(messages, chat.ChatMessage{
+			Role:    chat.ChatRoleAgent,
+			Content: gs.Interpolate(msg),
+		})
+	}
+
+	if seq.PersonalizedIntro != "" {
+		intro, err := h.generatePersonalizedIntro(ctx, s, gs)
+		if err != nil {
+			h.logger.Warn("Failed to generate personalized intro, skipping", "game_state_id", gs.ID.String(), "error", err)
+		} else if intro != "" {
+			messages = append(messages, chat.ChatMessage{
+				Role:    chat.ChatRoleAgent,
+				Content: intro,
+			})
+		}
+	}
+
+	if seq.Tutorial != "" {
+		messages = append(messages, chat.ChatMessage{
+			Role:    chat.ChatRoleAgent,
+			Content: gs.Interpolate(seq.Tutorial),
+		})
+	}
+
+	return messages
+}
+
+// generatePersonalizedIntro asks the LLM for an opening tailored to the game's PC
+func (h *GameStateHandler) generatePersonalizedIntro(ctx context.Context, s *scenario.Scenario, gs *state.GameState) (string, error) {
+	if h.llmService == nil {
+		return "", fmt.Errorf("no LLM service configured")
+	}
+	if gs.PC == nil {
+		return "", fmt.Errorf("no PC loaded for game")
+	}
+
+	messages, err := prompts.New().
+		WithGameState(gs).
+		WithScenario(s).
+		WithUserMessage(fmt.Sprintf(prompts.PersonalizedIntroPrompt, s.OpeningSequence.PersonalizedIntro), chat.ChatRoleUser).
+		Build()
+	if err != nil {
+		return "", fmt.Errorf("failed to build intro prompt: %w", err)
+	}
+
+	temperature := services.DefaultTemperature
+	if s.Temperature != nil {
+		temperature = *s.Temperature
+	}
+
+	ctx = services.WithModel(ctx, gs.ModelName)
+	ctx = services.WithCaptureLabel(ctx, gs.ID.String(), gs.TurnCounter)
+	if h.budget != nil {
+		ctx = services.WithUsageRecorder(ctx, h.budget.Recorder(gs.ID, gs.APIKeyID))
+	}
+	ctx, cancel := context.WithTimeout(ctx, openingIntroTimeout)
+	defer cancel()
+	resp, err := h.llmService.Chat(ctx, messages, temperature)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate intro: %w", err)
+	}
+	return strings.TrimSpace(resp.Message), nil
+}
+
 func (h *GameStateHandler) handleRead(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
-	gs, err := h.storage.LoadGameState(r.Context(), gameStateID)
+	gs, err := h.loadOwnedGameState(r.Context(), gameStateID)
 	if err != nil {
 		h.logger.Error("Failed to load game state", "error", err, "id", gameStateID.String())
 		w.WriteHeader(http.StatusInternalServerError)
-		response := ErrorResponse{
-			Error: "Failed to load game state",
-		}
+		response := newErrorResponse(w, "Failed to load game state")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
@@ -485,9 +753,7 @@ func (h *GameStateHandler) handleRead(w http.ResponseWriter, r *http.Request, ga
 	if gs == nil {
 		h.logger.Warn("Game state not found", "id", gameStateID.String())
 		w.WriteHeader(http.StatusNotFound)
-		response := ErrorResponse{
-			Error: "Game state not found",
-		}
+		response := newErrorResponse(w, "Game state not found")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
@@ -500,17 +766,34 @@ func (h *GameStateHandler) handleRead(w http.ResponseWriter, r *http.Request, ga
 	}
 }
 
+// ValidationErrorResponse is returned when a strict PATCH fails validation
+type ValidationErrorResponse struct {
+	Error       string             `json:"error"`
+	FieldErrors []state.FieldError `json:"field_errors"`
+	RequestID   string             `json:"request_id,omitempty"`
+}
+
 // handlePatch updates an existing game state.
-// It doesn't do extensive validation of the update, so use with caution.
-// Integ tests are the current use case.
+// By default it doesn't do extensive validation of the update, so use with caution.
+// Integ tests are the current use case. With ?strict=true, unknown fields are
+// rejected and the patched game is validated against its scenario before saving.
+// A plain JSON body only sets non-zero fields; send application/json-patch+json
+// or application/merge-patch+json to clear fields or set zero values.
 func (h *GameStateHandler) handlePatch(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
-	existingGS, err := h.storage.LoadGameState(r.Context(), gameStateID)
+	strict := false
+	if v := r.URL.Query().Get("strict"); v != "" {
+		var err error
+		if strict, err = strconv.ParseBool(v); err != nil {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid strict value %q", v))
+			return
+		}
+	}
+
+	existingGS, err := h.loadOwnedGameState(r.Context(), gameStateID)
 	if err != nil {
 		h.logger.Error("Failed to load game state for patch", "error", err, "id", gameStateID.String())
 		w.WriteHeader(http.StatusInternalServerError)
-		response := ErrorResponse{
-			Error: "Failed to load game state",
-		}
+		response := newErrorResponse(w, "Failed to load game state")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
@@ -520,23 +803,32 @@ func (h *GameStateHandler) handlePatch(w http.ResponseWriter, r *http.Request, g
 	if existingGS == nil {
 		h.logger.Warn("Game state not found for patch", "id", gameStateID.String())
 		w.WriteHeader(http.StatusNotFound)
-		response := ErrorResponse{
-			Error: "Game state not found",
-		}
+		response := newErrorResponse(w, "Game state not found")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
 		return
 	}
 
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == jsonpatch.MediaTypeJSONPatch || mediaType == jsonpatch.MediaTypeMergePatch {
+		h.handleDocumentPatch(w, r, existingGS, mediaType, strict)
+		return
+	}
+
 	// Parse patch request body
 	var patchData state.GameState
-	if err := json.NewDecoder(r.Body).Decode(&patchData); err != nil {
+	decoder := json.NewDecoder(r.Body)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&patchData); err != nil {
 		h.logger.Warn("Invalid JSON in PATCH request body", "error", err)
-		w.WriteHeader(http.StatusBadRequest)
-		response := ErrorResponse{
-			Error: "Invalid JSON in request body",
+		message := "Invalid JSON in request body"
+		if strict {
+			message += ": " + err.Error()
 		}
+		w.WriteHeader(http.StatusBadRequest)
+		response := newErrorResponse(w, message)
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
@@ -577,16 +869,35 @@ func (h *GameStateHandler) handlePatch(w http.ResponseWriter, r *http.Request, g
 	if len(patchData.ContingencyPrompts) > 0 {
 		updatedGS.ContingencyPrompts = patchData.ContingencyPrompts
 	}
+	if patchData.Seed != 0 {
+		updatedGS.Seed = patchData.Seed
+	}
 	if patchData.IsEnded != existingGS.IsEnded {
 		updatedGS.IsEnded = patchData.IsEnded
 	}
+	if patchData.ModelName != "" && patchData.ModelName != existingGS.ModelName {
+		if err := h.checkModelSwap(r.Context(), existingGS, patchData.ModelName); err != nil {
+			h.logger.Warn("Rejected model switch", "error", err, "id", gameStateID.String(), "model", patchData.ModelName)
+			w.WriteHeader(http.StatusBadRequest)
+			response := newErrorResponse(w, err.Error())
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				h.logger.Error("Failed to encode error response", "error", err)
+			}
+			return
+		}
+		h.logger.Info("Switching game model", "id", gameStateID.String(), "from", existingGS.ModelName, "to", patchData.ModelName)
+		updatedGS.ModelName = patchData.ModelName
+	}
+
+	if strict && !h.validatePatch(w, r, &updatedGS) {
+		return
+	}
 
-	if err := h.storage.SaveGameState(r.Context(), gameStateID, &updatedGS); err != nil {
+	ctx := state.WithGameEvent(r.Context(), state.GameEvent{Kind: state.EventPatch})
+	if err := h.storage.SaveGameState(ctx, gameStateID, &updatedGS); err != nil {
 		h.logger.Error("Failed to save patched game state", "error", err, "id", gameStateID.String())
 		w.WriteHeader(http.StatusInternalServerError)
-		response := ErrorResponse{
-			Error: "Failed to save game state",
-		}
+		response := newErrorResponse(w, "Failed to save game state")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
@@ -600,13 +911,46 @@ func (h *GameStateHandler) handlePatch(w http.ResponseWriter, r *http.Request, g
 	}
 }
 
+// validatePatch validates a patched game state against its scenario, writing a
+// field-level error response and returning false if it fails
+func (h *GameStateHandler) validatePatch(w http.ResponseWriter, r *http.Request, gs *state.GameState) bool {
+	s, err := h.storage.GetScenario(r.Context(), gs.Scenario)
+	if err != nil || s == nil {
+		h.logger.Error("Failed to load scenario for patch validation", "error", err, "id", gs.ID.String(), "scenario", gs.Scenario)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load scenario")
+		return false
+	}
+	gs.MigrateIDs(s) // a patch may use display-cased or renamed keys
+
+	fieldErrors := gs.ValidateAgainstScenario(s)
+	if len(fieldErrors) == 0 {
+		return true
+	}
+
+	h.logger.Warn("Rejected invalid game state patch", "id", gs.ID.String(), "field_errors", len(fieldErrors))
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	response := ValidationErrorResponse{
+		Error:       "Game state failed validation",
+		FieldErrors: fieldErrors,
+		RequestID:   w.Header().Get(middleware.RequestIDHeader),
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode error response", "error", err)
+	}
+	return false
+}
+
 func (h *GameStateHandler) handleDelete(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	if h.profile != "" {
+		if gs, err := h.loadOwnedGameState(r.Context(), gameStateID); err == nil && gs == nil {
+			h.writeError(w, http.StatusNotFound, "Game state not found")
+			return
+		}
+	}
 	if err := h.storage.DeleteGameState(r.Context(), gameStateID); err != nil {
 		h.logger.Error("Failed to delete game state", "error", err, "id", gameStateID.String())
 		w.WriteHeader(http.StatusInternalServerError)
-		response := ErrorResponse{
-			Error: "Failed to delete game state",
-		}
+		response := newErrorResponse(w, "Failed to delete game state")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			h.logger.Error("Failed to encode error response", "error", err)
 		}
@@ -615,3 +959,42 @@ func (h *GameStateHandler) handleDelete(w http.ResponseWriter, r *http.Request,
 	h.logger.Debug("Game state deleted successfully", "id", gameStateID.String())
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// loadOwnedGameState loads a game state, returning nil if it doesn't exist
+// or belongs to a different profile than this handler's
+func (h *GameStateHandler) loadOwnedGameState(ctx context.Context, gameStateID uuid.UUID) (*state.GameState, error) {
+	gs, err := h.storage.LoadGameState(ctx, gameStateID)
+	if err != nil || gs == nil {
+		return gs, err
+	}
+	if gs.Profile != h.profile {
+		h.logger.Warn("Game state belongs to another profile", "id", gameStateID.String(), "profile", h.profile)
+		return nil, nil
+	}
+	return gs, nil
+}
+
+// loadGameState loads a game state for a sub-resource request, writing the
+// error response and returning false if it cannot be loaded
+func (h *GameStateHandler) loadGameState(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) (*state.GameState, bool) {
+	gs, err := h.loadOwnedGameState(r.Context(), gameStateID)
+	if err != nil {
+		h.logger.Error("Failed to load game state", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to load game state")
+		return nil, false
+	}
+	if gs == nil {
+		h.logger.Warn("Game state not found", "id", gameStateID.String())
+		h.writeError(w, http.StatusNotFound, "Game state not found")
+		return nil, false
+	}
+	return gs, true
+}
+
+// writeError writes an ErrorResponse with the given status code
+func (h *GameStateHandler) writeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(newErrorResponse(w, message)); err != nil {
+		h.logger.Error("Failed to encode error response", "error", err)
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/pkg/actor"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
 	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
@@ -70,6 +73,227 @@ func TestGameStateHandler_Create(t *testing.T) {
 	}
 }
 
+func TestGameStateHandler_CreateModelCompatibility(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	newStorage := func() *storage.MockStorage {
+		mockStorage := storage.NewMockStorage()
+		for _, s := range []*scenario.Scenario{
+			{Name: "Castle", FileName: "castle.json", Rating: scenario.RatingR, OpeningLocation: "start"},
+			{Name: "Harbor", FileName: "harbor.json", Rating: scenario.RatingPG13, OpeningLocation: "start"},
+			{Name: "Meadow", FileName: "meadow.json", Rating: scenario.RatingG, OpeningLocation: "start"},
+		} {
+			mockStorage.AddScenario(s.FileName, s)
+		}
+		return mockStorage
+	}
+
+	tests := []struct {
+		name           string
+		model          string
+		registry       *config.ModelRegistry
+		scenario       string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "uncensored model runs R-rated scenario",
+			model:          "llama-3.3-70b",
+			scenario:       "castle.json",
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "default registry rejects R-rated scenario for claude",
+			model:          "claude-sonnet-4",
+			scenario:       "castle.json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Model claude-sonnet-4 does not support scenarios rated R (allowed ratings: G, PG, PG-13). Compatible scenarios: Harbor, Meadow",
+		},
+		{
+			name:           "configured entry overrides defaults",
+			model:          "claude-sonnet-4",
+			registry:       config.NewModelRegistry([]config.ModelCapabilities{{Name: "claude-sonnet-4", Streaming: true}}),
+			scenario:       "castle.json",
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "configured ratings restrict a model",
+			model:          "kids-model",
+			registry:       config.NewModelRegistry([]config.ModelCapabilities{{Name: "kids*", Ratings: []string{"G"}}}),
+			scenario:       "harbor.json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Compatible scenarios: Meadow",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewGameStateHandler(logger, tt.model, newStorage())
+			if tt.registry != nil {
+				handler = handler.WithModelRegistry(tt.registry)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(`{"scenario":"`+tt.scenario+`"}`))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedError != "" {
+				var response ErrorResponse
+				if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if !strings.Contains(response.Error, tt.expectedError) {
+					t.Errorf("Expected error containing %q, got %q", tt.expectedError, response.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_ModelOverride(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	registry := config.NewModelRegistry([]config.ModelCapabilities{
+		{Name: "llama-3.3-70b", Streaming: true},
+		{Name: "claude-haiku-4-5", Ratings: []string{"G", "PG", "PG-13"}, Streaming: true},
+	})
+
+	newHandler := func() (*GameStateHandler, *storage.MockStorage) {
+		mockStorage := storage.NewMockStorage()
+		mockStorage.AddScenario("castle.json", &scenario.Scenario{Name: "Castle", Rating: scenario.RatingR, OpeningLocation: "start"})
+		mockStorage.AddScenario("harbor.json", &scenario.Scenario{Name: "Harbor", Rating: scenario.RatingPG13, OpeningLocation: "start"})
+		return NewGameStateHandler(logger, "default-model", mockStorage).WithModelRegistry(registry), mockStorage
+	}
+
+	createTests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedModel  string
+		expectedError  string
+	}{
+		{"no override uses server default", `{"scenario":"castle.json"}`, http.StatusCreated, "default-model", ""},
+		{"configured model is used", `{"scenario":"castle.json","model_name":"llama-3.3-70b"}`, http.StatusCreated, "llama-3.3-70b", ""},
+		{"unconfigured model is rejected", `{"scenario":"castle.json","model_name":"mystery"}`, http.StatusBadRequest, "", "Available models: default-model, llama-3.3-70b, claude-haiku-4-5"},
+		{"model must support rating", `{"scenario":"castle.json","model_name":"claude-haiku-4-5"}`, http.StatusBadRequest, "", "Compatible scenarios: Harbor"},
+	}
+	for _, tt := range createTests {
+		t.Run("create "+tt.name, func(t *testing.T) {
+			handler, _ := newHandler()
+			req := httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedError != "" {
+				if !strings.Contains(rr.Body.String(), tt.expectedError) {
+					t.Errorf("Expected error containing %q, got %s", tt.expectedError, rr.Body.String())
+				}
+				return
+			}
+			var response state.GameState
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.ModelName != tt.expectedModel {
+				t.Errorf("Expected model %q, got %q", tt.expectedModel, response.ModelName)
+			}
+		})
+	}
+
+	patchTests := []struct {
+		name           string
+		scenario       string
+		model          string
+		expectedStatus int
+		expectedModel  string
+	}{
+		{"switch to configured model", "harbor.json", "claude-haiku-4-5", http.StatusOK, "claude-haiku-4-5"},
+		{"switch to unconfigured model", "harbor.json", "mystery", http.StatusBadRequest, "default-model"},
+		{"switch to model without rating support", "castle.json", "claude-haiku-4-5", http.StatusBadRequest, "default-model"},
+	}
+	for _, tt := range patchTests {
+		t.Run("patch "+tt.name, func(t *testing.T) {
+			handler, mockStorage := newHandler()
+			gs := state.NewGameState(tt.scenario, nil, "default-model")
+			if err := mockStorage.SaveGameState(context.Background(), gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "/v1/gamestate/"+gs.ID.String(), strings.NewReader(`{"model_name":"`+tt.model+`"}`))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			saved, err := mockStorage.LoadGameState(context.Background(), gs.ID)
+			if err != nil {
+				t.Fatalf("Failed to load game state: %v", err)
+			}
+			if saved.ModelName != tt.expectedModel {
+				t.Errorf("Expected stored model %q, got %q", tt.expectedModel, saved.ModelName)
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_Profile(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	mockStorage := storage.NewMockStorage()
+	mockStorage.AddScenario("castle.json", &scenario.Scenario{Name: "Castle", Rating: scenario.RatingR, OpeningLocation: "start"})
+	mockStorage.AddScenario("meadow.json", &scenario.Scenario{Name: "Meadow", Rating: scenario.RatingG, OpeningLocation: "start"})
+
+	kids := NewGameStateHandler(logger, "llama-3.3-70b", mockStorage).
+		WithProfile(config.Profile{Name: "kids", MaxRating: scenario.RatingPG})
+	other := NewGameStateHandler(logger, "llama-3.3-70b", mockStorage).
+		WithProfile(config.Profile{Name: "other"})
+
+	// The profile's max rating applies even though the model allows R
+	rr := httptest.NewRecorder()
+	kids.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(`{"scenario":"castle.json"}`)))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "Compatible scenarios: Meadow") {
+		t.Errorf("Expected 400 listing compatible scenarios, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	kids.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(`{"scenario":"meadow.json"}`)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created state.GameState
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.Profile != "kids" {
+		t.Errorf("Expected game to be stamped with profile kids, got %q", created.Profile)
+	}
+
+	// Other profiles can't read or delete the game
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		rr = httptest.NewRecorder()
+		other.ServeHTTP(rr, httptest.NewRequest(method, "/v1/gamestate/"+created.ID.String(), nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("%s from another profile: expected 404, got %d", method, rr.Code)
+		}
+	}
+	rr = httptest.NewRecorder()
+	kids.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/gamestate/"+created.ID.String(), nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected owning profile to read the game, got %d", rr.Code)
+	}
+}
+
 func TestGameStateHandler_CreateWithOverrides(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelError,
@@ -190,6 +414,228 @@ func TestGameStateHandler_CreateWithOverrides(t *testing.T) {
 	}
 }
 
+func TestGameStateHandler_CreateWithOpeningSequence(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	tests := []struct {
+		name             string
+		sequence         *scenario.OpeningSequence
+		withLLM          bool
+		expectedMessages []string
+	}{
+		{
+			name:             "no sequence keeps single opening prompt",
+			sequence:         nil,
+			expectedMessages: []string{"Welcome to the test!"},
+		},
+		{
+			name: "scripted messages and tutorial",
+			sequence: &scenario.OpeningSequence{
+				Messages: []string{"The fog lifts.", "", "A bell tolls."},
+				Tutorial: "Type what you want to do.",
+			},
+			expectedMessages: []string{"Welcome to the test!", "The fog lifts.", "A bell tolls.", "Type what you want to do."},
+		},
+		{
+			name: "personalized intro uses LLM",
+			sequence: &scenario.OpeningSequence{
+				PersonalizedIntro: "Mention the hero's sword.",
+				Tutorial:          "Type what you want to do.",
+			},
+			withLLM:          true,
+			expectedMessages: []string{"Welcome to the test!", "Mock response", "Type what you want to do."},
+		},
+		{
+			name: "personalized intro skipped without LLM",
+			sequence: &scenario.OpeningSequence{
+				Messages:          []string{"The fog lifts."},
+				PersonalizedIntro: "Mention the hero's sword.",
+			},
+			expectedMessages: []string{"Welcome to the test!", "The fog lifts."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{
+				Name:            "Test Scenario",
+				Story:           "A test scenario",
+				OpeningPrompt:   "Welcome to the test!",
+				OpeningSequence: tt.sequence,
+				OpeningLocation: "start",
+				Locations: map[string]scenario.Location{
+					"start": {Name: "start", Description: "Starting location"},
+				},
+			})
+			mockStorage.AddPCSpec("classic", &actor.PCSpec{ID: "classic", Name: "Hero", HP: 10, MaxHP: 10, AC: 12})
+
+			mockLLM := services.NewMockLLMAPI()
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+			if tt.withLLM {
+				handler = handler.WithLLMService(mockLLM)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(`{"scenario":"foo_scenario.json"}`))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d. Response body: %s", rr.Code, rr.Body.String())
+			}
+
+			var response state.GameState
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if len(response.ChatHistory) != len(tt.expectedMessages) {
+				t.Fatalf("Expected %d opening messages, got %d: %+v", len(tt.expectedMessages), len(response.ChatHistory), response.ChatHistory)
+			}
+			for i, expected := range tt.expectedMessages {
+				if response.ChatHistory[i].Content != expected {
+					t.Errorf("Message %d: expected %q, got %q", i, expected, response.ChatHistory[i].Content)
+				}
+			}
+
+			_, calls := mockLLM.GetCalls()
+			if tt.withLLM && len(calls) != 1 {
+				t.Errorf("Expected 1 LLM call for personalized intro, got %d", len(calls))
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_CreateChoicesMode(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	tests := []struct {
+		name            string
+		scenarioChoices bool
+		requestBody     string
+		expected        bool
+	}{
+		{
+			name:        "defaults to off",
+			requestBody: `{"scenario":"foo_scenario.json"}`,
+			expected:    false,
+		},
+		{
+			name:            "inherits scenario default",
+			scenarioChoices: true,
+			requestBody:     `{"scenario":"foo_scenario.json"}`,
+			expected:        true,
+		},
+		{
+			name:            "request overrides scenario",
+			scenarioChoices: true,
+			requestBody:     `{"scenario":"foo_scenario.json","choices_mode":false}`,
+			expected:        false,
+		},
+		{
+			name:        "request enables choices",
+			requestBody: `{"scenario":"foo_scenario.json","choices_mode":true}`,
+			expected:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{
+				Name:            "Test Scenario",
+				ChoicesMode:     tt.scenarioChoices,
+				OpeningLocation: "start",
+				Locations: map[string]scenario.Location{
+					"start": {Name: "start", Description: "Starting location"},
+				},
+			})
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d. Response body: %s", rr.Code, rr.Body.String())
+			}
+			var response state.GameState
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.ChoicesMode != tt.expected {
+				t.Errorf("Expected choices_mode %v, got %v", tt.expected, response.ChoicesMode)
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_CreateSeed(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	tests := []struct {
+		name        string
+		requestBody string
+		expected    int64 // 0 means any non-zero generated seed
+	}{
+		{
+			name:        "generates a seed",
+			requestBody: `{"scenario":"foo_scenario.json"}`,
+		},
+		{
+			name:        "request sets the seed",
+			requestBody: `{"scenario":"foo_scenario.json","seed":12345}`,
+			expected:    12345,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{
+				Name:            "Test Scenario",
+				OpeningLocation: "start",
+				Locations: map[string]scenario.Location{
+					"start": {Name: "start", Description: "Starting location"},
+				},
+			})
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d. Response body: %s", rr.Code, rr.Body.String())
+			}
+			var response state.GameState
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if tt.expected == 0 && response.Seed == 0 {
+				t.Error("Expected a generated seed, got 0")
+			}
+			if tt.expected != 0 && response.Seed != tt.expected {
+				t.Errorf("Expected seed %d, got %d", tt.expected, response.Seed)
+			}
+
+			saved, err := mockStorage.LoadGameState(context.Background(), response.ID)
+			if err != nil || saved == nil {
+				t.Fatalf("Failed to load saved game state: %v", err)
+			}
+			if saved.Seed != response.Seed {
+				t.Errorf("Expected saved seed %d, got %d", response.Seed, saved.Seed)
+			}
+		})
+	}
+}
+
 func TestCreateGameStateRequest_Normalize(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -386,6 +832,105 @@ func TestGameStateHandler_Read(t *testing.T) {
 				if response.ID == uuid.Nil {
 					t.Error("Expected valid game state ID in response")
 				}
+				if response.Seed != testGS.Seed {
+					t.Errorf("Expected seed %d, got %d", testGS.Seed, response.Seed)
+				}
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_PatchStrict(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		expectedStatus int
+		expectedFields []string
+	}{
+		{
+			name:           "raw mode skips validation",
+			body:           `{"user_location": "moon"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "strict valid patch",
+			query:          "?strict=true",
+			body:           `{"user_location": "tavern", "vars": {"has_ship": "true"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "strict invalid patch",
+			query:          "?strict=true",
+			body:           `{"user_location": "moon", "vars": {"gold": "10"}, "user_inventory": ["cutlass"]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedFields: []string{"user_location", "vars.gold", "user_inventory[0]"},
+		},
+		{
+			name:           "strict rejects unknown fields",
+			query:          "?strict=true",
+			body:           `{"location": "tavern"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid strict value",
+			query:          "?strict=maybe",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddScenario("harbor.json", &scenario.Scenario{
+				Name:      "Harbor",
+				Inventory: []string{"rope"},
+				Locations: map[string]scenario.Location{
+					"dock":   {Name: "Dock"},
+					"tavern": {Name: "Tavern"},
+				},
+				Vars: map[string]string{"has_ship": "false"},
+			})
+			gs := state.NewGameState("harbor.json", nil, "foo_model")
+			gs.WorldLocations = map[string]scenario.Location{"dock": {Name: "Dock"}, "tavern": {Name: "Tavern"}}
+			gs.Location = "dock"
+			if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+			req := httptest.NewRequest(http.MethodPatch, "/v1/gamestate/"+gs.ID.String()+tt.query, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedFields == nil {
+				return
+			}
+
+			var response ValidationErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			var fields []string
+			for _, e := range response.FieldErrors {
+				fields = append(fields, e.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.expectedFields, ",") {
+				t.Errorf("Expected field errors %v, got %+v", tt.expectedFields, response.FieldErrors)
+			}
+
+			saved, _ := mockStorage.LoadGameState(ctx, gs.ID)
+			if saved.Location != "dock" {
+				t.Errorf("Expected invalid patch not to be saved, got location %q", saved.Location)
 			}
 		})
 	}
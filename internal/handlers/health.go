@@ -12,19 +12,34 @@ import (
 )
 
 type HealthResponse struct {
-	Status     string                 `json:"status"`
-	Timestamp  time.Time              `json:"timestamp"`
-	Service    string                 `json:"service"`
-	Components map[string]interface{} `json:"components"`
+	Status       string                 `json:"status"`
+	Timestamp    time.Time              `json:"timestamp"`
+	Service      string                 `json:"service"`
+	Components   map[string]interface{} `json:"components"`
+	Capabilities *Capabilities          `json:"capabilities,omitempty"`
+}
+
+// Capabilities tells clients which work the server does for them, so they can skip
+// doing it themselves
+type Capabilities struct {
+	TextFilter *TextFilterCapability `json:"text_filter,omitempty"`
+}
+
+// TextFilterCapability describes the server's profanity filtering of narration
+type TextFilterCapability struct {
+	Narration bool     `json:"narration"` // narration is filtered for the scenario's rating before it is sent or saved
+	Leetspeak bool     `json:"leetspeak"` // words spelled with digits and symbols are caught
+	Locales   []string `json:"locales"`   // locales with word lists; English applies in all of them
 }
 
 type HealthHandler struct {
-	storage    storage.Storage
-	llmService services.LLMService
-	logger     *slog.Logger
+	storage      storage.Health
+	llmService   services.LLMService
+	logger       *slog.Logger
+	capabilities *Capabilities
 }
 
-func NewHealthHandler(logger *slog.Logger, storage storage.Storage, llmService services.LLMService) *HealthHandler {
+func NewHealthHandler(logger *slog.Logger, storage storage.Health, llmService services.LLMService) *HealthHandler {
 	return &HealthHandler{
 		logger:     logger,
 		storage:    storage,
@@ -32,6 +47,12 @@ func NewHealthHandler(logger *slog.Logger, storage storage.Storage, llmService s
 	}
 }
 
+// WithCapabilities reports the server's capabilities with every health check
+func (h *HealthHandler) WithCapabilities(capabilities Capabilities) *HealthHandler {
+	h.capabilities = &capabilities
+	return h
+}
+
 func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -52,10 +73,11 @@ func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := HealthResponse{
-		Status:     overallStatus,
-		Timestamp:  time.Now(),
-		Service:    "story-engine",
-		Components: components,
+		Status:       overallStatus,
+		Timestamp:    time.Now(),
+		Service:      "story-engine",
+		Components:   components,
+		Capabilities: h.capabilities,
 	}
 
 	statusCode := http.StatusOK
@@ -154,3 +154,48 @@ func TestHealthHandler_ResponseFormat(t *testing.T) {
 		t.Error("Storage component missing")
 	}
 }
+
+func TestHealthHandler_Capabilities(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	mockStorage := storage.NewMockStorage()
+	mockStorage.SetPingSuccess()
+
+	tests := []struct {
+		name         string
+		capabilities *Capabilities
+	}{
+		{name: "not reported"},
+		{name: "text filter", capabilities: &Capabilities{TextFilter: &TextFilterCapability{Narration: true, Leetspeak: true, Locales: []string{"en", "es"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthHandler(logger, mockStorage, services.NewMockLLMAPI())
+			if tt.capabilities != nil {
+				handler.WithCapabilities(*tt.capabilities)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			var response HealthResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if tt.capabilities == nil {
+				if response.Capabilities != nil {
+					t.Errorf("Expected no capabilities, got %+v", response.Capabilities)
+				}
+				return
+			}
+			if response.Capabilities == nil || response.Capabilities.TextFilter == nil {
+				t.Fatalf("Expected text filter capability, got %+v", response.Capabilities)
+			}
+			if got := response.Capabilities.TextFilter; !got.Narration || !got.Leetspeak || len(got.Locales) != 2 {
+				t.Errorf("Expected %+v, got %+v", tt.capabilities.TextFilter, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// HintsResponse lists the hints given in the current scene and how many remain.
+// Players ask for the next hint by sending "/hint" as a chat message.
+type HintsResponse struct {
+	GameStateID uuid.UUID `json:"gamestate_id"`
+	Scene       string    `json:"scene,omitempty"`
+	Hints       []string  `json:"hints"`     // Hints given in this scene, oldest first
+	Budget      int       `json:"budget"`    // Hints the player may ask for in this scene
+	Remaining   int       `json:"remaining"` // Hints left to ask for
+}
+
+// handleHints returns the hints given to the player in the current scene
+func (h *GameStateHandler) handleHints(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+	s, err := h.storage.GetScenario(r.Context(), gs.Scenario)
+	if err != nil {
+		h.logger.Error("Failed to load scenario for hints", "error", err, "scenario", gs.Scenario)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load scenario")
+		return
+	}
+	gs.MigrateIDs(s) // match the scenario's keys, as the next turn will
+
+	_, budget := s.SceneHints(gs.SceneName)
+	hints := gs.HintsGiven()
+	if hints == nil {
+		hints = []string{}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(HintsResponse{
+		GameStateID: gs.ID,
+		Scene:       gs.SceneName,
+		Hints:       hints,
+		Budget:      budget,
+		Remaining:   max(budget-len(hints), 0),
+	}); err != nil {
+		h.logger.Error("Failed to encode hints response", "error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_Hints(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	mockStorage := storage.NewMockStorage()
+	mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{
+		Name: "Foo",
+		Scenes: map[string]scenario.Scene{
+			"docks": {Story: "Find a ship.", HintBudget: 4},
+		},
+	})
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	gs.SceneName = "docks"
+	gs.RecordHint("The harbor master knows every captain.")
+	if err := mockStorage.SaveGameState(context.Background(), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+
+	handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"list hints", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/hints", http.StatusOK},
+		{"sub path", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/hints/1", http.StatusMethodNotAllowed},
+		{"wrong method", http.MethodPost, "/v1/gamestate/" + gs.ID.String() + "/hints", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("list body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/gamestate/"+gs.ID.String()+"/hints", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var response HintsResponse
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		want := HintsResponse{
+			GameStateID: gs.ID,
+			Scene:       "docks",
+			Hints:       []string{"The harbor master knows every captain."},
+			Budget:      4,
+			Remaining:   3,
+		}
+		if !reflect.DeepEqual(response, want) {
+			t.Errorf("Response = %+v, want %+v", response, want)
+		}
+	})
+}
@@ -11,10 +11,10 @@ import (
 
 type MonsterHandler struct {
 	logger  *slog.Logger
-	storage storage.Storage
+	storage storage.ContentStore
 }
 
-func NewMonsterHandler(logger *slog.Logger, storage storage.Storage) *MonsterHandler {
+func NewMonsterHandler(logger *slog.Logger, storage storage.ContentStore) *MonsterHandler {
 	return &MonsterHandler{
 		logger:  logger,
 		storage: storage,
@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
 )
 
@@ -13,11 +17,23 @@ import (
 const NarratorDataDir = "data/narrators"
 
 type NarratorHandler struct {
-	log     *slog.Logger
-	storage storage.Storage
+	log      *slog.Logger
+	storage  storage.ContentStore
+	games    []storage.GameStateStore // game storages checked for references before a delete; defaults to storage
+	adminKey string                   // optional; enables create, update, and delete
 }
 
-// ListNarrators lists all available narrator files
+// NarratorSummary is the listing entry for a narrator
+type NarratorSummary struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tone        string   `json:"tone,omitempty"`
+	Ratings     []string `json:"ratings,omitempty"`
+}
+
+// ListNarrators lists all available narrator files, optionally only those
+// suited to a scenario rating (?rating=PG)
 func (h *NarratorHandler) ListNarrators(w http.ResponseWriter, r *http.Request) {
 	narratorIDs, err := h.storage.ListNarrators(r.Context())
 	if err != nil {
@@ -25,9 +41,11 @@ func (h *NarratorHandler) ListNarrators(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Failed to list narrators", http.StatusInternalServerError)
 		return
 	}
+	slices.Sort(narratorIDs)
+	rating := r.URL.Query().Get("rating")
 
 	// Initialize as empty slice instead of nil
-	narratorList := make([]map[string]interface{}, 0)
+	narratorList := make([]NarratorSummary, 0)
 	for _, narratorID := range narratorIDs {
 		// Load each narrator to get details
 		narrator, err := h.storage.GetNarrator(r.Context(), narratorID)
@@ -35,14 +53,17 @@ func (h *NarratorHandler) ListNarrators(w http.ResponseWriter, r *http.Request)
 			h.log.Warn("Failed to load narrator", "error", err, "id", narratorID)
 			continue
 		}
-
-		// Create a summary object with just the key fields
-		narratorSummary := map[string]interface{}{
-			"id":          narrator.ID,
-			"name":        narrator.Name,
-			"description": narrator.Description,
+		if rating != "" && !narrator.SuitsRating(rating) {
+			continue
 		}
-		narratorList = append(narratorList, narratorSummary)
+
+		narratorList = append(narratorList, NarratorSummary{
+			ID:          narrator.ID,
+			Name:        narrator.Name,
+			Description: narrator.Description,
+			Tone:        narrator.Tone,
+			Ratings:     narrator.Ratings,
+		})
 	}
 
 	data, err := json.Marshal(narratorList)
@@ -59,38 +80,69 @@ func (h *NarratorHandler) ListNarrators(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-func NewNarratorHandler(log *slog.Logger, storage storage.Storage) *NarratorHandler {
+func NewNarratorHandler(log *slog.Logger, storage storage.ContentStore) *NarratorHandler {
 	return &NarratorHandler{
 		log:     log,
 		storage: storage,
 	}
 }
 
+// WithAdminKey enables creating, updating, and deleting narrators, authenticated
+// by the X-Admin-Key header. Without it narrators are read-only.
+func (h *NarratorHandler) WithAdminKey(adminKey string) *NarratorHandler {
+	h.adminKey = adminKey
+	return h
+}
+
+// WithGameStorages sets the game storages searched for games still using a
+// narrator before it is deleted, e.g. one per profile
+func (h *NarratorHandler) WithGameStorages(games ...storage.GameStateStore) *NarratorHandler {
+	h.games = games
+	return h
+}
+
+// ServeHTTP handles HTTP requests for narrators
+// Routes:
+// GET /v1/narrators         - List narrators, optionally filtered by ?rating=
+// GET /v1/narrators/{id}    - Read a narrator
+// POST /v1/narrators        - Create a narrator (admin only)
+// PUT /v1/narrators/{id}    - Replace a narrator (admin only)
+// DELETE /v1/narrators/{id} - Delete a narrator no scenario or active game uses (admin only)
 func (h *NarratorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		if r.URL.Path == "/v1/narrators" || r.URL.Path == "/v1/narrators/" {
-			h.ListNarrators(w, r)
-		} else {
-			h.handleGet(w, r)
+	id, err := resourceID(r.URL.Path, "/v1/narrators")
+	if err != nil {
+		http.Error(w, "Invalid narrator ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		h.ListNarrators(w, r)
+	case r.Method == http.MethodGet:
+		h.handleGet(w, r, id)
+	case r.Method == http.MethodPost && id == "",
+		r.Method == http.MethodPut && id != "",
+		r.Method == http.MethodDelete && id != "":
+		if !hasAdminKey(r, h.adminKey) {
+			h.log.Warn("Narrator change refused: missing or invalid admin key", "method", r.Method)
+			http.Error(w, "A valid admin key is required", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			h.handleCreate(w, r)
+		case http.MethodPut:
+			h.handleUpdate(w, r, id)
+		default:
+			h.handleDelete(w, r, id)
 		}
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-func (h *NarratorHandler) handleGet(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/v1/narrators/")
-	id := strings.TrimSpace(path)
-
-	if id == "" || id == "/" {
-		http.Error(w, "Narrator ID is required in URL path (e.g., /v1/narrators/vincent_price)", http.StatusBadRequest)
-		return
-	}
-
-	// Security: prevent directory traversal
-	if strings.Contains(id, "..") || strings.Contains(id, "/") {
-		http.Error(w, "Invalid narrator ID", http.StatusBadRequest)
+func (h *NarratorHandler) handleGet(w http.ResponseWriter, r *http.Request, id string) {
+	if !h.exists(w, r, id) {
 		return
 	}
 
@@ -107,17 +159,155 @@ func (h *NarratorHandler) handleGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Marshal the narrator
+	h.writeNarrator(w, http.StatusOK, narrator)
+}
+
+func (h *NarratorHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	narrator, ok := h.decodeNarrator(w, r)
+	if !ok {
+		return
+	}
+	narrator.ID = normalizeID(narrator.ID)
+	if narrator.ID == "" {
+		http.Error(w, "Narrator id is required", http.StatusBadRequest)
+		return
+	}
+
+	ids, err := h.storage.ListNarrators(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list narrators", "error", err)
+		http.Error(w, "Failed to list narrators", http.StatusInternalServerError)
+		return
+	}
+	if slices.Contains(ids, narrator.ID) {
+		http.Error(w, fmt.Sprintf("Narrator %s already exists", narrator.ID), http.StatusConflict)
+		return
+	}
+
+	if err := h.storage.SaveNarrator(r.Context(), narrator); err != nil {
+		h.log.Error("Failed to save narrator", "error", err, "id", narrator.ID)
+		writeContentError(w, err, "Failed to save narrator")
+		return
+	}
+	h.log.Info("Narrator created", "id", narrator.ID)
+	h.writeNarrator(w, http.StatusCreated, narrator)
+}
+
+func (h *NarratorHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id string) {
+	narrator, ok := h.decodeNarrator(w, r)
+	if !ok {
+		return
+	}
+	if narrator.ID != "" && normalizeID(narrator.ID) != id {
+		http.Error(w, "Narrator id in body does not match the URL", http.StatusBadRequest)
+		return
+	}
+	narrator.ID = id
+	if !h.exists(w, r, id) {
+		return
+	}
+
+	if err := h.storage.SaveNarrator(r.Context(), narrator); err != nil {
+		h.log.Error("Failed to save narrator", "error", err, "id", id)
+		writeContentError(w, err, "Failed to save narrator")
+		return
+	}
+	h.log.Info("Narrator updated", "id", id)
+	h.writeNarrator(w, http.StatusOK, narrator)
+}
+
+func (h *NarratorHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if !h.exists(w, r, id) {
+		return
+	}
+
+	refs, err := findReferences(r.Context(), h.storage, h.gameStorages(),
+		func(s *scenario.Scenario) bool { return s.NarratorID == id },
+		func(gs *state.GameState) bool { return gs.Narrator != nil && gs.Narrator.ID == id },
+	)
+	if err != nil {
+		h.log.Error("Failed to check narrator references", "error", err, "id", id)
+		http.Error(w, "Failed to check narrator references", http.StatusInternalServerError)
+		return
+	}
+	if refs.inUse() {
+		http.Error(w, fmt.Sprintf("Narrator %s is still used by %s", id, refs), http.StatusConflict)
+		return
+	}
+
+	if err := h.storage.DeleteNarrator(r.Context(), id); err != nil {
+		h.log.Error("Failed to delete narrator", "error", err, "id", id)
+		writeContentError(w, err, "Failed to delete narrator")
+		return
+	}
+	h.log.Info("Narrator deleted", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeNarrator reads and validates a narrator from the request body,
+// writing the error response and returning false if it is invalid
+func (h *NarratorHandler) decodeNarrator(w http.ResponseWriter, r *http.Request) (*scenario.Narrator, bool) {
+	var narrator scenario.Narrator
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&narrator); err != nil {
+		http.Error(w, "Invalid narrator JSON: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	var problems []string
+	if strings.TrimSpace(narrator.Name) == "" {
+		problems = append(problems, "name is required")
+	}
+	for _, rating := range narrator.Ratings {
+		if !validRating(rating) {
+			problems = append(problems, fmt.Sprintf("invalid rating %q", rating))
+		}
+	}
+	if len(problems) > 0 {
+		http.Error(w, "Invalid narrator: "+strings.Join(problems, "; "), http.StatusBadRequest)
+		return nil, false
+	}
+	return &narrator, true
+}
+
+// exists reports whether a narrator file exists, writing a 404 or 500 response if not
+func (h *NarratorHandler) exists(w http.ResponseWriter, r *http.Request, id string) bool {
+	ids, err := h.storage.ListNarrators(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list narrators", "error", err)
+		http.Error(w, "Failed to list narrators", http.StatusInternalServerError)
+		return false
+	}
+	if !slices.Contains(ids, id) {
+		http.Error(w, "Narrator not found", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+func (h *NarratorHandler) writeNarrator(w http.ResponseWriter, status int, narrator *scenario.Narrator) {
 	data, err := json.Marshal(narrator)
 	if err != nil {
-		h.log.Error("Failed to marshal narrator", "error", err, "id", id)
+		h.log.Error("Failed to marshal narrator", "error", err, "id", narrator.ID)
 		http.Error(w, "Failed to process narrator", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	if _, err := w.Write(data); err != nil {
-		h.log.Error("Failed to write response", "error", err, "id", id)
+		h.log.Error("Failed to write response", "error", err, "id", narrator.ID)
+	}
+}
+
+// gameStorages returns the game storages to check for references, defaulting to
+// the content storage when it also holds game states
+func (h *NarratorHandler) gameStorages() []storage.GameStateStore {
+	if len(h.games) == 0 {
+		if games, ok := h.storage.(storage.GameStateStore); ok {
+			return []storage.GameStateStore{games}
+		}
 	}
+	return h.games
 }
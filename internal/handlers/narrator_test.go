@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
 )
 
@@ -94,3 +99,144 @@ func TestNarratorHandler_GetNarrator(t *testing.T) {
 		t.Fatalf("expected narrator ID 'classic', got %s", narrator.ID)
 	}
 }
+
+func TestNarratorHandler_ListNarrators_Rating(t *testing.T) {
+	mockStorage := storage.NewMockStorage()
+	mockStorage.AddNarrator("classic", &scenario.Narrator{ID: "classic", Name: "Classic"})
+	mockStorage.AddNarrator("noir", &scenario.Narrator{ID: "noir", Name: "Noir", Tone: "hard-boiled", Ratings: []string{scenario.RatingPG13, scenario.RatingR}})
+	mockStorage.AddNarrator("comedic", &scenario.Narrator{ID: "comedic", Name: "Comedic", Ratings: []string{scenario.RatingG, scenario.RatingPG}})
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := NewNarratorHandler(log, mockStorage)
+
+	tests := []struct {
+		query   string
+		wantIDs []string
+	}{
+		{"", []string{"classic", "comedic", "noir"}},
+		{"?rating=R", []string{"classic", "noir"}},
+		{"?rating=G", []string{"classic", "comedic"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/narrators"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			var narrators []NarratorSummary
+			if err := json.Unmarshal(w.Body.Bytes(), &narrators); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			var ids []string
+			for _, n := range narrators {
+				ids = append(ids, n.ID)
+			}
+			if !slices.Equal(ids, tc.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tc.wantIDs)
+			}
+		})
+	}
+}
+
+func TestNarratorHandler_CRUD(t *testing.T) {
+	const adminKey = "secret"
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		key        string
+		wantStatus int
+	}{
+		{"create without admin key", http.MethodPost, "/v1/narrators", `{"id":"gothic","name":"Gothic"}`, "", http.StatusUnauthorized},
+		{"create", http.MethodPost, "/v1/narrators", `{"id":"gothic","name":"Gothic","tone":"brooding","ratings":["PG-13","R"]}`, adminKey, http.StatusCreated},
+		{"create existing", http.MethodPost, "/v1/narrators", `{"id":"classic","name":"Classic"}`, adminKey, http.StatusConflict},
+		{"create missing id", http.MethodPost, "/v1/narrators", `{"name":"Gothic"}`, adminKey, http.StatusBadRequest},
+		{"create missing name", http.MethodPost, "/v1/narrators", `{"id":"gothic"}`, adminKey, http.StatusBadRequest},
+		{"create invalid rating", http.MethodPost, "/v1/narrators", `{"id":"gothic","name":"Gothic","ratings":["NC-17"]}`, adminKey, http.StatusBadRequest},
+		{"update", http.MethodPut, "/v1/narrators/classic", `{"name":"Classic","tone":"warm"}`, adminKey, http.StatusOK},
+		{"update missing", http.MethodPut, "/v1/narrators/gothic", `{"name":"Gothic"}`, adminKey, http.StatusNotFound},
+		{"delete", http.MethodDelete, "/v1/narrators/classic", "", adminKey, http.StatusNoContent},
+		{"delete missing", http.MethodDelete, "/v1/narrators/gothic", "", adminKey, http.StatusNotFound},
+		{"delete collection", http.MethodDelete, "/v1/narrators", "", adminKey, http.StatusMethodNotAllowed},
+		{"get missing", http.MethodGet, "/v1/narrators/gothic", "", "", http.StatusNotFound},
+		{"traversal", http.MethodGet, "/v1/narrators/../secrets", "", "", http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddNarrator("classic", &scenario.Narrator{ID: "classic", Name: "Classic"})
+			handler := NewNarratorHandler(log, mockStorage).WithAdminKey(adminKey)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.key != "" {
+				req.Header.Set("X-Admin-Key", tc.key)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus == http.StatusCreated {
+				n, err := mockStorage.GetNarrator(req.Context(), "gothic")
+				if err != nil || n.Tone != "brooding" {
+					t.Errorf("created narrator = %+v, %v", n, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNarratorHandler_DeleteReferenced(t *testing.T) {
+	const adminKey = "secret"
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	narrator := &scenario.Narrator{ID: "noir", Name: "Noir"}
+
+	tests := []struct {
+		name       string
+		scenario   *scenario.Scenario
+		game       *state.GameState
+		wantStatus int
+	}{
+		{"narrator of a scenario", &scenario.Scenario{Name: "Detective", NarratorID: "noir"}, nil, http.StatusConflict},
+		{"narrator of an active game", nil, &state.GameState{ID: uuid.New(), Narrator: narrator}, http.StatusConflict},
+		{"narrator of an ended game", nil, &state.GameState{ID: uuid.New(), Narrator: narrator, IsEnded: true}, http.StatusNoContent},
+		{"unreferenced", &scenario.Scenario{Name: "Detective", NarratorID: "classic"}, nil, http.StatusNoContent},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddNarrator("noir", narrator)
+			if tc.scenario != nil {
+				mockStorage.AddScenario("detective.json", tc.scenario)
+			}
+			if tc.game != nil {
+				if err := mockStorage.SaveGameState(context.Background(), tc.game.ID, tc.game); err != nil {
+					t.Fatalf("SaveGameState: %v", err)
+				}
+			}
+			handler := NewNarratorHandler(log, mockStorage).WithAdminKey(adminKey)
+
+			req := httptest.NewRequest(http.MethodDelete, "/v1/narrators/noir", nil)
+			req.Header.Set("X-Admin-Key", adminKey)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, tc.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
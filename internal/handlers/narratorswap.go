@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// NarratorSwapRequest is the body for switching a game's narrator
+type NarratorSwapRequest struct {
+	NarratorID string `json:"narrator_id"`
+}
+
+// handleNarratorSwap replaces the narrator embedded in a game, e.g. to switch from a
+// grim voice to a comedic one. The new narrator tells the story from the next turn on;
+// earlier narration is unchanged.
+func (h *GameStateHandler) handleNarratorSwap(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	var req NarratorSwapRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.logger.Warn("Invalid JSON in narrator request body", "error", err)
+		h.writeError(w, http.StatusBadRequest, "Invalid JSON in request body")
+		return
+	}
+	narratorID := stripJSONExtension(normalizeID(req.NarratorID))
+	if narratorID == "" {
+		h.writeError(w, http.StatusBadRequest, "narrator_id is required")
+		return
+	}
+
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+	if gs.IsEnded {
+		h.writeError(w, http.StatusConflict, "Game has ended")
+		return
+	}
+
+	narrator, err := h.storage.GetNarrator(r.Context(), narratorID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.writeError(w, http.StatusNotFound, "Narrator not found: "+narratorID)
+			return
+		}
+		h.logger.Error("Failed to load narrator", "error", err, "narrator_id", narratorID)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load narrator")
+		return
+	}
+	s, err := h.storage.GetScenario(r.Context(), gs.Scenario)
+	if err != nil {
+		h.logger.Error("Failed to load scenario for narrator switch", "error", err, "scenario", gs.Scenario)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load scenario")
+		return
+	}
+	if !narrator.SuitsRating(s.Rating) {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("Narrator %s doesn't suit this scenario's %s rating", narratorID, s.Rating))
+		return
+	}
+
+	previous := ""
+	if gs.Narrator != nil {
+		previous = gs.Narrator.ID
+	}
+	gs.Narrator = narrator
+	ctx := state.WithGameEvent(r.Context(), state.GameEvent{Kind: state.EventNarrator})
+	if err := h.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		h.logger.Error("Failed to save game state with new narrator", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to save game state")
+		return
+	}
+
+	h.logger.Info("Game narrator switched", "id", gameStateID.String(), "from", previous, "to", narratorID)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(gs); err != nil {
+		h.logger.Error("Failed to encode game state response", "error", err)
+	}
+}
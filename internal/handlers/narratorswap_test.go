@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_NarratorSwap(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	grim := &scenario.Narrator{ID: "grim", Name: "Grim"}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		ended          bool
+		expectedStatus int
+		expectNarrator string
+	}{
+		{
+			name:           "switch",
+			method:         http.MethodPost,
+			body:           `{"narrator_id":"comedic"}`,
+			expectedStatus: http.StatusOK,
+			expectNarrator: "comedic",
+		},
+		{
+			name:           "switch by filename",
+			method:         http.MethodPost,
+			body:           `{"narrator_id":"comedic.json"}`,
+			expectedStatus: http.StatusOK,
+			expectNarrator: "comedic",
+		},
+		{
+			name:           "missing narrator_id",
+			method:         http.MethodPost,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectNarrator: "grim",
+		},
+		{
+			name:           "unknown field",
+			method:         http.MethodPost,
+			body:           `{"narrator":"comedic"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectNarrator: "grim",
+		},
+		{
+			name:           "unknown narrator",
+			method:         http.MethodPost,
+			body:           `{"narrator_id":"gothic"}`,
+			expectedStatus: http.StatusNotFound,
+			expectNarrator: "grim",
+		},
+		{
+			name:           "narrator unsuited to the rating",
+			method:         http.MethodPost,
+			body:           `{"narrator_id":"crude"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectNarrator: "grim",
+		},
+		{
+			name:           "ended game",
+			method:         http.MethodPost,
+			body:           `{"narrator_id":"comedic"}`,
+			ended:          true,
+			expectedStatus: http.StatusConflict,
+			expectNarrator: "grim",
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectNarrator: "grim",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{Name: "Foo", Rating: scenario.RatingPG})
+			mockStorage.AddNarrator("grim", grim)
+			mockStorage.AddNarrator("comedic", &scenario.Narrator{ID: "comedic", Name: "Comedic"})
+			mockStorage.AddNarrator("crude", &scenario.Narrator{ID: "crude", Name: "Crude", Ratings: []string{scenario.RatingR}})
+			gs := state.NewGameState("foo_scenario.json", grim, "foo_model")
+			gs.IsEnded = tt.ended
+			if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+			req := httptest.NewRequest(tt.method, "/v1/gamestate/"+gs.ID.String()+"/narrator", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			saved, err := mockStorage.LoadGameState(ctx, gs.ID)
+			if err != nil || saved == nil {
+				t.Fatalf("Failed to load game state: %v", err)
+			}
+			if saved.Narrator == nil || saved.Narrator.ID != tt.expectNarrator {
+				t.Errorf("Expected narrator %q, got %+v", tt.expectNarrator, saved.Narrator)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/jwebster45206/story-engine/pkg/jsonpatch"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// maxPatchBodyBytes bounds JSON Patch and merge patch request bodies
+const maxPatchBodyBytes = 1 << 20
+
+// handleDocumentPatch applies a JSON Patch (RFC 6902) or JSON Merge Patch (RFC 7396)
+// document to the game state's JSON. Unlike the default PATCH, these can clear fields
+// and set booleans and counters to their zero values. Fields that identify the game
+// can't be changed, and the patched document may not contain unknown fields.
+func (h *GameStateHandler) handleDocumentPatch(w http.ResponseWriter, r *http.Request, existingGS *state.GameState, mediaType string, strict bool) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPatchBodyBytes))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	doc, err := json.Marshal(existingGS)
+	if err != nil {
+		h.logger.Error("Failed to marshal game state for patch", "error", err, "id", existingGS.ID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to patch game state")
+		return
+	}
+
+	var patched []byte
+	if mediaType == jsonpatch.MediaTypeJSONPatch {
+		patched, err = jsonpatch.Apply(doc, body)
+	} else {
+		patched, err = jsonpatch.MergePatch(doc, body)
+	}
+	if err != nil {
+		h.logger.Warn("Failed to apply patch document", "error", err, "id", existingGS.ID.String(), "content_type", mediaType)
+		status := http.StatusBadRequest
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			status = http.StatusConflict
+		}
+		h.writeError(w, status, "Failed to apply patch: "+err.Error())
+		return
+	}
+
+	var updatedGS state.GameState
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&updatedGS); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Patched game state is invalid: "+err.Error())
+		return
+	}
+
+	if field := changedIdentityField(existingGS, &updatedGS); field != "" {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("%s cannot be changed", field))
+		return
+	}
+	if updatedGS.ModelName != existingGS.ModelName {
+		if err := h.checkModelSwap(r.Context(), existingGS, updatedGS.ModelName); err != nil {
+			h.logger.Warn("Rejected model switch", "error", err, "id", existingGS.ID.String(), "model", updatedGS.ModelName)
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Info("Switching game model", "id", existingGS.ID.String(), "from", existingGS.ModelName, "to", updatedGS.ModelName)
+	}
+
+	if strict && !h.validatePatch(w, r, &updatedGS) {
+		return
+	}
+
+	ctx := state.WithGameEvent(r.Context(), state.GameEvent{Kind: state.EventPatch})
+	if err := h.storage.SaveGameState(ctx, updatedGS.ID, &updatedGS); err != nil {
+		h.logger.Error("Failed to save patched game state", "error", err, "id", updatedGS.ID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to save game state")
+		return
+	}
+
+	h.logger.Info("Game state patched successfully", "id", updatedGS.ID.String(), "content_type", mediaType)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(updatedGS); err != nil {
+		h.logger.Error("Failed to encode patched game state response", "error", err)
+	}
+}
+
+// changedIdentityField returns the JSON name of the first field identifying the
+// game, its scenario, or its owner, or limiting a preview game, that differs between
+// a and b, or ""
+func changedIdentityField(a, b *state.GameState) string {
+	switch {
+	case a.ID != b.ID:
+		return "id"
+	case a.Scenario != b.Scenario:
+		return "scenario"
+	case a.Profile != b.Profile:
+		return "profile"
+	case a.APIKeyID != b.APIKeyID:
+		return "api_key_id"
+	case !a.CreatedAt.Equal(b.CreatedAt):
+		return "created_at"
+	case (a.Trial == nil) != (b.Trial == nil) || (a.Trial != nil && *a.Trial != *b.Trial):
+		return "trial"
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/jsonpatch"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_DocumentPatch(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		contentType    string
+		query          string
+		body           string
+		expectedStatus int
+		check          func(t *testing.T, gs *state.GameState)
+	}{
+		{
+			name:           "json patch sets is_ended false and clears inventory",
+			contentType:    jsonpatch.MediaTypeJSONPatch,
+			body:           `[{"op": "replace", "path": "/is_ended", "value": false}, {"op": "remove", "path": "/user_inventory"}]`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, gs *state.GameState) {
+				if gs.IsEnded || len(gs.Inventory) != 0 {
+					t.Errorf("Expected game not ended with empty inventory, got %v, %v", gs.IsEnded, gs.Inventory)
+				}
+			},
+		},
+		{
+			name:           "json patch edits nested values",
+			contentType:    jsonpatch.MediaTypeJSONPatch + "; charset=utf-8",
+			body:           `[{"op": "add", "path": "/user_inventory/-", "value": "rope"}, {"op": "replace", "path": "/npcs/gibbs/location", "value": "tavern"}, {"op": "replace", "path": "/turn_counter", "value": 0}]`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, gs *state.GameState) {
+				if !slices.Equal(gs.Inventory, []string{"lamp", "rope"}) {
+					t.Errorf("Expected inventory [lamp rope], got %v", gs.Inventory)
+				}
+				if gs.NPCs["gibbs"].Location != "tavern" {
+					t.Errorf("Expected gibbs in tavern, got %q", gs.NPCs["gibbs"].Location)
+				}
+				if gs.TurnCounter != 0 {
+					t.Errorf("Expected turn counter 0, got %d", gs.TurnCounter)
+				}
+			},
+		},
+		{
+			name:           "merge patch removes vars with null",
+			contentType:    jsonpatch.MediaTypeMergePatch,
+			body:           `{"vars": {"door_open": null, "has_ship": "true"}, "is_ended": false}`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, gs *state.GameState) {
+				if _, ok := gs.Vars["door_open"]; ok || gs.Vars["has_ship"] != "true" || gs.IsEnded {
+					t.Errorf("Unexpected state after merge patch: vars %v, ended %v", gs.Vars, gs.IsEnded)
+				}
+			},
+		},
+		{
+			name:           "failed test op",
+			contentType:    jsonpatch.MediaTypeJSONPatch,
+			body:           `[{"op": "test", "path": "/turn_counter", "value": 99}, {"op": "replace", "path": "/is_ended", "value": false}]`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "missing path",
+			contentType:    jsonpatch.MediaTypeJSONPatch,
+			body:           `[{"op": "replace", "path": "/nope", "value": 1}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown field",
+			contentType:    jsonpatch.MediaTypeMergePatch,
+			body:           `{"location": "tavern"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong type",
+			contentType:    jsonpatch.MediaTypeMergePatch,
+			body:           `{"turn_counter": "five"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "scenario is immutable",
+			contentType:    jsonpatch.MediaTypeMergePatch,
+			body:           `{"scenario": "other.json"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "id is immutable",
+			contentType:    jsonpatch.MediaTypeJSONPatch,
+			body:           `[{"op": "remove", "path": "/id"}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "strict mode validates the result",
+			contentType:    jsonpatch.MediaTypeJSONPatch,
+			query:          "?strict=true",
+			body:           `[{"op": "replace", "path": "/user_location", "value": "moon"}]`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddScenario("harbor.json", &scenario.Scenario{
+				Name:      "Harbor",
+				Inventory: []string{"lamp", "rope"},
+				Locations: map[string]scenario.Location{"dock": {Name: "Dock"}, "tavern": {Name: "Tavern"}},
+				NPCs:      map[string]actor.NPC{"gibbs": {Name: "Gibbs", Location: "dock"}},
+				Vars:      map[string]string{"has_ship": "false", "door_open": "false"},
+			})
+			gs := state.NewGameState("harbor.json", nil, "foo_model")
+			gs.WorldLocations = map[string]scenario.Location{"dock": {Name: "Dock"}, "tavern": {Name: "Tavern"}}
+			gs.NPCs = map[string]actor.NPC{"gibbs": {Name: "Gibbs", Location: "dock"}}
+			gs.Location = "dock"
+			gs.Inventory = []string{"lamp"}
+			gs.Vars = map[string]string{"has_ship": "false", "door_open": "true"}
+			gs.TurnCounter = 4
+			gs.IsEnded = true
+			if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+			req := httptest.NewRequest(http.MethodPatch, "/v1/gamestate/"+gs.ID.String()+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+
+			saved, err := mockStorage.LoadGameState(ctx, gs.ID)
+			if err != nil || saved == nil {
+				t.Fatalf("Failed to load game state: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, saved)
+			} else if !saved.IsEnded || saved.Location != "dock" || saved.Scenario != "harbor.json" {
+				t.Errorf("Expected a rejected patch to leave the game unchanged, got %+v", saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// PauseRequest is the optional body for pausing a game
+type PauseRequest struct {
+	Reason string `json:"reason,omitempty"` // Shown to players whose chats are rejected while paused
+}
+
+// handlePause pauses a game: chats are rejected and story events are held until it resumes
+func (h *GameStateHandler) handlePause(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	var req PauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Warn("Invalid JSON in pause request body", "error", err)
+		h.writeError(w, http.StatusBadRequest, "Invalid JSON in request body")
+		return
+	}
+
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok || h.rejectTrial(w, gs, "paused") {
+		return
+	}
+
+	gs.Pause(req.Reason)
+	ctx := state.WithGameEvent(r.Context(), state.GameEvent{Kind: state.EventPaused})
+	if err := h.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		h.logger.Error("Failed to save paused game state", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to save game state")
+		return
+	}
+
+	h.logger.Info("Game paused", "id", gameStateID.String(), "reason", gs.PauseReason)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(gs); err != nil {
+		h.logger.Error("Failed to encode paused game state response", "error", err)
+	}
+}
+
+// handleResume resumes a paused game and queues the story events held while it was paused
+func (h *GameStateHandler) handleResume(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+
+	if len(gs.HeldStoryEvents) > 0 && h.chatQueue == nil {
+		h.logger.Error("Cannot resume game with held story events: no queue configured", "id", gameStateID.String())
+		h.writeError(w, http.StatusServiceUnavailable, "Cannot resume game: story event queue unavailable")
+		return
+	}
+
+	held := gs.Resume()
+	ctx := state.WithGameEvent(r.Context(), state.GameEvent{Kind: state.EventResumed})
+	if err := h.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		h.logger.Error("Failed to save resumed game state", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to save game state")
+		return
+	}
+
+	// Held story events go back on the queue in the order they came due
+	for _, prompt := range held {
+		req := &queue.Request{
+			RequestID:   uuid.New().String(),
+			Type:        queue.RequestTypeStoryEvent,
+			GameStateID: gs.ID,
+			Profile:     gs.Profile,
+			EventPrompt: prompt,
+			EnqueuedAt:  time.Now(),
+		}
+		if err := h.chatQueue.EnqueueRequest(r.Context(), req); err != nil {
+			h.logger.Error("Failed to queue held story event", "error", err, "id", gameStateID.String(), "event", prompt)
+		}
+	}
+
+	h.logger.Info("Game resumed", "id", gameStateID.String(), "held_story_events", len(held))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(gs); err != nil {
+		h.logger.Error("Failed to encode resumed game state response", "error", err)
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_PauseResume(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		method         string
+		action         string
+		body           string
+		startPaused    bool
+		held           []string
+		noQueue        bool
+		expectedStatus int
+		expectPaused   bool
+		expectReason   string
+		expectQueued   []string
+	}{
+		{
+			name:           "pause",
+			method:         http.MethodPost,
+			action:         "pause",
+			expectedStatus: http.StatusOK,
+			expectPaused:   true,
+		},
+		{
+			name:           "pause with reason",
+			method:         http.MethodPost,
+			action:         "pause",
+			body:           `{"reason":"server maintenance"}`,
+			expectedStatus: http.StatusOK,
+			expectPaused:   true,
+			expectReason:   "server maintenance",
+		},
+		{
+			name:           "invalid body",
+			method:         http.MethodPost,
+			action:         "pause",
+			body:           `{"reason":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "resume queues held story events in order",
+			method:         http.MethodPost,
+			action:         "resume",
+			startPaused:    true,
+			held:           []string{"Thunder rolls.", "The door creaks open."},
+			expectedStatus: http.StatusOK,
+			expectQueued:   []string{"Thunder rolls.", "The door creaks open."},
+		},
+		{
+			name:           "resume without queue keeps held events",
+			method:         http.MethodPost,
+			action:         "resume",
+			startPaused:    true,
+			held:           []string{"Thunder rolls."},
+			noQueue:        true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectPaused:   true,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodGet,
+			action:         "pause",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+			if tt.startPaused {
+				gs.Pause("")
+			}
+			gs.HeldStoryEvents = tt.held
+			if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+
+			q := &stubChatQueue{}
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+			if !tt.noQueue {
+				handler = handler.WithQueue(q)
+			}
+
+			req := httptest.NewRequest(tt.method, "/v1/gamestate/"+gs.ID.String()+"/"+tt.action, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+
+			saved, err := mockStorage.LoadGameState(ctx, gs.ID)
+			if err != nil || saved == nil {
+				t.Fatalf("Failed to load game state: %v", err)
+			}
+			if saved.Paused != tt.expectPaused {
+				t.Errorf("Expected paused %v, got %v", tt.expectPaused, saved.Paused)
+			}
+			if saved.PauseReason != tt.expectReason {
+				t.Errorf("Expected reason %q, got %q", tt.expectReason, saved.PauseReason)
+			}
+			if saved.Paused && saved.PausedAt == nil {
+				t.Error("Expected paused_at to be set")
+			}
+
+			if len(q.requests) != len(tt.expectQueued) {
+				t.Fatalf("Expected %d queued story events, got %d", len(tt.expectQueued), len(q.requests))
+			}
+			for i, r := range q.requests {
+				if r.Type != queue.RequestTypeStoryEvent || r.EventPrompt != tt.expectQueued[i] || r.GameStateID != gs.ID {
+					t.Errorf("Unexpected queued request %d: %+v", i, r)
+				}
+			}
+			if len(tt.expectQueued) > 0 && len(saved.HeldStoryEvents) != 0 {
+				t.Errorf("Expected held story events to be cleared, got %v", saved.HeldStoryEvents)
+			}
+		})
+	}
+
+	t.Run("unknown game", func(t *testing.T) {
+		handler := NewGameStateHandler(logger, "foo_model", storage.NewMockStorage())
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/gamestate/"+uuid.New().String()+"/pause", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+}
+
+func TestChatHandler_PausedGame(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := storage.NewMockStorage()
+
+	active := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	paused := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	paused.Pause("back soon")
+	for _, gs := range []*state.GameState{active, paused} {
+		if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+			t.Fatalf("Failed to save game state: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		gameStateID    uuid.UUID
+		expectedStatus int
+		expectedQueued int
+	}{
+		{"active game queued", active.ID, http.StatusAccepted, 1},
+		{"paused game rejected", paused.ID, http.StatusConflict, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &stubChatQueue{}
+			handler := NewChatHandler(q, slog.New(slog.NewTextHandler(io.Discard, nil))).
+				WithStorage(mockStorage)
+
+			body, _ := json.Marshal(map[string]string{"gamestate_id": tt.gameStateID.String(), "message": "Look around"})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat", bytes.NewReader(body)))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if len(q.requests) != tt.expectedQueued {
+				t.Errorf("Expected %d queued requests, got %d", tt.expectedQueued, len(q.requests))
+			}
+			if tt.expectedStatus == http.StatusConflict && !strings.Contains(w.Body.String(), "back soon") {
+				t.Errorf("Expected the pause reason in the response, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -2,17 +2,23 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
 )
 
 type PCHandler struct {
-	log     *slog.Logger
-	storage storage.Storage
+	log      *slog.Logger
+	storage  storage.ContentStore
+	games    []storage.GameStateStore // game storages checked for references before a delete; defaults to storage
+	adminKey string                   // optional; enables create, update, and delete
 }
 
 // ListPCs lists all available PC files
@@ -23,6 +29,7 @@ func (h *PCHandler) ListPCs(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to list PCs", http.StatusInternalServerError)
 		return
 	}
+	slices.Sort(pcIDs)
 
 	// Initialize as empty slice instead of nil
 	pcList := make([]map[string]interface{}, 0)
@@ -36,12 +43,13 @@ func (h *PCHandler) ListPCs(w http.ResponseWriter, r *http.Request) {
 
 		// Create a summary object with just the key fields
 		pcSummary := map[string]interface{}{
-			"id":       spec.ID,
-			"name":     spec.Name,
-			"class":    spec.Class,
-			"level":    spec.Level,
-			"race":     spec.Race,
-			"pronouns": spec.Pronouns,
+			"id":          spec.ID,
+			"name":        spec.Name,
+			"class":       spec.Class,
+			"level":       spec.Level,
+			"race":        spec.Race,
+			"pronouns":    spec.Pronouns,
+			"description": spec.Description,
 		}
 		pcList = append(pcList, pcSummary)
 	}
@@ -60,51 +68,114 @@ func (h *PCHandler) ListPCs(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func NewPCHandler(log *slog.Logger, storage storage.Storage) *PCHandler {
+func NewPCHandler(log *slog.Logger, storage storage.ContentStore) *PCHandler {
 	return &PCHandler{
 		log:     log,
 		storage: storage,
 	}
 }
 
+// WithAdminKey enables creating, updating, and deleting PCs, authenticated
+// by the X-Admin-Key header. Without it PCs are read-only.
+func (h *PCHandler) WithAdminKey(adminKey string) *PCHandler {
+	h.adminKey = adminKey
+	return h
+}
+
+// WithGameStorages sets the game storages searched for games still using a
+// PC before it is deleted, e.g. one per profile
+func (h *PCHandler) WithGameStorages(games ...storage.GameStateStore) *PCHandler {
+	h.games = games
+	return h
+}
+
+// ServeHTTP handles HTTP requests for PCs
+// Routes:
+// GET /v1/pcs              - List PCs
+// GET /v1/pcs/{id}         - Read a PC, built from its spec
+// GET /v1/pcs/{id}/profile - Read a PC's render-ready character sheet
+// POST /v1/pcs             - Create a PC spec (admin only)
+// PUT /v1/pcs/{id}         - Replace a PC spec (admin only)
+// DELETE /v1/pcs/{id}      - Delete a PC no scenario or active game uses (admin only)
 func (h *PCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		if r.URL.Path == "/v1/pcs" || r.URL.Path == "/v1/pcs/" {
-			h.ListPCs(w, r)
-		} else {
-			h.handleGet(w, r)
+	if pcPath, ok := strings.CutSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/profile"); ok {
+		if id, err := resourceID(pcPath, "/v1/pcs"); err == nil && id != "" {
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.handleProfile(w, r, id)
+			return
+		}
+	}
+
+	id, err := resourceID(r.URL.Path, "/v1/pcs")
+	if err != nil {
+		http.Error(w, "Invalid PC ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		h.ListPCs(w, r)
+	case r.Method == http.MethodGet:
+		h.handleGet(w, r, id)
+	case r.Method == http.MethodPost && id == "",
+		r.Method == http.MethodPut && id != "",
+		r.Method == http.MethodDelete && id != "":
+		if !hasAdminKey(r, h.adminKey) {
+			h.log.Warn("PC change refused: missing or invalid admin key", "method", r.Method)
+			http.Error(w, "A valid admin key is required", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			h.handleCreate(w, r)
+		case http.MethodPut:
+			h.handleUpdate(w, r, id)
+		default:
+			h.handleDelete(w, r, id)
 		}
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-func (h *PCHandler) handleGet(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/v1/pcs/")
-	id := strings.TrimSpace(path)
-
-	if id == "" || id == "/" {
-		http.Error(w, "PC ID is required in URL path (e.g., /v1/pcs/pirate_captain)", http.StatusBadRequest)
+func (h *PCHandler) handleGet(w http.ResponseWriter, r *http.Request, id string) {
+	loadedPC, ok := h.loadPC(w, r, id)
+	if !ok {
 		return
 	}
+	// Marshal the PC (uses custom MarshalJSON that reads from Actor)
+	h.writeJSON(w, id, loadedPC)
+}
 
-	// Security: prevent directory traversal
-	if strings.Contains(id, "..") || strings.Contains(id, "/") {
-		http.Error(w, "Invalid PC ID", http.StatusBadRequest)
+// handleProfile serves the PC's render-ready character sheet
+func (h *PCHandler) handleProfile(w http.ResponseWriter, r *http.Request, id string) {
+	loadedPC, ok := h.loadPC(w, r, id)
+	if !ok {
 		return
 	}
+	h.writeJSON(w, id, loadedPC.Profile())
+}
 
+// loadPC loads a PC spec and builds the PC, writing the error response and
+// returning false if it can't
+func (h *PCHandler) loadPC(w http.ResponseWriter, r *http.Request, id string) (*actor.PC, bool) {
 	// Load the PC spec by ID (storage handles path construction)
 	pcSpec, err := h.storage.GetPCSpec(r.Context(), id)
 	if err != nil {
 		if err.Error() == "PC spec not found" {
 			http.Error(w, "PC not found", http.StatusNotFound)
-			return
+			return nil, false
+		}
+		if ids, listErr := h.storage.ListPCs(r.Context()); listErr == nil && !slices.Contains(ids, id) {
+			http.Error(w, "PC not found", http.StatusNotFound)
+			return nil, false
 		}
 		h.log.Error("Failed to load PC spec", "error", err, "id", id)
 		http.Error(w, "Failed to load PC", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 
 	// Build the PC from the spec
@@ -112,11 +183,13 @@ func (h *PCHandler) handleGet(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.log.Error("Failed to build PC from spec", "error", err, "id", id)
 		http.Error(w, "Failed to build PC", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
+	return loadedPC, true
+}
 
-	// Marshal the PC (uses custom MarshalJSON that reads from Actor)
-	data, err := json.Marshal(loadedPC)
+func (h *PCHandler) writeJSON(w http.ResponseWriter, id string, v any) {
+	data, err := json.Marshal(v)
 	if err != nil {
 		h.log.Error("Failed to marshal PC", "error", err, "id", id)
 		http.Error(w, "Failed to process PC", http.StatusInternalServerError)
@@ -129,3 +202,147 @@ func (h *PCHandler) handleGet(w http.ResponseWriter, r *http.Request) {
 		h.log.Error("Failed to write response", "error", err, "id", id)
 	}
 }
+
+func (h *PCHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	spec, ok := h.decodeSpec(w, r)
+	if !ok {
+		return
+	}
+	spec.ID = normalizeID(spec.ID)
+	if spec.ID == "" {
+		http.Error(w, "PC id is required", http.StatusBadRequest)
+		return
+	}
+
+	ids, err := h.storage.ListPCs(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list PCs", "error", err)
+		http.Error(w, "Failed to list PCs", http.StatusInternalServerError)
+		return
+	}
+	if slices.Contains(ids, spec.ID) {
+		http.Error(w, fmt.Sprintf("PC %s already exists", spec.ID), http.StatusConflict)
+		return
+	}
+
+	if err := h.storage.SavePCSpec(r.Context(), spec); err != nil {
+		h.log.Error("Failed to save PC spec", "error", err, "id", spec.ID)
+		writeContentError(w, err, "Failed to save PC")
+		return
+	}
+	h.log.Info("PC created", "id", spec.ID)
+	h.writeSpec(w, http.StatusCreated, spec)
+}
+
+func (h *PCHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id string) {
+	spec, ok := h.decodeSpec(w, r)
+	if !ok {
+		return
+	}
+	if spec.ID != "" && normalizeID(spec.ID) != id {
+		http.Error(w, "PC id in body does not match the URL", http.StatusBadRequest)
+		return
+	}
+	spec.ID = id
+	if !h.exists(w, r, id) {
+		return
+	}
+
+	if err := h.storage.SavePCSpec(r.Context(), spec); err != nil {
+		h.log.Error("Failed to save PC spec", "error", err, "id", id)
+		writeContentError(w, err, "Failed to save PC")
+		return
+	}
+	h.log.Info("PC updated", "id", id)
+	h.writeSpec(w, http.StatusOK, spec)
+}
+
+func (h *PCHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if !h.exists(w, r, id) {
+		return
+	}
+
+	refs, err := findReferences(r.Context(), h.storage, h.gameStorages(),
+		func(s *scenario.Scenario) bool { return s.DefaultPC == id },
+		func(gs *state.GameState) bool { return gs.PC != nil && gs.PC.Spec != nil && gs.PC.Spec.ID == id },
+	)
+	if err != nil {
+		h.log.Error("Failed to check PC references", "error", err, "id", id)
+		http.Error(w, "Failed to check PC references", http.StatusInternalServerError)
+		return
+	}
+	if refs.inUse() {
+		http.Error(w, fmt.Sprintf("PC %s is still used by %s", id, refs), http.StatusConflict)
+		return
+	}
+
+	if err := h.storage.DeletePCSpec(r.Context(), id); err != nil {
+		h.log.Error("Failed to delete PC spec", "error", err, "id", id)
+		writeContentError(w, err, "Failed to delete PC")
+		return
+	}
+	h.log.Info("PC deleted", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeSpec reads and validates a PC spec from the request body,
+// writing the error response and returning false if it is invalid
+func (h *PCHandler) decodeSpec(w http.ResponseWriter, r *http.Request) (*actor.PCSpec, bool) {
+	var spec actor.PCSpec
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		http.Error(w, "Invalid PC JSON: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if strings.TrimSpace(spec.Name) == "" {
+		http.Error(w, "Invalid PC: name is required", http.StatusBadRequest)
+		return nil, false
+	}
+	if _, err := actor.NewPCFromSpec(&spec); err != nil {
+		http.Error(w, "Invalid PC: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return &spec, true
+}
+
+// exists reports whether a PC file exists, writing a 404 or 500 response if not
+func (h *PCHandler) exists(w http.ResponseWriter, r *http.Request, id string) bool {
+	ids, err := h.storage.ListPCs(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list PCs", "error", err)
+		http.Error(w, "Failed to list PCs", http.StatusInternalServerError)
+		return false
+	}
+	if !slices.Contains(ids, id) {
+		http.Error(w, "PC not found", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+func (h *PCHandler) writeSpec(w http.ResponseWriter, status int, spec *actor.PCSpec) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		h.log.Error("Failed to marshal PC spec", "error", err, "id", spec.ID)
+		http.Error(w, "Failed to process PC", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		h.log.Error("Failed to write response", "error", err, "id", spec.ID)
+	}
+}
+
+// gameStorages returns the game storages to check for references, defaulting to
+// the content storage when it also holds game states
+func (h *PCHandler) gameStorages() []storage.GameStateStore {
+	if len(h.games) == 0 {
+		if games, ok := h.storage.(storage.GameStateStore); ok {
+			return []storage.GameStateStore{games}
+		}
+	}
+	return h.games
+}
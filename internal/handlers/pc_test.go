@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
 )
 
@@ -243,16 +249,24 @@ func TestPCHandler_MethodNotAllowed(t *testing.T) {
 	mockStorage := storage.NewMockStorage()
 	handler := NewPCHandler(log, mockStorage)
 
-	methods := []string{http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch}
-	for _, method := range methods {
-		t.Run(method, func(t *testing.T) {
-			req := httptest.NewRequest(method, "/v1/pcs", nil)
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPut, "/v1/pcs"},
+		{http.MethodDelete, "/v1/pcs"},
+		{http.MethodPatch, "/v1/pcs"},
+		{http.MethodPost, "/v1/pcs/classic"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
 			w := httptest.NewRecorder()
 
 			handler.ServeHTTP(w, req)
 
 			if w.Code != http.StatusMethodNotAllowed {
-				t.Errorf("%s method status = %d, want %d", method, w.Code, http.StatusMethodNotAllowed)
+				t.Errorf("%s %s status = %d, want %d", tc.method, tc.path, w.Code, http.StatusMethodNotAllowed)
 			}
 		})
 	}
@@ -276,3 +290,184 @@ func TestPCHandler_ListPCs_WithTrailingSlash(t *testing.T) {
 		t.Errorf("ListPCs() with trailing slash status = %d, want %d", w.Code, http.StatusOK)
 	}
 }
+
+func TestPCHandler_CRUD(t *testing.T) {
+	const adminKey = "secret"
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		key        string
+		wantStatus int
+	}{
+		{"create without admin key", http.MethodPost, "/v1/pcs", `{"id":"ranger","name":"Ranger"}`, "", http.StatusUnauthorized},
+		{"create with wrong admin key", http.MethodPost, "/v1/pcs", `{"id":"ranger","name":"Ranger"}`, "wrong", http.StatusUnauthorized},
+		{"create", http.MethodPost, "/v1/pcs", `{"id":"ranger","name":"Ranger","class":"ranger","level":2,"hp":12,"max_hp":12}`, adminKey, http.StatusCreated},
+		{"create existing", http.MethodPost, "/v1/pcs", `{"id":"classic","name":"Adventurer","hp":10,"max_hp":10}`, adminKey, http.StatusConflict},
+		{"create missing id", http.MethodPost, "/v1/pcs", `{"name":"Ranger"}`, adminKey, http.StatusBadRequest},
+		{"create missing name", http.MethodPost, "/v1/pcs", `{"id":"ranger"}`, adminKey, http.StatusBadRequest},
+		{"create unknown field", http.MethodPost, "/v1/pcs", `{"id":"ranger","name":"Ranger","colour":"green"}`, adminKey, http.StatusBadRequest},
+		{"update", http.MethodPut, "/v1/pcs/classic", `{"name":"Veteran Adventurer","level":4,"hp":20,"max_hp":20}`, adminKey, http.StatusOK},
+		{"update mismatched id", http.MethodPut, "/v1/pcs/classic", `{"id":"other","name":"Other"}`, adminKey, http.StatusBadRequest},
+		{"update missing", http.MethodPut, "/v1/pcs/nobody", `{"name":"Nobody","hp":5,"max_hp":5}`, adminKey, http.StatusNotFound},
+		{"delete unused", http.MethodDelete, "/v1/pcs/spare", "", adminKey, http.StatusNoContent},
+		{"delete missing", http.MethodDelete, "/v1/pcs/nobody", "", adminKey, http.StatusNotFound},
+		{"delete without admin key", http.MethodDelete, "/v1/pcs/spare", "", "", http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddPCSpec("classic", &actor.PCSpec{ID: "classic", Name: "Adventurer"})
+			mockStorage.AddPCSpec("spare", &actor.PCSpec{ID: "spare", Name: "Spare"})
+			handler := NewPCHandler(log, mockStorage).WithAdminKey(adminKey)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.key != "" {
+				req.Header.Set("X-Admin-Key", tc.key)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if tc.wantStatus == http.StatusCreated {
+				spec, err := mockStorage.GetPCSpec(req.Context(), "ranger")
+				if err != nil || spec.Level != 2 {
+					t.Errorf("created spec = %+v, %v", spec, err)
+				}
+			}
+			if tc.wantStatus == http.StatusNoContent {
+				if ids, _ := mockStorage.ListPCs(req.Context()); slices.Contains(ids, "spare") {
+					t.Errorf("spare still listed after delete: %v", ids)
+				}
+			}
+		})
+	}
+}
+
+func TestPCHandler_DeleteReferenced(t *testing.T) {
+	const adminKey = "secret"
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	spec := &actor.PCSpec{ID: "classic", Name: "Adventurer", HP: 10, MaxHP: 10}
+	pc, err := actor.NewPCFromSpec(spec)
+	if err != nil {
+		t.Fatalf("NewPCFromSpec: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		scenario   *scenario.Scenario
+		game       *state.GameState
+		wantStatus int
+	}{
+		{"default PC of a scenario", &scenario.Scenario{Name: "Pirates", DefaultPC: "classic"}, nil, http.StatusConflict},
+		{"PC of an active game", nil, &state.GameState{ID: uuid.New(), PC: pc}, http.StatusConflict},
+		{"PC of an ended game", nil, &state.GameState{ID: uuid.New(), PC: pc, IsEnded: true}, http.StatusNoContent},
+		{"other scenario", &scenario.Scenario{Name: "Pirates", DefaultPC: "pirate_captain"}, nil, http.StatusNoContent},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddPCSpec("classic", spec)
+			if tc.scenario != nil {
+				mockStorage.AddScenario("pirates.json", tc.scenario)
+			}
+			// Games live in a second profile's storage
+			games := storage.NewMockStorage()
+			if tc.game != nil {
+				if err := games.SaveGameState(context.Background(), tc.game.ID, tc.game); err != nil {
+					t.Fatalf("SaveGameState: %v", err)
+				}
+			}
+			handler := NewPCHandler(log, mockStorage).WithAdminKey(adminKey).WithGameStorages(mockStorage, games)
+
+			req := httptest.NewRequest(http.MethodDelete, "/v1/pcs/classic", nil)
+			req.Header.Set("X-Admin-Key", adminKey)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, tc.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPCHandler_GetProfile(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockStorage := storage.NewMockStorage()
+	mockStorage.AddPCSpec("classic", &actor.PCSpec{
+		ID:       "classic",
+		Name:     "Adventurer",
+		Class:    "fighter",
+		Level:    1,
+		Race:     "human",
+		Portrait: "portraits/classic.png",
+		Stats:    actor.Stats5e{Strength: 15, Dexterity: 14, Constitution: 13, Intelligence: 12, Wisdom: 10, Charisma: 8},
+		HP:       12,
+		MaxHP:    12,
+		AC:       16,
+	})
+	// A PC whose ID is "profile" is still reachable at /v1/pcs/profile
+	mockStorage.AddPCSpec("profile", &actor.PCSpec{ID: "profile", Name: "Profile", HP: 5, MaxHP: 5})
+	handler := NewPCHandler(log, mockStorage)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantName   string
+	}{
+		{"profile", http.MethodGet, "/v1/pcs/classic/profile", http.StatusOK, "Adventurer"},
+		{"trailing slash", http.MethodGet, "/v1/pcs/classic/profile/", http.StatusOK, "Adventurer"},
+		{"missing PC", http.MethodGet, "/v1/pcs/nobody/profile", http.StatusNotFound, ""},
+		{"wrong method", http.MethodPut, "/v1/pcs/classic/profile", http.StatusMethodNotAllowed, ""},
+		{"PC named profile", http.MethodGet, "/v1/pcs/profile", http.StatusOK, "Profile"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tc.wantStatus)
+			}
+			if tc.wantName == "" {
+				return
+			}
+			var profile actor.PCProfile
+			if err := json.Unmarshal(w.Body.Bytes(), &profile); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if profile.Name != tc.wantName {
+				t.Errorf("Name = %q, want %q", profile.Name, tc.wantName)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/pcs/classic/profile", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	var profile actor.PCProfile
+	if err := json.Unmarshal(w.Body.Bytes(), &profile); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if profile.Summary != "Level 1 human fighter" || profile.Portrait != "portraits/classic.png" || profile.AC != 16 {
+		t.Errorf("profile = %+v", profile)
+	}
+	if len(profile.Abilities) != 6 || profile.Abilities[0].Modifier != 2 {
+		t.Errorf("Abilities = %+v, want STR modifier +2 first", profile.Abilities)
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// ReceiptsResponse lists the turn receipts recorded for a game state
+type ReceiptsResponse struct {
+	GameStateID uuid.UUID           `json:"gamestate_id"`
+	Receipts    []state.TurnReceipt `json:"receipts"`
+}
+
+// serveSubresource routes requests for paths below /v1/gamestate/{id}/
+func (h *GameStateHandler) serveSubresource(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID, subPath string) {
+	resource, rest, _ := strings.Cut(subPath, "/")
+
+	switch resource {
+	case "receipts":
+		if r.Method != http.MethodGet {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: GET")
+			return
+		}
+		h.handleReceipts(w, r, gameStateID, rest)
+	case "codex":
+		if r.Method != http.MethodGet || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: GET")
+			return
+		}
+		h.handleCodex(w, r, gameStateID)
+	case "hints":
+		if r.Method != http.MethodGet || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: GET")
+			return
+		}
+		h.handleHints(w, r, gameStateID)
+	case "export":
+		if r.Method != http.MethodGet || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: GET")
+			return
+		}
+		h.handleExport(w, r, gameStateID)
+	case "events":
+		if r.Method != http.MethodGet {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: GET")
+			return
+		}
+		h.handleEvents(w, r, gameStateID, rest)
+	case "fork":
+		if r.Method != http.MethodPost || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: POST")
+			return
+		}
+		h.handleFork(w, r, gameStateID)
+	case "pause", "resume":
+		if r.Method != http.MethodPost || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: POST")
+			return
+		}
+		if resource == "pause" {
+			h.handlePause(w, r, gameStateID)
+		} else {
+			h.handleResume(w, r, gameStateID)
+		}
+	case "regenerate":
+		if r.Method != http.MethodPost || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: POST")
+			return
+		}
+		h.handleRegenerate(w, r, gameStateID)
+	case "continue":
+		if r.Method != http.MethodPost || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: POST")
+			return
+		}
+		h.handleContinue(w, r, gameStateID)
+	case "choose":
+		if r.Method != http.MethodPost || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: POST")
+			return
+		}
+		h.handleChooseVariant(w, r, gameStateID)
+	case "narrator":
+		if r.Method != http.MethodPost || rest != "" {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: POST")
+			return
+		}
+		h.handleNarratorSwap(w, r, gameStateID)
+	case "snapshots":
+		if rest != "" {
+			h.writeError(w, http.StatusNotFound, "Unknown snapshots resource: "+rest)
+			return
+		}
+		h.handleSnapshots(w, r, gameStateID)
+	case "restore":
+		if r.Method != http.MethodPost {
+			h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: POST")
+			return
+		}
+		h.handleRestore(w, r, gameStateID, rest)
+	default:
+		h.writeError(w, http.StatusNotFound, "Unknown game state resource: "+resource)
+	}
+}
+
+// handleReceipts returns all recent turn receipts, or the receipt for a single turn
+func (h *GameStateHandler) handleReceipts(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID, turnStr string) {
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+
+	if turnStr == "" {
+		receipts := gs.TurnReceipts
+		if receipts == nil {
+			receipts = []state.TurnReceipt{}
+		}
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(ReceiptsResponse{GameStateID: gs.ID, Receipts: receipts}); err != nil {
+			h.logger.Error("Failed to encode receipts response", "error", err)
+		}
+		return
+	}
+
+	turn, err := strconv.Atoi(turnStr)
+	if err != nil || turn < 0 {
+		h.writeError(w, http.StatusBadRequest, "Invalid turn number: "+turnStr)
+		return
+	}
+
+	receipt, found := gs.GetTurnReceipt(turn)
+	if !found {
+		h.writeError(w, http.StatusNotFound, "No receipt recorded for turn "+turnStr)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(receipt); err != nil {
+		h.logger.Error("Failed to encode receipt response", "error", err)
+	}
+}
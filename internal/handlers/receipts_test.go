@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_Receipts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	mockStorage := storage.NewMockStorage()
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	gs.AddTurnReceipt(&state.TurnReceipt{Turn: 1, ItemsGained: []string{"skeleton key"}})
+	gs.AddTurnReceipt(&state.TurnReceipt{Turn: 2, LocationChanged: "cellar"})
+	if err := mockStorage.SaveGameState(context.Background(), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+
+	handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"list receipts", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/receipts", http.StatusOK},
+		{"single receipt", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/receipts/1", http.StatusOK},
+		{"missing turn", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/receipts/7", http.StatusNotFound},
+		{"invalid turn", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/receipts/abc", http.StatusBadRequest},
+		{"unknown game", http.MethodGet, "/v1/gamestate/" + uuid.New().String() + "/receipts", http.StatusNotFound},
+		{"unknown resource", http.MethodGet, "/v1/gamestate/" + gs.ID.String() + "/bogus", http.StatusNotFound},
+		{"wrong method", http.MethodPost, "/v1/gamestate/" + gs.ID.String() + "/receipts", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("list body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/gamestate/"+gs.ID.String()+"/receipts", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var response ReceiptsResponse
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Receipts) != 2 {
+			t.Errorf("Expected 2 receipts, got %d", len(response.Receipts))
+		}
+	})
+
+	t.Run("single body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/gamestate/"+gs.ID.String()+"/receipts/1", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var receipt state.TurnReceipt
+		if err := json.NewDecoder(rr.Body).Decode(&receipt); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if receipt.Turn != 1 || len(receipt.ItemsGained) != 1 || receipt.ItemsGained[0] != "skeleton key" {
+			t.Errorf("Unexpected receipt: %+v", receipt)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+)
+
+// handleRegenerate queues the game's last player turn to be played again. The worker
+// undoes the turn and everything its delta changed, narrates the player's message anew
+// at a higher temperature, and runs the delta on the new narration. Progress is reported
+// on the game's event stream like any chat.
+func (h *GameStateHandler) handleRegenerate(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	if h.chatQueue == nil {
+		h.logger.Error("Cannot regenerate turn: no queue configured", "id", gameStateID.String())
+		h.writeError(w, http.StatusServiceUnavailable, "Cannot regenerate turn: chat queue unavailable")
+		return
+	}
+
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+	if gs.Paused {
+		h.writeError(w, http.StatusConflict, gs.PausedMessage())
+		return
+	}
+	if gs.LastTurn == nil {
+		h.writeError(w, http.StatusConflict, "There is no player turn to regenerate")
+		return
+	}
+	if h.budget != nil && !checkBudget(w, r, h.budget, h.logger, gs.ID, middleware.APIKeyID(r.Context())) {
+		return
+	}
+
+	h.enqueueTurn(w, r, &queue.Request{
+		Type:        queue.RequestTypeRegenerate,
+		GameStateID: gs.ID,
+		Profile:     gs.Profile,
+	})
+}
+
+// enqueueTurn queues a request that plays a turn for the worker, and answers 202 with
+// its request ID, as a chat does. The turn calls the LLM like a chat, so it is charged
+// against the profile's daily chat budget.
+func (h *GameStateHandler) enqueueTurn(w http.ResponseWriter, r *http.Request, req *queue.Request) {
+	if !middleware.SpendChat(r.Context()) {
+		h.writeError(w, http.StatusTooManyRequests, "Daily chat budget exhausted")
+		return
+	}
+	req.RequestID = queueRequestID(r)
+	req.EnqueuedAt = time.Now()
+	if err := h.chatQueue.EnqueueRequest(r.Context(), req); err != nil {
+		h.logger.Error("Failed to enqueue request", "error", err, "type", req.Type, "id", req.GameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to enqueue request for processing.")
+		return
+	}
+
+	h.logger.Info("Request enqueued", "request_id", req.RequestID, "type", req.Type, "id", req.GameStateID.String())
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(ChatResponse{
+		RequestID: req.RequestID,
+		Message:   "Request accepted for processing. Poll game state for updates.",
+	}); err != nil {
+		h.logger.Error("Failed to encode enqueue response", "error", err)
+	}
+}
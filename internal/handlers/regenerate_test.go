@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_Regenerate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		method         string
+		noTurn         bool
+		paused         bool
+		noQueue        bool
+		expectedStatus int
+		expectQueued   bool
+	}{
+		{name: "queues the last turn", method: http.MethodPost, expectedStatus: http.StatusAccepted, expectQueued: true},
+		{name: "no turn to regenerate", method: http.MethodPost, noTurn: true, expectedStatus: http.StatusConflict},
+		{name: "paused game", method: http.MethodPost, paused: true, expectedStatus: http.StatusConflict},
+		{name: "no queue", method: http.MethodPost, noQueue: true, expectedStatus: http.StatusServiceUnavailable},
+		{name: "wrong method", method: http.MethodGet, expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+			if !tt.noTurn {
+				if err := gs.RememberTurn("I look around", state.TurnPlayer); err != nil {
+					t.Fatalf("RememberTurn() error: %v", err)
+				}
+			}
+			if tt.paused {
+				gs.Pause("")
+			}
+			if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+
+			q := &stubChatQueue{}
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+			if !tt.noQueue {
+				handler = handler.WithQueue(q)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tt.method, "/v1/gamestate/"+gs.ID.String()+"/regenerate", nil))
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+
+			if !tt.expectQueued {
+				if len(q.requests) != 0 {
+					t.Errorf("Expected nothing queued, got %+v", q.requests)
+				}
+				return
+			}
+			if len(q.requests) != 1 {
+				t.Fatalf("Expected one queued request, got %d", len(q.requests))
+			}
+			if r := q.requests[0]; r.Type != queue.RequestTypeRegenerate || r.GameStateID != gs.ID || r.RequestID == "" {
+				t.Errorf("Unexpected queued request: %+v", r)
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_TurnsChargeChatBudget(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	mockStorage := storage.NewMockStorage()
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	if err := gs.RememberTurn("I look around", state.TurnPlayer); err != nil {
+		t.Fatalf("RememberTurn() error: %v", err)
+	}
+	gs.ChatHistory = []chat.ChatMessage{
+		{Role: chat.ChatRoleUser, Content: "I look around"},
+		{Role: chat.ChatRoleAgent, Content: "The fog lifts, and", Incomplete: true},
+	}
+	if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+
+	q := &stubChatQueue{}
+	router := middleware.NewProfileRouter(map[string]string{"key-b": "b"}, logger)
+	router.Handle(config.Profile{Name: "b", DailyChatLimit: 2}, NewGameStateHandler(logger, "foo_model", mockStorage).WithQueue(q))
+
+	tests := []struct {
+		action         string
+		expectedStatus int
+	}{
+		{action: "regenerate", expectedStatus: http.StatusAccepted},
+		{action: "continue", expectedStatus: http.StatusAccepted},
+		{action: "regenerate", expectedStatus: http.StatusTooManyRequests},
+	}
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/v1/gamestate/"+gs.ID.String()+"/"+tt.action, nil)
+		req.Header.Set("X-API-Key", "key-b")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != tt.expectedStatus {
+			t.Errorf("Request %d (%s): expected status %d, got %d. Response body: %s", i+1, tt.action, tt.expectedStatus, rr.Code, rr.Body.String())
+		}
+	}
+	if len(q.requests) != 2 {
+		t.Errorf("Expected 2 turns queued within the budget, got %d", len(q.requests))
+	}
+}
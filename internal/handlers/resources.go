@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// Helpers shared by the handlers for filesystem-backed resources (narrators, PCs)
+// and admin-only operations
+
+// hasAdminKey reports whether the request carries adminKey in the X-Admin-Key
+// header. Admin operations are refused when no admin key is configured.
+func hasAdminKey(r *http.Request, adminKey string) bool {
+	key := r.Header.Get("X-Admin-Key")
+	return adminKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) == 1
+}
+
+// writeContentError answers a failed content write: 409 when the content is locked by
+// its integrity manifest, otherwise 500 with message
+func writeContentError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, storage.ErrContentLocked) {
+		http.Error(w, "Content can't be changed while it is verified against an integrity manifest", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, storage.ErrContentBundled) {
+		http.Error(w, "Content bundled in the data directory can't be deleted through the API", http.StatusConflict)
+		return
+	}
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// resourceID extracts the resource ID from a path like "/v1/narrators/{id}",
+// returning "" for the collection path and an error for an unsafe ID
+func resourceID(path, collection string) (string, error) {
+	id := strings.Trim(strings.TrimPrefix(path, collection), "/")
+	// Security: prevent directory traversal
+	if strings.Contains(id, "..") || strings.Contains(id, "/") || strings.Contains(id, `\`) {
+		return "", fmt.Errorf("invalid ID")
+	}
+	return id, nil
+}
+
+// validRating reports whether rating is one of the scenario content ratings
+func validRating(rating string) bool {
+	switch rating {
+	case scenario.RatingG, scenario.RatingPG, scenario.RatingPG13, scenario.RatingR:
+		return true
+	}
+	return false
+}
+
+// resourceReferences lists what still uses a narrator or PC
+type resourceReferences struct {
+	scenarios []string // Scenario filenames
+	games     int      // Games that haven't ended
+}
+
+func (refs resourceReferences) inUse() bool {
+	return len(refs.scenarios) > 0 || refs.games > 0
+}
+
+func (refs resourceReferences) String() string {
+	var parts []string
+	if len(refs.scenarios) > 0 {
+		parts = append(parts, "scenarios "+strings.Join(refs.scenarios, ", "))
+	}
+	if refs.games > 0 {
+		parts = append(parts, fmt.Sprintf("%d active game(s)", refs.games))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// findReferences scans every scenario and every unended game in the given game
+// storages for references to a resource
+func findReferences(
+	ctx context.Context,
+	store storage.ContentStore,
+	games []storage.GameStateStore,
+	inScenario func(*scenario.Scenario) bool,
+	inGame func(*state.GameState) bool,
+) (resourceReferences, error) {
+	var refs resourceReferences
+
+	scenarios, err := store.ListScenarios(ctx)
+	if err != nil {
+		return refs, fmt.Errorf("failed to list scenarios: %w", err)
+	}
+	for _, filename := range scenarios {
+		s, err := store.GetScenario(ctx, filename)
+		if err != nil || s == nil {
+			continue
+		}
+		if inScenario(s) {
+			refs.scenarios = append(refs.scenarios, filename)
+		}
+	}
+	slices.Sort(refs.scenarios)
+
+	for _, gameStore := range games {
+		ids, err := gameStore.ListGameStateIDs(ctx)
+		if err != nil {
+			return refs, fmt.Errorf("failed to list games: %w", err)
+		}
+		for _, id := range ids {
+			gs, err := gameStore.LoadGameState(ctx, id)
+			if err != nil {
+				return refs, fmt.Errorf("failed to load game %s: %w", id, err)
+			}
+			if gs != nil && !gs.IsEnded && inGame(gs) {
+				refs.games++
+			}
+		}
+	}
+	return refs, nil
+}
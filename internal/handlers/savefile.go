@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// importWarningHeader notes problems with an imported save that didn't stop the import
+const importWarningHeader = "X-Import-Warning"
+
+// handleExport returns the game as a portable save file, to import on another server
+func (h *GameStateHandler) handleExport(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok || h.rejectTrial(w, gs, "exported") {
+		return
+	}
+	s, err := h.storage.GetScenario(r.Context(), gs.Scenario)
+	if err != nil {
+		h.logger.Error("Failed to load scenario for export", "error", err, "scenario", gs.Scenario)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load scenario")
+		return
+	}
+	gs.MigrateIDs(s) // export the game's references in the scenario's current keys
+
+	save, err := state.NewSaveFile(gs, s)
+	if err != nil {
+		h.logger.Error("Failed to build save file", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to export game state")
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.json", strings.TrimSuffix(gs.Scenario, ".json"), gs.ID.String()[:8])
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(save); err != nil {
+		h.logger.Error("Failed to encode save file response", "error", err)
+	}
+}
+
+// handleImport restores a game from a save file as a new game owned by the caller.
+// The scenario must be installed here; the narrator and PC come with the save.
+func (h *GameStateHandler) handleImport(w http.ResponseWriter, r *http.Request) {
+	var save state.SaveFile
+	if err := json.NewDecoder(r.Body).Decode(&save); err != nil {
+		h.logger.Warn("Invalid JSON in import request body", "error", err)
+		h.writeError(w, http.StatusBadRequest, "Invalid JSON in request body")
+		return
+	}
+	if err := save.Validate(); err != nil {
+		h.logger.Warn("Invalid save file", "error", err)
+		h.writeError(w, http.StatusBadRequest, "Invalid save file: "+err.Error())
+		return
+	}
+
+	keyID := middleware.APIKeyID(r.Context())
+	if h.budget != nil && !checkBudget(w, r, h.budget, h.logger, uuid.Nil, keyID) {
+		return
+	}
+
+	gs := save.GameState
+	s, err := h.storage.GetScenario(r.Context(), gs.Scenario)
+	if err != nil {
+		h.logger.Warn("Failed to load scenario for import", "error", err, "scenario", gs.Scenario)
+		h.writeError(w, http.StatusBadRequest, "Failed to load scenario: "+err.Error())
+		return
+	}
+
+	// Keep the save's model if this server has it, otherwise use the default
+	if gs.ModelName == "" || h.checkModelAvailable(gs.ModelName) != nil {
+		gs.ModelName = h.modelName
+	}
+	if err := h.checkModelCompatibility(r.Context(), gs.ModelName, s); err != nil {
+		h.logger.Warn("Scenario rating not supported by model", "model", gs.ModelName, "rating", s.Rating)
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Saves from before the scenario renamed something, or normalized its keys, are
+	// valid once migrated
+	if gs.MigrateIDs(s) {
+		h.logger.Info("Migrated renamed scenario references in imported save", "scenario", gs.Scenario)
+	}
+
+	if fieldErrors := gs.ValidateAgainstScenario(s); len(fieldErrors) > 0 {
+		h.logger.Warn("Rejected save file that doesn't match the installed scenario", "scenario", gs.Scenario, "field_errors", len(fieldErrors))
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		response := ValidationErrorResponse{
+			Error:       "Save file doesn't match the installed scenario",
+			FieldErrors: fieldErrors,
+			RequestID:   w.Header().Get(middleware.RequestIDHeader),
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			h.logger.Error("Failed to encode error response", "error", err)
+		}
+		return
+	}
+	if save.Scenario.Version != s.Version {
+		w.Header().Set(importWarningHeader, fmt.Sprintf("Save was made with version %q of the scenario; this server has version %q", save.Scenario.Version, s.Version))
+	}
+
+	// The import is a new game here, so it gets a new ID and belongs to the caller
+	importedFrom := gs.ID
+	gs.ID = uuid.New()
+	gs.Profile = h.profile
+	gs.APIKeyID = keyID
+
+	ctx := state.WithGameEvent(r.Context(), state.GameEvent{Kind: state.EventImported})
+	if err := h.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		h.logger.Error("Failed to save imported game state", "error", err, "id", gs.ID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to import game state")
+		return
+	}
+	if h.analytics != nil {
+		if err := h.analytics.RecordGameStarted(r.Context(), gs); err != nil {
+			h.logger.Warn("Failed to record game start analytics", "error", err, "id", gs.ID.String())
+		}
+	}
+
+	h.logger.Info("Game imported", "id", gs.ID.String(), "imported_from", importedFrom.String(), "scenario", gs.Scenario)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(gs); err != nil {
+		h.logger.Error("Failed to encode imported game state response", "error", err)
+	}
+}
+
+// rejectTrial writes a 403 response and returns true if gs is a preview game. Previews
+// can't be kept, so they can't be exported, forked, or paused.
+func (h *GameStateHandler) rejectTrial(w http.ResponseWriter, gs *state.GameState, action string) bool {
+	if !gs.IsTrial() {
+		return false
+	}
+	h.logger.Info("Refused to keep a preview game", "id", gs.ID.String(), "action", action)
+	h.writeError(w, http.StatusForbidden, "Preview games can't be "+action)
+	return true
+}
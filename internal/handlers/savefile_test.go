@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_ExportImport(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	s := &scenario.Scenario{
+		Name:            "Foo",
+		Version:         "1.0.0",
+		OpeningLocation: "start",
+		Locations: map[string]scenario.Location{
+			"start": {Name: "start", Description: "Starting location"},
+		},
+		Renamed: &scenario.Renames{Locations: map[string]string{"old_start": "start"}},
+	}
+	mockStorage := storage.NewMockStorage()
+	mockStorage.AddScenario("foo_scenario.json", s)
+
+	gs := state.NewGameState("foo_scenario.json", &scenario.Narrator{ID: "classic", Name: "Classic"}, "foo_model")
+	gs.APIKeyID = "key-1"
+	gs.Location = "start"
+	gs.WorldLocations = s.Locations
+	gs.TurnCounter = 4
+	if err := mockStorage.SaveGameState(context.Background(), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+
+	handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+	// Export the game
+	req := httptest.NewRequest(http.MethodGet, "/v1/gamestate/"+gs.ID.String()+"/export", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Response body: %s", rr.Code, rr.Body.String())
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.Contains(cd, "foo_scenario-") {
+		t.Errorf("Expected an attachment named after the scenario, got %q", cd)
+	}
+	exported := rr.Body.Bytes()
+	var save state.SaveFile
+	if err := json.Unmarshal(exported, &save); err != nil {
+		t.Fatalf("Failed to decode save file: %v", err)
+	}
+	if save.Scenario.Version != "1.0.0" || save.GameState.APIKeyID != "" || save.GameState.Narrator == nil {
+		t.Errorf("Unexpected save file: %+v", save)
+	}
+
+	withSave := func(change func(*state.SaveFile)) string {
+		var copied state.SaveFile
+		if err := json.Unmarshal(exported, &copied); err != nil {
+			t.Fatalf("Failed to copy save file: %v", err)
+		}
+		change(&copied)
+		data, err := json.Marshal(copied)
+		if err != nil {
+			t.Fatalf("Failed to encode save file: %v", err)
+		}
+		return string(data)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectWarning  bool
+	}{
+		{"import", http.MethodPost, string(exported), http.StatusCreated, false},
+		{"older scenario version", http.MethodPost, withSave(func(f *state.SaveFile) { f.Scenario.Version = "0.9.0" }), http.StatusCreated, true},
+		{"scenario not installed", http.MethodPost, withSave(func(f *state.SaveFile) {
+			f.Scenario.File, f.GameState.Scenario = "missing.json", "missing.json"
+		}), http.StatusBadRequest, false},
+		{"unsupported format", http.MethodPost, withSave(func(f *state.SaveFile) { f.Format = 99 }), http.StatusBadRequest, false},
+		{"doesn't match the scenario", http.MethodPost, withSave(func(f *state.SaveFile) { f.GameState.Location = "nowhere" }), http.StatusUnprocessableEntity, false},
+		{"location renamed since the save", http.MethodPost, withSave(func(f *state.SaveFile) { f.GameState.Location = "old_start" }), http.StatusCreated, false},
+		{"invalid JSON", http.MethodPost, `{"format":`, http.StatusBadRequest, false},
+		{"wrong method", http.MethodGet, "", http.StatusMethodNotAllowed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/gamestate/import", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if got := rr.Header().Get(importWarningHeader) != ""; got != tt.expectWarning {
+				t.Errorf("Expected import warning %v, got %q", tt.expectWarning, rr.Header().Get(importWarningHeader))
+			}
+			if rr.Code != http.StatusCreated {
+				return
+			}
+
+			var imported state.GameState
+			if err := json.NewDecoder(rr.Body).Decode(&imported); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if imported.ID == gs.ID {
+				t.Error("Expected the imported game to get a new ID")
+			}
+			if imported.Location != "start" {
+				t.Errorf("Expected the player at start, got %q", imported.Location)
+			}
+			if imported.TurnCounter != 4 || imported.Narrator == nil || imported.Narrator.ID != "classic" {
+				t.Errorf("Expected the game's progress and narrator to be restored, got %+v", imported)
+			}
+			if loaded, _ := mockStorage.LoadGameState(context.Background(), imported.ID); loaded == nil {
+				t.Error("Expected the imported game to be saved")
+			}
+		})
+	}
+}
@@ -1,32 +1,132 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/scenario/validate"
+	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
 )
 
 type ScenarioHandler struct {
-	log     *slog.Logger
-	storage storage.Storage
+	log       *slog.Logger
+	storage   storage.ContentStore
+	modelName string                   // configured model, used to flag compatible scenarios
+	models    *config.ModelRegistry    // capabilities used to check compatibility
+	maxRating string                   // highest rating the profile allows
+	games     []storage.GameStateStore // game storages checked for references before a delete; defaults to storage
+	adminKey  string                   // optional; enables create, update, and delete
 }
 
-// ListScenarios lists all available scenario files
+const (
+	defaultScenarioPageSize = 50
+	maxScenarioPageSize     = 100
+	maxScenarioBytes        = 1 << 20 // largest scenario document accepted for create or update
+
+	scenarioSortName    = "name"
+	scenarioSortUpdated = "updated"
+)
+
+// ScenarioListEntry is one scenario in the listing, with whether the
+// configured model's capabilities allow its content rating
+type ScenarioListEntry struct {
+	scenario.ScenarioSummary
+	Compatible bool `json:"compatible"`
+}
+
+// ScenarioWriteResponse is the body of a successful create or update: the scenario as
+// stored, and any validation warnings worth a look
+type ScenarioWriteResponse struct {
+	FileName string             `json:"file_name"`
+	Scenario *scenario.Scenario `json:"scenario"`
+	Warnings []validate.Finding `json:"warnings"`
+}
+
+// ScenarioValidationResponse is the body of a create or update refused because the
+// scenario doesn't pass validation, with the same findings cmd/validate reports
+type ScenarioValidationResponse struct {
+	Error     string             `json:"error"`
+	Errors    []validate.Finding `json:"errors"`
+	Warnings  []validate.Finding `json:"warnings"`
+	RequestID string             `json:"request_id,omitempty"`
+}
+
+// ScenarioListResponse is a page of scenario listing entries
+type ScenarioListResponse struct {
+	Scenarios []ScenarioListEntry `json:"scenarios"`
+	Total     int                 `json:"total"`
+	Page      int                 `json:"page"`
+	PageSize  int                 `json:"page_size"`
+	Sort      string              `json:"sort"`
+}
+
+// ListScenarios lists available scenarios with metadata, one page at a time.
+// Query parameters:
+//   - page: 1-based page number (default 1)
+//   - page_size: entries per page (default 50, max 100)
+//   - sort: "name" (default) or "updated" for most recently updated first
+//   - format=map: return the legacy name to filename map instead
 func (h *ScenarioHandler) ListScenarios(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	query := r.URL.Query()
+
+	page, err := parsePositiveInt(query.Get("page"), 1)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid page: "+query.Get("page"))
+		return
+	}
+	pageSize, err := parsePositiveInt(query.Get("page_size"), defaultScenarioPageSize)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid page_size: "+query.Get("page_size"))
+		return
+	}
+	pageSize = min(pageSize, maxScenarioPageSize)
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = scenarioSortName
+	}
+	if sortBy != scenarioSortName && sortBy != scenarioSortUpdated {
+		h.writeError(w, http.StatusBadRequest, "Invalid sort: "+sortBy+". Supported values: name, updated")
+		return
+	}
+
 	scenarios, err := h.storage.ListScenarios(ctx)
 	if err != nil {
 		h.log.Error("Failed to list scenarios", "error", err)
-		http.Error(w, "Failed to list scenarios", http.StatusInternalServerError)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list scenarios")
 		return
 	}
-	data, err := json.Marshal(scenarios)
+
+	var body interface{} = scenarios
+	if query.Get("format") != "map" {
+		entries := h.listEntries(ctx, scenarios, sortBy)
+		start := min((page-1)*pageSize, len(entries))
+		end := min(start+pageSize, len(entries))
+		body = ScenarioListResponse{
+			Scenarios: entries[start:end],
+			Total:     len(entries),
+			Page:      page,
+			PageSize:  pageSize,
+			Sort:      sortBy,
+		}
+	}
+
+	data, err := json.Marshal(body)
 	if err != nil {
 		h.log.Error("Failed to marshal scenario list", "error", err)
-		http.Error(w, "Failed to process scenario list", http.StatusInternalServerError)
+		h.writeError(w, http.StatusInternalServerError, "Failed to process scenario list")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -36,21 +136,133 @@ func (h *ScenarioHandler) ListScenarios(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-func NewScenarioHandler(log *slog.Logger, storage storage.Storage) *ScenarioHandler {
+// listEntries loads each listed scenario and returns its metadata in the requested order.
+// Scenarios that fail to load are logged and skipped.
+func (h *ScenarioHandler) listEntries(ctx context.Context, scenarios map[string]string, sortBy string) []ScenarioListEntry {
+	entries := make([]ScenarioListEntry, 0, len(scenarios))
+	for _, filename := range scenarios {
+		s, err := h.storage.GetScenario(ctx, filename)
+		if err != nil {
+			h.log.Warn("Failed to load scenario for listing", "error", err, "filename", filename)
+			continue
+		}
+		entries = append(entries, ScenarioListEntry{
+			ScenarioSummary: s.Summary(filename),
+			Compatible:      h.models.Lookup(h.modelName).LimitRatings(h.maxRating).AllowsRating(s.Rating),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if sortBy == scenarioSortUpdated && !entries[i].UpdatedAt.Equal(entries[j].UpdatedAt) {
+			return entries[i].UpdatedAt.After(entries[j].UpdatedAt)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// WithModelName sets the configured model used to flag compatible scenarios in listings
+func (h *ScenarioHandler) WithModelName(modelName string) *ScenarioHandler {
+	h.modelName = modelName
+	return h
+}
+
+// WithModelRegistry sets the model capabilities used to check scenario compatibility
+func (h *ScenarioHandler) WithModelRegistry(models *config.ModelRegistry) *ScenarioHandler {
+	h.models = models
+	return h
+}
+
+// WithProfile applies a profile's model and rating limit to compatibility checks
+func (h *ScenarioHandler) WithProfile(profile config.Profile) *ScenarioHandler {
+	h.modelName = profile.ModelName
+	h.maxRating = profile.MaxRating
+	return h
+}
+
+// WithAdminKey enables creating, updating, and deleting scenarios, authenticated
+// by the X-Admin-Key header. Without it scenarios are read-only.
+func (h *ScenarioHandler) WithAdminKey(adminKey string) *ScenarioHandler {
+	h.adminKey = adminKey
+	return h
+}
+
+// WithGameStorages sets the game storages searched for games still playing a
+// scenario before it is deleted, e.g. one per profile
+func (h *ScenarioHandler) WithGameStorages(games ...storage.GameStateStore) *ScenarioHandler {
+	h.games = games
+	return h
+}
+
+func (h *ScenarioHandler) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(newErrorResponse(w, message)); err != nil {
+		h.log.Error("Failed to encode error response", "error", err)
+	}
+}
+
+// parsePositiveInt parses a positive integer query value, returning def when empty
+func parsePositiveInt(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("must be a positive integer: %q", value)
+	}
+	return n, nil
+}
+
+func NewScenarioHandler(log *slog.Logger, storage storage.ContentStore) *ScenarioHandler {
 	return &ScenarioHandler{
 		log:     log,
 		storage: storage,
+		models:  config.NewModelRegistry(nil),
 	}
 }
 
+// ServeHTTP handles HTTP requests for scenarios
+// Routes:
+// GET /v1/scenarios               - List scenarios
+// GET /v1/scenarios/{filename}    - Read a scenario
+// POST /v1/scenarios              - Create a scenario stored as its file_name (admin only)
+// PUT /v1/scenarios/{filename}    - Replace a scenario (admin only)
+// DELETE /v1/scenarios/{filename} - Delete a scenario no active game is playing (admin only)
+//
+// Writes go wherever the storage keeps scenarios: the local data directory, or Redis
+// when scenario_store is "redis" (see storage.RedisStorage.WithScenariosInRedis).
 func (h *ScenarioHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
+	if r.Method == http.MethodGet {
 		if r.URL.Path == "/v1/scenarios" || r.URL.Path == "/v1/scenarios/" {
 			h.ListScenarios(w, r)
 		} else {
 			h.handleGet(w, r)
 		}
+		return
+	}
+
+	filename, err := resourceID(r.URL.Path, "/v1/scenarios")
+	if err != nil {
+		http.Error(w, "Invalid filename", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && filename == "",
+		r.Method == http.MethodPut && filename != "",
+		r.Method == http.MethodDelete && filename != "":
+		if !hasAdminKey(r, h.adminKey) {
+			h.log.Warn("Scenario change refused: missing or invalid admin key", "method", r.Method)
+			http.Error(w, "A valid admin key is required", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			h.handleCreate(w, r)
+		case http.MethodPut:
+			h.handleUpdate(w, r, filename)
+		default:
+			h.handleDelete(w, r, filename)
+		}
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
@@ -95,3 +307,197 @@ func (h *ScenarioHandler) handleGet(w http.ResponseWriter, r *http.Request) {
 		h.log.Error("Failed to write response", "error", err, "filename", filename)
 	}
 }
+
+func (h *ScenarioHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readBody(w, r)
+	if !ok {
+		return
+	}
+	var named struct {
+		FileName string `json:"file_name"`
+	}
+	if err := json.Unmarshal(body, &named); err != nil || strings.TrimSpace(named.FileName) == "" {
+		http.Error(w, "Scenario file_name is required, e.g. \"pirate.json\"", http.StatusBadRequest)
+		return
+	}
+	filename := named.FileName
+	if _, err := resourceID(filename, ""); err != nil {
+		http.Error(w, "Invalid filename", http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.storage.GetScenario(r.Context(), filename)
+	switch {
+	case err == nil:
+		http.Error(w, fmt.Sprintf("Scenario %s already exists", filename), http.StatusConflict)
+		return
+	case !strings.Contains(err.Error(), "not found"):
+		h.log.Error("Failed to check for an existing scenario", "error", err, "filename", filename)
+		http.Error(w, "Failed to check for an existing scenario", http.StatusInternalServerError)
+		return
+	}
+
+	s, result, ok := h.validateScenario(w, r, filename, body)
+	if !ok {
+		return
+	}
+	if err := h.storage.SaveScenario(r.Context(), filename, s); err != nil {
+		h.log.Error("Failed to save scenario", "error", err, "filename", filename)
+		writeContentError(w, err, "Failed to save scenario")
+		return
+	}
+	h.log.Info("Scenario created", "filename", filename, "warnings", len(result.Warnings))
+	h.writeScenario(w, http.StatusCreated, filename, s, result)
+}
+
+func (h *ScenarioHandler) handleUpdate(w http.ResponseWriter, r *http.Request, filename string) {
+	body, ok := h.readBody(w, r)
+	if !ok {
+		return
+	}
+	var named struct {
+		FileName string `json:"file_name"`
+	}
+	if err := json.Unmarshal(body, &named); err == nil && named.FileName != "" && named.FileName != filename {
+		http.Error(w, "Scenario file_name in body does not match the URL", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.storage.GetScenario(r.Context(), filename); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, "Scenario not found", http.StatusNotFound)
+			return
+		}
+		// A scenario that no longer loads can still be fixed by replacing it
+		h.log.Warn("Replacing a scenario that fails to load", "error", err, "filename", filename)
+	}
+
+	s, result, ok := h.validateScenario(w, r, filename, body)
+	if !ok {
+		return
+	}
+	if err := h.storage.SaveScenario(r.Context(), filename, s); err != nil {
+		h.log.Error("Failed to save scenario", "error", err, "filename", filename)
+		writeContentError(w, err, "Failed to save scenario")
+		return
+	}
+	h.log.Info("Scenario updated", "filename", filename, "warnings", len(result.Warnings))
+	h.writeScenario(w, http.StatusOK, filename, s, result)
+}
+
+func (h *ScenarioHandler) handleDelete(w http.ResponseWriter, r *http.Request, filename string) {
+	if _, err := h.storage.GetScenario(r.Context(), filename); err != nil && strings.Contains(err.Error(), "not found") {
+		http.Error(w, "Scenario not found", http.StatusNotFound)
+		return
+	}
+
+	refs, err := findReferences(r.Context(), h.storage, h.gameStorages(),
+		func(*scenario.Scenario) bool { return false },
+		func(gs *state.GameState) bool { return gs.Scenario == filename },
+	)
+	if err != nil {
+		h.log.Error("Failed to check scenario references", "error", err, "filename", filename)
+		http.Error(w, "Failed to check scenario references", http.StatusInternalServerError)
+		return
+	}
+	if refs.inUse() {
+		http.Error(w, fmt.Sprintf("Scenario %s is still played by %s", filename, refs), http.StatusConflict)
+		return
+	}
+
+	if err := h.storage.DeleteScenario(r.Context(), filename); err != nil {
+		h.log.Error("Failed to delete scenario", "error", err, "filename", filename)
+		writeContentError(w, err, "Failed to delete scenario")
+		return
+	}
+	h.log.Info("Scenario deleted", "filename", filename)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readBody reads a scenario document from the request body, writing the error
+// response and returning false if it can't be read
+func (h *ScenarioHandler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxScenarioBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Scenario is larger than %d bytes", maxScenarioBytes), http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
+// validateScenario runs the cmd/validate checks on a scenario document to be stored
+// as filename, and decodes it. It writes a 422 response with the findings and returns
+// false if the scenario is invalid, or if its name is taken by another scenario file.
+func (h *ScenarioHandler) validateScenario(w http.ResponseWriter, r *http.Request, filename string, body []byte) (*scenario.Scenario, *validate.Result, bool) {
+	result := validate.JSON(filename, body)
+	if !result.Valid() {
+		h.log.Warn("Rejected invalid scenario", "filename", filename, "errors", len(result.Errors))
+		h.writeValidationError(w, "Scenario failed validation", result)
+		return nil, nil, false
+	}
+
+	// Stored as written; keys are normalized when the scenario is loaded
+	var s scenario.Scenario
+	if err := json.Unmarshal(body, &s); err != nil {
+		http.Error(w, "Invalid scenario JSON: "+err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	// Listings are keyed by name, so a second file with the same name would hide one
+	scenarios, err := h.storage.ListScenarios(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list scenarios", "error", err)
+		http.Error(w, "Failed to list scenarios", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if other, ok := scenarios[s.Name]; ok && other != filename {
+		http.Error(w, fmt.Sprintf("Scenario name %q is already used by %s", s.Name, other), http.StatusConflict)
+		return nil, nil, false
+	}
+	return &s, result, true
+}
+
+func (h *ScenarioHandler) writeValidationError(w http.ResponseWriter, message string, result *validate.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	response := ScenarioValidationResponse{
+		Error:     message,
+		Errors:    result.Errors,
+		Warnings:  result.Warnings,
+		RequestID: w.Header().Get(middleware.RequestIDHeader),
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.log.Error("Failed to encode validation response", "error", err)
+	}
+}
+
+func (h *ScenarioHandler) writeScenario(w http.ResponseWriter, status int, filename string, s *scenario.Scenario, result *validate.Result) {
+	data, err := json.Marshal(ScenarioWriteResponse{FileName: filename, Scenario: s, Warnings: result.Warnings})
+	if err != nil {
+		h.log.Error("Failed to marshal scenario", "error", err, "filename", filename)
+		http.Error(w, "Failed to process scenario", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		h.log.Error("Failed to write response", "error", err, "filename", filename)
+	}
+}
+
+// gameStorages returns the game storages to check for references, defaulting to
+// the content storage when it also holds game states
+func (h *ScenarioHandler) gameStorages() []storage.GameStateStore {
+	if len(h.games) == 0 {
+		if games, ok := h.storage.(storage.GameStateStore); ok {
+			return []storage.GameStateStore{games}
+		}
+	}
+	return h.games
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
@@ -8,9 +9,12 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jwebster45206/story-engine/pkg/actor"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
 )
 
@@ -65,3 +69,235 @@ func TestScenarioHandler_ServeHTTP(t *testing.T) {
 		t.Errorf("Expected scenario name to contain 'Pirate', got %q", response.Name)
 	}
 }
+
+func TestScenarioHandler_ListScenarios(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	now := time.Now()
+	mockSt := storage.NewMockStorage()
+	mockSt.AddScenario("pirate.json", &scenario.Scenario{
+		Name:           "Pirate Adventure",
+		Story:          "A swashbuckling adventure on the high seas",
+		Synopsis:       "Sail for treasure.",
+		Rating:         scenario.RatingPG13,
+		Tags:           []string{"pirates"},
+		Author:         "Jane Doe",
+		Version:        "1.0.0",
+		EstimatedTurns: 60,
+		UpdatedAt:      now.Add(-time.Hour),
+	})
+	mockSt.AddScenario("castle.json", &scenario.Scenario{
+		Name:      "Castle",
+		Story:     "A gothic horror",
+		Rating:    scenario.RatingR,
+		UpdatedAt: now.Add(-48 * time.Hour),
+	})
+	mockSt.AddScenario("station.json", &scenario.Scenario{
+		Name:      "Station",
+		Rating:    scenario.RatingG,
+		UpdatedAt: now,
+	})
+
+	handler := NewScenarioHandler(logger, mockSt).WithModelName("claude-sonnet")
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedNames  []string
+		expectedTotal  int
+	}{
+		{"default sorts by name", "", http.StatusOK, []string{"Castle", "Pirate Adventure", "Station"}, 3},
+		{"recently updated first", "?sort=updated", http.StatusOK, []string{"Station", "Pirate Adventure", "Castle"}, 3},
+		{"first page", "?page_size=2", http.StatusOK, []string{"Castle", "Pirate Adventure"}, 3},
+		{"second page", "?page_size=2&page=2", http.StatusOK, []string{"Station"}, 3},
+		{"page past the end", "?page=5", http.StatusOK, []string{}, 3},
+		{"invalid page", "?page=0", http.StatusBadRequest, nil, 0},
+		{"invalid page size", "?page_size=abc", http.StatusBadRequest, nil, 0},
+		{"invalid sort", "?sort=rating", http.StatusBadRequest, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/scenarios"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response ScenarioListResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response.Total != tt.expectedTotal {
+				t.Errorf("Expected total %d, got %d", tt.expectedTotal, response.Total)
+			}
+			var names []string
+			for _, e := range response.Scenarios {
+				names = append(names, e.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expectedNames, ",") {
+				t.Errorf("Expected %v, got %v", tt.expectedNames, names)
+			}
+		})
+	}
+
+	t.Run("entries include metadata and compatibility", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/v1/scenarios", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		var response ScenarioListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		castle, pirate := response.Scenarios[0], response.Scenarios[1]
+		if castle.Compatible {
+			t.Error("Expected R-rated scenario to be incompatible with a censored model")
+		}
+		if castle.Synopsis != "A gothic horror" {
+			t.Errorf("Expected story as synopsis fallback, got %q", castle.Synopsis)
+		}
+		if !pirate.Compatible {
+			t.Error("Expected PG-13 scenario to be compatible")
+		}
+		if pirate.FileName != "pirate.json" || pirate.Synopsis != "Sail for treasure." ||
+			pirate.Author != "Jane Doe" || pirate.Version != "1.0.0" || pirate.EstimatedTurns != 60 {
+			t.Errorf("Unexpected pirate entry: %+v", pirate)
+		}
+	})
+
+	t.Run("legacy map format", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/v1/scenarios?format=map", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		var response map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if response["Pirate Adventure"] != "pirate.json" {
+			t.Errorf("Expected Pirate Adventure to map to pirate.json, got %v", response)
+		}
+	})
+}
+
+const tinyTaleJSON = `{
+  "name": "Tiny Tale",
+  "file_name": "tiny_tale.json",
+  "story": "A short story.",
+  "rating": "G",
+  "opening_location": "hall",
+  "locations": {
+    "hall": {"name": "Hall", "description": "A quiet hall.", "exits": {"north": "yard"}},
+    "yard": {"name": "Yard", "description": "An empty yard.", "exits": {"south": "hall"}}
+  }
+}`
+
+func TestScenarioHandler_CRUD(t *testing.T) {
+	const adminKey = "secret"
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	renamed := strings.Replace(tinyTaleJSON, `"tiny_tale.json"`, `"tiny_tale_2.json"`, 1)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		key        string
+		wantStatus int
+	}{
+		{"create without admin key", http.MethodPost, "/v1/scenarios", tinyTaleJSON, "", http.StatusUnauthorized},
+		{"create", http.MethodPost, "/v1/scenarios", tinyTaleJSON, adminKey, http.StatusCreated},
+		{"create existing", http.MethodPost, "/v1/scenarios", strings.Replace(tinyTaleJSON, "tiny_tale.json", "pirate.json", 1), adminKey, http.StatusConflict},
+		{"create missing file_name", http.MethodPost, "/v1/scenarios", `{"name":"Tiny Tale"}`, adminKey, http.StatusBadRequest},
+		{"create bad filename", http.MethodPost, "/v1/scenarios", strings.Replace(tinyTaleJSON, "tiny_tale.json", "Tiny-Tale.json", 1), adminKey, http.StatusUnprocessableEntity},
+		{"create unknown field", http.MethodPost, "/v1/scenarios", strings.Replace(tinyTaleJSON, `"story"`, `"stroy"`, 1), adminKey, http.StatusUnprocessableEntity},
+		{"create undefined exit", http.MethodPost, "/v1/scenarios", strings.Replace(tinyTaleJSON, `{"north": "yard"}`, `{"north": "garden"}`, 1), adminKey, http.StatusUnprocessableEntity},
+		{"create duplicate name", http.MethodPost, "/v1/scenarios", strings.Replace(renamed, "Tiny Tale", "Pirate Adventure", 1), adminKey, http.StatusConflict},
+		{"update", http.MethodPut, "/v1/scenarios/pirate.json", strings.Replace(tinyTaleJSON, `"file_name": "tiny_tale.json",`, "", 1), adminKey, http.StatusOK},
+		{"update file_name mismatch", http.MethodPut, "/v1/scenarios/pirate.json", tinyTaleJSON, adminKey, http.StatusBadRequest},
+		{"update missing", http.MethodPut, "/v1/scenarios/tiny_tale.json", tinyTaleJSON, adminKey, http.StatusNotFound},
+		{"delete", http.MethodDelete, "/v1/scenarios/pirate.json", "", adminKey, http.StatusNoContent},
+		{"delete missing", http.MethodDelete, "/v1/scenarios/tiny_tale.json", "", adminKey, http.StatusNotFound},
+		{"delete collection", http.MethodDelete, "/v1/scenarios", "", adminKey, http.StatusMethodNotAllowed},
+		{"traversal", http.MethodPut, "/v1/scenarios/../secrets.json", tinyTaleJSON, adminKey, http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSt := storage.NewMockStorage()
+			mockSt.AddScenario("pirate.json", &scenario.Scenario{Name: "Pirate Adventure"})
+			handler := NewScenarioHandler(logger, mockSt).WithAdminKey(adminKey)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.key != "" {
+				req.Header.Set("X-Admin-Key", tc.key)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tc.wantStatus, w.Body.String())
+			}
+			switch tc.wantStatus {
+			case http.StatusCreated:
+				s, err := mockSt.GetScenario(req.Context(), "tiny_tale.json")
+				if err != nil || s.OpeningLocation != "hall" {
+					t.Errorf("created scenario = %+v, %v", s, err)
+				}
+			case http.StatusUnprocessableEntity:
+				var response ScenarioValidationResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if len(response.Errors) == 0 || response.Errors[0].Message == "" {
+					t.Errorf("expected validation errors, got %+v", response)
+				}
+			}
+		})
+	}
+}
+
+func TestScenarioHandler_DeleteReferenced(t *testing.T) {
+	const adminKey = "secret"
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	tests := []struct {
+		name       string
+		game       *state.GameState
+		wantStatus int
+	}{
+		{"scenario of an active game", &state.GameState{ID: uuid.New(), Scenario: "pirate.json"}, http.StatusConflict},
+		{"scenario of an ended game", &state.GameState{ID: uuid.New(), Scenario: "pirate.json", IsEnded: true}, http.StatusNoContent},
+		{"another scenario's game", &state.GameState{ID: uuid.New(), Scenario: "dracula.json"}, http.StatusNoContent},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSt := storage.NewMockStorage()
+			mockSt.AddScenario("pirate.json", &scenario.Scenario{Name: "Pirate Adventure"})
+			games := storage.NewMockStorage()
+			if err := games.SaveGameState(context.Background(), tc.game.ID, tc.game); err != nil {
+				t.Fatalf("SaveGameState: %v", err)
+			}
+			handler := NewScenarioHandler(logger, mockSt).WithAdminKey(adminKey).WithGameStorages(games)
+
+			req := httptest.NewRequest(http.MethodDelete, "/v1/scenarios/pirate.json", nil)
+			req.Header.Set("X-Admin-Key", adminKey)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, tc.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
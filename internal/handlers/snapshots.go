@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// SnapshotRequest is the body for taking a named snapshot of a game
+type SnapshotRequest struct {
+	Name string `json:"name"` // Save slot name; saving with an existing name replaces that snapshot
+}
+
+// SnapshotsResponse lists a game's snapshots, oldest first, without their game states
+type SnapshotsResponse struct {
+	GameStateID uuid.UUID        `json:"gamestate_id"`
+	Snapshots   []state.Snapshot `json:"snapshots"`
+}
+
+// snapshotStorage returns the handler's storage as snapshot storage, writing a 404
+// response if it can't keep snapshots
+func (h *GameStateHandler) snapshotStorage(w http.ResponseWriter) (storage.Snapshots, bool) {
+	snapshots, ok := h.storage.(storage.Snapshots)
+	if !ok {
+		h.writeError(w, http.StatusNotFound, "Snapshots are not supported by this server.")
+		return nil, false
+	}
+	return snapshots, true
+}
+
+// handleSnapshots lists a game's snapshots on GET, or takes a new one on POST
+func (h *GameStateHandler) handleSnapshots(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleListSnapshots(w, r, gameStateID)
+	case http.MethodPost:
+		h.handleTakeSnapshot(w, r, gameStateID)
+	default:
+		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Supported methods: GET, POST")
+	}
+}
+
+func (h *GameStateHandler) handleListSnapshots(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	snapshots, ok := h.snapshotStorage(w)
+	if !ok {
+		return
+	}
+	if _, ok := h.loadGameState(w, r, gameStateID); !ok {
+		return
+	}
+
+	list, err := snapshots.ListSnapshots(r.Context(), gameStateID)
+	if err != nil {
+		h.logger.Error("Failed to list snapshots", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to list snapshots")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SnapshotsResponse{GameStateID: gameStateID, Snapshots: list}); err != nil {
+		h.logger.Error("Failed to encode snapshots response", "error", err)
+	}
+}
+
+// handleTakeSnapshot saves the game as it is now under a name, replacing any snapshot
+// with the same name. A game keeps at most state.MaxSnapshots.
+func (h *GameStateHandler) handleTakeSnapshot(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	var req SnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid JSON in snapshot request body", "error", err)
+		h.writeError(w, http.StatusBadRequest, "Invalid JSON in request body")
+		return
+	}
+
+	snapshots, ok := h.snapshotStorage(w)
+	if !ok {
+		return
+	}
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok || h.rejectTrial(w, gs, "saved") {
+		return
+	}
+
+	snap, err := state.NewSnapshot(req.Name, gs)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid snapshot: "+err.Error())
+		return
+	}
+
+	existing, err := snapshots.ListSnapshots(r.Context(), gameStateID)
+	if err != nil {
+		h.logger.Error("Failed to list snapshots", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to save snapshot")
+		return
+	}
+	replacing := false
+	for _, s := range existing {
+		replacing = replacing || s.Name == snap.Name
+	}
+	if !replacing && len(existing) >= state.MaxSnapshots {
+		h.writeError(w, http.StatusConflict, "A game can keep at most "+strconv.Itoa(state.MaxSnapshots)+" snapshots. Save over an existing one instead.")
+		return
+	}
+
+	if err := snapshots.SaveSnapshot(r.Context(), gameStateID, snap); err != nil {
+		h.logger.Error("Failed to save snapshot", "error", err, "id", gameStateID.String(), "snapshot", snap.Name)
+		h.writeError(w, http.StatusInternalServerError, "Failed to save snapshot")
+		return
+	}
+
+	h.logger.Info("Snapshot saved", "id", gameStateID.String(), "snapshot", snap.Name, "turn", snap.Turn)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(snap.Summary()); err != nil {
+		h.logger.Error("Failed to encode snapshot response", "error", err)
+	}
+}
+
+// handleRestore rolls a game back to one of its snapshots. The snapshot is kept, so
+// the game can be restored to it again.
+func (h *GameStateHandler) handleRestore(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID, name string) {
+	if name == "" {
+		h.writeError(w, http.StatusBadRequest, "Snapshot name is required")
+		return
+	}
+	snapshots, ok := h.snapshotStorage(w)
+	if !ok {
+		return
+	}
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+
+	snap, err := snapshots.LoadSnapshot(r.Context(), gameStateID, name)
+	if err != nil {
+		h.logger.Error("Failed to load snapshot", "error", err, "id", gameStateID.String(), "snapshot", name)
+		h.writeError(w, http.StatusInternalServerError, "Failed to load snapshot")
+		return
+	}
+	if snap == nil {
+		h.writeError(w, http.StatusNotFound, "Snapshot not found: "+name)
+		return
+	}
+
+	restored, err := snap.Restore(gs)
+	if err != nil {
+		h.logger.Error("Failed to restore snapshot", "error", err, "id", gameStateID.String(), "snapshot", name)
+		h.writeError(w, http.StatusInternalServerError, "Failed to restore snapshot")
+		return
+	}
+	ctx := state.WithGameEvent(r.Context(), state.GameEvent{Kind: state.EventRestored})
+	if err := h.storage.SaveGameState(ctx, gameStateID, restored); err != nil {
+		h.logger.Error("Failed to save restored game state", "error", err, "id", gameStateID.String())
+		h.writeError(w, http.StatusInternalServerError, "Failed to save game state")
+		return
+	}
+
+	h.logger.Info("Game restored from snapshot", "id", gameStateID.String(), "snapshot", name, "turn", restored.TurnCounter)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(restored); err != nil {
+		h.logger.Error("Failed to encode restored game state response", "error", err)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_Snapshots(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	mockStorage := storage.NewMockStorage()
+	handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+	ctx := context.Background()
+
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	gs.Location = "start"
+	gs.TurnCounter = 2
+	if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+	base := "/v1/gamestate/" + gs.ID.String()
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Save a snapshot, then play on
+	rr := serve(http.MethodPost, base+"/snapshots", `{"name": "Before the storm"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d. Response body: %s", rr.Code, rr.Body.String())
+	}
+	gs.Location = "ship"
+	gs.TurnCounter = 5
+
+	rr = serve(http.MethodGet, base+"/snapshots", "")
+	var list SnapshotsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(list.Snapshots) != 1 || list.Snapshots[0].Name != "Before the storm" || list.Snapshots[0].Turn != 2 {
+		t.Errorf("Unexpected snapshots: %+v", list.Snapshots)
+	}
+
+	rr = serve(http.MethodPost, base+"/restore/Before%20the%20storm", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Response body: %s", rr.Code, rr.Body.String())
+	}
+	restored, _ := mockStorage.LoadGameState(ctx, gs.ID)
+	if restored.Location != "start" || restored.TurnCounter != 2 || restored.ID != gs.ID {
+		t.Errorf("Expected the game rolled back to the snapshot, got %+v", restored)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"save over an existing snapshot", http.MethodPost, "/snapshots", `{"name": "Before the storm"}`, http.StatusCreated},
+		{"invalid name", http.MethodPost, "/snapshots", `{"name": "../etc"}`, http.StatusBadRequest},
+		{"missing name", http.MethodPost, "/snapshots", `{}`, http.StatusBadRequest},
+		{"invalid JSON", http.MethodPost, "/snapshots", `{"name":`, http.StatusBadRequest},
+		{"wrong method", http.MethodDelete, "/snapshots", "", http.StatusMethodNotAllowed},
+		{"restore missing snapshot", http.MethodPost, "/restore/missing", "", http.StatusNotFound},
+		{"restore without a name", http.MethodPost, "/restore", "", http.StatusBadRequest},
+		{"restore with GET", http.MethodGet, "/restore/Before%20the%20storm", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serve(tt.method, base+tt.path, tt.body)
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_SnapshotLimit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	mockStorage := storage.NewMockStorage()
+	handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	if err := mockStorage.SaveGameState(context.Background(), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+	save := func(name string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/gamestate/"+gs.ID.String()+"/snapshots", bytes.NewBufferString(`{"name": "`+name+`"}`))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	for i := range state.MaxSnapshots {
+		if code := save(fmt.Sprintf("slot %d", i)); code != http.StatusCreated {
+			t.Fatalf("Expected snapshot %d to be saved, got status %d", i, code)
+		}
+	}
+	if code := save("one too many"); code != http.StatusConflict {
+		t.Errorf("Expected status 409 past the limit, got %d", code)
+	}
+	if code := save("slot 0"); code != http.StatusCreated {
+		t.Errorf("Expected saving over a snapshot at the limit to succeed, got %d", code)
+	}
+}
+
+func TestGameStateHandler_SnapshotsUnsupported(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+	mockStorage := storage.NewMockStorage()
+	handler := NewGameStateHandler(logger, "foo_model", storage.Combine(mockStorage, mockStorage))
+
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	if err := mockStorage.SaveGameState(context.Background(), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/gamestate/"+gs.ID.String()+"/snapshots", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 from storage without snapshots, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/services/events"
+)
+
+// SpectatorSource reads a game's spectator feed. It is implemented by events.SpectatorFeed.
+type SpectatorSource interface {
+	Since(ctx context.Context, gameID uuid.UUID, after string, until time.Time) ([]events.Narration, error)
+}
+
+// SpectatorHandler streams the narration of public games to spectators over Server-Sent
+// Events, delayed so spectators can't relay a turn to the player while it matters.
+// It needs no API key: only games created with spectators enabled publish anything.
+type SpectatorHandler struct {
+	feed      SpectatorSource
+	delay     time.Duration
+	poll      time.Duration // how often the feed is checked for narration that has aged enough
+	heartbeat time.Duration // how often a ping event is sent
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewSpectatorHandler creates a new spectator handler. Narration is sent once it is
+// delay old.
+func NewSpectatorHandler(feed SpectatorSource, delay time.Duration, logger *slog.Logger) *SpectatorHandler {
+	return &SpectatorHandler{
+		feed:      feed,
+		delay:     delay,
+		poll:      time.Second,
+		heartbeat: config.DefaultHeartbeatInterval,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// WithHeartbeat sets how often a ping event is sent
+func (h *SpectatorHandler) WithHeartbeat(interval time.Duration) *SpectatorHandler {
+	h.heartbeat = interval
+	return h
+}
+
+// ServeHTTP handles SSE requests for the spectator feed
+// GET /v1/spectate/{gameStateID}
+// A reconnecting client's Last-Event-ID header resumes the feed after that narration;
+// otherwise it starts from the delayed present.
+func (h *SpectatorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed. Only GET is supported.")
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/v1/spectate/")
+	if idStr == "" || strings.Contains(idStr, "/") {
+		h.writeError(w, http.StatusBadRequest, "Invalid path. Expected /v1/spectate/{gameStateID}")
+		return
+	}
+	gameStateID, err := uuid.Parse(idStr)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid game state ID format.")
+		return
+	}
+
+	cursor := r.Header.Get("Last-Event-ID")
+	if cursor == "" {
+		cursor = events.FeedPosition(h.now().Add(-h.delay))
+	}
+
+	h.logger.Info("Spectator connected", "game_state_id", gameStateID.String(), "remote_addr", r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	h.sendSSE(w, "", "connected", map[string]interface{}{
+		"game_id":           gameStateID.String(),
+		"delay_seconds":     int(h.delay.Seconds()),
+		"heartbeat_seconds": int(h.heartbeat.Seconds()),
+	})
+
+	pollTicker := time.NewTicker(h.poll)
+	defer pollTicker.Stop()
+	// Heartbeats keep proxies and clients from closing the stream while the LLM thinks
+	heartbeatTicker := time.NewTicker(h.heartbeat)
+	defer heartbeatTicker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			h.logger.Info("Spectator disconnected", "game_state_id", gameStateID.String())
+			return
+
+		case <-pollTicker.C:
+			narrations, err := h.feed.Since(r.Context(), gameStateID, cursor, h.now().Add(-h.delay))
+			if err != nil {
+				if r.Context().Err() == nil {
+					h.logger.Error("Failed to read spectator feed", "error", err, "game_state_id", gameStateID.String())
+				}
+				continue
+			}
+			for _, n := range narrations {
+				h.sendSSE(w, n.ID, "narration", n)
+				cursor = n.ID
+			}
+
+		case <-heartbeatTicker.C:
+			h.sendSSE(w, "", "ping", map[string]interface{}{
+				"time": h.now().UTC().Format(time.RFC3339),
+			})
+		}
+	}
+}
+
+// sendSSE sends a Server-Sent Event, with an ID when there is one
+func (h *SpectatorHandler) sendSSE(w http.ResponseWriter, id, eventType string, data interface{}) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		h.logger.Error("Failed to marshal SSE data", "error", err)
+		return
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			h.logger.Error("Failed to write event ID", "error", err)
+			return
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, dataJSON); err != nil {
+		h.logger.Error("Failed to write event", "error", err)
+		return
+	}
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (h *SpectatorHandler) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(newErrorResponse(w, message)); err != nil {
+		h.logger.Error("Failed to encode error response", "error", err)
+	}
+}
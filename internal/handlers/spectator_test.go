@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/services/events"
+)
+
+// stubSpectatorFeed serves a fixed feed, honouring the cursor and the delay
+type stubSpectatorFeed struct {
+	narrations []events.Narration
+}
+
+func (s *stubSpectatorFeed) Since(_ context.Context, _ uuid.UUID, after string, until time.Time) ([]events.Narration, error) {
+	var out []events.Narration
+	for _, n := range s.narrations {
+		if n.ID > after && !n.At.After(until) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func TestSpectatorHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	feed := &stubSpectatorFeed{narrations: []events.Narration{
+		{ID: events.FeedPosition(now.Add(-2 * time.Minute)), Text: "Before you tuned in.", At: now.Add(-2 * time.Minute)},
+		{ID: events.FeedPosition(now.Add(-20 * time.Second)), Text: "The ship sets sail.", At: now.Add(-20 * time.Second)},
+		{ID: events.FeedPosition(now.Add(-5 * time.Second)), Text: "A storm gathers.", At: now.Add(-5 * time.Second)},
+	}}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		lastEventID    string
+		expectedStatus int
+		expected       []string
+		unexpected     []string
+	}{
+		{
+			name:           "delayed narration",
+			method:         http.MethodGet,
+			path:           "/v1/spectate/" + uuid.New().String(),
+			expectedStatus: http.StatusOK,
+			expected:       []string{"event: connected", `"delay_seconds":30`, "event: narration", "The ship sets sail."},
+			unexpected:     []string{"Before you tuned in.", "A storm gathers."},
+		},
+		{
+			name:           "resume after last event",
+			method:         http.MethodGet,
+			path:           "/v1/spectate/" + uuid.New().String(),
+			lastEventID:    feed.narrations[0].ID,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"id: " + feed.narrations[1].ID, "The ship sets sail."},
+			unexpected:     []string{"Before you tuned in.", "A storm gathers."},
+		},
+		{name: "invalid game ID", method: http.MethodGet, path: "/v1/spectate/not-a-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "extra path", method: http.MethodGet, path: "/v1/spectate/" + uuid.New().String() + "/chat", expectedStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, path: "/v1/spectate/" + uuid.New().String(), expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSpectatorHandler(feed, 30*time.Second, logger)
+			handler.poll = 5 * time.Millisecond
+			// The spectator connects at now; by the first poll 20 seconds have passed
+			clock := now
+			handler.now = func() time.Time {
+				t := clock
+				clock = now.Add(20 * time.Second)
+				return t
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			req := httptest.NewRequest(tt.method, tt.path, nil).WithContext(ctx)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			body := rr.Body.String()
+			for _, want := range tt.expected {
+				if !strings.Contains(body, want) {
+					t.Errorf("Expected stream to contain %q, got %s", want, body)
+				}
+			}
+			for _, unwanted := range tt.unexpected {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("Expected stream not to contain %q, got %s", unwanted, body)
+				}
+			}
+			if strings.Count(body, "event: narration") > 1 {
+				t.Errorf("Expected each narration to be sent once, got %s", body)
+			}
+		})
+	}
+}
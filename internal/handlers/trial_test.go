@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_CreateTrial(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedTrial  *state.Trial
+	}{
+		{name: "full game", requestBody: `{"scenario":"foo_scenario.json"}`, expectedStatus: http.StatusCreated},
+		{name: "preview", requestBody: `{"scenario":"foo_scenario.json","trial_turns":3}`, expectedStatus: http.StatusCreated, expectedTrial: &state.Trial{MaxTurns: 3}},
+		{name: "negative turns", requestBody: `{"scenario":"foo_scenario.json","trial_turns":-1}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{
+				Name:            "Test Scenario",
+				OpeningLocation: "start",
+				Locations: map[string]scenario.Location{
+					"start": {Name: "start", Description: "Starting location"},
+				},
+			})
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/gamestate", strings.NewReader(tt.requestBody)))
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if rr.Code != http.StatusCreated {
+				return
+			}
+			var response state.GameState
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if (response.Trial == nil) != (tt.expectedTrial == nil) || (response.Trial != nil && *response.Trial != *tt.expectedTrial) {
+				t.Errorf("Expected trial %+v, got %+v", tt.expectedTrial, response.Trial)
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_TrialCantBeKept(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+	mockStorage := storage.NewMockStorage()
+	mockStorage.AddScenario("foo_scenario.json", &scenario.Scenario{Name: "Test Scenario"})
+
+	preview := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	preview.Trial = &state.Trial{MaxTurns: 3, TurnsUsed: 1}
+	full := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	for _, gs := range []*state.GameState{preview, full} {
+		if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+			t.Fatalf("Failed to save game state: %v", err)
+		}
+	}
+	handler := NewGameStateHandler(logger, "foo_model", mockStorage)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{name: "export preview", method: http.MethodGet, path: "/v1/gamestate/" + preview.ID.String() + "/export", expectedStatus: http.StatusForbidden},
+		{name: "pause preview", method: http.MethodPost, path: "/v1/gamestate/" + preview.ID.String() + "/pause", expectedStatus: http.StatusForbidden},
+		{name: "lift preview limit", method: http.MethodPatch, path: "/v1/gamestate/" + preview.ID.String(), contentType: "application/merge-patch+json", body: `{"trial":null}`, expectedStatus: http.StatusBadRequest},
+		{name: "add turns to preview", method: http.MethodPatch, path: "/v1/gamestate/" + preview.ID.String(), contentType: "application/merge-patch+json", body: `{"trial":{"max_turns":99}}`, expectedStatus: http.StatusBadRequest},
+		{name: "export full game", method: http.MethodGet, path: "/v1/gamestate/" + full.ID.String() + "/export", expectedStatus: http.StatusOK},
+		{name: "pause full game", method: http.MethodPost, path: "/v1/gamestate/" + full.ID.String() + "/pause", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGameStateHandler_ForkTrial(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	st := newEventLogStorage()
+	gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	gs.Trial = &state.Trial{MaxTurns: 3, TurnsUsed: 2}
+	if err := st.SaveGameState(context.Background(), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save game state: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	NewGameStateHandler(logger, "foo_model", st).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/gamestate/"+gs.ID.String()+"/fork", strings.NewReader(`{"seq": 1}`)))
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403, got %d. Response body: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestChatHandler_TrialGame(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := storage.NewMockStorage()
+
+	playing := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	playing.Trial = &state.Trial{MaxTurns: 3, TurnsUsed: 2}
+	ended := state.NewGameState("foo_scenario.json", nil, "foo_model")
+	ended.Trial = &state.Trial{MaxTurns: 3, TurnsUsed: 3}
+	for _, gs := range []*state.GameState{playing, ended} {
+		if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+			t.Fatalf("Failed to save game state: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		gameStateID    uuid.UUID
+		expectedStatus int
+		expectedQueued int
+	}{
+		{"preview with turns left queued", playing.ID, http.StatusAccepted, 1},
+		{"ended preview rejected", ended.ID, http.StatusForbidden, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &stubChatQueue{}
+			handler := NewChatHandler(q, slog.New(slog.NewTextHandler(io.Discard, nil))).
+				WithStorage(mockStorage)
+
+			body, _ := json.Marshal(map[string]string{"gamestate_id": tt.gameStateID.String(), "message": "Look around"})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat", bytes.NewReader(body)))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if len(q.requests) != tt.expectedQueued {
+				t.Errorf("Expected %d queued requests, got %d", tt.expectedQueued, len(q.requests))
+			}
+			if tt.expectedStatus == http.StatusForbidden && !strings.Contains(w.Body.String(), "preview has ended") {
+				t.Errorf("Expected the preview ended message, got %s", w.Body.String())
+			}
+		})
+	}
+}
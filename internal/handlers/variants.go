@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// ChooseVariantRequest is the body for choosing one of a turn's narration variants
+type ChooseVariantRequest struct {
+	Variant *int `json:"variant"` // Index of the chosen candidate
+}
+
+// handleChooseVariant queues the turn whose narration variants are pending, with the
+// chosen candidate as its narration. The worker adds it to the chat history and runs the
+// gamestate delta on it, as for any turn.
+func (h *GameStateHandler) handleChooseVariant(w http.ResponseWriter, r *http.Request, gameStateID uuid.UUID) {
+	var req ChooseVariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Variant == nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body. Expected JSON with 'variant' field.")
+		return
+	}
+	if h.chatQueue == nil {
+		h.logger.Error("Cannot choose variant: no queue configured", "id", gameStateID.String())
+		h.writeError(w, http.StatusServiceUnavailable, "Cannot choose variant: chat queue unavailable")
+		return
+	}
+
+	gs, ok := h.loadGameState(w, r, gameStateID)
+	if !ok {
+		return
+	}
+	if gs.Paused {
+		h.writeError(w, http.StatusConflict, gs.PausedMessage())
+		return
+	}
+	if _, _, err := gs.ChooseVariant(*req.Variant); errors.Is(err, state.ErrNoPendingVariants) {
+		h.writeError(w, http.StatusConflict, "There are no narration variants to choose from")
+		return
+	} else if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.enqueueTurn(w, r, &queue.Request{
+		Type:        queue.RequestTypeChooseVariant,
+		GameStateID: gs.ID,
+		Profile:     gs.Profile,
+		Variant:     *req.Variant,
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+func TestGameStateHandler_ChooseVariant(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		body           string
+		noPending      bool
+		expectedStatus int
+	}{
+		{name: "choose a variant", body: `{"variant":1}`, expectedStatus: http.StatusAccepted},
+		{name: "first variant", body: `{"variant":0}`, expectedStatus: http.StatusAccepted},
+		{name: "out of range", body: `{"variant":2}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing variant", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid body", body: `{"variant":`, expectedStatus: http.StatusBadRequest},
+		{name: "nothing pending", body: `{"variant":0}`, noPending: true, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := storage.NewMockStorage()
+			gs := state.NewGameState("foo_scenario.json", nil, "foo_model")
+			if !tt.noPending {
+				gs.PendingVariants = &state.PendingVariants{Message: "I open the chest", Candidates: []string{"Gold!", "Spiders!"}}
+			}
+			if err := mockStorage.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save game state: %v", err)
+			}
+
+			q := &stubChatQueue{}
+			handler := NewGameStateHandler(logger, "foo_model", mockStorage).WithQueue(q)
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/gamestate/"+gs.ID.String()+"/choose", strings.NewReader(tt.body)))
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d. Response body: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+
+			if tt.expectedStatus != http.StatusAccepted {
+				if len(q.requests) != 0 {
+					t.Errorf("Expected nothing queued, got %+v", q.requests)
+				}
+				return
+			}
+			if len(q.requests) != 1 {
+				t.Fatalf("Expected one queued request, got %d", len(q.requests))
+			}
+			if r := q.requests[0]; r.Type != queue.RequestTypeChooseVariant || r.GameStateID != gs.ID || r.RequestID == "" {
+				t.Errorf("Unexpected queued request: %+v", r)
+			}
+		})
+	}
+}
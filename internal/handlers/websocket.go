@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/logger"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/internal/services/events"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// missedPongs is how many heartbeat intervals can pass without hearing from a
+	// WebSocket client before its connection is dropped
+	missedPongs = 3
+	// wsWriteWait is how long a write to a WebSocket client may block before the
+	// connection is dropped, so a slow client can't hold up its writer forever
+	wsWriteWait = 10 * time.Second
+	// wsMaxMessage is the largest message a WebSocket client may send
+	wsMaxMessage = 64 << 10
+)
+
+// WebSocketRequest is a message sent by a WebSocket client. Chat messages carry the
+// same fields as POST /v1/chat; the game is the one the connection was opened for.
+// A message may bring its own request ID, a UUID as in the X-Request-ID header.
+type WebSocketRequest struct {
+	Type      string `json:"type"`
+	RequestID string `json:"request_id,omitempty"`
+	chat.ChatRequest
+}
+
+// WebSocketReply answers a WebSocket client's message
+type WebSocketReply struct {
+	Type      string   `json:"type"` // chat.accepted or error
+	RequestID string   `json:"request_id,omitempty"`
+	Status    int      `json:"status,omitempty"` // HTTP status the same refusal would get from /v1/chat
+	Error     string   `json:"error,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"` // budget warnings
+}
+
+// WebSocketHandler carries a game's chat and events over one WebSocket connection:
+// the client sends chat messages and receives the game's events as they're published
+type WebSocketHandler struct {
+	chat        *ChatHandler
+	redisClient *redis.Client
+	heartbeat   time.Duration   // how often the client is pinged
+	origins     map[string]bool // browser origins besides the server's own allowed to connect
+	upgrader    websocket.Upgrader
+	logger      *slog.Logger
+}
+
+// NewWebSocketHandler creates a new WebSocket handler, queueing chats through chatHandler
+func NewWebSocketHandler(chatHandler *ChatHandler, redisClient *redis.Client, logger *slog.Logger) *WebSocketHandler {
+	h := &WebSocketHandler{
+		chat:        chatHandler,
+		redisClient: redisClient,
+		heartbeat:   config.DefaultHeartbeatInterval,
+		origins:     make(map[string]bool),
+		logger:      logger,
+	}
+	h.upgrader = websocket.Upgrader{CheckOrigin: h.checkOrigin, Error: h.upgradeError}
+	return h
+}
+
+// WithHeartbeat sets how often the client is pinged
+func (h *WebSocketHandler) WithHeartbeat(interval time.Duration) *WebSocketHandler {
+	h.heartbeat = interval
+	return h
+}
+
+// WithAllowedOrigins lets pages served from the given origins, such as
+// "https://play.example.com", open sockets. Pages from the API's own host may always.
+func (h *WebSocketHandler) WithAllowedOrigins(origins ...string) *WebSocketHandler {
+	for _, origin := range origins {
+		h.origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+	return h
+}
+
+// checkOrigin refuses browsers on other sites, which would otherwise open sockets with
+// the player's credentials. Clients that aren't browsers send no Origin and are allowed.
+func (h *WebSocketHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host) || h.origins[strings.ToLower(origin)]
+}
+
+// upgradeError answers a failed upgrade, such as one from a refused origin, with the
+// usual JSON error
+func (h *WebSocketHandler) upgradeError(w http.ResponseWriter, r *http.Request, status int, reason error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(newErrorResponse(w, "WebSocket upgrade failed: "+reason.Error())); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to encode error response", "error", err)
+	}
+}
+
+// ServeHTTP upgrades to a WebSocket for one game
+// GET /v1/ws?game_state_id={gameStateID}
+// Every event published for the game (chat.chunk, game.state_updated, request and
+// story events) is forwarded as a JSON text message in the same shape the SSE stream
+// uses. Chat messages are checked and queued like POST /v1/chat, and answered with a
+// chat.accepted or error reply. The client is pinged every heartbeat interval and
+// dropped after missing several. Browsers may only connect from allowed origins.
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		if err := json.NewEncoder(w).Encode(newErrorResponse(w, "Method not allowed. Only GET is supported.")); err != nil {
+			h.logger.Error("Failed to encode error response", "error", err)
+		}
+		return
+	}
+
+	gameStateID, err := uuid.Parse(r.URL.Query().Get("game_state_id"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		if err := json.NewEncoder(w).Encode(newErrorResponse(w, "Invalid or missing game_state_id.")); err != nil {
+			h.logger.Error("Failed to encode error response", "error", err)
+		}
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "WebSocket upgrade failed", "error", err, "remote_addr", r.RemoteAddr, "origin", r.Header.Get("Origin"))
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	conn.SetReadLimit(wsMaxMessage)
+	idle := missedPongs * h.heartbeat
+	_ = conn.SetReadDeadline(time.Now().Add(idle))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(idle))
+	})
+
+	h.logger.InfoContext(r.Context(), "WebSocket connection established",
+		"game_state_id", gameStateID.String(),
+		"remote_addr", r.RemoteAddr)
+
+	// The server stops watching a hijacked connection, so the reader cancels instead
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	defer cancel()
+
+	channel := fmt.Sprintf("game-events:%s", gameStateID.String())
+	pubsub := h.redisClient.Subscribe(ctx, channel)
+	defer func() {
+		if err := pubsub.Close(); err != nil {
+			h.logger.ErrorContext(ctx, "Failed to close pubsub", "error", err)
+		}
+	}()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to subscribe to channel", "error", err, "channel", channel)
+		closeWebSocket(conn, websocket.CloseGoingAway, "event stream unavailable")
+		return
+	}
+	msgChan := pubsub.Channel()
+
+	if err := writeWebSocketJSON(conn, events.Event{
+		Type:   "connected",
+		GameID: gameStateID.String(),
+		Data: map[string]interface{}{
+			"message":           "Connected to game socket",
+			"heartbeat_seconds": int(h.heartbeat.Seconds()),
+			"state_updates":     true,
+		},
+	}); err != nil {
+		h.logger.WarnContext(ctx, "Failed to send connected message", "error", err)
+		return
+	}
+
+	// Only this goroutine writes messages; the reader hands its replies over
+	replies := make(chan WebSocketReply)
+	keyID := middleware.APIKeyID(r.Context())
+	go func() {
+		defer cancel()
+		h.readMessages(ctx, conn, gameStateID, keyID, idle, replies)
+	}()
+
+	heartbeatTicker := time.NewTicker(h.heartbeat)
+	defer heartbeatTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger.InfoContext(ctx, "WebSocket client disconnected",
+				"game_state_id", gameStateID.String())
+			return
+
+		case reply := <-replies:
+			if err := writeWebSocketJSON(conn, reply); err != nil {
+				h.logger.DebugContext(ctx, "Failed to send WebSocket reply", "error", err)
+				return
+			}
+
+		case msg, ok := <-msgChan:
+			if !ok {
+				closeWebSocket(conn, websocket.CloseGoingAway, "event stream closed")
+				return
+			}
+			// Payloads are already events.Event JSON
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload)); err != nil {
+				h.logger.DebugContext(ctx, "Failed to forward event", "error", err)
+				return
+			}
+
+		case <-heartbeatTicker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				h.logger.DebugContext(ctx, "Failed to ping WebSocket client", "error", err)
+				return
+			}
+		}
+	}
+}
+
+// readMessages answers the client's messages until the connection closes or goes idle,
+// passing each reply to the writer
+func (h *WebSocketHandler) readMessages(ctx context.Context, conn *websocket.Conn, gameStateID uuid.UUID, keyID string, idle time.Duration, replies chan<- WebSocketReply) {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				h.logger.DebugContext(ctx, "WebSocket read ended", "error", err, "game_state_id", gameStateID.String())
+			}
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(idle))
+
+		reply := h.handleMessage(ctx, message, gameStateID, keyID)
+		select {
+		case replies <- reply:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// writeWebSocketJSON sends v as a JSON text message, giving up after wsWriteWait
+func writeWebSocketJSON(conn *websocket.Conn, v any) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteJSON(v)
+}
+
+// closeWebSocket tells the client why the connection is closing
+func closeWebSocket(conn *websocket.Conn, code int, reason string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+}
+
+// handleMessage carries out one client message
+func (h *WebSocketHandler) handleMessage(ctx context.Context, message []byte, gameStateID uuid.UUID, keyID string) WebSocketReply {
+	var request WebSocketRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return WebSocketReply{Type: "error", Status: http.StatusBadRequest, Error: "Invalid message. Expected JSON with a 'type' field."}
+	}
+	if request.Type != "chat" {
+		return WebSocketReply{Type: "error", Status: http.StatusBadRequest, Error: fmt.Sprintf("Unknown message type %q.", request.Type)}
+	}
+
+	request.GameStateID = gameStateID
+	if err := request.Validate(); err != nil {
+		return WebSocketReply{Type: "error", Status: http.StatusBadRequest, Error: "Invalid request: " + err.Error()}
+	}
+	// The profile router only saw the upgrade request, so each chat is rate limited here
+	if retryAfter, ok := middleware.AllowMessage(ctx); !ok {
+		return WebSocketReply{Type: "error", Status: http.StatusTooManyRequests, Error: fmt.Sprintf("Rate limit exceeded. Retry in %ds.", retryAfter)}
+	}
+
+	// Each chat gets its own request ID, so its API and worker logs share it as they
+	// would for POST /v1/chat
+	requestID := middleware.RequestID(request.RequestID)
+	ctx = logger.ContextWithRequestID(ctx, requestID)
+	warnings, refusal := h.chat.queueChat(ctx, request.ChatRequest, requestID, keyID)
+	if refusal != nil {
+		return WebSocketReply{Type: "error", RequestID: requestID, Status: refusal.status, Error: refusal.message, Warnings: warnings}
+	}
+
+	h.logger.InfoContext(ctx, "Chat request enqueued",
+		"request_id", requestID,
+		"game_state_id", gameStateID.String(),
+		"free_action", request.FreeAction,
+		"transport", "websocket")
+
+	return WebSocketReply{Type: "chat.accepted", RequestID: requestID, Warnings: warnings}
+}
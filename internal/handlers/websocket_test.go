@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/middleware"
+	"github.com/jwebster45206/story-engine/internal/services/events"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	q := &stubChatQueue{}
+	handler := NewWebSocketHandler(NewChatHandler(q, logger), client, logger)
+	server := httptest.NewServer(middleware.Logger(handler))
+	t.Cleanup(server.Close)
+
+	gameID := uuid.New()
+	conn := dialWebSocket(t, server.URL+"/v1/ws?game_state_id="+gameID.String(), nil)
+
+	var connected events.Event
+	if err := conn.ReadJSON(&connected); err != nil {
+		t.Fatalf("Failed to read connected message: %v", err)
+	}
+	if connected.Type != "connected" || connected.Data["state_updates"] != true {
+		t.Errorf("Unexpected connected message: %+v", connected)
+	}
+
+	tests := []struct {
+		name          string
+		message       string
+		expectedType  string
+		expectedError string
+	}{
+		{name: "chat", message: `{"type":"chat","message":"Look around"}`, expectedType: "chat.accepted"},
+		{name: "chat with request ID", message: `{"type":"chat","message":"Open the door","request_id":"6f1c1d1e-8f0a-4a49-9b76-0f4f5f0c2a10"}`, expectedType: "chat.accepted"},
+		{name: "empty chat", message: `{"type":"chat","message":""}`, expectedType: "error", expectedError: "message cannot be empty"},
+		{name: "unknown type", message: `{"type":"dance"}`, expectedType: "error", expectedError: "Unknown message type"},
+		{name: "not JSON", message: `look around`, expectedType: "error", expectedError: "Invalid message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(tt.message)); err != nil {
+				t.Fatalf("WriteMessage failed: %v", err)
+			}
+			var reply WebSocketReply
+			if err := conn.ReadJSON(&reply); err != nil {
+				t.Fatalf("Failed to read reply: %v", err)
+			}
+			if reply.Type != tt.expectedType {
+				t.Errorf("Expected reply type %q, got %+v", tt.expectedType, reply)
+			}
+			if !strings.Contains(reply.Error, tt.expectedError) {
+				t.Errorf("Expected error containing %q, got %q", tt.expectedError, reply.Error)
+			}
+			if tt.expectedType == "chat.accepted" && reply.RequestID == "" {
+				t.Errorf("Expected a request ID in %+v", reply)
+			}
+		})
+	}
+
+	// Only the valid chats were queued, for the connection's game, each with its own
+	// request ID; a client's UUID is kept
+	if len(q.requests) != 2 || q.requests[0].GameStateID != gameID || q.requests[1].GameStateID != gameID {
+		t.Fatalf("Expected two chats queued for %s, got %+v", gameID, q.requests)
+	}
+	if q.requests[0].RequestID == q.requests[1].RequestID {
+		t.Errorf("Expected each chat to get its own request ID, got %q twice", q.requests[0].RequestID)
+	}
+	if q.requests[1].RequestID != "6f1c1d1e-8f0a-4a49-9b76-0f4f5f0c2a10" {
+		t.Errorf("Expected the client's request ID to be kept, got %q", q.requests[1].RequestID)
+	}
+
+	// The game's events are forwarded as they're published
+	if err := events.NewBroadcaster(client, logger).PublishGameStateUpdated(context.Background(), gameID, 4, "harbor"); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	var forwarded events.Event
+	if err := conn.ReadJSON(&forwarded); err != nil {
+		t.Fatalf("Failed to read forwarded event: %v", err)
+	}
+	if forwarded.Type != events.EventTypeGameStateUpdated || forwarded.Data["location"] != "harbor" {
+		t.Errorf("Unexpected forwarded event: %+v", forwarded)
+	}
+}
+
+func TestWebSocketHandler_ChatBudget(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	q := &stubChatQueue{}
+	router := middleware.NewProfileRouter(map[string]string{"key-b": "b"}, logger)
+	router.Handle(config.Profile{Name: "b", DailyChatLimit: 2}, NewWebSocketHandler(NewChatHandler(q, logger), client, logger))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	conn := dialWebSocket(t, server.URL+"/v1/ws?game_state_id="+uuid.New().String(), http.Header{"X-Api-Key": {"key-b"}})
+	var connected events.Event
+	if err := conn.ReadJSON(&connected); err != nil {
+		t.Fatalf("Failed to read connected message: %v", err)
+	}
+
+	// Each chat on the connection is charged, not just the upgrade request
+	expected := []int{0, 0, http.StatusTooManyRequests}
+	for i, status := range expected {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"chat","message":"Look around"}`)); err != nil {
+			t.Fatalf("WriteMessage failed: %v", err)
+		}
+		var reply WebSocketReply
+		if err := conn.ReadJSON(&reply); err != nil {
+			t.Fatalf("Failed to read reply: %v", err)
+		}
+		if reply.Status != status {
+			t.Errorf("Chat %d: expected status %d, got %+v", i+1, status, reply)
+		}
+	}
+	if len(q.requests) != 2 {
+		t.Errorf("Expected 2 chats queued within the budget, got %d", len(q.requests))
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/profile", nil)
+	req.Header.Set("X-API-Key", "key-b")
+	router.ServeHTTP(w, req)
+	var status middleware.ProfileStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode profile status: %v", err)
+	}
+	if status.ChatRequests != 2 || status.BudgetRejected != 1 {
+		t.Errorf("Unexpected profile status: %+v", status)
+	}
+}
+
+func TestWebSocketHandler_InvalidGameID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	server := httptest.NewServer(NewWebSocketHandler(NewChatHandler(&stubChatQueue{}, logger), client, logger))
+	t.Cleanup(server.Close)
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL(server.URL)+"/v1/ws?game_state_id=not-a-uuid", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a 400 handshake error, got %v", err)
+	}
+}
+
+func TestWebSocketHandler_Origin(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	handler := NewWebSocketHandler(NewChatHandler(&stubChatQueue{}, logger), client, logger).
+		WithAllowedOrigins("https://play.example.com")
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name           string
+		origin         string
+		expectedStatus int
+	}{
+		{"no origin", "", http.StatusSwitchingProtocols},
+		{"same origin", server.URL, http.StatusSwitchingProtocols},
+		{"allowed origin", "https://play.example.com", http.StatusSwitchingProtocols},
+		{"other site", "https://evil.example.com", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+			conn, resp, err := websocket.DefaultDialer.Dial(wsURL(server.URL)+"/v1/ws?game_state_id="+uuid.NewString(), header)
+			if conn != nil {
+				_ = conn.Close()
+			}
+			if resp == nil {
+				t.Fatalf("Expected a handshake response, got %v", err)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// dialWebSocket connects to the handler at httpURL, closing the connection when the test ends
+func dialWebSocket(t *testing.T, httpURL string, header http.Header) *websocket.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL(httpURL), header)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	return conn
+}
+
+// wsURL turns a test server's http:// URL into a ws:// one
+func wsURL(httpURL string) string {
+	return "ws" + strings.TrimPrefix(httpURL, "http")
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Handler is a JSON slog handler that applies per-module levels, samples records by
+// category, and adds the request ID from the context to records that lack one
+type Handler struct {
+	next     slog.Handler
+	level    slog.Level
+	modules  map[string]slog.Level
+	samplers map[string]*sampler
+	module   string // Set by a module attribute from With
+	category string // Set by a category attribute from With
+}
+
+// sampler keeps one in every n records. It's shared by all handlers derived with With.
+type sampler struct {
+	n     uint64
+	count atomic.Uint64
+}
+
+func (s *sampler) keep() bool {
+	return (s.count.Add(1)-1)%s.n == 0
+}
+
+// NewHandler returns a handler writing JSON to w. Records below level are dropped,
+// unless they come from a module with a level of its own. sample maps categories to
+// N, keeping one in every N of their records.
+func NewHandler(w io.Writer, level slog.Level, modules map[string]slog.Level, sample map[string]int) *Handler {
+	// The JSON handler sees every record; levels are checked in Enabled
+	lowest := level
+	for _, l := range modules {
+		lowest = min(lowest, l)
+	}
+	samplers := make(map[string]*sampler, len(sample))
+	for category, n := range sample {
+		if n > 1 {
+			samplers[category] = &sampler{n: uint64(n)}
+		}
+	}
+	return &Handler{
+		next:     slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lowest}),
+		level:    level,
+		modules:  modules,
+		samplers: samplers,
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := h.level
+	if l, ok := h.modules[h.module]; ok {
+		minLevel = l
+	}
+	return level >= minLevel
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	category := h.category
+	hasRequestID := false
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case CategoryKey:
+			category = a.Value.String()
+		case RequestIDKey:
+			hasRequestID = true
+		}
+		return true
+	})
+	if s, ok := h.samplers[category]; ok && !s.keep() {
+		return nil
+	}
+	if !hasRequestID {
+		if id := RequestIDFromContext(ctx); id != "" {
+			r.AddAttrs(slog.String(RequestIDKey, id))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	for _, a := range attrs {
+		switch a.Key {
+		case ModuleKey:
+			clone.module = a.Value.String()
+		case CategoryKey:
+			clone.category = a.Value.String()
+		}
+	}
+	clone.next = h.next.WithAttrs(attrs)
+	return &clone
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	return &clone
+}
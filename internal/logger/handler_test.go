@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// records decodes the JSON lines written by a handler
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("Failed to decode %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestHandler_ModuleLevels(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewHandler(&buf, slog.LevelInfo, map[string]slog.Level{
+		ModuleWorker:  slog.LevelDebug,
+		ModuleStorage: slog.LevelWarn,
+	}, nil))
+
+	log.Debug("base debug")
+	log.Info("base info")
+	Module(log, ModuleWorker).Debug("worker debug")
+	Module(log, ModuleStorage).Info("storage info")
+	Module(log, ModuleStorage).Warn("storage warn")
+	Module(log, ModuleHandlers).Debug("handlers debug")
+
+	var got []string
+	for _, rec := range records(t, &buf) {
+		got = append(got, rec["msg"].(string))
+	}
+	expected := []string{"base info", "worker debug", "storage warn"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestHandler_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewHandler(&buf, slog.LevelDebug, nil, map[string]int{CategoryPrompt: 3}))
+
+	for range 7 {
+		log.Debug("prompt", CategoryKey, CategoryPrompt)
+		log.With(CategoryKey, CategoryPrompt).Debug("prompt via With")
+		log.Debug("other")
+	}
+
+	counts := make(map[string]int)
+	for _, rec := range records(t, &buf) {
+		counts[rec["msg"].(string)]++
+	}
+	// The sampler is shared, so 14 prompt records keep the 1st, 4th, 7th, 10th and 13th
+	if prompts := counts["prompt"] + counts["prompt via With"]; prompts != 5 {
+		t.Errorf("Expected 5 sampled prompt records, got %d", prompts)
+	}
+	if counts["other"] != 7 {
+		t.Errorf("Expected every uncategorised record, got %d", counts["other"])
+	}
+}
+
+func TestHandler_RequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewHandler(&buf, slog.LevelInfo, nil, nil))
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+
+	log.InfoContext(ctx, "from context")
+	log.InfoContext(ctx, "explicit", RequestIDKey, "req-2")
+	log.Info("no context")
+
+	recs := records(t, &buf)
+	if len(recs) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(recs))
+	}
+	if recs[0][RequestIDKey] != "req-1" {
+		t.Errorf("Expected the context's request ID, got %v", recs[0][RequestIDKey])
+	}
+	if recs[1][RequestIDKey] != "req-2" || strings.Count(buf.String(), "req-1") != 1 {
+		t.Errorf("Expected the explicit request ID kept, got %v", recs[1])
+	}
+	if _, ok := recs[2][RequestIDKey]; ok {
+		t.Errorf("Expected no request ID, got %v", recs[2][RequestIDKey])
+	}
+}
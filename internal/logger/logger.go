@@ -1,35 +1,90 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
 
 	"github.com/jwebster45206/story-engine/internal/config"
 )
 
-// Setup configures the global slog logger with JSON format
+// Attribute keys the handler acts on
+const (
+	ModuleKey    = "module"     // Part of the service that logged, for per-module levels
+	CategoryKey  = "category"   // High-volume kind of record, for sampling
+	RequestIDKey = "request_id" // Request that caused the record, across API and worker
+)
+
+// Modules that tag their loggers with Module
+const (
+	ModuleHandlers = "handlers"
+	ModuleWorker   = "worker"
+	ModuleStorage  = "storage"
+	ModuleQueue    = "queue"
+	ModuleLLM      = "llm"
+)
+
+// CategoryPrompt marks full prompt dumps
+const CategoryPrompt = "prompt"
+
+// Setup configures the global slog logger with JSON format, writing to stdout and
+// to the configured log file, if any
 func Setup(cfg *config.Config) *slog.Logger {
-	// Configure handler options
-	opts := &slog.HandlerOptions{
-		Level: cfg.LogLevel,
+	var out io.Writer = os.Stdout
+	var fileErr error
+	if cfg.Logging.File != "" {
+		file, err := NewRotatingFile(cfg.Logging.File, cfg.Logging.FileMaxBytes(), cfg.Logging.FileMaxBackups())
+		if err != nil {
+			fileErr = err
+		} else {
+			out = io.MultiWriter(os.Stdout, file)
+		}
 	}
 
-	handler := slog.NewJSONHandler(os.Stdout, opts)
-
-	logger := slog.New(handler)
+	logger := slog.New(NewHandler(out, cfg.LogLevel, cfg.Logging.ModuleLevels(), cfg.Logging.Sample))
 
 	// Set as default logger
 	slog.SetDefault(logger)
 
+	if fileErr != nil {
+		logger.Error("Failed to open log file; logging to stdout only", "error", fileErr, "file", cfg.Logging.File)
+	}
 	return logger
 }
 
+// Module tags a logger with the part of the service using it, so its level can be
+// set with logging.modules
+func Module(logger *slog.Logger, module string) *slog.Logger {
+	return logger.With(ModuleKey, module)
+}
+
 // WithRequestID adds request ID to logger context
 func WithRequestID(logger *slog.Logger, requestID string) *slog.Logger {
-	return logger.With("request_id", requestID)
+	return logger.With(RequestIDKey, requestID)
 }
 
 // WithError adds error to logger context
 func WithError(logger *slog.Logger, err error) *slog.Logger {
 	return logger.With("error", err.Error())
 }
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a context whose records, logged with the slog
+// ...Context methods, carry the request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID set by ContextWithRequestID, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
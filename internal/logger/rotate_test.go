@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("NewRotatingFile() error: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error: %v", err)
+		}
+	}
+
+	// Each line fills the file, so each write rotates; the oldest line is dropped
+	expected := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for p, content := range expected {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Errorf("ReadFile(%s) error: %v", filepath.Base(p), err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, expected %q", filepath.Base(p), data, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected no third backup, got %v", err)
+	}
+}
+
+func TestRotatingFile_AppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewRotatingFile(path, 100, 1)
+	if err != nil {
+		t.Fatalf("NewRotatingFile() error: %v", err)
+	}
+	if _, err := f.Write([]byte("new\n")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	_ = f.Close()
+
+	if data, _ := os.ReadFile(path); string(data) != "old\nnew\n" {
+		t.Errorf("Expected the file appended to, got %q", data)
+	}
+}
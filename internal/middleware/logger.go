@@ -1,11 +1,20 @@
 package middleware
 
 import (
+	"bufio"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/logger"
 )
 
+// RequestIDHeader carries the request ID, on requests that bring their own and on every response
+const RequestIDHeader = "X-Request-ID"
+
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
@@ -31,11 +40,36 @@ func (rw *responseWriter) Flush() {
 	}
 }
 
-// Logger middleware logs HTTP requests with structured logging
+// Hijack implements http.Hijacker to support WebSocket upgrades
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	rw.statusCode = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// RequestID returns candidate if it is a UUID, so a client can bring its own request ID,
+// or else a new one
+func RequestID(candidate string) string {
+	if _, err := uuid.Parse(candidate); err != nil {
+		return uuid.New().String()
+	}
+	return candidate
+}
+
+// Logger middleware logs HTTP requests with structured logging. Each request gets an ID,
+// taken from an X-Request-ID header that holds a UUID or else generated, which is returned
+// in the response header and set on the request's context for logs and queued work.
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
+		requestID := RequestID(r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, requestID)
+		r = r.WithContext(logger.ContextWithRequestID(r.Context(), requestID))
+
 		// Wrap the response writer
 		wrapped := &responseWriter{
 			ResponseWriter: w,
@@ -48,7 +82,7 @@ func Logger(next http.Handler) http.Handler {
 		// Log the request
 		duration := time.Since(start)
 
-		slog.Info("HTTP request",
+		slog.InfoContext(r.Context(), "HTTP request",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", wrapped.statusCode,
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/logger"
+)
+
+func TestLogger_RequestID(t *testing.T) {
+	const clientID = "6f1c1d1e-8f0a-4a49-9b76-0f4f5f0c2a10"
+	tests := []struct {
+		name     string
+		header   string
+		expected string // "" for a generated ID
+	}{
+		{"generated", "", ""},
+		{"client UUID kept", clientID, clientID},
+		{"invalid header replaced", "not-a-uuid", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := Logger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = logger.RequestIDFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/gamestate", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			returned := rec.Header().Get(RequestIDHeader)
+			if seen != returned {
+				t.Errorf("Expected the context and response to share an ID, got %q and %q", seen, returned)
+			}
+			if tt.expected != "" && returned != tt.expected {
+				t.Errorf("Expected request ID %q, got %q", tt.expected, returned)
+			}
+			if _, err := uuid.Parse(returned); err != nil {
+				t.Errorf("Expected a UUID request ID, got %q", returned)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+)
+
+// ProfileMetrics counts API activity for one profile since the server started
+type ProfileMetrics struct {
+	Requests       atomic.Int64
+	RateLimited    atomic.Int64
+	BudgetRejected atomic.Int64
+	ChatRequests   atomic.Int64
+	GamesCreated   atomic.Int64
+}
+
+// ProfileStatus is the response body for GET /v1/profile
+type ProfileStatus struct {
+	Name           string `json:"name"`
+	ModelName      string `json:"model_name"`
+	MaxRating      string `json:"max_rating,omitempty"`
+	RateLimit      int    `json:"rate_limit,omitempty"`
+	DailyChatLimit int    `json:"daily_chat_limit,omitempty"`
+	ChatsToday     int    `json:"chats_today"`
+	Requests       int64  `json:"requests"`
+	RateLimited    int64  `json:"rate_limited"`
+	BudgetRejected int64  `json:"budget_rejected"`
+	ChatRequests   int64  `json:"chat_requests"`
+	GamesCreated   int64  `json:"games_created"`
+}
+
+type apiKeyIDContextKey struct{}
+
+// APIKeyID returns the ID of the API key that authenticated the request,
+// or "" when no API keys are configured
+func APIKeyID(ctx context.Context) string {
+	id, _ := ctx.Value(apiKeyIDContextKey{}).(string)
+	return id
+}
+
+// WithAPIKeyID returns a context for a request authenticated by the API key with ID id
+func WithAPIKeyID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, apiKeyIDContextKey{}, id)
+}
+
+type chatMeterContextKey struct{}
+
+// chatMeter charges the chats of one profile's request against the profile's limits
+type chatMeter struct {
+	route  *profileRoute
+	router *ProfileRouter
+}
+
+// SpendChat charges one queued chat turn against the daily chat budget of the profile
+// serving ctx's request, reporting false once the budget is used up. Every route that
+// queues an LLM turn calls it once per turn: chats over HTTP or a WebSocket, and
+// regenerated, continued, and chosen turns. Requests not routed by profile are never
+// refused.
+func SpendChat(ctx context.Context) bool {
+	m, ok := ctx.Value(chatMeterContextKey{}).(*chatMeter)
+	if !ok {
+		return true
+	}
+	if !m.route.spendChat(m.router.now()) {
+		m.route.metrics.BudgetRejected.Add(1)
+		m.router.logger.WarnContext(ctx, "Daily chat budget exhausted", "profile", m.route.profile.Name)
+		return false
+	}
+	m.route.metrics.ChatRequests.Add(1)
+	return true
+}
+
+// AllowMessage counts a message on a long-lived connection, such as a chat sent over a
+// WebSocket, against the per-minute rate limit of the profile serving ctx's request, as
+// if it were a request of its own. When the limit is exceeded it returns false and the
+// seconds until the limit resets.
+func AllowMessage(ctx context.Context) (int, bool) {
+	m, ok := ctx.Value(chatMeterContextKey{}).(*chatMeter)
+	if !ok {
+		return 0, true
+	}
+	if retryAfter, limited := m.route.rateLimited(m.router.now()); limited {
+		m.route.metrics.RateLimited.Add(1)
+		return retryAfter, false
+	}
+	return 0, true
+}
+
+// KeyID derives a stable identifier for an API key that is safe to store and log
+func KeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// profileRoute holds a profile's handler, limits, and usage
+type profileRoute struct {
+	profile config.Profile
+	handler http.Handler
+	metrics ProfileMetrics
+
+	mu          sync.Mutex
+	windowStart time.Time // start of the current rate limit minute
+	windowCount int
+	day         string // UTC date of chatsToday
+	chatsToday  int
+}
+
+// ProfileRouter dispatches each request to the handler of the profile selected by its
+// API key, enforcing that profile's rate limit and handing its daily chat budget to
+// handlers (see SpendChat). API keys are read from the X-API-Key header, an
+// "Authorization: Bearer" header, or the api_key query parameter (for EventSource
+// clients that can't set headers).
+type ProfileRouter struct {
+	keys   map[string]string // API key -> profile name
+	routes map[string]*profileRoute
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewProfileRouter creates a router for the given API key to profile name mapping.
+// With no keys, every request goes to the default profile ("") without authentication.
+func NewProfileRouter(keys map[string]string, logger *slog.Logger) *ProfileRouter {
+	return &ProfileRouter{
+		keys:   keys,
+		routes: make(map[string]*profileRoute),
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Handle registers the handler serving a profile's requests
+func (pr *ProfileRouter) Handle(profile config.Profile, handler http.Handler) {
+	pr.routes[profile.Name] = &profileRoute{profile: profile, handler: handler}
+}
+
+func (pr *ProfileRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := ""
+	if len(pr.keys) > 0 {
+		key := apiKey(r)
+		var ok bool
+		if name, ok = pr.keys[key]; !ok || key == "" {
+			writeJSONError(w, http.StatusUnauthorized, "A valid API key is required")
+			return
+		}
+		r = r.WithContext(WithAPIKeyID(r.Context(), KeyID(key)))
+	}
+	route, ok := pr.routes[name]
+	if !ok {
+		pr.logger.Error("No handler registered for profile", "profile", name)
+		writeJSONError(w, http.StatusInternalServerError, "Profile is not configured")
+		return
+	}
+	route.metrics.Requests.Add(1)
+
+	if retryAfter, limited := route.rateLimited(pr.now()); limited {
+		route.metrics.RateLimited.Add(1)
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		writeJSONError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+		return
+	}
+
+	if r.Method == http.MethodGet && r.URL.Path == "/v1/profile" {
+		route.writeStatus(w, pr.now())
+		return
+	}
+
+	// Handlers charge each turn they queue against the daily chat budget; see SpendChat
+	r = r.WithContext(context.WithValue(r.Context(), chatMeterContextKey{}, &chatMeter{route: route, router: pr}))
+
+	wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+	route.handler.ServeHTTP(wrapped, r)
+
+	if r.Method == http.MethodPost && r.URL.Path == "/v1/gamestate" && wrapped.statusCode == http.StatusCreated {
+		route.metrics.GamesCreated.Add(1)
+	}
+}
+
+// rateLimited counts a request against the per-minute limit, returning the
+// seconds until the window resets when the limit is exceeded
+func (pr *profileRoute) rateLimited(now time.Time) (int, bool) {
+	if pr.profile.RateLimit <= 0 {
+		return 0, false
+	}
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if now.Sub(pr.windowStart) >= time.Minute {
+		pr.windowStart = now
+		pr.windowCount = 0
+	}
+	if pr.windowCount >= pr.profile.RateLimit {
+		remaining := time.Minute - now.Sub(pr.windowStart)
+		return int(remaining.Seconds()) + 1, true
+	}
+	pr.windowCount++
+	return 0, false
+}
+
+// spendChat counts a chat request against the daily budget, reporting false
+// if the budget is already used up
+func (pr *profileRoute) spendChat(now time.Time) bool {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.resetDay(now)
+	if pr.profile.DailyChatLimit > 0 && pr.chatsToday >= pr.profile.DailyChatLimit {
+		return false
+	}
+	pr.chatsToday++
+	return true
+}
+
+// resetDay clears the daily chat count when the UTC date changes. Callers hold mu.
+func (pr *profileRoute) resetDay(now time.Time) {
+	if day := now.UTC().Format(time.DateOnly); day != pr.day {
+		pr.day = day
+		pr.chatsToday = 0
+	}
+}
+
+func (pr *profileRoute) status(now time.Time) ProfileStatus {
+	pr.mu.Lock()
+	pr.resetDay(now)
+	chatsToday := pr.chatsToday
+	pr.mu.Unlock()
+
+	return ProfileStatus{
+		Name:           pr.profile.Name,
+		ModelName:      pr.profile.ModelName,
+		MaxRating:      pr.profile.MaxRating,
+		RateLimit:      pr.profile.RateLimit,
+		DailyChatLimit: pr.profile.DailyChatLimit,
+		ChatsToday:     chatsToday,
+		Requests:       pr.metrics.Requests.Load(),
+		RateLimited:    pr.metrics.RateLimited.Load(),
+		BudgetRejected: pr.metrics.BudgetRejected.Load(),
+		ChatRequests:   pr.metrics.ChatRequests.Load(),
+		GamesCreated:   pr.metrics.GamesCreated.Load(),
+	}
+}
+
+func (pr *profileRoute) writeStatus(w http.ResponseWriter, now time.Time) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(pr.status(now))
+}
+
+// apiKey extracts the API key from the request
+func apiKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error     string `json:"error"`
+		RequestID string `json:"request_id,omitempty"`
+	}{Error: message, RequestID: w.Header().Get(RequestIDHeader)})
+}
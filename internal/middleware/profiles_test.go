@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+)
+
+// echoProfile responds with the name of the profile whose handler served the request,
+// charging chats against the profile's budget the way the chat handler does
+func echoProfile(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/gamestate" {
+			w.WriteHeader(http.StatusCreated)
+		}
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/chat" && !SpendChat(r.Context()) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(name))
+	})
+}
+
+func newTestRouter(now *time.Time) *ProfileRouter {
+	router := NewProfileRouter(map[string]string{"key-a": "a", "key-b": "b"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	router.now = func() time.Time { return *now }
+	router.Handle(config.Profile{Name: "a", ModelName: "model-a", RateLimit: 3}, echoProfile("a"))
+	router.Handle(config.Profile{Name: "b", DailyChatLimit: 1}, echoProfile("b"))
+	return router
+}
+
+func TestProfileRouter_Routing(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	router := newTestRouter(&now)
+
+	tests := []struct {
+		name           string
+		setup          func(r *http.Request)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"missing key", func(r *http.Request) {}, http.StatusUnauthorized, ""},
+		{"unknown key", func(r *http.Request) { r.Header.Set("X-API-Key", "nope") }, http.StatusUnauthorized, ""},
+		{"header key", func(r *http.Request) { r.Header.Set("X-API-Key", "key-a") }, http.StatusOK, "a"},
+		{"bearer key", func(r *http.Request) { r.Header.Set("Authorization", "Bearer key-b") }, http.StatusOK, "b"},
+		{"query key", func(r *http.Request) { r.URL.RawQuery = "api_key=key-b" }, http.StatusOK, "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/scenarios", nil)
+			tt.setup(req)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("Expected profile %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProfileRouter_NoKeysUsesDefaultProfile(t *testing.T) {
+	router := NewProfileRouter(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	router.Handle(config.Profile{}, echoProfile("default"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/scenarios", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "default" {
+		t.Errorf("Expected default profile to serve request, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestProfileRouter_APIKeyID(t *testing.T) {
+	var got string
+	router := NewProfileRouter(map[string]string{"key-a": "a"}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	router.Handle(config.Profile{Name: "a"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = APIKeyID(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/scenarios", nil)
+	req.Header.Set("X-API-Key", "key-a")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got == "" || got == "key-a" {
+		t.Fatalf("Expected a derived key ID, got %q", got)
+	}
+	if got != KeyID("key-a") || got == KeyID("key-b") {
+		t.Errorf("Expected key ID to be stable and distinct per key, got %q", got)
+	}
+}
+
+func TestProfileRouter_Limits(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	router := newTestRouter(&now)
+
+	send := func(method, path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Profile a allows 3 requests per minute
+	for i := 0; i < 3; i++ {
+		if w := send(http.MethodGet, "/v1/scenarios", "key-a"); w.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i+1, w.Code)
+		}
+	}
+	w := send(http.MethodGet, "/v1/scenarios", "key-a")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 429 with Retry-After, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	now = now.Add(time.Minute)
+	if w := send(http.MethodGet, "/v1/scenarios", "key-a"); w.Code != http.StatusOK {
+		t.Errorf("Expected rate limit to reset after a minute, got %d", w.Code)
+	}
+
+	// Profile b allows 1 chat per day
+	if w := send(http.MethodPost, "/v1/chat", "key-b"); w.Code != http.StatusOK {
+		t.Fatalf("Expected first chat to pass, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/v1/chat", "key-b"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected second chat to exceed the daily budget, got %d", w.Code)
+	}
+	if w := send(http.MethodPost, "/v1/gamestate", "key-b"); w.Code != http.StatusCreated {
+		t.Errorf("Expected non-chat requests to ignore the chat budget, got %d", w.Code)
+	}
+	now = now.Add(24 * time.Hour)
+	if w := send(http.MethodPost, "/v1/chat", "key-b"); w.Code != http.StatusOK {
+		t.Errorf("Expected chat budget to reset the next day, got %d", w.Code)
+	}
+
+	w = send(http.MethodGet, "/v1/profile", "key-b")
+	var status ProfileStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode profile status: %v", err)
+	}
+	if status.Name != "b" || status.ChatRequests != 2 || status.BudgetRejected != 1 || status.GamesCreated != 1 || status.ChatsToday != 1 {
+		t.Errorf("Unexpected profile status: %+v", status)
+	}
+}
+
+func TestSpendChat_WithoutProfile(t *testing.T) {
+	if !SpendChat(context.Background()) {
+		t.Error("Expected chats outside a profile router to be allowed")
+	}
+	if _, ok := AllowMessage(context.Background()); !ok {
+		t.Error("Expected messages outside a profile router to be allowed")
+	}
+}
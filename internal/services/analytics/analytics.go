@@ -0,0 +1,230 @@
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/redis/go-redis/v9"
+)
+
+// AbandonAfter is how long a game can sit idle before it counts as abandoned in its
+// scene. It matches how long storage keeps an idle game.
+const AbandonAfter = time.Hour
+
+// Report summarizes play of a scenario across every game, so authors can find
+// scenes that drag or stall and conditionals that never come into play
+type Report struct {
+	Scenario          string           `json:"scenario"`
+	GamesStarted      int64            `json:"games_started"`
+	GamesCompleted    int64            `json:"games_completed"`
+	GamesAbandoned    int64            `json:"games_abandoned"`
+	Scenes            []SceneStats     `json:"scenes"`
+	ConditionalsFired map[string]int64 `json:"conditionals_fired"` // Times fired, by "scene/conditional"
+	NeverFired        []string         `json:"never_fired"`        // Scenario conditionals that have never fired, as "scene/conditional"
+}
+
+// SceneStats is how games have played through one scene
+type SceneStats struct {
+	Scene     string  `json:"scene"`
+	Entered   int64   `json:"entered"`   // Times a game entered the scene, including starting in it
+	Exited    int64   `json:"exited"`    // Times a game moved on to another scene
+	Completed int64   `json:"completed"` // Games that ended in the scene
+	Abandoned int64   `json:"abandoned"` // Games left idle in the scene until they expired
+	Active    int64   `json:"active"`    // Games in the scene now
+	AvgTurns  float64 `json:"avg_turns"` // Average turns spent in the scene by games that left or ended it
+}
+
+// Store aggregates anonymized play statistics per scenario in Redis. Only counts are
+// kept; games are tracked by a hash of their ID while they are active.
+type Store struct {
+	client    *redis.Client
+	keyPrefix string
+	now       func() time.Time
+}
+
+// NewStore creates an analytics store
+func NewStore(client *redis.Client) *Store {
+	return &Store{client: client, now: time.Now}
+}
+
+// WithKeyPrefix returns a store sharing this connection whose keys are prefixed,
+// so each profile's games are counted separately
+func (st *Store) WithKeyPrefix(prefix string) *Store {
+	prefixed := *st
+	prefixed.keyPrefix = prefix
+	return &prefixed
+}
+
+// RecordGameStarted counts a new game of the scenario and its entry into the opening scene
+func (st *Store) RecordGameStarted(ctx context.Context, gs *state.GameState) error {
+	key := st.scenarioKey(gs.Scenario)
+	pipe := st.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, "games_started", 1)
+	pipe.HIncrBy(ctx, key, sceneField(gs.SceneName, "entered"), 1)
+	pipe.ZAdd(ctx, st.activeKey(gs.Scenario, gs.SceneName), redis.Z{Score: st.score(), Member: member(gs)})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record game start in redis: %w", err)
+	}
+	return nil
+}
+
+// RecordTurn counts what a turn's delta did: scene changes, with the turns spent in the
+// scene left behind, the game ending, and the conditionals that fired
+func (st *Store) RecordTurn(ctx context.Context, s *scenario.Scenario, before, after *state.GameState, conditionalsFired []string) error {
+	key := st.scenarioKey(after.Scenario)
+	turns := int64(before.SceneTurnCounter + after.TurnCounter - before.TurnCounter)
+
+	pipe := st.client.TxPipeline()
+	for _, id := range conditionalsFired {
+		// Conditionals fired after a scene change belong to the new scene
+		scene := before.SceneName
+		if _, ok := s.Scenes[scene].Conditionals[id]; !ok {
+			scene = after.SceneName
+		}
+		pipe.HIncrBy(ctx, key, conditionalField(scene, id), 1)
+	}
+	switch {
+	case after.IsEnded && !before.IsEnded:
+		if after.SceneName != before.SceneName {
+			pipe.HIncrBy(ctx, key, sceneField(before.SceneName, "exited"), 1)
+			pipe.HIncrBy(ctx, key, sceneField(before.SceneName, "turns"), turns)
+			pipe.HIncrBy(ctx, key, sceneField(after.SceneName, "entered"), 1)
+			turns = int64(after.SceneTurnCounter)
+		}
+		pipe.HIncrBy(ctx, key, "games_completed", 1)
+		pipe.HIncrBy(ctx, key, sceneField(after.SceneName, "completed"), 1)
+		pipe.HIncrBy(ctx, key, sceneField(after.SceneName, "turns"), turns)
+		pipe.ZRem(ctx, st.activeKey(after.Scenario, before.SceneName), member(after))
+	case after.IsEnded:
+		return nil
+	case after.SceneName != before.SceneName:
+		pipe.HIncrBy(ctx, key, sceneField(before.SceneName, "exited"), 1)
+		pipe.HIncrBy(ctx, key, sceneField(before.SceneName, "turns"), turns)
+		pipe.HIncrBy(ctx, key, sceneField(after.SceneName, "entered"), 1)
+		pipe.ZRem(ctx, st.activeKey(after.Scenario, before.SceneName), member(after))
+		pipe.ZAdd(ctx, st.activeKey(after.Scenario, after.SceneName), redis.Z{Score: st.score(), Member: member(after)})
+	default:
+		pipe.ZAdd(ctx, st.activeKey(after.Scenario, after.SceneName), redis.Z{Score: st.score(), Member: member(after)})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record turn in redis: %w", err)
+	}
+	return nil
+}
+
+// Report returns the statistics recorded for a scenario. Games idle for longer than
+// AbandonAfter are first counted as abandoned in their scene.
+func (st *Store) Report(ctx context.Context, file string, s *scenario.Scenario) (*Report, error) {
+	scenes := []string{""}
+	if len(s.Scenes) > 0 {
+		scenes = slices.Sorted(maps.Keys(s.Scenes))
+	}
+	for _, scene := range scenes {
+		if err := st.sweep(ctx, file, scene); err != nil {
+			return nil, err
+		}
+	}
+
+	fields, err := st.client.HGetAll(ctx, st.scenarioKey(file)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analytics from redis: %w", err)
+	}
+	count := func(field string) int64 {
+		n, _ := strconv.ParseInt(fields[field], 10, 64)
+		return n
+	}
+
+	r := &Report{
+		Scenario:          file,
+		GamesStarted:      count("games_started"),
+		GamesCompleted:    count("games_completed"),
+		Scenes:            []SceneStats{},
+		ConditionalsFired: map[string]int64{},
+		NeverFired:        []string{},
+	}
+	for _, scene := range scenes {
+		active, err := st.client.ZCard(ctx, st.activeKey(file, scene)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load analytics from redis: %w", err)
+		}
+		stats := SceneStats{
+			Scene:     scene,
+			Entered:   count(sceneField(scene, "entered")),
+			Exited:    count(sceneField(scene, "exited")),
+			Completed: count(sceneField(scene, "completed")),
+			Abandoned: count(sceneField(scene, "abandoned")),
+			Active:    active,
+		}
+		if finished := stats.Exited + stats.Completed; finished > 0 {
+			stats.AvgTurns = float64(count(sceneField(scene, "turns"))) / float64(finished)
+		}
+		r.GamesAbandoned += stats.Abandoned
+		r.Scenes = append(r.Scenes, stats)
+
+		for _, id := range slices.Sorted(maps.Keys(s.Scenes[scene].Conditionals)) {
+			if n := count(conditionalField(scene, id)); n > 0 {
+				r.ConditionalsFired[scene+"/"+id] = n
+			} else {
+				r.NeverFired = append(r.NeverFired, scene+"/"+id)
+			}
+		}
+	}
+	return r, nil
+}
+
+// sweep counts games idle for longer than AbandonAfter as abandoned in the scene and
+// stops tracking them
+func (st *Store) sweep(ctx context.Context, file, scene string) error {
+	key := st.activeKey(file, scene)
+	cutoff := strconv.FormatInt(st.now().Add(-AbandonAfter).Unix(), 10)
+	stale, err := st.client.ZRemRangeByScore(ctx, key, "-inf", "("+cutoff).Result()
+	if err != nil {
+		return fmt.Errorf("failed to sweep idle games in redis: %w", err)
+	}
+	if stale > 0 {
+		if err := st.client.HIncrBy(ctx, st.scenarioKey(file), sceneField(scene, "abandoned"), stale).Err(); err != nil {
+			return fmt.Errorf("failed to record abandoned games in redis: %w", err)
+		}
+	}
+	return nil
+}
+
+func (st *Store) score() float64 {
+	return float64(st.now().Unix())
+}
+
+// member identifies a game in the active sets without storing its ID
+func member(gs *state.GameState) string {
+	sum := sha256.Sum256([]byte(gs.ID.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// scenarioKey returns the key of a scenario's counters, e.g. "analytics:scenario:pirate.json"
+// or "<prefix>:analytics:scenario:pirate.json" for a prefixed store
+func (st *Store) scenarioKey(file string) string {
+	if st.keyPrefix == "" {
+		return "analytics:scenario:" + file
+	}
+	return st.keyPrefix + ":analytics:scenario:" + file
+}
+
+// activeKey returns the key of the set of games active in a scene
+func (st *Store) activeKey(file, scene string) string {
+	return st.scenarioKey(file) + ":active:" + scene
+}
+
+func sceneField(scene, stat string) string {
+	return "scene:" + scene + ":" + stat
+}
+
+func conditionalField(scene, id string) string {
+	return "conditional:" + scene + "/" + id
+}
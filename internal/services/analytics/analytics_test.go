@@ -0,0 +1,120 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) (*Store, *time.Time) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	st := NewStore(client)
+	st.now = func() time.Time { return now }
+	return st, &now
+}
+
+func testScenario() *scenario.Scenario {
+	return &scenario.Scenario{
+		Scenes: map[string]scenario.Scene{
+			"docks": {Conditionals: map[string]scenario.Conditional{
+				"hire_ship": {When: conditionals.ConditionalWhen{Vars: map[string]string{"paid": "true"}}},
+				"bar_fight": {When: conditionals.ConditionalWhen{Vars: map[string]string{"drunk": "true"}}},
+			}},
+			"voyage": {Conditionals: map[string]scenario.Conditional{
+				"storm": {When: conditionals.ConditionalWhen{MinSceneTurns: new(int)}},
+			}},
+		},
+	}
+}
+
+// turn returns a copy of gs advanced by one turn, with changes applied
+func turn(t *testing.T, gs *state.GameState, change func(*state.GameState)) *state.GameState {
+	t.Helper()
+	next, err := gs.DeepCopy()
+	require.NoError(t, err)
+	next.IncrementTurnCounters()
+	if change != nil {
+		change(next)
+	}
+	return next
+}
+
+func TestStore_Report(t *testing.T) {
+	ctx := context.Background()
+	st, now := newTestStore(t)
+	s := testScenario()
+
+	newGame := func() *state.GameState {
+		gs := &state.GameState{ID: uuid.New(), Scenario: "pirate.json", SceneName: "docks"}
+		require.NoError(t, st.RecordGameStarted(ctx, gs))
+		return gs
+	}
+
+	// The first game takes three turns at the docks, then one on the voyage before it ends
+	finished := newGame()
+	for range 2 {
+		next := turn(t, finished, nil)
+		require.NoError(t, st.RecordTurn(ctx, s, finished, next, nil))
+		finished = next
+	}
+	next := turn(t, finished, func(gs *state.GameState) { gs.SceneName, gs.SceneTurnCounter = "voyage", 0 })
+	require.NoError(t, st.RecordTurn(ctx, s, finished, next, []string{"hire_ship", "storm"}))
+	finished = next
+	next = turn(t, finished, func(gs *state.GameState) { gs.IsEnded = true })
+	require.NoError(t, st.RecordTurn(ctx, s, finished, next, nil))
+
+	// The second game is left idle at the docks; the third is still playing
+	idle := newGame()
+	require.NoError(t, st.RecordTurn(ctx, s, idle, turn(t, idle, nil), nil))
+	*now = now.Add(2 * AbandonAfter)
+	playing := newGame()
+	require.NoError(t, st.RecordTurn(ctx, s, playing, turn(t, playing, nil), []string{"hire_ship"}))
+
+	r, err := st.Report(ctx, "pirate.json", s)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), r.GamesStarted)
+	assert.Equal(t, int64(1), r.GamesCompleted)
+	assert.Equal(t, int64(1), r.GamesAbandoned)
+	assert.Equal(t, []SceneStats{
+		{Scene: "docks", Entered: 3, Exited: 1, Abandoned: 1, Active: 1, AvgTurns: 3},
+		{Scene: "voyage", Entered: 1, Completed: 1, AvgTurns: 1},
+	}, r.Scenes)
+	assert.Equal(t, map[string]int64{"docks/hire_ship": 2, "voyage/storm": 1}, r.ConditionalsFired)
+	assert.Equal(t, []string{"docks/bar_fight"}, r.NeverFired)
+
+	// Abandoned games are only counted once
+	r, err = st.Report(ctx, "pirate.json", s)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), r.GamesAbandoned)
+}
+
+func TestStore_Report_NoScenes(t *testing.T) {
+	ctx := context.Background()
+	st, _ := newTestStore(t)
+	s := &scenario.Scenario{}
+
+	gs := &state.GameState{ID: uuid.New(), Scenario: "short.json"}
+	require.NoError(t, st.RecordGameStarted(ctx, gs))
+	next := turn(t, gs, func(gs *state.GameState) { gs.IsEnded = true })
+	require.NoError(t, st.RecordTurn(ctx, s, gs, next, nil))
+
+	r, err := st.Report(ctx, "short.json", s)
+	require.NoError(t, err)
+	assert.Equal(t, []SceneStats{{Scene: "", Entered: 1, Completed: 1, AvgTurns: 1}}, r.Scenes)
+	assert.Empty(t, r.NeverFired)
+}
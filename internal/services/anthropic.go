@@ -14,6 +14,7 @@ import (
 
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/state"
 )
 
 const (
@@ -46,7 +47,7 @@ type AnthropicChatRequest struct {
 	MaxTokens     int                  `json:"max_tokens"`
 	Temperature   *float64             `json:"temperature,omitempty"`
 	Messages      []chat.ChatMessage   `json:"messages"`
-	System        string               `json:"system,omitempty"`
+	System        any                  `json:"system,omitempty"` // A string, or []AnthropicSystemBlock to mark a cacheable prefix
 	Stream        bool                 `json:"stream,omitempty"`
 	TopP          *float64             `json:"top_p,omitempty"`
 	TopK          *int                 `json:"top_k,omitempty"`
@@ -55,6 +56,18 @@ type AnthropicChatRequest struct {
 	ToolChoice    *AnthropicToolChoice `json:"tool_choice,omitempty"`
 }
 
+// AnthropicSystemBlock is one text block of a system prompt. A block with CacheControl
+// set ends a prompt prefix that Anthropic caches between requests.
+type AnthropicSystemBlock struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text"`
+	CacheControl *AnthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+type AnthropicCacheControl struct {
+	Type string `json:"type"` // "ephemeral"
+}
+
 type AnthropicContentBlock struct {
 	Type  string         `json:"type"`
 	Text  string         `json:"text,omitempty"`
@@ -95,16 +108,28 @@ type AnthropicChatResponse struct {
 	Model        string                  `json:"model"`
 	StopReason   string                  `json:"stop_reason"`
 	StopSequence *string                 `json:"stop_sequence"`
-	Usage        struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage"`
-	Error *struct {
+	Usage        AnthropicUsage          `json:"usage"`
+	Error        *struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
 }
 
+// AnthropicUsage is the token usage of a request. InputTokens excludes tokens written
+// to or read from the prompt cache, which are reported separately.
+type AnthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// TotalInputTokens counts every prompt token, cached or not. Budgets use it so
+// that caching never lets a game exceed its cap.
+func (u AnthropicUsage) TotalInputTokens() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
 func NewAnthropicService(apiKey string, modelName string, backendModelName string, logger *slog.Logger) *AnthropicService {
 	return &AnthropicService{
 		apiKey:           apiKey,
@@ -139,13 +164,59 @@ func (a *AnthropicService) splitChatMessages(messages []chat.ChatMessage) (strin
 	return systemPrompt, nonSystemMessages
 }
 
+// systemBlocks splits the system prompt into blocks so the cacheable prefix of each
+// system message (see chat.ChatMessage.CachePrefix) ends in a cache breakpoint.
+// It returns nil if no system message has a cacheable prefix; send the plain
+// system prompt from splitChatMessages instead.
+func (a *AnthropicService) systemBlocks(messages []chat.ChatMessage) []AnthropicSystemBlock {
+	var blocks []AnthropicSystemBlock
+	var pending []string
+	cached := false
+	for _, msg := range messages {
+		if msg.Role != chat.ChatRoleSystem {
+			continue
+		}
+		if msg.CachePrefix <= 0 || msg.CachePrefix > len(msg.Content) {
+			pending = append(pending, msg.Content)
+			continue
+		}
+		pending = append(pending, msg.Content[:msg.CachePrefix])
+		blocks = append(blocks, AnthropicSystemBlock{
+			Type:         "text",
+			Text:         strings.Join(pending, "\n\n"),
+			CacheControl: &AnthropicCacheControl{Type: "ephemeral"},
+		})
+		cached = true
+		pending = nil
+		if rest := msg.Content[msg.CachePrefix:]; rest != "" {
+			pending = append(pending, rest)
+		}
+	}
+	if !cached {
+		return nil
+	}
+	if len(pending) > 0 {
+		blocks = append(blocks, AnthropicSystemBlock{Type: "text", Text: strings.Join(pending, "\n\n")})
+	}
+	return blocks
+}
+
+// setSystem adds the system prompt to req, with cache breakpoints if any message has a cacheable prefix
+func (a *AnthropicService) setSystem(req *AnthropicChatRequest, messages []chat.ChatMessage, systemPrompt string) {
+	if blocks := a.systemBlocks(messages); blocks != nil {
+		req.System = blocks
+	} else if systemPrompt != "" {
+		req.System = systemPrompt
+	}
+}
+
 // Chat generates a chat response using Anthropic Claude
 // chatCompletion makes a chat completion request to Anthropic with the specified model
 func (a *AnthropicService) chatCompletion(ctx context.Context, messages []chat.ChatMessage, modelName string, temperature float64, tools []AnthropicTool) (string, error) {
 	// Extract system messages and convert to Anthropic format
 	systemPrompt, conversationMessages := a.splitChatMessages(messages)
 
-	maxTokens := DefaultMaxTokens
+	maxTokens := maxTokensFromContext(ctx, DefaultMaxTokens)
 	if temperature == 0 {
 		maxTokens = BackendMaxTokens
 	}
@@ -158,9 +229,7 @@ func (a *AnthropicService) chatCompletion(ctx context.Context, messages []chat.C
 	}
 
 	// Add system prompt if we have one
-	if systemPrompt != "" {
-		anthropicReq.System = systemPrompt
-	}
+	a.setSystem(&anthropicReq, messages, systemPrompt)
 
 	// Add tools if provided, and use the first tool as the tool choice
 	if len(tools) > 0 {
@@ -209,6 +278,8 @@ func (a *AnthropicService) chatCompletion(ctx context.Context, messages []chat.C
 	if anthropicResp.Error != nil {
 		return "", fmt.Errorf("API error: %s", anthropicResp.Error.Message)
 	}
+	a.logCacheUsage(modelName, anthropicResp.Usage)
+	recordUsage(ctx, modelName, anthropicResp.Usage.TotalInputTokens(), anthropicResp.Usage.OutputTokens)
 
 	// Extract content from the response (text or tool use)
 	var responseText string
@@ -234,7 +305,7 @@ func (a *AnthropicService) chatCompletion(ctx context.Context, messages []chat.C
 }
 
 func (a *AnthropicService) Chat(ctx context.Context, messages []chat.ChatMessage, temperature float64) (*chat.ChatResponse, error) {
-	content, err := a.chatCompletion(ctx, messages, a.modelName, temperature, nil)
+	content, err := a.chatCompletion(ctx, messages, modelFromContext(ctx, a.modelName), temperature, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -250,18 +321,17 @@ func (a *AnthropicService) ChatStream(ctx context.Context, messages []chat.ChatM
 	systemPrompt, conversationMessages := a.splitChatMessages(messages)
 
 	temp := temperature
+	modelName := modelFromContext(ctx, a.modelName)
 	anthropicReq := AnthropicChatRequest{
-		Model:       a.modelName,
-		MaxTokens:   DefaultMaxTokens,
+		Model:       modelName,
+		MaxTokens:   maxTokensFromContext(ctx, DefaultMaxTokens),
 		Temperature: &temp,
 		Messages:    conversationMessages,
 		Stream:      true,
 	}
 
 	// Add system prompt if we have one
-	if systemPrompt != "" {
-		anthropicReq.System = systemPrompt
-	}
+	a.setSystem(&anthropicReq, messages, systemPrompt)
 
 	reqBody, err := json.Marshal(anthropicReq)
 	if err != nil {
@@ -294,6 +364,8 @@ func (a *AnthropicService) ChatStream(ctx context.Context, messages []chat.ChatM
 		defer func() { _ = resp.Body.Close() }()
 		defer close(chunkChan)
 
+		// Input tokens arrive with message_start, output tokens with message_delta
+		var inputTokens, outputTokens int
 		scanner := bufio.NewScanner(resp.Body)
 		for scanner.Scan() {
 			select {
@@ -344,11 +416,21 @@ func (a *AnthropicService) ChatStream(ctx context.Context, messages []chat.ChatM
 						Done:    false,
 					}
 				}
+			case "message_start":
+				if streamEvent.Message != nil {
+					a.logCacheUsage(modelName, streamEvent.Message.Usage)
+					inputTokens = streamEvent.Message.Usage.TotalInputTokens()
+				}
+			case "message_delta":
+				if streamEvent.Usage != nil {
+					outputTokens = streamEvent.Usage.OutputTokens
+				}
 			case "message_stop":
 				// End of stream
+				recordUsage(ctx, modelName, inputTokens, outputTokens)
 				chunkChan <- StreamChunk{Done: true}
 				return
-			case "message_start", "content_block_start", "content_block_stop", "message_delta", "ping":
+			case "content_block_start", "content_block_stop", "ping":
 				// These are structural events we can ignore for our streaming purposes
 				continue
 			default:
@@ -366,115 +448,31 @@ func (a *AnthropicService) ChatStream(ctx context.Context, messages []chat.ChatM
 	return chunkChan, nil
 }
 
+// logCacheUsage logs how much of a prompt was written to or read from the prompt cache
+func (a *AnthropicService) logCacheUsage(modelName string, usage AnthropicUsage) {
+	if usage.CacheCreationInputTokens == 0 && usage.CacheReadInputTokens == 0 {
+		return
+	}
+	a.logger.Debug("Anthropic prompt cache usage",
+		"model", modelName,
+		"input_tokens", usage.InputTokens,
+		"cache_creation_input_tokens", usage.CacheCreationInputTokens,
+		"cache_read_input_tokens", usage.CacheReadInputTokens)
+}
+
 // getDeltaUpdateTool returns the tool definition for gamestate deltas
 func (a *AnthropicService) getDeltaUpdateTool() AnthropicTool {
 	return AnthropicTool{
 		Name:        "apply_changes",
 		Description: "Return only the delta for game state updates.",
-		InputSchema: map[string]any{
-			"type":                 "object",
-			"additionalProperties": false,
-			"properties": map[string]any{
-				"user_location": map[string]any{
-					"type": "string",
-				},
-				"scene_change": map[string]any{
-					"anyOf": []any{
-						map[string]any{
-							"type":                 "object",
-							"additionalProperties": false,
-							"properties": map[string]any{
-								"to":     map[string]any{"type": "string"},
-								"reason": map[string]any{"type": "string"},
-							},
-							"required": []string{"to", "reason"},
-						},
-						map[string]any{"type": "null"},
-					},
-				},
-				"item_events": map[string]any{
-					"type": "array",
-					"items": map[string]any{
-						"type":                 "object",
-						"additionalProperties": false,
-						"properties": map[string]any{
-							"item": map[string]any{
-								"type": "string",
-							},
-							"action": map[string]any{
-								"type": "string",
-								"enum": []string{"acquire", "give", "drop", "move", "use"},
-							},
-							"from": map[string]any{
-								"type":                 "object",
-								"additionalProperties": false,
-								"properties": map[string]any{
-									"type": map[string]any{
-										"type": "string",
-										"enum": []string{"player", "npc", "location"},
-									},
-									"name": map[string]any{
-										"type": "string",
-									},
-								},
-								"required": []string{"type"},
-							},
-							"to": map[string]any{
-								"type":                 "object",
-								"additionalProperties": false,
-								"properties": map[string]any{
-									"type": map[string]any{
-										"type": "string",
-										"enum": []string{"player", "npc", "location"},
-									},
-									"name": map[string]any{
-										"type": "string",
-									},
-								},
-								"required": []string{"type"},
-							},
-							"consumed": map[string]any{
-								"type": "boolean",
-							},
-						},
-						"required": []string{"item", "action"},
-					},
-				},
-				"npc_events": map[string]any{
-					"type": "array",
-					"items": map[string]any{
-						"type":                 "object",
-						"additionalProperties": false,
-						"properties": map[string]any{
-							"npc_id": map[string]any{
-								"type": "string",
-							},
-							"set_location": map[string]any{
-								"type": "string",
-							},
-						},
-						"required": []string{"npc_id"},
-					},
-				},
-				"set_vars": map[string]any{
-					"type": "object",
-					"additionalProperties": map[string]any{
-						"type": "string",
-					},
-				},
-				"game_ended": map[string]any{
-					"type": "boolean",
-				},
-			},
-			"required": []string{"user_location", "scene_change", "item_events", "npc_events", "set_vars", "game_ended"},
-		},
+		InputSchema: conditionals.DeltaSchema(),
 	}
 }
 
 // DeltaUpdate processes a gamestate delta request using Anthropic Claude
 func (a *AnthropicService) DeltaUpdate(ctx context.Context, messages []chat.ChatMessage) (*conditionals.GameStateDelta, string, error) {
 	// Determine which model to use for DeltaUpdate
-	modelToUse := a.modelName
+	modelToUse := modelFromContext(ctx, a.modelName)
 	if a.backendModelName != "" {
 		modelToUse = a.backendModelName
 	}
@@ -494,3 +492,61 @@ func (a *AnthropicService) DeltaUpdate(ctx context.Context, messages []chat.Chat
 
 	return deltaUpdate, modelToUse, nil
 }
+
+// getChoicesTool returns the tool definition for structured choice suggestions
+func (a *AnthropicService) getChoicesTool() AnthropicTool {
+	return AnthropicTool{
+		Name:        "suggest_choices",
+		Description: "Suggest the player's next actions",
+		InputSchema: map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"choices": map[string]any{
+					"type":     "array",
+					"minItems": MinChoices,
+					"maxItems": MaxChoices,
+					"items": map[string]any{
+						"type": "string",
+					},
+				},
+			},
+			"required": []string{"choices"},
+		},
+	}
+}
+
+// SuggestChoices asks the backend model for suggested next actions using Anthropic Claude
+func (a *AnthropicService) SuggestChoices(ctx context.Context, messages []chat.ChatMessage) ([]string, error) {
+	modelToUse := modelFromContext(ctx, a.modelName)
+	if a.backendModelName != "" {
+		modelToUse = a.backendModelName
+	}
+
+	content, err := a.chatCompletion(ctx, messages, modelToUse, 0.0, []AnthropicTool{a.getChoicesTool()})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseChoicesResponse(content)
+}
+
+// CheckNarration asks the backend model where a narration contradicts the game state using Anthropic Claude
+func (a *AnthropicService) CheckNarration(ctx context.Context, messages []chat.ChatMessage) ([]state.NarrationIssue, error) {
+	modelToUse := modelFromContext(ctx, a.modelName)
+	if a.backendModelName != "" {
+		modelToUse = a.backendModelName
+	}
+
+	tool := AnthropicTool{
+		Name:        "report_inconsistencies",
+		Description: "Report where the narration contradicts the game state",
+		InputSchema: narrationIssuesSchema(),
+	}
+	content, err := a.chatCompletion(ctx, messages, modelToUse, 0.0, []AnthropicTool{tool})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNarrationIssues(content)
+}
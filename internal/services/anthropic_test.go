@@ -446,3 +446,54 @@ func TestAnthropicService_ChatStream(t *testing.T) {
 		}
 	})
 }
+
+func TestAnthropicService_SystemBlocks(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewAnthropicService("test-key", "claude-3-sonnet-20240229", "claude-3-backend", log)
+
+	t.Run("no cacheable prefix", func(t *testing.T) {
+		blocks := service.systemBlocks([]chat.ChatMessage{
+			{Role: chat.ChatRoleSystem, Content: "You are a narrator."},
+			{Role: chat.ChatRoleUser, Content: "Hello"},
+		})
+		if blocks != nil {
+			t.Errorf("Expected nil blocks, got %+v", blocks)
+		}
+	})
+
+	t.Run("prefix ends in a cache breakpoint", func(t *testing.T) {
+		blocks := service.systemBlocks([]chat.ChatMessage{
+			{Role: chat.ChatRoleSystem, Content: "Narrator rules.\n\nState: dock", CachePrefix: len("Narrator rules.")},
+			{Role: chat.ChatRoleUser, Content: "Hello"},
+			{Role: chat.ChatRoleSystem, Content: "Game over."},
+		})
+		if len(blocks) != 2 {
+			t.Fatalf("Expected 2 blocks, got %+v", blocks)
+		}
+		if blocks[0].Text != "Narrator rules." || blocks[0].CacheControl == nil || blocks[0].CacheControl.Type != "ephemeral" {
+			t.Errorf("Unexpected cached block: %+v", blocks[0])
+		}
+		if blocks[1].Text != "\n\nState: dock\n\nGame over." || blocks[1].CacheControl != nil {
+			t.Errorf("Unexpected uncached block: %+v", blocks[1])
+		}
+
+		// The blocks must add up to the same prompt as the plain system string
+		systemPrompt, _ := service.splitChatMessages([]chat.ChatMessage{
+			{Role: chat.ChatRoleSystem, Content: "Narrator rules.\n\nState: dock"},
+			{Role: chat.ChatRoleSystem, Content: "Game over."},
+		})
+		if blocks[0].Text+blocks[1].Text != systemPrompt {
+			t.Errorf("Blocks %q + %q differ from system prompt %q", blocks[0].Text, blocks[1].Text, systemPrompt)
+		}
+	})
+}
+
+func TestAnthropicUsage_TotalInputTokens(t *testing.T) {
+	var usage AnthropicUsage
+	if err := json.Unmarshal([]byte(`{"input_tokens": 20, "output_tokens": 5, "cache_creation_input_tokens": 100, "cache_read_input_tokens": 1000}`), &usage); err != nil {
+		t.Fatalf("Failed to unmarshal usage: %v", err)
+	}
+	if got := usage.TotalInputTokens(); got != 1120 {
+		t.Errorf("Expected 1120 total input tokens, got %d", got)
+	}
+}
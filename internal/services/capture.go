@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// Capture call kinds, naming the LLMService method that was called
+const (
+	CaptureChat           = "chat"
+	CaptureChatStream     = "chat_stream"
+	CaptureDelta          = "delta"
+	CaptureChoices        = "choices"
+	CaptureCheckNarration = "check_narration"
+)
+
+// captureNoGame is the capture subdirectory for calls made without a game
+const captureNoGame = "_nogame"
+
+// Capture is one LLM request and its response, as written by CaptureService
+type Capture struct {
+	GameID      string                       `json:"game_id,omitempty"`
+	Turn        int                          `json:"turn"`  // Game's turn counter when the call was made
+	Seq         int64                        `json:"seq"`   // Order of the call within the process
+	Call        string                       `json:"call"`  // See the Capture constants
+	Model       string                       `json:"model"` // Model requested with WithModel, or the backend model for a delta
+	At          time.Time                    `json:"at"`
+	DurationMS  int64                        `json:"duration_ms"`
+	Temperature *float64                     `json:"temperature,omitempty"`
+	Messages    []chat.ChatMessage           `json:"messages"`
+	Response    string                       `json:"response,omitempty"` // Narration text, raw as the model produced it
+	Delta       *conditionals.GameStateDelta `json:"delta,omitempty"`
+	Choices     []string                     `json:"choices,omitempty"`
+	Issues      []state.NarrationIssue       `json:"issues,omitempty"`
+	Error       string                       `json:"error,omitempty"`
+}
+
+type captureContextKey struct{}
+
+type captureLabel struct {
+	gameID string
+	turn   int
+}
+
+// WithCaptureLabel returns a context whose LLM calls are captured under the game and
+// turn given, when the service is wrapped in a CaptureService
+func WithCaptureLabel(ctx context.Context, gameID string, turn int) context.Context {
+	return context.WithValue(ctx, captureContextKey{}, captureLabel{gameID: gameID, turn: turn})
+}
+
+// CaptureService wraps an LLMService and writes every request and response to
+// <dir>/<game id>/<turn>-<seq>-<call>.json. It's meant for prompt engineering in
+// development, not production: captures hold full prompts and are never cleaned up.
+type CaptureService struct {
+	next   LLMService
+	dir    string
+	seq    atomic.Int64
+	logger *slog.Logger
+}
+
+// NewCaptureService wraps next, writing captures under dir
+func NewCaptureService(next LLMService, dir string, logger *slog.Logger) *CaptureService {
+	return &CaptureService{
+		next:   next,
+		dir:    dir,
+		logger: logger,
+	}
+}
+
+func (c *CaptureService) InitModel(ctx context.Context, modelName string) error {
+	return c.next.InitModel(ctx, modelName)
+}
+
+func (c *CaptureService) Chat(ctx context.Context, messages []chat.ChatMessage, temperature float64) (*chat.ChatResponse, error) {
+	capture := c.start(ctx, CaptureChat, messages)
+	capture.Temperature = &temperature
+	resp, err := c.next.Chat(ctx, messages, temperature)
+	if resp != nil {
+		capture.Response = resp.Message
+	}
+	c.write(capture, err)
+	return resp, err
+}
+
+// ChatStream captures the streamed narration once the stream ends
+func (c *CaptureService) ChatStream(ctx context.Context, messages []chat.ChatMessage, temperature float64) (<-chan StreamChunk, error) {
+	capture := c.start(ctx, CaptureChatStream, messages)
+	capture.Temperature = &temperature
+	stream, err := c.next.ChatStream(ctx, messages, temperature)
+	if err != nil {
+		c.write(capture, err)
+		return nil, err
+	}
+
+	out := make(chan StreamChunk, cap(stream))
+	go func() {
+		defer close(out)
+		var narration strings.Builder
+		var streamErr error
+		for chunk := range stream {
+			narration.WriteString(chunk.Content)
+			if chunk.Error != nil {
+				streamErr = chunk.Error
+			}
+			out <- chunk
+		}
+		capture.Response = narration.String()
+		c.write(capture, streamErr)
+	}()
+	return out, nil
+}
+
+func (c *CaptureService) DeltaUpdate(ctx context.Context, messages []chat.ChatMessage) (*conditionals.GameStateDelta, string, error) {
+	capture := c.start(ctx, CaptureDelta, messages)
+	delta, model, err := c.next.DeltaUpdate(ctx, messages)
+	capture.Delta = delta
+	if model != "" {
+		capture.Model = model
+	}
+	c.write(capture, err)
+	return delta, model, err
+}
+
+func (c *CaptureService) SuggestChoices(ctx context.Context, messages []chat.ChatMessage) ([]string, error) {
+	capture := c.start(ctx, CaptureChoices, messages)
+	choices, err := c.next.SuggestChoices(ctx, messages)
+	capture.Choices = choices
+	c.write(capture, err)
+	return choices, err
+}
+
+func (c *CaptureService) CheckNarration(ctx context.Context, messages []chat.ChatMessage) ([]state.NarrationIssue, error) {
+	capture := c.start(ctx, CaptureCheckNarration, messages)
+	issues, err := c.next.CheckNarration(ctx, messages)
+	capture.Issues = issues
+	c.write(capture, err)
+	return issues, err
+}
+
+// start fills in a capture's labels before the call is made
+func (c *CaptureService) start(ctx context.Context, call string, messages []chat.ChatMessage) *Capture {
+	label, _ := ctx.Value(captureContextKey{}).(captureLabel)
+	return &Capture{
+		GameID:   label.gameID,
+		Turn:     label.turn,
+		Seq:      c.seq.Add(1),
+		Call:     call,
+		Model:    modelFromContext(ctx, ""),
+		At:       time.Now(),
+		Messages: messages,
+	}
+}
+
+// write saves a finished capture. Failures are logged; capturing never fails a call.
+func (c *CaptureService) write(capture *Capture, err error) {
+	capture.DurationMS = time.Since(capture.At).Milliseconds()
+	if err != nil {
+		capture.Error = err.Error()
+	}
+
+	gameDir := capture.GameID
+	if gameDir == "" {
+		gameDir = captureNoGame
+	}
+	dir := filepath.Join(c.dir, gameDir)
+	path := filepath.Join(dir, fmt.Sprintf("%04d-%06d-%s.json", capture.Turn, capture.Seq, capture.Call))
+
+	data, marshalErr := json.MarshalIndent(capture, "", "  ")
+	if marshalErr != nil {
+		c.logger.Warn("Failed to marshal LLM capture", "error", marshalErr, "path", path)
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		c.logger.Warn("Failed to create LLM capture directory", "error", err, "dir", dir)
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		c.logger.Warn("Failed to write LLM capture", "error", err, "path", path)
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingMock streams a fixed narration in two chunks
+type streamingMock struct {
+	MockLLMAPI
+}
+
+func (m *streamingMock) ChatStream(ctx context.Context, messages []chat.ChatMessage, _ float64) (<-chan StreamChunk, error) {
+	stream := make(chan StreamChunk, 3)
+	stream <- StreamChunk{Content: "The door "}
+	stream <- StreamChunk{Content: "creaks open."}
+	stream <- StreamChunk{Done: true}
+	close(stream)
+	return stream, nil
+}
+
+// readCaptures returns the captures written for a game, in file name order
+func readCaptures(t *testing.T, dir, gameID string) []Capture {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, gameID, "*.json"))
+	require.NoError(t, err)
+	captures := make([]Capture, len(paths))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &captures[i]))
+	}
+	return captures
+}
+
+func TestCaptureService(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewCaptureService(&streamingMock{}, dir, slog.Default())
+	messages := []chat.ChatMessage{{Role: chat.ChatRoleUser, Content: "Open the door"}}
+
+	ctx := WithCaptureLabel(WithModel(context.Background(), "narrator-model"), "game-1", 3)
+	resp, err := svc.Chat(ctx, messages, 0.7)
+	require.NoError(t, err)
+	assert.Equal(t, "Mock response", resp.Message)
+
+	stream, err := svc.ChatStream(ctx, messages, 0.7)
+	require.NoError(t, err)
+	var narration string
+	for chunk := range stream {
+		narration += chunk.Content
+	}
+	assert.Equal(t, "The door creaks open.", narration)
+
+	_, model, err := svc.DeltaUpdate(WithCaptureLabel(ctx, "game-1", 4), messages)
+	require.NoError(t, err)
+	assert.Equal(t, "mock-model", model)
+
+	captures := readCaptures(t, dir, "game-1")
+	require.Len(t, captures, 3)
+
+	assert.Equal(t, CaptureChat, captures[0].Call)
+	assert.Equal(t, 3, captures[0].Turn)
+	assert.Equal(t, "narrator-model", captures[0].Model)
+	assert.Equal(t, "Mock response", captures[0].Response)
+	require.NotNil(t, captures[0].Temperature)
+	assert.Equal(t, 0.7, *captures[0].Temperature)
+	assert.Equal(t, messages, captures[0].Messages)
+
+	assert.Equal(t, CaptureChatStream, captures[1].Call)
+	assert.Equal(t, "The door creaks open.", captures[1].Response)
+
+	assert.Equal(t, CaptureDelta, captures[2].Call)
+	assert.Equal(t, 4, captures[2].Turn)
+	assert.Equal(t, "mock-model", captures[2].Model)
+	require.NotNil(t, captures[2].Delta)
+	assert.Equal(t, "mock_location", captures[2].Delta.UserLocation)
+	assert.Less(t, captures[1].Seq, captures[2].Seq)
+}
+
+func TestCaptureService_NoGameAndErrors(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewCaptureService(&MockLLMAPI{}, dir, slog.Default())
+
+	// The mock doesn't stream, so the failed call is captured with its error
+	_, err := svc.ChatStream(context.Background(), nil, 0.5)
+	require.Error(t, err)
+
+	captures := readCaptures(t, dir, captureNoGame)
+	require.Len(t, captures, 1)
+	assert.Equal(t, CaptureChatStream, captures[0].Call)
+	assert.Empty(t, captures[0].GameID)
+	assert.Contains(t, captures[0].Error, "streaming not implemented")
+}
@@ -24,6 +24,7 @@ const (
 
 // Event represents a generic event structure
 type Event struct {
+	ID        string                 `json:"id,omitempty"` // set on buffered chat.chunk events; the SSE event ID
 	Type      EventType              `json:"type"`
 	RequestID string                 `json:"request_id,omitempty"`
 	GameID    string                 `json:"game_id,omitempty"`
@@ -33,6 +34,7 @@ type Event struct {
 // Broadcaster publishes events to Redis Pub/Sub for SSE distribution
 type Broadcaster struct {
 	redisClient *redis.Client
+	chunks      *ChunkBuffer
 	logger      *slog.Logger
 }
 
@@ -40,6 +42,7 @@ type Broadcaster struct {
 func NewBroadcaster(redisClient *redis.Client, logger *slog.Logger) *Broadcaster {
 	return &Broadcaster{
 		redisClient: redisClient,
+		chunks:      NewChunkBuffer(redisClient),
 		logger:      logger,
 	}
 }
@@ -101,7 +104,9 @@ func (b *Broadcaster) PublishRequestFailed(ctx context.Context, gameID uuid.UUID
 	return b.publishToGame(ctx, gameID, event)
 }
 
-// PublishChatChunk publishes a chat.chunk event (for streaming LLM responses)
+// PublishChatChunk publishes a chat.chunk event (for streaming LLM responses). The chunk
+// is buffered first, so a client that reconnects can resume from it; if buffering fails
+// it is still published, without an ID.
 func (b *Broadcaster) PublishChatChunk(ctx context.Context, gameID uuid.UUID, requestID string, content string, done bool) error {
 	event := Event{
 		Type:      EventTypeChatChunk,
@@ -112,6 +117,11 @@ func (b *Broadcaster) PublishChatChunk(ctx context.Context, gameID uuid.UUID, re
 			"done":    done,
 		},
 	}
+	id, err := b.chunks.Append(ctx, gameID, event)
+	if err != nil {
+		b.logger.Warn("Failed to buffer chat chunk", "error", err, "game_id", gameID.String())
+	}
+	event.ID = id
 	return b.publishToGame(ctx, gameID, event)
 }
 
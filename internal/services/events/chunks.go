@@ -0,0 +1,115 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// chunkBufferLength caps how many chat chunks each game's buffer keeps
+	chunkBufferLength = 1000
+	// ChunkBufferTTL is how long a game's chat chunks stay buffered after its last chunk.
+	// A client that reconnects later than this has to sync the game state instead.
+	ChunkBufferTTL = 2 * time.Minute
+)
+
+// ChunkBuffer keeps each game's recent chat.chunk events in a Redis stream, so an SSE
+// client whose connection drops mid-narration can resume from the last chunk it received.
+// Entry IDs are the SSE event IDs.
+type ChunkBuffer struct {
+	redisClient *redis.Client
+}
+
+// NewChunkBuffer creates a new chat chunk buffer
+func NewChunkBuffer(redisClient *redis.Client) *ChunkBuffer {
+	return &ChunkBuffer{redisClient: redisClient}
+}
+
+// Append buffers a chat.chunk event and returns its ID
+func (c *ChunkBuffer) Append(ctx context.Context, gameID uuid.UUID, event Event) (string, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal chat chunk: %w", err)
+	}
+
+	key := chunkBufferKey(gameID)
+	pipe := c.redisClient.TxPipeline()
+	add := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: chunkBufferLength,
+		Approx: true,
+		Values: map[string]interface{}{"event": data},
+	})
+	pipe.Expire(ctx, key, ChunkBufferTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to buffer chat chunk: %w", err)
+	}
+	return add.Val(), nil
+}
+
+// Since returns the game's buffered chat chunks after the one with ID after, oldest first
+func (c *ChunkBuffer) Since(ctx context.Context, gameID uuid.UUID, after string) ([]Event, error) {
+	entries, err := c.redisClient.XRange(ctx, chunkBufferKey(gameID), "("+after, "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat chunk buffer: %w", err)
+	}
+
+	chunks := make([]Event, 0, len(entries))
+	for _, entry := range entries {
+		data, _ := entry.Values["event"].(string)
+		var event Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue
+		}
+		event.ID = entry.ID
+		chunks = append(chunks, event)
+	}
+	return chunks, nil
+}
+
+// ValidChunkID reports whether id has the form of a chunk ID, "<milliseconds>-<sequence>"
+func ValidChunkID(id string) bool {
+	_, _, ok := parseChunkID(id)
+	return ok
+}
+
+// ChunkIDAfter reports whether chunk ID a was buffered after chunk ID b. IDs that
+// don't parse are never after anything.
+func ChunkIDAfter(a, b string) bool {
+	aMs, aSeq, aOK := parseChunkID(a)
+	bMs, bSeq, bOK := parseChunkID(b)
+	if !aOK || !bOK {
+		return false
+	}
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+func parseChunkID(id string) (ms, seq uint64, ok bool) {
+	msStr, seqStr, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err = strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+func chunkBufferKey(gameID uuid.UUID) string {
+	return "chat-chunks:" + gameID.String()
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcaster_BuffersChatChunks(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	broadcaster := NewBroadcaster(client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	buffer := NewChunkBuffer(client)
+	ctx := context.Background()
+	gameID := uuid.New()
+
+	for _, content := range []string{"The fog ", "lifts ", "slowly."} {
+		require.NoError(t, broadcaster.PublishChatChunk(ctx, gameID, "req-1", content, content == "slowly."))
+	}
+
+	chunks, err := buffer.Since(ctx, gameID, "0-0")
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "The fog ", chunks[0].Data["content"])
+	assert.Equal(t, "req-1", chunks[0].RequestID)
+	assert.True(t, ChunkIDAfter(chunks[1].ID, chunks[0].ID))
+
+	missed, err := buffer.Since(ctx, gameID, chunks[0].ID)
+	require.NoError(t, err)
+	require.Len(t, missed, 2, "only chunks after the last one received should be returned")
+	assert.Equal(t, "lifts ", missed[0].Data["content"])
+	assert.Equal(t, true, missed[1].Data["done"])
+
+	assert.Equal(t, ChunkBufferTTL, mr.TTL(chunkBufferKey(gameID)))
+}
+
+func TestChunkIDAfter(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1700000000001-0", "1700000000000-5", true},
+		{"1700000000000-6", "1700000000000-5", true},
+		{"1700000000000-5", "1700000000000-5", false},
+		{"1700000000000-4", "1700000000000-5", false},
+		{"bogus", "1700000000000-5", false},
+		{"1700000000000-5", "", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ChunkIDAfter(tt.a, tt.b), "ChunkIDAfter(%q, %q)", tt.a, tt.b)
+	}
+	assert.True(t, ValidChunkID("1700000000000-0"))
+	assert.False(t, ValidChunkID("abc-1"))
+	assert.False(t, ValidChunkID("42"))
+}
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// spectatorFeedLength caps how many narrations each game's feed keeps
+	spectatorFeedLength = 200
+	// spectatorFeedTTL expires the feed of a game nobody has played for a while
+	spectatorFeedTTL = 24 * time.Hour
+	// spectatorRating is the rating spectator narration is filtered for, whatever the
+	// game's own rating: spectators haven't chosen the game's content
+	spectatorRating = "G"
+)
+
+// Narration is one narrated turn in a spectator feed
+type Narration struct {
+	ID   string    `json:"id"` // feed entry ID, usable as an SSE Last-Event-ID
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
+
+// SpectatorFeed mirrors the narration of public games to a Redis stream per game, which
+// spectators read after a delay. The feed carries narration only, never the player's
+// messages, command replies, or game state, and profanity is filtered as for a G rating,
+// with the word lists for the narration's language.
+type SpectatorFeed struct {
+	redisClient *redis.Client
+	profanity   *textfilter.ProfanityFilter
+	logger      *slog.Logger
+}
+
+// NewSpectatorFeed creates a new spectator feed
+func NewSpectatorFeed(redisClient *redis.Client, logger *slog.Logger) *SpectatorFeed {
+	return &SpectatorFeed{
+		redisClient: redisClient,
+		profanity:   textfilter.NewProfanityFilter(),
+		logger:      logger,
+	}
+}
+
+// WithProfanityFilter replaces the built-in profanity filter with one carrying
+// configured word lists
+func (f *SpectatorFeed) WithProfanityFilter(filter *textfilter.ProfanityFilter) *SpectatorFeed {
+	f.profanity = filter
+	return f
+}
+
+// Publish appends a narration to the game's feed
+func (f *SpectatorFeed) Publish(ctx context.Context, gameID uuid.UUID, narration string) error {
+	filter := f.profanity.ForLocale(chat.DetectLanguage(narration))
+	text := strings.TrimSpace(filter.FilterText(narration, spectatorRating))
+	if text == "" {
+		return nil
+	}
+
+	key := spectatorKey(gameID)
+	pipe := f.redisClient.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: spectatorFeedLength,
+		Approx: true,
+		Values: map[string]interface{}{"text": text},
+	})
+	pipe.Expire(ctx, key, spectatorFeedTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		f.logger.Error("Failed to publish spectator narration", "error", err, "game_id", gameID.String())
+		return fmt.Errorf("failed to publish spectator narration: %w", err)
+	}
+	return nil
+}
+
+// Since returns the game's narration published after the entry with ID after, up to and
+// including until. An empty after reads from the start of the feed.
+func (f *SpectatorFeed) Since(ctx context.Context, gameID uuid.UUID, after string, until time.Time) ([]Narration, error) {
+	start := "-"
+	if after != "" {
+		start = "(" + after
+	}
+	end := strconv.FormatInt(until.UnixMilli(), 10)
+
+	entries, err := f.redisClient.XRange(ctx, spectatorKey(gameID), start, end).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spectator feed: %w", err)
+	}
+
+	narrations := make([]Narration, 0, len(entries))
+	for _, entry := range entries {
+		text, _ := entry.Values["text"].(string)
+		narrations = append(narrations, Narration{ID: entry.ID, Text: text, At: entryTime(entry.ID)})
+	}
+	return narrations, nil
+}
+
+// FeedPosition returns the feed entry ID for a point in time, to read narration
+// published after it
+func FeedPosition(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10) + "-0"
+}
+
+func spectatorKey(gameID uuid.UUID) string {
+	return "spectate:" + gameID.String()
+}
+
+// entryTime returns the time a stream entry was added, from the milliseconds in its ID
+func entryTime(id string) time.Time {
+	ms, _, _ := strings.Cut(id, "-")
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(n).UTC()
+}
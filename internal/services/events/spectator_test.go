@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFeed(t *testing.T) (*SpectatorFeed, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewSpectatorFeed(client, slog.New(slog.NewTextHandler(io.Discard, nil))), mr
+}
+
+func TestSpectatorFeed_PublishAndRead(t *testing.T) {
+	feed, mr := newTestFeed(t)
+	ctx := context.Background()
+	gameID := uuid.New()
+
+	require.NoError(t, feed.Publish(ctx, gameID, "The captain swears: \"Damn this fog!\""))
+	require.NoError(t, feed.Publish(ctx, gameID, "   "))
+	require.NoError(t, feed.Publish(ctx, gameID, "The fog lifts."))
+
+	narrations, err := feed.Since(ctx, gameID, "", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, narrations, 2, "blank narration should be skipped")
+	assert.Equal(t, "The captain swears: \"Dang this fog!\"", narrations[0].Text, "profanity should be filtered whatever the rating")
+	assert.Equal(t, "The fog lifts.", narrations[1].Text)
+	assert.WithinDuration(t, time.Now(), narrations[0].At, time.Minute)
+
+	rest, err := feed.Since(ctx, gameID, narrations[0].ID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Equal(t, narrations[1].ID, rest[0].ID)
+
+	other, err := feed.Since(ctx, uuid.New(), "", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	assert.Greater(t, mr.TTL("spectate:"+gameID.String()), time.Duration(0), "the feed should expire")
+}
+
+func TestSpectatorFeed_Delay(t *testing.T) {
+	feed, _ := newTestFeed(t)
+	ctx := context.Background()
+	gameID := uuid.New()
+
+	require.NoError(t, feed.Publish(ctx, gameID, "The ship sets sail."))
+
+	narrations, err := feed.Since(ctx, gameID, "", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, narrations, "narration newer than the delay should be held back")
+
+	narrations, err = feed.Since(ctx, gameID, FeedPosition(time.Now().Add(-time.Minute)), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, narrations, 1)
+}
@@ -8,12 +8,20 @@ import (
 
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/state"
 )
 
 const (
 	DefaultTemperature = 0.4
 	DefaultMaxTokens   = 512
 	BackendMaxTokens   = 512
+
+	// MinChoices and MaxChoices bound the suggested actions returned in choices mode
+	MinChoices = 2
+	MaxChoices = 4
+
+	// MaxNarrationIssues caps the inconsistencies returned by a consistency check
+	MaxNarrationIssues = 5
 )
 
 type StreamChunk struct {
@@ -21,6 +29,7 @@ type StreamChunk struct {
 	Done     bool   `json:"done"`
 	Error    error  `json:"-"`               // Don't serialize directly
 	ErrorMsg string `json:"error,omitempty"` // Serialize error message as string
+	Trailer  string `json:"-"`               // Narration trailer removed from the stream, on the final chunk
 }
 
 func (sc StreamChunk) MarshalJSON() ([]byte, error) {
@@ -39,6 +48,75 @@ func (sc StreamChunk) MarshalJSON() ([]byte, error) {
 	return json.Marshal(aux)
 }
 
+type modelContextKey struct{}
+
+// WithModel returns a context that routes LLM calls made with it to modelName
+// instead of the service's configured model. The backend model, when configured,
+// is still used for DeltaUpdate, SuggestChoices, and CheckNarration. An empty name returns ctx unchanged.
+func WithModel(ctx context.Context, modelName string) context.Context {
+	if modelName == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, modelContextKey{}, modelName)
+}
+
+// modelFromContext returns the model set by WithModel, or fallback if none
+func modelFromContext(ctx context.Context, fallback string) string {
+	if modelName, ok := ctx.Value(modelContextKey{}).(string); ok {
+		return modelName
+	}
+	return fallback
+}
+
+type maxTokensContextKey struct{}
+
+// WithMaxTokens returns a context whose narration calls, Chat and ChatStream, stop after
+// maxTokens output tokens instead of DefaultMaxTokens. Backend calls keep BackendMaxTokens.
+// Zero or less returns ctx unchanged.
+func WithMaxTokens(ctx context.Context, maxTokens int) context.Context {
+	if maxTokens <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, maxTokensContextKey{}, maxTokens)
+}
+
+// maxTokensFromContext returns the limit set by WithMaxTokens, or fallback if none
+func maxTokensFromContext(ctx context.Context, fallback int) int {
+	if maxTokens, ok := ctx.Value(maxTokensContextKey{}).(int); ok {
+		return maxTokens
+	}
+	return fallback
+}
+
+// TokenUsage is the token count reported by the provider for one LLM call
+type TokenUsage struct {
+	Model        string
+	InputTokens  int
+	OutputTokens int
+}
+
+// UsageRecorder receives the token usage of each LLM call made with a context from WithUsageRecorder
+type UsageRecorder func(ctx context.Context, usage TokenUsage)
+
+type usageContextKey struct{}
+
+// WithUsageRecorder returns a context whose LLM calls report their token usage to rec
+func WithUsageRecorder(ctx context.Context, rec UsageRecorder) context.Context {
+	if rec == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, usageContextKey{}, rec)
+}
+
+// recordUsage passes a call's token usage to the context's recorder, if any
+func recordUsage(ctx context.Context, modelName string, inputTokens, outputTokens int) {
+	rec, ok := ctx.Value(usageContextKey{}).(UsageRecorder)
+	if !ok || inputTokens+outputTokens == 0 {
+		return
+	}
+	rec(ctx, TokenUsage{Model: modelName, InputTokens: inputTokens, OutputTokens: outputTokens})
+}
+
 // LLMService defines the interface for interacting with the LLM API
 type LLMService interface {
 	InitModel(ctx context.Context, modelName string) error
@@ -50,6 +128,12 @@ type LLMService interface {
 	ChatStream(ctx context.Context, messages []chat.ChatMessage, temperature float64) (<-chan StreamChunk, error)
 
 	DeltaUpdate(ctx context.Context, messages []chat.ChatMessage) (*conditionals.GameStateDelta, string, error)
+
+	// SuggestChoices asks the backend model for suggested next player actions
+	SuggestChoices(ctx context.Context, messages []chat.ChatMessage) ([]string, error)
+
+	// CheckNarration asks the backend model where a narration contradicts the game state
+	CheckNarration(ctx context.Context, messages []chat.ChatMessage) ([]state.NarrationIssue, error)
 }
 
 // parseDeltaUpdateResponse parses an LLM response text into a DeltaUpdate struct.
@@ -115,3 +199,99 @@ func parseDeltaUpdateResponse(responseText string) (*conditionals.GameStateDelta
 
 	return &metaUpdate, nil
 }
+
+// parseChoicesResponse parses a {"choices": [...]} LLM response into a list of actions.
+// Blank and duplicate entries are dropped and the list is capped at MaxChoices.
+// Returns an error if fewer than MinChoices usable actions remain.
+func parseChoicesResponse(responseText string) ([]string, error) {
+	mTxt := strings.TrimSpace(responseText)
+	if start := strings.Index(mTxt, "{"); start >= 0 {
+		if end := strings.LastIndex(mTxt, "}"); end > start {
+			mTxt = mTxt[start : end+1]
+		}
+	}
+
+	var parsed struct {
+		Choices []string `json:"choices"`
+	}
+	if err := json.Unmarshal([]byte(mTxt), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse choices. Original response: %q, Error: %w", responseText, err)
+	}
+
+	seen := make(map[string]bool)
+	choices := make([]string, 0, MaxChoices)
+	for _, c := range parsed.Choices {
+		c = strings.TrimSpace(c)
+		key := strings.ToLower(c)
+		if c == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		choices = append(choices, c)
+		if len(choices) == MaxChoices {
+			break
+		}
+	}
+
+	if len(choices) < MinChoices {
+		return nil, fmt.Errorf("expected at least %d choices, got %d", MinChoices, len(choices))
+	}
+	return choices, nil
+}
+
+// narrationIssueKinds are the kinds a consistency check may report, for the response schemas
+var narrationIssueKinds = []string{state.IssueItemNotHeld, state.IssueNPCNotPresent, state.IssueBlockedExit}
+
+// narrationIssuesSchema is the JSON schema of a consistency check's response
+func narrationIssuesSchema() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"issues": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties": map[string]any{
+						"kind":   map[string]any{"type": "string", "enum": narrationIssueKinds},
+						"detail": map[string]any{"type": "string"},
+					},
+					"required": []string{"kind", "detail"},
+				},
+			},
+		},
+		"required": []string{"issues"},
+	}
+}
+
+// parseNarrationIssues parses a consistency check response. Issues of unknown kinds or
+// without a detail are dropped, and at most MaxNarrationIssues are kept.
+func parseNarrationIssues(responseText string) ([]state.NarrationIssue, error) {
+	mTxt := strings.TrimSpace(responseText)
+	if start := strings.Index(mTxt, "{"); start >= 0 {
+		if end := strings.LastIndex(mTxt, "}"); end > start {
+			mTxt = mTxt[start : end+1]
+		}
+	}
+
+	var parsed struct {
+		Issues []state.NarrationIssue `json:"issues"`
+	}
+	if err := json.Unmarshal([]byte(mTxt), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse narration issues. Original response: %q, Error: %w", responseText, err)
+	}
+
+	var issues []state.NarrationIssue
+	for _, issue := range parsed.Issues {
+		issue.Detail = strings.TrimSpace(issue.Detail)
+		if !state.ValidNarrationIssueKind(issue.Kind) || issue.Detail == "" {
+			continue
+		}
+		issues = append(issues, issue)
+		if len(issues) == MaxNarrationIssues {
+			break
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChoicesResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "clean JSON",
+			response: `{"choices": ["Open the gate", "Talk to the guard"]}`,
+			expected: []string{"Open the gate", "Talk to the guard"},
+		},
+		{
+			name:     "JSON wrapped in prose and code fence",
+			response: "Here you go:\n```json\n{\"choices\": [\"Look around\", \"Go north\", \"Wait\"]}\n```",
+			expected: []string{"Look around", "Go north", "Wait"},
+		},
+		{
+			name:     "blank and duplicate entries dropped",
+			response: `{"choices": ["Look around", "  ", "look around", "Go north"]}`,
+			expected: []string{"Look around", "Go north"},
+		},
+		{
+			name:     "capped at max choices",
+			response: `{"choices": ["a", "b", "c", "d", "e"]}`,
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "too few choices",
+			response: `{"choices": ["Look around"]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid JSON",
+			response: `{choices: nope}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choices, err := parseChoicesResponse(tt.response)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, choices)
+		})
+	}
+}
+
+func TestParseNarrationIssues(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected []state.NarrationIssue
+		wantErr  bool
+	}{
+		{
+			name:     "clean JSON",
+			response: `{"issues": [{"kind": "npc_not_present", "detail": "Gibbs speaks, but he is in the tavern."}]}`,
+			expected: []state.NarrationIssue{{Kind: state.IssueNPCNotPresent, Detail: "Gibbs speaks, but he is in the tavern."}},
+		},
+		{
+			name:     "no issues",
+			response: `{"issues": []}`,
+			expected: nil,
+		},
+		{
+			name:     "JSON wrapped in a code fence",
+			response: "```json\n{\"issues\": [{\"kind\": \"item_not_held\", \"detail\": \"The player has no key.\"}]}\n```",
+			expected: []state.NarrationIssue{{Kind: state.IssueItemNotHeld, Detail: "The player has no key."}},
+		},
+		{
+			name:     "unknown kinds and blank details dropped",
+			response: `{"issues": [{"kind": "tone", "detail": "Too grim."}, {"kind": "blocked_exit", "detail": " "}, {"kind": "blocked_exit", "detail": "The gate is locked."}]}`,
+			expected: []state.NarrationIssue{{Kind: state.IssueBlockedExit, Detail: "The gate is locked."}},
+		},
+		{
+			name:     "capped at max issues",
+			response: `{"issues": [{"kind": "blocked_exit", "detail": "a"}, {"kind": "blocked_exit", "detail": "b"}, {"kind": "blocked_exit", "detail": "c"}, {"kind": "blocked_exit", "detail": "d"}, {"kind": "blocked_exit", "detail": "e"}, {"kind": "blocked_exit", "detail": "f"}]}`,
+			expected: []state.NarrationIssue{
+				{Kind: state.IssueBlockedExit, Detail: "a"},
+				{Kind: state.IssueBlockedExit, Detail: "b"},
+				{Kind: state.IssueBlockedExit, Detail: "c"},
+				{Kind: state.IssueBlockedExit, Detail: "d"},
+				{Kind: state.IssueBlockedExit, Detail: "e"},
+			},
+		},
+		{
+			name:     "invalid JSON",
+			response: `{issues: nope}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := parseNarrationIssues(tt.response)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, issues)
+		})
+	}
+}
+
+func TestWithModel(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "default", modelFromContext(ctx, "default"))
+	assert.Equal(t, "default", modelFromContext(WithModel(ctx, ""), "default"))
+	assert.Equal(t, "override", modelFromContext(WithModel(ctx, "override"), "default"))
+}
+
+func TestWithMaxTokens(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, DefaultMaxTokens, maxTokensFromContext(ctx, DefaultMaxTokens))
+	assert.Equal(t, DefaultMaxTokens, maxTokensFromContext(WithMaxTokens(ctx, 0), DefaultMaxTokens))
+	assert.Equal(t, 150, maxTokensFromContext(WithMaxTokens(ctx, 150), DefaultMaxTokens))
+}
+
+func TestWithUsageRecorder(t *testing.T) {
+	var got []TokenUsage
+	ctx := WithUsageRecorder(context.Background(), func(_ context.Context, u TokenUsage) {
+		got = append(got, u)
+	})
+
+	recordUsage(ctx, "claude-haiku-4-5", 120, 30)
+	recordUsage(ctx, "claude-haiku-4-5", 0, 0)
+	recordUsage(context.Background(), "claude-haiku-4-5", 5, 5)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, TokenUsage{Model: "claude-haiku-4-5", InputTokens: 120, OutputTokens: 30}, got[0])
+}
+
+// FuzzParseDeltaUpdateResponse feeds arbitrary model output to the delta parser.
+// It must never panic, and anything it accepts must survive a JSON round trip.
+func FuzzParseDeltaUpdateResponse(f *testing.F) {
+	seeds := []string{
+		"",
+		"{}",
+		`{"user_location": "tavern", "vars": {"door_open": "true"}}`,
+		"```json\n{\"item_events\": [{\"item\": \"key\", \"action\": \"acquire\", \"from\": {\"type\": \"location\", \"name\": \"Cellar\"}}]}\n```",
+		`Here is the update: {"npc_events": [{"npc_id": "gibbs", "set_following": "pc"}], "game_ended": false}`,
+		"```\njson\n{\"scene_change\": {\"to\": \"docks\", \"reason\": \"sailed\"}}\n```",
+		`{"monster_events": [{"action": "spawn", "instance_id": "rat_1", "template": "giant_rat", "location": "cellar"}]}`,
+		"```",
+		"{",
+		`{"item_events": null, "vars": []}`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, response string) {
+		delta, err := parseDeltaUpdateResponse(response)
+		if err != nil {
+			require.Nil(t, delta)
+			return
+		}
+		if delta == nil {
+			require.Empty(t, response)
+			return
+		}
+
+		encoded, err := json.Marshal(delta)
+		require.NoError(t, err)
+		reparsed, err := parseDeltaUpdateResponse(string(encoded))
+		require.NoError(t, err, "parser rejected its own output %s", encoded)
+		reencoded, err := json.Marshal(reparsed)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(encoded), string(reencoded))
+	})
+}
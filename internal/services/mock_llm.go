@@ -9,6 +9,7 @@ import (
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
 	"github.com/jwebster45206/story-engine/pkg/prompts"
+	"github.com/jwebster45206/story-engine/pkg/state"
 )
 
 // MockLLMAPI is a mock implementation of LLMService for testing
@@ -77,6 +78,16 @@ func (m *MockLLMAPI) DeltaUpdate(ctx context.Context, messages []chat.ChatMessag
 	}, "mock-model", nil
 }
 
+// SuggestChoices mocks choice suggestions for choices mode
+func (m *MockLLMAPI) SuggestChoices(ctx context.Context, messages []chat.ChatMessage) ([]string, error) {
+	return []string{"Look around", "Go north"}, nil
+}
+
+// CheckNarration mocks a consistency check that finds nothing wrong
+func (m *MockLLMAPI) CheckNarration(ctx context.Context, messages []chat.ChatMessage) ([]state.NarrationIssue, error) {
+	return nil, nil
+}
+
 type GenerateResponseCall struct {
 	Messages []chat.ChatMessage
 }
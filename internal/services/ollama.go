@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -10,23 +11,60 @@ import (
 	"time"
 
 	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/state"
 )
 
+// ollamaDefaultURL is where a local Ollama listens by default
+const ollamaDefaultURL = "http://localhost:11434"
+
 // OllamaService implements the LLMService interface for Ollama API
 type OllamaService struct {
-	baseURL    string
-	modelName  string
-	httpClient *http.Client
-	logger     *slog.Logger
+	baseURL          string
+	modelName        string
+	backendModelName string
+	httpClient       *http.Client
+	logger           *slog.Logger
+}
+
+// ollamaChatRequest is the body of an Ollama /api/chat request
+type ollamaChatRequest struct {
+	Model    string             `json:"model"`
+	Messages []chat.ChatMessage `json:"messages"`
+	Stream   bool               `json:"stream"`
+	Format   any                `json:"format,omitempty"` // JSON schema the reply must match
+	Options  ollamaOptions      `json:"options"`
 }
 
-// NewOllamaService creates a new Ollama service instance
-func NewOllamaService(baseURL string, modelName string, logger *slog.Logger) *OllamaService {
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"` // Output token limit; the model's own when unset
+}
+
+// ollamaChatResponse is an Ollama /api/chat reply, or one line of a streamed reply.
+// Token counts are only set once done.
+type ollamaChatResponse struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	Error           string `json:"error,omitempty"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+// NewOllamaService creates a new Ollama service instance. An empty baseURL uses a local Ollama.
+func NewOllamaService(baseURL string, modelName string, backendModelName string, logger *slog.Logger) *OllamaService {
+	if baseURL == "" {
+		baseURL = ollamaDefaultURL
+	}
 	return &OllamaService{
-		baseURL:   baseURL,
-		modelName: modelName,
+		baseURL:          baseURL,
+		modelName:        modelName,
+		backendModelName: backendModelName,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			// Local models can be slow, especially on CPU
+			Timeout: 5 * time.Minute,
 		},
 		logger: logger,
 	}
@@ -40,20 +78,26 @@ func (s *OllamaService) InitModel(ctx context.Context, modelName string) error {
 		return fmt.Errorf("ollama service is not ready: %w", err)
 	}
 
-	ready, err := s.isModelReady(ctx, modelName)
-	if err != nil {
-		return fmt.Errorf("failed to check model readiness: %w", err)
+	models := []string{modelName}
+	if s.backendModelName != "" && s.backendModelName != modelName {
+		models = append(models, s.backendModelName)
 	}
+	for _, model := range models {
+		ready, err := s.isModelReady(ctx, model)
+		if err != nil {
+			return fmt.Errorf("failed to check model readiness: %w", err)
+		}
 
-	if !ready {
-		// Pull the model if it's not available
-		s.logger.Info("Model not found, pulling it", "model", modelName)
-		if err := s.pullModel(ctx, modelName); err != nil {
-			return fmt.Errorf("failed to pull model: %w", err)
+		if !ready {
+			// Pull the model if it's not available
+			s.logger.Info("Model not found, pulling it", "model", model)
+			if err := s.pullModel(ctx, model); err != nil {
+				return fmt.Errorf("failed to pull model: %w", err)
+			}
+			s.logger.Info("Model pulled successfully", "model", model)
+		} else {
+			s.logger.Info("Model already available", "model", model)
 		}
-		s.logger.Info("Model pulled successfully", "model", modelName)
-	} else {
-		s.logger.Info("Model already available", "model", modelName)
 	}
 
 	return nil
@@ -64,20 +108,181 @@ func (s *OllamaService) Chat(ctx context.Context, messages []chat.ChatMessage, t
 	return s.GetChatResponse(ctx, messages, temperature)
 }
 
-// ChatStream generates a streaming chat response using the Ollama API
+// ChatStream generates a streaming chat response using the Ollama API.
+// Ollama streams one JSON object per line, the last with done set.
 func (s *OllamaService) ChatStream(ctx context.Context, messages []chat.ChatMessage, temperature float64) (<-chan StreamChunk, error) {
-	return nil, fmt.Errorf("streaming not implemented for Ollama")
+	modelName := modelFromContext(ctx, s.modelName)
+	resp, err := s.send(ctx, ollamaChatRequest{
+		Model:    modelName,
+		Messages: messages,
+		Stream:   true,
+		Options:  ollamaOptions{Temperature: temperature, NumPredict: maxTokensFromContext(ctx, 0)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chunkChan := make(chan StreamChunk, 10)
+
+	go func() {
+		defer func() { _ = resp.Body.Close() }()
+		defer close(chunkChan)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			select {
+			case <-ctx.Done():
+				chunkChan <- StreamChunk{Error: ctx.Err()}
+				return
+			default:
+			}
+
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+
+			var streamResp ollamaChatResponse
+			if err := json.Unmarshal(line, &streamResp); err != nil {
+				chunkChan <- StreamChunk{Error: fmt.Errorf("failed to decode streaming response: %w", err)}
+				return
+			}
+			if streamResp.Error != "" {
+				chunkChan <- StreamChunk{Error: fmt.Errorf("ollama API error: %s", streamResp.Error)}
+				return
+			}
+
+			if streamResp.Done {
+				recordUsage(ctx, modelName, streamResp.PromptEvalCount, streamResp.EvalCount)
+			}
+			chunkChan <- StreamChunk{
+				Content: streamResp.Message.Content,
+				Done:    streamResp.Done,
+			}
+			if streamResp.Done {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			chunkChan <- StreamChunk{Error: fmt.Errorf("error reading stream: %w", err)}
+		}
+	}()
+
+	return chunkChan, nil
 }
 
 // GetChatResponse generates a chat response using the Ollama API
 func (s *OllamaService) GetChatResponse(ctx context.Context, messages []chat.ChatMessage, temperature float64) (*chat.ChatResponse, error) {
-	reqBody := map[string]interface{}{
-		"model":       s.modelName,
-		"messages":    messages,
-		"stream":      false,
-		"temperature": temperature,
+	content, err := s.chatCompletion(ctx, messages, modelFromContext(ctx, s.modelName), temperature, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chat.ChatResponse{
+		Message: content,
+	}, nil
+}
+
+// DeltaUpdate asks the backend model for the gamestate delta, constrained to the delta schema
+func (s *OllamaService) DeltaUpdate(ctx context.Context, messages []chat.ChatMessage) (*conditionals.GameStateDelta, string, error) {
+	modelToUse := s.backendModel(ctx)
+
+	content, err := s.chatCompletion(ctx, messages, modelToUse, 0.0, conditionals.DeltaSchema())
+	if err != nil {
+		return nil, "", err
+	}
+
+	deltaUpdate, err := parseDeltaUpdateResponse(content)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return deltaUpdate, modelToUse, nil
+}
+
+// SuggestChoices asks the backend model for suggested next actions using Ollama
+func (s *OllamaService) SuggestChoices(ctx context.Context, messages []chat.ChatMessage) ([]string, error) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"choices": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+		},
+		"required": []string{"choices"},
+	}
+	content, err := s.chatCompletion(ctx, messages, s.backendModel(ctx), 0.0, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseChoicesResponse(content)
+}
+
+// CheckNarration asks the backend model where a narration contradicts the game state using Ollama
+func (s *OllamaService) CheckNarration(ctx context.Context, messages []chat.ChatMessage) ([]state.NarrationIssue, error) {
+	content, err := s.chatCompletion(ctx, messages, s.backendModel(ctx), 0.0, narrationIssuesSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNarrationIssues(content)
+}
+
+// backendModel returns the model for backend operations
+func (s *OllamaService) backendModel(ctx context.Context) string {
+	if s.backendModelName != "" {
+		return s.backendModelName
+	}
+	return modelFromContext(ctx, s.modelName)
+}
+
+// chatCompletion makes a non-streaming chat request and returns the reply's content.
+// A non-nil format is a JSON schema the reply must match. Only replies without one are
+// narration, so only they are held to the context's WithMaxTokens limit.
+func (s *OllamaService) chatCompletion(ctx context.Context, messages []chat.ChatMessage, modelName string, temperature float64, format any) (string, error) {
+	options := ollamaOptions{Temperature: temperature}
+	if format == nil {
+		options.NumPredict = maxTokensFromContext(ctx, 0)
+	}
+	resp, err := s.send(ctx, ollamaChatRequest{
+		Model:    modelName,
+		Messages: messages,
+		Format:   format,
+		Options:  options,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close() // Ignore error in defer
+	}()
+
+	// Read the full response body for logging
+	var responseBody bytes.Buffer
+	if _, err := responseBody.ReadFrom(resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var ollamaResp ollamaChatResponse
+	if err := json.Unmarshal(responseBody.Bytes(), &ollamaResp); err != nil {
+		s.logger.Error("Failed to decode Ollama response",
+			"error", err,
+			"response_body", responseBody.String())
+		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	recordUsage(ctx, modelName, ollamaResp.PromptEvalCount, ollamaResp.EvalCount)
+
+	return ollamaResp.Message.Content, nil
+}
+
+// send posts a chat request to Ollama. The caller closes the response body.
+func (s *OllamaService) send(ctx context.Context, reqBody ollamaChatRequest) (*http.Response, error) {
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -85,12 +290,12 @@ func (s *OllamaService) GetChatResponse(ctx context.Context, messages []chat.Cha
 
 	url := s.baseURL + "/api/chat"
 
-	// Log the full request details
-	s.logger.Info("Making Ollama chat request",
+	s.logger.DebugContext(ctx, "Making Ollama chat request",
 		"url", url,
-		"model", s.modelName,
-		"message_count", len(messages),
-		"request_body", string(jsonBody))
+		"model", reqBody.Model,
+		"message_count", len(reqBody.Messages),
+		"stream", reqBody.Stream,
+		"structured", reqBody.Format != nil)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
@@ -103,41 +308,19 @@ func (s *OllamaService) GetChatResponse(ctx context.Context, messages []chat.Cha
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
-	defer func() {
-		_ = resp.Body.Close() // Ignore error in defer
-	}()
-
-	// Read the full response body for logging
-	var responseBody bytes.Buffer
-	if _, err := responseBody.ReadFrom(resp.Body); err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-	responseBodyStr := responseBody.String()
 
 	if resp.StatusCode != http.StatusOK {
+		var responseBody bytes.Buffer
+		_, _ = responseBody.ReadFrom(resp.Body)
+		_ = resp.Body.Close()
 		s.logger.Error("Ollama API returned error",
 			"status_code", resp.StatusCode,
 			"status", resp.Status,
-			"response_body", responseBodyStr)
+			"response_body", responseBody.String())
 		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
 	}
 
-	var ollamaResp struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	}
-
-	if err := json.NewDecoder(bytes.NewReader(responseBody.Bytes())).Decode(&ollamaResp); err != nil {
-		s.logger.Error("Failed to decode Ollama response",
-			"error", err,
-			"response_body", responseBodyStr)
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return &chat.ChatResponse{
-		Message: ollamaResp.Message.Content,
-	}, nil
+	return resp, nil
 }
 
 // isModelReady checks if the specified model is available
@@ -178,8 +361,9 @@ func (s *OllamaService) isModelReady(ctx context.Context, modelName string) (boo
 
 // pullModel pulls a model from Ollama
 func (s *OllamaService) pullModel(ctx context.Context, modelName string) error {
-	reqBody := map[string]string{
-		"name": modelName,
+	reqBody := map[string]any{
+		"name":   modelName,
+		"stream": false,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
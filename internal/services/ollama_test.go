@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ LLMService = (*OllamaService)(nil)
+
+// newFakeOllama serves /api/chat, recording each request and replying with reply,
+// or with streamed lines for stream requests
+func newFakeOllama(t *testing.T, reply string, streamed []string) (*httptest.Server, *[]ollamaChatRequest) {
+	t.Helper()
+	var requests []ollamaChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+
+		if req.Stream {
+			for _, line := range streamed {
+				_, _ = w.Write([]byte(line + "\n"))
+			}
+			return
+		}
+		resp := ollamaChatResponse{Done: true, PromptEvalCount: 10, EvalCount: 5}
+		resp.Message.Content = reply
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestOllamaService_ChatStream(t *testing.T) {
+	server, requests := newFakeOllama(t, "", []string{
+		`{"message":{"content":"The tide "},"done":false}`,
+		`{"message":{"content":"turns."},"done":false}`,
+		`{"message":{"content":""},"done":true,"prompt_eval_count":12,"eval_count":3}`,
+	})
+	service := NewOllamaService(server.URL, "llama3", "", slog.Default())
+
+	stream, err := service.ChatStream(context.Background(), []chat.ChatMessage{{Role: chat.ChatRoleUser, Content: "wait"}}, 0.7)
+	require.NoError(t, err)
+
+	var narration strings.Builder
+	var done bool
+	for chunk := range stream {
+		require.NoError(t, chunk.Error)
+		narration.WriteString(chunk.Content)
+		done = done || chunk.Done
+	}
+	assert.Equal(t, "The tide turns.", narration.String())
+	assert.True(t, done)
+	require.Len(t, *requests, 1)
+	assert.True(t, (*requests)[0].Stream)
+	assert.Equal(t, 0.7, (*requests)[0].Options.Temperature)
+}
+
+func TestOllamaService_MaxTokens(t *testing.T) {
+	server, requests := newFakeOllama(t, `{"choices":["Wait","Run"]}`, nil)
+	service := NewOllamaService(server.URL, "llama3", "small", slog.Default())
+	ctx := WithMaxTokens(context.Background(), 120)
+
+	_, err := service.Chat(ctx, []chat.ChatMessage{{Role: chat.ChatRoleUser, Content: "wait"}}, 0.7)
+	require.NoError(t, err)
+	_, err = service.SuggestChoices(ctx, nil)
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 2)
+	assert.Equal(t, 120, (*requests)[0].Options.NumPredict, "narration is held to the limit")
+	assert.Zero(t, (*requests)[1].Options.NumPredict, "backend calls are not")
+}
+
+func TestOllamaService_StructuredOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		reply       string
+		call        func(s *OllamaService) (any, error)
+		wantModel   string
+		wantErr     bool
+		wantDecoded func(t *testing.T, got any)
+	}{
+		{
+			name:  "delta update uses the backend model and delta schema",
+			reply: `{"user_location":"deck"}`,
+			call: func(s *OllamaService) (any, error) {
+				delta, model, err := s.DeltaUpdate(context.Background(), nil)
+				assert.Equal(t, "small", model)
+				return delta, err
+			},
+			wantModel: "small",
+			wantDecoded: func(t *testing.T, got any) {
+				assert.Contains(t, mustJSON(t, got), `"user_location":"deck"`)
+			},
+		},
+		{
+			name:  "suggest choices",
+			reply: `{"choices":["Climb the mast","Check the hold"]}`,
+			call: func(s *OllamaService) (any, error) {
+				return s.SuggestChoices(context.Background(), nil)
+			},
+			wantModel: "small",
+			wantDecoded: func(t *testing.T, got any) {
+				assert.Equal(t, []string{"Climb the mast", "Check the hold"}, got)
+			},
+		},
+		{
+			name:  "unparseable reply is an error",
+			reply: `not json`,
+			call: func(s *OllamaService) (any, error) {
+				return s.SuggestChoices(context.Background(), nil)
+			},
+			wantModel: "small",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newFakeOllama(t, tt.reply, nil)
+			service := NewOllamaService(server.URL, "llama3", "small", slog.Default())
+
+			got, err := tt.call(service)
+			require.Len(t, *requests, 1)
+			req := (*requests)[0]
+			assert.Equal(t, tt.wantModel, req.Model)
+			assert.NotNil(t, req.Format, "structured calls send a schema")
+			assert.False(t, req.Stream)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.wantDecoded(t, got)
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
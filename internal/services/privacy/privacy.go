@@ -0,0 +1,287 @@
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/storage"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	pkgstorage "github.com/jwebster45206/story-engine/pkg/storage"
+	"github.com/redis/go-redis/v9"
+)
+
+// Data request job kinds
+const (
+	JobExport = "export" // Collect everything belonging to an owner
+	JobDelete = "delete" // Hard-delete everything belonging to an owner
+)
+
+// Data request job statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// JobTTL is how long jobs, and the exports they produce, are kept after they're created
+const JobTTL = 24 * time.Hour
+
+// Job is an export or deletion of all the data belonging to one owner, the API key
+// that created the games
+type Job struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"` // JobExport or JobDelete
+	Owner       string     `json:"owner"`
+	Status      string     `json:"status"`
+	Games       int        `json:"games"` // Games exported or deleted
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Done reports whether the job has finished, successfully or not
+func (j *Job) Done() bool {
+	return j.Status == StatusCompleted || j.Status == StatusFailed
+}
+
+// Export is everything stored for an owner: their games, including the full chat
+// transcripts, and each game's event log when event sourcing is on
+type Export struct {
+	Owner      string         `json:"owner"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Games      []ExportedGame `json:"games"`
+}
+
+// ExportedGame is one game in an export
+type ExportedGame struct {
+	GameState *state.GameState  `json:"gamestate"`
+	Events    []state.GameEvent `json:"events,omitempty"`
+}
+
+// Store runs data requests against game storage and tracks them in Redis, so any
+// API server can report on a job started by another
+type Store struct {
+	client    *redis.Client
+	keyPrefix string
+	encryptor *storage.Encryptor
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewStore creates a data request store
+func NewStore(client *redis.Client, logger *slog.Logger) *Store {
+	return &Store{client: client, logger: logger, now: time.Now}
+}
+
+// WithKeyPrefix returns a store sharing this connection whose keys are prefixed,
+// so each profile's jobs are kept apart
+func (st *Store) WithKeyPrefix(prefix string) *Store {
+	prefixed := *st
+	prefixed.keyPrefix = prefix
+	return &prefixed
+}
+
+// WithEncryption encrypts exports before they are written, as game states are
+func (st *Store) WithEncryption(encryptor *storage.Encryptor) *Store {
+	st.encryptor = encryptor
+	return st
+}
+
+// CreateJob records a pending job of kind for owner
+func (st *Store) CreateJob(ctx context.Context, kind, owner string) (*Job, error) {
+	job := &Job{
+		ID:        uuid.New().String(),
+		Kind:      kind,
+		Owner:     owner,
+		Status:    StatusPending,
+		CreatedAt: st.now(),
+	}
+	pipe := st.client.TxPipeline()
+	pipe.SAdd(ctx, st.ownerKey(owner), job.ID)
+	pipe.Expire(ctx, st.ownerKey(owner), JobTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to index data job in redis: %w", err)
+	}
+	if err := st.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJob returns a job, or nil if there is no such job or it has expired
+func (st *Store) GetJob(ctx context.Context, id string) (*Job, error) {
+	data, err := st.client.Get(ctx, st.jobKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data job from redis: %w", err)
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetExport returns the export produced by a completed export job, or nil if there
+// is none
+func (st *Store) GetExport(ctx context.Context, id string) ([]byte, error) {
+	payload, err := st.client.Get(ctx, st.exportKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load export from redis: %w", err)
+	}
+	if st.encryptor == nil {
+		return []byte(payload), nil
+	}
+	return st.encryptor.Open(payload, []byte("export:"+id))
+}
+
+// Run carries out a job against games, recording its progress as it goes. Failures
+// are recorded on the job rather than returned.
+func (st *Store) Run(ctx context.Context, games pkgstorage.GameStateStore, job *Job) {
+	job.Status = StatusRunning
+	if err := st.saveJob(ctx, job); err != nil {
+		st.logger.Error("Failed to update data job", "job", job.ID, "error", err)
+	}
+
+	var err error
+	switch job.Kind {
+	case JobExport:
+		err = st.runExport(ctx, games, job)
+	case JobDelete:
+		err = st.runDelete(ctx, games, job)
+	default:
+		err = fmt.Errorf("unknown job kind %q", job.Kind)
+	}
+
+	job.Status = StatusCompleted
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		st.logger.Error("Data job failed", "job", job.ID, "kind", job.Kind, "owner", job.Owner, "error", err)
+	} else {
+		st.logger.Info("Data job completed", "job", job.ID, "kind", job.Kind, "owner", job.Owner, "games", job.Games)
+	}
+	completed := st.now()
+	job.CompletedAt = &completed
+	if err := st.saveJob(ctx, job); err != nil {
+		st.logger.Error("Failed to update data job", "job", job.ID, "error", err)
+	}
+}
+
+// runExport collects the owner's games and event logs and saves them as the job's export
+func (st *Store) runExport(ctx context.Context, games pkgstorage.GameStateStore, job *Job) error {
+	owned, err := ownedGames(ctx, games, job.Owner)
+	if err != nil {
+		return err
+	}
+	export := Export{Owner: job.Owner, ExportedAt: st.now(), Games: make([]ExportedGame, 0, len(owned))}
+	eventLog, hasEvents := games.(pkgstorage.EventLog)
+	for _, gs := range owned {
+		game := ExportedGame{GameState: gs}
+		if hasEvents {
+			if game.Events, err = eventLog.ListGameEvents(ctx, gs.ID); err != nil {
+				return fmt.Errorf("failed to load events of game %s: %w", gs.ID, err)
+			}
+		}
+		export.Games = append(export.Games, game)
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return fmt.Errorf("failed to marshal export: %w", err)
+	}
+	payload := string(data)
+	if st.encryptor != nil {
+		if payload, err = st.encryptor.Seal(data, []byte("export:"+job.ID)); err != nil {
+			return fmt.Errorf("failed to encrypt export: %w", err)
+		}
+	}
+	if err := st.client.Set(ctx, st.exportKey(job.ID), payload, JobTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save export to redis: %w", err)
+	}
+	job.Games = len(export.Games)
+	return nil
+}
+
+// runDelete deletes the owner's games, which removes their transcripts and event logs,
+// and any exports made for the owner
+func (st *Store) runDelete(ctx context.Context, games pkgstorage.GameStateStore, job *Job) error {
+	owned, err := ownedGames(ctx, games, job.Owner)
+	if err != nil {
+		return err
+	}
+	for _, gs := range owned {
+		if err := games.DeleteGameState(ctx, gs.ID); err != nil {
+			return fmt.Errorf("failed to delete game %s: %w", gs.ID, err)
+		}
+		job.Games++
+	}
+
+	jobIDs, err := st.client.SMembers(ctx, st.ownerKey(job.Owner)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list data jobs from redis: %w", err)
+	}
+	for _, id := range jobIDs {
+		if err := st.client.Del(ctx, st.exportKey(id)).Err(); err != nil {
+			return fmt.Errorf("failed to delete export from redis: %w", err)
+		}
+	}
+	return nil
+}
+
+// ownedGames loads every game in games created by owner
+func ownedGames(ctx context.Context, games pkgstorage.GameStateStore, owner string) ([]*state.GameState, error) {
+	ids, err := games.ListGameStateIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list games: %w", err)
+	}
+	var owned []*state.GameState
+	for _, id := range ids {
+		gs, err := games.LoadGameState(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load game %s: %w", id, err)
+		}
+		if gs != nil && gs.APIKeyID == owner {
+			owned = append(owned, gs)
+		}
+	}
+	return owned, nil
+}
+
+func (st *Store) saveJob(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data job: %w", err)
+	}
+	ttl := JobTTL - st.now().Sub(job.CreatedAt)
+	if ttl < time.Minute {
+		ttl = time.Minute
+	}
+	if err := st.client.Set(ctx, st.jobKey(job.ID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save data job to redis: %w", err)
+	}
+	return nil
+}
+
+func (st *Store) jobKey(id string) string {
+	return st.keyPrefix + "datajob:" + id
+}
+
+func (st *Store) exportKey(id string) string {
+	return st.jobKey(id) + ":export"
+}
+
+func (st *Store) ownerKey(owner string) string {
+	return st.keyPrefix + "datajobs:owner:" + owner
+}
@@ -0,0 +1,154 @@
+package privacy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jwebster45206/story-engine/internal/storage"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) (*Store, *storage.RedisStorage, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	games := storage.NewRedisStorage(mr.Addr(), "", logger).WithEventSourcing(true)
+	return NewStore(client, logger), games, mr
+}
+
+func saveGame(t *testing.T, games *storage.RedisStorage, owner, message string) *state.GameState {
+	t.Helper()
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.APIKeyID = owner
+	gs.ChatHistory = []chat.ChatMessage{{Role: chat.ChatRoleUser, Content: message}}
+	ctx := state.WithGameEvent(context.Background(), state.GameEvent{Kind: state.EventCreated})
+	require.NoError(t, games.SaveGameState(ctx, gs.ID, gs))
+	return gs
+}
+
+func TestStore_Export(t *testing.T) {
+	st, games, _ := newTestStore(t)
+	ctx := context.Background()
+	mine := saveGame(t, games, "alice", "I hide the letter")
+	saveGame(t, games, "bob", "I open the door")
+
+	job, err := st.CreateJob(ctx, JobExport, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, job.Status)
+
+	st.Run(ctx, games, job)
+
+	loaded, err := st.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, StatusCompleted, loaded.Status)
+	assert.Equal(t, 1, loaded.Games)
+	assert.NotNil(t, loaded.CompletedAt)
+
+	data, err := st.GetExport(ctx, job.ID)
+	require.NoError(t, err)
+	var export Export
+	require.NoError(t, json.Unmarshal(data, &export))
+	assert.Equal(t, "alice", export.Owner)
+	require.Len(t, export.Games, 1)
+	assert.Equal(t, mine.ID, export.Games[0].GameState.ID)
+	assert.Equal(t, "I hide the letter", export.Games[0].GameState.ChatHistory[0].Content)
+	require.Len(t, export.Games[0].Events, 1)
+	assert.Equal(t, state.EventCreated, export.Games[0].Events[0].Kind)
+}
+
+func TestStore_Delete(t *testing.T) {
+	st, games, mr := newTestStore(t)
+	ctx := context.Background()
+	mine := saveGame(t, games, "alice", "I hide the letter")
+	theirs := saveGame(t, games, "bob", "I open the door")
+
+	export, err := st.CreateJob(ctx, JobExport, "alice")
+	require.NoError(t, err)
+	st.Run(ctx, games, export)
+
+	job, err := st.CreateJob(ctx, JobDelete, "alice")
+	require.NoError(t, err)
+	st.Run(ctx, games, job)
+
+	loaded, err := st.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, loaded.Status)
+	assert.Equal(t, 1, loaded.Games)
+
+	gs, err := games.LoadGameState(ctx, mine.ID)
+	require.NoError(t, err)
+	assert.Nil(t, gs, "owner's game should be deleted")
+	events, err := games.ListGameEvents(ctx, mine.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events, "owner's event log should be deleted")
+	gs, err = games.LoadGameState(ctx, theirs.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, gs, "other owners' games should be kept")
+
+	data, err := st.GetExport(ctx, export.ID)
+	require.NoError(t, err)
+	assert.Nil(t, data, "earlier exports should be deleted")
+	for _, key := range mr.Keys() {
+		val, _ := mr.Get(key)
+		assert.NotContains(t, val, "I hide the letter", "key %s still holds the owner's transcript", key)
+	}
+}
+
+func TestStore_EncryptedExport(t *testing.T) {
+	st, games, mr := newTestStore(t)
+	ctx := context.Background()
+	enc, err := storage.NewEncryptor(map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}, "k1")
+	require.NoError(t, err)
+	st = st.WithEncryption(enc).WithKeyPrefix("tenant:")
+	saveGame(t, games, "alice", "I hide the letter")
+
+	job, err := st.CreateJob(ctx, JobExport, "alice")
+	require.NoError(t, err)
+	st.Run(ctx, games, job)
+
+	raw, err := mr.Get("tenant:datajob:" + job.ID + ":export")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, "enc:v2:k1:"))
+
+	data, err := st.GetExport(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "I hide the letter")
+}
+
+func TestStore_Jobs(t *testing.T) {
+	st, games, mr := newTestStore(t)
+	ctx := context.Background()
+
+	job, err := st.GetJob(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	job, err = st.CreateJob(ctx, "archive", "alice")
+	require.NoError(t, err)
+	st.Run(ctx, games, job)
+	loaded, err := st.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, loaded.Status)
+	assert.Contains(t, loaded.Error, "unknown job kind")
+	assert.True(t, loaded.Done())
+
+	mr.FastForward(JobTTL + time.Minute)
+	loaded, err = st.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Nil(t, loaded, "jobs should expire")
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/logger"
+)
+
+// NewLLMService creates the LLM service for a provider and model, taking API keys and the
+// Ollama address from cfg. With cfg.CaptureDir set, every call is also captured there;
+// see CaptureService.
+func NewLLMService(cfg *config.Config, provider, modelName, backendModelName string, log *slog.Logger) (LLMService, error) {
+	log = logger.Module(log, logger.ModuleLLM)
+	var llm LLMService
+	switch strings.ToLower(provider) {
+	case "anthropic":
+		if cfg.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("anthropic API key is required when using anthropic provider")
+		}
+		llm = NewAnthropicService(cfg.AnthropicAPIKey, modelName, backendModelName, log)
+	case "venice":
+		if cfg.VeniceAPIKey == "" {
+			return nil, fmt.Errorf("venice API key is required when using venice provider")
+		}
+		llm = NewVeniceService(cfg.VeniceAPIKey, modelName, backendModelName, log)
+	case "ollama":
+		llm = NewOllamaService(cfg.OllamaURL, modelName, backendModelName, log)
+	default:
+		return nil, fmt.Errorf("invalid LLM provider %q (supported: anthropic, venice, ollama)", provider)
+	}
+
+	if cfg.CaptureDir != "" {
+		llm = NewCaptureService(llm, cfg.CaptureDir, log)
+	}
+	return llm, nil
+}
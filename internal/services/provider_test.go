@@ -0,0 +1,45 @@
+package services
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+)
+
+func TestNewLLMService(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		provider    string
+		expectErr   bool
+		wantCapture bool
+	}{
+		{name: "anthropic", cfg: config.Config{AnthropicAPIKey: "key"}, provider: "anthropic"},
+		{name: "provider case ignored", cfg: config.Config{VeniceAPIKey: "key"}, provider: "Venice"},
+		{name: "ollama needs no key", provider: "ollama"},
+		{name: "missing key", provider: "anthropic", expectErr: true},
+		{name: "unknown provider", provider: "gpt", expectErr: true},
+		{name: "captured", cfg: config.Config{CaptureDir: t.TempDir()}, provider: "ollama", wantCapture: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm, err := NewLLMService(&tt.cfg, tt.provider, "model", "", log)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if _, captured := llm.(*CaptureService); captured != tt.wantCapture {
+				t.Errorf("Expected capture %v, got %T", tt.wantCapture, llm)
+			}
+		})
+	}
+}
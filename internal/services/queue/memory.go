@@ -0,0 +1,156 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+)
+
+// MemoryQueue is an in-memory ChatQueue for running without Redis: the same global
+// request queue and per-game story event lists, held in the process. It is safe for
+// concurrent use. Requests are kept as JSON, as in Redis, so a dequeued request
+// never shares memory with the one that was enqueued.
+type MemoryQueue struct {
+	mu       sync.Mutex
+	requests [][]byte
+	events   map[uuid.UUID][]string
+	ready    chan struct{} // closed and replaced whenever a request is enqueued
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		events: make(map[uuid.UUID][]string),
+		ready:  make(chan struct{}),
+	}
+}
+
+// Dequeue removes and returns all queued chat messages and story events for a game
+func (q *MemoryQueue) Dequeue(ctx context.Context, gameStateID uuid.UUID) ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.events[gameStateID]
+	delete(q.events, gameStateID)
+	return events, nil
+}
+
+// Peek returns all story events without removing them
+func (q *MemoryQueue) Peek(ctx context.Context, gameStateID uuid.UUID, limit int) ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.events[gameStateID]
+	if limit > 0 && limit < len(events) {
+		events = events[:limit]
+	}
+	return append([]string(nil), events...), nil
+}
+
+// Clear removes all story events for a game
+func (q *MemoryQueue) Clear(ctx context.Context, gameStateID uuid.UUID) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.events, gameStateID)
+	return nil
+}
+
+// Depth returns the number of story events queued for a game
+func (q *MemoryQueue) Depth(ctx context.Context, gameStateID uuid.UUID) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events[gameStateID]), nil
+}
+
+// GetFormattedEvents returns all queued story events formatted as a single prompt
+func (q *MemoryQueue) GetFormattedEvents(ctx context.Context, gameStateID uuid.UUID) (string, error) {
+	events, err := q.Peek(ctx, gameStateID, 0)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(events, "\n\n"), nil
+}
+
+// EnqueueRequest adds a unified request to the global requests queue
+func (q *MemoryQueue) EnqueueRequest(ctx context.Context, req *queue.Request) error {
+	data, err := req.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to serialize request: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.requests = append(q.requests, data)
+	close(q.ready)
+	q.ready = make(chan struct{})
+	return nil
+}
+
+// DequeueRequest removes and returns the next request from the global queue
+// Returns nil if queue is empty
+func (q *MemoryQueue) DequeueRequest(ctx context.Context) (*queue.Request, error) {
+	q.mu.Lock()
+	data, ok := q.pop()
+	q.mu.Unlock()
+	if !ok {
+		return nil, nil // Queue is empty
+	}
+	return parseRequest(data)
+}
+
+// BlockingDequeueRequest blocks until a request is available, then returns it.
+// It returns nil if the timeout passes or ctx ends first; 0 means wait forever.
+func (q *MemoryQueue) BlockingDequeueRequest(ctx context.Context, timeout time.Duration) (*queue.Request, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		q.mu.Lock()
+		data, ok := q.pop()
+		ready := q.ready
+		q.mu.Unlock()
+		if ok {
+			return parseRequest(data)
+		}
+
+		select {
+		case <-ready:
+		case <-expired:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, nil
+		}
+	}
+}
+
+// RequestQueueDepth returns the number of requests in the global queue
+func (q *MemoryQueue) RequestQueueDepth(ctx context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.requests), nil
+}
+
+// pop removes the oldest request. The caller holds mu.
+func (q *MemoryQueue) pop() ([]byte, bool) {
+	if len(q.requests) == 0 {
+		return nil, false
+	}
+	data := q.requests[0]
+	q.requests[0] = nil
+	q.requests = q.requests[1:]
+	return data, true
+}
+
+func parseRequest(data []byte) (*queue.Request, error) {
+	req, err := queue.FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request: %w", err)
+	}
+	return req, nil
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	queuePkg "github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+var _ state.ChatQueue = (*MemoryQueue)(nil)
+
+func TestMemoryQueue_EnqueueAndDequeueRequest(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+	gameStateID := uuid.New()
+
+	first := &queuePkg.Request{RequestID: "first", Type: queuePkg.RequestTypeChat, GameStateID: gameStateID, Message: "hello"}
+	second := &queuePkg.Request{RequestID: "second", Type: queuePkg.RequestTypeStoryEvent, GameStateID: gameStateID, EventPrompt: "A storm"}
+	for _, req := range []*queuePkg.Request{first, second} {
+		if err := q.EnqueueRequest(ctx, req); err != nil {
+			t.Fatalf("Failed to enqueue request: %v", err)
+		}
+	}
+
+	// Changing an enqueued request must not change the queued copy
+	first.Message = "changed"
+
+	if depth, _ := q.RequestQueueDepth(ctx); depth != 2 {
+		t.Errorf("Expected depth 2, got %d", depth)
+	}
+
+	got, err := q.DequeueRequest(ctx)
+	if err != nil {
+		t.Fatalf("Failed to dequeue request: %v", err)
+	}
+	if got.RequestID != "first" || got.Message != "hello" {
+		t.Errorf("Expected the first request as enqueued, got %+v", got)
+	}
+	got, _ = q.DequeueRequest(ctx)
+	if got.RequestID != "second" {
+		t.Errorf("Expected the second request, got %+v", got)
+	}
+	got, err = q.DequeueRequest(ctx)
+	if err != nil || got != nil {
+		t.Errorf("Expected nil from an empty queue, got %+v, %v", got, err)
+	}
+}
+
+func TestMemoryQueue_BlockingDequeueRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		enqueueIn  time.Duration // -1 to never enqueue
+		timeout    time.Duration
+		cancelIn   time.Duration // 0 to never cancel
+		wantResult bool
+	}{
+		{name: "waits for a request", enqueueIn: 20 * time.Millisecond, timeout: time.Second, wantResult: true},
+		{name: "returns nil on timeout", enqueueIn: -1, timeout: 20 * time.Millisecond},
+		{name: "returns nil when cancelled", enqueueIn: -1, cancelIn: 20 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewMemoryQueue()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelIn > 0 {
+				time.AfterFunc(tt.cancelIn, cancel)
+			}
+			if tt.enqueueIn >= 0 {
+				time.AfterFunc(tt.enqueueIn, func() {
+					_ = q.EnqueueRequest(context.Background(), &queuePkg.Request{RequestID: "late", Type: queuePkg.RequestTypeChat})
+				})
+			}
+
+			got, err := q.BlockingDequeueRequest(ctx, tt.timeout)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (got != nil) != tt.wantResult {
+				t.Errorf("Expected result=%v, got %+v", tt.wantResult, got)
+			}
+		})
+	}
+}
+
+func TestMemoryQueue_StoryEvents(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+	gameStateID := uuid.New()
+	q.events[gameStateID] = []string{"A dragon appears", "The ground shakes"}
+
+	formatted, err := q.GetFormattedEvents(ctx, gameStateID)
+	if err != nil {
+		t.Fatalf("Failed to format events: %v", err)
+	}
+	if formatted != "A dragon appears\n\nThe ground shakes" {
+		t.Errorf("Unexpected formatted events: %q", formatted)
+	}
+	if peeked, _ := q.Peek(ctx, gameStateID, 1); len(peeked) != 1 {
+		t.Errorf("Expected 1 peeked event, got %v", peeked)
+	}
+	if depth, _ := q.Depth(ctx, gameStateID); depth != 2 {
+		t.Errorf("Expected depth 2, got %d", depth)
+	}
+
+	events, _ := q.Dequeue(ctx, gameStateID)
+	if len(events) != 2 {
+		t.Errorf("Expected 2 dequeued events, got %v", events)
+	}
+	if depth, _ := q.Depth(ctx, gameStateID); depth != 0 {
+		t.Errorf("Expected an empty queue after dequeue, got %d", depth)
+	}
+}
@@ -0,0 +1,239 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// gameUsageTTL keeps a game's totals for a day after its last LLM call,
+	// well past the game state's own expiry
+	gameUsageTTL = 24 * time.Hour
+
+	// keyUsageTTL keeps an API key's monthly totals into the following month
+	keyUsageTTL = 62 * 24 * time.Hour
+)
+
+// Level is how close usage is to its cap
+type Level string
+
+const (
+	LevelOK       Level = "ok"
+	LevelWarning  Level = "warning"  // soft cap reached
+	LevelExceeded Level = "exceeded" // hard cap reached
+)
+
+// Subjects that budgets apply to
+const (
+	SubjectGame   = "game"
+	SubjectAPIKey = "api_key"
+)
+
+// Totals is the accumulated token usage and estimated spend of a game or API key
+type Totals struct {
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// Tokens returns the combined input and output token count
+func (t Totals) Tokens() int64 {
+	return t.InputTokens + t.OutputTokens
+}
+
+// Evaluate returns the budget level of the totals against a cap
+func Evaluate(t Totals, c config.BudgetCap) Level {
+	if (c.HardTokens > 0 && t.Tokens() >= c.HardTokens) || (c.HardUSD > 0 && t.CostUSD >= c.HardUSD) {
+		return LevelExceeded
+	}
+	if soft := c.SoftTokenCap(); soft > 0 && t.Tokens() >= soft {
+		return LevelWarning
+	}
+	if soft := c.SoftUSDCap(); soft > 0 && t.CostUSD >= soft {
+		return LevelWarning
+	}
+	return LevelOK
+}
+
+// Status is the budget state of one game or API key
+type Status struct {
+	Subject string           `json:"subject"` // SubjectGame or SubjectAPIKey
+	ID      string           `json:"id"`
+	Level   Level            `json:"level"`
+	Usage   Totals           `json:"usage"`
+	Cap     config.BudgetCap `json:"cap"`
+}
+
+// Message summarizes usage against the cap, e.g. "game usage 41200 of 50000 tokens, $1.20 of $5.00"
+func (s Status) Message() string {
+	var parts []string
+	if limit := s.Cap.HardTokens; limit > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d tokens", s.Usage.Tokens(), limit))
+	} else if limit := s.Cap.SoftTokenCap(); limit > 0 {
+		parts = append(parts, fmt.Sprintf("%d tokens (soft cap %d)", s.Usage.Tokens(), limit))
+	}
+	if limit := s.Cap.HardUSD; limit > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f of $%.2f", s.Usage.CostUSD, limit))
+	} else if limit := s.Cap.SoftUSDCap(); limit > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f (soft cap $%.2f)", s.Usage.CostUSD, limit))
+	}
+	return fmt.Sprintf("%s usage %s", strings.ReplaceAll(s.Subject, "_", " "), strings.Join(parts, ", "))
+}
+
+// Ledger accumulates token usage per game and per API key in Redis and checks
+// the totals against the configured budgets
+type Ledger struct {
+	client  *redis.Client
+	models  *config.ModelRegistry
+	budgets config.Budgets
+	webhook *webhook
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewLedger creates a ledger. Spend is estimated from the model registry's pricing,
+// and alerts are posted to budgets.AlertWebhook when set.
+func NewLedger(client *redis.Client, models *config.ModelRegistry, budgets config.Budgets, logger *slog.Logger) *Ledger {
+	l := &Ledger{
+		client:  client,
+		models:  models,
+		budgets: budgets,
+		logger:  logger,
+		now:     time.Now,
+	}
+	if budgets.AlertWebhook != "" {
+		l.webhook = newWebhook(budgets.AlertWebhook, logger)
+	}
+	return l
+}
+
+// Recorder returns a services.UsageRecorder that charges LLM calls to a game
+// and API key. An empty keyID charges the game only.
+func (l *Ledger) Recorder(gameID uuid.UUID, keyID string) services.UsageRecorder {
+	return func(ctx context.Context, u services.TokenUsage) {
+		if err := l.Record(context.WithoutCancel(ctx), gameID, keyID, u); err != nil {
+			l.logger.Error("Failed to record token usage", "error", err, "game_state_id", gameID.String(), "model", u.Model)
+		}
+	}
+}
+
+// Record adds one LLM call's usage to the game and API key totals,
+// alerting operators the first time a total reaches a cap
+func (l *Ledger) Record(ctx context.Context, gameID uuid.UUID, keyID string, u services.TokenUsage) error {
+	cost := l.models.Lookup(u.Model).Cost(int64(u.InputTokens), int64(u.OutputTokens))
+
+	key := gameKey(gameID)
+	totals, err := l.add(ctx, key, gameUsageTTL, u, cost)
+	if err != nil {
+		return err
+	}
+	l.alert(ctx, key, gameUsageTTL, newStatus(SubjectGame, gameID.String(), totals, l.budgets.Game))
+
+	if keyID == "" {
+		return nil
+	}
+	key = l.apiKeyKey(keyID)
+	totals, err = l.add(ctx, key, keyUsageTTL, u, cost)
+	if err != nil {
+		return err
+	}
+	l.alert(ctx, key, keyUsageTTL, newStatus(SubjectAPIKey, keyID, totals, l.budgets.APIKey))
+	return nil
+}
+
+// Check returns the budget status of the game and of the API key's current month.
+// Subjects without a configured cap, a nil gameID, and an empty keyID are skipped.
+func (l *Ledger) Check(ctx context.Context, gameID uuid.UUID, keyID string) ([]Status, error) {
+	var statuses []Status
+	if gameID != uuid.Nil && l.budgets.Game.Enabled() {
+		totals, err := l.totals(ctx, gameKey(gameID))
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, newStatus(SubjectGame, gameID.String(), totals, l.budgets.Game))
+	}
+	if keyID != "" && l.budgets.APIKey.Enabled() {
+		totals, err := l.totals(ctx, l.apiKeyKey(keyID))
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, newStatus(SubjectAPIKey, keyID, totals, l.budgets.APIKey))
+	}
+	return statuses, nil
+}
+
+// GameTotals returns the usage recorded for a game
+func (l *Ledger) GameTotals(ctx context.Context, gameID uuid.UUID) (Totals, error) {
+	return l.totals(ctx, gameKey(gameID))
+}
+
+// APIKeyTotals returns the usage recorded for an API key in the current month
+func (l *Ledger) APIKeyTotals(ctx context.Context, keyID string) (Totals, error) {
+	return l.totals(ctx, l.apiKeyKey(keyID))
+}
+
+func newStatus(subject, id string, totals Totals, c config.BudgetCap) Status {
+	return Status{Subject: subject, ID: id, Level: Evaluate(totals, c), Usage: totals, Cap: c}
+}
+
+// add increments the totals stored at key and returns the new totals
+func (l *Ledger) add(ctx context.Context, key string, ttl time.Duration, u services.TokenUsage, cost float64) (Totals, error) {
+	pipe := l.client.TxPipeline()
+	in := pipe.HIncrBy(ctx, key, "input_tokens", int64(u.InputTokens))
+	out := pipe.HIncrBy(ctx, key, "output_tokens", int64(u.OutputTokens))
+	usd := pipe.HIncrByFloat(ctx, key, "cost_usd", cost)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Totals{}, fmt.Errorf("failed to record usage in redis: %w", err)
+	}
+	return Totals{InputTokens: in.Val(), OutputTokens: out.Val(), CostUSD: usd.Val()}, nil
+}
+
+func (l *Ledger) totals(ctx context.Context, key string) (Totals, error) {
+	fields, err := l.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return Totals{}, fmt.Errorf("failed to load usage from redis: %w", err)
+	}
+	var t Totals
+	t.InputTokens, _ = strconv.ParseInt(fields["input_tokens"], 10, 64)
+	t.OutputTokens, _ = strconv.ParseInt(fields["output_tokens"], 10, 64)
+	t.CostUSD, _ = strconv.ParseFloat(fields["cost_usd"], 64)
+	return t, nil
+}
+
+// alert logs and posts the status the first time its subject reaches each level
+func (l *Ledger) alert(ctx context.Context, key string, ttl time.Duration, s Status) {
+	if s.Level == LevelOK {
+		return
+	}
+	first, err := l.client.SetNX(ctx, key+":alerted:"+string(s.Level), 1, ttl).Result()
+	if err != nil {
+		l.logger.Error("Failed to record budget alert", "error", err, "subject", s.Subject, "id", s.ID)
+		return
+	}
+	if !first {
+		return
+	}
+	l.logger.Warn("Budget cap reached", "subject", s.Subject, "id", s.ID, "level", s.Level, "usage", s.Message())
+	if l.webhook != nil {
+		go l.webhook.send(Alert{Status: s, Message: s.Message(), Timestamp: l.now().UTC()})
+	}
+}
+
+func gameKey(gameID uuid.UUID) string {
+	return "usage:game:" + gameID.String()
+}
+
+// apiKeyKey returns the key for an API key's totals in the current UTC month
+func (l *Ledger) apiKeyKey(keyID string) string {
+	return "usage:apikey:" + keyID + ":" + l.now().UTC().Format("2006-01")
+}
@@ -0,0 +1,168 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLedger(t *testing.T, budgets config.Budgets) *Ledger {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	models := config.NewModelRegistry([]config.ModelCapabilities{
+		{Name: "priced-model", InputUSDPerM: 2, OutputUSDPerM: 10, Streaming: true},
+	})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	l := NewLedger(client, models, budgets, logger)
+	l.now = func() time.Time { return time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC) }
+	return l
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		totals   Totals
+		cap      config.BudgetCap
+		expected Level
+	}{
+		{"no caps", Totals{InputTokens: 1_000_000}, config.BudgetCap{}, LevelOK},
+		{"under soft cap", Totals{InputTokens: 100}, config.BudgetCap{HardTokens: 1000}, LevelOK},
+		{"default soft cap", Totals{InputTokens: 700, OutputTokens: 100}, config.BudgetCap{HardTokens: 1000}, LevelWarning},
+		{"explicit soft cap", Totals{InputTokens: 500}, config.BudgetCap{SoftTokens: 500, HardTokens: 1000}, LevelWarning},
+		{"hard token cap", Totals{InputTokens: 900, OutputTokens: 100}, config.BudgetCap{HardTokens: 1000}, LevelExceeded},
+		{"soft USD cap", Totals{CostUSD: 4.5}, config.BudgetCap{HardUSD: 5}, LevelWarning},
+		{"hard USD cap", Totals{CostUSD: 5}, config.BudgetCap{HardUSD: 5, HardTokens: 1000}, LevelExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Evaluate(tt.totals, tt.cap))
+		})
+	}
+}
+
+func TestStatus_Message(t *testing.T) {
+	s := Status{
+		Subject: SubjectAPIKey,
+		Usage:   Totals{InputTokens: 40000, OutputTokens: 1200, CostUSD: 1.2},
+		Cap:     config.BudgetCap{HardTokens: 50000, SoftUSD: 1},
+	}
+	assert.Equal(t, "api key usage 41200 of 50000 tokens, $1.20 (soft cap $1.00)", s.Message())
+}
+
+func TestLedger_RecordAndCheck(t *testing.T) {
+	l := newTestLedger(t, config.Budgets{
+		Game:   config.BudgetCap{HardTokens: 1000},
+		APIKey: config.BudgetCap{HardUSD: 1},
+	})
+	ctx := context.Background()
+	gameID := uuid.New()
+
+	rec := l.Recorder(gameID, "key1")
+	rec(ctx, services.TokenUsage{Model: "priced-model", InputTokens: 500, OutputTokens: 100})
+	rec(ctx, services.TokenUsage{Model: "unpriced-model", InputTokens: 200, OutputTokens: 50})
+
+	game, err := l.GameTotals(ctx, gameID)
+	require.NoError(t, err)
+	assert.Equal(t, Totals{InputTokens: 700, OutputTokens: 150, CostUSD: 0.002}, game)
+
+	key, err := l.APIKeyTotals(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, game, key)
+
+	statuses, err := l.Check(ctx, gameID, "key1")
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, SubjectGame, statuses[0].Subject)
+	assert.Equal(t, LevelWarning, statuses[0].Level)
+	assert.Equal(t, SubjectAPIKey, statuses[1].Subject)
+	assert.Equal(t, LevelOK, statuses[1].Level)
+
+	// A game-only recorder doesn't charge any API key
+	require.NoError(t, l.Record(ctx, gameID, "", services.TokenUsage{Model: "priced-model", InputTokens: 200}))
+	statuses, err = l.Check(ctx, gameID, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, LevelExceeded, statuses[0].Level)
+	assert.Equal(t, int64(700), statuses[1].Usage.InputTokens)
+
+	// API key totals are kept per calendar month
+	l.now = func() time.Time { return time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC) }
+	key, err = l.APIKeyTotals(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, Totals{}, key)
+}
+
+func TestLedger_CheckSkipsUncappedSubjects(t *testing.T) {
+	l := newTestLedger(t, config.Budgets{APIKey: config.BudgetCap{HardTokens: 100}})
+
+	statuses, err := l.Check(context.Background(), uuid.New(), "")
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+}
+
+func TestLedger_AlertWebhook(t *testing.T) {
+	alerts := make(chan Alert, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err == nil {
+			alerts <- a
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	l := newTestLedger(t, config.Budgets{
+		Game:         config.BudgetCap{SoftTokens: 100, HardTokens: 200},
+		AlertWebhook: server.URL,
+	})
+	ctx := context.Background()
+	gameID := uuid.New()
+	usage := services.TokenUsage{Model: "unpriced-model", InputTokens: 60}
+
+	receive := func() Alert {
+		t.Helper()
+		select {
+		case a := <-alerts:
+			return a
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for budget alert")
+			return Alert{}
+		}
+	}
+
+	require.NoError(t, l.Record(ctx, gameID, "", usage)) // 60: no alert
+	require.NoError(t, l.Record(ctx, gameID, "", usage)) // 120: warning
+	a := receive()
+	assert.Equal(t, LevelWarning, a.Level)
+	assert.Equal(t, SubjectGame, a.Subject)
+	assert.Equal(t, gameID.String(), a.ID)
+	assert.Equal(t, int64(120), a.Usage.InputTokens)
+
+	require.NoError(t, l.Record(ctx, gameID, "", usage)) // 180: still warning, already alerted
+	require.NoError(t, l.Record(ctx, gameID, "", usage)) // 240: exceeded
+	a = receive()
+	assert.Equal(t, LevelExceeded, a.Level)
+	assert.Equal(t, "game usage 240 of 200 tokens", a.Message)
+
+	select {
+	case extra := <-alerts:
+		t.Errorf("Unexpected extra alert: %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
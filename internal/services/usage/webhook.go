@@ -0,0 +1,51 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Alert is the JSON body posted to the budget alert webhook
+type Alert struct {
+	Status
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// webhook posts budget alerts to an operator-configured URL
+type webhook struct {
+	url        string
+	httpClient *http.Client
+	logger     *slog.Logger
+}
+
+func newWebhook(url string, logger *slog.Logger) *webhook {
+	return &webhook{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// send posts the alert, logging rather than returning failures
+func (w *webhook) send(alert Alert) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		w.logger.Error("Failed to marshal budget alert", "error", err)
+		return
+	}
+	resp, err := w.httpClient.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		w.logger.Error("Failed to send budget alert", "error", err, "subject", alert.Subject, "id", alert.ID)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		w.logger.Error("Budget alert webhook returned error", "status_code", resp.StatusCode, "subject", alert.Subject, "id", alert.ID)
+	}
+}
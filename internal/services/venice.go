@@ -7,12 +7,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/state"
 )
 
 const (
@@ -25,7 +28,14 @@ type VeniceService struct {
 	apiKey           string
 	modelName        string
 	backendModelName string
+	baseURL          string
 	httpClient       *http.Client
+	logger           *slog.Logger
+
+	// schemaSupport records, per model, whether response_format json_schema
+	// is honoured. Filled by InitModel and by requests the API rejects.
+	schemaMu      sync.RWMutex
+	schemaSupport map[string]bool
 }
 
 type VeniceResponseFormat struct {
@@ -52,9 +62,22 @@ type VeniceChatRequest struct {
 	MaxTokens        int                   `json:"max_tokens,omitempty"`
 	Stream           bool                  `json:"stream"`
 	ResponseFormat   *VeniceResponseFormat `json:"response_format,omitempty"`
+	StreamOptions    *VeniceStreamOptions  `json:"stream_options,omitempty"`
 	VeniceParameters VeniceParameters      `json:"venice_parameters"`
 }
 
+// VeniceStreamOptions asks for a final usage chunk on streamed responses
+type VeniceStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// VeniceUsage reports the tokens consumed by a completion
+type VeniceUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
 // VeniceChatChoice represents a single choice in the Venice AI response
 type VeniceChatChoice struct {
 	Index   int `json:"index"`
@@ -72,12 +95,8 @@ type VeniceChatResponse struct {
 	Created int64              `json:"created"`
 	Model   string             `json:"model"`
 	Choices []VeniceChatChoice `json:"choices"`
-	Usage   struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage,omitempty"`
-	Error *struct {
+	Usage   VeniceUsage        `json:"usage,omitempty"`
+	Error   *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
 		Code    string `json:"code"`
@@ -101,6 +120,7 @@ type VeniceStreamResponse struct {
 	Created int64                `json:"created"`
 	Model   string               `json:"model"`
 	Choices []VeniceStreamChoice `json:"choices"`
+	Usage   *VeniceUsage         `json:"usage,omitempty"`
 	Error   *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
@@ -109,25 +129,23 @@ type VeniceStreamResponse struct {
 }
 
 // NewVeniceService creates a new Venice AI service
-func NewVeniceService(apiKey string, modelName string, backendModelName string) *VeniceService {
+func NewVeniceService(apiKey string, modelName string, backendModelName string, logger *slog.Logger) *VeniceService {
 	return &VeniceService{
 		apiKey:           apiKey,
 		modelName:        modelName,
 		backendModelName: backendModelName,
+		baseURL:          veniceBaseURL,
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
+		logger:        logger,
+		schemaSupport: make(map[string]bool),
 	}
 }
 
-// InitModel initializes the model (Venice AI doesn't require explicit model initialization)
-func (v *VeniceService) InitModel(ctx context.Context, modelName string) error {
-	return nil
-}
-
 // chatCompletion makes a chat completion request to Venice AI with the specified model
 func (v *VeniceService) chatCompletion(ctx context.Context, messages []chat.ChatMessage, modelName string, temperature float64, responseFormat *VeniceResponseFormat) (string, error) {
-	maxTokens := DefaultMaxTokens
+	maxTokens := maxTokensFromContext(ctx, DefaultMaxTokens)
 	if temperature == 0.0 {
 		maxTokens = BackendMaxTokens
 	}
@@ -153,7 +171,7 @@ func (v *VeniceService) chatCompletion(ctx context.Context, messages []chat.Chat
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", veniceBaseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", v.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -173,7 +191,7 @@ func (v *VeniceService) chatCompletion(ctx context.Context, messages []chat.Chat
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", &veniceStatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var veniceResp VeniceChatResponse
@@ -185,6 +203,13 @@ func (v *VeniceService) chatCompletion(ctx context.Context, messages []chat.Chat
 		return "", fmt.Errorf("API error: %s", veniceResp.Error.Message)
 	}
 
+	recordUsage(ctx, modelName, veniceResp.Usage.PromptTokens, veniceResp.Usage.CompletionTokens)
+	v.logger.Debug("Venice completion",
+		"model", modelName,
+		"structured", responseFormat != nil,
+		"prompt_tokens", veniceResp.Usage.PromptTokens,
+		"completion_tokens", veniceResp.Usage.CompletionTokens)
+
 	if len(veniceResp.Choices) == 0 {
 		return msgNoResponse, nil
 	}
@@ -200,111 +225,14 @@ func (v *VeniceService) getDeltaUpdateResponseFormat() *VeniceResponseFormat {
 		JSONSchema: VeniceJSONSchema{
 			Name:   "apply_changes",
 			Strict: true,
-			Schema: map[string]any{
-				"type":                 "object",
-				"additionalProperties": false,
-				"properties": map[string]any{
-					"user_location": map[string]any{
-						"type": "string",
-					},
-					// REQUIRED + NULLABLE scene_change
-					"scene_change": map[string]any{
-						"anyOf": []any{
-							map[string]any{
-								"type":                 "object",
-								"additionalProperties": false,
-								"properties": map[string]any{
-									"to":     map[string]any{"type": "string"},
-									"reason": map[string]any{"type": "string"},
-								},
-								"required": []string{"to", "reason"},
-							},
-							map[string]any{"type": "null"},
-						},
-					},
-					"item_events": map[string]any{
-						"type": "array",
-						"items": map[string]any{
-							"type":                 "object",
-							"additionalProperties": false,
-							"properties": map[string]any{
-								"item": map[string]any{
-									"type": "string",
-								},
-								"action": map[string]any{
-									"type": "string",
-									"enum": []string{"acquire", "give", "drop", "move", "use"},
-								},
-								"from": map[string]any{
-									"type":                 "object",
-									"additionalProperties": false,
-									"properties": map[string]any{
-										"type": map[string]any{
-											"type": "string",
-											"enum": []string{"player", "npc", "location"},
-										},
-										"name": map[string]any{
-											"type": "string",
-										},
-									},
-									"required": []string{"type"},
-								},
-								"to": map[string]any{
-									"type":                 "object",
-									"additionalProperties": false,
-									"properties": map[string]any{
-										"type": map[string]any{
-											"type": "string",
-											"enum": []string{"player", "npc", "location"},
-										},
-										"name": map[string]any{
-											"type": "string",
-										},
-									},
-									"required": []string{"type"},
-								},
-								"consumed": map[string]any{
-									"type": "boolean",
-								},
-							},
-							"required": []string{"item", "action"},
-						},
-					},
-					"npc_events": map[string]any{
-						"type": "array",
-						"items": map[string]any{
-							"type":                 "object",
-							"additionalProperties": false,
-							"properties": map[string]any{
-								"npc_id": map[string]any{
-									"type": "string",
-								},
-								"set_location": map[string]any{
-									"type": "string",
-								},
-							},
-							"required": []string{"npc_id"},
-						},
-					},
-					"set_vars": map[string]any{
-						"type": "object",
-						"additionalProperties": map[string]any{
-							"type": "string",
-						},
-					},
-					"game_ended": map[string]any{
-						"type": "boolean",
-					},
-				},
-				"required": []string{"user_location", "scene_change", "item_events", "npc_events", "set_vars", "game_ended"},
-			},
+			Schema: conditionals.DeltaSchema(),
 		},
 	}
 }
 
 // Chat generates a chat response using Venice AI
 func (v *VeniceService) Chat(ctx context.Context, messages []chat.ChatMessage, temperature float64) (*chat.ChatResponse, error) {
-	content, err := v.chatCompletion(ctx, messages, v.modelName, temperature, nil)
+	content, err := v.chatCompletion(ctx, messages, modelFromContext(ctx, v.modelName), temperature, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -316,12 +244,14 @@ func (v *VeniceService) Chat(ctx context.Context, messages []chat.ChatMessage, t
 
 // ChatStream generates a streaming chat response using Venice AI
 func (v *VeniceService) ChatStream(ctx context.Context, messages []chat.ChatMessage, temperature float64) (<-chan StreamChunk, error) {
+	modelName := modelFromContext(ctx, v.modelName)
 	reqBody := VeniceChatRequest{
-		Model:       v.modelName,
-		Messages:    messages,
-		Temperature: temperature,
-		MaxTokens:   DefaultMaxTokens,
-		Stream:      true,
+		Model:         modelName,
+		Messages:      messages,
+		Temperature:   temperature,
+		MaxTokens:     maxTokensFromContext(ctx, DefaultMaxTokens),
+		Stream:        true,
+		StreamOptions: &VeniceStreamOptions{IncludeUsage: true},
 		VeniceParameters: VeniceParameters{
 			IncludeVeniceSystemPrompt: false,
 			EnableWebSearch:           "off",
@@ -333,7 +263,7 @@ func (v *VeniceService) ChatStream(ctx context.Context, messages []chat.ChatMess
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", veniceBaseURL+"/chat/completions", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", v.baseURL+"/chat/completions", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -348,6 +278,7 @@ func (v *VeniceService) ChatStream(ctx context.Context, messages []chat.ChatMess
 
 	if resp.StatusCode != http.StatusOK {
 		_ = resp.Body.Close()
+		v.logger.Warn("Venice stream request failed", "model", modelName, "status", resp.StatusCode)
 		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
 	}
 
@@ -357,6 +288,8 @@ func (v *VeniceService) ChatStream(ctx context.Context, messages []chat.ChatMess
 		defer func() { _ = resp.Body.Close() }()
 		defer close(chunkChan)
 
+		// The usage chunk follows the finish_reason chunk, so keep reading until [DONE]
+		finished := false
 		scanner := bufio.NewScanner(resp.Body)
 		for scanner.Scan() {
 			select {
@@ -381,7 +314,9 @@ func (v *VeniceService) ChatStream(ctx context.Context, messages []chat.ChatMess
 
 			// Check for end of stream
 			if jsonData == "[DONE]" {
-				chunkChan <- StreamChunk{Done: true}
+				if !finished {
+					chunkChan <- StreamChunk{Done: true}
+				}
 				return
 			}
 
@@ -397,8 +332,12 @@ func (v *VeniceService) ChatStream(ctx context.Context, messages []chat.ChatMess
 				return
 			}
 
+			if streamResp.Usage != nil {
+				recordUsage(ctx, modelName, streamResp.Usage.PromptTokens, streamResp.Usage.CompletionTokens)
+			}
+
 			// Extract content from the first choice
-			if len(streamResp.Choices) > 0 {
+			if len(streamResp.Choices) > 0 && !finished {
 				choice := streamResp.Choices[0]
 				chunkChan <- StreamChunk{
 					Content: choice.Delta.Content,
@@ -408,7 +347,7 @@ func (v *VeniceService) ChatStream(ctx context.Context, messages []chat.ChatMess
 				// Check if streaming is complete
 				if choice.FinishReason != nil {
 					chunkChan <- StreamChunk{Done: true}
-					return
+					finished = true
 				}
 			}
 		}
@@ -422,14 +361,13 @@ func (v *VeniceService) ChatStream(ctx context.Context, messages []chat.ChatMess
 }
 
 func (v *VeniceService) DeltaUpdate(ctx context.Context, messages []chat.ChatMessage) (*conditionals.GameStateDelta, string, error) {
-	modelToUse := v.modelName
+	modelToUse := modelFromContext(ctx, v.modelName)
 	if v.backendModelName != "" {
 		modelToUse = v.backendModelName
 	}
 
 	// Use structured JSON response format with temperature 0 for deterministic output
-	responseFormat := v.getDeltaUpdateResponseFormat()
-	content, err := v.chatCompletion(ctx, messages, modelToUse, 0.0, responseFormat)
+	content, err := v.structuredCompletion(ctx, messages, modelToUse, v.getDeltaUpdateResponseFormat())
 	if err != nil {
 		return nil, "", err
 	}
@@ -441,3 +379,66 @@ func (v *VeniceService) DeltaUpdate(ctx context.Context, messages []chat.ChatMes
 
 	return deltaUpdate, modelToUse, nil
 }
+
+// getChoicesResponseFormat returns the response format
+// for structured choice suggestions
+func (v *VeniceService) getChoicesResponseFormat() *VeniceResponseFormat {
+	return &VeniceResponseFormat{
+		Type: "json_schema",
+		JSONSchema: VeniceJSONSchema{
+			Name:   "suggest_choices",
+			Strict: true,
+			Schema: map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]any{
+					"choices": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type": "string",
+						},
+					},
+				},
+				"required": []string{"choices"},
+			},
+		},
+	}
+}
+
+// SuggestChoices asks the backend model for suggested next actions using Venice AI
+func (v *VeniceService) SuggestChoices(ctx context.Context, messages []chat.ChatMessage) ([]string, error) {
+	modelToUse := modelFromContext(ctx, v.modelName)
+	if v.backendModelName != "" {
+		modelToUse = v.backendModelName
+	}
+
+	content, err := v.structuredCompletion(ctx, messages, modelToUse, v.getChoicesResponseFormat())
+	if err != nil {
+		return nil, err
+	}
+
+	return parseChoicesResponse(content)
+}
+
+// CheckNarration asks the backend model where a narration contradicts the game state using Venice AI
+func (v *VeniceService) CheckNarration(ctx context.Context, messages []chat.ChatMessage) ([]state.NarrationIssue, error) {
+	modelToUse := modelFromContext(ctx, v.modelName)
+	if v.backendModelName != "" {
+		modelToUse = v.backendModelName
+	}
+
+	format := &VeniceResponseFormat{
+		Type: "json_schema",
+		JSONSchema: VeniceJSONSchema{
+			Name:   "report_inconsistencies",
+			Strict: true,
+			Schema: narrationIssuesSchema(),
+		},
+	}
+	content, err := v.structuredCompletion(ctx, messages, modelToUse, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNarrationIssues(content)
+}
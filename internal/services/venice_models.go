@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+)
+
+// jsonFallbackPrompt asks for JSON in plain text, for models that
+// don't support a json_schema response format
+const jsonFallbackPrompt = "Respond with only a single JSON object that matches this JSON schema. Do not add any other text, markdown, or code fences.\n\nSchema:\n%s"
+
+// VeniceModelCapabilities is the part of a model's spec from GET /models that we act on
+type VeniceModelCapabilities struct {
+	SupportsResponseSchema  bool `json:"supportsResponseSchema"`
+	SupportsFunctionCalling bool `json:"supportsFunctionCalling"`
+}
+
+// VeniceModel is one entry in the Venice models list
+type VeniceModel struct {
+	ID        string `json:"id"`
+	ModelSpec struct {
+		Capabilities VeniceModelCapabilities `json:"capabilities"`
+	} `json:"model_spec"`
+}
+
+// VeniceModelsResponse is the response from GET /models
+type VeniceModelsResponse struct {
+	Data []VeniceModel `json:"data"`
+}
+
+// veniceStatusError is a non-200 response from the Venice API
+type veniceStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *veniceStatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// InitModel probes the Venice models list for the capabilities of the chat and backend
+// models. A failed probe is logged and not fatal: structured output is assumed and
+// falls back to prompt-based JSON if the API rejects it.
+func (v *VeniceService) InitModel(ctx context.Context, modelName string) error {
+	models, err := v.listModels(ctx)
+	if err != nil {
+		v.logger.Warn("Failed to probe Venice model capabilities; assuming structured output support", "error", err)
+		return nil
+	}
+
+	probe := []string{modelName}
+	if v.backendModelName != "" && v.backendModelName != modelName {
+		probe = append(probe, v.backendModelName)
+	}
+	for _, name := range probe {
+		i := slices.IndexFunc(models, func(m VeniceModel) bool { return m.ID == name })
+		if i < 0 {
+			v.logger.Warn("Model not found in Venice models list", "model", name)
+			continue
+		}
+		caps := models[i].ModelSpec.Capabilities
+		v.setSchemaSupport(name, caps.SupportsResponseSchema)
+		v.logger.Info("Venice model capabilities",
+			"model", name,
+			"response_schema", caps.SupportsResponseSchema,
+			"function_calling", caps.SupportsFunctionCalling)
+	}
+	return nil
+}
+
+// listModels fetches the text models available on Venice
+func (v *VeniceService) listModels(ctx context.Context) ([]VeniceModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", v.baseURL+"/models?type=text", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+v.apiKey)
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &veniceStatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var modelsResp VeniceModelsResponse
+	if err := json.Unmarshal(body, &modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse models response: %w", err)
+	}
+	return modelsResp.Data, nil
+}
+
+// supportsSchema reports whether the model takes a json_schema response format.
+// Models that haven't been probed are assumed to.
+func (v *VeniceService) supportsSchema(modelName string) bool {
+	v.schemaMu.RLock()
+	defer v.schemaMu.RUnlock()
+	supported, ok := v.schemaSupport[modelName]
+	return !ok || supported
+}
+
+func (v *VeniceService) setSchemaSupport(modelName string, supported bool) {
+	v.schemaMu.Lock()
+	defer v.schemaMu.Unlock()
+	v.schemaSupport[modelName] = supported
+}
+
+// structuredCompletion makes a temperature 0 request for JSON matching the format's schema.
+// Models without schema support get the schema in a system prompt instead. A model that
+// rejects the response format is remembered and retried with the prompt.
+func (v *VeniceService) structuredCompletion(ctx context.Context, messages []chat.ChatMessage, modelName string, format *VeniceResponseFormat) (string, error) {
+	if v.supportsSchema(modelName) {
+		content, err := v.chatCompletion(ctx, messages, modelName, 0.0, format)
+		var statusErr *veniceStatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+			return content, err
+		}
+		v.logger.Warn("Venice rejected structured output; falling back to prompt-based JSON",
+			"model", modelName, "schema", format.JSONSchema.Name, "error", err)
+		v.setSchemaSupport(modelName, false)
+	}
+
+	schema, err := json.Marshal(format.JSONSchema.Schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	v.logger.Debug("Using prompt-based JSON", "model", modelName, "schema", format.JSONSchema.Name)
+	fallback := append(slices.Clone(messages), chat.ChatMessage{
+		Role:    chat.ChatRoleSystem,
+		Content: fmt.Sprintf(jsonFallbackPrompt, schema),
+	})
+	return v.chatCompletion(ctx, fallback, modelName, 0.0, nil)
+}
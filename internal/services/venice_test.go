@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -18,7 +19,7 @@ func TestNewVeniceService(t *testing.T) {
 	modelName := "test-model"
 	backendModelName := "test-backend-model"
 
-	service := NewVeniceService(apiKey, modelName, backendModelName)
+	service := NewVeniceService(apiKey, modelName, backendModelName, slog.Default())
 
 	if service.apiKey != apiKey {
 		t.Errorf("Expected apiKey %s, got %s", apiKey, service.apiKey)
@@ -34,7 +35,7 @@ func TestNewVeniceService(t *testing.T) {
 }
 
 func TestVeniceService_InitModel(t *testing.T) {
-	service := NewVeniceService("invalid-key", "test-model", "test-backend-model")
+	service := NewVeniceService("invalid-key", "test-model", "test-backend-model", slog.Default())
 
 	// This should not fail even with invalid key since we handle the error gracefully
 	err := service.InitModel(context.Background(), "test-model")
@@ -97,7 +98,7 @@ func TestVeniceService_ChatStream(t *testing.T) {
 		defer server.Close()
 
 		// Create service with custom HTTP client pointing to mock server
-		service := NewVeniceService("test-key", "test-model", "test-model")
+		service := NewVeniceService("test-key", "test-model", "test-model", slog.Default())
 		service.httpClient = server.Client()
 
 		// For now, let's test the error case to verify the interface works
@@ -158,3 +159,107 @@ func TestVeniceService_ChatStream(t *testing.T) {
 		assert.Equal(t, "invalid_api_key", streamResp.Error.Code)
 	})
 }
+
+// newFakeVenice serves a models list and answers chat completions with the given
+// content, rejecting response_format with a 400 when rejectSchema is set
+func newFakeVenice(t *testing.T, models string, content string, rejectSchema bool) (*httptest.Server, *[]VeniceChatRequest) {
+	var requests []VeniceChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/models":
+			_, _ = w.Write([]byte(models))
+		case "/chat/completions":
+			var req VeniceChatRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			requests = append(requests, req)
+			if rejectSchema && req.ResponseFormat != nil {
+				http.Error(w, `{"error":"response_format is not supported"}`, http.StatusBadRequest)
+				return
+			}
+			resp := VeniceChatResponse{Choices: []VeniceChatChoice{{}}}
+			resp.Choices[0].Message.Content = content
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestVeniceService_StructuredOutput(t *testing.T) {
+	const models = `{"data":[
+		{"id":"schema-model","model_spec":{"capabilities":{"supportsResponseSchema":true,"supportsFunctionCalling":true}}},
+		{"id":"plain-model","model_spec":{"capabilities":{"supportsResponseSchema":false,"supportsFunctionCalling":false}}}
+	]}`
+	messages := []chat.ChatMessage{{Role: chat.ChatRoleUser, Content: "What next?"}}
+
+	t.Run("probed schema support uses the response format", func(t *testing.T) {
+		server, requests := newFakeVenice(t, models, `{"choices":["Run","Hide"]}`, false)
+		service := NewVeniceService("test-key", "schema-model", "", slog.Default())
+		service.baseURL = server.URL
+
+		require.NoError(t, service.InitModel(context.Background(), "schema-model"))
+		choices, err := service.SuggestChoices(context.Background(), messages)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Run", "Hide"}, choices)
+		require.Len(t, *requests, 1)
+		require.NotNil(t, (*requests)[0].ResponseFormat)
+		assert.Equal(t, "suggest_choices", (*requests)[0].ResponseFormat.JSONSchema.Name)
+	})
+
+	t.Run("probed lack of schema support prompts for JSON", func(t *testing.T) {
+		server, requests := newFakeVenice(t, models, "Sure!\n```json\n{\"choices\":[\"Run\",\"Hide\"]}\n```", false)
+		service := NewVeniceService("test-key", "schema-model", "plain-model", slog.Default())
+		service.baseURL = server.URL
+
+		require.NoError(t, service.InitModel(context.Background(), "schema-model"))
+		assert.True(t, service.supportsSchema("schema-model"))
+		assert.False(t, service.supportsSchema("plain-model"))
+
+		choices, err := service.SuggestChoices(context.Background(), messages)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Run", "Hide"}, choices)
+		require.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, "plain-model", req.Model)
+		assert.Nil(t, req.ResponseFormat)
+		require.Len(t, req.Messages, 2)
+		assert.Equal(t, chat.ChatRoleSystem, req.Messages[1].Role)
+		assert.Contains(t, req.Messages[1].Content, `"choices"`)
+	})
+
+	t.Run("rejected response format falls back and is remembered", func(t *testing.T) {
+		server, requests := newFakeVenice(t, `{"data":[]}`, `{"issues":[]}`, true)
+		service := NewVeniceService("test-key", "new-model", "", slog.Default())
+		service.baseURL = server.URL
+
+		require.NoError(t, service.InitModel(context.Background(), "new-model"))
+		for range 2 {
+			issues, err := service.CheckNarration(context.Background(), messages)
+			require.NoError(t, err)
+			assert.Empty(t, issues)
+		}
+
+		// Structured, then prompt-based; the second call goes straight to the prompt
+		require.Len(t, *requests, 3)
+		assert.NotNil(t, (*requests)[0].ResponseFormat)
+		assert.Nil(t, (*requests)[1].ResponseFormat)
+		assert.Nil(t, (*requests)[2].ResponseFormat)
+		assert.False(t, service.supportsSchema("new-model"))
+	})
+
+	t.Run("failed probe is not fatal", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		service := NewVeniceService("bad-key", "test-model", "", slog.Default())
+		service.baseURL = server.URL
+
+		assert.NoError(t, service.InitModel(context.Background(), "test-model"))
+		assert.True(t, service.supportsSchema("test-model"))
+	})
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// FileStore serves the filesystem-backed resources in the data directory: scenarios,
+// narrators, PCs, monsters, and NPCs. Game state storages embed it, and it can be
+// combined with another game state store with storage.Combine.
+type FileStore struct {
+	dataDir  string
+	logger   *slog.Logger
+	manifest *ContentManifest // optional; files are verified against it when read, and can't be written
+	hidden   *hiddenScenarios // left out of ListScenarios; shared with copies, e.g. per-profile storages
+}
+
+// Ensure FileStore implements ContentStore interface
+var _ storage.ContentStore = (*FileStore)(nil)
+
+// NewFileStore creates a content store reading resources from dataDir
+func NewFileStore(dataDir string, logger *slog.Logger) *FileStore {
+	if dataDir == "" {
+		dataDir = "./data"
+	}
+	return &FileStore{dataDir: dataDir, logger: logger, hidden: newHiddenScenarios()}
+}
+
+// WithContentManifest verifies every content file read against manifest, and refuses
+// writes, which would no longer match it
+func (r *FileStore) WithContentManifest(manifest *ContentManifest) *FileStore {
+	r.manifest = manifest
+	return r
+}
+
+// Helpers for the filesystem-backed resources in the data directory
+
+// readDataFile reads a content file, verifying it against the content manifest if one is set
+func (r *FileStore) readDataFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || r.manifest == nil {
+		return data, err
+	}
+	rel, err := filepath.Rel(r.dataDir, path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is outside the data directory", ErrContentIntegrity, path)
+	}
+	if err := r.manifest.Check(filepath.ToSlash(rel), data); err != nil {
+		r.logger.Error("Refused content file", "path", path, "error", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// writeDataFile writes v as indented JSON to <dataDir>/<dir>/<id>.json. The file is
+// written under a temporary name and renamed into place, so readers never see a
+// partly written file.
+func (r *FileStore) writeDataFile(dir, id string, v any) error {
+	if r.manifest != nil {
+		return storage.ErrContentLocked
+	}
+	if id == "" {
+		return fmt.Errorf("%s ID is required", dir)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s %s: %w", dir, id, err)
+	}
+
+	dirPath := filepath.Join(r.dataDir, dir)
+	if err := os.MkdirAll(dirPath, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dirPath, "."+id+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s %s: %w", dir, id, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s %s: %w", dir, id, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s %s: %w", dir, id, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dirPath, id+".json")); err != nil {
+		return fmt.Errorf("failed to write %s %s: %w", dir, id, err)
+	}
+	return nil
+}
+
+// removeDataFile deletes <dataDir>/<dir>/<id>.json; a missing file is not an error
+func (r *FileStore) removeDataFile(dir, id string) error {
+	if r.manifest != nil {
+		return storage.ErrContentLocked
+	}
+	err := os.Remove(filepath.Join(r.dataDir, dir, id+".json"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete %s %s: %w", dir, id, err)
+	}
+	return nil
+}
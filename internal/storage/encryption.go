@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Encrypted payloads are stored as "enc:<version>:<key ID>:<base64 of nonce and
+// ciphertext>". Version 2 payloads are bound to what they were sealed for, such as a
+// game's state, by authenticated data; version 1 payloads, sealed before that, are
+// still opened without it.
+const (
+	encryptedPrefix   = "enc:v2:"
+	encryptedPrefixV1 = "enc:v1:"
+)
+
+// Encryptor encrypts game state payloads with AES-GCM. Payloads name the key that
+// sealed them, so keys can be rotated without re-encrypting stored games.
+type Encryptor struct {
+	active string
+	aeads  map[string]cipher.AEAD
+}
+
+// NewEncryptor creates an encryptor that seals with the active key and opens
+// payloads sealed with any of keys
+func NewEncryptor(keys map[string][]byte, active string) (*Encryptor, error) {
+	e := &Encryptor{active: active, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid encryption key ID %q", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		e.aeads[id] = aead
+	}
+	if _, ok := e.aeads[active]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not configured", active)
+	}
+	return e, nil
+}
+
+// Seal encrypts plaintext with the active key. The payload only opens with the same
+// aad, such as the ID of the game it belongs to, so it can't be moved elsewhere.
+func (e *Encryptor) Seal(plaintext, aad []byte) (string, error) {
+	aead := e.aeads[e.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, aad)
+	return encryptedPrefix + e.active + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a payload from Seal with the key named in it. aad must be the one it
+// was sealed with.
+func (e *Encryptor) Open(payload string, aad []byte) ([]byte, error) {
+	rest, ok := strings.CutPrefix(payload, encryptedPrefix)
+	if !ok {
+		if rest, ok = strings.CutPrefix(payload, encryptedPrefixV1); !ok {
+			return nil, fmt.Errorf("not an encrypted payload")
+		}
+		aad = nil
+	}
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return nil, fmt.Errorf("not an encrypted payload")
+	}
+	aead, ok := e.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("payload is encrypted with unknown key %q", id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted payload: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted payload: too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload with key %q: %w", id, err)
+	}
+	return plaintext, nil
+}
+
+// seal encrypts a payload for storage when encryption is on, and otherwise returns it as
+// is. aad names what the payload is, e.g. from gameStateAAD; see Encryptor.Seal.
+func (r *RedisStorage) seal(data []byte, aad string) (string, error) {
+	if r.encryptor == nil {
+		return string(data), nil
+	}
+	return r.encryptor.Seal(data, []byte(aad))
+}
+
+// open returns a stored payload's plaintext, given the aad it was sealed with. Payloads
+// saved before encryption was turned on are read as they are.
+func (r *RedisStorage) open(payload, aad string) ([]byte, error) {
+	if !strings.HasPrefix(payload, encryptedPrefix) && !strings.HasPrefix(payload, encryptedPrefixV1) {
+		return []byte(payload), nil
+	}
+	if r.encryptor == nil {
+		return nil, fmt.Errorf("payload is encrypted but no encryption keys are configured")
+	}
+	return r.encryptor.Open(payload, []byte(aad))
+}
+
+// The authenticated data each kind of payload is sealed with. It names the game, so a
+// payload copied under another game's key fails to open.
+func gameStateAAD(id uuid.UUID) string { return "gamestate:" + id.String() }
+func gameEventAAD(id uuid.UUID) string { return "event:" + id.String() }
+func snapshotAAD(id uuid.UUID, name string) string {
+	return "snapshot:" + id.String() + ":" + name
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func testEncryptor(t *testing.T, active string, ids ...string) *Encryptor {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, 32)
+	}
+	enc, err := NewEncryptor(keys, active)
+	if err != nil {
+		t.Fatalf("Failed to create encryptor: %v", err)
+	}
+	return enc
+}
+
+func TestNewEncryptor(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	tests := []struct {
+		name    string
+		keys    map[string][]byte
+		active  string
+		wantErr bool
+	}{
+		{name: "valid", keys: map[string][]byte{"k1": key}, active: "k1"},
+		{name: "active key missing", keys: map[string][]byte{"k1": key}, active: "k2", wantErr: true},
+		{name: "bad key length", keys: map[string][]byte{"k1": key[:10]}, active: "k1", wantErr: true},
+		{name: "colon in key ID", keys: map[string][]byte{"k:1": key}, active: "k:1", wantErr: true},
+		{name: "empty key ID", keys: map[string][]byte{"": key}, active: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEncryptor(tt.keys, tt.active)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewEncryptor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryptor_SealOpen(t *testing.T) {
+	enc := testEncryptor(t, "k1", "k1")
+	aad := []byte("gamestate:1")
+	payload, err := enc.Seal([]byte(`{"location":"hall"}`), aad)
+	if err != nil {
+		t.Fatalf("Seal() error: %v", err)
+	}
+	if !strings.HasPrefix(payload, "enc:v2:k1:") || strings.Contains(payload, "hall") {
+		t.Errorf("Expected an encrypted payload naming key k1, got %q", payload)
+	}
+	plaintext, err := enc.Open(payload, aad)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	if string(plaintext) != `{"location":"hall"}` {
+		t.Errorf("Expected the original plaintext, got %q", plaintext)
+	}
+
+	other := testEncryptor(t, "k2", "k2")
+	if _, err := other.Open(payload, aad); err == nil {
+		t.Error("Expected an error opening a payload sealed with an unknown key")
+	}
+	if _, err := enc.Open(payload[:len(payload)-4]+"AAAA", aad); err == nil {
+		t.Error("Expected an error opening a tampered payload")
+	}
+	if _, err := enc.Open(payload, []byte("gamestate:2")); err == nil {
+		t.Error("Expected an error opening a payload with other authenticated data")
+	}
+
+	// Version 1 payloads were sealed without authenticated data
+	aead := enc.aeads["k1"]
+	nonce := make([]byte, aead.NonceSize())
+	v1 := "enc:v1:k1:" + base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte("old"), nil))
+	if plaintext, err := enc.Open(v1, aad); err != nil || string(plaintext) != "old" {
+		t.Errorf("Expected to open a version 1 payload, got %q, err %v", plaintext, err)
+	}
+}
+
+func TestRedisStorage_EncryptedPayloadMovedToAnotherGame(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	rs := NewRedisStorage(mr.Addr(), "", logger).WithEventSourcing(true).WithEncryption(testEncryptor(t, "k1", "k1"))
+	ctx := context.Background()
+
+	victim := state.NewGameState("test.json", nil, "test-model")
+	source := state.NewGameState("test.json", nil, "test-model")
+	for _, gs := range []*state.GameState{victim, source} {
+		if err := rs.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventCreated}), gs.ID, gs); err != nil {
+			t.Fatalf("Failed to save gamestate: %v", err)
+		}
+	}
+	if err := rs.SaveSnapshot(ctx, source.ID, &state.Snapshot{Name: "start", GameState: source}); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+
+	// Someone with write access to Redis copies the source game's payloads over the victim's
+	raw, _ := mr.Get(rs.gameStateKey(source.ID))
+	_ = mr.Set(rs.gameStateKey(victim.ID), raw)
+	events, _ := mr.List(rs.gameEventsKey(source.ID))
+	mr.Del(rs.gameEventsKey(victim.ID))
+	for _, e := range events {
+		_, _ = mr.Push(rs.gameEventsKey(victim.ID), e)
+	}
+	snapshot := mr.HGet(rs.gameSnapshotsKey(source.ID), "start")
+	mr.HSet(rs.gameSnapshotsKey(victim.ID), "start", snapshot)
+	mr.HSet(rs.gameSnapshotsKey(source.ID), "renamed", snapshot)
+
+	if _, err := rs.LoadGameState(ctx, victim.ID); err == nil {
+		t.Error("Expected a gamestate moved to another game to fail to open")
+	}
+	if _, err := rs.ListGameEvents(ctx, victim.ID); err == nil {
+		t.Error("Expected events moved to another game to fail to open")
+	}
+	if _, err := rs.LoadSnapshot(ctx, victim.ID, "start"); err == nil {
+		t.Error("Expected a snapshot moved to another game to fail to open")
+	}
+	if _, err := rs.LoadSnapshot(ctx, source.ID, "renamed"); err == nil {
+		t.Error("Expected a snapshot moved to another name to fail to open")
+	}
+}
+
+func TestRedisStorage_Encryption(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	newGame := func(location string) *state.GameState {
+		gs := state.NewGameState("test.json", nil, "test-model")
+		gs.Location = location
+		gs.ChatHistory = []chat.ChatMessage{{Role: chat.ChatRoleUser, Content: "open the secret door"}}
+		return gs
+	}
+
+	// A game saved before encryption was turned on
+	plain := NewRedisStorage(mr.Addr(), "", logger)
+	legacy := newGame("attic")
+	if err := plain.SaveGameState(ctx, legacy.ID, legacy); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+
+	rs := NewRedisStorage(mr.Addr(), "", logger).WithEncryption(testEncryptor(t, "k1", "k1"))
+	gs := newGame("hall")
+	if err := rs.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	raw, err := mr.Get(rs.gameStateKey(gs.ID))
+	if err != nil {
+		t.Fatalf("Failed to read raw gamestate: %v", err)
+	}
+	if !strings.HasPrefix(raw, "enc:v2:k1:") || strings.Contains(raw, "secret door") {
+		t.Errorf("Expected the stored gamestate to be encrypted with k1, got %q", raw)
+	}
+
+	loaded, err := rs.LoadGameState(ctx, gs.ID)
+	if err != nil || loaded == nil || loaded.Location != "hall" || len(loaded.ChatHistory) != 1 {
+		t.Fatalf("Expected to load the encrypted gamestate, got %+v, err %v", loaded, err)
+	}
+	if loaded, err := rs.LoadGameState(ctx, legacy.ID); err != nil || loaded == nil || loaded.Location != "attic" {
+		t.Errorf("Expected to load the unencrypted gamestate, got %+v, err %v", loaded, err)
+	}
+	if _, err := plain.LoadGameState(ctx, gs.ID); err == nil {
+		t.Error("Expected an error loading an encrypted gamestate without keys")
+	}
+
+	// Rotate: k2 becomes active and k1 stays to read older saves
+	rotated := NewRedisStorage(mr.Addr(), "", logger).WithEncryption(testEncryptor(t, "k2", "k1", "k2"))
+	loaded, err = rotated.LoadGameState(ctx, gs.ID)
+	if err != nil || loaded == nil || loaded.Location != "hall" {
+		t.Fatalf("Expected to load the gamestate saved with k1, got %+v, err %v", loaded, err)
+	}
+	if err := rotated.SaveGameState(ctx, gs.ID, loaded); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	if raw, _ := mr.Get(rotated.gameStateKey(gs.ID)); !strings.HasPrefix(raw, "enc:v2:k2:") {
+		t.Errorf("Expected the gamestate to be re-encrypted with k2, got %q", raw)
+	}
+
+	// Once k1 is retired, games still sealed with it can't be read
+	retired := NewRedisStorage(mr.Addr(), "", logger).WithEncryption(testEncryptor(t, "k2", "k2"))
+	other := newGame("cellar")
+	if err := rs.SaveGameState(ctx, other.ID, other); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	if _, err := retired.LoadGameState(ctx, other.ID); err == nil {
+		t.Error("Expected an error loading a gamestate encrypted with a retired key")
+	}
+}
+
+func TestRedisStorage_EncryptedEvents(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	rs := NewRedisStorage(mr.Addr(), "", logger).WithEventSourcing(true).WithEncryption(testEncryptor(t, "k1", "k1"))
+	ctx := context.Background()
+
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.Location = "hall"
+	if err := rs.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventCreated}), gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	gs.Location = "cellar"
+	if err := rs.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+
+	raw, err := mr.List(rs.gameEventsKey(gs.ID))
+	if err != nil {
+		t.Fatalf("Failed to read raw events: %v", err)
+	}
+	for i, e := range raw {
+		if !strings.HasPrefix(e, "enc:v2:k1:") || strings.Contains(e, "cellar") {
+			t.Errorf("Expected event %d to be encrypted, got %q", i+1, e)
+		}
+	}
+
+	events, err := rs.ListGameEvents(ctx, gs.ID)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d, err %v", len(events), err)
+	}
+
+	// The state is rebuilt from the encrypted log, and the fork's copied log stays readable
+	mr.Del(rs.gameStateKey(gs.ID))
+	if loaded, err := rs.LoadGameState(ctx, gs.ID); err != nil || loaded == nil || loaded.Location != "cellar" {
+		t.Fatalf("Expected to rebuild the gamestate, got %+v, err %v", loaded, err)
+	}
+	fork, err := rs.ForkGameState(ctx, gs.ID, 1, uuid.New())
+	if err != nil || fork.Location != "hall" {
+		t.Fatalf("Expected a fork in the hall, got %+v, err %v", fork, err)
+	}
+	if loaded, err := rs.LoadGameState(ctx, fork.ID); err != nil || loaded == nil || loaded.Location != "hall" {
+		t.Errorf("Expected to load the fork, got %+v, err %v", loaded, err)
+	}
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/jsonpatch"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/redis/go-redis/v9"
+)
+
+// Game event log operations (Redis-backed)
+
+// maxEventRetries bounds how often a save is retried when another save of the same
+// game lands between reading the previous state and appending the event
+const maxEventRetries = 5
+
+// gameEventsKey returns the Redis key of a game's event log, e.g. "gamestate:<id>:events"
+func (r *RedisStorage) gameEventsKey(id uuid.UUID) string {
+	return r.gameStateKey(id) + ":events"
+}
+
+// saveWithEvent saves a game state and appends the change to its event log in one
+// transaction. The event's kind and details come from the context; see state.WithGameEvent.
+func (r *RedisStorage) saveWithEvent(ctx context.Context, id uuid.UUID, gs *state.GameState, data []byte, ttl time.Duration) error {
+	key, eventsKey := r.gameStateKey(id), r.gameEventsKey(id)
+	ev := state.GameEventFromContext(ctx)
+	ev.Turn = gs.TurnCounter
+	ev.At = gs.UpdatedAt
+
+	save := func(tx *redis.Tx) error {
+		n, err := tx.LLen(ctx, eventsKey).Result()
+		if err != nil {
+			return err
+		}
+		prev, err := r.previousGameState(ctx, tx, id, key, eventsKey, n)
+		if err != nil {
+			return err
+		}
+		if ev.Patch, err = jsonpatch.CreateMergePatch(prev, data); err != nil {
+			return err
+		}
+		ev.Seq = int(n) + 1
+		event, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		snapshot, err := r.seal(data, gameStateAAD(id))
+		if err != nil {
+			return err
+		}
+		sealedEvent, err := r.seal(event, gameEventAAD(id))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, snapshot, ttl)
+			pipe.RPush(ctx, eventsKey, sealedEvent)
+			if ttl > 0 {
+				pipe.Expire(ctx, eventsKey, ttl)
+			} else {
+				pipe.Persist(ctx, eventsKey)
+			}
+			r.expireSnapshots(ctx, pipe, id, ttl)
+			return nil
+		})
+		return err
+	}
+
+	for range maxEventRetries {
+		err := r.client.Watch(ctx, save, key, eventsKey)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to save gamestate event", "uuid", id, "error", err)
+			return fmt.Errorf("failed to save gamestate event: %w", err)
+		}
+		return nil
+	}
+	r.logger.ErrorContext(ctx, "Failed to save gamestate event, too many concurrent saves", "uuid", id)
+	return fmt.Errorf("failed to save gamestate event: too many concurrent saves")
+}
+
+// previousGameState returns the JSON the next event's patch is taken against: nothing
+// for an empty log, so the first event holds the whole state, otherwise the saved
+// state, or the log's projection if the saved state is gone
+func (r *RedisStorage) previousGameState(ctx context.Context, tx *redis.Tx, id uuid.UUID, key, eventsKey string, n int64) ([]byte, error) {
+	if n == 0 {
+		return []byte("{}"), nil
+	}
+	prev, err := tx.Get(ctx, key).Result()
+	if err == nil {
+		return r.open(prev, gameStateAAD(id))
+	}
+	if err != redis.Nil {
+		return nil, err
+	}
+	raw, err := tx.LRange(ctx, eventsKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	events, err := r.decodeGameEvents(id, raw)
+	if err != nil {
+		return nil, err
+	}
+	return state.ProjectJSON(events)
+}
+
+// rebuildGameState projects a game's event log and saves the result as its current state.
+// It returns nil when the game has no events.
+func (r *RedisStorage) rebuildGameState(ctx context.Context, id uuid.UUID) (*state.GameState, error) {
+	events, err := r.ListGameEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		r.logger.WarnContext(ctx, "Gamestate not found", "uuid", id)
+		return nil, nil
+	}
+
+	gs, err := state.Project(events)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to rebuild gamestate from events", "uuid", id, "error", err)
+		return nil, fmt.Errorf("failed to rebuild gamestate: %w", err)
+	}
+	data, err := json.Marshal(gs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal gamestate: %w", err)
+	}
+	snapshot, err := r.seal(data, gameStateAAD(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt gamestate: %w", err)
+	}
+	ttl := gameStateTTL
+	if gs.Paused {
+		ttl = 0
+	}
+	if err := r.client.Set(ctx, r.gameStateKey(id), snapshot, ttl).Err(); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to save rebuilt gamestate", "uuid", id, "error", err)
+		return nil, fmt.Errorf("failed to save rebuilt gamestate: %w", err)
+	}
+	r.logger.InfoContext(ctx, "Rebuilt gamestate from events", "uuid", id, "events", len(events))
+	return gs, nil
+}
+
+// ListGameEvents returns a game's event log, oldest first. It is empty when event
+// sourcing is off or the game hasn't been saved since it was turned on.
+func (r *RedisStorage) ListGameEvents(ctx context.Context, id uuid.UUID) ([]state.GameEvent, error) {
+	raw, err := r.client.LRange(ctx, r.gameEventsKey(id), 0, -1).Result()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to load gamestate events", "uuid", id, "error", err)
+		return nil, fmt.Errorf("failed to load gamestate events: %w", err)
+	}
+	return r.decodeGameEvents(id, raw)
+}
+
+// ForkGameState copies the first seq events of a game's log to a new game with ID newID,
+// so the fork shares the original's history, and saves the fork's state. The fork's log
+// continues with a forked event that records the new ID.
+func (r *RedisStorage) ForkGameState(ctx context.Context, id uuid.UUID, seq int, newID uuid.UUID) (*state.GameState, error) {
+	if !r.eventSourcing {
+		return nil, fmt.Errorf("event sourcing is not enabled")
+	}
+	if seq < 1 {
+		return nil, fmt.Errorf("invalid event sequence number %d", seq)
+	}
+	raw, err := r.client.LRange(ctx, r.gameEventsKey(id), 0, int64(seq)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gamestate events: %w", err)
+	}
+	if len(raw) < seq {
+		return nil, fmt.Errorf("game %s has no event %d", id, seq)
+	}
+	events, err := r.decodeGameEvents(id, raw)
+	if err != nil {
+		return nil, err
+	}
+	gs, err := state.Project(events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to project gamestate: %w", err)
+	}
+
+	// Encrypted events are bound to their game, so the copies are sealed for the fork
+	copied := make([]any, len(raw))
+	for i, e := range raw {
+		data, err := r.open(e, gameEventAAD(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt gamestate event %d: %w", i+1, err)
+		}
+		if copied[i], err = r.seal(data, gameEventAAD(newID)); err != nil {
+			return nil, fmt.Errorf("failed to encrypt gamestate event %d: %w", i+1, err)
+		}
+	}
+	if err := r.client.RPush(ctx, r.gameEventsKey(newID), copied...).Err(); err != nil {
+		return nil, fmt.Errorf("failed to copy gamestate events: %w", err)
+	}
+	gs.ID = newID
+	forkCtx := state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventForked, ForkedFrom: id.String()})
+	if err := r.SaveGameState(forkCtx, newID, gs); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+// decodeGameEvents decrypts and unmarshals the raw event log entries of game id
+func (r *RedisStorage) decodeGameEvents(id uuid.UUID, raw []string) ([]state.GameEvent, error) {
+	events := make([]state.GameEvent, 0, len(raw))
+	for i, payload := range raw {
+		data, err := r.open(payload, gameEventAAD(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt gamestate event %d: %w", i+1, err)
+		}
+		var ev state.GameEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal gamestate event %d: %w", i+1, err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func TestRedisStorage_EventSourcing(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	rs := NewRedisStorage(mr.Addr(), "", logger).WithEventSourcing(true)
+	ctx := context.Background()
+
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.Location = "hall"
+	save := func(ev state.GameEvent) {
+		t.Helper()
+		if err := rs.SaveGameState(state.WithGameEvent(ctx, ev), gs.ID, gs); err != nil {
+			t.Fatalf("Failed to save gamestate: %v", err)
+		}
+	}
+
+	save(state.GameEvent{Kind: state.EventCreated})
+	gs.IncrementTurnCounters()
+	gs.Location = "cellar"
+	save(state.GameEvent{Kind: state.EventDelta, Delta: &conditionals.GameStateDelta{UserLocation: "cellar"}, ConditionalsFired: []string{"dark"}})
+	gs.Paused = true
+	if err := rs.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+
+	events, err := rs.ListGameEvents(ctx, gs.ID)
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	wantKinds := []string{state.EventCreated, state.EventDelta, state.EventSaved}
+	if len(events) != len(wantKinds) {
+		t.Fatalf("Expected %d events, got %d", len(wantKinds), len(events))
+	}
+	for i, ev := range events {
+		if ev.Seq != i+1 || ev.Kind != wantKinds[i] {
+			t.Errorf("Event %d: expected seq %d kind %q, got seq %d kind %q", i, i+1, wantKinds[i], ev.Seq, ev.Kind)
+		}
+	}
+	if events[1].Turn != 1 || len(events[1].ConditionalsFired) != 1 || events[1].Delta == nil {
+		t.Errorf("Expected the delta event to record its turn, delta and conditionals, got %+v", events[1])
+	}
+	if ttl := mr.TTL(rs.gameEventsKey(gs.ID)); ttl != 0 {
+		t.Errorf("Expected a paused game's event log not to expire, got TTL %v", ttl)
+	}
+
+	// The saved state is rebuilt from the log when it's missing
+	mr.Del(rs.gameStateKey(gs.ID))
+	loaded, err := rs.LoadGameState(ctx, gs.ID)
+	if err != nil || loaded == nil {
+		t.Fatalf("Expected the game to be rebuilt from events, got %v, %v", loaded, err)
+	}
+	if loaded.Location != "cellar" || !loaded.Paused || loaded.TurnCounter != 1 {
+		t.Errorf("Rebuilt state doesn't match the last save: %+v", loaded)
+	}
+	if !mr.Exists(rs.gameStateKey(gs.ID)) {
+		t.Error("Expected the rebuilt state to be saved")
+	}
+
+	// Forking at the delta event starts a new game from that point
+	forkID := uuid.New()
+	fork, err := rs.ForkGameState(ctx, gs.ID, 2, forkID)
+	if err != nil {
+		t.Fatalf("Failed to fork: %v", err)
+	}
+	if fork.ID != forkID || fork.Location != "cellar" || fork.Paused {
+		t.Errorf("Fork doesn't match the state at event 2: %+v", fork)
+	}
+	forkEvents, err := rs.ListGameEvents(ctx, forkID)
+	if err != nil {
+		t.Fatalf("Failed to list fork events: %v", err)
+	}
+	if len(forkEvents) != 3 || forkEvents[2].Kind != state.EventForked || forkEvents[2].ForkedFrom != gs.ID.String() {
+		t.Errorf("Expected the fork's log to be the first two events and a forked event, got %+v", forkEvents)
+	}
+	if loaded, err := rs.LoadGameState(ctx, forkID); err != nil || loaded == nil || loaded.ID != forkID {
+		t.Errorf("Expected to load the fork, got %v, %v", loaded, err)
+	}
+	if _, err := rs.ForkGameState(ctx, gs.ID, 4, uuid.New()); err == nil {
+		t.Error("Expected an error forking past the end of the log")
+	}
+
+	// Event logs aren't listed as games, and are deleted with them
+	ids, err := rs.ListGameStateIDs(ctx)
+	if err != nil || len(ids) != 2 {
+		t.Errorf("Expected 2 games, got %v, %v", ids, err)
+	}
+	if err := rs.DeleteGameState(ctx, gs.ID); err != nil {
+		t.Fatalf("Failed to delete gamestate: %v", err)
+	}
+	if mr.Exists(rs.gameEventsKey(gs.ID)) {
+		t.Error("Expected the event log to be deleted with the game")
+	}
+}
+
+func TestRedisStorage_EventSourcingDisabled(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	rs := NewRedisStorage(mr.Addr(), "", logger)
+	ctx := context.Background()
+
+	gs := state.NewGameState("test.json", nil, "test-model")
+	if err := rs.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	events, err := rs.ListGameEvents(ctx, gs.ID)
+	if err != nil || len(events) != 0 {
+		t.Errorf("Expected no events, got %v, %v", events, err)
+	}
+	if _, err := rs.ForkGameState(ctx, gs.ID, 1, uuid.New()); err == nil {
+		t.Error("Expected an error forking without event sourcing")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,26 @@ import (
 
 // GameState operations (Redis-backed)
 
+// gameStateTTL is how long an idle game is kept
+const gameStateTTL = time.Hour
+
+// gameStateKeyPattern matches the keys of every game state in this storage's prefix
+func (r *RedisStorage) gameStateKeyPattern() string {
+	if r.keyPrefix == "" {
+		return "gamestate:*"
+	}
+	return r.keyPrefix + ":gamestate:*"
+}
+
+// gameStateKey returns the Redis key for a game state, e.g. "gamestate:<id>"
+// or "<prefix>:gamestate:<id>" for a prefixed storage
+func (r *RedisStorage) gameStateKey(id uuid.UUID) string {
+	if r.keyPrefix == "" {
+		return "gamestate:" + id.String()
+	}
+	return r.keyPrefix + ":gamestate:" + id.String()
+}
+
 func (r *RedisStorage) SaveGameState(ctx context.Context, id uuid.UUID, gs *state.GameState) error {
 	// Update the UpdatedAt timestamp
 	gs.UpdatedAt = time.Now()
@@ -20,15 +41,32 @@ func (r *RedisStorage) SaveGameState(ctx context.Context, id uuid.UUID, gs *stat
 	// Marshal gamestate to JSON
 	data, err := json.Marshal(gs)
 	if err != nil {
-		r.logger.Error("Failed to marshal gamestate", "uuid", id, "error", err)
+		r.logger.ErrorContext(ctx, "Failed to marshal gamestate", "uuid", id, "error", err)
 		return fmt.Errorf("failed to marshal gamestate: %w", err)
 	}
 
-	// Use gamestate: prefix for gamestate keys
-	key := "gamestate:" + id.String()
-	cmd := r.client.Set(ctx, key, string(data), time.Hour)
-	if err := cmd.Err(); err != nil {
-		r.logger.Error("Failed to save gamestate", "uuid", id, "error", err)
+	// Paused games don't expire, so a long pause can't lose the game
+	ttl := gameStateTTL
+	if gs.Paused {
+		ttl = 0
+	}
+
+	if r.eventSourcing {
+		return r.saveWithEvent(ctx, id, gs, data, ttl)
+	}
+
+	payload, err := r.seal(data, gameStateAAD(id))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to encrypt gamestate", "uuid", id, "error", err)
+		return fmt.Errorf("failed to encrypt gamestate: %w", err)
+	}
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, r.gameStateKey(id), payload, ttl)
+		r.expireSnapshots(ctx, pipe, id, ttl)
+		return nil
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to save gamestate", "uuid", id, "error", err)
 		return fmt.Errorf("failed to save gamestate: %w", err)
 	}
 
@@ -36,26 +74,34 @@ func (r *RedisStorage) SaveGameState(ctx context.Context, id uuid.UUID, gs *stat
 }
 
 func (r *RedisStorage) LoadGameState(ctx context.Context, id uuid.UUID) (*state.GameState, error) {
-	key := "gamestate:" + id.String()
+	key := r.gameStateKey(id)
 	cmd := r.client.Get(ctx, key)
 	if err := cmd.Err(); err != nil {
 		if err == redis.Nil {
-			r.logger.Warn("Gamestate not found", "uuid", id)
+			if r.eventSourcing {
+				// The saved state is a projection of the event log, so it can be rebuilt
+				return r.rebuildGameState(ctx, id)
+			}
+			r.logger.WarnContext(ctx, "Gamestate not found", "uuid", id)
 			return nil, nil // Return nil for not found
 		}
-		r.logger.Error("Failed to load gamestate", "uuid", id, "error", err)
+		r.logger.ErrorContext(ctx, "Failed to load gamestate", "uuid", id, "error", err)
 		return nil, fmt.Errorf("failed to load gamestate: %w", err)
 	}
 
-	data := cmd.Val()
-	if data == "" {
-		r.logger.Warn("Gamestate not found", "uuid", id)
+	if cmd.Val() == "" {
+		r.logger.WarnContext(ctx, "Gamestate not found", "uuid", id)
 		return nil, nil
 	}
+	data, err := r.open(cmd.Val(), gameStateAAD(id))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to decrypt gamestate", "uuid", id, "error", err)
+		return nil, fmt.Errorf("failed to decrypt gamestate: %w", err)
+	}
 
 	var gs state.GameState
-	if err := json.Unmarshal([]byte(data), &gs); err != nil {
-		r.logger.Error("Failed to unmarshal gamestate", "uuid", id, "error", err)
+	if err := json.Unmarshal(data, &gs); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to unmarshal gamestate", "uuid", id, "error", err)
 		return nil, fmt.Errorf("failed to unmarshal gamestate: %w", err)
 	}
 
@@ -63,11 +109,32 @@ func (r *RedisStorage) LoadGameState(ctx context.Context, id uuid.UUID) (*state.
 }
 
 func (r *RedisStorage) DeleteGameState(ctx context.Context, id uuid.UUID) error {
-	key := "gamestate:" + id.String()
-	cmd := r.client.Del(ctx, key)
+	cmd := r.client.Del(ctx, r.gameStateKey(id), r.gameEventsKey(id), r.gameSnapshotsKey(id))
 	if err := cmd.Err(); err != nil {
-		r.logger.Error("Failed to delete gamestate", "uuid", id, "error", err)
+		r.logger.ErrorContext(ctx, "Failed to delete gamestate", "uuid", id, "error", err)
 		return fmt.Errorf("failed to delete gamestate: %w", err)
 	}
 	return nil
 }
+
+// ListGameStateIDs returns the IDs of all stored game states. Keys are scanned
+// rather than listed with KEYS so a large keyspace doesn't block Redis.
+func (r *RedisStorage) ListGameStateIDs(ctx context.Context) ([]uuid.UUID, error) {
+	pattern := r.gameStateKeyPattern()
+	prefix := strings.TrimSuffix(pattern, "*")
+
+	var ids []uuid.UUID
+	iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		id, err := uuid.Parse(strings.TrimPrefix(iter.Val(), prefix))
+		if err != nil {
+			continue // not a game state key
+		}
+		ids = append(ids, id)
+	}
+	if err := iter.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to list gamestates", "error", err)
+		return nil, fmt.Errorf("failed to list gamestates: %w", err)
+	}
+	return ids, nil
+}
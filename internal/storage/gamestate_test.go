@@ -2,9 +2,12 @@ package storage
 
 import (
 	"context"
+	"log/slog"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/google/uuid"
 	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
@@ -133,3 +136,100 @@ func TestMockStorage_UpdateGameState(t *testing.T) {
 		t.Errorf("Expected inventory with 'potion', got %v", loaded.Inventory)
 	}
 }
+
+func TestRedisStorage_KeyPrefix(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	base := NewRedisStorage(mr.Addr(), "", logger)
+	kids := base.WithKeyPrefix("kids")
+	ctx := context.Background()
+
+	gs := state.NewGameState("test.json", nil, "test-model")
+	if err := kids.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+
+	if !mr.Exists("kids:gamestate:" + gs.ID.String()) {
+		t.Errorf("Expected prefixed key to be written, keys: %v", mr.Keys())
+	}
+	if loaded, err := kids.LoadGameState(ctx, gs.ID); err != nil || loaded == nil {
+		t.Errorf("Expected prefixed storage to load its own game, got %v, %v", loaded, err)
+	}
+	if loaded, err := base.LoadGameState(ctx, gs.ID); err != nil || loaded != nil {
+		t.Errorf("Expected unprefixed storage not to see the game, got %v, %v", loaded, err)
+	}
+}
+
+func TestRedisStorage_PausedGameDoesNotExpire(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	rs := NewRedisStorage(mr.Addr(), "", logger)
+	ctx := context.Background()
+
+	gs := state.NewGameState("test.json", nil, "test-model")
+	key := "gamestate:" + gs.ID.String()
+
+	tests := []struct {
+		name    string
+		paused  bool
+		wantTTL time.Duration
+	}{
+		{"active game expires", false, gameStateTTL},
+		{"paused game kept", true, 0},
+		{"resumed game expires again", false, gameStateTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs.Paused = tt.paused
+			if err := rs.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save gamestate: %v", err)
+			}
+			if ttl := mr.TTL(key); ttl != tt.wantTTL {
+				t.Errorf("Expected TTL %v, got %v", tt.wantTTL, ttl)
+			}
+		})
+	}
+}
+
+func TestRedisStorage_ListGameStateIDs(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	base := NewRedisStorage(mr.Addr(), "", logger)
+	kids := base.WithKeyPrefix("kids")
+	ctx := context.Background()
+
+	baseGame := state.NewGameState("test.json", nil, "test-model")
+	kidsGame := state.NewGameState("test.json", nil, "test-model")
+	if err := base.SaveGameState(ctx, baseGame.ID, baseGame); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	if err := kids.SaveGameState(ctx, kidsGame.ID, kidsGame); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	// Non-gamestate keys are ignored
+	if err := mr.Set("gamestate:not-a-uuid", "{}"); err != nil {
+		t.Fatalf("Failed to set key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		storage *RedisStorage
+		want    uuid.UUID
+	}{
+		{"unprefixed storage lists its own games", base, baseGame.ID},
+		{"prefixed storage lists its own games", kids, kidsGame.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := tt.storage.ListGameStateIDs(ctx)
+			if err != nil {
+				t.Fatalf("Failed to list gamestates: %v", err)
+			}
+			if len(ids) != 1 || ids[0] != tt.want {
+				t.Errorf("Expected [%v], got %v", tt.want, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ContentManifestFormat is the version of the manifest layout written by BuildContentManifest
+const ContentManifestFormat = 1
+
+// contentDirs are the data directories whose files a content manifest covers
+var contentDirs = []string{"scenarios", "narrators", "pcs", "monsters", "npcs"}
+
+// ErrContentIntegrity is wrapped by errors for content files that are missing from
+// the manifest or don't match it
+var ErrContentIntegrity = errors.New("content integrity check failed")
+
+// ContentManifest lists the SHA-256 checksum of every content file in a data directory,
+// so files that were tampered with or corrupted can be refused when they are loaded.
+// It can be signed with an Ed25519 key, so the manifest can't be changed to match.
+type ContentManifest struct {
+	Format    int               `json:"format"`              // ContentManifestFormat when written
+	Files     map[string]string `json:"files"`               // slash-separated path under the data directory -> hex SHA-256
+	Signature string            `json:"signature,omitempty"` // base64 Ed25519 signature of the format and files
+}
+
+// BuildContentManifest checksums every JSON file in the data directory's content directories
+func BuildContentManifest(dataDir string) (*ContentManifest, error) {
+	m := &ContentManifest{Format: ContentManifestFormat, Files: make(map[string]string)}
+	for _, dir := range contentDirs {
+		err := filepath.WalkDir(filepath.Join(dataDir, dir), func(path string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dataDir, path)
+			if err != nil {
+				return err
+			}
+			m.Files[filepath.ToSlash(rel)] = checksum(data)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum %s: %w", dir, err)
+		}
+	}
+	return m, nil
+}
+
+// LoadContentManifest reads a manifest, and checks its signature when publicKey is set
+func LoadContentManifest(path string, publicKey ed25519.PublicKey) (*ContentManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content manifest: %w", err)
+	}
+	var m ContentManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse content manifest: %w", err)
+	}
+	if m.Format < 1 || m.Format > ContentManifestFormat {
+		return nil, fmt.Errorf("unsupported content manifest format %d (supported: 1 to %d)", m.Format, ContentManifestFormat)
+	}
+	if publicKey != nil {
+		if err := m.VerifySignature(publicKey); err != nil {
+			return nil, err
+		}
+	}
+	return &m, nil
+}
+
+// Sign signs the manifest's format and files with key
+func (m *ContentManifest) Sign(key ed25519.PrivateKey) error {
+	payload, err := m.signedPayload()
+	if err != nil {
+		return err
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return nil
+}
+
+// VerifySignature checks that the manifest was signed with the private key for publicKey
+func (m *ContentManifest) VerifySignature(publicKey ed25519.PublicKey) error {
+	if m.Signature == "" {
+		return fmt.Errorf("content manifest is not signed")
+	}
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("content manifest signature is not valid base64: %w", err)
+	}
+	payload, err := m.signedPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, payload, sig) {
+		return fmt.Errorf("content manifest signature does not match its public key")
+	}
+	return nil
+}
+
+// signedPayload is what the signature covers. Map keys are marshaled in sorted order,
+// so the payload is the same wherever it is built.
+func (m *ContentManifest) signedPayload() ([]byte, error) {
+	payload, err := json.Marshal(struct {
+		Format int               `json:"format"`
+		Files  map[string]string `json:"files"`
+	}{m.Format, m.Files})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal content manifest: %w", err)
+	}
+	return payload, nil
+}
+
+// Check verifies a content file's data against the manifest. path is slash-separated
+// and relative to the data directory, e.g. "scenarios/pirate.json".
+func (m *ContentManifest) Check(path string, data []byte) error {
+	want, ok := m.Files[path]
+	if !ok {
+		return fmt.Errorf("%w: %s is not in the content manifest", ErrContentIntegrity, path)
+	}
+	if checksum(data) != want {
+		return fmt.Errorf("%w: %s does not match its checksum in the content manifest", ErrContentIntegrity, path)
+	}
+	return nil
+}
+
+// CheckDir verifies every content file in the data directory and every file the
+// manifest lists, returning a problem for each file that fails, sorted by path
+func (m *ContentManifest) CheckDir(dataDir string) []error {
+	current, err := BuildContentManifest(dataDir)
+	if err != nil {
+		return []error{err}
+	}
+
+	paths := make(map[string]bool, len(m.Files))
+	for path := range m.Files {
+		paths[path] = true
+	}
+	for path := range current.Files {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var problems []error
+	for _, path := range sorted {
+		got, found := current.Files[path]
+		want, listed := m.Files[path]
+		switch {
+		case !found:
+			problems = append(problems, fmt.Errorf("%w: %s is in the content manifest but missing", ErrContentIntegrity, path))
+		case !listed:
+			problems = append(problems, fmt.Errorf("%w: %s is not in the content manifest", ErrContentIntegrity, path))
+		case got != want:
+			problems = append(problems, fmt.Errorf("%w: %s does not match its checksum in the content manifest", ErrContentIntegrity, path))
+		}
+	}
+	return problems
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
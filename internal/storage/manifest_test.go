@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// newTestDataDir writes a small data directory with one scenario and one narrator
+func newTestDataDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"scenarios/pirate.json": `{"name": "Pirates"}`,
+		"narrators/salty.json":  `{"id": "salty", "name": "Salty"}`,
+	}
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return dir
+}
+
+func writeTestManifest(t *testing.T, m *ContentManifest) string {
+	t.Helper()
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Failed to marshal manifest: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	return path
+}
+
+func TestContentManifest_Signature(t *testing.T) {
+	dir := newTestDataDir(t)
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	otherKey, _, _ := ed25519.GenerateKey(nil)
+
+	m, err := BuildContentManifest(dir)
+	if err != nil {
+		t.Fatalf("Failed to build manifest: %v", err)
+	}
+	if len(m.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %v", m.Files)
+	}
+	if err := m.Sign(privateKey); err != nil {
+		t.Fatalf("Failed to sign manifest: %v", err)
+	}
+
+	forged := *m
+	forged.Files = map[string]string{"scenarios/pirate.json": checksum([]byte(`{"name": "Forged"}`))}
+	unsigned := *m
+	unsigned.Signature = ""
+
+	tests := []struct {
+		name      string
+		manifest  *ContentManifest
+		publicKey ed25519.PublicKey
+		wantErr   bool
+	}{
+		{"signed", m, publicKey, false},
+		{"no key to check", &unsigned, nil, false},
+		{"unsigned", &unsigned, publicKey, true},
+		{"signed by another key", m, otherKey, true},
+		{"files changed after signing", &forged, publicKey, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadContentManifest(writeTestManifest(t, tt.manifest), tt.publicKey)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadContentManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContentManifest_CheckDir(t *testing.T) {
+	dir := newTestDataDir(t)
+	m, err := BuildContentManifest(dir)
+	if err != nil {
+		t.Fatalf("Failed to build manifest: %v", err)
+	}
+	if problems := m.CheckDir(dir); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+
+	// Tamper with one file, remove another, and add an unlisted one
+	if err := os.WriteFile(filepath.Join(dir, "scenarios/pirate.json"), []byte(`{"name": "Tampered"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "narrators/salty.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "pcs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pcs/extra.json"), []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	problems := m.CheckDir(dir)
+	if len(problems) != 3 {
+		t.Fatalf("Expected 3 problems, got %v", problems)
+	}
+	for _, problem := range problems {
+		if !errors.Is(problem, ErrContentIntegrity) {
+			t.Errorf("Expected an integrity error, got %v", problem)
+		}
+	}
+}
+
+func TestFileStore_ContentManifest(t *testing.T) {
+	dir := newTestDataDir(t)
+	m, err := BuildContentManifest(dir)
+	if err != nil {
+		t.Fatalf("Failed to build manifest: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+	store := NewFileStore(dir, logger).WithContentManifest(m)
+	ctx := context.Background()
+
+	if _, err := store.GetScenario(ctx, "pirate.json"); err != nil {
+		t.Errorf("Expected a listed scenario to load, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "scenarios/pirate.json"), []byte(`{"name": "Tampered"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetScenario(ctx, "pirate.json"); !errors.Is(err, ErrContentIntegrity) {
+		t.Errorf("Expected a tampered scenario to be refused, got %v", err)
+	}
+	if scenarios, _ := store.ListScenarios(ctx); len(scenarios) != 0 {
+		t.Errorf("Expected a tampered scenario to be left out of the list, got %v", scenarios)
+	}
+
+	if err := store.SaveNarrator(ctx, &scenario.Narrator{ID: "new"}); !errors.Is(err, storage.ErrContentLocked) {
+		t.Errorf("Expected writes to be refused, got %v", err)
+	}
+	if err := store.DeleteNarrator(ctx, "salty"); !errors.Is(err, storage.ErrContentLocked) {
+		t.Errorf("Expected deletes to be refused, got %v", err)
+	}
+	if _, err := store.GetNarrator(ctx, "salty"); err != nil {
+		t.Errorf("Expected the narrator to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+)
+
+// MemoryStorage implements the Storage interface with game states held in memory
+// and static resources read from the filesystem, like RedisStorage. Game states
+// expire after the same idle TTL, and paused games never expire. It is safe for
+// concurrent use; nothing survives the process.
+type MemoryStorage struct {
+	FileStore
+	games     *memoryGames
+	keyPrefix string // isolates profiles, as for RedisStorage
+}
+
+// memoryGames is the game state table shared by every prefixed view of a MemoryStorage
+type memoryGames struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	snapshots map[string]map[string][]byte // snapshot JSON by game key, then name
+	now       func() time.Time
+}
+
+// memoryEntry is a saved game state. States are kept as JSON, so callers can't
+// change a saved state through a pointer they still hold.
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time // zero for no expiry
+}
+
+// Ensure MemoryStorage implements Storage interface
+var _ storage.Storage = (*MemoryStorage)(nil)
+var _ storage.Snapshots = (*MemoryStorage)(nil)
+
+// NewMemoryStorage creates an empty in-memory storage reading resources from dataDir
+func NewMemoryStorage(dataDir string, logger *slog.Logger) *MemoryStorage {
+	if dataDir == "" {
+		dataDir = "./data"
+	}
+
+	return &MemoryStorage{
+		FileStore: FileStore{dataDir: dataDir, logger: logger, hidden: newHiddenScenarios()},
+		games: &memoryGames{
+			entries:   make(map[string]memoryEntry),
+			snapshots: make(map[string]map[string][]byte),
+			now:       time.Now,
+		},
+	}
+}
+
+// WithKeyPrefix returns a storage sharing these game states whose keys are prefixed,
+// so profiles can't see each other's games
+func (m *MemoryStorage) WithKeyPrefix(prefix string) *MemoryStorage {
+	prefixed := *m
+	prefixed.keyPrefix = prefix
+	return &prefixed
+}
+
+// WithContentManifest verifies content files against a checksum manifest; see
+// FileStore.WithContentManifest
+func (m *MemoryStorage) WithContentManifest(manifest *ContentManifest) *MemoryStorage {
+	m.FileStore.WithContentManifest(manifest)
+	return m
+}
+
+// Health and lifecycle methods
+
+func (m *MemoryStorage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryStorage) Close() error {
+	return nil
+}
+
+// GameState operations (memory-backed)
+
+func (m *MemoryStorage) gameStateKey(id uuid.UUID) string {
+	return m.keyPrefix + ":" + id.String()
+}
+
+func (m *MemoryStorage) SaveGameState(ctx context.Context, id uuid.UUID, gs *state.GameState) error {
+	gs.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(gs)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "Failed to marshal gamestate", "uuid", id, "error", err)
+		return fmt.Errorf("failed to marshal gamestate: %w", err)
+	}
+
+	m.games.mu.Lock()
+	defer m.games.mu.Unlock()
+
+	// Paused games don't expire, so a long pause can't lose the game
+	entry := memoryEntry{data: data}
+	if !gs.Paused {
+		entry.expiresAt = m.games.now().Add(gameStateTTL)
+	}
+	m.games.entries[m.gameStateKey(id)] = entry
+	return nil
+}
+
+func (m *MemoryStorage) LoadGameState(ctx context.Context, id uuid.UUID) (*state.GameState, error) {
+	m.games.mu.Lock()
+	entry, ok := m.games.live(m.gameStateKey(id))
+	m.games.mu.Unlock()
+	if !ok {
+		m.logger.WarnContext(ctx, "Gamestate not found", "uuid", id)
+		return nil, nil // Return nil for not found
+	}
+
+	var gs state.GameState
+	if err := json.Unmarshal(entry.data, &gs); err != nil {
+		m.logger.ErrorContext(ctx, "Failed to unmarshal gamestate", "uuid", id, "error", err)
+		return nil, fmt.Errorf("failed to unmarshal gamestate: %w", err)
+	}
+
+	return &gs, nil
+}
+
+func (m *MemoryStorage) DeleteGameState(ctx context.Context, id uuid.UUID) error {
+	m.games.mu.Lock()
+	defer m.games.mu.Unlock()
+	delete(m.games.entries, m.gameStateKey(id))
+	delete(m.games.snapshots, m.gameStateKey(id))
+	return nil
+}
+
+// ListGameStateIDs returns the IDs of all unexpired game states in this storage's prefix
+func (m *MemoryStorage) ListGameStateIDs(ctx context.Context) ([]uuid.UUID, error) {
+	m.games.mu.Lock()
+	defer m.games.mu.Unlock()
+
+	prefix := m.keyPrefix + ":"
+	var ids []uuid.UUID
+	for key := range m.games.entries {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		id, err := uuid.Parse(rest)
+		if err != nil {
+			continue
+		}
+		if _, ok := m.games.live(key); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Snapshot operations (memory-backed). Snapshots are dropped when their game expires.
+
+func (m *MemoryStorage) SaveSnapshot(ctx context.Context, id uuid.UUID, snap *state.Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "Failed to marshal snapshot", "uuid", id, "snapshot", snap.Name, "error", err)
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	m.games.mu.Lock()
+	defer m.games.mu.Unlock()
+	key := m.gameStateKey(id)
+	if m.games.snapshots[key] == nil {
+		m.games.snapshots[key] = make(map[string][]byte)
+	}
+	m.games.snapshots[key][snap.Name] = data
+	return nil
+}
+
+func (m *MemoryStorage) ListSnapshots(ctx context.Context, id uuid.UUID) ([]state.Snapshot, error) {
+	m.games.mu.Lock()
+	defer m.games.mu.Unlock()
+
+	key := m.gameStateKey(id)
+	if _, ok := m.games.live(key); !ok {
+		return []state.Snapshot{}, nil
+	}
+	snapshots := make([]state.Snapshot, 0, len(m.games.snapshots[key]))
+	for name, data := range m.games.snapshots[key] {
+		var snap state.Snapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to unmarshal snapshot", "uuid", id, "snapshot", name, "error", err)
+			return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snap.Summary())
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+func (m *MemoryStorage) LoadSnapshot(ctx context.Context, id uuid.UUID, name string) (*state.Snapshot, error) {
+	m.games.mu.Lock()
+	key := m.gameStateKey(id)
+	_, ok := m.games.live(key)
+	data, found := m.games.snapshots[key][name]
+	m.games.mu.Unlock()
+	if !ok || !found {
+		return nil, nil
+	}
+
+	var snap state.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		m.logger.ErrorContext(ctx, "Failed to unmarshal snapshot", "uuid", id, "snapshot", name, "error", err)
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	return &snap, nil
+}
+
+// live returns the entry for key unless it has expired, dropping it if it has.
+// The caller holds mu.
+func (g *memoryGames) live(key string) (memoryEntry, bool) {
+	entry, ok := g.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if !entry.expiresAt.IsZero() && !g.now().Before(entry.expiresAt) {
+		delete(g.entries, key)
+		delete(g.snapshots, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
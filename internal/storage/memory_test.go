@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func newTestMemoryStorage(t *testing.T) (*MemoryStorage, *time.Time) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	m := NewMemoryStorage("../../data", logger)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.games.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestMemoryStorage_SaveAndLoadGameState(t *testing.T) {
+	m, _ := newTestMemoryStorage(t)
+	ctx := context.Background()
+
+	gs := state.NewGameState("test_scenario.json", nil, "test_model")
+	gs.Location = "tavern"
+	gs.Inventory = []string{"sword"}
+	if err := m.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+
+	// Changing the saved value afterwards must not change what was stored
+	gs.Inventory = append(gs.Inventory, "shield")
+
+	loaded, err := m.LoadGameState(ctx, gs.ID)
+	if err != nil {
+		t.Fatalf("Failed to load gamestate: %v", err)
+	}
+	if loaded == nil {
+		t.Fatal("Expected non-nil gamestate")
+	}
+	if loaded.Location != "tavern" {
+		t.Errorf("Expected location 'tavern', got %v", loaded.Location)
+	}
+	if len(loaded.Inventory) != 1 {
+		t.Errorf("Expected 1 inventory item, got %v", loaded.Inventory)
+	}
+
+	if err := m.DeleteGameState(ctx, gs.ID); err != nil {
+		t.Fatalf("Failed to delete gamestate: %v", err)
+	}
+	loaded, err = m.LoadGameState(ctx, gs.ID)
+	if err != nil || loaded != nil {
+		t.Errorf("Expected deleted gamestate to be gone, got %v, %v", loaded, err)
+	}
+}
+
+func TestMemoryStorage_TTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		paused   bool
+		elapsed  time.Duration
+		wantKept bool
+	}{
+		{name: "kept within TTL", elapsed: gameStateTTL - time.Second, wantKept: true},
+		{name: "expired after TTL", elapsed: gameStateTTL, wantKept: false},
+		{name: "paused games never expire", paused: true, elapsed: 48 * time.Hour, wantKept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, now := newTestMemoryStorage(t)
+			ctx := context.Background()
+
+			gs := state.NewGameState("test_scenario.json", nil, "test_model")
+			gs.Paused = tt.paused
+			if err := m.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Fatalf("Failed to save gamestate: %v", err)
+			}
+			*now = now.Add(tt.elapsed)
+
+			loaded, err := m.LoadGameState(ctx, gs.ID)
+			if err != nil {
+				t.Fatalf("Failed to load gamestate: %v", err)
+			}
+			if (loaded != nil) != tt.wantKept {
+				t.Errorf("Expected kept=%v, got %v", tt.wantKept, loaded != nil)
+			}
+			ids, _ := m.ListGameStateIDs(ctx)
+			if (len(ids) == 1) != tt.wantKept {
+				t.Errorf("Expected listed=%v, got %v", tt.wantKept, ids)
+			}
+		})
+	}
+}
+
+func TestMemoryStorage_SaveRefreshesTTL(t *testing.T) {
+	m, now := newTestMemoryStorage(t)
+	ctx := context.Background()
+
+	gs := state.NewGameState("test_scenario.json", nil, "test_model")
+	if err := m.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	*now = now.Add(gameStateTTL - time.Minute)
+	if err := m.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	*now = now.Add(gameStateTTL - time.Minute)
+
+	if loaded, _ := m.LoadGameState(ctx, gs.ID); loaded == nil {
+		t.Error("Expected a saved game to be kept for a full TTL after its last save")
+	}
+}
+
+func TestMemoryStorage_KeyPrefix(t *testing.T) {
+	base, _ := newTestMemoryStorage(t)
+	ctx := context.Background()
+	a := base.WithKeyPrefix("tenant-a")
+	b := base.WithKeyPrefix("tenant-b")
+
+	gs := state.NewGameState("test_scenario.json", nil, "test_model")
+	if err := a.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+
+	if loaded, _ := b.LoadGameState(ctx, gs.ID); loaded != nil {
+		t.Error("Expected another prefix not to see the game")
+	}
+	if loaded, _ := base.LoadGameState(ctx, gs.ID); loaded != nil {
+		t.Error("Expected the unprefixed storage not to see the game")
+	}
+	ids, err := a.ListGameStateIDs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list gamestates: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != gs.ID {
+		t.Errorf("Expected [%v], got %v", gs.ID, ids)
+	}
+	if ids, _ := b.ListGameStateIDs(ctx); len(ids) != 0 {
+		t.Errorf("Expected no games for another prefix, got %v", ids)
+	}
+}
+
+func TestMemoryStorage_ConcurrentSaves(t *testing.T) {
+	m, _ := newTestMemoryStorage(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gs := state.NewGameState("test_scenario.json", nil, "test_model")
+			if err := m.SaveGameState(ctx, gs.ID, gs); err != nil {
+				t.Errorf("Failed to save gamestate: %v", err)
+			}
+			if _, err := m.LoadGameState(ctx, gs.ID); err != nil {
+				t.Errorf("Failed to load gamestate: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ids, _ := m.ListGameStateIDs(ctx)
+	if len(ids) != 50 {
+		t.Errorf("Expected 50 games, got %d", len(ids))
+	}
+}
+
+func TestMemoryStorage_Resources(t *testing.T) {
+	m, _ := newTestMemoryStorage(t)
+	ctx := context.Background()
+
+	scenarios, err := m.ListScenarios(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list scenarios: %v", err)
+	}
+	if len(scenarios) == 0 {
+		t.Error("Expected scenarios from the data directory")
+	}
+	if _, err := m.GetScenario(ctx, "pirate.json"); err != nil {
+		t.Errorf("Failed to load scenario: %v", err)
+	}
+	if err := m.Ping(ctx); err != nil {
+		t.Errorf("Expected ping to succeed, got %v", err)
+	}
+}
@@ -12,14 +12,14 @@ import (
 	"github.com/jwebster45206/story-engine/pkg/actor"
 )
 
-func (r *RedisStorage) GetMonster(ctx context.Context, templateID string) (*actor.Monster, error) {
+func (r *FileStore) GetMonster(ctx context.Context, templateID string) (*actor.Monster, error) {
 	path := filepath.Join(r.dataDir, "monsters", templateID+".json")
-	r.logger.Debug("Loading monster template", "templateID", templateID, "full_path", path)
+	r.logger.DebugContext(ctx, "Loading monster template", "templateID", templateID, "full_path", path)
 
-	file, err := os.ReadFile(path)
+	file, err := r.readDataFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			r.logger.Error("Monster template file not found", "path", path, "error", err)
+			r.logger.ErrorContext(ctx, "Monster template file not found", "path", path, "error", err)
 			return nil, fmt.Errorf("monster template not found: %s", templateID)
 		}
 		return nil, fmt.Errorf("failed to read monster template file: %w", err)
@@ -33,12 +33,12 @@ func (r *RedisStorage) GetMonster(ctx context.Context, templateID string) (*acto
 	return &m, nil
 }
 
-func (r *RedisStorage) ListMonsters(ctx context.Context) (map[string]string, error) {
+func (r *FileStore) ListMonsters(ctx context.Context) (map[string]string, error) {
 	monstersDir := filepath.Join(r.dataDir, "monsters")
 	monsters := make(map[string]string)
 
 	if _, err := os.Stat(monstersDir); os.IsNotExist(err) {
-		r.logger.Debug("Monsters directory does not exist", "path", monstersDir)
+		r.logger.DebugContext(ctx, "Monsters directory does not exist", "path", monstersDir)
 		return monsters, nil // Return empty map if directory doesn't exist
 	}
 
@@ -47,15 +47,15 @@ func (r *RedisStorage) ListMonsters(ctx context.Context) (map[string]string, err
 			return nil
 		}
 
-		file, err := os.ReadFile(path)
+		file, err := r.readDataFile(path)
 		if err != nil {
-			r.logger.Warn("Failed to read monster file", "path", path, "error", err)
+			r.logger.WarnContext(ctx, "Failed to read monster file", "path", path, "error", err)
 			return nil
 		}
 
 		var m actor.Monster
 		if err := json.Unmarshal(file, &m); err != nil {
-			r.logger.Warn("Failed to unmarshal monster file", "path", path, "error", err)
+			r.logger.WarnContext(ctx, "Failed to unmarshal monster file", "path", path, "error", err)
 			return nil
 		}
 
@@ -65,7 +65,7 @@ func (r *RedisStorage) ListMonsters(ctx context.Context) (map[string]string, err
 	})
 
 	if err != nil {
-		r.logger.Error("Failed to walk monsters directory", "error", err)
+		r.logger.ErrorContext(ctx, "Failed to walk monsters directory", "error", err)
 		return nil, fmt.Errorf("failed to list monsters: %w", err)
 	}
 
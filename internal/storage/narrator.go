@@ -12,14 +12,14 @@ import (
 
 // Narrator operations (filesystem-backed)
 
-func (r *RedisStorage) GetNarrator(ctx context.Context, narratorID string) (*scenario.Narrator, error) {
+func (r *FileStore) GetNarrator(ctx context.Context, narratorID string) (*scenario.Narrator, error) {
 	if narratorID == "" {
 		return nil, nil // No narrator specified
 	}
 
 	narratorPath := filepath.Join(r.dataDir, "narrators", narratorID+".json")
 
-	data, err := os.ReadFile(narratorPath)
+	data, err := r.readDataFile(narratorPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			absPath, _ := filepath.Abs(narratorPath)
@@ -38,7 +38,7 @@ func (r *RedisStorage) GetNarrator(ctx context.Context, narratorID string) (*sce
 	return &narrator, nil
 }
 
-func (r *RedisStorage) ListNarrators(ctx context.Context) ([]string, error) {
+func (r *FileStore) ListNarrators(ctx context.Context) ([]string, error) {
 	narratorsPath := filepath.Join(r.dataDir, "narrators")
 
 	entries, err := os.ReadDir(narratorsPath)
@@ -59,3 +59,13 @@ func (r *RedisStorage) ListNarrators(ctx context.Context) ([]string, error) {
 
 	return narratorIDs, nil
 }
+
+// SaveNarrator writes a narrator to its file, creating or replacing it
+func (r *FileStore) SaveNarrator(ctx context.Context, n *scenario.Narrator) error {
+	return r.writeDataFile("narrators", n.ID, n)
+}
+
+// DeleteNarrator removes a narrator's file
+func (r *FileStore) DeleteNarrator(ctx context.Context, narratorID string) error {
+	return r.removeDataFile("narrators", narratorID)
+}
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"context"
+	"log/slog"
+	"os"
+	"slices"
 	"testing"
 
 	"github.com/jwebster45206/story-engine/pkg/scenario"
@@ -133,3 +136,35 @@ func TestMockStorage_ListNarratorsEmpty(t *testing.T) {
 		t.Errorf("Expected 0 narrators, got %d", len(narrators))
 	}
 }
+
+func TestRedisStorage_SaveAndDeleteNarrator(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	rs := NewRedisStorage("localhost:0", t.TempDir(), logger)
+	ctx := context.Background()
+
+	n := &scenario.Narrator{ID: "gothic", Name: "Gothic Narrator", Tone: "brooding", Ratings: []string{"PG-13", "R"}, Prompts: []string{"Dwell on shadows."}}
+	if err := rs.SaveNarrator(ctx, n); err != nil {
+		t.Fatalf("Failed to save narrator: %v", err)
+	}
+
+	loaded, err := rs.GetNarrator(ctx, "gothic")
+	if err != nil {
+		t.Fatalf("Failed to get narrator: %v", err)
+	}
+	if loaded.Name != n.Name || loaded.Tone != n.Tone || !slices.Equal(loaded.Ratings, n.Ratings) {
+		t.Errorf("Expected %+v, got %+v", n, loaded)
+	}
+	if ids, _ := rs.ListNarrators(ctx); !slices.Equal(ids, []string{"gothic"}) {
+		t.Errorf("Expected [gothic], got %v", ids)
+	}
+
+	if err := rs.DeleteNarrator(ctx, "gothic"); err != nil {
+		t.Fatalf("Failed to delete narrator: %v", err)
+	}
+	if ids, _ := rs.ListNarrators(ctx); len(ids) != 0 {
+		t.Errorf("Expected no narrators after delete, got %v", ids)
+	}
+	if err := rs.DeleteNarrator(ctx, "gothic"); err != nil {
+		t.Errorf("Expected deleting a missing narrator to succeed, got %v", err)
+	}
+}
@@ -12,14 +12,14 @@ import (
 	"github.com/jwebster45206/story-engine/pkg/actor"
 )
 
-func (r *RedisStorage) GetNPC(ctx context.Context, templateID string) (*actor.NPC, error) {
+func (r *FileStore) GetNPC(ctx context.Context, templateID string) (*actor.NPC, error) {
 	path := filepath.Join(r.dataDir, "npcs", templateID+".json")
-	r.logger.Debug("Loading NPC template", "templateID", templateID, "full_path", path)
+	r.logger.DebugContext(ctx, "Loading NPC template", "templateID", templateID, "full_path", path)
 
-	file, err := os.ReadFile(path)
+	file, err := r.readDataFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			r.logger.Error("NPC template file not found", "path", path, "error", err)
+			r.logger.ErrorContext(ctx, "NPC template file not found", "path", path, "error", err)
 			return nil, fmt.Errorf("npc template not found: %s", templateID)
 		}
 		return nil, fmt.Errorf("failed to read npc template file: %w", err)
@@ -36,12 +36,12 @@ func (r *RedisStorage) GetNPC(ctx context.Context, templateID string) (*actor.NP
 	return &n, nil
 }
 
-func (r *RedisStorage) ListNPCs(ctx context.Context) (map[string]string, error) {
+func (r *FileStore) ListNPCs(ctx context.Context) (map[string]string, error) {
 	npcsDir := filepath.Join(r.dataDir, "npcs")
 	npcs := make(map[string]string)
 
 	if _, err := os.Stat(npcsDir); os.IsNotExist(err) {
-		r.logger.Debug("NPCs directory does not exist", "path", npcsDir)
+		r.logger.DebugContext(ctx, "NPCs directory does not exist", "path", npcsDir)
 		return npcs, nil // Return empty map if directory doesn't exist
 	}
 
@@ -50,15 +50,15 @@ func (r *RedisStorage) ListNPCs(ctx context.Context) (map[string]string, error)
 			return nil
 		}
 
-		file, err := os.ReadFile(path)
+		file, err := r.readDataFile(path)
 		if err != nil {
-			r.logger.Warn("Failed to read npc file", "path", path, "error", err)
+			r.logger.WarnContext(ctx, "Failed to read npc file", "path", path, "error", err)
 			return nil
 		}
 
 		var n actor.NPC
 		if err := json.Unmarshal(file, &n); err != nil {
-			r.logger.Warn("Failed to unmarshal npc file", "path", path, "error", err)
+			r.logger.WarnContext(ctx, "Failed to unmarshal npc file", "path", path, "error", err)
 			return nil
 		}
 
@@ -68,7 +68,7 @@ func (r *RedisStorage) ListNPCs(ctx context.Context) (map[string]string, error)
 	})
 
 	if err != nil {
-		r.logger.Error("Failed to walk npcs directory", "error", err)
+		r.logger.ErrorContext(ctx, "Failed to walk npcs directory", "error", err)
 		return nil, fmt.Errorf("failed to list npcs: %w", err)
 	}
 
@@ -12,11 +12,11 @@ import (
 
 // PC operations (filesystem-backed, returns PCSpec only)
 
-func (r *RedisStorage) GetPCSpec(ctx context.Context, pcID string) (*actor.PCSpec, error) {
+func (r *FileStore) GetPCSpec(ctx context.Context, pcID string) (*actor.PCSpec, error) {
 	// Construct the full path internally
 	path := filepath.Join(r.dataDir, "pcs", pcID+".json")
 
-	data, err := os.ReadFile(path)
+	data, err := r.readDataFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read PC file: %w", err)
 	}
@@ -32,7 +32,7 @@ func (r *RedisStorage) GetPCSpec(ctx context.Context, pcID string) (*actor.PCSpe
 	return &spec, nil
 }
 
-func (r *RedisStorage) ListPCs(ctx context.Context) ([]string, error) {
+func (r *FileStore) ListPCs(ctx context.Context) ([]string, error) {
 	pcsPath := filepath.Join(r.dataDir, "pcs")
 
 	entries, err := os.ReadDir(pcsPath)
@@ -53,3 +53,13 @@ func (r *RedisStorage) ListPCs(ctx context.Context) ([]string, error) {
 
 	return pcIDs, nil
 }
+
+// SavePCSpec writes a PC spec to its file, creating or replacing it
+func (r *FileStore) SavePCSpec(ctx context.Context, spec *actor.PCSpec) error {
+	return r.writeDataFile("pcs", spec.ID, spec)
+}
+
+// DeletePCSpec removes a PC spec's file
+func (r *FileStore) DeletePCSpec(ctx context.Context, pcID string) error {
+	return r.removeDataFile("pcs", pcID)
+}
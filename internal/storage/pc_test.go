@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"log/slog"
+	"os"
 	"testing"
 
 	"github.com/jwebster45206/story-engine/pkg/actor"
@@ -185,3 +187,29 @@ func TestMockStorage_PCIDHandling(t *testing.T) {
 		}
 	}
 }
+
+func TestRedisStorage_SaveAndDeletePCSpec(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	rs := NewRedisStorage("localhost:0", t.TempDir(), logger)
+	ctx := context.Background()
+
+	spec := &actor.PCSpec{ID: "scout", Name: "Scout", Class: "Ranger", Level: 2, Inventory: []string{"bow"}}
+	if err := rs.SavePCSpec(ctx, spec); err != nil {
+		t.Fatalf("Failed to save PC spec: %v", err)
+	}
+
+	loaded, err := rs.GetPCSpec(ctx, "scout")
+	if err != nil {
+		t.Fatalf("Failed to get PC spec: %v", err)
+	}
+	if loaded.Name != "Scout" || loaded.Level != 2 || len(loaded.Inventory) != 1 {
+		t.Errorf("Expected %+v, got %+v", spec, loaded)
+	}
+
+	if err := rs.DeletePCSpec(ctx, "scout"); err != nil {
+		t.Fatalf("Failed to delete PC spec: %v", err)
+	}
+	if _, err := rs.GetPCSpec(ctx, "scout"); err == nil {
+		t.Error("Expected an error loading a deleted PC spec")
+	}
+}
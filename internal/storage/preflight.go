@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario/validate"
+)
+
+// PreflightScenarios validates every scenario file with the rules cmd/validate uses,
+// returning each file's findings by filename. Files are read as GetScenario reads
+// them, so a file refused by the content manifest is reported as an error.
+func (r *FileStore) PreflightScenarios(ctx context.Context) (map[string]*validate.Result, error) {
+	results := make(map[string]*validate.Result)
+	err := filepath.WalkDir(filepath.Join(r.dataDir, "scenarios"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		filename := filepath.Base(path)
+		data, err := r.readDataFile(path)
+		if err != nil {
+			results[filename] = &validate.Result{Errors: []validate.Finding{{
+				File:     filename,
+				Rule:     validate.RuleRead,
+				Severity: validate.SeverityError,
+				Message:  fmt.Sprintf("failed to read scenario file: %v", err),
+			}}}
+			return nil
+		}
+		results[filename] = validate.JSON(filename, data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to preflight scenarios: %w", err)
+	}
+	return results, nil
+}
+
+// hiddenScenarios is the set of scenario filenames left out of ListScenarios
+type hiddenScenarios struct {
+	mu        sync.RWMutex
+	filenames map[string]bool
+}
+
+func newHiddenScenarios() *hiddenScenarios {
+	return &hiddenScenarios{filenames: make(map[string]bool)}
+}
+
+// HideScenarios leaves scenario files out of ListScenarios, e.g. ones that failed
+// preflight. They can still be loaded by filename, and saving one lists it again.
+func (r *FileStore) HideScenarios(filenames ...string) {
+	r.hidden.mu.Lock()
+	defer r.hidden.mu.Unlock()
+	for _, filename := range filenames {
+		r.hidden.filenames[filename] = true
+	}
+}
+
+// HiddenScenarios returns the filenames left out of ListScenarios
+func (r *FileStore) HiddenScenarios() []string {
+	r.hidden.mu.RLock()
+	defer r.hidden.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.hidden.filenames))
+}
+
+func (r *FileStore) isHidden(filename string) bool {
+	r.hidden.mu.RLock()
+	defer r.hidden.mu.RUnlock()
+	return r.hidden.filenames[filename]
+}
+
+func (r *FileStore) unhide(filename string) {
+	r.hidden.mu.Lock()
+	defer r.hidden.mu.Unlock()
+	delete(r.hidden.filenames, filename)
+}
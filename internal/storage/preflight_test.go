@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestFileStore_PreflightScenarios(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+	files := map[string]string{
+		"tiny_tale.json": `{"name": "Tiny Tale", "rating": "G", "opening_location": "hall", "locations": {"hall": {"name": "Hall"}}}`,
+		"lost_way.json":  `{"name": "Lost Way", "rating": "G", "opening_location": "hall", "locations": {"hall": {"name": "Hall", "exits": {"north": "garden"}}}}`,
+		"typo.json":      `{"name": "Typo", "stroy": "A misspelled story."}`,
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "scenarios"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, "scenarios", name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := NewMemoryStorage(dir, logger)
+	ctx := context.Background()
+
+	results, err := store.PreflightScenarios(ctx)
+	if err != nil {
+		t.Fatalf("PreflightScenarios: %v", err)
+	}
+	if len(results) != len(files) {
+		t.Fatalf("Expected %d results, got %d", len(files), len(results))
+	}
+	for name, wantValid := range map[string]bool{"tiny_tale.json": true, "lost_way.json": false, "typo.json": false} {
+		if got := results[name].Valid(); got != wantValid {
+			t.Errorf("%s: Valid() = %v, want %v (errors %v)", name, got, wantValid, results[name].Errors)
+		}
+	}
+
+	// Hidden scenarios are left out of every profile's listing until saved again
+	profile := store.WithKeyPrefix("profile:")
+	store.HideScenarios("lost_way.json", "typo.json")
+	listed, err := profile.ListScenarios(ctx)
+	if err != nil {
+		t.Fatalf("ListScenarios: %v", err)
+	}
+	if len(listed) != 1 || listed["Tiny Tale"] != "tiny_tale.json" {
+		t.Errorf("Expected only tiny_tale.json to be listed, got %v", listed)
+	}
+	if _, err := profile.GetScenario(ctx, "lost_way.json"); err != nil {
+		t.Errorf("Expected a hidden scenario to still load, got %v", err)
+	}
+
+	fixed := &scenario.Scenario{Name: "Lost Way", Rating: scenario.RatingG, OpeningLocation: "hall"}
+	if err := store.SaveScenario(ctx, "lost_way.json", fixed); err != nil {
+		t.Fatalf("SaveScenario: %v", err)
+	}
+	if got := profile.HiddenScenarios(); !slices.Equal(got, []string{"typo.json"}) {
+		t.Errorf("Expected only typo.json to stay hidden, got %v", got)
+	}
+}
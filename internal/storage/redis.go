@@ -13,13 +13,19 @@ import (
 // RedisStorage implements the Storage interface using Redis for gamestate
 // and filesystem for static resources (scenarios, narrators, PCs)
 type RedisStorage struct {
-	client  *redis.Client
-	logger  *slog.Logger
-	dataDir string
+	FileStore
+	client    *redis.Client
+	keyPrefix string // prepended to game state keys to isolate profiles
+
+	redisScenarios bool // keep scenarios saved through the API in Redis, not the data directory
+
+	eventSourcing bool       // record every game state save in an append-only event log
+	encryptor     *Encryptor // optional; encrypts game states and event logs at rest
 }
 
 // Ensure RedisStorage implements Storage interface
 var _ storage.Storage = (*RedisStorage)(nil)
+var _ storage.EventLog = (*RedisStorage)(nil)
 
 // NewRedisStorage creates a new Redis storage instance
 func NewRedisStorage(redisURL string, dataDir string, logger *slog.Logger) *RedisStorage {
@@ -32,12 +38,48 @@ func NewRedisStorage(redisURL string, dataDir string, logger *slog.Logger) *Redi
 	}
 
 	return &RedisStorage{
-		client:  rdb,
-		logger:  logger,
-		dataDir: dataDir,
+		FileStore: FileStore{dataDir: dataDir, logger: logger, hidden: newHiddenScenarios()},
+		client:    rdb,
 	}
 }
 
+// WithKeyPrefix returns a storage sharing this connection whose game state keys
+// are prefixed, so profiles sharing a Redis instance can't see each other's games
+func (r *RedisStorage) WithKeyPrefix(prefix string) *RedisStorage {
+	prefixed := *r
+	prefixed.keyPrefix = prefix
+	return &prefixed
+}
+
+// WithEventSourcing records every game state save in an append-only event log next
+// to the saved state, from which any earlier state can be rebuilt. See state.GameEvent.
+func (r *RedisStorage) WithEventSourcing(enabled bool) *RedisStorage {
+	r.eventSourcing = enabled
+	return r
+}
+
+// WithEncryption encrypts game states and their event logs before they are written.
+// Games saved before encryption was turned on can still be read.
+func (r *RedisStorage) WithEncryption(encryptor *Encryptor) *RedisStorage {
+	r.encryptor = encryptor
+	return r
+}
+
+// WithScenariosInRedis keeps scenarios saved through the API in Redis, so every API
+// instance and worker sharing it sees them. Scenario files in the data directory are
+// still served, unless a scenario saved in Redis has the same filename.
+func (r *RedisStorage) WithScenariosInRedis(enabled bool) *RedisStorage {
+	r.redisScenarios = enabled
+	return r
+}
+
+// WithContentManifest verifies content files against a checksum manifest; see
+// FileStore.WithContentManifest
+func (r *RedisStorage) WithContentManifest(manifest *ContentManifest) *RedisStorage {
+	r.FileStore.WithContentManifest(manifest)
+	return r
+}
+
 // Health and lifecycle methods
 
 func (r *RedisStorage) Ping(ctx context.Context) error {
@@ -64,7 +106,7 @@ func (r *RedisStorage) WaitForConnection(ctx context.Context) error {
 
 	for i := 0; i < maxRetries; i++ {
 		if err := r.Ping(ctx); err != nil {
-			r.logger.Debug("Redis not ready yet", "error", err, "attempt", i+1)
+			r.logger.DebugContext(ctx, "Redis not ready yet", "error", err, "attempt", i+1)
 
 			select {
 			case <-ctx.Done():
@@ -74,7 +116,7 @@ func (r *RedisStorage) WaitForConnection(ctx context.Context) error {
 			}
 		}
 
-		r.logger.Info("Redis connection established")
+		r.logger.InfoContext(ctx, "Redis connection established")
 		return nil
 	}
 
@@ -3,34 +3,39 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+	"github.com/redis/go-redis/v9"
 )
 
 // Scenario operations (filesystem-backed)
 
-func (r *RedisStorage) ListScenarios(ctx context.Context) (map[string]string, error) {
+func (r *FileStore) ListScenarios(ctx context.Context) (map[string]string, error) {
 	scenariosDir := filepath.Join(r.dataDir, "scenarios")
 	scenarios := make(map[string]string)
 
 	err := filepath.WalkDir(scenariosDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" || r.isHidden(filepath.Base(path)) {
 			return nil
 		}
 
-		file, err := os.ReadFile(path)
+		file, err := r.readDataFile(path)
 		if err != nil {
-			r.logger.Warn("Failed to read scenario file", "path", path, "error", err)
+			r.logger.WarnContext(ctx, "Failed to read scenario file", "path", path, "error", err)
 			return nil
 		}
 
 		var s scenario.Scenario
 		if err := json.Unmarshal(file, &s); err != nil {
-			r.logger.Warn("Failed to unmarshal scenario file", "path", path, "error", err)
+			r.logger.WarnContext(ctx, "Failed to unmarshal scenario file", "path", path, "error", err)
 			return nil
 		}
 
@@ -40,21 +45,21 @@ func (r *RedisStorage) ListScenarios(ctx context.Context) (map[string]string, er
 	})
 
 	if err != nil {
-		r.logger.Error("Failed to walk scenarios directory", "error", err)
+		r.logger.ErrorContext(ctx, "Failed to walk scenarios directory", "error", err)
 		return nil, fmt.Errorf("failed to list scenarios: %w", err)
 	}
 
 	return scenarios, nil
 }
 
-func (r *RedisStorage) GetScenario(ctx context.Context, filename string) (*scenario.Scenario, error) {
+func (r *FileStore) GetScenario(ctx context.Context, filename string) (*scenario.Scenario, error) {
 	path := filepath.Join(r.dataDir, "scenarios", filename)
-	r.logger.Debug("Loading scenario", "filename", filename, "full_path", path, "dataDir", r.dataDir)
+	r.logger.DebugContext(ctx, "Loading scenario", "filename", filename, "full_path", path, "dataDir", r.dataDir)
 
-	file, err := os.ReadFile(path)
+	file, err := r.readDataFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			r.logger.Error("Scenario file not found", "path", path, "error", err)
+			r.logger.ErrorContext(ctx, "Scenario file not found", "path", path, "error", err)
 			return nil, fmt.Errorf("scenario not found: %s", filename)
 		}
 		return nil, fmt.Errorf("failed to read scenario file: %w", err)
@@ -64,6 +69,152 @@ func (r *RedisStorage) GetScenario(ctx context.Context, filename string) (*scena
 	if err := json.Unmarshal(file, &s); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal scenario: %w", err)
 	}
+	for _, c := range s.Normalize() {
+		r.logger.WarnContext(ctx, "Scenario keys collide", "filename", filename, "kind", c.Kind, "key", c.Key, "keys", c.Keys)
+	}
+	if info, err := os.Stat(path); err == nil {
+		s.UpdatedAt = info.ModTime()
+	}
+
+	return &s, nil
+}
+
+// SaveScenario writes a scenario to its file, creating or replacing it
+func (r *FileStore) SaveScenario(ctx context.Context, filename string, s *scenario.Scenario) error {
+	if err := r.writeDataFile("scenarios", strings.TrimSuffix(filename, ".json"), s); err != nil {
+		return err
+	}
+	r.unhide(filename) // saved scenarios have passed validation
+	return nil
+}
+
+// DeleteScenario removes a scenario's file
+func (r *FileStore) DeleteScenario(ctx context.Context, filename string) error {
+	if err := r.removeDataFile("scenarios", strings.TrimSuffix(filename, ".json")); err != nil {
+		return err
+	}
+	r.unhide(filename)
+	return nil
+}
+
+// Scenario operations (Redis-backed; see RedisStorage.WithScenariosInRedis)
+
+// scenariosKey is the hash of scenarios saved through the API, by filename. Like the
+// data directory, it is shared by every profile, so it has no key prefix.
+const scenariosKey = "scenarios"
+
+// storedScenario is a scenario as saved in scenariosKey
+type storedScenario struct {
+	UpdatedAt time.Time       `json:"updated_at"`
+	Scenario  json.RawMessage `json:"scenario"`
+}
+
+// scenariosInRedis reports whether scenarios are read from and saved to Redis. Content
+// verified against a manifest is only ever read from the data directory.
+func (r *RedisStorage) scenariosInRedis() bool {
+	return r.redisScenarios && r.manifest == nil
+}
+
+func (r *RedisStorage) ListScenarios(ctx context.Context) (map[string]string, error) {
+	scenarios, err := r.FileStore.ListScenarios(ctx)
+	if err != nil || !r.scenariosInRedis() {
+		return scenarios, err
+	}
 
+	stored, err := r.client.HGetAll(ctx, scenariosKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scenarios: %w", err)
+	}
+	// A scenario saved in Redis replaces the file of the same name
+	for name, filename := range scenarios {
+		if _, ok := stored[filename]; ok {
+			delete(scenarios, name)
+		}
+	}
+	for filename, payload := range stored {
+		if r.isHidden(filename) {
+			continue
+		}
+		s, err := decodeStoredScenario(payload)
+		if err != nil {
+			r.logger.WarnContext(ctx, "Failed to unmarshal stored scenario", "filename", filename, "error", err)
+			continue
+		}
+		scenarios[s.Name] = filename
+	}
+	return scenarios, nil
+}
+
+func (r *RedisStorage) GetScenario(ctx context.Context, filename string) (*scenario.Scenario, error) {
+	if !r.scenariosInRedis() {
+		return r.FileStore.GetScenario(ctx, filename)
+	}
+	payload, err := r.client.HGet(ctx, scenariosKey, filename).Result()
+	if errors.Is(err, redis.Nil) {
+		return r.FileStore.GetScenario(ctx, filename)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load scenario: %w", err)
+	}
+
+	s, err := decodeStoredScenario(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scenario: %w", err)
+	}
+	for _, c := range s.Normalize() {
+		r.logger.WarnContext(ctx, "Scenario keys collide", "filename", filename, "kind", c.Kind, "key", c.Key, "keys", c.Keys)
+	}
+	return s, nil
+}
+
+// SaveScenario saves a scenario in Redis, creating or replacing it
+func (r *RedisStorage) SaveScenario(ctx context.Context, filename string, s *scenario.Scenario) error {
+	if !r.scenariosInRedis() {
+		return r.FileStore.SaveScenario(ctx, filename, s)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scenario %s: %w", filename, err)
+	}
+	payload, err := json.Marshal(storedScenario{UpdatedAt: time.Now().UTC(), Scenario: data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal scenario %s: %w", filename, err)
+	}
+	if err := r.client.HSet(ctx, scenariosKey, filename, payload).Err(); err != nil {
+		return fmt.Errorf("failed to save scenario %s: %w", filename, err)
+	}
+	r.unhide(filename) // saved scenarios have passed validation
+	return nil
+}
+
+// DeleteScenario removes a scenario saved in Redis. A file in the data directory of
+// the same name is served again; one with no saved scenario over it can't be deleted.
+func (r *RedisStorage) DeleteScenario(ctx context.Context, filename string) error {
+	if !r.scenariosInRedis() {
+		return r.FileStore.DeleteScenario(ctx, filename)
+	}
+	removed, err := r.client.HDel(ctx, scenariosKey, filename).Result()
+	if err != nil {
+		return fmt.Errorf("failed to delete scenario %s: %w", filename, err)
+	}
+	if removed == 0 {
+		if _, err := os.Stat(filepath.Join(r.dataDir, "scenarios", filename)); err == nil {
+			return storage.ErrContentBundled
+		}
+	}
+	r.unhide(filename)
+	return nil
+}
+
+// decodeStoredScenario unmarshals a scenario saved in Redis
+func decodeStoredScenario(payload string) (*scenario.Scenario, error) {
+	var stored storedScenario
+	if err := json.Unmarshal([]byte(payload), &stored); err != nil {
+		return nil, err
+	}
+	var s scenario.Scenario
+	if err := json.Unmarshal(stored.Scenario, &s); err != nil {
+		return nil, err
+	}
+	s.UpdatedAt = stored.UpdatedAt
 	return &s, nil
 }
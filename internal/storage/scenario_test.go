@@ -2,8 +2,12 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"os"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
 	"github.com/jwebster45206/story-engine/pkg/storage"
 )
@@ -122,3 +126,96 @@ func TestMockStorage_ListScenariosEmpty(t *testing.T) {
 		t.Errorf("Expected 0 scenarios, got %d", len(scenarios))
 	}
 }
+
+func TestFileStore_SaveAndDeleteScenario(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	store := NewFileStore(t.TempDir(), logger)
+	ctx := context.Background()
+
+	s := &scenario.Scenario{Name: "Tiny Tale", Rating: scenario.RatingG, OpeningLocation: "hall"}
+	if err := store.SaveScenario(ctx, "tiny_tale.json", s); err != nil {
+		t.Fatalf("Failed to save scenario: %v", err)
+	}
+
+	loaded, err := store.GetScenario(ctx, "tiny_tale.json")
+	if err != nil {
+		t.Fatalf("Failed to get scenario: %v", err)
+	}
+	if loaded.Name != s.Name || loaded.OpeningLocation != s.OpeningLocation {
+		t.Errorf("Expected %+v, got %+v", s, loaded)
+	}
+	if scenarios, _ := store.ListScenarios(ctx); scenarios["Tiny Tale"] != "tiny_tale.json" {
+		t.Errorf("Expected Tiny Tale in tiny_tale.json, got %v", scenarios)
+	}
+
+	if err := store.DeleteScenario(ctx, "tiny_tale.json"); err != nil {
+		t.Fatalf("Failed to delete scenario: %v", err)
+	}
+	if _, err := store.GetScenario(ctx, "tiny_tale.json"); err == nil {
+		t.Error("Expected the scenario to be gone after delete")
+	}
+	if err := store.DeleteScenario(ctx, "tiny_tale.json"); err != nil {
+		t.Errorf("Expected deleting a missing scenario to succeed, got %v", err)
+	}
+}
+
+func TestRedisStorage_ScenariosInRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dataDir := t.TempDir()
+	ctx := context.Background()
+
+	// A scenario bundled in the data directory
+	bundled := &scenario.Scenario{Name: "Old Mill", Rating: scenario.RatingG, OpeningLocation: "mill"}
+	if err := NewFileStore(dataDir, logger).SaveScenario(ctx, "old_mill.json", bundled); err != nil {
+		t.Fatalf("Failed to write bundled scenario: %v", err)
+	}
+
+	rs := NewRedisStorage(mr.Addr(), dataDir, logger).WithScenariosInRedis(true)
+	// Another API instance with its own data directory, sharing the Redis
+	other := NewRedisStorage(mr.Addr(), t.TempDir(), logger).WithScenariosInRedis(true)
+
+	s := &scenario.Scenario{Name: "Tiny Tale", Rating: scenario.RatingG, OpeningLocation: "hall"}
+	if err := rs.SaveScenario(ctx, "tiny_tale.json", s); err != nil {
+		t.Fatalf("Failed to save scenario: %v", err)
+	}
+	if _, err := os.Stat(dataDir + "/scenarios/tiny_tale.json"); !os.IsNotExist(err) {
+		t.Errorf("Expected no scenario file in the data directory, got %v", err)
+	}
+	loaded, err := other.GetScenario(ctx, "tiny_tale.json")
+	if err != nil {
+		t.Fatalf("Expected the scenario to be shared through Redis, got %v", err)
+	}
+	if loaded.Name != s.Name || loaded.OpeningLocation != s.OpeningLocation || loaded.UpdatedAt.IsZero() {
+		t.Errorf("Expected %+v with an update time, got %+v", s, loaded)
+	}
+
+	// Saving over a bundled scenario replaces it until deleted
+	edited := &scenario.Scenario{Name: "New Mill", Rating: scenario.RatingG, OpeningLocation: "mill"}
+	if err := rs.SaveScenario(ctx, "old_mill.json", edited); err != nil {
+		t.Fatalf("Failed to save scenario: %v", err)
+	}
+	scenarios, err := rs.ListScenarios(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list scenarios: %v", err)
+	}
+	if len(scenarios) != 2 || scenarios["Tiny Tale"] != "tiny_tale.json" || scenarios["New Mill"] != "old_mill.json" {
+		t.Errorf("Expected Tiny Tale and New Mill, got %v", scenarios)
+	}
+	if err := rs.DeleteScenario(ctx, "old_mill.json"); err != nil {
+		t.Fatalf("Failed to delete scenario: %v", err)
+	}
+	if loaded, err := rs.GetScenario(ctx, "old_mill.json"); err != nil || loaded.Name != "Old Mill" {
+		t.Errorf("Expected the bundled scenario back after delete, got %+v, %v", loaded, err)
+	}
+	if err := rs.DeleteScenario(ctx, "old_mill.json"); !errors.Is(err, storage.ErrContentBundled) {
+		t.Errorf("Expected ErrContentBundled deleting a bundled scenario, got %v", err)
+	}
+
+	if err := other.DeleteScenario(ctx, "tiny_tale.json"); err != nil {
+		t.Fatalf("Failed to delete scenario: %v", err)
+	}
+	if _, err := rs.GetScenario(ctx, "tiny_tale.json"); err == nil {
+		t.Error("Expected the scenario to be gone after delete")
+	}
+}
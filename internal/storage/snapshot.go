@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/storage"
+	"github.com/redis/go-redis/v9"
+)
+
+// Game snapshot operations (Redis-backed)
+
+var _ storage.Snapshots = (*RedisStorage)(nil)
+
+// gameSnapshotsKey returns the Redis key of a game's snapshot hash, e.g. "gamestate:<id>:snapshots".
+// Fields are snapshot names.
+func (r *RedisStorage) gameSnapshotsKey(id uuid.UUID) string {
+	return r.gameStateKey(id) + ":snapshots"
+}
+
+// expireSnapshots gives a game's snapshots the same TTL as the game, so they live as
+// long as it does
+func (r *RedisStorage) expireSnapshots(ctx context.Context, pipe redis.Pipeliner, id uuid.UUID, ttl time.Duration) {
+	if ttl > 0 {
+		pipe.Expire(ctx, r.gameSnapshotsKey(id), ttl)
+	} else {
+		pipe.Persist(ctx, r.gameSnapshotsKey(id))
+	}
+}
+
+func (r *RedisStorage) SaveSnapshot(ctx context.Context, id uuid.UUID, snap *state.Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to marshal snapshot", "uuid", id, "snapshot", snap.Name, "error", err)
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	payload, err := r.seal(data, snapshotAAD(id, snap.Name))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to encrypt snapshot", "uuid", id, "snapshot", snap.Name, "error", err)
+		return fmt.Errorf("failed to encrypt snapshot: %w", err)
+	}
+
+	// Take the game's remaining TTL, which is none for a paused game
+	ttl := gameStateTTL
+	if remaining, err := r.client.PTTL(ctx, r.gameStateKey(id)).Result(); err == nil && remaining != -2 {
+		ttl = max(remaining, 0)
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, r.gameSnapshotsKey(id), snap.Name, payload)
+		r.expireSnapshots(ctx, pipe, id, ttl)
+		return nil
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to save snapshot", "uuid", id, "snapshot", snap.Name, "error", err)
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisStorage) ListSnapshots(ctx context.Context, id uuid.UUID) ([]state.Snapshot, error) {
+	raw, err := r.client.HGetAll(ctx, r.gameSnapshotsKey(id)).Result()
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to list snapshots", "uuid", id, "error", err)
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	snapshots := make([]state.Snapshot, 0, len(raw))
+	for name, payload := range raw {
+		snap, err := r.decodeSnapshot(payload, snapshotAAD(id, name))
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to read snapshot", "uuid", id, "snapshot", name, "error", err)
+			return nil, err
+		}
+		snapshots = append(snapshots, snap.Summary())
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+func (r *RedisStorage) LoadSnapshot(ctx context.Context, id uuid.UUID, name string) (*state.Snapshot, error) {
+	payload, err := r.client.HGet(ctx, r.gameSnapshotsKey(id), name).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to load snapshot", "uuid", id, "snapshot", name, "error", err)
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	snap, err := r.decodeSnapshot(payload, snapshotAAD(id, name))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to read snapshot", "uuid", id, "snapshot", name, "error", err)
+		return nil, err
+	}
+	return snap, nil
+}
+
+func (r *RedisStorage) decodeSnapshot(payload, aad string) (*state.Snapshot, error) {
+	data, err := r.open(payload, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot: %w", err)
+	}
+	var snap state.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	return &snap, nil
+}
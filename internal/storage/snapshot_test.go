@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func TestRedisStorage_Snapshots(t *testing.T) {
+	mr := miniredis.RunT(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	rs := NewRedisStorage(mr.Addr(), "", logger).WithEncryption(testEncryptor(t, "k1", "k1"))
+	ctx := context.Background()
+
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.Location = "tavern"
+	if err := rs.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	key := "gamestate:" + gs.ID.String() + ":snapshots"
+
+	for _, name := range []string{"first", "second", "first"} {
+		snap, err := state.NewSnapshot(name, gs)
+		if err != nil {
+			t.Fatalf("Failed to take snapshot: %v", err)
+		}
+		if err := rs.SaveSnapshot(ctx, gs.ID, snap); err != nil {
+			t.Fatalf("Failed to save snapshot: %v", err)
+		}
+		time.Sleep(time.Millisecond) // keep creation times apart
+	}
+	if ttl := mr.TTL(key); ttl <= 0 || ttl > gameStateTTL {
+		t.Errorf("Expected snapshots to expire with the game, got TTL %v", ttl)
+	}
+
+	snapshots, err := rs.ListSnapshots(ctx, gs.ID)
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != "second" || snapshots[1].Name != "first" {
+		t.Errorf("Expected [second first] with the replaced snapshot last, got %+v", snapshots)
+	}
+	if snapshots[0].GameState != nil {
+		t.Error("Expected listed snapshots without their game states")
+	}
+
+	snap, err := rs.LoadSnapshot(ctx, gs.ID, "first")
+	if err != nil || snap == nil || snap.GameState == nil || snap.GameState.Location != "tavern" {
+		t.Fatalf("Expected the snapshot with its game state, got %+v, %v", snap, err)
+	}
+	if missing, err := rs.LoadSnapshot(ctx, gs.ID, "missing"); err != nil || missing != nil {
+		t.Errorf("Expected nil for a missing snapshot, got %+v, %v", missing, err)
+	}
+
+	// Pausing the game keeps its snapshots too
+	gs.Pause("")
+	if err := rs.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	if ttl := mr.TTL(key); ttl != 0 {
+		t.Errorf("Expected a paused game's snapshots not to expire, got TTL %v", ttl)
+	}
+
+	if err := rs.DeleteGameState(ctx, gs.ID); err != nil {
+		t.Fatalf("Failed to delete gamestate: %v", err)
+	}
+	if mr.Exists(key) {
+		t.Error("Expected snapshots to be deleted with the game")
+	}
+}
+
+func TestMemoryStorage_Snapshots(t *testing.T) {
+	m, now := newTestMemoryStorage(t)
+	ctx := context.Background()
+
+	gs := state.NewGameState("test_scenario.json", nil, "test_model")
+	if err := m.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	snap, err := state.NewSnapshot("slot", gs)
+	if err != nil {
+		t.Fatalf("Failed to take snapshot: %v", err)
+	}
+	if err := m.SaveSnapshot(ctx, gs.ID, snap); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+
+	if loaded, _ := m.LoadSnapshot(ctx, gs.ID, "slot"); loaded == nil || loaded.GameState == nil {
+		t.Errorf("Expected the saved snapshot, got %+v", loaded)
+	}
+	if other, _ := m.WithKeyPrefix("other").ListSnapshots(ctx, gs.ID); len(other) != 0 {
+		t.Errorf("Expected another prefix not to see the snapshot, got %+v", other)
+	}
+
+	*now = now.Add(gameStateTTL)
+	if snapshots, _ := m.ListSnapshots(ctx, gs.ID); len(snapshots) != 0 {
+		t.Errorf("Expected snapshots to expire with the game, got %+v", snapshots)
+	}
+	if loaded, _ := m.LoadSnapshot(ctx, gs.ID, "slot"); loaded != nil {
+		t.Error("Expected an expired game's snapshot to be gone")
+	}
+}
@@ -10,17 +10,29 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/logger"
 	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/internal/services/analytics"
+	"github.com/jwebster45206/story-engine/internal/services/usage"
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
 	"github.com/jwebster45206/story-engine/pkg/prompts"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
 	"github.com/jwebster45206/story-engine/pkg/state"
 	"github.com/jwebster45206/story-engine/pkg/storage"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
 )
 
 const PromptHistoryLimit = 16
 
+// RegenerateTemperatureStep is how much hotter a regenerated turn is narrated than the
+// original, so the new narration differs from the dud it replaces
+const RegenerateTemperatureStep = 0.2
+
+// maxRegenerateTemperature caps the temperature of a regenerated turn
+const maxRegenerateTemperature = 1.0
+
 // ChatProcessor handles the core chat processing logic
 // It's used by both the HTTP handler (synchronously) and the worker (asynchronously)
 type ChatProcessor struct {
@@ -29,10 +41,78 @@ type ChatProcessor struct {
 	chatQueue    state.ChatQueue
 	logger       *slog.Logger
 	historyLimit int
+	models       *config.ModelRegistry
+	backendModel string           // runs the gamestate delta instead of the game's model; see WithBackendModel
+	ledger       *usage.Ledger    // optional; records token usage per game and API key
+	analytics    *analytics.Store // optional; records anonymized play statistics per scenario
+	translate    bool             // translate non-English player messages for the gamestate delta
+	prefixes     *prompts.PrefixCache
+	compactAt    int // narration length from which the delta gets a compact before-state; 0 = never
+	maxNarration int // narration length limit in characters; 0 = none
+	profanity    *textfilter.ProfanityFilter
+	consistency  string // narration consistency check mode; see WithConsistencyCheck
+	meta         bool   // ask the narrator for a structured trailer; see WithNarrationMeta
+
+	// Each game's background gamestate deltas, run one at a time; see deltaLane
+	deltasMu sync.Mutex
+	deltas   map[uuid.UUID]*deltaLane
+	deltaSeq uint64        // last sequence number given to a delta run
+	idle     chan struct{} // closed when the last lane exits; see Drain
+
+	// deltaApplied, if set, is called after a gamestate delta has been saved
+	deltaApplied func(ctx context.Context, gs *state.GameState)
+}
+
+// DeltaRun is a gamestate delta started before a turn's narration is complete. The
+// reducer prompt, scenario, and any input translation are prepared while the
+// narration streams, and the delta request is sent as soon as it is finished.
+type DeltaRun struct {
+	gameStateID uuid.UUID
+	narration   chan string
+	cancel      context.CancelFunc
+	done        chan struct{}
+	sync        func() // the delta itself, run on the game's lane
+	seq         uint64 // order the run was posted in; later turns have higher numbers
+}
+
+// Abort stops the delta, e.g. when the narration stream fails. Safe to call on a nil run.
+func (r *DeltaRun) Abort() {
+	if r != nil {
+		r.cancel()
+	}
+}
+
+// finish hands the complete narration to the delta
+func (r *DeltaRun) finish(narration string) {
+	if r != nil {
+		r.narration <- narration
+	}
+}
+
+// wait blocks until the delta has been applied or has given up
+func (r *DeltaRun) wait() {
+	if r != nil {
+		<-r.done
+	}
+}
+
+// execute runs the delta on its game's lane
+func (r *DeltaRun) execute() {
+	defer close(r.done)
+	defer r.cancel()
+	r.sync()
+}
 
-	// For background gamestate delta cancellation
-	metaCancelMu sync.Mutex
-	metaCancel   map[uuid.UUID]context.CancelFunc
+// drop discards a run that a newer one replaced before it started
+func (r *DeltaRun) drop() {
+	r.cancel()
+	close(r.done)
+}
+
+// supersedes reports whether r belongs to a later turn than other. A nil run
+// supersedes every run.
+func (r *DeltaRun) supersedes(other *DeltaRun) bool {
+	return r == nil || other.seq < r.seq
 }
 
 // NewChatProcessor creates a new chat processor
@@ -52,8 +132,155 @@ func NewChatProcessor(
 		chatQueue:    chatQueue,
 		logger:       logger,
 		historyLimit: historyLimit,
-		metaCancel:   make(map[uuid.UUID]context.CancelFunc),
+		models:       config.NewModelRegistry(nil),
+		prefixes:     prompts.NewPrefixCache(0),
+		profanity:    textfilter.NewProfanityFilter(),
+		deltas:       make(map[uuid.UUID]*deltaLane),
+	}
+}
+
+// WithModelRegistry sets the model capabilities consulted when calling the LLM,
+// e.g. to fall back to a single response for models that can't stream
+func (p *ChatProcessor) WithModelRegistry(models *config.ModelRegistry) *ChatProcessor {
+	p.models = models
+	return p
+}
+
+// WithBackendModel names the LLM service's backend model, if it has one. The backend
+// model runs the gamestate delta, so its reducer prompt override is used instead of the
+// game model's.
+func (p *ChatProcessor) WithBackendModel(modelName string) *ChatProcessor {
+	p.backendModel = modelName
+	return p
+}
+
+// WithUsageLedger records the token usage of every LLM call against the game
+// and the API key that created it
+func (p *ChatProcessor) WithUsageLedger(ledger *usage.Ledger) *ChatProcessor {
+	p.ledger = ledger
+	return p
+}
+
+// WithAnalytics records each turn's scene changes, endings and fired conditionals
+// in the scenario's play statistics
+func (p *ChatProcessor) WithAnalytics(store *analytics.Store) *ChatProcessor {
+	p.analytics = store
+	return p
+}
+
+// WithInputTranslation translates player messages that aren't in English before the
+// gamestate delta, so the reducer can match them to the game state. Narration and the
+// chat history keep the original message.
+func (p *ChatProcessor) WithInputTranslation(enabled bool) *ChatProcessor {
+	p.translate = enabled
+	return p
+}
+
+// WithCompactDelta sends the reducer a compact before-state, without descriptions and
+// stats, for narrations of at least minChars characters. Long narrations already make
+// the delta request large, so trimming the state keeps its backend token usage down.
+// Zero disables it.
+func (p *ChatProcessor) WithCompactDelta(minChars int) *ChatProcessor {
+	p.compactAt = minChars
+	return p
+}
+
+// WithMaxNarrationChars cuts narration longer than maxChars characters at the last
+// sentence end before the limit. Zero means no limit.
+func (p *ChatProcessor) WithMaxNarrationChars(maxChars int) *ChatProcessor {
+	p.maxNarration = maxChars
+	return p
+}
+
+// WithProfanityFilter replaces the built-in profanity filter with one carrying
+// configured word lists
+func (p *ChatProcessor) WithProfanityFilter(filter *textfilter.ProfanityFilter) *ChatProcessor {
+	p.profanity = filter
+	return p
+}
+
+// WithNarrationMeta asks the narrator to end each narration with a structured trailer:
+// the mood, the NPCs present, and suggested choices. The trailer is removed before the
+// player sees the narration and stored on the narrator's message.
+func (p *ChatProcessor) WithNarrationMeta(enabled bool) *ChatProcessor {
+	p.meta = enabled
+	return p
+}
+
+// narrationPipeline returns the post-processing for one narration in the scenario.
+// Profanity is filtered with the word lists for the language of the player's message,
+// which the narrator answers in.
+func (p *ChatProcessor) narrationPipeline(s *scenario.Scenario, length scenario.NarrationLength, playerMessage string) *textfilter.Pipeline {
+	filter := p.profanity.ForLocale(chat.DetectLanguage(playerMessage))
+	pipeline := textfilter.NarrationPipeline(filter, s.Rating, p.maxNarration).WithMaxSentences(length.MaxSentences)
+	if p.meta {
+		pipeline.WithTrailer(textfilter.TrailerTag)
+	}
+	return pipeline
+}
+
+// narrationMeta parses a narration trailer. A trailer that can't be parsed is logged
+// and dropped, as the narration itself is fine.
+func (p *ChatProcessor) narrationMeta(ctx context.Context, gs *state.GameState, trailer string) *chat.NarrationMeta {
+	meta, err := chat.ParseNarrationMeta(trailer)
+	if err != nil {
+		p.logger.WarnContext(ctx, "Failed to parse narration trailer", "error", err, "game_state_id", gs.ID.String())
+		return nil
+	}
+	if meta == nil && p.meta {
+		p.logger.DebugContext(ctx, "Narration has no trailer", "game_state_id", gs.ID.String())
+	}
+	return meta
+}
+
+// appendTurn adds the player's message and the narration, with its trailer if any, to
+// the chat history, or to the side conversation's history while one is open
+func appendTurn(gs *state.GameState, userMessage chat.ChatMessage, narration string, meta *chat.NarrationMeta) {
+	turn := []chat.ChatMessage{userMessage, {Role: chat.ChatRoleAgent, Content: narration, Meta: meta}}
+	if gs.Conversation != nil {
+		gs.Conversation.History = append(gs.Conversation.History, turn...)
+		return
 	}
+	gs.ChatHistory = append(gs.ChatHistory, turn...)
+}
+
+// narrationSegments splits processed narration into prose and dialogue, recognising
+// the game's NPCs and PC by name
+func narrationSegments(gs *state.GameState, narration string) []chat.Segment {
+	var speakers []string
+	if gs.PC != nil && gs.PC.Spec != nil {
+		speakers = append(speakers, gs.PC.Spec.Name)
+	}
+	for _, npc := range gs.NPCs {
+		speakers = append(speakers, npc.Name)
+	}
+	return chat.ParseSegments(narration, speakers)
+}
+
+// llmContext routes LLM calls made with ctx to the game's model, charges their usage to the
+// game, and labels them with the game and turn for LLM capture
+func (p *ChatProcessor) llmContext(ctx context.Context, gs *state.GameState) context.Context {
+	ctx = services.WithModel(ctx, gs.ModelName)
+	ctx = services.WithCaptureLabel(ctx, gs.ID.String(), gs.TurnCounter)
+	if p.ledger != nil {
+		ctx = services.WithUsageRecorder(ctx, p.ledger.Recorder(gs.ID, gs.APIKeyID))
+	}
+	return ctx
+}
+
+// narrationContext returns the context for narrating with: llmContext, holding the
+// provider to the narration's token limit
+func (p *ChatProcessor) narrationContext(ctx context.Context, gs *state.GameState, length scenario.NarrationLength) context.Context {
+	return services.WithMaxTokens(p.llmContext(ctx, gs), length.MaxTokens)
+}
+
+// narrationLength returns the bounds on a turn's narration. Each bound the request sets
+// overrides the active scene's, which overrides the scenario's.
+func narrationLength(gs *state.GameState, s *scenario.Scenario, req chat.ChatRequest) scenario.NarrationLength {
+	return s.SceneNarrationLength(gs.SceneName).Override(scenario.NarrationLength{
+		MaxTokens:    req.MaxTokens,
+		MaxSentences: req.MaxSentences,
+	})
 }
 
 // resolveTemperature returns the effective LLM temperature for the current game state.
@@ -70,6 +297,34 @@ func resolveTemperature(gs *state.GameState, s *scenario.Scenario) float64 {
 	return services.DefaultTemperature
 }
 
+// turnTemperature returns the temperature to narrate a turn at: the resolved
+// temperature, raised by RegenerateTemperatureStep for a regenerated turn
+func turnTemperature(gs *state.GameState, s *scenario.Scenario, req chat.ChatRequest) float64 {
+	temperature := resolveTemperature(gs, s)
+	if req.Regenerate {
+		temperature = min(temperature+RegenerateTemperatureStep, maxRegenerateTemperature)
+	}
+	return temperature
+}
+
+// trialWatermark returns the preview notice shown after the narration of a preview
+// game's next turn, or "" for a full game. It isn't kept in the chat history, so the
+// narrator never sees it.
+func trialWatermark(gs *state.GameState) string {
+	if gs.Trial == nil {
+		return ""
+	}
+	return "\n\n" + gs.Trial.Watermark()
+}
+
+// turnKind returns whether a player's chat request consumes a turn
+func turnKind(req chat.ChatRequest) state.TurnKind {
+	if req.FreeAction {
+		return state.TurnFree
+	}
+	return state.TurnPlayer
+}
+
 // ProcessChatRequest processes a chat request and returns the response
 func (p *ChatProcessor) ProcessChatRequest(ctx context.Context, req chat.ChatRequest) (*chat.ChatResponse, error) {
 	// Load game state
@@ -81,20 +336,44 @@ func (p *ChatProcessor) ProcessChatRequest(ctx context.Context, req chat.ChatReq
 	if gs == nil {
 		return nil, fmt.Errorf("game state not found: %s", req.GameStateID.String())
 	}
+	startInventory := append([]string(nil), gs.Inventory...)
+
+	req.Message = p.ExpandAliases(ctx, gs, req.Message)
+
+	// Server commands such as /inventory are answered without a turn
+	if reply, handled, err := p.HandleCommand(ctx, gs, req.Message, req.Embellish); err != nil {
+		return nil, err
+	} else if handled {
+		return &chat.ChatResponse{
+			GameStateID: gs.ID,
+			Message:     reply,
+			State:       gs.Summary(startInventory),
+		}, nil
+	}
 
 	// Get Scenario for the chat
 	loadedScenario, err := p.storage.GetScenario(ctx, gs.Scenario)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load scenario: %w", err)
 	}
+	if gs.MigrateIDs(loadedScenario) {
+		p.logger.InfoContext(ctx, "Migrated renamed scenario references", "game_state_id", gs.ID.String(), "scenario", gs.Scenario)
+		for i, item := range startInventory {
+			startInventory[i] = loadedScenario.ItemID(item)
+		}
+	}
 
 	// Build chat messages using the prompt builder
 	// Note: req.Message should be pre-formatted with PC name if applicable
+	length := narrationLength(gs, loadedScenario, req)
 	messages, err := prompts.New().
 		WithGameState(gs).
 		WithScenario(loadedScenario).
 		WithUserMessage(req.Message, chat.ChatRoleUser).
 		WithHistoryLimit(p.historyLimit).
+		WithPrefixCache(p.prefixes).
+		WithMaxSentences(length.MaxSentences).
+		WithNarrationMeta(p.meta).
 		Build()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build chat messages: %w", err)
@@ -102,53 +381,54 @@ func (p *ChatProcessor) ProcessChatRequest(ctx context.Context, req chat.ChatReq
 
 	chatCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
+	chatCtx = p.narrationContext(chatCtx, gs, length)
 
-	temperature := resolveTemperature(gs, loadedScenario)
-	p.logger.Debug("Sending chat request to LLM", "game_state_id", gs.ID.String(), "messages", messages)
+	// Prepare the gamestate delta while the narrator writes
+	run := p.StartDelta(ctx, gs, req.Message, turnKind(req))
+
+	temperature := turnTemperature(gs, loadedScenario, req)
+	p.logger.DebugContext(ctx, "Sending chat request to LLM", "game_state_id", gs.ID.String(), "messages", messages, logger.CategoryKey, logger.CategoryPrompt)
 	response, err := p.llmService.Chat(chatCtx, messages, temperature)
 	if err != nil {
+		run.Abort()
 		return nil, fmt.Errorf("LLM chat failed: %w", err)
 	}
+	response.Message = p.reviseNarration(ctx, gs, messages, temperature, req.Message, response.Message)
 
-	// Cancel any in-process gamestate delta for this game state
-	p.metaCancelMu.Lock()
-	if cancel, ok := p.metaCancel[gs.ID]; ok {
-		cancel()
-	}
-	metaCtx, metaCancel := context.WithCancel(context.Background())
-	p.metaCancel[gs.ID] = metaCancel
-	p.metaCancelMu.Unlock()
-
-	if !gs.IsEnded {
-		// Make a deep copy for the background goroutine to avoid data races
-		gsCopy, err := gs.DeepCopy()
-		if err != nil {
-			p.logger.Error("Failed to copy game state for background sync", "error", err, "game_state_id", gs.ID.String())
-		} else {
-			// Start background goroutine to update game meta (PromptState)
-			go p.syncGameState(metaCtx, gsCopy, req.Message, response.Message)
-		}
-	}
-
-	// Update game state with new chat message
-	gs.ChatHistory = append(gs.ChatHistory, chat.ChatMessage{
-		Role:    chat.ChatRoleUser,
-		Content: req.Message,
-	})
+	// Cancel the game's earlier gamestate deltas; this turn supersedes them
+	p.supersedeDeltas(gs.ID, run)
 
-	// Add to game state
+	// Add the turn to the game state
+	pipeline := p.narrationPipeline(loadedScenario, length, req.Message)
+	response.Message = pipeline.Process(response.Message)
 	response.Message = strings.TrimRight(response.Message, "\n")
-	gs.ChatHistory = append(gs.ChatHistory, chat.ChatMessage{
-		Role:    chat.ChatRoleAgent,
-		Content: response.Message,
-	})
+	meta := p.narrationMeta(ctx, gs, pipeline.Trailer())
+	if err := gs.RememberTurn(req.Message, turnKind(req)); err != nil {
+		p.logger.WarnContext(ctx, "Failed to remember turn for regeneration", "error", err, "game_state_id", gs.ID.String())
+	}
+	gs.PendingVariants = nil
+	gs.PendingCheck = nil // this narration resolved it
+	gs.CombatNarrated()
+	appendTurn(gs, chat.ChatMessage{Role: chat.ChatRoleUser, Content: req.Message}, response.Message, meta)
+	watermark := trialWatermark(gs)
+	if gs.Trial != nil {
+		gs.Trial.TurnsUsed++
+	}
 
 	// Save the updated game state
-	if err := p.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+	if err := p.storage.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventTurn}), gs.ID, gs); err != nil {
+		run.Abort()
 		return nil, fmt.Errorf("failed to save game state: %w", err)
 	}
+	run.finish(response.Message)
+	response.Message += watermark
 
 	response.GameStateID = gs.ID
+	response.Segments = narrationSegments(gs, response.Message)
+	response.State = gs.Summary(startInventory)
+	if gs.ChoicesMode && !gs.IsEnded {
+		response.Choices = p.SuggestChoices(ctx, gs, response.Message)
+	}
 	return response, nil
 }
 
@@ -169,14 +449,21 @@ func (p *ChatProcessor) ProcessChatStream(ctx context.Context, req chat.ChatRequ
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to load scenario: %w", err)
 	}
+	if gs.MigrateIDs(loadedScenario) {
+		p.logger.InfoContext(ctx, "Migrated renamed scenario references", "game_state_id", gs.ID.String(), "scenario", gs.Scenario)
+	}
 
 	// Build chat messages using the prompt builder
 	// req.Message is already formatted with PC name if applicable
+	length := narrationLength(gs, loadedScenario, req)
 	messages, err := prompts.New().
 		WithGameState(gs).
 		WithScenario(loadedScenario).
 		WithUserMessage(req.Message, chat.ChatRoleUser).
 		WithHistoryLimit(p.historyLimit).
+		WithPrefixCache(p.prefixes).
+		WithMaxSentences(length.MaxSentences).
+		WithNarrationMeta(p.meta).
 		Build()
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to build chat messages: %w", err)
@@ -185,14 +472,25 @@ func (p *ChatProcessor) ProcessChatStream(ctx context.Context, req chat.ChatRequ
 	// Clear story events after consumption
 	if p.chatQueue != nil {
 		if err := p.chatQueue.Clear(ctx, gs.ID); err != nil {
-			p.logger.Error("Failed to clear chat queue", "error", err, "game_state_id", gs.ID.String())
+			p.logger.ErrorContext(ctx, "Failed to clear chat queue", "error", err, "game_state_id", gs.ID.String())
 		}
 	}
 
 	// Initialize LLM streaming
 	// Use the context passed in from the worker - it will stay alive while consuming the stream
-	temperature := resolveTemperature(gs, loadedScenario)
-	p.logger.Debug("Sending streaming chat request to LLM", "game_state_id", gs.ID.String(), "messages", messages)
+	ctx = p.narrationContext(ctx, gs, length)
+	temperature := turnTemperature(gs, loadedScenario, req)
+	pipeline := p.narrationPipeline(loadedScenario, length, req.Message)
+	if p.consistency == config.ConsistencyRegenerate {
+		// The narration can't be shown until it has been checked
+		p.logger.DebugContext(ctx, "Consistency check enabled, sending single chat request", "game_state_id", gs.ID.String())
+		return filterStream(p.checkedChunkStream(ctx, gs, messages, temperature, req.Message), pipeline), "", nil
+	}
+	if !p.models.Lookup(gs.ModelName).Streaming {
+		p.logger.DebugContext(ctx, "Model does not support streaming, sending single chat request", "game_state_id", gs.ID.String(), "model", gs.ModelName)
+		return filterStream(p.singleChunkStream(ctx, messages, temperature), pipeline), "", nil
+	}
+	p.logger.DebugContext(ctx, "Sending streaming chat request to LLM", "game_state_id", gs.ID.String(), "messages", messages, logger.CategoryKey, logger.CategoryPrompt)
 	streamChan, err := p.llmService.ChatStream(ctx, messages, temperature)
 	if err != nil {
 		return nil, "", fmt.Errorf("LLM chat stream failed: %w", err)
@@ -200,68 +498,287 @@ func (p *ChatProcessor) ProcessChatStream(ctx context.Context, req chat.ChatRequ
 
 	// Return the stream channel and additional context for post-processing
 	// The caller is responsible for consuming the stream and updating game state
-	return streamChan, "", nil
+	return filterStream(streamChan, pipeline), "", nil
+}
+
+// filterStream runs streamed narration through the post-processing pipeline. Text is
+// held back until the pipeline completes a segment, and the rest is flushed with the
+// final chunk, along with any trailer. Errors are passed through with any text held
+// back, so a narration cut off by an error can be kept.
+func filterStream(in <-chan services.StreamChunk, pipeline *textfilter.Pipeline) <-chan services.StreamChunk {
+	out := make(chan services.StreamChunk, cap(in))
+	go func() {
+		defer close(out)
+		for chunk := range in {
+			if chunk.Error != nil {
+				chunk.Content = pipeline.Write(chunk.Content) + pipeline.Flush()
+				out <- chunk
+				return
+			}
+			chunk.Content = pipeline.Write(chunk.Content)
+			if chunk.Done {
+				chunk.Content += pipeline.Flush()
+				chunk.Trailer = pipeline.Trailer()
+				out <- chunk
+				return
+			}
+			if chunk.Content != "" {
+				out <- chunk
+			}
+		}
+		// The stream closed without a final chunk; deliver what's left
+		if rest := pipeline.Flush(); rest != "" || pipeline.Trailer() != "" {
+			out <- services.StreamChunk{Content: rest, Trailer: pipeline.Trailer()}
+		}
+	}()
+	return out
+}
+
+// singleChunkStream calls the non-streaming Chat API and delivers the whole
+// response as one final chunk, so callers can consume it like a stream
+func (p *ChatProcessor) singleChunkStream(ctx context.Context, messages []chat.ChatMessage, temperature float64) <-chan services.StreamChunk {
+	ch := make(chan services.StreamChunk, 1)
+	go func() {
+		defer close(ch)
+		resp, err := p.llmService.Chat(ctx, messages, temperature)
+		if err != nil {
+			ch <- services.StreamChunk{Error: fmt.Errorf("LLM chat failed: %w", err), Done: true}
+			return
+		}
+		ch <- services.StreamChunk{Content: resp.Message, Done: true}
+	}()
+	return ch
 }
 
 // UpdateGameStateAfterStream updates game state after streaming is complete
-// This should be called by the handler after consuming the stream
-func (p *ChatProcessor) UpdateGameStateAfterStream(gs *state.GameState, userMessage, responseMessage, storyEventPrompt string, isStoryEvent bool) error {
-	ctx := context.Background()
+// This should be called by the handler after consuming the stream, with the delta run
+// from StartDelta, which then receives the narration, and the final chunk's trailer.
+// Story events are system turns. The save goes ahead even if ctx is cancelled.
+func (p *ChatProcessor) UpdateGameStateAfterStream(ctx context.Context, gs *state.GameState, run *DeltaRun, userMessage, responseMessage, trailer, storyEventPrompt string, kind state.TurnKind) error {
+	ctx = context.WithoutCancel(ctx)
 
-	// Cancel any in-process gamestate delta for this game state
-	p.metaCancelMu.Lock()
-	if cancel, ok := p.metaCancel[gs.ID]; ok {
-		cancel()
+	// Cancel the game's earlier gamestate deltas; this turn supersedes them
+	p.supersedeDeltas(gs.ID, run)
+
+	responseMessage = strings.TrimRight(responseMessage, "\n")
+	if err := p.saveStreamedTurn(ctx, gs, userMessage, responseMessage, p.narrationMeta(ctx, gs, trailer), kind, false); err != nil {
+		run.Abort()
+		return err
 	}
-	metaCtx, metaCancel := context.WithCancel(context.Background())
-	p.metaCancel[gs.ID] = metaCancel
-	p.metaCancelMu.Unlock()
 
-	gs.ChatHistory = append(gs.ChatHistory, chat.ChatMessage{
+	// The delta loads the game after this save, so it sees the new chat history
+	run.finish(responseMessage)
+
+	p.logger.DebugContext(ctx, "Game state updated after streaming", "game_state_id", gs.ID.String())
+	return nil
+}
+
+// SalvagePartialStream keeps the narration a stream delivered before it failed, such as
+// when the LLM timed out, as the turn's response marked incomplete, so the player keeps
+// what they have read and can ask for the rest. The gamestate delta is skipped: a
+// narration cut off mid-action isn't a reliable account of what happened.
+func (p *ChatProcessor) SalvagePartialStream(ctx context.Context, gs *state.GameState, run *DeltaRun, userMessage, partial string, kind state.TurnKind) error {
+	ctx = context.WithoutCancel(ctx)
+	run.Abort()
+
+	partial = strings.TrimRight(partial, "\n")
+	if err := p.saveStreamedTurn(ctx, gs, userMessage, partial, nil, kind, true); err != nil {
+		return err
+	}
+	p.logger.DebugContext(ctx, "Partial narration salvaged", "game_state_id", gs.ID.String(), "chars", len(partial))
+	return nil
+}
+
+// saveStreamedTurn adds a streamed turn to the game state and saves it
+func (p *ChatProcessor) saveStreamedTurn(ctx context.Context, gs *state.GameState, userMessage, narration string, meta *chat.NarrationMeta, kind state.TurnKind, incomplete bool) error {
+	if err := gs.RememberTurn(userMessage, kind); err != nil {
+		p.logger.WarnContext(ctx, "Failed to remember turn for regeneration", "error", err, "game_state_id", gs.ID.String())
+	}
+	gs.PendingVariants = nil
+	gs.PendingCheck = nil // this narration resolved it
+	gs.CombatNarrated()
+	appendTurn(gs, chat.ChatMessage{
 		Role:         chat.ChatRoleUser,
 		Content:      userMessage,
-		IsStoryEvent: isStoryEvent,
-	})
+		IsStoryEvent: kind == state.TurnSystem,
+	}, narration, meta)
+	if incomplete {
+		history := gs.ChatHistory
+		if gs.Conversation != nil {
+			history = gs.Conversation.History
+		}
+		history[len(history)-1].Incomplete = true
+	}
+	if gs.Trial != nil && kind != state.TurnSystem {
+		gs.Trial.TurnsUsed++
+	}
 
-	// Add to game state
-	responseMessage = strings.TrimRight(responseMessage, "\n")
-	gs.ChatHistory = append(gs.ChatHistory, chat.ChatMessage{
-		Role:    chat.ChatRoleAgent,
-		Content: responseMessage,
+	if err := p.storage.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventTurn}), gs.ID, gs); err != nil {
+		return fmt.Errorf("failed to save game state after streaming: %w", err)
+	}
+	return nil
+}
+
+// RewindLastTurn undoes the game's latest player turn so it can be played again: the
+// turn's messages and everything its delta changed are dropped. A delta for the turn
+// that is still running is stopped first, so it can't land on the rewound state.
+// Returns state.ErrNoLastTurn if there is no player turn to rewind.
+func (p *ChatProcessor) RewindLastTurn(ctx context.Context, gameStateID uuid.UUID) (*state.GameState, *state.LastTurn, error) {
+	running := p.latestDelta(gameStateID)
+	running.Abort()
+	running.wait()
+
+	gs, err := p.GetGameState(ctx, gameStateID)
+	if err != nil {
+		return nil, nil, err
+	}
+	turn, err := gs.RewindLastTurn()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := p.storage.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventRewound}), gs.ID, gs); err != nil {
+		return nil, nil, fmt.Errorf("failed to save rewound game state: %w", err)
+	}
+	return gs, turn, nil
+}
+
+// SuggestChoices runs a backend-model pass to extract suggested next actions
+// for games in choices mode. Errors are logged and return nil so that
+// narration is never blocked by a failed suggestion.
+func (p *ChatProcessor) SuggestChoices(ctx context.Context, gs *state.GameState, responseMessage string) []string {
+	currentStateJSON, err := json.Marshal(prompts.ToBackgroundPromptState(gs))
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to marshal game state for choices", "error", err, "game_state_id", gs.ID.String())
+		return nil
+	}
+
+	messages := []chat.ChatMessage{
+		{
+			Role:    chat.ChatRoleSystem,
+			Content: prompts.ChoicesPrompt,
+		},
+		{
+			Role:    chat.ChatRoleSystem,
+			Content: fmt.Sprintf("Current game state: %s", string(currentStateJSON)),
+		},
+		{
+			Role:    chat.ChatRoleUser,
+			Content: fmt.Sprintf("Latest narration:\n%s\n\nSuggest the player's next actions as JSON.", responseMessage),
+		},
+	}
+
+	choicesCtx, cancel := context.WithTimeout(p.llmContext(ctx, gs), 15*time.Second)
+	defer cancel()
+
+	choices, err := p.llmService.SuggestChoices(choicesCtx, messages)
+	if err != nil {
+		p.logger.WarnContext(ctx, "Failed to suggest choices", "error", err, "game_state_id", gs.ID.String())
+		return nil
+	}
+	return choices
+}
+
+// StartDelta begins the background gamestate delta for a turn before its narration is
+// written, so everything that doesn't depend on the narration overlaps with it. The run
+// waits until UpdateGameStateAfterStream passes it the narration; call Abort if the turn
+// fails first. Returns nil for ended games, which get no delta.
+func (p *ChatProcessor) StartDelta(ctx context.Context, gs *state.GameState, userMessage string, kind state.TurnKind) *DeltaRun {
+	if gs.IsEnded {
+		return nil
+	}
+
+	// Snapshot the state now; the caller goes on to change gs while the run waits, so
+	// the run reads its own copy
+	before, err := p.snapshotBefore(gs)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to marshal current game state for gamestate delta", "error", err, "game_state_id", gs.ID.String())
+		return nil
+	}
+	turnGS, err := gs.DeepCopy()
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to copy game state for gamestate delta", "error", err, "game_state_id", gs.ID.String())
+		return nil
+	}
+
+	// A retried or redelivered request must not apply its delta twice
+	key := state.DeltaKey(logger.RequestIDFromContext(ctx), gs.TurnCounter, kind)
+
+	// The run outlives the request, keeping only its values, such as the request ID for logs
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	run := &DeltaRun{
+		gameStateID: gs.ID,
+		narration:   make(chan string, 1),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	p.postDelta(run, func() {
+		p.syncGameState(ctx, turnGS, before, key, userMessage, run.narration, kind)
 	})
+	return run
+}
 
-	if err := p.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
-		return fmt.Errorf("failed to save game state after streaming: %w", err)
+// beforeState is the game state before a turn, serialized for the reducer
+type beforeState struct {
+	full    []byte
+	compact []byte // Without descriptions and stats; nil unless compact deltas are enabled
+}
+
+// snapshotBefore serializes the game state for the reducer, in compact form too if enabled
+func (p *ChatProcessor) snapshotBefore(gs *state.GameState) (beforeState, error) {
+	ps := prompts.ToBackgroundPromptState(gs)
+	full, err := json.Marshal(ps)
+	if err != nil {
+		return beforeState{}, err
+	}
+	before := beforeState{full: full}
+	if p.compactAt > 0 {
+		if before.compact, err = json.Marshal(ps.Compact()); err != nil {
+			return beforeState{}, err
+		}
 	}
+	return before, nil
+}
 
-	// Start background gamestate delta update if game is not ended
-	if !gs.IsEnded {
-		go p.syncGameState(metaCtx, gs, userMessage, responseMessage)
+// forVersion returns the snapshot in the shape of the prompt state version a scenario
+// is pinned to
+func (b beforeState) forVersion(version int) (beforeState, error) {
+	full, err := prompts.PromptStateJSONForVersion(b.full, version)
+	if err != nil {
+		return beforeState{}, err
 	}
+	converted := beforeState{full: full}
+	if b.compact != nil {
+		if converted.compact, err = prompts.PromptStateJSONForVersion(b.compact, version); err != nil {
+			return beforeState{}, err
+		}
+	}
+	return converted, nil
+}
 
-	p.logger.Debug("Game state updated after streaming", "game_state_id", gs.ID.String())
-	return nil
+// forNarration returns the state to send with a narration, and whether it is the compact one
+func (b beforeState) forNarration(narration string, compactAt int) ([]byte, bool) {
+	if b.compact != nil && compactAt > 0 && len(narration) >= compactAt {
+		return b.compact, true
+	}
+	return b.full, false
 }
 
-// syncGameState runs in the background to extract and update the stateful parts of gamestate
-func (p *ChatProcessor) syncGameState(ctx context.Context, gs *state.GameState, userMessage string, responseMessage string) {
+// syncGameState runs in the background to extract and update the stateful parts of gamestate.
+// It prepares the reducer request, then waits for the turn's narration before sending it.
+// Only player turns advance the turn counters.
+func (p *ChatProcessor) syncGameState(ctx context.Context, gs *state.GameState, before beforeState, key, userMessage string, narration <-chan string, kind state.TurnKind) {
 	start := time.Now()
-	p.logger.Debug("Starting background game gamestate delta", "game_state_id", gs.ID.String(), "response", responseMessage)
-	defer func() {
-		p.metaCancelMu.Lock()
-		delete(p.metaCancel, gs.ID)
-		p.metaCancelMu.Unlock()
-	}()
+	p.logger.DebugContext(ctx, "Starting background game gamestate delta", "game_state_id", gs.ID.String())
 
-	currentStateJSON, err := json.Marshal(prompts.ToBackgroundPromptState(gs))
+	s, err := p.storage.GetScenario(ctx, gs.Scenario)
 	if err != nil {
-		p.logger.Error("Failed to marshal current game state for gamestate delta", "error", err, "game_state_id", gs.ID.String())
+		p.logger.ErrorContext(ctx, "Failed to get scenario from storage", "error", err, "game_state_id", gs.ID.String())
 		return
 	}
 
-	s, err := p.storage.GetScenario(ctx, gs.Scenario)
-	if err != nil {
-		p.logger.Error("Failed to get scenario from storage", "error", err, "game_state_id", gs.ID.String())
+	// Contingency rules may be tuned to an older shape of the state
+	if before, err = before.forVersion(s.PromptStateVersion); err != nil {
+		p.logger.ErrorContext(ctx, "Failed to shape game state for gamestate delta", "error", err, "game_state_id", gs.ID.String())
 		return
 	}
 
@@ -271,20 +788,50 @@ func (p *ChatProcessor) syncGameState(ctx context.Context, gs *state.GameState,
 		contingencyRules = append(contingencyRules, s.Scenes[gs.SceneName].ContingencyRules...)
 	}
 
+	reducerContent, reducerVersion := p.reducerPrompt(gs, contingencyRules)
 	messages := []chat.ChatMessage{
 		{
 			Role:    chat.ChatRoleSystem,
-			Content: fmt.Sprintf(prompts.ReducerPrompt, strings.Join(contingencyRules, "\n- ")),
+			Content: reducerContent,
 		},
 		{
 			Role:    chat.ChatRoleSystem,
-			Content: fmt.Sprintf("BEFORE game state: %s", string(currentStateJSON)),
-		},
-		{
-			Role:    chat.ChatRoleUser,
-			Content: userMessage,
+			Content: fmt.Sprintf("BEFORE game state: %s", string(before.full)),
 		},
 	}
+	const beforeStateIndex = 1
+
+	// Story event prompts are written by the scenario author, so only player input is normalized
+	reducerMessage := userMessage
+	if kind != state.TurnSystem {
+		var note string
+		reducerMessage, note = p.normalizeInput(ctx, gs, userMessage)
+		if note != "" {
+			messages = append(messages, chat.ChatMessage{Role: chat.ChatRoleSystem, Content: note})
+		}
+	}
+	messages = append(messages, chat.ChatMessage{
+		Role:    chat.ChatRoleUser,
+		Content: reducerMessage,
+	})
+
+	var responseMessage string
+	select {
+	case responseMessage = <-narration:
+	case <-ctx.Done():
+		p.logger.DebugContext(ctx, "Gamestate delta cancelled before narration completed", "game_state_id", gs.ID.String())
+		return
+	}
+	narrated := time.Now()
+
+	if stateJSON, compact := before.forNarration(responseMessage, p.compactAt); compact {
+		messages[beforeStateIndex].Content = fmt.Sprintf("BEFORE game state: %s", string(stateJSON))
+		p.logger.DebugContext(ctx, "Using compact state for gamestate delta",
+			"game_state_id", gs.ID.String(),
+			"narration_chars", len(responseMessage),
+			"state_bytes", len(stateJSON),
+			"full_state_bytes", len(before.full))
+	}
 
 	// Add the narrator response followed by a user extraction request.
 	// Some LLM providers (e.g. Venice) reject a conversation whose last message
@@ -302,7 +849,16 @@ func (p *ChatProcessor) syncGameState(ctx context.Context, gs *state.GameState,
 		},
 	)
 
-	metaCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	// In annotate mode, the narration is checked against the state while the reducer runs
+	var narrationIssues chan []state.NarrationIssue
+	if p.consistency == config.ConsistencyAnnotate {
+		narrationIssues = make(chan []state.NarrationIssue, 1)
+		go func() {
+			narrationIssues <- p.checkNarration(ctx, gs, before.full, userMessage, responseMessage)
+		}()
+	}
+
+	metaCtx, cancel := context.WithTimeout(p.llmContext(ctx, gs), 30*time.Second)
 	defer cancel()
 
 	// Send the gamestate delta request to the LLM (with one retry on error)
@@ -313,22 +869,22 @@ func (p *ChatProcessor) syncGameState(ctx context.Context, gs *state.GameState,
 	maxAttempts := 2
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		if attempt > 1 {
-			p.logger.Info("Retrying gamestate delta extraction", "game_state_id", gs.ID.String(), "attempt", attempt)
+			p.logger.InfoContext(ctx, "Retrying gamestate delta extraction", "game_state_id", gs.ID.String(), "attempt", attempt)
 		}
 
-		p.logger.Debug("Sending gamestate delta request to LLM", "game_state_id", gs.ID.String(), "attempt", attempt)
+		p.logger.DebugContext(ctx, "Sending gamestate delta request to LLM", "game_state_id", gs.ID.String(), "attempt", attempt)
 		delta, backendModel, deltaErr = p.llmService.DeltaUpdate(metaCtx, messages)
 
 		if deltaErr == nil {
-			p.logger.Debug("Received gamestate delta from LLM", "game_state_id", gs.ID.String(), "delta", delta, "backend_model", backendModel)
+			p.logger.DebugContext(ctx, "Received gamestate delta from LLM", "game_state_id", gs.ID.String(), "delta", delta, "backend_model", backendModel, "reducer", reducerVersion)
 			break
 		}
 
 		// Log error and retry if not the last attempt
 		if attempt < maxAttempts {
-			p.logger.Warn("Gamestate delta extraction failed, will retry", "error", deltaErr, "game_state_id", gs.ID.String(), "attempt", attempt)
+			p.logger.WarnContext(ctx, "Gamestate delta extraction failed, will retry", "error", deltaErr, "game_state_id", gs.ID.String(), "attempt", attempt)
 		} else {
-			p.logger.Error("Failed to get meta extraction response from LLM after retries", "error", deltaErr, "game_state_id", gs.ID.String(), "attempts", maxAttempts)
+			p.logger.ErrorContext(ctx, "Failed to get meta extraction response from LLM after retries", "error", deltaErr, "game_state_id", gs.ID.String(), "attempts", maxAttempts)
 			return
 		}
 	}
@@ -339,55 +895,186 @@ func (p *ChatProcessor) syncGameState(ctx context.Context, gs *state.GameState,
 
 	latestGS, err := p.storage.LoadGameState(metaCtx, gs.ID)
 	if err != nil {
-		p.logger.Error("Failed to load latest game state for gamestate delta", "error", err, "game_state_id", gs.ID.String())
+		p.logger.ErrorContext(ctx, "Failed to load latest game state for gamestate delta", "error", err, "game_state_id", gs.ID.String())
 		return
 	}
 	if latestGS == nil {
-		p.logger.Warn("Game state not found during gamestate delta", "game_state_id", gs.ID.String())
+		p.logger.WarnContext(ctx, "Game state not found during gamestate delta", "game_state_id", gs.ID.String())
 		return
 	}
-
-	// Increment turn counters on the latest game state
-	if !latestGS.IsEnded {
-		latestGS.IncrementTurnCounters()
-	}
+	latestGS.MigrateIDs(s) // in case the scenario changed mid-turn
 
 	// Use DeltaWorker to handle all delta application logic
 	worker := state.NewDeltaWorker(latestGS, delta, s, p.logger).
 		WithQueue(p.chatQueue).
 		WithStorage(p.storage).
-		WithContext(metaCtx)
+		WithContext(metaCtx).
+		WithIdempotencyKey(key)
+	if worker.AlreadyApplied() {
+		p.logger.WarnContext(ctx, "Skipping gamestate delta that was already applied", "game_state_id", gs.ID.String(), "key", key)
+		return
+	}
+
+	// Snapshot state before the delta so a turn receipt can be recorded
+	beforeGS, err := latestGS.DeepCopy()
+	if err != nil {
+		p.logger.WarnContext(ctx, "Failed to snapshot game state for turn receipt", "error", err, "game_state_id", gs.ID.String())
+	}
+
+	// Increment turn counters on the latest game state
+	if !latestGS.IsEnded && kind.AdvancesTurn() {
+		latestGS.IncrementTurnCounters()
+	}
+
+	// Repair or drop anything in the model's delta that doesn't fit the schema or the game
+	issues := worker.Validate()
+	for _, issue := range issues {
+		p.logger.WarnContext(ctx, "Invalid gamestate delta field",
+			"game_state_id", latestGS.ID.String(),
+			"path", issue.Path,
+			"value", issue.Value,
+			"reason", issue.Reason,
+			"fix", issue.Fix)
+	}
 
 	// Apply vars first (before evaluating conditionals)
 	worker.ApplyVars()
 
 	// Apply the delta from the LLM reducer to the game state
 	if err := worker.Apply(); err != nil {
-		p.logger.Error("Failed to apply initial delta", "error", err, "game_state_id", latestGS.ID.String())
+		p.logger.ErrorContext(ctx, "Failed to apply initial delta", "error", err, "game_state_id", latestGS.ID.String())
 		return
 	}
 
+	// Lore mentioned this turn goes into the player's codex before conditionals are
+	// evaluated, so lore_known conditions see it on the same turn. Story event prompts are
+	// unseen by the player, so only their narration counts.
+	seen := []string{responseMessage}
+	if kind != state.TurnSystem {
+		seen = append(seen, userMessage)
+	}
+	if found := latestGS.DiscoverLore(s.MatchLore(seen...)...); len(found) > 0 {
+		p.logger.DebugContext(ctx, "Discovered lore", "game_state_id", latestGS.ID.String(), "entries", found)
+	}
+	// NPCs named this turn rank higher when a crowded location's NPCs are summarized
+	latestGS.NoteNPCInteractions(seen...)
+
 	// Now recursively evaluate and apply conditionals until none trigger
-	p.applyConditionalsCascade(worker, latestGS.ID)
+	firedConditionals := p.applyConditionalsCascade(ctx, worker, latestGS.ID)
+
+	if beforeGS != nil {
+		receipt := state.NewTurnReceipt(beforeGS, latestGS, firedConditionals)
+		receipt.DeltaIssues = issues
+		receipt.Reducer = reducerVersion
+		if narrationIssues != nil {
+			receipt.NarrationIssues = <-narrationIssues
+		}
+		if kind.AdvancesTurn() {
+			latestGS.AddTurnReceipt(receipt)
+		} else {
+			latestGS.MergeTurnReceipt(receipt)
+		}
+	}
 
-	// Save the updated game state
-	if err := p.storage.SaveGameState(metaCtx, latestGS.ID, latestGS); err != nil {
-		p.logger.Error("Failed to save updated game state after meta extraction", "error", err, "game_state_id", latestGS.ID.String())
+	// A newer turn has taken over; it loads the game after this run returns
+	if ctx.Err() != nil {
+		p.logger.DebugContext(ctx, "Gamestate delta superseded before saving", "game_state_id", gs.ID.String())
 		return
 	}
 
-	p.logger.Debug("Updated game meta",
+	// Save the updated game state, recording the delta and what it set off
+	saveCtx := state.WithGameEvent(metaCtx, state.GameEvent{Kind: state.EventDelta, Delta: delta, ConditionalsFired: firedConditionals, Reducer: reducerVersion})
+	if err := p.storage.SaveGameState(saveCtx, latestGS.ID, latestGS); err != nil {
+		p.logger.ErrorContext(ctx, "Failed to save updated game state after meta extraction", "error", err, "game_state_id", latestGS.ID.String())
+		return
+	}
+	if p.deltaApplied != nil {
+		p.deltaApplied(metaCtx, latestGS)
+	}
+
+	if p.analytics != nil && beforeGS != nil {
+		if err := p.analytics.RecordTurn(metaCtx, s, beforeGS, latestGS, firedConditionals); err != nil {
+			p.logger.WarnContext(ctx, "Failed to record turn analytics", "error", err, "game_state_id", latestGS.ID.String())
+		}
+	}
+
+	// Build the next turn's system prompt now, while the player reads
+	p.prewarmPrompt(latestGS, s)
+
+	p.logger.DebugContext(ctx, "Updated game meta",
 		"game_state_id", gs.ID.String(),
 		"delta", delta,
-		"duration_s", time.Since(start).Seconds(),
+		"duration_s", time.Since(narrated).Seconds(), // what the player waits for after the narration
+		"prepared_s", narrated.Sub(start).Seconds(), // time overlapped with the narration
+
 		"backend_model", backendModel,
+		"turn_kind", kind,
 	)
 }
 
+// reducerPrompt returns the reducer prompt for the model that runs the gamestate delta,
+// with the contingency rules filled in, and the prompt's version. A model's reducer
+// override in config replaces the built-in prompt.
+func (p *ChatProcessor) reducerPrompt(gs *state.GameState, contingencyRules []string) (string, string) {
+	modelName := p.backendModel
+	if modelName == "" {
+		modelName = gs.ModelName
+	}
+	if reducer := p.models.Lookup(modelName).Reducer; reducer != nil {
+		return reducer.Render(contingencyRules), reducer.Version
+	}
+	return fmt.Sprintf(prompts.ReducerPrompt, strings.Join(contingencyRules, "\n- ")), prompts.ReducerPromptVersion
+}
+
+// normalizeInput prepares a player's message for the gamestate delta. English messages
+// are returned unchanged. Others are translated to English when translation is enabled;
+// if it is disabled or fails, the original message is returned with a note telling the
+// reducer which language it is in.
+func (p *ChatProcessor) normalizeInput(ctx context.Context, gs *state.GameState, message string) (string, string) {
+	lang := chat.DetectLanguage(message)
+	if lang == chat.LanguageEnglish {
+		return message, ""
+	}
+	note := fmt.Sprintf(prompts.InputLanguageNote, chat.LanguageName(lang))
+	if !p.translate {
+		return message, note
+	}
+
+	translateCtx, cancel := context.WithTimeout(p.llmContext(ctx, gs), 15*time.Second)
+	defer cancel()
+
+	resp, err := p.llmService.Chat(translateCtx, []chat.ChatMessage{
+		{Role: chat.ChatRoleSystem, Content: prompts.TranslateInputPrompt},
+		{Role: chat.ChatRoleUser, Content: message},
+	}, 0)
+	if err != nil || strings.TrimSpace(resp.Message) == "" {
+		p.logger.WarnContext(ctx, "Failed to translate player message for gamestate delta", "error", err, "game_state_id", gs.ID.String(), "language", lang)
+		return message, note
+	}
+
+	translated := strings.TrimSpace(resp.Message)
+	p.logger.DebugContext(ctx, "Translated player message for gamestate delta", "game_state_id", gs.ID.String(), "language", lang, "original", message, "translated", translated)
+	return translated, ""
+}
+
+// prewarmPrompt builds the system prompt for the game's next turn from the state just saved
+func (p *ChatProcessor) prewarmPrompt(gs *state.GameState, s *scenario.Scenario) {
+	// A copy drops transient fields, so the prompt matches what the next turn loads
+	saved, err := gs.DeepCopy()
+	if err == nil {
+		err = p.prefixes.Prewarm(saved, s)
+	}
+	if err != nil {
+		p.logger.Warn("Failed to prewarm system prompt", "error", err, "game_state_id", gs.ID.String())
+	}
+}
+
 // applyConditionalsCascade recursively evaluates and applies conditionals until none trigger
-func (p *ChatProcessor) applyConditionalsCascade(worker *state.DeltaWorker, gameStateID uuid.UUID) {
+// Returns the IDs of all conditionals that fired
+func (p *ChatProcessor) applyConditionalsCascade(ctx context.Context, worker *state.DeltaWorker, gameStateID uuid.UUID) []string {
 	const maxConditionalIterations = 10
 	allTriggeredConditionals := make(map[string]bool) // Track all triggered conditional IDs
+	var fired []string
 
 	for iteration := range maxConditionalIterations {
 		// Evaluate conditionals based on current game state
@@ -403,13 +1090,14 @@ func (p *ChatProcessor) applyConditionalsCascade(worker *state.DeltaWorker, game
 		for conditionalID := range triggeredConditionals {
 			if !allTriggeredConditionals[conditionalID] {
 				allTriggeredConditionals[conditionalID] = true
+				fired = append(fired, conditionalID)
 				foundNew = true
 			}
 		}
 
 		if !foundNew {
 			// All conditionals were already triggered, avoid infinite loop
-			p.logger.Warn("Conditionals re-triggered, stopping to avoid loop",
+			p.logger.WarnContext(ctx, "Conditionals re-triggered, stopping to avoid loop",
 				"game_state_id", gameStateID.String(),
 				"iteration", iteration)
 			break
@@ -420,24 +1108,24 @@ func (p *ChatProcessor) applyConditionalsCascade(worker *state.DeltaWorker, game
 
 		// Apply the conditional delta to game state
 		if err := worker.Apply(); err != nil {
-			p.logger.Error("Failed to apply conditional delta",
+			p.logger.ErrorContext(ctx, "Failed to apply conditional delta",
 				"error", err,
 				"game_state_id", gameStateID.String(),
 				"iteration", iteration)
-			return
+			return fired
 		}
 
 		// Log triggered conditionals
 		for conditionalID, conditional := range triggeredConditionals {
 			if conditional.Then.SceneChange != nil && conditional.Then.SceneChange.To != "" {
-				p.logger.Info("Conditional scene change",
+				p.logger.InfoContext(ctx, "Conditional scene change",
 					"game_state_id", gameStateID.String(),
 					"conditional_id", conditionalID,
 					"to_scene", conditional.Then.SceneChange.To,
 					"iteration", iteration)
 			}
 			if conditional.Then.GameEnded != nil {
-				p.logger.Info("Conditional game ended",
+				p.logger.InfoContext(ctx, "Conditional game ended",
 					"game_state_id", gameStateID.String(),
 					"conditional_id", conditionalID,
 					"ended", *conditional.Then.GameEnded,
@@ -449,7 +1137,7 @@ func (p *ChatProcessor) applyConditionalsCascade(worker *state.DeltaWorker, game
 				if len(prompt) < previewLen {
 					previewLen = len(prompt)
 				}
-				p.logger.Info("Conditional prompt triggered",
+				p.logger.InfoContext(ctx, "Conditional prompt triggered",
 					"game_state_id", gameStateID.String(),
 					"conditional_id", conditionalID,
 					"prompt_preview", prompt[:previewLen]+"...",
@@ -458,11 +1146,12 @@ func (p *ChatProcessor) applyConditionalsCascade(worker *state.DeltaWorker, game
 		}
 
 		if iteration == maxConditionalIterations-1 {
-			p.logger.Warn("Max conditional iterations reached",
+			p.logger.WarnContext(ctx, "Max conditional iterations reached",
 				"game_state_id", gameStateID.String(),
 				"iterations", maxConditionalIterations)
 		}
 	}
+	return fired
 }
 
 // GetGameState loads a game state by ID
@@ -476,3 +1165,11 @@ func (p *ChatProcessor) GetGameState(ctx context.Context, gameStateID uuid.UUID)
 	}
 	return gs, nil
 }
+
+// SaveGameState saves a game state
+func (p *ChatProcessor) SaveGameState(ctx context.Context, gs *state.GameState) error {
+	if err := p.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		return fmt.Errorf("failed to save game state: %w", err)
+	}
+	return nil
+}
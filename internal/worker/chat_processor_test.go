@@ -2,17 +2,27 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/logger"
 	"github.com/jwebster45206/story-engine/internal/services"
 	"github.com/jwebster45206/story-engine/pkg/actor"
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/prompts"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
 	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
 )
 
 func TestApplyConditionalsCascade_NoConditionals(t *testing.T) {
@@ -35,7 +45,7 @@ func TestApplyConditionalsCascade_NoConditionals(t *testing.T) {
 	worker := state.NewDeltaWorker(gs, delta, s, logger)
 
 	// Execute
-	processor.applyConditionalsCascade(worker, gs.ID)
+	processor.applyConditionalsCascade(context.Background(), worker, gs.ID)
 
 	// No conditionals should trigger, function should return cleanly
 	// (This is mainly testing that it doesn't panic or error)
@@ -80,7 +90,7 @@ func TestApplyConditionalsCascade_OneIteration(t *testing.T) {
 	worker := state.NewDeltaWorker(gs, delta, s, logger)
 
 	// Execute
-	processor.applyConditionalsCascade(worker, gs.ID)
+	processor.applyConditionalsCascade(context.Background(), worker, gs.ID)
 
 	// Verify the conditional triggered and applied
 	if gs.IsEnded != true {
@@ -144,7 +154,7 @@ func TestApplyConditionalsCascade_TwoIterations(t *testing.T) {
 	worker := state.NewDeltaWorker(gs, delta, s, logger)
 
 	// Execute
-	processor.applyConditionalsCascade(worker, gs.ID)
+	processor.applyConditionalsCascade(context.Background(), worker, gs.ID)
 
 	// Verify both conditionals triggered in cascade
 	if achievement := gs.Vars["achievement_unlocked"]; achievement != "true" {
@@ -164,21 +174,55 @@ func TestApplyConditionalsCascade_TwoIterations(t *testing.T) {
 
 // stubLLMService captures the messages slice passed to Chat() and no-ops everything else.
 type stubLLMService struct {
+	mu               sync.Mutex // variants call Chat concurrently
 	capturedMessages []chat.ChatMessage
 	capturedTemp     float64
+	choicesErr       error
+	delta            *conditionals.GameStateDelta
+	deltaMessages    []chat.ChatMessage
+	streamChunks     []string
+	streamErr        error // ends the stream in place of the final chunk
+	narrationIssues  []state.NarrationIssue
+	reply            string // Chat response; "ok" when empty
 }
 
 func (s *stubLLMService) InitModel(_ context.Context, _ string) error { return nil }
 func (s *stubLLMService) Chat(_ context.Context, messages []chat.ChatMessage, temperature float64) (*chat.ChatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.capturedMessages = messages
 	s.capturedTemp = temperature
+	if s.reply != "" {
+		return &chat.ChatResponse{Message: s.reply}, nil
+	}
 	return &chat.ChatResponse{Message: "ok"}, nil
 }
 func (s *stubLLMService) ChatStream(_ context.Context, _ []chat.ChatMessage, _ float64) (<-chan services.StreamChunk, error) {
-	return nil, nil
+	ch := make(chan services.StreamChunk, len(s.streamChunks)+1)
+	for _, c := range s.streamChunks {
+		ch <- services.StreamChunk{Content: c}
+	}
+	if s.streamErr != nil {
+		ch <- services.StreamChunk{Error: s.streamErr, Done: true}
+		close(ch)
+		return ch, nil
+	}
+	ch <- services.StreamChunk{Done: true}
+	close(ch)
+	return ch, nil
 }
-func (s *stubLLMService) DeltaUpdate(_ context.Context, _ []chat.ChatMessage) (*conditionals.GameStateDelta, string, error) {
-	return nil, "", nil
+func (s *stubLLMService) DeltaUpdate(_ context.Context, messages []chat.ChatMessage) (*conditionals.GameStateDelta, string, error) {
+	s.deltaMessages = messages
+	return s.delta, "", nil
+}
+func (s *stubLLMService) SuggestChoices(_ context.Context, _ []chat.ChatMessage) ([]string, error) {
+	if s.choicesErr != nil {
+		return nil, s.choicesErr
+	}
+	return []string{"Look around", "Go north"}, nil
+}
+func (s *stubLLMService) CheckNarration(_ context.Context, _ []chat.ChatMessage) ([]state.NarrationIssue, error) {
+	return s.narrationIssues, nil
 }
 
 // stubStorage returns a preset GameState and Scenario; all writes are no-ops.
@@ -195,13 +239,24 @@ func (s *stubStorage) SaveGameState(_ context.Context, _ uuid.UUID, _ *state.Gam
 func (s *stubStorage) LoadGameState(_ context.Context, _ uuid.UUID) (*state.GameState, error) {
 	return s.gs, nil
 }
-func (s *stubStorage) DeleteGameState(_ context.Context, _ uuid.UUID) error { return nil }
+func (s *stubStorage) DeleteGameState(_ context.Context, _ uuid.UUID) error       { return nil }
+func (s *stubStorage) SaveNarrator(_ context.Context, _ *scenario.Narrator) error { return nil }
+func (s *stubStorage) DeleteNarrator(_ context.Context, _ string) error           { return nil }
+func (s *stubStorage) SavePCSpec(_ context.Context, _ *actor.PCSpec) error        { return nil }
+func (s *stubStorage) DeletePCSpec(_ context.Context, _ string) error             { return nil }
+func (s *stubStorage) ListGameStateIDs(_ context.Context) ([]uuid.UUID, error) {
+	return nil, nil
+}
 func (s *stubStorage) ListScenarios(_ context.Context) (map[string]string, error) {
 	return nil, nil
 }
 func (s *stubStorage) GetScenario(_ context.Context, _ string) (*scenario.Scenario, error) {
 	return s.sc, nil
 }
+func (s *stubStorage) SaveScenario(_ context.Context, _ string, _ *scenario.Scenario) error {
+	return nil
+}
+func (s *stubStorage) DeleteScenario(_ context.Context, _ string) error { return nil }
 func (s *stubStorage) GetNarrator(_ context.Context, _ string) (*scenario.Narrator, error) {
 	return nil, nil
 }
@@ -441,3 +496,876 @@ func TestProcessChatRequest_UsesSceneTemperature(t *testing.T) {
 		t.Errorf("expected scene temperature %f, got %f", sceneTemp, llm.capturedTemp)
 	}
 }
+
+func TestNarrationLength(t *testing.T) {
+	s := &scenario.Scenario{
+		NarrationLength: &scenario.NarrationLength{MaxTokens: 300, MaxSentences: 6},
+		Scenes: map[string]scenario.Scene{
+			"chase": {Story: "Chase", NarrationLength: &scenario.NarrationLength{MaxSentences: 2}},
+		},
+	}
+	tests := []struct {
+		name  string
+		scene string
+		req   chat.ChatRequest
+		want  scenario.NarrationLength
+	}{
+		{name: "scenario", want: scenario.NarrationLength{MaxTokens: 300, MaxSentences: 6}},
+		{name: "scene overrides scenario", scene: "chase", want: scenario.NarrationLength{MaxTokens: 300, MaxSentences: 2}},
+		{name: "request overrides scene", scene: "chase", req: chat.ChatRequest{MaxSentences: 4}, want: scenario.NarrationLength{MaxTokens: 300, MaxSentences: 4}},
+		{name: "request tokens", req: chat.ChatRequest{MaxTokens: 100}, want: scenario.NarrationLength{MaxTokens: 100, MaxSentences: 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{SceneName: tt.scene}
+			if got := narrationLength(gs, s, tt.req); got != tt.want {
+				t.Errorf("narrationLength() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessChatRequest_MaxSentences(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 10)
+	llm.reply = "The ship lists. Water pours in. Everyone runs."
+	req.MaxSentences = 2
+
+	resp, err := processor.ProcessChatRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessChatRequest returned error: %v", err)
+	}
+	if want := "The ship lists. Water pours in."; resp.Message != want {
+		t.Errorf("narration = %q, want %q", resp.Message, want)
+	}
+	lengthPrompt := fmt.Sprintf(prompts.MaxSentencesPrompt, 2)
+	if !slices.ContainsFunc(llm.capturedMessages, func(m chat.ChatMessage) bool { return m.Content == lengthPrompt }) {
+		t.Errorf("expected the narrator to be asked for %q", lengthPrompt)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Choices mode
+// ---------------------------------------------------------------------------
+
+func TestSuggestChoices(t *testing.T) {
+	tests := []struct {
+		name     string
+		llmErr   error
+		expected []string
+	}{
+		{
+			name:     "returns suggested actions",
+			expected: []string{"Look around", "Go north"},
+		},
+		{
+			name:     "LLM error returns nil",
+			llmErr:   fmt.Errorf("backend unavailable"),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, llm, _ := newTestSetup(2, 10)
+			llm.choicesErr = tt.llmErr
+			gs := &state.GameState{ID: uuid.New(), Location: "start"}
+
+			choices := processor.SuggestChoices(context.Background(), gs, "The gate creaks open.")
+			if len(choices) != len(tt.expected) {
+				t.Fatalf("expected %d choices, got %d: %v", len(tt.expected), len(choices), choices)
+			}
+			for i := range tt.expected {
+				if choices[i] != tt.expected[i] {
+					t.Errorf("choice %d: expected %q, got %q", i, tt.expected[i], choices[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProcessChatRequest_ChoicesOnlyInChoicesMode(t *testing.T) {
+	gsID := uuid.New()
+	gs := &state.GameState{
+		ID:          gsID,
+		Scenario:    "test.json",
+		ChatHistory: makeHistory(2),
+		IsEnded:     true, // ended games never get choices
+		ChoicesMode: true,
+		Vars:        make(map[string]string),
+	}
+	sc := &scenario.Scenario{Name: "Test", Story: "A test story", Rating: scenario.RatingPG}
+	processor := NewChatProcessor(&stubStorage{gs: gs, sc: sc}, &stubLLMService{}, nil, slog.Default(), 10)
+
+	resp, err := processor.ProcessChatRequest(context.Background(), chat.ChatRequest{GameStateID: gsID, Message: "hello"})
+	if err != nil {
+		t.Fatalf("ProcessChatRequest returned error: %v", err)
+	}
+	if len(resp.Choices) != 0 {
+		t.Errorf("expected no choices for ended game, got %v", resp.Choices)
+	}
+	if resp.State == nil || !resp.State.IsEnded {
+		t.Errorf("expected state summary reporting ended game, got %+v", resp.State)
+	}
+}
+
+// TestProcessChatStream_NonStreamingModel verifies that models registered without
+// streaming support get the full Chat() response delivered as a single final chunk.
+func TestProcessChatStream_NonStreamingModel(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 4)
+	processor.storage.(*stubStorage).gs.ModelName = "batch-model"
+	processor.WithModelRegistry(config.NewModelRegistry([]config.ModelCapabilities{{Name: "batch-model", Streaming: false}}))
+
+	streamChan, _, err := processor.ProcessChatStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessChatStream returned error: %v", err)
+	}
+
+	var chunks []services.StreamChunk
+	for chunk := range streamChan {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 1 || chunks[0].Content != "ok" || !chunks[0].Done {
+		t.Errorf("expected one final chunk with the chat response, got %+v", chunks)
+	}
+	if len(llm.capturedMessages) == 0 {
+		t.Error("expected messages to be sent through Chat()")
+	}
+}
+
+// TestProcessChatStream_FiltersNarration verifies that streamed narration goes through
+// the post-processing pipeline, with the held-back remainder in the final chunk.
+func TestProcessChatStream_FiltersNarration(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 4)
+	processor.storage.(*stubStorage).sc.Rating = scenario.RatingG
+	llm.streamChunks = []string{"**Gib", "bs:** What the ", "hell?\n<rules>\nBe ", "nice.\n</rules>\nThe ", "end."}
+
+	streamChan, _, err := processor.ProcessChatStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessChatStream returned error: %v", err)
+	}
+
+	var full string
+	var last services.StreamChunk
+	for chunk := range streamChan {
+		full += chunk.Content
+		last = chunk
+	}
+	if want := "Gibbs: What the heck?\nThe end."; full != want {
+		t.Errorf("streamed narration = %q, want %q", full, want)
+	}
+	if !last.Done || last.Content != "The end." {
+		t.Errorf("final chunk = %+v, want remainder with Done", last)
+	}
+}
+
+func TestProcessChatRequest_NarrationMeta(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 4)
+	processor.WithNarrationMeta(true)
+	gs := processor.storage.(*stubStorage).gs
+	llm.reply = "Gibbs eyes the map.\n<turn_meta>{\"mood\": \"wary\", \"npcs_present\": [\"Gibbs\"], \"choices\": [\"Ask about the map\"]}</turn_meta>"
+
+	resp, err := processor.ProcessChatRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessChatRequest returned error: %v", err)
+	}
+	if resp.Message != "Gibbs eyes the map." {
+		t.Errorf("Expected the trailer removed from the narration, got %q", resp.Message)
+	}
+	if last := llm.capturedMessages[len(llm.capturedMessages)-1]; last.Content != prompts.NarrationMetaPrompt {
+		t.Errorf("Expected the narrator asked for a trailer, got last message %q", last.Content)
+	}
+	want := &chat.NarrationMeta{Mood: "wary", NPCsPresent: []string{"Gibbs"}, Choices: []string{"Ask about the map"}}
+	if got := gs.ChatHistory[len(gs.ChatHistory)-1].Meta; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the trailer stored with the narration, got %+v", got)
+	}
+}
+
+func TestProcessChatStream_NarrationMeta(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 4)
+	processor.WithNarrationMeta(true)
+	gs := processor.storage.(*stubStorage).gs
+	llm.streamChunks = []string{"The fog ", "lifts.\n<turn_", "meta>{\"mood\": ", "\"calm\"}</turn_meta>"}
+
+	streamChan, _, err := processor.ProcessChatStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessChatStream returned error: %v", err)
+	}
+	var full, trailer string
+	for chunk := range streamChan {
+		full += chunk.Content
+		trailer += chunk.Trailer
+	}
+	if full != "The fog lifts.\n" {
+		t.Errorf("Expected the trailer kept out of the stream, got %q", full)
+	}
+
+	if err := processor.UpdateGameStateAfterStream(context.Background(), gs, nil, req.Message, full, trailer, "", state.TurnPlayer); err != nil {
+		t.Fatalf("UpdateGameStateAfterStream returned error: %v", err)
+	}
+	if got := gs.ChatHistory[len(gs.ChatHistory)-1].Meta; got == nil || got.Mood != "calm" {
+		t.Errorf("Expected the trailer stored with the narration, got %+v", got)
+	}
+}
+
+// TestSalvagePartialStream verifies that narration streamed before an LLM error, including
+// text the post-processor held back, is kept as an incomplete turn.
+func TestSalvagePartialStream(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 4)
+	gs := processor.storage.(*stubStorage).gs
+	turns := len(gs.ChatHistory)
+	llm.streamChunks = []string{"The fog lifts.\nA ship ", "appears on the"}
+	llm.streamErr = errors.New("context deadline exceeded")
+
+	streamChan, _, err := processor.ProcessChatStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessChatStream returned error: %v", err)
+	}
+	var partial string
+	var streamErr error
+	for chunk := range streamChan {
+		partial += chunk.Content
+		if chunk.Error != nil {
+			streamErr = chunk.Error
+		}
+	}
+	if streamErr == nil {
+		t.Fatal("Expected the stream to end with an error")
+	}
+	if partial != "The fog lifts.\nA ship appears on the" {
+		t.Errorf("Expected the held-back text delivered with the error, got %q", partial)
+	}
+
+	if err := processor.SalvagePartialStream(context.Background(), gs, nil, req.Message, partial, state.TurnPlayer); err != nil {
+		t.Fatalf("SalvagePartialStream returned error: %v", err)
+	}
+	if len(gs.ChatHistory) != turns+2 {
+		t.Fatalf("Expected the turn added to the chat history, got %d messages", len(gs.ChatHistory))
+	}
+	last := gs.ChatHistory[len(gs.ChatHistory)-1]
+	if last.Role != chat.ChatRoleAgent || last.Content != partial || !last.Incomplete {
+		t.Errorf("Expected the partial narration kept and marked incomplete, got %+v", last)
+	}
+	if gs.ChatHistory[len(gs.ChatHistory)-2].Incomplete {
+		t.Errorf("Expected only the narration marked incomplete")
+	}
+}
+
+// TestProcessChatRequest_FiltersPlayerLanguage verifies that narration is filtered with
+// the configured word lists for the language the player writes in.
+func TestProcessChatRequest_FiltersPlayerLanguage(t *testing.T) {
+	filter := textfilter.NewProfanityFilter()
+	if err := filter.AddWords("es", "PG", map[string]string{"mierda": "caramba"}); err != nil {
+		t.Fatalf("AddWords returned error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{"Spanish player", "abro la puerta con la llave", "¡Caramba! What the heck?"},
+		{"English player", "I open the door with the key", "¡Mierda! What the heck?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, llm, req := newTestSetup(2, 4)
+			processor.WithProfanityFilter(filter)
+			llm.reply = "¡Mierda! What the hell?"
+			req.Message = tt.message
+
+			resp, err := processor.ProcessChatRequest(context.Background(), req)
+			if err != nil {
+				t.Fatalf("ProcessChatRequest returned error: %v", err)
+			}
+			if resp.Message != tt.expected {
+				t.Errorf("Message = %q, want %q", resp.Message, tt.expected)
+			}
+		})
+	}
+}
+
+// TestProcessChatRequest_Segments verifies that the response splits the processed
+// narration into prose and dialogue, recognising the game's NPCs by name.
+func TestProcessChatRequest_Segments(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 4)
+	processor.storage.(*stubStorage).gs.NPCs = map[string]actor.NPC{"bosun": {Name: "the bosun"}}
+	llm.reply = "The deck creaks.\n**The Bosun:** Hold fast!\nthe bosun: Steady."
+
+	resp, err := processor.ProcessChatRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessChatRequest returned error: %v", err)
+	}
+	want := []chat.Segment{
+		{Kind: chat.SegmentNarration, Text: "The deck creaks."},
+		{Kind: chat.SegmentDialogue, Speaker: "the bosun", Text: "Hold fast!"},
+		{Kind: chat.SegmentDialogue, Speaker: "the bosun", Text: "Steady."},
+	}
+	if !reflect.DeepEqual(resp.Segments, want) {
+		t.Errorf("Segments = %+v, want %+v", resp.Segments, want)
+	}
+}
+
+// TestProcessChatRequest_Trial verifies that a preview game's turn is counted and its
+// narration watermarked, while the chat history keeps the narration as written.
+func TestProcessChatRequest_Trial(t *testing.T) {
+	processor, llm, req := newTestSetup(0, 4)
+	gs := processor.storage.(*stubStorage).gs
+	gs.Trial = &state.Trial{MaxTurns: 3, TurnsUsed: 1}
+	llm.reply = "The deck creaks."
+
+	resp, err := processor.ProcessChatRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessChatRequest returned error: %v", err)
+	}
+	if want := "The deck creaks.\n\n[Preview: turn 2 of 3]"; resp.Message != want {
+		t.Errorf("Message = %q, want %q", resp.Message, want)
+	}
+	if gs.Trial.TurnsUsed != 2 {
+		t.Errorf("TurnsUsed = %d, want 2", gs.Trial.TurnsUsed)
+	}
+	if last := gs.ChatHistory[len(gs.ChatHistory)-1]; last.Content != "The deck creaks." {
+		t.Errorf("Expected the chat history without the watermark, got %q", last.Content)
+	}
+	if resp.State.TrialTurnsLeft == nil || *resp.State.TrialTurnsLeft != 1 {
+		t.Errorf("Expected 1 trial turn left in the summary, got %v", resp.State.TrialTurnsLeft)
+	}
+}
+
+func TestSyncGameState_TurnKinds(t *testing.T) {
+	tests := []struct {
+		name          string
+		kind          state.TurnKind
+		wantTurn      int
+		wantSceneTurn int
+		wantVars      map[string]string // vars_changed on the receipt for wantTurn
+	}{
+		{
+			name:          "player turn advances",
+			kind:          state.TurnPlayer,
+			wantTurn:      4,
+			wantSceneTurn: 2,
+			wantVars:      map[string]string{"lamp_lit": "true"},
+		},
+		{
+			name:          "free action does not advance",
+			kind:          state.TurnFree,
+			wantTurn:      3,
+			wantSceneTurn: 1,
+			wantVars:      map[string]string{"door_open": "true", "lamp_lit": "true"},
+		},
+		{
+			name:          "system turn does not advance",
+			kind:          state.TurnSystem,
+			wantTurn:      3,
+			wantSceneTurn: 1,
+			wantVars:      map[string]string{"door_open": "true", "lamp_lit": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{
+				ID:               uuid.New(),
+				Scenario:         "test.json",
+				TurnCounter:      3,
+				SceneTurnCounter: 1,
+				Vars:             map[string]string{"door_open": "true", "lamp_lit": "false"},
+				TurnReceipts:     []state.TurnReceipt{{Turn: 3, VarsChanged: map[string]string{"door_open": "true"}}},
+			}
+			llm := &stubLLMService{delta: &conditionals.GameStateDelta{SetVars: map[string]string{"lamp_lit": "true"}}}
+			processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{}}, llm, nil, slog.Default(), 0)
+
+			run := processor.StartDelta(context.Background(), gs, "I light the lamp", tt.kind)
+			run.finish("The lamp flickers to life.")
+			run.wait()
+
+			if gs.TurnCounter != tt.wantTurn || gs.SceneTurnCounter != tt.wantSceneTurn {
+				t.Errorf("Expected turn %d/%d, got %d/%d", tt.wantTurn, tt.wantSceneTurn, gs.TurnCounter, gs.SceneTurnCounter)
+			}
+			receipt, ok := gs.GetTurnReceipt(tt.wantTurn)
+			if !ok {
+				t.Fatalf("Expected a receipt for turn %d", tt.wantTurn)
+			}
+			if fmt.Sprint(receipt.VarsChanged) != fmt.Sprint(tt.wantVars) {
+				t.Errorf("Expected receipt vars %v, got %v", tt.wantVars, receipt.VarsChanged)
+			}
+		})
+	}
+}
+
+func TestSyncGameState_DiscoversLore(t *testing.T) {
+	sc := &scenario.Scenario{Lore: map[string]scenario.LoreEntry{
+		"tortuga": {Title: "Tortuga", Keywords: []string{"tortuga"}},
+		"kraken":  {Title: "The Kraken", Keywords: []string{"kraken"}},
+		"cove":    {Title: "Smuggler's Cove", Keywords: []string{"cove"}},
+	}}
+
+	tests := []struct {
+		name    string
+		kind    state.TurnKind
+		message string
+		want    []string
+	}{
+		{"player and narration", state.TurnPlayer, "I ask about Tortuga", []string{"cove", "kraken", "tortuga"}},
+		{"story event prompt is unseen", state.TurnSystem, "Describe Tortuga burning", []string{"cove", "kraken"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", DiscoveredLore: []string{"cove"}}
+			llm := &stubLLMService{delta: &conditionals.GameStateDelta{}}
+			processor := NewChatProcessor(&stubStorage{gs: gs, sc: sc}, llm, nil, slog.Default(), 0)
+
+			run := processor.StartDelta(context.Background(), gs, tt.message, tt.kind)
+			run.finish("Sailors whisper of the kraken and the cove.")
+			run.wait()
+
+			if fmt.Sprint(gs.DiscoveredLore) != fmt.Sprint(tt.want) {
+				t.Errorf("DiscoveredLore = %v, want %v", gs.DiscoveredLore, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncGameState_NormalizesInput(t *testing.T) {
+	spanishNote := fmt.Sprintf(prompts.InputLanguageNote, "Spanish")
+	tests := []struct {
+		name        string
+		message     string
+		kind        state.TurnKind
+		translate   bool
+		wantMessage string
+		wantNote    bool
+	}{
+		{"english unchanged", "I open the door", state.TurnPlayer, true, "I open the door", false},
+		{"non-english noted", "abro la puerta con mi llave", state.TurnPlayer, false, "abro la puerta con mi llave", true},
+		{"non-english translated", "abro la puerta con mi llave", state.TurnPlayer, true, "ok", false},
+		{"story event untouched", "abro la puerta con mi llave", state.TurnSystem, true, "abro la puerta con mi llave", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: make(map[string]string)}
+			llm := &stubLLMService{}
+			processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{}}, llm, nil, slog.Default(), 0).
+				WithInputTranslation(tt.translate)
+
+			run := processor.StartDelta(context.Background(), gs, tt.message, tt.kind)
+			run.finish("The door creaks open.")
+			run.wait()
+
+			var userMessage string
+			hasNote := false
+			for _, m := range llm.deltaMessages {
+				if m.Role == chat.ChatRoleUser && userMessage == "" {
+					userMessage = m.Content
+				}
+				if m.Content == spanishNote {
+					hasNote = true
+				}
+			}
+			if userMessage != tt.wantMessage {
+				t.Errorf("reducer user message = %q, want %q", userMessage, tt.wantMessage)
+			}
+			if hasNote != tt.wantNote {
+				t.Errorf("language note present = %v, want %v", hasNote, tt.wantNote)
+			}
+			if tt.translate && tt.wantMessage == "ok" && llm.capturedMessages[0].Content != prompts.TranslateInputPrompt {
+				t.Errorf("translation prompt missing: %+v", llm.capturedMessages)
+			}
+		})
+	}
+}
+
+func TestStartDelta_WaitsForNarration(t *testing.T) {
+	tests := []struct {
+		name      string
+		abort     bool
+		wantDelta bool
+	}{
+		{"finished narration is sent", false, true},
+		{"aborted turn sends nothing", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: make(map[string]string)}
+			llm := &stubLLMService{}
+			processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{}}, llm, nil, slog.Default(), 0)
+
+			run := processor.StartDelta(context.Background(), gs, "I open the door", state.TurnPlayer)
+			if tt.abort {
+				run.Abort()
+			} else {
+				run.finish("The door creaks open.")
+			}
+			run.wait()
+
+			if got := llm.deltaMessages != nil; got != tt.wantDelta {
+				t.Fatalf("delta sent = %v, want %v", got, tt.wantDelta)
+			}
+			if tt.wantDelta {
+				var narration string
+				for _, m := range llm.deltaMessages {
+					if m.Role == chat.ChatRoleAgent {
+						narration = m.Content
+					}
+				}
+				if narration != "The door creaks open." {
+					t.Errorf("reducer narration = %q", narration)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncGameState_AppliesDeltaOnce(t *testing.T) {
+	tests := []struct {
+		name       string
+		requestIDs []string
+		wantTurn   int
+		wantKeys   []string
+	}{
+		{"redelivered request", []string{"req-1", "req-1"}, 4, []string{"request:req-1"}},
+		{"separate requests", []string{"req-1", "req-2"}, 5, []string{"request:req-1", "request:req-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", TurnCounter: 3, Vars: make(map[string]string)}
+			llm := &stubLLMService{delta: &conditionals.GameStateDelta{}}
+			processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{}}, llm, nil, slog.Default(), 0)
+
+			for _, id := range tt.requestIDs {
+				run := processor.StartDelta(logger.ContextWithRequestID(context.Background(), id), gs, "I wait", state.TurnPlayer)
+				run.finish("Time passes.")
+				run.wait()
+			}
+
+			if gs.TurnCounter != tt.wantTurn {
+				t.Errorf("TurnCounter = %d, want %d", gs.TurnCounter, tt.wantTurn)
+			}
+			if !slices.Equal(gs.AppliedDeltas, tt.wantKeys) {
+				t.Errorf("AppliedDeltas = %v, want %v", gs.AppliedDeltas, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestStartDelta_NewerTurnCancelsEarlier(t *testing.T) {
+	gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: make(map[string]string)}
+	llm := &stubLLMService{}
+	processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{}}, llm, nil, slog.Default(), 0)
+
+	first := processor.StartDelta(context.Background(), gs, "I wait", state.TurnPlayer)
+	processor.supersedeDeltas(gs.ID, first)
+	second := processor.StartDelta(context.Background(), gs, "I open the door", state.TurnPlayer)
+	processor.supersedeDeltas(gs.ID, second)
+
+	first.wait()
+	if llm.deltaMessages != nil {
+		t.Fatal("cancelled delta should not have been sent")
+	}
+
+	second.finish("The door creaks open.")
+	second.wait()
+	if llm.deltaMessages == nil {
+		t.Error("expected the newer delta to be sent")
+	}
+	if processor.latestDelta(gs.ID) != nil {
+		t.Error("finished runs should be forgotten")
+	}
+}
+
+func TestStartDelta_EndedGame(t *testing.T) {
+	processor := NewChatProcessor(&stubStorage{}, &stubLLMService{}, nil, slog.Default(), 0)
+	run := processor.StartDelta(context.Background(), &state.GameState{ID: uuid.New(), IsEnded: true}, "hello", state.TurnPlayer)
+	if run != nil {
+		t.Fatal("ended games should not start a delta")
+	}
+	// A nil run is safe to use
+	run.finish("bye")
+	run.Abort()
+	run.wait()
+}
+
+func TestSyncGameState_CompactDelta(t *testing.T) {
+	tests := []struct {
+		name        string
+		compactAt   int
+		narration   string
+		wantCompact bool
+	}{
+		{"disabled", 0, strings.Repeat("The storm rages. ", 20), false},
+		{"short narration", 100, "The door creaks open.", false},
+		{"long narration", 100, strings.Repeat("The storm rages. ", 20), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{
+				ID:       uuid.New(),
+				Scenario: "test.json",
+				Location: "deck",
+				Vars:     make(map[string]string),
+				WorldLocations: map[string]scenario.Location{
+					"deck": {Name: "Deck", Description: "Rain lashes the slick planks of the deck.", Exits: map[string]string{"down": "hold"}},
+				},
+			}
+			llm := &stubLLMService{}
+			processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{}}, llm, nil, slog.Default(), 0).
+				WithCompactDelta(tt.compactAt)
+
+			run := processor.StartDelta(context.Background(), gs, "I hold on", state.TurnPlayer)
+			run.finish(tt.narration)
+			run.wait()
+
+			if len(llm.deltaMessages) < 2 {
+				t.Fatalf("expected a delta request, got %+v", llm.deltaMessages)
+			}
+			before := llm.deltaMessages[1].Content
+			if !strings.HasPrefix(before, "BEFORE game state: ") || !strings.Contains(before, `"down":"hold"`) {
+				t.Errorf("unexpected before-state message: %s", before)
+			}
+			if compact := !strings.Contains(before, "Rain lashes"); compact != tt.wantCompact {
+				t.Errorf("compact = %v, want %v: %s", compact, tt.wantCompact, before)
+			}
+		})
+	}
+}
+
+func TestSyncGameState_AnnotatesNarrationIssues(t *testing.T) {
+	issues := []state.NarrationIssue{{Kind: state.IssueItemNotHeld, Detail: "The player lights a lantern they don't hold."}}
+
+	tests := []struct {
+		name string
+		mode string
+		want int
+	}{
+		{"off", config.ConsistencyOff, 0},
+		{"annotate", config.ConsistencyAnnotate, 1},
+		{"regenerate leaves receipts alone", config.ConsistencyRegenerate, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: map[string]string{}}
+			llm := &stubLLMService{delta: &conditionals.GameStateDelta{}, narrationIssues: issues}
+			processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{}}, llm, nil, slog.Default(), 0).
+				WithConsistencyCheck(tt.mode)
+
+			run := processor.StartDelta(context.Background(), gs, "I light the lantern", state.TurnPlayer)
+			run.finish("The lantern flares to life.")
+			run.wait()
+
+			if len(gs.TurnReceipts) != 1 {
+				t.Fatalf("Expected one turn receipt, got %d", len(gs.TurnReceipts))
+			}
+			if got := gs.TurnReceipts[0].NarrationIssues; len(got) != tt.want {
+				t.Errorf("Expected %d narration issues, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSyncGameState_ReducerPromptOverride(t *testing.T) {
+	override := &config.ReducerPrompt{Version: "llama-1", Template: "Output JSON. Rules:\n- " + config.ContingencyRulesVar}
+	models := config.NewModelRegistry([]config.ModelCapabilities{{Name: "llama*", Streaming: true, Reducer: override}})
+
+	tests := []struct {
+		name         string
+		gameModel    string
+		backendModel string
+		wantVersion  string
+		wantPrefix   string
+	}{
+		{"built-in", "claude-sonnet-4-5", "", prompts.ReducerPromptVersion, prompts.ReducerPrompt[:50]},
+		{"game model override", "llama-3.3-70b", "", "llama-1", "Output JSON. Rules:\n- "},
+		{"backend model decides", "llama-3.3-70b", "claude-haiku-4-5", prompts.ReducerPromptVersion, prompts.ReducerPrompt[:50]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", ModelName: tt.gameModel, Vars: map[string]string{}}
+			llm := &stubLLMService{delta: &conditionals.GameStateDelta{}}
+			sc := &scenario.Scenario{ContingencyRules: []string{"Open the vault when asked."}}
+			processor := NewChatProcessor(&stubStorage{gs: gs, sc: sc}, llm, nil, slog.Default(), 0).
+				WithModelRegistry(models).
+				WithBackendModel(tt.backendModel)
+
+			run := processor.StartDelta(context.Background(), gs, "Open the vault", state.TurnPlayer)
+			run.finish("The vault swings open.")
+			run.wait()
+
+			if len(llm.deltaMessages) == 0 || !strings.HasPrefix(llm.deltaMessages[0].Content, tt.wantPrefix) {
+				t.Errorf("Expected the reducer prompt to start with %q, got %v", tt.wantPrefix, llm.deltaMessages)
+			}
+			if len(gs.TurnReceipts) != 1 || gs.TurnReceipts[0].Reducer != tt.wantVersion {
+				t.Errorf("Expected a receipt from reducer %q, got %+v", tt.wantVersion, gs.TurnReceipts)
+			}
+		})
+	}
+}
+
+func TestProcessChatRequest_RegeneratesInconsistentNarration(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		issues      []state.NarrationIssue
+		wantRetry   bool
+		wantMessage string
+	}{
+		{"off", config.ConsistencyOff, []state.NarrationIssue{{Kind: state.IssueBlockedExit, Detail: "The vault door is locked."}}, false, "ok"},
+		{"consistent", config.ConsistencyRegenerate, nil, false, "ok"},
+		{"inconsistent", config.ConsistencyRegenerate, []state.NarrationIssue{{Kind: state.IssueBlockedExit, Detail: "The vault door is locked."}}, true, "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, llm, req := newTestSetup(2, 4)
+			processor.WithConsistencyCheck(tt.mode)
+			llm.narrationIssues = tt.issues
+
+			resp, err := processor.ProcessChatRequest(context.Background(), req)
+			if err != nil {
+				t.Fatalf("ProcessChatRequest returned error: %v", err)
+			}
+			if resp.Message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, resp.Message)
+			}
+
+			retried := false
+			for _, m := range llm.capturedMessages {
+				if m.Role == chat.ChatRoleSystem && strings.Contains(m.Content, "blocked_exit: The vault door is locked.") {
+					retried = true
+				}
+			}
+			if retried != tt.wantRetry {
+				t.Errorf("Expected retry %v, got %v", tt.wantRetry, retried)
+			}
+		})
+	}
+}
+
+func TestRewindLastTurn_Regenerate(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 4)
+	gs := processor.storage.(*stubStorage).gs
+
+	if _, _, err := processor.RewindLastTurn(context.Background(), gs.ID); !errors.Is(err, state.ErrNoLastTurn) {
+		t.Fatalf("Expected ErrNoLastTurn before any turn, got %v", err)
+	}
+
+	if _, err := processor.ProcessChatRequest(context.Background(), req); err != nil {
+		t.Fatalf("ProcessChatRequest returned error: %v", err)
+	}
+	if len(gs.ChatHistory) != 4 {
+		t.Fatalf("Expected the turn in the chat history, got %d messages", len(gs.ChatHistory))
+	}
+
+	rewound, turn, err := processor.RewindLastTurn(context.Background(), gs.ID)
+	if err != nil {
+		t.Fatalf("RewindLastTurn returned error: %v", err)
+	}
+	if turn.Message != req.Message || len(rewound.ChatHistory) != 2 {
+		t.Errorf("Expected the turn undone, got message %q and %d messages", turn.Message, len(rewound.ChatHistory))
+	}
+
+	req.Regenerate = true
+	if _, err := processor.ProcessChatRequest(context.Background(), req); err != nil {
+		t.Fatalf("ProcessChatRequest returned error: %v", err)
+	}
+	if want := services.DefaultTemperature + RegenerateTemperatureStep; math.Abs(llm.capturedTemp-want) > 1e-9 {
+		t.Errorf("Expected regenerated turn at temperature %v, got %v", want, llm.capturedTemp)
+	}
+	if len(gs.ChatHistory) != 4 {
+		t.Errorf("Expected only the regenerated turn in the chat history, got %d messages", len(gs.ChatHistory))
+	}
+}
+
+func TestGenerateVariants(t *testing.T) {
+	processor, llm, req := newTestSetup(2, 4)
+	gs := processor.storage.(*stubStorage).gs
+	llm.reply = "The door creaks open."
+	req.Variants = 3
+
+	candidates, err := processor.GenerateVariants(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateVariants returned error: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Fatalf("Expected 3 candidates, got %v", candidates)
+	}
+	if gs.PendingVariants == nil || gs.PendingVariants.Message != req.Message || len(gs.PendingVariants.Candidates) != 3 {
+		t.Fatalf("Expected the candidates pending on the game state, got %+v", gs.PendingVariants)
+	}
+	if len(gs.ChatHistory) != 2 {
+		t.Errorf("Expected no turn in the chat history before a choice, got %d messages", len(gs.ChatHistory))
+	}
+
+	_, narration, err := gs.ChooseVariant(1)
+	if err != nil {
+		t.Fatalf("ChooseVariant returned error: %v", err)
+	}
+	if err := processor.UpdateGameStateAfterStream(context.Background(), gs, nil, req.Message, narration, "", "", state.TurnPlayer); err != nil {
+		t.Fatalf("UpdateGameStateAfterStream returned error: %v", err)
+	}
+	if gs.PendingVariants != nil {
+		t.Error("Expected the variants cleared once the turn was played")
+	}
+	if last := gs.ChatHistory[len(gs.ChatHistory)-1]; last.Content != "The door creaks open." {
+		t.Errorf("Expected the chosen narration in the chat history, got %q", last.Content)
+	}
+}
+
+func TestContinueNarration(t *testing.T) {
+	tests := []struct {
+		name        string
+		history     int
+		chunks      []string
+		streamErr   error
+		expected    string
+		incomplete  bool
+		expectedErr error
+	}{
+		{name: "finishes the narration", history: 2, chunks: []string{", and a ship ", "appears."}, expected: "assistant msg 1, and a ship appears."},
+		{name: "cut off again", history: 2, chunks: []string{"A ship "}, streamErr: errors.New("context deadline exceeded"), expected: "assistant msg 1 A ship ", incomplete: true},
+		{name: "player message last", history: 3, expectedErr: state.ErrNoNarration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, llm, req := newTestSetup(tt.history, 4)
+			gs := processor.storage.(*stubStorage).gs
+			gs.TurnCounter = 3
+			llm.streamChunks = tt.chunks
+			llm.streamErr = tt.streamErr
+
+			streamChan, continued, err := processor.ContinueStream(context.Background(), req.GameStateID)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			var continuation string
+			for chunk := range streamChan {
+				continuation += chunk.Content
+			}
+
+			if err := processor.SaveContinuation(context.Background(), continued, nil, continuation, tt.incomplete); err != nil {
+				t.Fatalf("SaveContinuation returned error: %v", err)
+			}
+			if len(gs.ChatHistory) != tt.history {
+				t.Errorf("Expected no messages added, got %d", len(gs.ChatHistory))
+			}
+			last := gs.ChatHistory[len(gs.ChatHistory)-1]
+			if last.Content != tt.expected || last.Incomplete != tt.incomplete {
+				t.Errorf("Expected narration %q (incomplete %v), got %q (incomplete %v)", tt.expected, tt.incomplete, last.Content, last.Incomplete)
+			}
+			if gs.TurnCounter != 3 {
+				t.Errorf("Expected the turn counter left at 3, got %d", gs.TurnCounter)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/prompts"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// TryHandleCommand answers server commands from the game state and scenario, without
+// an LLM call. It returns false if message is not a command, so it can be sent as a
+// normal chat. Commands:
+//
+//	/inventory       - List the items the player is carrying
+//	/examine <item>  - Describe an item the player carries or can see at their location
+func TryHandleCommand(gs *state.GameState, s *scenario.Scenario, message string) (string, bool) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(message), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch strings.ToLower(command) {
+	case "/inventory", "/inv":
+		if len(gs.Inventory) == 0 {
+			return "You aren't carrying anything.", true
+		}
+		return "You are carrying: " + strings.Join(gs.DisplayItems(gs.Inventory), ", ") + ".", true
+	case "/examine", "/x":
+		if arg == "" {
+			return "Examine what? Try /examine followed by an item name.", true
+		}
+		return examineItem(gs, s, arg), true
+	}
+	return "", false
+}
+
+// examineItem describes an item the player carries or that lies at their location.
+// An exact name match wins; otherwise a single partial match is accepted.
+func examineItem(gs *state.GameState, s *scenario.Scenario, name string) string {
+	candidates := append([]string(nil), gs.Inventory...)
+	if loc, ok := gs.WorldLocations[gs.Location]; ok {
+		candidates = append(candidates, loc.Items...)
+	}
+
+	var partial []string
+	item := ""
+	for _, c := range candidates {
+		if strings.EqualFold(gs.ItemName(c), name) || c == gs.ItemID(name) {
+			item = c
+			break
+		}
+		if strings.Contains(strings.ToLower(gs.ItemName(c)), strings.ToLower(name)) {
+			partial = append(partial, c)
+		}
+	}
+	if item == "" {
+		switch len(partial) {
+		case 0:
+			return fmt.Sprintf("You don't have or see any %s here.", name)
+		case 1:
+			item = partial[0]
+		default:
+			return fmt.Sprintf("Which do you mean: %s?", strings.Join(gs.DisplayItems(partial), ", "))
+		}
+	}
+
+	if s != nil {
+		for key, details := range s.ItemDetails {
+			if strings.EqualFold(key, item) && details != "" {
+				return fmt.Sprintf("%s: %s", gs.ItemName(item), details)
+			}
+		}
+	}
+	return fmt.Sprintf("You look over the %s. Nothing about it stands out.", gs.ItemName(item))
+}
+
+// ExpandAliases expands a shortcut at the start of a player's message using the
+// scenario's aliases and the defaults, e.g. "n" becomes "go north". If the scenario
+// can't be loaded only the defaults are used.
+func (p *ChatProcessor) ExpandAliases(ctx context.Context, gs *state.GameState, message string) string {
+	var custom map[string]string
+	if s, err := p.storage.GetScenario(ctx, gs.Scenario); err != nil {
+		p.logger.WarnContext(ctx, "Failed to load scenario for aliases", "error", err, "game_state_id", gs.ID.String())
+	} else if s != nil {
+		custom = s.Aliases
+	}
+	expanded := chat.ExpandAlias(message, custom)
+	if expanded != message {
+		p.logger.DebugContext(ctx, "Expanded input alias", "game_state_id", gs.ID.String(), "from", message, "to", expanded)
+	}
+	return expanded
+}
+
+// HandleCommand answers a server command for the game without taking a turn: nothing
+// is added to the chat history and no game state delta runs. With embellish set, the
+// narrator rewords the answer; if that call fails the plain answer is used.
+// It returns false if message is not a command. Besides those of TryHandleCommand,
+// it handles:
+//
+//	/talk <npc>  - Start a side conversation with an NPC at the player's location
+//	/leave       - End the side conversation and return to the main story
+//	/hint        - Give the next hint for the current scene, more direct than the last
+func (p *ChatProcessor) HandleCommand(ctx context.Context, gs *state.GameState, message string, embellish bool) (string, bool, error) {
+	if !strings.HasPrefix(strings.TrimSpace(message), "/") {
+		return "", false, nil
+	}
+	ctx = state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventCommand})
+	if reply, ok, err := p.handleConversationCommand(ctx, gs, message); ok || err != nil {
+		return reply, ok, err
+	}
+	s, err := p.storage.GetScenario(ctx, gs.Scenario)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to load scenario: %w", err)
+	}
+	if reply, ok, err := p.handleHintCommand(ctx, gs, s, message); ok || err != nil {
+		return reply, ok, err
+	}
+
+	reply, ok := TryHandleCommand(gs, s, message)
+	if !ok || !embellish {
+		return reply, ok, nil
+	}
+
+	messages := []chat.ChatMessage{
+		{Role: chat.ChatRoleSystem, Content: prompts.BuildSystemPrompt(gs.Narrator, gs.PC)},
+		{Role: chat.ChatRoleSystem, Content: prompts.EmbellishCommandPrompt},
+		{Role: chat.ChatRoleUser, Content: reply},
+	}
+	resp, err := p.llmService.Chat(p.llmContext(ctx, gs), messages, resolveTemperature(gs, s))
+	if err != nil || strings.TrimSpace(resp.Message) == "" {
+		p.logger.WarnContext(ctx, "Failed to embellish command reply, using plain reply", "error", err, "game_state_id", gs.ID.String())
+		return reply, true, nil
+	}
+	return strings.TrimSpace(resp.Message), true, nil
+}
+
+// handleConversationCommand starts or ends a side conversation, saving the game.
+// It returns false for other messages.
+func (p *ChatProcessor) handleConversationCommand(ctx context.Context, gs *state.GameState, message string) (string, bool, error) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(message), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch strings.ToLower(command) {
+	case "/talk":
+		if arg == "" {
+			return "Talk to whom? Try /talk followed by a name.", true, nil
+		}
+		ids := gs.FindNPCsHere(arg)
+		switch len(ids) {
+		case 0:
+			return fmt.Sprintf("There's no one called %s here to talk to.", arg), true, nil
+		case 1:
+		default:
+			names := make([]string, len(ids))
+			for i, id := range ids {
+				names[i] = gs.NPCName(id)
+			}
+			return fmt.Sprintf("Who do you mean: %s?", strings.Join(names, ", ")), true, nil
+		}
+		if err := gs.StartConversation(ids[0]); err != nil {
+			return "", false, err
+		}
+		if err := p.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+			return "", false, fmt.Errorf("failed to save game state: %w", err)
+		}
+		return fmt.Sprintf("You turn to talk with %s. Type /leave to end the conversation.", gs.NPCName(ids[0])), true, nil
+	case "/leave":
+		npcID, ok, err := p.EndConversation(ctx, gs)
+		if err != nil {
+			return "", false, err
+		}
+		if !ok {
+			return "You aren't talking with anyone.", true, nil
+		}
+		return fmt.Sprintf("You finish your conversation with %s.", gs.NPCName(npcID)), true, nil
+	}
+	return "", false, nil
+}
+
+// handleHintCommand gives the next hint for the current scene, within its budget, and
+// saves the game. The scene's written hints are given first, in order; after those the
+// narrator writes one from the scene's unmet goals. It returns false for other messages.
+func (p *ChatProcessor) handleHintCommand(ctx context.Context, gs *state.GameState, s *scenario.Scenario, message string) (string, bool, error) {
+	command, _, _ := strings.Cut(strings.TrimSpace(message), " ")
+	if !strings.EqualFold(command, "/hint") {
+		return "", false, nil
+	}
+
+	written, budget := s.SceneHints(gs.SceneName)
+	n := len(gs.HintsGiven())
+	if budget == 0 {
+		return "There are no hints for this part of the story.", true, nil
+	}
+	if n >= budget {
+		return fmt.Sprintf("You've used all %d hints for this part of the story.", budget), true, nil
+	}
+
+	var hint string
+	if n < len(written) {
+		hint = written[n]
+	} else {
+		messages, err := prompts.BuildHintMessages(gs, s, budget)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to build hint prompt: %w", err)
+		}
+		resp, err := p.llmService.Chat(p.llmContext(ctx, gs), messages, resolveTemperature(gs, s))
+		if err != nil || strings.TrimSpace(resp.Message) == "" {
+			// The hint isn't counted, so the player can ask again
+			p.logger.WarnContext(ctx, "Failed to generate hint", "error", err, "game_state_id", gs.ID.String())
+			return "No hint comes to mind right now. Try again in a moment.", true, nil
+		}
+		hint = strings.TrimSpace(resp.Message)
+	}
+
+	gs.RecordHint(hint)
+	if err := p.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		return "", false, fmt.Errorf("failed to save game state: %w", err)
+	}
+	return fmt.Sprintf("Hint %d of %d: %s", n+1, budget, hint), true, nil
+}
+
+// EndConversation ends the game's side conversation, if any, and saves the game.
+// It returns the NPC's ID and false if there was no conversation.
+func (p *ChatProcessor) EndConversation(ctx context.Context, gs *state.GameState) (string, bool, error) {
+	npcID, ok := gs.EndConversation()
+	if !ok {
+		return "", false, nil
+	}
+	if err := p.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		return "", false, fmt.Errorf("failed to save game state: %w", err)
+	}
+	return npcID, true, nil
+}
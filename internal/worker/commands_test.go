@@ -0,0 +1,287 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/prompts"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func TestTryHandleCommand(t *testing.T) {
+	gs := &state.GameState{
+		Location:  "dock",
+		Inventory: []string{"Brass Key", "rope", "rusty key"},
+		WorldLocations: map[string]scenario.Location{
+			"dock": {Name: "Dock", Items: []string{"lantern"}},
+		},
+	}
+	s := &scenario.Scenario{
+		ItemDetails: map[string]string{
+			"brass key": "A small key stamped with an anchor.",
+			"lantern":   "An oil lantern, still warm.",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		message     string
+		wantHandled bool
+		want        string
+	}{
+		{"not a command", "I open the door", false, ""},
+		{"unknown command", "/dance", false, ""},
+		{"inventory", "/inventory", true, "You are carrying: Brass Key, rope, rusty key."},
+		{"inventory alias and case", " /INV ", true, "You are carrying: Brass Key, rope, rusty key."},
+		{"examine carried item", "/examine brass key", true, "Brass Key: A small key stamped with an anchor."},
+		{"examine item at location", "/examine Lantern", true, "lantern: An oil lantern, still warm."},
+		{"examine without details", "/x rope", true, "You look over the rope. Nothing about it stands out."},
+		{"examine partial match", "/examine rusty", true, "You look over the rusty key. Nothing about it stands out."},
+		{"examine ambiguous", "/examine key", true, "Which do you mean: Brass Key, rusty key?"},
+		{"examine missing", "/examine sword", true, "You don't have or see any sword here."},
+		{"examine without item", "/examine", true, "Examine what? Try /examine followed by an item name."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, handled := TryHandleCommand(gs, s, tt.message)
+			if handled != tt.wantHandled {
+				t.Fatalf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+			if got != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTryHandleCommand_CataloguedItems(t *testing.T) {
+	gs := &state.GameState{
+		Inventory: []string{"iron_key", "rope"},
+		ItemNames: map[string]string{"iron_key": "Iron Key"},
+	}
+	s := &scenario.Scenario{ItemDetails: map[string]string{"iron_key": "Cold and heavy."}}
+
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"/inventory", "You are carrying: Iron Key, rope."},
+		{"/examine iron key", "Iron Key: Cold and heavy."},
+		{"/examine iron_key", "Iron Key: Cold and heavy."},
+		{"/examine iron", "Iron Key: Cold and heavy."},
+	}
+	for _, tt := range tests {
+		if got, _ := TryHandleCommand(gs, s, tt.message); got != tt.want {
+			t.Errorf("TryHandleCommand(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestTryHandleCommand_EmptyInventory(t *testing.T) {
+	got, handled := TryHandleCommand(&state.GameState{}, nil, "/inventory")
+	if !handled || got != "You aren't carrying anything." {
+		t.Errorf("TryHandleCommand() = %q, %v", got, handled)
+	}
+}
+
+func TestProcessChatRequest_Command(t *testing.T) {
+	tests := []struct {
+		name         string
+		embellish    bool
+		wantMessage  string
+		wantLLMCalls bool
+	}{
+		{"plain", false, "You are carrying: compass.", false},
+		{"embellished", true, "ok", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &state.GameState{
+				ID:        uuid.New(),
+				Scenario:  "test.json",
+				Inventory: []string{"compass"},
+				Vars:      make(map[string]string),
+			}
+			llm := &stubLLMService{}
+			stor := &stubStorage{gs: gs, sc: &scenario.Scenario{Name: "Test"}}
+			processor := NewChatProcessor(stor, llm, nil, slog.Default(), 0)
+
+			resp, err := processor.ProcessChatRequest(context.Background(), chat.ChatRequest{
+				GameStateID: gs.ID,
+				Message:     "/inventory",
+				Embellish:   tt.embellish,
+			})
+			if err != nil {
+				t.Fatalf("ProcessChatRequest returned error: %v", err)
+			}
+			if resp.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", resp.Message, tt.wantMessage)
+			}
+			if called := llm.capturedMessages != nil; called != tt.wantLLMCalls {
+				t.Errorf("LLM called = %v, want %v", called, tt.wantLLMCalls)
+			}
+			if tt.embellish {
+				last := llm.capturedMessages[len(llm.capturedMessages)-1]
+				if last.Content != "You are carrying: compass." {
+					t.Errorf("embellish input = %q", last.Content)
+				}
+				if llm.capturedMessages[1].Content != prompts.EmbellishCommandPrompt {
+					t.Errorf("embellish prompt missing: %+v", llm.capturedMessages)
+				}
+			}
+			if len(gs.ChatHistory) != 0 || gs.TurnCounter != 0 {
+				t.Errorf("command changed the game: history %d, turn %d", len(gs.ChatHistory), gs.TurnCounter)
+			}
+		})
+	}
+}
+
+func TestProcessChatRequest_ExpandsAliases(t *testing.T) {
+	tests := []struct {
+		message string
+		aliases map[string]string
+		want    string
+	}{
+		{"n", nil, "go north"},
+		{"x lantern", nil, "examine lantern"},
+		{"xyzzy", map[string]string{"xyzzy": "say the magic word"}, "say the magic word"},
+		{"I wave", nil, "I wave"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			gs := &state.GameState{
+				ID:       uuid.New(),
+				Scenario: "test.json",
+				IsEnded:  true, // skip background syncGameState goroutine
+				Vars:     make(map[string]string),
+			}
+			llm := &stubLLMService{}
+			stor := &stubStorage{gs: gs, sc: &scenario.Scenario{Name: "Test", Aliases: tt.aliases}}
+			processor := NewChatProcessor(stor, llm, nil, slog.Default(), 0)
+
+			if _, err := processor.ProcessChatRequest(context.Background(), chat.ChatRequest{GameStateID: gs.ID, Message: tt.message}); err != nil {
+				t.Fatalf("ProcessChatRequest returned error: %v", err)
+			}
+			if got := gs.ChatHistory[len(gs.ChatHistory)-2].Content; got != tt.want {
+				t.Errorf("stored user message = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessChatRequest_AliasToCommand(t *testing.T) {
+	gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Inventory: []string{"map"}}
+	llm := &stubLLMService{}
+	processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{Name: "Test"}}, llm, nil, slog.Default(), 0)
+
+	resp, err := processor.ProcessChatRequest(context.Background(), chat.ChatRequest{GameStateID: gs.ID, Message: "inv"})
+	if err != nil {
+		t.Fatalf("ProcessChatRequest returned error: %v", err)
+	}
+	if resp.Message != "You are carrying: map." || llm.capturedMessages != nil {
+		t.Errorf("Message = %q, LLM called = %v", resp.Message, llm.capturedMessages != nil)
+	}
+}
+
+func TestProcessChatRequest_Conversation(t *testing.T) {
+	gs := &state.GameState{
+		ID:          uuid.New(),
+		Scenario:    "test.json",
+		Location:    "dock",
+		IsEnded:     true, // skip background syncGameState goroutine
+		Vars:        make(map[string]string),
+		NPCs:        map[string]actor.NPC{"gibbs": {Name: "Gibbs", Location: "dock"}},
+		ChatHistory: []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "You reach the dock."}},
+	}
+	llm := &stubLLMService{}
+	processor := NewChatProcessor(&stubStorage{gs: gs, sc: &scenario.Scenario{Name: "Test"}}, llm, nil, slog.Default(), 0)
+	send := func(message string) string {
+		t.Helper()
+		resp, err := processor.ProcessChatRequest(context.Background(), chat.ChatRequest{GameStateID: gs.ID, Message: message})
+		if err != nil {
+			t.Fatalf("ProcessChatRequest(%q) returned error: %v", message, err)
+		}
+		return resp.Message
+	}
+
+	if got := send("/talk anne"); got != "There's no one called anne here to talk to." {
+		t.Errorf("unknown NPC reply = %q", got)
+	}
+	if got := send("/talk gibbs"); got != "You turn to talk with Gibbs. Type /leave to end the conversation." {
+		t.Errorf("talk reply = %q", got)
+	}
+	if gs.Conversation == nil || gs.Conversation.NPCID != "gibbs" {
+		t.Fatalf("expected a conversation with gibbs, got %+v", gs.Conversation)
+	}
+
+	send("Any news?")
+	if len(gs.ChatHistory) != 1 || len(gs.Conversation.History) != 2 {
+		t.Errorf("expected the turn in the conversation only, got history %d, conversation %d", len(gs.ChatHistory), len(gs.Conversation.History))
+	}
+	if !strings.Contains(llm.capturedMessages[0].Content, "talk with Gibbs") {
+		t.Errorf("expected the conversation prompt, got %q", llm.capturedMessages[0].Content)
+	}
+
+	if got := send("/leave"); got != "You finish your conversation with Gibbs." {
+		t.Errorf("leave reply = %q", got)
+	}
+	if gs.Conversation != nil || len(gs.NPCMemories["gibbs"]) != 2 || len(gs.ChatHistory) != 3 {
+		t.Errorf("expected the conversation ended and remembered, got %+v, memories %d, history %d", gs.Conversation, len(gs.NPCMemories["gibbs"]), len(gs.ChatHistory))
+	}
+	if got := send("/leave"); got != "You aren't talking with anyone." {
+		t.Errorf("second leave reply = %q", got)
+	}
+}
+
+func TestProcessChatRequest_Hint(t *testing.T) {
+	gs := &state.GameState{
+		ID:        uuid.New(),
+		Scenario:  "test.json",
+		SceneName: "docks",
+		Vars:      make(map[string]string),
+	}
+	s := &scenario.Scenario{
+		Name: "Test",
+		Scenes: map[string]scenario.Scene{
+			"docks": {Story: "Find a ship.", Hints: []string{"Captains drink at the tavern."}, HintBudget: 2},
+		},
+	}
+	llm := &stubLLMService{reply: "Ask the harbor master about the Pearl."}
+	processor := NewChatProcessor(&stubStorage{gs: gs, sc: s}, llm, nil, slog.Default(), 0)
+	send := func(message string) string {
+		t.Helper()
+		resp, err := processor.ProcessChatRequest(context.Background(), chat.ChatRequest{GameStateID: gs.ID, Message: message})
+		if err != nil {
+			t.Fatalf("ProcessChatRequest(%q) returned error: %v", message, err)
+		}
+		return resp.Message
+	}
+
+	if got := send("/hint"); got != "Hint 1 of 2: Captains drink at the tavern." {
+		t.Errorf("first hint = %q", got)
+	}
+	if llm.capturedMessages != nil {
+		t.Error("expected the written hint without an LLM call")
+	}
+	if got := send("/HINT"); got != "Hint 2 of 2: Ask the harbor master about the Pearl." {
+		t.Errorf("second hint = %q", got)
+	}
+	if llm.capturedMessages == nil || !strings.Contains(llm.capturedMessages[1].Content, "Captains drink at the tavern.") {
+		t.Errorf("expected the generated hint to see earlier hints, got %+v", llm.capturedMessages)
+	}
+	if got := send("/hint"); got != "You've used all 2 hints for this part of the story." {
+		t.Errorf("third hint = %q", got)
+	}
+	if len(gs.Hints["docks"]) != 2 || len(gs.ChatHistory) != 0 || gs.TurnCounter != 0 {
+		t.Errorf("expected two hints recorded without taking a turn, got hints %v, history %d, turn %d", gs.Hints, len(gs.ChatHistory), gs.TurnCounter)
+	}
+}
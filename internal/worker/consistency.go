@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jwebster45206/story-engine/internal/config"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/prompts"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// WithConsistencyCheck checks each narration against the game state with a backend-model
+// pass. In config.ConsistencyAnnotate mode, the items, NPCs and exits the narration gets
+// wrong are recorded in the turn receipt. In config.ConsistencyRegenerate mode, a
+// narration that gets any wrong is written again, once, before the player sees it.
+func (p *ChatProcessor) WithConsistencyCheck(mode string) *ChatProcessor {
+	p.consistency = mode
+	return p
+}
+
+// checkNarration asks the backend model where a narration contradicts the game state
+// from before the turn. Errors are logged and return nil, so that a failed check never
+// blocks the turn.
+func (p *ChatProcessor) checkNarration(ctx context.Context, gs *state.GameState, stateJSON []byte, playerMessage, narration string) []state.NarrationIssue {
+	messages := []chat.ChatMessage{
+		{
+			Role:    chat.ChatRoleSystem,
+			Content: prompts.ConsistencyCheckPrompt,
+		},
+		{
+			Role:    chat.ChatRoleSystem,
+			Content: fmt.Sprintf("BEFORE game state: %s", string(stateJSON)),
+		},
+		{
+			Role:    chat.ChatRoleUser,
+			Content: fmt.Sprintf("Player:\n%s\n\nNarration:\n%s\n\nList the inconsistencies as JSON.", playerMessage, narration),
+		},
+	}
+
+	checkCtx, cancel := context.WithTimeout(p.llmContext(ctx, gs), 15*time.Second)
+	defer cancel()
+
+	issues, err := p.llmService.CheckNarration(checkCtx, messages)
+	if err != nil {
+		p.logger.WarnContext(ctx, "Failed to check narration consistency", "error", err, "game_state_id", gs.ID.String())
+		return nil
+	}
+	return issues
+}
+
+// reviseNarration checks a narration in regenerate mode and, if it contradicts the game
+// state, asks the narrator to write it again, naming the mistakes. The draft is kept if
+// the retry fails. messages are the ones the draft was written from.
+func (p *ChatProcessor) reviseNarration(ctx context.Context, gs *state.GameState, messages []chat.ChatMessage, temperature float64, playerMessage, narration string) string {
+	if p.consistency != config.ConsistencyRegenerate {
+		return narration
+	}
+	stateJSON, err := json.Marshal(prompts.ToBackgroundPromptState(gs))
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to marshal game state for consistency check", "error", err, "game_state_id", gs.ID.String())
+		return narration
+	}
+	issues := p.checkNarration(ctx, gs, stateJSON, playerMessage, narration)
+	if len(issues) == 0 {
+		return narration
+	}
+	p.logger.WarnContext(ctx, "Narration contradicts game state, regenerating", "game_state_id", gs.ID.String(), "issues", issues)
+
+	retry := append(slices.Clone(messages), chat.ChatMessage{
+		Role:    chat.ChatRoleSystem,
+		Content: fmt.Sprintf(prompts.NarrationRetryPrompt, formatNarrationIssues(issues)),
+	})
+
+	retryCtx, cancel := context.WithTimeout(p.llmContext(ctx, gs), 30*time.Second)
+	defer cancel()
+	resp, err := p.llmService.Chat(retryCtx, retry, temperature)
+	if err != nil || strings.TrimSpace(resp.Message) == "" {
+		p.logger.WarnContext(ctx, "Failed to regenerate narration, keeping the draft", "error", err, "game_state_id", gs.ID.String())
+		return narration
+	}
+	return resp.Message
+}
+
+// checkedChunkStream is singleChunkStream for regenerate mode: the narration is checked,
+// and written again if need be, before it is delivered
+func (p *ChatProcessor) checkedChunkStream(ctx context.Context, gs *state.GameState, messages []chat.ChatMessage, temperature float64, playerMessage string) <-chan services.StreamChunk {
+	ch := make(chan services.StreamChunk, 1)
+	go func() {
+		defer close(ch)
+		resp, err := p.llmService.Chat(ctx, messages, temperature)
+		if err != nil {
+			ch <- services.StreamChunk{Error: fmt.Errorf("LLM chat failed: %w", err), Done: true}
+			return
+		}
+		ch <- services.StreamChunk{Content: p.reviseNarration(ctx, gs, messages, temperature, playerMessage, resp.Message), Done: true}
+	}()
+	return ch
+}
+
+// formatNarrationIssues lists inconsistencies one per line, for the narrator
+func formatNarrationIssues(issues []state.NarrationIssue) string {
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = fmt.Sprintf("- %s: %s", issue.Kind, issue.Detail)
+	}
+	return strings.Join(lines, "\n")
+}
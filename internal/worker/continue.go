@@ -0,0 +1,175 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/prompts"
+	queuePkg "github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// ContinueStream narrates more of the game's last narration, when it was cut off by the
+// model's token limit or an error, or the player wants more of it. The turn's delta is
+// waited for first, so the narrator continues from the state the turn left. Returns the
+// game state it narrates from, and state.ErrNoNarration if the latest message isn't a
+// narration.
+func (p *ChatProcessor) ContinueStream(ctx context.Context, gameStateID uuid.UUID) (<-chan services.StreamChunk, *state.GameState, error) {
+	p.awaitDelta(gameStateID)
+
+	gs, err := p.GetGameState(ctx, gameStateID)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := gs.LastNarration()
+	if last == nil {
+		return nil, nil, state.ErrNoNarration
+	}
+
+	loadedScenario, err := p.storage.GetScenario(ctx, gs.Scenario)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load scenario: %w", err)
+	}
+
+	// The instruction goes where a player message would, and isn't kept in the history
+	length := loadedScenario.SceneNarrationLength(gs.SceneName)
+	messages, err := prompts.New().
+		WithGameState(gs).
+		WithScenario(loadedScenario).
+		WithUserMessage(prompts.ContinuePrompt, chat.ChatRoleUser).
+		WithHistoryLimit(p.historyLimit).
+		WithPrefixCache(p.prefixes).
+		WithMaxSentences(length.MaxSentences).
+		Build()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build chat messages: %w", err)
+	}
+
+	ctx = p.narrationContext(ctx, gs, length)
+	temperature := resolveTemperature(gs, loadedScenario)
+	pipeline := p.narrationPipeline(loadedScenario, length, last.Content)
+	if !p.models.Lookup(gs.ModelName).Streaming {
+		return filterStream(p.singleChunkStream(ctx, messages, temperature), pipeline), gs, nil
+	}
+	streamChan, err := p.llmService.ChatStream(ctx, messages, temperature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("LLM chat stream failed: %w", err)
+	}
+	return filterStream(streamChan, pipeline), gs, nil
+}
+
+// SaveContinuation adds a continuation to the game's last narration and saves it, then
+// hands it to the delta run from StartDelta. A continuation cut off by an error is kept,
+// marked incomplete, and its delta skipped, as SalvagePartialStream does for a turn.
+// The turn counters are left alone. The save goes ahead even if ctx is cancelled.
+func (p *ChatProcessor) SaveContinuation(ctx context.Context, gs *state.GameState, run *DeltaRun, continuation string, incomplete bool) error {
+	ctx = context.WithoutCancel(ctx)
+	if incomplete {
+		run.Abort()
+	} else {
+		p.supersedeDeltas(gs.ID, run)
+	}
+
+	if err := gs.ExtendNarration(continuation, incomplete); err != nil {
+		run.Abort()
+		return err
+	}
+	if err := p.storage.SaveGameState(state.WithGameEvent(ctx, state.GameEvent{Kind: state.EventContinue}), gs.ID, gs); err != nil {
+		run.Abort()
+		return fmt.Errorf("failed to save continued narration: %w", err)
+	}
+
+	if !incomplete {
+		run.finish(strings.TrimRight(continuation, "\n"))
+	}
+	return nil
+}
+
+// awaitDelta waits for the delta still running for the game in this process, if any
+func (p *ChatProcessor) awaitDelta(gameStateID uuid.UUID) {
+	p.latestDelta(gameStateID).wait()
+}
+
+// processContinue streams a continuation of the game's last narration and adds it to
+// that narration. The delta runs on the continuation as part of the same turn, like a
+// story event's, so the turn counters don't advance.
+func (w *Worker) processContinue(ctx context.Context, processor *ChatProcessor, req *queuePkg.Request, start time.Time) error {
+	fail := func(err error) error {
+		if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+			w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
+		}
+		return fmt.Errorf("failed to continue narration: %w", err)
+	}
+
+	streamChan, gs, err := processor.ContinueStream(ctx, req.GameStateID)
+	if err != nil {
+		w.log.ErrorContext(ctx, "Failed to start continuation stream",
+			"error", err,
+			"request_id", req.RequestID,
+			"game_state_id", req.GameStateID.String(),
+		)
+		return fail(err)
+	}
+	startInventory := append([]string(nil), gs.Inventory...)
+	run := processor.StartDelta(ctx, gs, prompts.ContinuePrompt, state.TurnSystem)
+
+	var continuation string
+	var streamErr error
+	for chunk := range streamChan {
+		if chunk.Error != nil {
+			streamErr = chunk.Error
+			w.log.ErrorContext(ctx, "Error in continuation stream",
+				"error", chunk.Error,
+				"request_id", req.RequestID,
+			)
+			continuation += w.publishHeldBack(ctx, req, chunk.Content)
+			break
+		}
+
+		continuation += chunk.Content
+		if err := w.broadcaster.PublishChatChunk(ctx, req.GameStateID, req.RequestID, chunk.Content, chunk.Done); err != nil {
+			w.log.ErrorContext(ctx, "Failed to publish chat chunk", "error", err)
+		}
+		if chunk.Done {
+			break
+		}
+	}
+
+	incomplete := streamErr != nil
+	if incomplete && strings.TrimSpace(continuation) == "" {
+		run.Abort()
+		return fail(streamErr)
+	}
+	if err := processor.SaveContinuation(ctx, gs, run, continuation, incomplete); err != nil {
+		w.log.ErrorContext(ctx, "Failed to save continued narration",
+			"error", err,
+			"request_id", req.RequestID,
+		)
+		return fail(err)
+	}
+	w.publishToSpectators(gs, continuation)
+
+	w.log.InfoContext(ctx, "Narration continued",
+		"worker_id", w.id,
+		"request_id", req.RequestID,
+		"incomplete", incomplete,
+		"chars", len(continuation),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	result := w.completionResult(ctx, processor, gs, continuation, start, startInventory)
+	result["continued"] = true
+	if incomplete {
+		result["incomplete"] = true
+		result["error"] = streamErr.Error()
+	}
+	if err := w.broadcaster.PublishRequestCompleted(ctx, req.GameStateID, req.RequestID, result); err != nil {
+		w.log.ErrorContext(ctx, "Failed to publish completion event", "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// deltaLane runs a game's gamestate deltas one at a time, so a delta never loads the
+// game while another is between loading and saving it. It holds the run in progress and
+// a mailbox of one: a run posted while another is pending replaces it, so only the
+// newest waiting turn is applied. The lane's goroutine exits once the mailbox is empty.
+type deltaLane struct {
+	current *DeltaRun // running on the lane's goroutine
+	pending *DeltaRun // next to run; replaced by newer runs
+}
+
+// latest returns the newest run for the game: the pending one, or the one in progress
+func (l *deltaLane) latest() *DeltaRun {
+	if l.pending != nil {
+		return l.pending
+	}
+	return l.current
+}
+
+// postDelta queues a run on its game's lane, starting the lane if it isn't running.
+// A run already waiting is dropped in its favor.
+func (p *ChatProcessor) postDelta(run *DeltaRun, sync func()) {
+	run.sync = sync
+
+	p.deltasMu.Lock()
+	defer p.deltasMu.Unlock()
+	p.deltaSeq++
+	run.seq = p.deltaSeq
+	lane, ok := p.deltas[run.gameStateID]
+	if !ok {
+		lane = &deltaLane{}
+		p.deltas[run.gameStateID] = lane
+		go p.runLane(run.gameStateID, lane)
+	}
+	if lane.pending != nil {
+		lane.pending.drop()
+	}
+	lane.pending = run
+}
+
+// runLane runs the lane's runs in order until its mailbox is empty
+func (p *ChatProcessor) runLane(gameStateID uuid.UUID, lane *deltaLane) {
+	for {
+		p.deltasMu.Lock()
+		run := lane.pending
+		if run == nil {
+			lane.current = nil
+			delete(p.deltas, gameStateID)
+			if len(p.deltas) == 0 && p.idle != nil {
+				close(p.idle)
+				p.idle = nil
+			}
+			p.deltasMu.Unlock()
+			return
+		}
+		lane.pending, lane.current = nil, run
+		p.deltasMu.Unlock()
+
+		run.execute()
+	}
+}
+
+// supersedeDeltas cancels the game's runs started before run, as run's turn has been
+// narrated. Runs of newer turns are left alone, so a turn whose narration finishes late
+// can't cancel them. A nil run supersedes every run. A cancelled run stops before it
+// saves; the lane still waits for it to return before starting the next, so it can't
+// save over a newer delta.
+func (p *ChatProcessor) supersedeDeltas(gameStateID uuid.UUID, run *DeltaRun) {
+	p.deltasMu.Lock()
+	defer p.deltasMu.Unlock()
+	lane, ok := p.deltas[gameStateID]
+	if !ok {
+		return
+	}
+	if lane.current != nil && run.supersedes(lane.current) {
+		lane.current.cancel()
+	}
+	if lane.pending != nil && run.supersedes(lane.pending) {
+		lane.pending.drop()
+		lane.pending = nil
+	}
+}
+
+// latestDelta returns the newest delta run for the game in this process, or nil
+func (p *ChatProcessor) latestDelta(gameStateID uuid.UUID) *DeltaRun {
+	p.deltasMu.Lock()
+	defer p.deltasMu.Unlock()
+	if lane, ok := p.deltas[gameStateID]; ok {
+		return lane.latest()
+	}
+	return nil
+}
+
+// Drain waits for every game's background gamestate deltas to be saved, so a process
+// that is shutting down doesn't lose the last turns' state changes. Call it once nothing
+// else will start a turn, e.g. after the worker has stopped. If ctx ends first, the
+// deltas still waiting or running are cancelled and an error is returned.
+func (p *ChatProcessor) Drain(ctx context.Context) error {
+	p.deltasMu.Lock()
+	if len(p.deltas) == 0 {
+		p.deltasMu.Unlock()
+		return nil
+	}
+	if p.idle == nil {
+		p.idle = make(chan struct{})
+	}
+	idle := p.idle
+	p.deltasMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.deltasMu.Lock()
+	games := len(p.deltas)
+	for _, lane := range p.deltas {
+		if lane.current != nil {
+			lane.current.cancel()
+		}
+		if lane.pending != nil {
+			lane.pending.drop()
+			lane.pending = nil
+		}
+	}
+	p.deltasMu.Unlock()
+	return fmt.Errorf("gamestate deltas for %d games did not finish: %w", games, ctx.Err())
+}
@@ -0,0 +1,289 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/logger"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// laneLLM tracks how many gamestate deltas are in flight per game. If release is set,
+// each delta waits for it, after signalling entered.
+type laneLLM struct {
+	*stubLLMService
+	mu       sync.Mutex
+	inFlight map[string]int
+	maxSeen  int
+	calls    atomic.Int32
+	entered  chan struct{}
+	release  chan struct{}
+}
+
+func (l *laneLLM) DeltaUpdate(ctx context.Context, _ []chat.ChatMessage) (*conditionals.GameStateDelta, string, error) {
+	game := fmt.Sprint(ctx.Value(laneGameKey{}))
+	l.mu.Lock()
+	l.inFlight[game]++
+	l.maxSeen = max(l.maxSeen, l.inFlight[game])
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.inFlight[game]--
+		l.mu.Unlock()
+	}()
+
+	l.calls.Add(1)
+	if l.entered != nil {
+		l.entered <- struct{}{}
+	}
+	if l.release != nil {
+		select {
+		case <-l.release:
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		}
+	}
+	time.Sleep(time.Millisecond) // widen the window for overlapping deltas
+	return &conditionals.GameStateDelta{}, "", nil
+}
+
+type laneGameKey struct{}
+
+// copyStorage keeps each game as a copy, like a real store, so overlapping deltas would
+// lose each other's updates
+type copyStorage struct {
+	*stubStorage
+	mu    sync.Mutex
+	games map[uuid.UUID]*state.GameState
+}
+
+func (s *copyStorage) LoadGameState(_ context.Context, id uuid.UUID) (*state.GameState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.games[id].DeepCopy()
+}
+
+func (s *copyStorage) SaveGameState(_ context.Context, id uuid.UUID, gs *state.GameState) error {
+	saved, err := gs.DeepCopy()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.games[id] = saved
+	return nil
+}
+
+func newLaneProcessor(games ...*state.GameState) (*ChatProcessor, *laneLLM, *copyStorage) {
+	store := &copyStorage{stubStorage: &stubStorage{sc: &scenario.Scenario{}}, games: make(map[uuid.UUID]*state.GameState)}
+	for _, gs := range games {
+		store.games[gs.ID] = gs
+	}
+	llm := &laneLLM{stubLLMService: &stubLLMService{}, inFlight: make(map[string]int)}
+	return NewChatProcessor(store, llm, nil, slog.Default(), 0), llm, store
+}
+
+func TestDeltaLane_StressSingleFlight(t *testing.T) {
+	const games, turns = 4, 25
+
+	var all []*state.GameState
+	for range games {
+		all = append(all, &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: make(map[string]string)})
+	}
+	processor, llm, store := newLaneProcessor(all...)
+
+	var applied sync.Map // game ID → *atomic.Int32
+	processor.deltaApplied = func(_ context.Context, gs *state.GameState) {
+		n, _ := applied.LoadOrStore(gs.ID, new(atomic.Int32))
+		n.(*atomic.Int32).Add(1)
+	}
+
+	var wg sync.WaitGroup
+	for _, gs := range all {
+		for turn := range turns {
+			wg.Go(func() {
+				ctx := context.WithValue(logger.ContextWithRequestID(context.Background(), fmt.Sprintf("%s-%d", gs.ID, turn)), laneGameKey{}, gs.ID)
+				run := processor.StartDelta(ctx, gs, "I wait", state.TurnPlayer)
+				if turn%3 == 0 {
+					processor.supersedeDeltas(gs.ID, run)
+				}
+				run.finish("Time passes.")
+				run.wait()
+			})
+		}
+	}
+	wg.Wait()
+
+	if llm.maxSeen != 1 {
+		t.Errorf("Expected one delta in flight per game, saw %d", llm.maxSeen)
+	}
+	for _, gs := range all {
+		if processor.latestDelta(gs.ID) != nil {
+			t.Errorf("Expected game %s's lane to be gone", gs.ID)
+		}
+		var want int32
+		if n, ok := applied.Load(gs.ID); ok {
+			want = n.(*atomic.Int32).Load()
+		}
+		saved, _ := store.LoadGameState(context.Background(), gs.ID)
+		if int32(saved.TurnCounter) != want {
+			t.Errorf("Game %s: TurnCounter = %d after %d applied deltas; an update was lost", gs.ID, saved.TurnCounter, want)
+		}
+	}
+}
+
+func TestDeltaLane_LatestWins(t *testing.T) {
+	gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: make(map[string]string)}
+	processor, llm, _ := newLaneProcessor(gs)
+	llm.entered = make(chan struct{}, 3)
+	llm.release = make(chan struct{})
+
+	first := processor.StartDelta(context.Background(), gs, "I wait", state.TurnPlayer)
+	first.finish("Time passes.")
+	<-llm.entered
+
+	// Both wait behind the first; the newer replaces the older in the mailbox
+	second := processor.StartDelta(context.Background(), gs, "I wait again", state.TurnPlayer)
+	third := processor.StartDelta(context.Background(), gs, "I open the door", state.TurnPlayer)
+	second.wait()
+	if got := processor.latestDelta(gs.ID); got != third {
+		t.Fatal("Expected the newest run to be next")
+	}
+
+	third.finish("The door creaks open.")
+	close(llm.release)
+	first.wait()
+	third.wait()
+
+	if got := llm.calls.Load(); got != 2 {
+		t.Errorf("Expected the first and newest deltas to run, got %d delta calls", got)
+	}
+}
+
+func TestDeltaLane_LateNarrationKeepsNewerDelta(t *testing.T) {
+	t.Run("newer delta pending", func(t *testing.T) {
+		gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: make(map[string]string)}
+		processor, llm, store := newLaneProcessor(gs)
+		llm.entered = make(chan struct{}, 2)
+		llm.release = make(chan struct{})
+
+		// Turn N's run waits on the lane for its narration; turn N+1's waits behind it
+		older := processor.StartDelta(logger.ContextWithRequestID(context.Background(), "turn-1"), gs, "I wait", state.TurnPlayer)
+		waitForCurrent(processor, gs.ID, older)
+		newer := processor.StartDelta(logger.ContextWithRequestID(context.Background(), "turn-2"), gs, "I open the door", state.TurnPlayer)
+
+		// Turn N's narration finishes late
+		processor.supersedeDeltas(gs.ID, older)
+		older.finish("Time passes.")
+		<-llm.entered
+		llm.release <- struct{}{}
+		older.wait()
+
+		newer.finish("The door creaks open.")
+		releaseUnlessDone(llm, newer)
+
+		saved, _ := store.LoadGameState(context.Background(), gs.ID)
+		if saved.TurnCounter != 2 {
+			t.Errorf("Expected both deltas to be saved, got TurnCounter %d", saved.TurnCounter)
+		}
+	})
+
+	t.Run("newer delta running", func(t *testing.T) {
+		gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: make(map[string]string)}
+		processor, llm, store := newLaneProcessor(gs)
+		llm.entered = make(chan struct{}, 2)
+		llm.release = make(chan struct{})
+
+		first := processor.StartDelta(logger.ContextWithRequestID(context.Background(), "turn-1"), gs, "I wait", state.TurnPlayer)
+		first.finish("Time passes.")
+		<-llm.entered
+
+		// Turn N waits behind the first run until turn N+1 replaces it in the mailbox
+		older := processor.StartDelta(logger.ContextWithRequestID(context.Background(), "turn-2"), gs, "I wait again", state.TurnPlayer)
+		newer := processor.StartDelta(logger.ContextWithRequestID(context.Background(), "turn-3"), gs, "I open the door", state.TurnPlayer)
+		older.wait()
+		llm.release <- struct{}{}
+		first.wait()
+		waitForCurrent(processor, gs.ID, newer)
+
+		// Turn N's narration finishes while turn N+1's delta is running
+		processor.supersedeDeltas(gs.ID, older)
+		newer.finish("The door creaks open.")
+		releaseUnlessDone(llm, newer)
+
+		saved, _ := store.LoadGameState(context.Background(), gs.ID)
+		if saved.TurnCounter != 2 {
+			t.Errorf("Expected the newest delta to be saved, got TurnCounter %d", saved.TurnCounter)
+		}
+	})
+}
+
+// releaseUnlessDone lets run's delta call through, or returns once run is dropped or
+// cancelled without calling the LLM
+func releaseUnlessDone(llm *laneLLM, run *DeltaRun) {
+	select {
+	case <-llm.entered:
+		llm.release <- struct{}{}
+	case <-run.done:
+	}
+	run.wait()
+}
+
+// waitForCurrent waits until run is in progress on its game's lane
+func waitForCurrent(p *ChatProcessor, gameStateID uuid.UUID, run *DeltaRun) {
+	for {
+		p.deltasMu.Lock()
+		lane, ok := p.deltas[gameStateID]
+		current := ok && lane.current == run
+		p.deltasMu.Unlock()
+		if current {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestChatProcessor_Drain(t *testing.T) {
+	gs := &state.GameState{ID: uuid.New(), Scenario: "test.json", Vars: make(map[string]string)}
+	processor, llm, store := newLaneProcessor(gs)
+	llm.entered = make(chan struct{}, 1)
+	llm.release = make(chan struct{})
+
+	run := processor.StartDelta(context.Background(), gs, "I wait", state.TurnPlayer)
+	run.finish("Time passes.")
+	<-llm.entered
+
+	// The delta is still waiting on the LLM, so a short drain gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := processor.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the drain to time out, got %v", err)
+	}
+	run.wait()
+
+	// A delta that finishes in time is saved before the drain returns
+	second := processor.StartDelta(context.Background(), gs, "I wait again", state.TurnPlayer)
+	second.finish("More time passes.")
+	<-llm.entered
+	go close(llm.release)
+	if err := processor.Drain(context.Background()); err != nil {
+		t.Fatalf("Expected the drain to finish, got %v", err)
+	}
+	saved, _ := store.LoadGameState(context.Background(), gs.ID)
+	if saved.TurnCounter != 1 {
+		t.Errorf("Expected the second delta to be saved, got TurnCounter %d", saved.TurnCounter)
+	}
+	if processor.latestDelta(gs.ID) != nil {
+		t.Error("Expected the game's lane to be gone after the drain")
+	}
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/internal/services"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/prompts"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// GenerateVariants narrates a player turn req.Variants times in parallel, and keeps the
+// candidates pending on the game state for the client to choose from. Nothing is added
+// to the chat history and no delta runs until a candidate is chosen. Candidates whose
+// call fails are left out; it fails only if they all do.
+func (p *ChatProcessor) GenerateVariants(ctx context.Context, req chat.ChatRequest) ([]string, error) {
+	gs, err := p.GetGameState(ctx, req.GameStateID)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedScenario, err := p.storage.GetScenario(ctx, gs.Scenario)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scenario: %w", err)
+	}
+
+	length := narrationLength(gs, loadedScenario, req)
+	messages, err := prompts.New().
+		WithGameState(gs).
+		WithScenario(loadedScenario).
+		WithUserMessage(req.Message, chat.ChatRoleUser).
+		WithHistoryLimit(p.historyLimit).
+		WithPrefixCache(p.prefixes).
+		WithMaxSentences(length.MaxSentences).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build chat messages: %w", err)
+	}
+
+	type candidate struct {
+		narration string
+		err       error
+	}
+	llmCtx := p.narrationContext(ctx, gs, length)
+	temperature := turnTemperature(gs, loadedScenario, req)
+	results := make(chan candidate, req.Variants)
+	for range req.Variants {
+		go func() {
+			resp, err := p.llmService.Chat(llmCtx, messages, temperature)
+			if err != nil {
+				results <- candidate{err: err}
+				return
+			}
+			narration := p.narrationPipeline(loadedScenario, length, req.Message).Process(resp.Message)
+			results <- candidate{narration: strings.TrimRight(narration, "\n")}
+		}()
+	}
+
+	var candidates []string
+	var lastErr error
+	for range req.Variants {
+		c := <-results
+		if c.err != nil {
+			p.logger.WarnContext(ctx, "Failed to narrate a variant", "error", c.err, "game_state_id", gs.ID.String())
+			lastErr = c.err
+			continue
+		}
+		candidates = append(candidates, c.narration)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("LLM chat failed: %w", lastErr)
+	}
+
+	gs.PendingVariants = &state.PendingVariants{
+		Message:    req.Message,
+		FreeAction: req.FreeAction,
+		Candidates: candidates,
+	}
+	if err := p.storage.SaveGameState(ctx, gs.ID, gs); err != nil {
+		return nil, fmt.Errorf("failed to save narration variants: %w", err)
+	}
+	return candidates, nil
+}
+
+// chosenStream delivers a chosen variant as one final chunk, so the turn can be
+// played like a streamed one. It has already been through the narration pipeline.
+func chosenStream(narration string) <-chan services.StreamChunk {
+	ch := make(chan services.StreamChunk, 1)
+	ch <- services.StreamChunk{Content: narration, Done: true}
+	close(ch)
+	return ch
+}
@@ -2,15 +2,20 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/internal/logger"
 	"github.com/jwebster45206/story-engine/internal/services/events"
-	"github.com/jwebster45206/story-engine/internal/services/queue"
 	"github.com/jwebster45206/story-engine/pkg/chat"
 	queuePkg "github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -18,20 +23,31 @@ const (
 	workerTimeout = 5 * time.Second
 )
 
+// RequestQueue is the global request queue a worker takes requests from.
+// queue.ChatQueue keeps it in Redis and queue.MemoryQueue in memory.
+type RequestQueue interface {
+	EnqueueRequest(ctx context.Context, req *queuePkg.Request) error
+	BlockingDequeueRequest(ctx context.Context, timeout time.Duration) (*queuePkg.Request, error)
+}
+
 // Worker processes messages in the chat queue
 type Worker struct {
 	id          string
-	queue       *queue.ChatQueue
+	queue       RequestQueue
 	processor   *ChatProcessor
+	profiles    map[string]*ChatProcessor // processors for named profiles
 	broadcaster *events.Broadcaster
+	spectators  *events.SpectatorFeed
 	redisClient *redis.Client
 	log         *slog.Logger
-	ctx         context.Context
+	ctx         context.Context // ends when the worker is told to stop taking requests
 	cancel      context.CancelFunc
+	started     atomic.Bool
+	stopped     chan struct{} // closed when Start returns
 }
 
 // New creates a new worker instance
-func New(queueClient *queue.ChatQueue, processor *ChatProcessor, redisClient *redis.Client, log *slog.Logger, workerID string) *Worker {
+func New(queueClient RequestQueue, processor *ChatProcessor, redisClient *redis.Client, log *slog.Logger, workerID string) *Worker {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	if workerID == "" {
@@ -40,21 +56,57 @@ func New(queueClient *queue.ChatQueue, processor *ChatProcessor, redisClient *re
 
 	broadcaster := events.NewBroadcaster(redisClient, log)
 
-	return &Worker{
+	w := &Worker{
 		id:          workerID,
 		queue:       queueClient,
 		processor:   processor,
 		broadcaster: broadcaster,
+		spectators:  events.NewSpectatorFeed(redisClient, log),
 		redisClient: redisClient,
 		log:         log,
 		ctx:         ctx,
 		cancel:      cancel,
+		stopped:     make(chan struct{}),
+	}
+	processor.deltaApplied = w.publishStateUpdated
+	return w
+}
+
+// WithProfileProcessors sets the processors used for requests from named profiles,
+// each with its own storage and LLM service. Requests without a profile use the
+// default processor.
+func (w *Worker) WithProfileProcessors(profiles map[string]*ChatProcessor) *Worker {
+	w.profiles = profiles
+	for _, processor := range profiles {
+		processor.deltaApplied = w.publishStateUpdated
 	}
+	return w
+}
+
+// WithProfanityFilter sets the profanity filter, with configured word lists, used for
+// the spectator feed
+func (w *Worker) WithProfanityFilter(filter *textfilter.ProfanityFilter) *Worker {
+	w.spectators.WithProfanityFilter(filter)
+	return w
+}
+
+// processorFor returns the processor for the request's profile
+func (w *Worker) processorFor(req *queuePkg.Request) (*ChatProcessor, error) {
+	if req.Profile == "" {
+		return w.processor, nil
+	}
+	processor, ok := w.profiles[req.Profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile: %s", req.Profile)
+	}
+	return processor, nil
 }
 
 // Start begins processing requests from the queue
 func (w *Worker) Start() error {
 	w.log.Info("Worker starting", "worker_id", w.id)
+	w.started.Store(true)
+	defer close(w.stopped)
 
 	for {
 		select {
@@ -71,12 +123,32 @@ func (w *Worker) Start() error {
 	}
 }
 
-// Stop gracefully shuts down the worker
+// Stop tells the worker to stop taking requests. The request in progress, if any, runs
+// to completion; see Shutdown to wait for it.
 func (w *Worker) Stop() {
 	w.log.Info("Worker stop requested", "worker_id", w.id)
 	w.cancel()
 }
 
+// Shutdown stops the worker, then waits for the request in progress and every
+// processor's background gamestate deltas, until ctx ends
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.Stop()
+	if w.started.Load() {
+		select {
+		case <-w.stopped:
+		case <-ctx.Done():
+			return fmt.Errorf("request in progress did not finish: %w", ctx.Err())
+		}
+	}
+
+	errs := []error{w.processor.Drain(ctx)}
+	for _, processor := range w.profiles {
+		errs = append(errs, processor.Drain(ctx))
+	}
+	return errors.Join(errs...)
+}
+
 // processNextRequest pulls the next request from the queue and processes it
 func (w *Worker) processNextRequest() error {
 	// Block waiting for next request (timeout after 5 seconds to check for shutdown)
@@ -114,7 +186,7 @@ func (w *Worker) processNextRequest() error {
 			"request_id", req.RequestID,
 			"game_state_id", req.GameStateID.String(),
 		)
-		if err := w.queue.EnqueueRequest(w.ctx, req); err != nil {
+		if err := w.queue.EnqueueRequest(context.WithoutCancel(w.ctx), req); err != nil {
 			return fmt.Errorf("failed to re-queue request: %w", err)
 		}
 		return nil
@@ -157,98 +229,198 @@ func (w *Worker) releaseGameLock(gameStateID uuid.UUID) {
 		end
 	`)
 
-	if err := script.Run(w.ctx, w.redisClient, []string{lockKey}, w.id).Err(); err != nil {
+	// The lock is released even when the worker is stopping
+	if err := script.Run(context.WithoutCancel(w.ctx), w.redisClient, []string{lockKey}, w.id).Err(); err != nil {
 		w.log.Error("Failed to release game lock", "error", err, "game_state_id", gameStateID.String())
 	}
 }
 
 // processRequest processes a single request using the ChatProcessor
 func (w *Worker) processRequest(req *queuePkg.Request) error {
-	w.log.Info("Processing request",
+	// Logs made with ctx carry the request ID the API gave the request. Stopping the
+	// worker doesn't cancel a request it has taken.
+	ctx := logger.ContextWithRequestID(context.WithoutCancel(w.ctx), req.RequestID)
+	w.log.InfoContext(ctx, "Processing request",
 		"worker_id", w.id,
 		"request_id", req.RequestID,
 		"type", req.Type,
 		"game_state_id", req.GameStateID.String(),
+		"profile", req.Profile,
 	)
 
 	start := time.Now()
 
-	gs, err := w.processor.GetGameState(w.ctx, req.GameStateID)
+	processor, err := w.processorFor(req)
+	if err != nil {
+		if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+			w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
+		}
+		return err
+	}
+
+	gs, err := processor.GetGameState(ctx, req.GameStateID)
 	if err != nil {
-		w.log.Error("Failed to load game state",
+		w.log.ErrorContext(ctx, "Failed to load game state",
 			"error", err,
 			"request_id", req.RequestID,
 		)
-		if pubErr := w.broadcaster.PublishRequestFailed(w.ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
-			w.log.Error("Failed to publish failure event", "error", pubErr)
+		if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+			w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
 		}
 		return fmt.Errorf("failed to load game state: %w", err)
 	}
+	startInventory := append([]string(nil), gs.Inventory...)
 
-	var userMessage string
+	if gs.Paused {
+		return w.handlePaused(ctx, processor, gs, req)
+	}
+	if gs.TrialExhausted() {
+		return w.handleTrialEnded(ctx, gs, req)
+	}
+
+	var userMessage, playerMessage, chosen string
 	switch req.Type {
 	case queuePkg.RequestTypeChat:
-		// Format message with PC name prefix if available
-		userMessage = req.Message
+		// Expand shortcuts such as "n", then format message with PC name prefix if available
+		playerMessage = processor.ExpandAliases(ctx, gs, req.Message)
+		userMessage = playerMessage
 		if gs.PC != nil && gs.PC.Spec != nil && gs.PC.Spec.Name != "" {
-			userMessage = chat.FormatWithPCName(req.Message, gs.PC.Spec.Name)
+			userMessage = chat.FormatWithPCName(playerMessage, gs.PC.Spec.Name)
 		}
 	case queuePkg.RequestTypeStoryEvent:
 		userMessage = req.EventPrompt
+	case queuePkg.RequestTypeRegenerate:
+		// Undo the last player turn, then play it again from its original message below
+		rewound, turn, err := processor.RewindLastTurn(ctx, req.GameStateID)
+		if err != nil {
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
+			}
+			return fmt.Errorf("failed to rewind last turn: %w", err)
+		}
+		gs = rewound
+		startInventory = append([]string(nil), gs.Inventory...)
+		userMessage = turn.Message
+		req.FreeAction = turn.FreeAction
+	case queuePkg.RequestTypeChooseVariant:
+		// The chosen narration is played below as the turn's response
+		pending, narration, err := gs.ChooseVariant(req.Variant)
+		if err != nil {
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
+			}
+			return fmt.Errorf("failed to choose variant: %w", err)
+		}
+		userMessage = pending.Message
+		req.FreeAction = pending.FreeAction
+		chosen = narration
 	default:
 		userMessage = ""
 	}
 
 	// Publish processing event with formatted user message
-	if err := w.broadcaster.PublishRequestProcessing(w.ctx, req.GameStateID, req.RequestID, string(req.Type), userMessage); err != nil {
-		w.log.Error("Failed to publish processing event", "error", err)
+	if err := w.broadcaster.PublishRequestProcessing(ctx, req.GameStateID, req.RequestID, string(req.Type), userMessage); err != nil {
+		w.log.ErrorContext(ctx, "Failed to publish processing event", "error", err)
 		// Don't fail the request just because event publishing failed
 	}
 
 	switch req.Type {
-	case queuePkg.RequestTypeChat:
+	case queuePkg.RequestTypeChat, queuePkg.RequestTypeRegenerate, queuePkg.RequestTypeChooseVariant:
+		// Server commands such as /inventory are answered from the game state. Regenerated
+		// and chosen turns have no player message of their own, so they are never commands.
+		reply, handled, err := processor.HandleCommand(ctx, gs, playerMessage, req.Embellish)
+		if err != nil {
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
+			}
+			return fmt.Errorf("failed to handle command: %w", err)
+		}
+		if handled {
+			if err := w.broadcaster.PublishChatChunk(ctx, req.GameStateID, req.RequestID, reply, true); err != nil {
+				w.log.ErrorContext(ctx, "Failed to publish chat chunk", "error", err)
+			}
+			result := map[string]interface{}{
+				"message":     reply,
+				"duration_ms": time.Since(start).Milliseconds(),
+				"state":       gs.Summary(startInventory),
+				"command":     true,
+			}
+			if err := w.broadcaster.PublishRequestCompleted(ctx, req.GameStateID, req.RequestID, result); err != nil {
+				w.log.ErrorContext(ctx, "Failed to publish completion event", "error", err)
+			}
+			w.log.InfoContext(ctx, "Command handled",
+				"worker_id", w.id,
+				"request_id", req.RequestID,
+				"embellish", req.Embellish,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+			return nil
+		}
+
 		// Convert queue request to chat request (using pre-formatted message)
 		chatReq := chat.ChatRequest{
-			GameStateID: req.GameStateID,
-			Message:     userMessage,
+			GameStateID:  req.GameStateID,
+			Message:      userMessage,
+			FreeAction:   req.FreeAction,
+			Variants:     req.Variants,
+			Regenerate:   req.Type == queuePkg.RequestTypeRegenerate,
+			MaxTokens:    req.MaxTokens,
+			MaxSentences: req.MaxSentences,
+		}
+		if chatReq.Variants > 1 {
+			return w.processVariants(ctx, processor, gs, req, chatReq, start, startInventory)
 		}
 
-		// Process using streaming ChatProcessor
-		streamChan, storyEventPrompt, err := w.processor.ProcessChatStream(w.ctx, chatReq)
+		// Start the gamestate delta alongside the narration, then process using streaming
+		// ChatProcessor. A chosen variant is already narrated.
+		run := processor.StartDelta(ctx, gs, userMessage, turnKind(chatReq))
+		streamChan, storyEventPrompt := chosenStream(chosen), ""
+		if req.Type != queuePkg.RequestTypeChooseVariant {
+			streamChan, storyEventPrompt, err = processor.ProcessChatStream(ctx, chatReq)
+		}
 		if err != nil {
-			w.log.Error("Failed to start chat stream",
+			run.Abort()
+			w.log.ErrorContext(ctx, "Failed to start chat stream",
 				"error", err,
 				"request_id", req.RequestID,
 				"game_state_id", req.GameStateID.String(),
 			)
 
 			// Publish failure event
-			if pubErr := w.broadcaster.PublishRequestFailed(w.ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
-				w.log.Error("Failed to publish failure event", "error", pubErr)
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
 			}
 
 			return fmt.Errorf("failed to process chat request: %w", err)
 		}
 
-		// Stream chunks to SSE as they arrive
-		var fullMessage string
+		// Stream chunks to SSE as they arrive. A preview's watermark follows the
+		// narration but isn't saved with it.
+		var fullMessage, trailer string
 		var streamErr error
+		watermark := trialWatermark(gs)
 
 		for chunk := range streamChan {
 			if chunk.Error != nil {
 				streamErr = chunk.Error
-				w.log.Error("Error in chat stream",
+				w.log.ErrorContext(ctx, "Error in chat stream",
 					"error", chunk.Error,
 					"request_id", req.RequestID,
 				)
+				fullMessage += w.publishHeldBack(ctx, req, chunk.Content)
 				break
 			}
 
 			fullMessage += chunk.Content
+			trailer += chunk.Trailer
+			content := chunk.Content
+			if chunk.Done {
+				content += watermark
+			}
 
 			// Publish chunk to SSE
-			if err := w.broadcaster.PublishChatChunk(w.ctx, req.GameStateID, req.RequestID, chunk.Content, chunk.Done); err != nil {
-				w.log.Error("Failed to publish chat chunk", "error", err)
+			if err := w.broadcaster.PublishChatChunk(ctx, req.GameStateID, req.RequestID, content, chunk.Done); err != nil {
+				w.log.ErrorContext(ctx, "Failed to publish chat chunk", "error", err)
 				// Don't fail the stream, just log it
 			}
 
@@ -257,42 +429,45 @@ func (w *Worker) processRequest(req *queuePkg.Request) error {
 			}
 		}
 
+		if streamErr != nil && strings.TrimSpace(fullMessage) != "" {
+			return w.salvageNarration(ctx, processor, gs, run, req, userMessage, fullMessage, turnKind(chatReq), streamErr, start, startInventory)
+		}
 		if streamErr != nil {
+			run.Abort()
+
 			// Publish failure event
-			if pubErr := w.broadcaster.PublishRequestFailed(w.ctx, req.GameStateID, req.RequestID, streamErr.Error()); pubErr != nil {
-				w.log.Error("Failed to publish failure event", "error", pubErr)
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, streamErr.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
 			}
 			return fmt.Errorf("failed to process chat request: %w", streamErr)
 		}
 
 		// Update game state with the full streamed message (using pre-formatted userMessage)
-		if err := w.processor.UpdateGameStateAfterStream(gs, userMessage, fullMessage, storyEventPrompt, false); err != nil {
-			w.log.Error("Failed to update game state after stream",
+		if err := processor.UpdateGameStateAfterStream(ctx, gs, run, userMessage, fullMessage, trailer, storyEventPrompt, turnKind(chatReq)); err != nil {
+			w.log.ErrorContext(ctx, "Failed to update game state after stream",
 				"error", err,
 				"request_id", req.RequestID,
 			)
 
 			// Publish failure event
-			if pubErr := w.broadcaster.PublishRequestFailed(w.ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
-				w.log.Error("Failed to publish failure event", "error", pubErr)
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
 			}
 
 			return fmt.Errorf("failed to update game state: %w", err)
 		}
+		w.publishToSpectators(gs, fullMessage)
 
-		w.log.Info("Chat request processed successfully",
+		w.log.InfoContext(ctx, "Chat request processed successfully",
 			"worker_id", w.id,
 			"request_id", req.RequestID,
 			"duration_ms", time.Since(start).Milliseconds(),
 		)
 
 		// Publish completion event with full message
-		result := map[string]interface{}{
-			"message":     fullMessage,
-			"duration_ms": time.Since(start).Milliseconds(),
-		}
-		if err := w.broadcaster.PublishRequestCompleted(w.ctx, req.GameStateID, req.RequestID, result); err != nil {
-			w.log.Error("Failed to publish completion event", "error", err)
+		result := w.completionResult(ctx, processor, gs, fullMessage+watermark, start, startInventory)
+		if err := w.broadcaster.PublishRequestCompleted(ctx, req.GameStateID, req.RequestID, result); err != nil {
+			w.log.ErrorContext(ctx, "Failed to publish completion event", "error", err)
 		}
 
 	case queuePkg.RequestTypeStoryEvent:
@@ -305,42 +480,51 @@ func (w *Worker) processRequest(req *queuePkg.Request) error {
 			Message:     storyEventMessage,
 		}
 
-		// Process using streaming ChatProcessor
-		streamChan, storyEventPrompt, err := w.processor.ProcessChatStream(w.ctx, chatReq)
+		// Story events belong to the main story, so they break off any side conversation
+		if _, _, err := processor.EndConversation(ctx, gs); err != nil {
+			w.log.ErrorContext(ctx, "Failed to end conversation for story event", "error", err, "request_id", req.RequestID)
+		}
+
+		// Start the gamestate delta alongside the narration, then process using streaming ChatProcessor
+		run := processor.StartDelta(ctx, gs, storyEventMessage, state.TurnSystem)
+		streamChan, storyEventPrompt, err := processor.ProcessChatStream(ctx, chatReq)
 		if err != nil {
-			w.log.Error("Failed to start story event stream",
+			run.Abort()
+			w.log.ErrorContext(ctx, "Failed to start story event stream",
 				"error", err,
 				"request_id", req.RequestID,
 				"game_state_id", req.GameStateID.String(),
 			)
 
 			// Publish failure event
-			if pubErr := w.broadcaster.PublishRequestFailed(w.ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
-				w.log.Error("Failed to publish failure event", "error", pubErr)
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
 			}
 
 			return fmt.Errorf("failed to process story event: %w", err)
 		}
 
 		// Stream chunks to SSE as they arrive
-		var fullMessage string
+		var fullMessage, trailer string
 		var streamErr error
 
 		for chunk := range streamChan {
 			if chunk.Error != nil {
 				streamErr = chunk.Error
-				w.log.Error("Error in story event stream",
+				w.log.ErrorContext(ctx, "Error in story event stream",
 					"error", chunk.Error,
 					"request_id", req.RequestID,
 				)
+				fullMessage += w.publishHeldBack(ctx, req, chunk.Content)
 				break
 			}
 
 			fullMessage += chunk.Content
+			trailer += chunk.Trailer
 
 			// Publish chunk to SSE
-			if err := w.broadcaster.PublishChatChunk(w.ctx, req.GameStateID, req.RequestID, chunk.Content, chunk.Done); err != nil {
-				w.log.Error("Failed to publish chat chunk", "error", err)
+			if err := w.broadcaster.PublishChatChunk(ctx, req.GameStateID, req.RequestID, chunk.Content, chunk.Done); err != nil {
+				w.log.ErrorContext(ctx, "Failed to publish chat chunk", "error", err)
 				// Don't fail the stream, just log it
 			}
 
@@ -349,63 +533,238 @@ func (w *Worker) processRequest(req *queuePkg.Request) error {
 			}
 		}
 
+		if streamErr != nil && strings.TrimSpace(fullMessage) != "" {
+			return w.salvageNarration(ctx, processor, gs, run, req, storyEventMessage, fullMessage, state.TurnSystem, streamErr, start, startInventory)
+		}
 		if streamErr != nil {
+			run.Abort()
+
 			// Publish failure event
-			if pubErr := w.broadcaster.PublishRequestFailed(w.ctx, req.GameStateID, req.RequestID, streamErr.Error()); pubErr != nil {
-				w.log.Error("Failed to publish failure event", "error", pubErr)
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, streamErr.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
 			}
 			return fmt.Errorf("failed to process story event: %w", streamErr)
 		}
 
 		// Load game state to update it
-		gs, err := w.processor.GetGameState(w.ctx, req.GameStateID)
+		gs, err := processor.GetGameState(ctx, req.GameStateID)
 		if err != nil {
-			w.log.Error("Failed to load game state for update",
+			w.log.ErrorContext(ctx, "Failed to load game state for update",
 				"error", err,
 				"request_id", req.RequestID,
 			)
 
 			// Publish failure event
-			if pubErr := w.broadcaster.PublishRequestFailed(w.ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
-				w.log.Error("Failed to publish failure event", "error", pubErr)
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
 			}
 
+			run.Abort()
 			return fmt.Errorf("failed to load game state: %w", err)
 		}
 
 		// Update game state with the full streamed message
-		if err := w.processor.UpdateGameStateAfterStream(gs, storyEventMessage, fullMessage, storyEventPrompt, true); err != nil {
-			w.log.Error("Failed to update game state after stream",
+		if err := processor.UpdateGameStateAfterStream(ctx, gs, run, storyEventMessage, fullMessage, trailer, storyEventPrompt, state.TurnSystem); err != nil {
+			w.log.ErrorContext(ctx, "Failed to update game state after stream",
 				"error", err,
 				"request_id", req.RequestID,
 			)
 
 			// Publish failure event
-			if pubErr := w.broadcaster.PublishRequestFailed(w.ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
-				w.log.Error("Failed to publish failure event", "error", pubErr)
+			if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+				w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
 			}
 
 			return fmt.Errorf("failed to update game state: %w", err)
 		}
+		w.publishToSpectators(gs, fullMessage)
 
-		w.log.Info("Story event processed successfully",
+		w.log.InfoContext(ctx, "Story event processed successfully",
 			"worker_id", w.id,
 			"request_id", req.RequestID,
 			"duration_ms", time.Since(start).Milliseconds(),
 		)
 
 		// Publish completion event with full message
-		result := map[string]interface{}{
-			"message":     fullMessage,
-			"duration_ms": time.Since(start).Milliseconds(),
-		}
-		if err := w.broadcaster.PublishRequestCompleted(w.ctx, req.GameStateID, req.RequestID, result); err != nil {
-			w.log.Error("Failed to publish completion event", "error", err)
+		result := w.completionResult(ctx, processor, gs, fullMessage, start, startInventory)
+		if err := w.broadcaster.PublishRequestCompleted(ctx, req.GameStateID, req.RequestID, result); err != nil {
+			w.log.ErrorContext(ctx, "Failed to publish completion event", "error", err)
 		}
 
+	case queuePkg.RequestTypeContinue:
+		return w.processContinue(ctx, processor, req, start)
+
 	default:
 		return fmt.Errorf("unknown request type: %s", req.Type)
 	}
 
 	return nil
 }
+
+// processVariants narrates a chat turn as several candidates and reports them in the
+// completion event for the client to choose from. The turn is played once it does.
+func (w *Worker) processVariants(ctx context.Context, processor *ChatProcessor, gs *state.GameState, req *queuePkg.Request, chatReq chat.ChatRequest, start time.Time, startInventory []string) error {
+	candidates, err := processor.GenerateVariants(ctx, chatReq)
+	if err != nil {
+		w.log.ErrorContext(ctx, "Failed to generate narration variants",
+			"error", err,
+			"request_id", req.RequestID,
+			"game_state_id", req.GameStateID.String(),
+		)
+		if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, err.Error()); pubErr != nil {
+			w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
+		}
+		return fmt.Errorf("failed to generate variants: %w", err)
+	}
+
+	w.log.InfoContext(ctx, "Narration variants generated",
+		"worker_id", w.id,
+		"request_id", req.RequestID,
+		"variants", len(candidates),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	result := map[string]interface{}{
+		"variants":    candidates,
+		"duration_ms": time.Since(start).Milliseconds(),
+		"state":       gs.Summary(startInventory),
+	}
+	if err := w.broadcaster.PublishRequestCompleted(ctx, req.GameStateID, req.RequestID, result); err != nil {
+		w.log.ErrorContext(ctx, "Failed to publish completion event", "error", err)
+	}
+	return nil
+}
+
+// publishHeldBack publishes narration the post-processor held back until the stream
+// failed, and returns it
+func (w *Worker) publishHeldBack(ctx context.Context, req *queuePkg.Request, content string) string {
+	if content == "" {
+		return ""
+	}
+	if err := w.broadcaster.PublishChatChunk(ctx, req.GameStateID, req.RequestID, content, false); err != nil {
+		w.log.ErrorContext(ctx, "Failed to publish chat chunk", "error", err)
+	}
+	return content
+}
+
+// salvageNarration keeps the narration a failed stream had already delivered as the
+// turn's response, marked incomplete, and completes the request with it. The completion
+// event carries "incomplete" and the error, so the client can offer to continue.
+func (w *Worker) salvageNarration(ctx context.Context, processor *ChatProcessor, gs *state.GameState, run *DeltaRun, req *queuePkg.Request, userMessage, partial string, kind state.TurnKind, streamErr error, start time.Time, startInventory []string) error {
+	if err := processor.SalvagePartialStream(ctx, gs, run, userMessage, partial, kind); err != nil {
+		w.log.ErrorContext(ctx, "Failed to save partial narration",
+			"error", err,
+			"request_id", req.RequestID,
+		)
+		if pubErr := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, streamErr.Error()); pubErr != nil {
+			w.log.ErrorContext(ctx, "Failed to publish failure event", "error", pubErr)
+		}
+		return fmt.Errorf("failed to save partial narration: %w", err)
+	}
+	w.publishToSpectators(gs, partial)
+
+	w.log.WarnContext(ctx, "Narration cut off; partial narration kept",
+		"worker_id", w.id,
+		"request_id", req.RequestID,
+		"error", streamErr,
+		"chars", len(partial),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	result := w.completionResult(ctx, processor, gs, partial, start, startInventory)
+	result["incomplete"] = true
+	result["error"] = streamErr.Error()
+	if err := w.broadcaster.PublishRequestCompleted(ctx, req.GameStateID, req.RequestID, result); err != nil {
+		w.log.ErrorContext(ctx, "Failed to publish completion event", "error", err)
+	}
+	return nil
+}
+
+// publishStateUpdated tells clients that a turn's gamestate delta has been applied,
+// so they can refresh the game state
+func (w *Worker) publishStateUpdated(ctx context.Context, gs *state.GameState) {
+	if err := w.broadcaster.PublishGameStateUpdated(ctx, gs.ID, gs.TurnCounter, gs.Location); err != nil {
+		w.log.ErrorContext(ctx, "Failed to publish game state update", "error", err, "game_state_id", gs.ID.String())
+	}
+}
+
+// publishToSpectators mirrors a turn's narration to the spectator feed of a public game.
+// The player's message and the preview watermark are never part of it.
+func (w *Worker) publishToSpectators(gs *state.GameState, narration string) {
+	if !gs.Spectators {
+		return
+	}
+	if err := w.spectators.Publish(context.WithoutCancel(w.ctx), gs.ID, narration); err != nil {
+		w.log.Error("Failed to publish to spectators", "error", err, "game_state_id", gs.ID.String())
+	}
+}
+
+// handlePaused rejects a chat sent to a paused game, or holds a story event until the game resumes
+func (w *Worker) handlePaused(ctx context.Context, processor *ChatProcessor, gs *state.GameState, req *queuePkg.Request) error {
+	if req.Type == queuePkg.RequestTypeStoryEvent {
+		gs.HeldStoryEvents = append(gs.HeldStoryEvents, req.EventPrompt)
+		if err := processor.SaveGameState(ctx, gs); err != nil {
+			return fmt.Errorf("failed to hold story event for paused game: %w", err)
+		}
+		w.log.InfoContext(ctx, "Game is paused, holding story event until resume",
+			"worker_id", w.id,
+			"request_id", req.RequestID,
+			"game_state_id", req.GameStateID.String(),
+		)
+		return nil
+	}
+
+	w.log.InfoContext(ctx, "Game is paused, rejecting request",
+		"worker_id", w.id,
+		"request_id", req.RequestID,
+		"game_state_id", req.GameStateID.String(),
+	)
+	if err := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, gs.PausedMessage()); err != nil {
+		w.log.ErrorContext(ctx, "Failed to publish failure event", "error", err)
+	}
+	return nil
+}
+
+// handleTrialEnded rejects a chat sent to a preview game that has used all its turns,
+// or drops a story event that came due after the preview ended
+func (w *Worker) handleTrialEnded(ctx context.Context, gs *state.GameState, req *queuePkg.Request) error {
+	w.log.InfoContext(ctx, "Preview has ended, rejecting request",
+		"worker_id", w.id,
+		"request_id", req.RequestID,
+		"type", req.Type,
+		"game_state_id", req.GameStateID.String(),
+	)
+	if req.Type == queuePkg.RequestTypeStoryEvent {
+		return nil
+	}
+	if err := w.broadcaster.PublishRequestFailed(ctx, req.GameStateID, req.RequestID, gs.Trial.EndedMessage()); err != nil {
+		w.log.ErrorContext(ctx, "Failed to publish failure event", "error", err)
+	}
+	return nil
+}
+
+// completionResult builds the request.completed payload: the full narration,
+// a state summary so clients can skip a follow-up fetch, and suggested
+// actions when the game is in choices mode.
+func (w *Worker) completionResult(ctx context.Context, processor *ChatProcessor, gs *state.GameState, fullMessage string, start time.Time, startInventory []string) map[string]interface{} {
+	// Prefer the latest stored state; a background delta may have landed during streaming
+	latest := gs
+	if stored, err := processor.GetGameState(ctx, gs.ID); err == nil {
+		latest = stored
+	} else {
+		w.log.WarnContext(ctx, "Failed to reload game state for completion summary", "error", err, "game_state_id", gs.ID.String())
+	}
+
+	result := map[string]interface{}{
+		"message":     fullMessage,
+		"segments":    narrationSegments(latest, fullMessage),
+		"duration_ms": time.Since(start).Milliseconds(),
+		"state":       latest.Summary(startInventory),
+	}
+	if latest.ChoicesMode && !latest.IsEnded {
+		if choices := processor.SuggestChoices(ctx, latest, fullMessage); len(choices) > 0 {
+			result["choices"] = choices
+		}
+	}
+	return result
+}
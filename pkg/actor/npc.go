@@ -1,6 +1,12 @@
 package actor
 
-import "github.com/jwebster45206/story-engine/pkg/conditionals"
+import (
+	"fmt"
+	"maps"
+
+	"github.com/jwebster45206/d20"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
 
 // NPC represents a non-player character in the game.
 // NPCs can be defined inline in a scenario or loaded from a standalone JSON
@@ -12,23 +18,25 @@ type NPC struct {
 	// Leave empty for fully inline NPCs (original behavior, unchanged).
 	TemplateID string `json:"template_id,omitempty"`
 
-	Name        string `json:"name"`
-	Type        string `json:"type"`                  // e.g. "villager", "guard", "merchant"
-	Disposition string `json:"disposition"`           // e.g. "hostile", "neutral", "friendly"
-	Description string `json:"description,omitempty"` // short description or backstory
-	IsImportant bool   `json:"important,omitempty"`   // whether this NPC is important to the story
-	Location    string `json:"location,omitempty"`    // where the NPC is currently located
-	Following   string `json:"following,omitempty"`   // ID of actor being followed ("pc" or NPC ID); empty = not following
-	Items       []string `json:"items,omitempty"`     // items the NPC has or can give
-
-	// Actor properties — only populated for standalone NPCs loaded from templates.
-	// These are optional even in standalone files; omit them for purely narrative NPCs.
-	AC               int            `json:"ac,omitempty"`
-	HP               int            `json:"hp,omitempty"`
-	MaxHP            int            `json:"max_hp,omitempty"`
-	Attributes       map[string]int `json:"attributes,omitempty"`        // e.g. {"strength": 14, "dexterity": 12}
-	CombatMods       map[string]int `json:"combat_modifiers,omitempty"`  // e.g. {"sword": 3}
-	DropItemsOnDefeat bool          `json:"drop_items_on_defeat,omitempty"`
+	ID          string   `json:"id,omitempty"`          // stable ID that saved games and references use; defaults to the map key
+	Name        string   `json:"name"`                  // display name; can change freely
+	Type        string   `json:"type"`                  // e.g. "villager", "guard", "merchant"
+	Disposition string   `json:"disposition"`           // e.g. "hostile", "neutral", "friendly"
+	Description string   `json:"description,omitempty"` // short description or backstory
+	IsImportant bool     `json:"important,omitempty"`   // whether this NPC is important to the story
+	Location    string   `json:"location,omitempty"`    // where the NPC is currently located
+	Following   string   `json:"following,omitempty"`   // ID of actor being followed ("pc" or NPC ID); empty = not following
+	Items       []string `json:"items,omitempty"`       // items the NPC has or can give
+
+	// Actor properties, inline or from a template. All optional; omit them for purely
+	// narrative NPCs. An NPC with MaxHP can fight; see Actor.
+	Stats             *Stats5e       `json:"stats,omitempty"` // ability scores, merged into Attributes
+	AC                int            `json:"ac,omitempty"`
+	HP                int            `json:"hp,omitempty"` // current HP; starts at MaxHP when omitted
+	MaxHP             int            `json:"max_hp,omitempty"`
+	Attributes        map[string]int `json:"attributes,omitempty"`       // e.g. {"strength": 14, "dexterity": 12}
+	CombatMods        map[string]int `json:"combat_modifiers,omitempty"` // e.g. {"sword": 3}
+	DropItemsOnDefeat bool           `json:"drop_items_on_defeat,omitempty"`
 
 	ContingencyPrompts []conditionals.ContingencyPrompt `json:"contingency_prompts,omitempty"` // NPC-specific prompts shown when at player location
 }
@@ -50,6 +58,9 @@ func NewNPCFromTemplate(template *NPC, overrides *NPC) *NPC {
 	n.TemplateID = template.TemplateID
 
 	// Scalar string overrides
+	if overrides.ID != "" {
+		n.ID = overrides.ID
+	}
 	if overrides.Name != "" {
 		n.Name = overrides.Name
 	}
@@ -88,6 +99,11 @@ func NewNPCFromTemplate(template *NPC, overrides *NPC) *NPC {
 		n.MaxHP = overrides.MaxHP
 	}
 
+	if overrides.Stats != nil {
+		stats := *overrides.Stats
+		n.Stats = &stats
+	}
+
 	// Map overrides (merge on top of template)
 	if len(overrides.Attributes) > 0 {
 		if n.Attributes == nil {
@@ -147,3 +163,47 @@ func (n *NPC) Heal(amount int) {
 		n.HP = n.MaxHP
 	}
 }
+
+// HasActor reports whether the NPC has the hit points to be a d20 actor
+func (n *NPC) HasActor() bool {
+	return n.MaxHP > 0
+}
+
+// InitHP starts the NPC at MaxHP when its HP was left out. Call it only on NPCs fresh
+// from a scenario, as a defeated NPC also has no HP.
+func (n *NPC) InitHP() {
+	if n.MaxHP > 0 && n.HP == 0 {
+		n.HP = n.MaxHP
+	}
+}
+
+// AllAttributes returns the NPC's ability scores from Stats with its Attributes on top
+func (n *NPC) AllAttributes() map[string]int {
+	attrs := make(map[string]int)
+	if n.Stats != nil {
+		attrs = n.Stats.ToAttributes()
+	}
+	maps.Copy(attrs, n.Attributes)
+	return attrs
+}
+
+// Actor builds a d20 actor from the NPC's current stats. Build it again after the
+// NPC's HP changes; the NPC, not the actor, is what the game state saves.
+func (n *NPC) Actor(id string) (*d20.Actor, error) {
+	if !n.HasActor() {
+		return nil, fmt.Errorf("NPC %s has no max_hp", id)
+	}
+	a, err := d20.NewActor(id).
+		WithHP(n.MaxHP).
+		WithAC(n.AC).
+		WithAttributes(n.AllAttributes()).
+		WithCombatModifiers(n.CombatMods).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build actor for NPC %s: %w", id, err)
+	}
+	if err := a.SetHP(n.HP); err != nil {
+		return nil, fmt.Errorf("failed to set HP for NPC %s: %w", id, err)
+	}
+	return a, nil
+}
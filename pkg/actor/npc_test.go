@@ -0,0 +1,101 @@
+package actor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewNPCFromTemplate_Stats(t *testing.T) {
+	template := &NPC{
+		Name:  "Guard Captain",
+		Stats: &Stats5e{Strength: 16, Dexterity: 12},
+		MaxHP: 20,
+	}
+
+	n := NewNPCFromTemplate(template, &NPC{Location: "gate"})
+	if n.Stats == nil || n.Stats.Strength != 16 {
+		t.Errorf("expected the template's stats, got %+v", n.Stats)
+	}
+
+	n = NewNPCFromTemplate(template, &NPC{Stats: &Stats5e{Strength: 10}})
+	if n.Stats.Strength != 10 {
+		t.Errorf("expected the override's stats, got %+v", n.Stats)
+	}
+	n.Stats.Strength = 8
+	if template.Stats.Strength != 16 {
+		t.Error("changing the NPC's stats must not change the template")
+	}
+}
+
+func TestNPC_InitHP(t *testing.T) {
+	tests := []struct {
+		name   string
+		npc    NPC
+		wantHP int
+	}{
+		{"starts at max", NPC{MaxHP: 12}, 12},
+		{"keeps hp set", NPC{HP: 5, MaxHP: 12}, 5},
+		{"narrative NPC", NPC{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.npc.InitHP()
+			if tt.npc.HP != tt.wantHP {
+				t.Errorf("InitHP() HP = %d, want %d", tt.npc.HP, tt.wantHP)
+			}
+		})
+	}
+}
+
+func TestNPC_AllAttributes(t *testing.T) {
+	n := NPC{
+		Stats:      &Stats5e{Strength: 16, Dexterity: 12},
+		Attributes: map[string]int{"dexterity": 14, "athletics": 5},
+	}
+	got := n.AllAttributes()
+	want := map[string]int{
+		"strength": 16, "dexterity": 14, "constitution": 0,
+		"intelligence": 0, "wisdom": 0, "charisma": 0, "athletics": 5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AllAttributes() = %v, want %v", got, want)
+	}
+
+	n = NPC{Attributes: map[string]int{"strength": 10}}
+	if got := n.AllAttributes(); !reflect.DeepEqual(got, map[string]int{"strength": 10}) {
+		t.Errorf("AllAttributes() without stats = %v", got)
+	}
+}
+
+func TestNPC_Actor(t *testing.T) {
+	n := NPC{
+		Name:       "Guard Captain",
+		Stats:      &Stats5e{Strength: 16},
+		AC:         16,
+		HP:         7,
+		MaxHP:      20,
+		CombatMods: map[string]int{"longsword": 5},
+	}
+	a, err := n.Actor("captain")
+	if err != nil {
+		t.Fatalf("Actor() error = %v", err)
+	}
+	if a.ID() != "captain" || a.AC() != 16 || a.HP() != 7 || a.MaxHP() != 20 {
+		t.Errorf("Actor() = id %s, AC %d, HP %d/%d", a.ID(), a.AC(), a.HP(), a.MaxHP())
+	}
+	if str, ok := a.Attribute("strength"); !ok || str != 16 {
+		t.Errorf("Actor() strength = %d, %v, want 16", str, ok)
+	}
+
+	n.HP = 0
+	if a, err := n.Actor("captain"); err != nil || !a.IsKnockedOut() {
+		t.Errorf("Actor() at 0 HP should be knocked out, got %v", err)
+	}
+
+	if _, err := (&NPC{Name: "Innkeeper"}).Actor("innkeeper"); err == nil {
+		t.Error("Actor() without max_hp should fail")
+	}
+	if _, err := (&NPC{HP: 30, MaxHP: 20}).Actor("brute"); err == nil {
+		t.Error("Actor() with HP over max_hp should fail")
+	}
+}
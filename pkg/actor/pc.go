@@ -42,6 +42,11 @@ type PCSpec struct {
 	Pronouns           string                           `json:"pronouns,omitempty"`
 	Description        string                           `json:"description,omitempty"`
 	Background         string                           `json:"background,omitempty"`
+	Appearance         string                           `json:"appearance,omitempty"`          // How the character looks; shown on the character sheet
+	Personality        string                           `json:"personality,omitempty"`         // Traits, ideals, and flaws
+	Motivation         string                           `json:"motivation,omitempty"`          // What drives the character
+	Portrait           string                           `json:"portrait,omitempty"`            // URL or path of a portrait image
+	Assets             map[string]string                `json:"assets,omitempty"`              // Other named asset references, e.g. "token" or "banner"
 	OpeningPrompt      string                           `json:"opening_prompt,omitempty"`      // PC-specific opening text
 	ContingencyPrompts []conditionals.ContingencyPrompt `json:"contingency_prompts,omitempty"` // Conditional prompts for this PC
 	Stats              Stats5e                          `json:"stats,omitempty"`
@@ -129,6 +134,11 @@ func (pc *PC) MarshalJSON() ([]byte, error) {
 		Pronouns           string                           `json:"pronouns,omitempty"`
 		Description        string                           `json:"description,omitempty"`
 		Background         string                           `json:"background,omitempty"`
+		Appearance         string                           `json:"appearance,omitempty"`
+		Personality        string                           `json:"personality,omitempty"`
+		Motivation         string                           `json:"motivation,omitempty"`
+		Portrait           string                           `json:"portrait,omitempty"`
+		Assets             map[string]string                `json:"assets,omitempty"`
 		OpeningPrompt      string                           `json:"opening_prompt,omitempty"`
 		ContingencyPrompts []conditionals.ContingencyPrompt `json:"contingency_prompts,omitempty"`
 		Stats              Stats5e                          `json:"stats"`
@@ -150,6 +160,11 @@ func (pc *PC) MarshalJSON() ([]byte, error) {
 		Pronouns:           pc.Spec.Pronouns,
 		Description:        pc.Spec.Description,
 		Background:         pc.Spec.Background,
+		Appearance:         pc.Spec.Appearance,
+		Personality:        pc.Spec.Personality,
+		Motivation:         pc.Spec.Motivation,
+		Portrait:           pc.Spec.Portrait,
+		Assets:             pc.Spec.Assets,
 		OpeningPrompt:      pc.Spec.OpeningPrompt,
 		ContingencyPrompts: pc.Spec.ContingencyPrompts,
 		Inventory:          pc.Spec.Inventory,
@@ -519,3 +519,44 @@ func TestBuildPrompt_WithActor(t *testing.T) {
 		t.Errorf("BuildPrompt() = %q, want %q", got, want)
 	}
 }
+
+func TestPC_CheckModifier(t *testing.T) {
+	pc, err := NewPCFromSpec(&PCSpec{
+		ID:         "korga",
+		Stats:      Stats5e{Strength: 18, Dexterity: 12, Wisdom: 8},
+		MaxHP:      45,
+		AC:         14,
+		Attributes: map[string]int{"athletics": 6, "endurance": 5},
+	})
+	if err != nil {
+		t.Fatalf("NewPCFromSpec() error = %v", err)
+	}
+
+	tests := []struct {
+		attribute string
+		want      int
+		wantOK    bool
+	}{
+		{"strength", 4, true},
+		{"Wisdom", -1, true},
+		{"charisma", 0, true},
+		{"athletics", 6, true},
+		{"stealth", 1, true},
+		{"Sleight of Hand", 1, true},
+		{"endurance", 5, true},
+		{"lockpicking", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.attribute, func(t *testing.T) {
+			got, ok := pc.CheckModifier(tt.attribute)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("CheckModifier(%q) = %d, %t, want %d, %t", tt.attribute, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	var none *PC
+	if got, ok := none.CheckModifier("stealth"); got != 0 || !ok {
+		t.Errorf("nil PC CheckModifier(stealth) = %d, %t, want 0, true", got, ok)
+	}
+}
@@ -0,0 +1,179 @@
+package actor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PCProfile is a render-ready character sheet for a PC. Clients can display it
+// as-is without knowing how stats, modifiers, and attributes are stored.
+type PCProfile struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Summary     string            `json:"summary,omitempty"` // e.g. "Level 5 human rogue"
+	Pronouns    string            `json:"pronouns,omitempty"`
+	Portrait    string            `json:"portrait,omitempty"`
+	Assets      map[string]string `json:"assets,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Background  string            `json:"background,omitempty"`
+	Appearance  string            `json:"appearance,omitempty"`
+	Personality string            `json:"personality,omitempty"`
+	Motivation  string            `json:"motivation,omitempty"`
+	HP          int               `json:"hp"`
+	MaxHP       int               `json:"max_hp"`
+	AC          int               `json:"ac"`
+	Abilities   []AbilityScore    `json:"abilities"`                  // The six core stats, in the usual 5e order
+	Modifiers   []NamedValue      `json:"combat_modifiers,omitempty"` // Sorted by name
+	Skills      []NamedValue      `json:"skills,omitempty"`           // Other attributes, sorted by name
+	Inventory   []string          `json:"inventory,omitempty"`
+}
+
+// AbilityScore is one core stat with its derived modifier
+type AbilityScore struct {
+	Name     string `json:"name"`     // e.g. "strength"
+	Abbrev   string `json:"abbrev"`   // e.g. "STR"
+	Score    int    `json:"score"`    // e.g. 14
+	Modifier int    `json:"modifier"` // e.g. +2
+}
+
+// NamedValue is a labelled number such as a combat modifier or skill bonus
+type NamedValue struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// AbilityModifier returns the 5e modifier for an ability score: (score - 10) / 2, rounded down
+func AbilityModifier(score int) int {
+	diff := score - 10
+	if diff < 0 {
+		return (diff - 1) / 2
+	}
+	return diff / 2
+}
+
+// FormatModifier formats a modifier with its sign, e.g. "+2" or "-1"
+func FormatModifier(mod int) string {
+	return fmt.Sprintf("%+d", mod)
+}
+
+// Profile builds the PC's character sheet from its current runtime state.
+// Returns nil if pc is nil.
+func (pc *PC) Profile() *PCProfile {
+	if pc == nil || pc.Spec == nil {
+		return nil
+	}
+	spec := pc.Spec
+
+	profile := &PCProfile{
+		ID:          spec.ID,
+		Name:        spec.Name,
+		Summary:     pcSummary(spec),
+		Pronouns:    spec.Pronouns,
+		Portrait:    spec.Portrait,
+		Assets:      spec.Assets,
+		Description: spec.Description,
+		Background:  spec.Background,
+		Appearance:  spec.Appearance,
+		Personality: spec.Personality,
+		Motivation:  spec.Motivation,
+		HP:          spec.HP,
+		MaxHP:       spec.MaxHP,
+		AC:          spec.AC,
+		Inventory:   spec.Inventory,
+	}
+
+	stats := spec.Stats
+	modifiers := spec.CombatModifiers
+	attributes := spec.Attributes
+	if pc.Actor != nil {
+		// Prefer the actor's current values, which change during play
+		profile.HP = pc.Actor.HP()
+		profile.MaxHP = pc.Actor.MaxHP()
+		profile.AC = pc.Actor.AC()
+		for _, ability := range coreAbilities {
+			if v, ok := pc.Actor.Attribute(ability.name); ok {
+				stats.set(ability.name, v)
+			}
+		}
+		modifiers = make(map[string]int)
+		for _, mod := range pc.Actor.GetCombatModifiers() {
+			modifiers[mod.Reason] = mod.Value
+		}
+		attributes = make(map[string]int, len(spec.Attributes))
+		for key := range spec.Attributes {
+			if v, ok := pc.Actor.Attribute(key); ok {
+				attributes[key] = v
+			}
+		}
+	}
+
+	scores := stats.ToAttributes()
+	for _, ability := range coreAbilities {
+		score := scores[ability.name]
+		profile.Abilities = append(profile.Abilities, AbilityScore{
+			Name:     ability.name,
+			Abbrev:   ability.abbrev,
+			Score:    score,
+			Modifier: AbilityModifier(score),
+		})
+	}
+	profile.Modifiers = sortedValues(modifiers, nil)
+	profile.Skills = sortedValues(attributes, scores)
+	return profile
+}
+
+// coreAbilities lists the six core stats in the order character sheets show them
+var coreAbilities = []struct{ name, abbrev string }{
+	{"strength", "STR"},
+	{"dexterity", "DEX"},
+	{"constitution", "CON"},
+	{"intelligence", "INT"},
+	{"wisdom", "WIS"},
+	{"charisma", "CHA"},
+}
+
+func (s *Stats5e) set(name string, v int) {
+	switch name {
+	case "strength":
+		s.Strength = v
+	case "dexterity":
+		s.Dexterity = v
+	case "constitution":
+		s.Constitution = v
+	case "intelligence":
+		s.Intelligence = v
+	case "wisdom":
+		s.Wisdom = v
+	case "charisma":
+		s.Charisma = v
+	}
+}
+
+// pcSummary describes a PC in one line, e.g. "Level 5 human rogue"
+func pcSummary(spec *PCSpec) string {
+	var parts []string
+	if spec.Level > 0 {
+		parts = append(parts, fmt.Sprintf("Level %d", spec.Level))
+	}
+	if spec.Race != "" {
+		parts = append(parts, spec.Race)
+	}
+	if spec.Class != "" {
+		parts = append(parts, spec.Class)
+	}
+	return strings.Join(parts, " ")
+}
+
+// sortedValues converts a map to NamedValues sorted by name, skipping keys in exclude
+func sortedValues(values map[string]int, exclude map[string]int) []NamedValue {
+	var out []NamedValue
+	for name, v := range values {
+		if _, skip := exclude[name]; skip {
+			continue
+		}
+		out = append(out, NamedValue{Name: name, Value: v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
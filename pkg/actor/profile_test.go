@@ -0,0 +1,128 @@
+package actor
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAbilityModifier(t *testing.T) {
+	tests := []struct {
+		score int
+		want  int
+	}{
+		{1, -5},
+		{8, -1},
+		{9, -1},
+		{10, 0},
+		{11, 0},
+		{12, 1},
+		{18, 4},
+		{20, 5},
+	}
+
+	for _, tt := range tests {
+		if got := AbilityModifier(tt.score); got != tt.want {
+			t.Errorf("AbilityModifier(%d) = %d, want %d", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestPC_Profile(t *testing.T) {
+	spec := &PCSpec{
+		ID:          "ranger",
+		Name:        "Wren",
+		Class:       "ranger",
+		Level:       4,
+		Race:        "half-elf",
+		Pronouns:    "they/them",
+		Background:  "Raised by foresters",
+		Appearance:  "Tall, with a green cloak",
+		Motivation:  "Find their lost sibling",
+		Portrait:    "https://example.com/wren.png",
+		Assets:      map[string]string{"token": "tokens/wren.png"},
+		Stats:       Stats5e{Strength: 12, Dexterity: 17, Constitution: 14, Intelligence: 10, Wisdom: 15, Charisma: 8},
+		HP:          20,
+		MaxHP:       30,
+		AC:          15,
+		Attributes:  map[string]int{"survival": 5, "stealth": 4},
+		Inventory:   []string{"longbow", "rope"},
+		Description: "A quiet tracker",
+		CombatModifiers: map[string]int{
+			"favored enemy": 2,
+		},
+	}
+	pc, err := NewPCFromSpec(spec)
+	if err != nil {
+		t.Fatalf("NewPCFromSpec() error = %v", err)
+	}
+
+	profile := pc.Profile()
+
+	if profile.Summary != "Level 4 half-elf ranger" {
+		t.Errorf("Summary = %q", profile.Summary)
+	}
+	if profile.HP != 20 || profile.MaxHP != 30 || profile.AC != 15 {
+		t.Errorf("HP/MaxHP/AC = %d/%d/%d, want 20/30/15", profile.HP, profile.MaxHP, profile.AC)
+	}
+	if profile.Portrait != spec.Portrait || profile.Assets["token"] != "tokens/wren.png" {
+		t.Errorf("portrait/assets = %q/%v", profile.Portrait, profile.Assets)
+	}
+	if len(profile.Abilities) != 6 {
+		t.Fatalf("len(Abilities) = %d, want 6", len(profile.Abilities))
+	}
+	if dex := profile.Abilities[1]; dex.Abbrev != "DEX" || dex.Score != 17 || dex.Modifier != 3 {
+		t.Errorf("Abilities[1] = %+v, want DEX 17 (+3)", dex)
+	}
+	if cha := profile.Abilities[5]; cha.Abbrev != "CHA" || cha.Modifier != -1 {
+		t.Errorf("Abilities[5] = %+v, want CHA (-1)", cha)
+	}
+	wantSkills := []NamedValue{{"stealth", 4}, {"survival", 5}}
+	if len(profile.Skills) != len(wantSkills) {
+		t.Fatalf("Skills = %+v, want %+v", profile.Skills, wantSkills)
+	}
+	for i, s := range wantSkills {
+		if profile.Skills[i] != s {
+			t.Errorf("Skills[%d] = %+v, want %+v", i, profile.Skills[i], s)
+		}
+	}
+	if len(profile.Modifiers) != 1 || profile.Modifiers[0] != (NamedValue{"favored enemy", 2}) {
+		t.Errorf("Modifiers = %+v", profile.Modifiers)
+	}
+}
+
+func TestPC_Profile_Nil(t *testing.T) {
+	var pc *PC
+	if pc.Profile() != nil {
+		t.Error("Profile() of a nil PC should be nil")
+	}
+}
+
+func TestPC_MarshalJSON_Profile_RoundTrip(t *testing.T) {
+	// Bio and asset fields must survive a game state save and load
+	spec := &PCSpec{
+		ID:          "ranger",
+		Name:        "Wren",
+		HP:          10,
+		MaxHP:       10,
+		Personality: "Wry and watchful",
+		Portrait:    "portraits/wren.png",
+		Assets:      map[string]string{"banner": "banners/wren.png"},
+	}
+	pc, err := NewPCFromSpec(spec)
+	if err != nil {
+		t.Fatalf("NewPCFromSpec() error = %v", err)
+	}
+
+	data, err := json.Marshal(pc)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var loaded PC
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if loaded.Spec.Personality != spec.Personality || loaded.Spec.Portrait != spec.Portrait || loaded.Spec.Assets["banner"] != "banners/wren.png" {
+		t.Errorf("round trip lost fields: %+v", loaded.Spec)
+	}
+}
@@ -0,0 +1,75 @@
+package actor
+
+import "strings"
+
+// SkillAbilities maps each 5e skill to the ability it is checked with
+var SkillAbilities = map[string]string{
+	"acrobatics":      "dexterity",
+	"animal_handling": "wisdom",
+	"arcana":          "intelligence",
+	"athletics":       "strength",
+	"deception":       "charisma",
+	"history":         "intelligence",
+	"insight":         "wisdom",
+	"intimidation":    "charisma",
+	"investigation":   "intelligence",
+	"medicine":        "wisdom",
+	"nature":          "intelligence",
+	"perception":      "wisdom",
+	"performance":     "charisma",
+	"persuasion":      "charisma",
+	"religion":        "intelligence",
+	"sleight_of_hand": "dexterity",
+	"stealth":         "dexterity",
+	"survival":        "wisdom",
+}
+
+// NormalizeAttribute returns an ability or skill name in attribute key form,
+// e.g. "Sleight of Hand" becomes "sleight_of_hand"
+func NormalizeAttribute(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// IsCheckAttribute reports whether attribute is an ability or a skill, which any PC can
+// be checked on
+func IsCheckAttribute(attribute string) bool {
+	_, ok := (*PC)(nil).CheckModifier(attribute)
+	return ok
+}
+
+// CheckModifier returns the bonus the PC adds to a d20 check of an ability or skill.
+// An ability adds its modifier. A skill adds its own value from the PC's attributes,
+// as the PC's skill bonus, or else the modifier of the ability it's checked with. Any
+// other attribute the PC has adds its value. ok is false for attributes the PC doesn't
+// have and that aren't abilities or skills.
+func (pc *PC) CheckModifier(attribute string) (modifier int, ok bool) {
+	attribute = NormalizeAttribute(attribute)
+	value := func(key string) (int, bool) {
+		if pc == nil || pc.Actor == nil {
+			return 0, false
+		}
+		return pc.Actor.Attribute(key)
+	}
+
+	// An unset score of zero adds nothing
+	abilityModifier := func(ability string) int {
+		if score, _ := value(ability); score != 0 {
+			return AbilityModifier(score)
+		}
+		return 0
+	}
+
+	for _, ability := range coreAbilities {
+		if attribute == ability.name {
+			return abilityModifier(ability.name), true
+		}
+	}
+	if bonus, has := value(attribute); has {
+		return bonus, true
+	}
+	if ability, isSkill := SkillAbilities[attribute]; isSkill {
+		return abilityModifier(ability), true
+	}
+	return 0, false
+}
@@ -0,0 +1,49 @@
+package chat
+
+import "strings"
+
+// DefaultAliases are terminal-style shortcuts expanded before a player's message is
+// sent to the narrator. Scenarios can add to or override them.
+var DefaultAliases = map[string]string{
+	"n":   "go north",
+	"s":   "go south",
+	"e":   "go east",
+	"w":   "go west",
+	"ne":  "go northeast",
+	"nw":  "go northwest",
+	"se":  "go southeast",
+	"sw":  "go southwest",
+	"l":   "look around",
+	"x":   "examine",
+	"z":   "wait",
+	"inv": "/inventory",
+}
+
+// ExpandAlias replaces a leading alias in message with its expansion, keeping the rest
+// of the message, e.g. "x lantern" becomes "examine lantern". Only the first word is
+// matched, case-insensitively. Aliases in custom take precedence over DefaultAliases,
+// and a custom alias with an empty expansion disables the default.
+func ExpandAlias(message string, custom map[string]string) string {
+	trimmed := strings.TrimSpace(message)
+	word, rest, _ := strings.Cut(trimmed, " ")
+	word = strings.ToLower(word)
+
+	expansion, ok := lookupAlias(word, custom)
+	if !ok || expansion == "" {
+		return message
+	}
+	if rest = strings.TrimSpace(rest); rest != "" {
+		return expansion + " " + rest
+	}
+	return expansion
+}
+
+func lookupAlias(word string, custom map[string]string) (string, bool) {
+	for alias, expansion := range custom {
+		if strings.EqualFold(alias, word) {
+			return expansion, true
+		}
+	}
+	expansion, ok := DefaultAliases[word]
+	return expansion, ok
+}
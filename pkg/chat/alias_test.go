@@ -0,0 +1,39 @@
+package chat
+
+import "testing"
+
+func TestExpandAlias(t *testing.T) {
+	custom := map[string]string{
+		"Xyzzy": "say the magic word",
+		"n":     "sail north",
+		"z":     "",
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		custom  map[string]string
+		want    string
+	}{
+		{"direction", "n", nil, "go north"},
+		{"uppercase", "E", nil, "go east"},
+		{"surrounding space", "  sw ", nil, "go southwest"},
+		{"alias with argument", "x brass lantern", nil, "examine brass lantern"},
+		{"server command", "inv", nil, "/inventory"},
+		{"not an alias", "north", nil, "north"},
+		{"alias not first", "I go n", nil, "I go n"},
+		{"sentence kept as is", "I open the door", nil, "I open the door"},
+		{"custom alias", "xyzzy", custom, "say the magic word"},
+		{"custom overrides default", "n", custom, "sail north"},
+		{"custom disables default", "z", custom, "z"},
+		{"defaults still apply", "s", custom, "go south"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandAlias(tt.message, tt.custom); got != tt.want {
+				t.Errorf("ExpandAlias(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
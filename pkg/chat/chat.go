@@ -9,12 +9,26 @@ import (
 
 const MaxMessageLength = 255
 
+// MaxVariants is the most narration candidates a turn can ask for
+const MaxVariants = 4
+
+// MaxNarrationTokens is the most output tokens a request can allow its narration
+const MaxNarrationTokens = 2048
+
 // ChatRequest represents a chat message request made by the user
 // to the story engine api.
 type ChatRequest struct {
 	GameStateID uuid.UUID `json:"gamestate_id"` // Unique ID for the game state
 	Message     string    `json:"message"`
-	Stream      bool      `json:"stream,omitempty"` // Whether to stream the response
+	Stream      bool      `json:"stream,omitempty"`      // Whether to stream the response
+	FreeAction  bool      `json:"free_action,omitempty"` // Narrate without advancing the turn counters
+	Embellish   bool      `json:"embellish,omitempty"`   // Have the narrator reword answers to server commands such as /inventory
+	Variants    int       `json:"variants,omitempty"`    // Narrate this many candidates for the client to choose from (2 to MaxVariants)
+	Regenerate  bool      `json:"-"`                     // Set by the worker when replaying the last turn, for a different narration
+
+	// Bounds on this turn's narration, overriding the scenario's narration_length
+	MaxTokens    int `json:"max_tokens,omitempty"`    // Output tokens the narrator may generate (up to MaxNarrationTokens)
+	MaxSentences int `json:"max_sentences,omitempty"` // Sentences the narrator is asked for; the narration is cut after this many
 }
 
 // ChatResponse represents a chat message response returned by the story engine api.
@@ -23,7 +37,22 @@ type ChatRequest struct {
 type ChatResponse struct {
 	GameStateID uuid.UUID     `json:"gamestate_id,omitempty"` // Unique ID for the game state
 	Message     string        `json:"message,omitempty"`
+	Segments    []Segment     `json:"segments,omitempty"`     // Message split into narration and dialogue
 	ChatHistory []ChatMessage `json:"chat_history,omitempty"` // History of chat messages
+	Choices     []string      `json:"choices,omitempty"`      // Suggested next actions (choices mode only)
+	State       *StateSummary `json:"state,omitempty"`        // Compact game state snapshot at the end of the turn
+}
+
+// StateSummary is a compact snapshot of the game state returned with a chat response,
+// so clients can refresh their display without an immediate gamestate fetch.
+type StateSummary struct {
+	Location         string   `json:"location,omitempty"`   // Current location of the user
+	SceneName        string   `json:"scene_name,omitempty"` // Current scene, if scenes are used
+	TurnCounter      int      `json:"turn_counter"`
+	SceneTurnCounter int      `json:"scene_turn_counter"`
+	IsEnded          bool     `json:"is_ended"`
+	NewItems         []string `json:"new_items,omitempty"`        // Items in inventory that were not there when the turn started
+	TrialTurnsLeft   *int     `json:"trial_turns_left,omitempty"` // Turns left in a preview game; absent for full games
 }
 
 const (
@@ -36,9 +65,15 @@ const (
 // This interface is defined by Ollama's API and is used to structure messages
 // sent to the LLM.
 type ChatMessage struct {
-	Role         string `json:"role"` // "user", "assistant", "system"
-	Content      string `json:"content"`
-	IsStoryEvent bool   `json:"is_story_event,omitempty"` // True if this message is a story event injected by the engine
+	Role         string         `json:"role"` // "user", "assistant", "system"
+	Content      string         `json:"content"`
+	IsStoryEvent bool           `json:"is_story_event,omitempty"` // True if this message is a story event injected by the engine
+	Meta         *NarrationMeta `json:"meta,omitempty"`           // Structured trailer of a narration, when narration metadata is enabled
+	Incomplete   bool           `json:"incomplete,omitempty"`     // True if the narration was cut off by an LLM error and only its start was kept
+
+	// CachePrefix is the length in bytes of the start of Content that stays the same
+	// from turn to turn. Providers that support prompt caching cache it. Not persisted.
+	CachePrefix int `json:"-"`
 }
 
 func (cr *ChatRequest) Validate() error {
@@ -51,6 +86,15 @@ func (cr *ChatRequest) Validate() error {
 	if cr.GameStateID == uuid.Nil {
 		return fmt.Errorf("game state ID cannot be empty")
 	}
+	if cr.Variants != 0 && (cr.Variants < 2 || cr.Variants > MaxVariants) {
+		return fmt.Errorf("variants must be between 2 and %d", MaxVariants)
+	}
+	if cr.MaxTokens < 0 || cr.MaxTokens > MaxNarrationTokens {
+		return fmt.Errorf("max_tokens must be between 0 and %d", MaxNarrationTokens)
+	}
+	if cr.MaxSentences < 0 {
+		return fmt.Errorf("max_sentences cannot be negative")
+	}
 	return nil
 }
 
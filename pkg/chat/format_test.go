@@ -115,6 +115,36 @@ func TestChatRequest_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "cannot be empty",
 		},
+		{
+			name: "valid narration length",
+			req: ChatRequest{
+				Message:      "I run.",
+				GameStateID:  mustParseUUID("550e8400-e29b-41d4-a716-446655440000"),
+				MaxTokens:    200,
+				MaxSentences: 3,
+			},
+			wantErr: false,
+		},
+		{
+			name: "max tokens too high",
+			req: ChatRequest{
+				Message:     "I run.",
+				GameStateID: mustParseUUID("550e8400-e29b-41d4-a716-446655440000"),
+				MaxTokens:   MaxNarrationTokens + 1,
+			},
+			wantErr: true,
+			errMsg:  "max_tokens must be between",
+		},
+		{
+			name: "negative max sentences",
+			req: ChatRequest{
+				Message:      "I run.",
+				GameStateID:  mustParseUUID("550e8400-e29b-41d4-a716-446655440000"),
+				MaxSentences: -1,
+			},
+			wantErr: true,
+			errMsg:  "max_sentences cannot be negative",
+		},
 	}
 
 	for _, tt := range tests {
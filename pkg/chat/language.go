@@ -0,0 +1,106 @@
+package chat
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LanguageEnglish is the language the backend reducer prompts are written in
+const LanguageEnglish = "en"
+
+// languageNames maps the codes returned by DetectLanguage to display names
+var languageNames = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+	"pt": "Portuguese",
+	"nl": "Dutch",
+	"ru": "Russian",
+	"el": "Greek",
+	"he": "Hebrew",
+	"ar": "Arabic",
+	"zh": "Chinese",
+	"ja": "Japanese",
+	"ko": "Korean",
+}
+
+// stopwords are common short words that mark a Latin-script language. Words shared
+// between languages count toward each of them.
+var stopwords = map[string][]string{
+	"en": {"the", "a", "an", "and", "i", "to", "of", "my", "at", "with", "on", "in", "is", "it", "go", "look", "take", "open", "what", "you"},
+	"es": {"el", "la", "los", "las", "y", "yo", "de", "del", "mi", "con", "en", "es", "un", "una", "al", "que", "por", "para", "abro", "miro", "voy"},
+	"fr": {"le", "la", "les", "et", "je", "de", "du", "des", "mon", "ma", "avec", "dans", "est", "un", "une", "au", "que", "pour", "vers", "sur"},
+	"de": {"der", "die", "das", "und", "ich", "zu", "mit", "ein", "eine", "den", "dem", "ist", "nach", "auf", "im", "mein", "meine", "nicht"},
+	"it": {"il", "lo", "la", "gli", "le", "e", "io", "di", "del", "della", "con", "un", "una", "che", "per", "nel", "sono", "apro", "vado"},
+	"pt": {"o", "a", "os", "as", "e", "eu", "de", "do", "da", "com", "em", "um", "uma", "que", "para", "no", "na", "meu", "minha", "vou"},
+	"nl": {"de", "het", "een", "en", "ik", "van", "met", "naar", "op", "is", "mijn", "niet", "dat", "ga"},
+}
+
+// DetectLanguage guesses the language of a player's message and returns its code,
+// e.g. "en" or "es". Non-Latin scripts are recognised by their characters and
+// Latin-script languages by their common words. Ties go to English, and a message
+// with nothing to go on, such as "n" or "xyzzy", is assumed to be English.
+func DetectLanguage(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	best, bestScore := LanguageEnglish, 0
+	for _, lang := range []string{"en", "es", "fr", "de", "it", "pt", "nl"} {
+		score := 0
+		for _, w := range words {
+			for _, sw := range stopwords[lang] {
+				if w == sw {
+					score++
+					break
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore = lang, score
+		}
+	}
+	return best
+}
+
+// detectScript returns the language of the first letter written in a script that
+// identifies it, or "" for Latin text
+func detectScript(text string) string {
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			return "ja"
+		case unicode.Is(unicode.Hangul, r):
+			return "ko"
+		case unicode.Is(unicode.Han, r):
+			// Kanji alone can't tell Japanese from Chinese; keep looking for kana
+			if strings.ContainsFunc(text, func(r rune) bool { return unicode.In(r, unicode.Hiragana, unicode.Katakana) }) {
+				return "ja"
+			}
+			return "zh"
+		case unicode.Is(unicode.Cyrillic, r):
+			return "ru"
+		case unicode.Is(unicode.Greek, r):
+			return "el"
+		case unicode.Is(unicode.Hebrew, r):
+			return "he"
+		case unicode.Is(unicode.Arabic, r):
+			return "ar"
+		}
+	}
+	return ""
+}
+
+// LanguageName returns the display name for a code from DetectLanguage, or the
+// code itself if it isn't known
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
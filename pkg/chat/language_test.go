@@ -0,0 +1,42 @@
+package chat
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"I open the door with the key", "en"},
+		{"n", "en"},
+		{"xyzzy", "en"},
+		{"", "en"},
+		{"abro la puerta con mi llave", "es"},
+		{"j'ouvre la porte avec ma clé", "fr"},
+		{"ich öffne die Tür mit dem Schlüssel", "de"},
+		{"apro la porta con la chiave e vado nel bosco", "it"},
+		{"eu abro a porta com a minha chave", "pt"},
+		{"ik open de deur met mijn sleutel", "nl"},
+		{"открываю дверь ключом", "ru"},
+		{"扉を鍵で開ける", "ja"},
+		{"用钥匙开门", "zh"},
+		{"열쇠로 문을 연다", "ko"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := DetectLanguage(tt.text); got != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	if got := LanguageName("es"); got != "Spanish" {
+		t.Errorf("LanguageName(es) = %q, want Spanish", got)
+	}
+	if got := LanguageName("xx"); got != "xx" {
+		t.Errorf("LanguageName(xx) = %q, want xx", got)
+	}
+}
@@ -0,0 +1,65 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MaxMetaChoices caps the suggested actions kept from a narration trailer
+const MaxMetaChoices = 4
+
+// NarrationMeta is the structured trailer a narrator appends after its prose when
+// narration metadata is enabled: the mood of the scene, the characters present, and a
+// few actions the player might take next. It is stored on the narrator's message.
+type NarrationMeta struct {
+	Mood        string   `json:"mood,omitempty"`         // One or two words, e.g. "tense"
+	NPCsPresent []string `json:"npcs_present,omitempty"` // Names of the characters in the scene
+	Choices     []string `json:"choices,omitempty"`      // Suggested next actions, in the player's voice
+}
+
+// ParseNarrationMeta reads a trailer's JSON object, ignoring any text or code fence
+// around it. Blank and duplicate entries are dropped and choices are capped at
+// MaxMetaChoices. An empty trailer returns nil.
+func ParseNarrationMeta(text string) (*NarrationMeta, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("narration trailer has no JSON object: %q", text)
+	}
+
+	var meta NarrationMeta
+	if err := json.Unmarshal([]byte(text[start:end+1]), &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse narration trailer: %w", err)
+	}
+	meta.Mood = strings.TrimSpace(meta.Mood)
+	meta.NPCsPresent = cleanList(meta.NPCsPresent, 0)
+	meta.Choices = cleanList(meta.Choices, MaxMetaChoices)
+	if meta.Mood == "" && meta.NPCsPresent == nil && meta.Choices == nil {
+		return nil, nil
+	}
+	return &meta, nil
+}
+
+// cleanList trims entries and drops blanks and case-insensitive duplicates, keeping at
+// most limit entries if limit is positive
+func cleanList(list []string, limit int) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		key := strings.ToLower(s)
+		if s == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, s)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package chat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNarrationMeta(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		want      *NarrationMeta
+		expectErr bool
+	}{
+		{
+			name: "full trailer",
+			text: `{"mood": "tense", "npcs_present": ["Gibbs", "Anne"], "choices": ["Ask Gibbs about the map", "Leave the tavern"]}`,
+			want: &NarrationMeta{Mood: "tense", NPCsPresent: []string{"Gibbs", "Anne"}, Choices: []string{"Ask Gibbs about the map", "Leave the tavern"}},
+		},
+		{
+			name: "code fence and blank entries",
+			text: "```json\n{\"mood\": \" calm \", \"npcs_present\": [\"Gibbs\", \"gibbs\", \" \"]}\n```",
+			want: &NarrationMeta{Mood: "calm", NPCsPresent: []string{"Gibbs"}},
+		},
+		{
+			name: "choices capped",
+			text: `{"choices": ["a", "b", "c", "d", "e"]}`,
+			want: &NarrationMeta{Choices: []string{"a", "b", "c", "d"}},
+		},
+		{name: "empty", text: "  ", want: nil},
+		{name: "empty object", text: `{"mood": ""}`, want: nil},
+		{name: "not JSON", text: "The mood is tense.", expectErr: true},
+		{name: "malformed", text: `{"mood": }`, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNarrationMeta(tt.text)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseNarrationMeta() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseNarrationMeta() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
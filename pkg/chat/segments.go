@@ -0,0 +1,95 @@
+package chat
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	SegmentNarration = "narration" // Narrator prose
+	SegmentDialogue  = "dialogue"  // A line spoken by a character
+)
+
+// narratorSpeaker is the prefix narrators sometimes give their own lines
+const narratorSpeaker = "narrator"
+
+// Segment is one part of a narration: narrator prose, or a line of dialogue with
+// its speaker, so clients can style dialogue without parsing "Name:" prefixes
+type Segment struct {
+	Kind    string `json:"kind"`              // SegmentNarration or SegmentDialogue
+	Speaker string `json:"speaker,omitempty"` // Who is speaking; dialogue only
+	Text    string `json:"text"`
+}
+
+// ParseSegments splits narration into narrator prose and dialogue. A line is dialogue
+// when it starts with "Name: ", where Name is one of speakers (matched ignoring case),
+// or, for characters the narrator made up, up to three capitalized words. Consecutive
+// prose lines, including blank lines between paragraphs, form one segment.
+func ParseSegments(narration string, speakers []string) []Segment {
+	var segments []Segment
+	var prose []string
+	flush := func() {
+		text := strings.Trim(strings.Join(prose, "\n"), "\n")
+		prose = nil
+		if strings.TrimSpace(text) != "" {
+			segments = append(segments, Segment{Kind: SegmentNarration, Text: text})
+		}
+	}
+
+	for _, line := range strings.Split(narration, "\n") {
+		speaker, text, ok := splitSpeaker(line, speakers)
+		if !ok {
+			prose = append(prose, line)
+			continue
+		}
+		if strings.EqualFold(speaker, narratorSpeaker) {
+			prose = append(prose, text)
+			continue
+		}
+		flush()
+		segments = append(segments, Segment{Kind: SegmentDialogue, Speaker: speaker, Text: text})
+	}
+	flush()
+	return segments
+}
+
+// splitSpeaker splits a "Name: text" line, returning the speaker as listed in speakers
+// when known
+func splitSpeaker(line string, speakers []string) (speaker, text string, ok bool) {
+	name, text, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found || (text != "" && text[0] != ' ') {
+		return "", "", false
+	}
+	text = strings.TrimSpace(text)
+	for _, s := range speakers {
+		if s != "" && strings.EqualFold(name, s) {
+			return s, text, true
+		}
+	}
+	if strings.EqualFold(name, narratorSpeaker) || looksLikeName(name) {
+		return name, text, true
+	}
+	return "", "", false
+}
+
+// looksLikeName reports whether s is one to three capitalized words, such as
+// "Gibbs" or "Old Tom", and not a sentence that happens to contain a colon
+func looksLikeName(s string) bool {
+	words := strings.Fields(s)
+	if len(words) == 0 || len(words) > 3 || utf8.RuneCountInString(s) > 30 {
+		return false
+	}
+	for _, w := range words {
+		first, _ := utf8.DecodeRuneInString(w)
+		if !unicode.IsUpper(first) {
+			return false
+		}
+		for _, r := range w {
+			if !unicode.IsLetter(r) && r != '\'' && r != '’' && r != '-' && r != '.' {
+				return false
+			}
+		}
+	}
+	return true
+}
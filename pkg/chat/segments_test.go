@@ -0,0 +1,71 @@
+package chat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSegments(t *testing.T) {
+	tests := []struct {
+		name      string
+		narration string
+		speakers  []string
+		want      []Segment
+	}{
+		{
+			name:      "prose only",
+			narration: "The tide rolls in.\n\nGulls circle overhead.",
+			want:      []Segment{{Kind: SegmentNarration, Text: "The tide rolls in.\n\nGulls circle overhead."}},
+		},
+		{
+			name:      "prose and dialogue",
+			narration: "The captain turns.\n\nGibbs: Aye, we sail at dawn.\nAnne: Not without me.\n\nThe wind picks up.",
+			want: []Segment{
+				{Kind: SegmentNarration, Text: "The captain turns."},
+				{Kind: SegmentDialogue, Speaker: "Gibbs", Text: "Aye, we sail at dawn."},
+				{Kind: SegmentDialogue, Speaker: "Anne", Text: "Not without me."},
+				{Kind: SegmentNarration, Text: "The wind picks up."},
+			},
+		},
+		{
+			name:      "narrator prefix is prose",
+			narration: "Narrator: The door opens.",
+			want:      []Segment{{Kind: SegmentNarration, Text: "The door opens."}},
+		},
+		{
+			name:      "known speaker matched ignoring case",
+			narration: "the old bosun: Mind the rigging.",
+			speakers:  []string{"The Old Bosun"},
+			want:      []Segment{{Kind: SegmentDialogue, Speaker: "The Old Bosun", Text: "Mind the rigging."}},
+		},
+		{
+			name:      "sentence with colon is prose",
+			narration: "You find three things: a rope, a key, and a map.",
+			want:      []Segment{{Kind: SegmentNarration, Text: "You find three things: a rope, a key, and a map."}},
+		},
+		{
+			name:      "time is prose",
+			narration: "The clock reads 10:30.",
+			want:      []Segment{{Kind: SegmentNarration, Text: "The clock reads 10:30."}},
+		},
+		{
+			name:      "unknown multi-word name",
+			narration: "Old Tom: Careful now.",
+			want:      []Segment{{Kind: SegmentDialogue, Speaker: "Old Tom", Text: "Careful now."}},
+		},
+		{
+			name:      "empty",
+			narration: "",
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseSegments(tt.narration, tt.speakers)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSegments() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
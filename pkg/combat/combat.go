@@ -0,0 +1,371 @@
+// Package combat runs turn-based fights between the PC and NPCs or monsters with d20
+// rules: initiative order, attack rolls against AC, and hit points.
+package combat
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/d20"
+	"github.com/jwebster45206/story-engine/pkg/actor"
+)
+
+// Kinds of combatant
+const (
+	KindPC      = "pc"
+	KindNPC     = "npc"
+	KindMonster = "monster"
+)
+
+// PCID is the PC's combatant ID
+const PCID = "pc"
+
+// How a fight ended
+const (
+	OutcomeVictory = "victory" // every foe is down
+	OutcomeDefeat  = "defeat"  // the PC is down
+)
+
+// DamageDie is the die rolled for an attack's damage; a critical hit rolls it twice
+const DamageDie = 6
+
+// ErrUnknownCombatant is returned for a combatant ID that isn't in the fight
+var ErrUnknownCombatant = errors.New("unknown combatant")
+
+// Combatant is one side of the fight's stats, copied from the PC, an NPC, or a monster
+// when the fight starts. HP is tracked here and written back to the actor.
+type Combatant struct {
+	ID              string `json:"id"`   // PCID, an NPC ID, or a monster instance ID
+	Name            string `json:"name"` // Display name, for narration
+	Kind            string `json:"kind"` // KindPC, KindNPC, or KindMonster
+	Initiative      int    `json:"initiative"`
+	InitiativeBonus int    `json:"initiative_bonus,omitempty"` // Dexterity modifier
+	AC              int    `json:"ac"`
+	HP              int    `json:"hp"`
+	MaxHP           int    `json:"max_hp"`
+	AttackBonus     int    `json:"attack_bonus,omitempty"`
+	DamageBonus     int    `json:"damage_bonus,omitempty"` // Strength modifier
+}
+
+// Defeated reports whether the combatant is out of the fight
+func (c *Combatant) Defeated() bool {
+	return c.HP <= 0
+}
+
+// Attack is one attack roll and what it did
+type Attack struct {
+	Attacker string `json:"attacker"` // Combatant ID
+	Target   string `json:"target"`   // Combatant ID
+	Roll     int    `json:"roll"`     // The d20's face
+	Total    int    `json:"total"`    // Roll plus attack bonus
+	Hit      bool   `json:"hit"`
+	Critical bool   `json:"critical,omitempty"` // A natural 20
+	Damage   int    `json:"damage,omitempty"`
+	Defeated bool   `json:"defeated,omitempty"` // The attack took the target out
+}
+
+// Combat is a fight in progress. Combatants take turns in initiative order, and every
+// combatant but the PC is the PC's foe.
+type Combat struct {
+	Reason     string      `json:"reason,omitempty"` // Why the fight started, for narration
+	Round      int         `json:"round"`            // Rounds played so far
+	Combatants []Combatant `json:"combatants"`       // In initiative order
+	LastRound  []Attack    `json:"last_round,omitempty"`
+	Outcome    string      `json:"outcome,omitempty"` // Set once the fight is over
+}
+
+// FromPC makes the PC's combatant. The attack bonus is the sum of the PC's combat
+// modifiers, as in d20.Actor.AttackRoll.
+func FromPC(pc *actor.PC) (Combatant, error) {
+	if pc == nil || pc.Actor == nil {
+		return Combatant{}, errors.New("the game has no PC to fight")
+	}
+	c := Combatant{
+		ID:    PCID,
+		Name:  pc.Spec.Name,
+		Kind:  KindPC,
+		AC:    pc.Actor.AC(),
+		HP:    pc.Actor.HP(),
+		MaxHP: pc.Actor.MaxHP(),
+	}
+	if c.Name == "" {
+		c.Name = pc.Spec.ID
+	}
+	for _, mod := range pc.Actor.GetCombatModifiers() {
+		c.AttackBonus += mod.Value
+	}
+	if dex, ok := pc.Actor.Attribute("dexterity"); ok && dex != 0 {
+		c.InitiativeBonus = actor.AbilityModifier(dex)
+	}
+	if str, ok := pc.Actor.Attribute("strength"); ok && str != 0 {
+		c.DamageBonus = actor.AbilityModifier(str)
+	}
+	return c, nil
+}
+
+// FromNPC makes an NPC's combatant. Only NPCs with actor properties can fight.
+func FromNPC(id string, npc actor.NPC) (Combatant, error) {
+	if !npc.HasActor() {
+		return Combatant{}, fmt.Errorf("NPC %s has no hit points to fight with", id)
+	}
+	c := fromStats(npc.AllAttributes(), npc.CombatMods)
+	c.ID, c.Name, c.Kind = id, npc.Name, KindNPC
+	c.AC, c.HP, c.MaxHP = npc.AC, npc.HP, npc.MaxHP
+	return c, nil
+}
+
+// FromMonster makes a monster's combatant
+func FromMonster(m *actor.Monster) (Combatant, error) {
+	if m == nil || m.MaxHP <= 0 {
+		return Combatant{}, errors.New("monster has no hit points to fight with")
+	}
+	c := fromStats(m.Attributes, m.CombatMods)
+	c.ID, c.Name, c.Kind = m.ID, m.Name, KindMonster
+	c.AC, c.HP, c.MaxHP = m.AC, m.HP, m.MaxHP
+	return c, nil
+}
+
+// fromStats sets the bonuses of an NPC or monster. Their combat modifiers are named
+// attacks, such as "bite": 5, so the best of them is used.
+func fromStats(attributes, combatMods map[string]int) Combatant {
+	var c Combatant
+	if len(combatMods) > 0 {
+		c.AttackBonus = slices.Max(slices.Collect(maps.Values(combatMods)))
+	}
+	if dex := attributes["dexterity"]; dex != 0 {
+		c.InitiativeBonus = actor.AbilityModifier(dex)
+	}
+	if str := attributes["strength"]; str != 0 {
+		c.DamageBonus = actor.AbilityModifier(str)
+	}
+	return c
+}
+
+// Start rolls initiative for the combatants and begins the fight. One of them must
+// be the PC. Ties go to the higher initiative bonus, then to the PC.
+func Start(reason string, combatants []Combatant, roller *d20.Roller) (*Combat, error) {
+	if !slices.ContainsFunc(combatants, func(c Combatant) bool { return c.ID == PCID }) {
+		return nil, errors.New("the PC must be in the fight")
+	}
+	if len(combatants) < 2 {
+		return nil, errors.New("the PC has no one to fight")
+	}
+
+	c := &Combat{Reason: reason, Combatants: slices.Clone(combatants)}
+	for i := range c.Combatants {
+		cb := &c.Combatants[i]
+		outcome, err := roller.Dice(1, 20).WithModifier("initiative", cb.InitiativeBonus).Roll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to roll initiative for %s: %w", cb.ID, err)
+		}
+		cb.Initiative = outcome.Value
+	}
+	slices.SortStableFunc(c.Combatants, func(a, b Combatant) int {
+		if a.Initiative != b.Initiative {
+			return b.Initiative - a.Initiative
+		}
+		if a.InitiativeBonus != b.InitiativeBonus {
+			return b.InitiativeBonus - a.InitiativeBonus
+		}
+		if a.ID == PCID {
+			return -1
+		}
+		if b.ID == PCID {
+			return 1
+		}
+		return 0
+	})
+	return c, nil
+}
+
+// Get returns the combatant with the ID, or nil
+func (c *Combat) Get(id string) *Combatant {
+	for i := range c.Combatants {
+		if c.Combatants[i].ID == id {
+			return &c.Combatants[i]
+		}
+	}
+	return nil
+}
+
+// Find returns the combatant with the ID or name, ignoring case, or nil
+func (c *Combat) Find(name string) *Combatant {
+	if cb := c.Get(name); cb != nil {
+		return cb
+	}
+	for i := range c.Combatants {
+		if strings.EqualFold(c.Combatants[i].ID, name) || strings.EqualFold(c.Combatants[i].Name, name) {
+			return &c.Combatants[i]
+		}
+	}
+	return nil
+}
+
+// PC returns the PC's combatant
+func (c *Combat) PC() *Combatant {
+	return c.Get(PCID)
+}
+
+// Foes returns the IDs of the PC's foes still standing, in initiative order
+func (c *Combat) Foes() []string {
+	var ids []string
+	for _, cb := range c.Combatants {
+		if cb.ID != PCID && !cb.Defeated() {
+			ids = append(ids, cb.ID)
+		}
+	}
+	return ids
+}
+
+// Over reports whether the fight is decided
+func (c *Combat) Over() bool {
+	return c.Outcome != ""
+}
+
+// Attack rolls one attack: a d20 plus the attacker's attack bonus, hitting if it meets
+// the target's AC. A natural 20 always hits and rolls its damage die twice; a natural 1
+// always misses. Damage comes off the target's HP, which stops at zero.
+func (c *Combat) Attack(attackerID, targetID string, roller *d20.Roller) (Attack, error) {
+	attacker, target := c.Get(attackerID), c.Get(targetID)
+	if attacker == nil {
+		return Attack{}, fmt.Errorf("%w: %s", ErrUnknownCombatant, attackerID)
+	}
+	if target == nil {
+		return Attack{}, fmt.Errorf("%w: %s", ErrUnknownCombatant, targetID)
+	}
+	if attacker.Defeated() || target.Defeated() {
+		return Attack{}, fmt.Errorf("%s can't attack %s: already defeated", attackerID, targetID)
+	}
+
+	outcome, err := roller.Dice(1, 20).WithModifier("attack", attacker.AttackBonus).Roll()
+	if err != nil {
+		return Attack{}, fmt.Errorf("failed to roll attack: %w", err)
+	}
+	a := Attack{
+		Attacker: attackerID,
+		Target:   targetID,
+		Roll:     outcome.DiceRolls[0],
+		Total:    outcome.Value,
+	}
+	a.Critical = a.Roll == 20
+	a.Hit = a.Critical || (a.Roll != 1 && a.Total >= target.AC)
+	if !a.Hit {
+		return a, nil
+	}
+
+	dice := uint(1)
+	if a.Critical {
+		dice = 2
+	}
+	damage, err := roller.Dice(dice, DamageDie).WithModifier("damage", attacker.DamageBonus).Roll()
+	if err != nil {
+		return Attack{}, fmt.Errorf("failed to roll damage: %w", err)
+	}
+	a.Damage = max(damage.Value, 1)
+	target.HP = max(target.HP-a.Damage, 0)
+	a.Defeated = target.Defeated()
+	return a, nil
+}
+
+// PlayRound plays one round in initiative order. The PC attacks target, or no one if
+// target is empty, and each foe standing attacks the PC. The round stops as soon as the
+// fight is decided, and its attacks replace LastRound.
+func (c *Combat) PlayRound(target string, roller *d20.Roller) error {
+	if c.Over() {
+		return errors.New("the fight is already over")
+	}
+	if target != "" {
+		foe := c.Get(target)
+		if foe == nil || target == PCID {
+			return fmt.Errorf("%w: %s", ErrUnknownCombatant, target)
+		}
+		if foe.Defeated() {
+			return fmt.Errorf("%s is already defeated", target)
+		}
+	}
+
+	c.Round++
+	c.LastRound = nil
+	for i := range c.Combatants {
+		cb := &c.Combatants[i]
+		if cb.Defeated() {
+			continue
+		}
+		attackerID, targetID := cb.ID, PCID
+		if cb.ID == PCID {
+			if target == "" || c.Get(target).Defeated() {
+				continue
+			}
+			targetID = target
+		}
+		attack, err := c.Attack(attackerID, targetID, roller)
+		if err != nil {
+			return err
+		}
+		c.LastRound = append(c.LastRound, attack)
+
+		if c.PC().Defeated() {
+			c.Outcome = OutcomeDefeat
+			return nil
+		}
+		if len(c.Foes()) == 0 {
+			c.Outcome = OutcomeVictory
+			return nil
+		}
+	}
+	return nil
+}
+
+// Summary describes the fight for the narrator: who stands where in the initiative
+// order, what happened last round, and how it ended, if it has
+func (c *Combat) Summary() string {
+	var sb strings.Builder
+	if c.Reason != "" {
+		fmt.Fprintf(&sb, "Fight: %s\n", c.Reason)
+	}
+	fmt.Fprintf(&sb, "Round: %d\nInitiative order:\n", c.Round)
+	for _, cb := range c.Combatants {
+		status := fmt.Sprintf("%d/%d HP", cb.HP, cb.MaxHP)
+		if cb.Defeated() {
+			status = "defeated"
+		}
+		role := "foe"
+		if cb.ID == PCID {
+			role = "player"
+		}
+		fmt.Fprintf(&sb, "- %s (%s): %s\n", cb.Name, role, status)
+	}
+	if len(c.LastRound) > 0 {
+		sb.WriteString("Last round:\n")
+		for _, a := range c.LastRound {
+			fmt.Fprintf(&sb, "- %s\n", c.describe(a))
+		}
+	}
+	switch c.Outcome {
+	case OutcomeVictory:
+		sb.WriteString("Outcome: the player has won the fight.\n")
+	case OutcomeDefeat:
+		sb.WriteString("Outcome: the player has been defeated.\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// describe tells an attack in words, without the dice
+func (c *Combat) describe(a Attack) string {
+	attacker, target := c.Get(a.Attacker).Name, c.Get(a.Target).Name
+	switch {
+	case !a.Hit:
+		return fmt.Sprintf("%s attacked %s and missed", attacker, target)
+	case a.Defeated:
+		return fmt.Sprintf("%s struck %s down", attacker, target)
+	case a.Critical:
+		return fmt.Sprintf("%s landed a critical hit on %s, a grievous wound", attacker, target)
+	case a.Damage*2 >= c.Get(a.Target).MaxHP:
+		return fmt.Sprintf("%s hit %s hard", attacker, target)
+	default:
+		return fmt.Sprintf("%s hit %s", attacker, target)
+	}
+}
@@ -0,0 +1,227 @@
+package combat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/d20"
+	"github.com/jwebster45206/story-engine/pkg/actor"
+)
+
+func testPC(t *testing.T) Combatant {
+	t.Helper()
+	pc, err := actor.NewPCFromSpec(&actor.PCSpec{
+		ID:              "korga",
+		Name:            "Korga",
+		Stats:           actor.Stats5e{Strength: 16, Dexterity: 14},
+		MaxHP:           20,
+		AC:              15,
+		CombatModifiers: map[string]int{"strength": 3, "proficiency": 2},
+	})
+	if err != nil {
+		t.Fatalf("NewPCFromSpec() error = %v", err)
+	}
+	c, err := FromPC(pc)
+	if err != nil {
+		t.Fatalf("FromPC() error = %v", err)
+	}
+	return c
+}
+
+func TestFromActors(t *testing.T) {
+	pc := testPC(t)
+	if pc.ID != PCID || pc.Name != "Korga" || pc.AttackBonus != 5 || pc.InitiativeBonus != 2 || pc.DamageBonus != 3 || pc.HP != 20 || pc.AC != 15 {
+		t.Errorf("Unexpected PC combatant %+v", pc)
+	}
+
+	npc, err := FromNPC("guard", actor.NPC{Name: "Guard", AC: 13, MaxHP: 11, HP: 11, CombatMods: map[string]int{"spear": 3, "shield_bash": 1}, Attributes: map[string]int{"dexterity": 12}})
+	if err != nil {
+		t.Fatalf("FromNPC() error = %v", err)
+	}
+	if npc.Kind != KindNPC || npc.AttackBonus != 3 || npc.InitiativeBonus != 1 {
+		t.Errorf("Unexpected NPC combatant %+v", npc)
+	}
+	statted, err := FromNPC("captain", actor.NPC{Name: "Captain", MaxHP: 20, HP: 20, Stats: &actor.Stats5e{Strength: 16, Dexterity: 14}})
+	if err != nil {
+		t.Fatalf("FromNPC() error = %v", err)
+	}
+	if statted.InitiativeBonus != 2 || statted.DamageBonus != 3 {
+		t.Errorf("Expected bonuses from the NPC's stats, got %+v", statted)
+	}
+	if _, err := FromNPC("innkeeper", actor.NPC{Name: "Innkeeper"}); err == nil {
+		t.Error("Expected an error for an NPC without hit points")
+	}
+
+	m, err := FromMonster(&actor.Monster{ID: "rat_1", Name: "Rat", AC: 10, HP: 3, MaxHP: 3, CombatMods: map[string]int{"bite": 2}})
+	if err != nil {
+		t.Fatalf("FromMonster() error = %v", err)
+	}
+	if m.Kind != KindMonster || m.ID != "rat_1" || m.AttackBonus != 2 {
+		t.Errorf("Unexpected monster combatant %+v", m)
+	}
+}
+
+func TestStart(t *testing.T) {
+	rat := Combatant{ID: "rat_1", Name: "Rat", AC: 10, HP: 3, MaxHP: 3}
+	tests := []struct {
+		name       string
+		combatants []Combatant
+		wantErr    bool
+	}{
+		{"PC and foes", []Combatant{testPC(t), rat, {ID: "rat_2", Name: "Rat", HP: 3, MaxHP: 3, InitiativeBonus: 4}}, false},
+		{"no PC", []Combatant{rat}, true},
+		{"no foes", []Combatant{testPC(t)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Start("ambush", tt.combatants, d20.NewRoller(1))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Start() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(c.Combatants) != len(tt.combatants) || c.Round != 0 || c.Reason != "ambush" {
+				t.Fatalf("Unexpected combat %+v", c)
+			}
+			for i := 1; i < len(c.Combatants); i++ {
+				if c.Combatants[i-1].Initiative < c.Combatants[i].Initiative {
+					t.Errorf("Expected initiative order, got %+v", c.Combatants)
+				}
+			}
+			for _, cb := range c.Combatants {
+				if cb.Initiative < 1+cb.InitiativeBonus || cb.Initiative > 20+cb.InitiativeBonus {
+					t.Errorf("Initiative %d out of range for %s", cb.Initiative, cb.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestCombat_Attack(t *testing.T) {
+	tests := []struct {
+		name     string
+		targetAC int
+		wantHit  func(roll int) bool // whether the attack should hit, by the d20's face
+	}{
+		{"AC too low to miss", 0, func(roll int) bool { return roll != 1 }},
+		{"AC too high to hit", 100, func(roll int) bool { return roll == 20 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roller := d20.NewRoller(3)
+			for range 50 {
+				c := &Combat{Combatants: []Combatant{testPC(t), {ID: "ogre", Name: "Ogre", AC: tt.targetAC, HP: 100, MaxHP: 100}}}
+				a, err := c.Attack(PCID, "ogre", roller)
+				if err != nil {
+					t.Fatalf("Attack() error = %v", err)
+				}
+				if a.Hit != tt.wantHit(a.Roll) {
+					t.Errorf("Roll %d: expected hit %t", a.Roll, !a.Hit)
+				}
+				if a.Total != a.Roll+5 {
+					t.Errorf("Expected total %d, got %d", a.Roll+5, a.Total)
+				}
+				ogre := c.Get("ogre")
+				if a.Hit && (a.Damage < 1 || ogre.HP != 100-a.Damage) {
+					t.Errorf("Expected %d damage taken, HP is %d", a.Damage, ogre.HP)
+				}
+				if !a.Hit && (a.Damage != 0 || ogre.HP != 100) {
+					t.Errorf("Expected no damage on a miss, got %+v", a)
+				}
+			}
+		})
+	}
+
+	c := &Combat{Combatants: []Combatant{testPC(t), {ID: "rat_1", Name: "Rat"}}}
+	if _, err := c.Attack(PCID, "ghost", d20.NewRoller(1)); !errors.Is(err, ErrUnknownCombatant) {
+		t.Errorf("Expected ErrUnknownCombatant, got %v", err)
+	}
+	if _, err := c.Attack(PCID, "rat_1", d20.NewRoller(1)); err == nil {
+		t.Error("Expected an error attacking a defeated foe")
+	}
+}
+
+func TestCombat_PlayRound(t *testing.T) {
+	tests := []struct {
+		name    string
+		pcHP    int
+		foeHP   int
+		target  string
+		outcome string
+	}{
+		{"PC wins", 1000, 1, "rat_1", OutcomeVictory},
+		{"PC loses", 1, 1000, "rat_1", OutcomeDefeat},
+		{"PC holds back", 1, 1000, "", OutcomeDefeat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := testPC(t)
+			pc.HP, pc.MaxHP = tt.pcHP, tt.pcHP
+			rat := Combatant{ID: "rat_1", Name: "Rat", AC: 1, HP: tt.foeHP, MaxHP: tt.foeHP, AttackBonus: 30}
+			c, err := Start("", []Combatant{pc, rat}, d20.NewRoller(9))
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			roller := d20.NewRoller(9)
+			for round := 1; !c.Over(); round++ {
+				if round > 100 {
+					t.Fatal("Expected the fight to be decided")
+				}
+				if err := c.PlayRound(tt.target, roller); err != nil {
+					t.Fatalf("PlayRound() error = %v", err)
+				}
+				if c.Round != round || len(c.LastRound) == 0 {
+					t.Fatalf("Round %d: unexpected combat %+v", round, c)
+				}
+				for _, a := range c.LastRound {
+					if tt.target == "" && a.Attacker == PCID {
+						t.Errorf("Expected the PC not to attack, got %+v", a)
+					}
+				}
+			}
+			if c.Outcome != tt.outcome {
+				t.Errorf("Expected outcome %s, got %s", tt.outcome, c.Outcome)
+			}
+			if err := c.PlayRound(tt.target, roller); err == nil {
+				t.Error("Expected an error playing a round after the fight")
+			}
+		})
+	}
+
+	c := &Combat{Combatants: []Combatant{testPC(t), {ID: "rat_1", Name: "Rat", HP: 3, MaxHP: 3}}}
+	if err := c.PlayRound("ghost", d20.NewRoller(1)); !errors.Is(err, ErrUnknownCombatant) {
+		t.Errorf("Expected ErrUnknownCombatant, got %v", err)
+	}
+	if err := c.PlayRound(PCID, d20.NewRoller(1)); !errors.Is(err, ErrUnknownCombatant) {
+		t.Errorf("Expected the PC not to be a target, got %v", err)
+	}
+}
+
+func TestCombat_Summary(t *testing.T) {
+	c := &Combat{
+		Reason: "bandits at the bridge",
+		Round:  2,
+		Combatants: []Combatant{
+			{ID: PCID, Name: "Korga", HP: 12, MaxHP: 20},
+			{ID: "bandit", Name: "Bandit", HP: 0, MaxHP: 9},
+		},
+		LastRound: []Attack{{Attacker: PCID, Target: "bandit", Roll: 15, Total: 20, Hit: true, Damage: 4, Defeated: true}},
+		Outcome:   OutcomeVictory,
+	}
+	summary := c.Summary()
+	for _, want := range []string{"Fight: bandits at the bridge", "Round: 2", "- Korga (player): 12/20 HP", "- Bandit (foe): defeated", "- Korga struck Bandit down", "the player has won"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "15") {
+		t.Errorf("Expected no dice in the summary, got:\n%s", summary)
+	}
+
+	if got := c.Find("BANDIT"); got == nil || got.ID != "bandit" {
+		t.Errorf("Expected Find to match by name, got %+v", got)
+	}
+}
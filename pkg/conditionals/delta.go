@@ -1,5 +1,10 @@
 package conditionals
 
+import (
+	"slices"
+	"strings"
+)
+
 // GameStateDelta is a compact, structured representation of changes to game state.
 // It is intentionally smaller and stricter than the full game state.
 type GameStateDelta struct {
@@ -33,6 +38,65 @@ type GameStateDelta struct {
 	SetVars   map[string]string `json:"set_vars,omitempty"`
 	GameEnded *bool             `json:"game_ended,omitempty"`
 	Prompt    *string           `json:"prompt,omitempty"` // Narrative prompt to inject as a story event
+	Check     *SkillCheck       `json:"check,omitempty"`  // d20 roll for the PC; its result is given to the next narration
+	Attack    *CombatAttack     `json:"attack,omitempty"` // The PC's attack in a fight, which plays a combat round
+
+	// Scenario conditionals only; never accepted from the model
+	RemoveVars     []string          `json:"remove_vars,omitempty"`     // Vars to unset
+	ClearInventory []InventoryFilter `json:"clear_inventory,omitempty"` // Remove matching items from the player's inventory
+	RemoveNPCs     []string          `json:"remove_npcs,omitempty"`     // NPC IDs to remove from the game, with their items
+	UnlockLore     []string          `json:"unlock_lore,omitempty"`     // Lore entry IDs to add to the player's codex
+	StartCombat    *CombatStart      `json:"start_combat,omitempty"`    // Start a fight between the PC and these foes
+	EndCombat      bool              `json:"end_combat,omitempty"`      // End the fight in progress, if any
+}
+
+// Bounds on a skill check's difficulty class
+const (
+	MinDC = 1
+	MaxDC = 30
+)
+
+// SkillCheck asks for a d20 roll against one of the PC's abilities or skills, as in
+// {"check": {"attribute": "stealth", "dc": 15}}. The roll adds the PC's modifier for
+// the attribute and succeeds if it meets the DC.
+type SkillCheck struct {
+	Attribute string `json:"attribute"`        // Ability ("dexterity") or skill ("stealth")
+	DC        int    `json:"dc"`               // Difficulty class, from MinDC to MaxDC
+	Reason    string `json:"reason,omitempty"` // What the roll decides, e.g. "sneak past the guard"
+	Var       string `json:"var,omitempty"`    // Variable set to "success" or "failure" by the roll, for conditionals to test
+}
+
+// CombatAttack is the PC's attack on a foe in the fight in progress
+type CombatAttack struct {
+	Target string `json:"target"` // Foe's NPC ID, monster instance ID, or name
+}
+
+// CombatStart starts a fight between the PC and NPCs or monsters that have hit points
+type CombatStart struct {
+	Foes   []string `json:"foes"`             // NPC IDs and monster instance IDs
+	Reason string   `json:"reason,omitempty"` // Why the fight starts, for narration
+}
+
+// InventoryFilter selects player inventory items for clear_inventory.
+// An empty filter matches every item.
+type InventoryFilter struct {
+	Items    []string `json:"items,omitempty"`    // Match these exact items
+	Contains string   `json:"contains,omitempty"` // Match items containing this text (case-insensitive)
+	Except   []string `json:"except,omitempty"`   // Never match these items
+}
+
+// Matches reports whether the filter selects an item
+func (f InventoryFilter) Matches(item string) bool {
+	if slices.Contains(f.Except, item) {
+		return false
+	}
+	if len(f.Items) > 0 && !slices.Contains(f.Items, item) {
+		return false
+	}
+	if f.Contains != "" && !strings.Contains(strings.ToLower(item), strings.ToLower(f.Contains)) {
+		return false
+	}
+	return true
 }
 
 type MonsterEventAction string
@@ -0,0 +1,148 @@
+package conditionals
+
+// Item event actions
+const (
+	ItemActionAcquire = "acquire"
+	ItemActionGive    = "give"
+	ItemActionDrop    = "drop"
+	ItemActionMove    = "move"
+	ItemActionUse     = "use"
+)
+
+// Item holder types, for an item event's from/to
+const (
+	HolderPlayer   = "player"
+	HolderNPC      = "npc"
+	HolderLocation = "location"
+)
+
+// ItemActions are the valid item event actions
+var ItemActions = []string{ItemActionAcquire, ItemActionGive, ItemActionDrop, ItemActionMove, ItemActionUse}
+
+// HolderTypes are the valid item holder types
+var HolderTypes = []string{HolderPlayer, HolderNPC, HolderLocation}
+
+// DeltaSchema returns the JSON Schema the backend model's delta output must match.
+// Providers send it as a structured output format, and the same enums are
+// enforced again when the delta is validated before it is applied.
+func DeltaSchema() map[string]any {
+	holder := func() map[string]any {
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"type": map[string]any{
+					"type": "string",
+					"enum": HolderTypes,
+				},
+				"name": map[string]any{
+					"type": "string",
+				},
+			},
+			"required": []string{"type"},
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"user_location": map[string]any{
+				"type": "string",
+			},
+			// REQUIRED + NULLABLE scene_change
+			"scene_change": map[string]any{
+				"anyOf": []any{
+					map[string]any{
+						"type":                 "object",
+						"additionalProperties": false,
+						"properties": map[string]any{
+							"to":     map[string]any{"type": "string"},
+							"reason": map[string]any{"type": "string"},
+						},
+						"required": []string{"to", "reason"},
+					},
+					map[string]any{"type": "null"},
+				},
+			},
+			"item_events": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties": map[string]any{
+						"item": map[string]any{
+							"type": "string",
+						},
+						"action": map[string]any{
+							"type": "string",
+							"enum": ItemActions,
+						},
+						"from": holder(),
+						"to":   holder(),
+						"consumed": map[string]any{
+							"type": "boolean",
+						},
+					},
+					"required": []string{"item", "action"},
+				},
+			},
+			"npc_events": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties": map[string]any{
+						"npc_id": map[string]any{
+							"type": "string",
+						},
+						"set_location": map[string]any{
+							"type": "string",
+						},
+					},
+					"required": []string{"npc_id"},
+				},
+			},
+			"set_vars": map[string]any{
+				"type": "object",
+				"additionalProperties": map[string]any{
+					"type": "string",
+				},
+			},
+			"game_ended": map[string]any{
+				"type": "boolean",
+			},
+			// REQUIRED + NULLABLE check
+			"check": map[string]any{
+				"anyOf": []any{
+					map[string]any{
+						"type":                 "object",
+						"additionalProperties": false,
+						"properties": map[string]any{
+							"attribute": map[string]any{"type": "string"},
+							"dc":        map[string]any{"type": "integer"},
+							"reason":    map[string]any{"type": "string"},
+						},
+						"required": []string{"attribute", "dc", "reason"},
+					},
+					map[string]any{"type": "null"},
+				},
+			},
+			// REQUIRED + NULLABLE attack
+			"attack": map[string]any{
+				"anyOf": []any{
+					map[string]any{
+						"type":                 "object",
+						"additionalProperties": false,
+						"properties": map[string]any{
+							"target": map[string]any{"type": "string"},
+						},
+						"required": []string{"target"},
+					},
+					map[string]any{"type": "null"},
+				},
+			},
+		},
+		"required": []string{"user_location", "scene_change", "item_events", "npc_events", "set_vars", "game_ended", "check", "attack"},
+	}
+}
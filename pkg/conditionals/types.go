@@ -32,6 +32,7 @@ type ConditionalWhen struct {
 	Location         string            `json:"location,omitempty"`           // User must be at this location
 	MinSceneTurns    *int              `json:"min_scene_turns,omitempty"`    // Scene turn counter >= this value
 	MinTurns         *int              `json:"min_turns,omitempty"`          // Turn counter >= this value
+	LoreKnown        []string          `json:"lore_known,omitempty"`         // All specified lore entries must be discovered
 }
 
 // GameStateView provides the minimal interface needed to evaluate conditionals
@@ -42,6 +43,7 @@ type GameStateView interface {
 	GetSceneTurnCounter() int
 	GetTurnCounter() int
 	GetUserLocation() string
+	KnowsLore(id string) bool
 }
 
 // FilterContingencyPrompts returns only the prompts whose conditions are met
@@ -71,7 +73,8 @@ func EvaluateWhen(when ConditionalWhen, gsView GameStateView) bool {
 		when.TurnCounter != nil ||
 		when.Location != "" ||
 		when.MinSceneTurns != nil ||
-		when.MinTurns != nil
+		when.MinTurns != nil ||
+		len(when.LoreKnown) > 0
 
 	if !hasCondition {
 		return false
@@ -127,6 +130,13 @@ func EvaluateWhen(when ConditionalWhen, gsView GameStateView) bool {
 		}
 	}
 
+	// Check discovered lore
+	for _, id := range when.LoreKnown {
+		if !gsView.KnowsLore(id) {
+			return false
+		}
+	}
+
 	// All conditions passed
 	return true
 }
@@ -0,0 +1,392 @@
+// Package jsonpatch applies JSON Patch (RFC 6902) and JSON Merge Patch (RFC 7396)
+// documents to JSON values.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types for the two patch formats
+const (
+	MediaTypeJSONPatch  = "application/json-patch+json"
+	MediaTypeMergePatch = "application/merge-patch+json"
+)
+
+// ErrTestFailed is returned when a patch's test operation doesn't match the document
+var ErrTestFailed = errors.New("test failed: value does not match")
+
+// Operation is one step of a JSON Patch document
+type Operation struct {
+	Op    string          `json:"op"`              // add, remove, replace, move, copy, or test
+	Path  string          `json:"path"`            // JSON Pointer (RFC 6901) to the target
+	From  string          `json:"from,omitempty"`  // Source pointer for move and copy
+	Value json.RawMessage `json:"value,omitempty"` // Value for add, replace, and test
+}
+
+// Apply applies a JSON Patch document to doc. Operations are applied in order,
+// and if any fails the whole patch fails and doc is left unchanged.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid JSON Patch document: %w", err)
+	}
+	root, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target document: %w", err)
+	}
+
+	for i, op := range ops {
+		if root, err = applyOp(root, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+// MergePatch applies a JSON Merge Patch document to doc: objects are merged
+// recursively, null removes a member, and any other value replaces the target.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	root, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target document: %w", err)
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch document: %w", err)
+	}
+	return json.Marshal(merge(root, p))
+}
+
+// CreateMergePatch returns a JSON Merge Patch document that turns original into
+// modified. Arrays are replaced whole, and members that are null in modified are
+// removed, since a merge patch can't set a value to null.
+func CreateMergePatch(original, modified []byte) ([]byte, error) {
+	a, err := decode(original)
+	if err != nil {
+		return nil, fmt.Errorf("invalid original document: %w", err)
+	}
+	b, err := decode(modified)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modified document: %w", err)
+	}
+	return json.Marshal(diff(a, b))
+}
+
+func diff(original, modified any) any {
+	a, aok := original.(map[string]any)
+	b, bok := modified.(map[string]any)
+	if !aok || !bok {
+		return modified
+	}
+	patch := make(map[string]any)
+	for k := range a {
+		if v, ok := b[k]; !ok || v == nil {
+			if a[k] != nil {
+				patch[k] = nil
+			}
+		}
+	}
+	for k, v := range b {
+		if v == nil {
+			continue
+		}
+		old, ok := a[k]
+		if ok && equal(old, v) {
+			continue
+		}
+		if ok {
+			patch[k] = diff(old, v)
+		} else {
+			patch[k] = v
+		}
+	}
+	return patch
+}
+
+func merge(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = merge(targetObj[k], v)
+	}
+	return targetObj
+}
+
+// decode parses JSON keeping numbers as json.Number, so large integers survive a round trip
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func applyOp(root any, op Operation) (any, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("value is required")
+		}
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		switch op.Op {
+		case "add":
+			return add(root, op.Path, value)
+		case "replace":
+			if _, err := get(root, op.Path); err != nil {
+				return nil, err
+			}
+			if op.Path == "" {
+				return value, nil // the whole document
+			}
+			if root, err = remove(root, op.Path); err != nil {
+				return nil, err
+			}
+			return add(root, op.Path, value)
+		default:
+			current, err := get(root, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !equal(current, value) {
+				return nil, ErrTestFailed
+			}
+			return root, nil
+		}
+	case "remove":
+		return remove(root, op.Path)
+	case "move", "copy":
+		value, err := get(root, op.From)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "move" {
+			if op.Path == op.From {
+				return root, nil
+			}
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, fmt.Errorf("cannot move a value into one of its children")
+			}
+			if root, err = remove(root, op.From); err != nil {
+				return nil, err
+			}
+		} else {
+			// Copy through JSON so the two locations don't share maps or slices
+			data, _ := json.Marshal(value)
+			value, _ = decode(data)
+		}
+		return add(root, op.Path, value)
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+// parsePointer splits a JSON Pointer into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q: must be empty or start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// get returns the value at pointer
+func get(root any, pointer string) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	node := root
+	for _, t := range tokens {
+		switch n := node.(type) {
+		case map[string]any:
+			v, ok := n[t]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			node = v
+		case []any:
+			i, err := arrayIndex(t, len(n)-1)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %w", pointer, err)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	}
+	return node, nil
+}
+
+// add inserts or sets value at pointer and returns the new root
+func add(root any, pointer string, value any) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return update(root, tokens, pointer, func(parent any, last string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			p[last] = value
+			return p, nil
+		case []any:
+			if last == "-" {
+				return append(p, value), nil
+			}
+			i, err := arrayIndex(last, len(p))
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %w", pointer, err)
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		default:
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	})
+}
+
+// remove deletes the value at pointer and returns the new root
+func remove(root any, pointer string) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	return update(root, tokens, pointer, func(parent any, last string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			if _, ok := p[last]; !ok {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			delete(p, last)
+			return p, nil
+		case []any:
+			i, err := arrayIndex(last, len(p)-1)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %w", pointer, err)
+			}
+			return append(p[:i], p[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	})
+}
+
+// update walks to the parent of the last token, lets fn modify it, and writes the
+// result back up the tree, since appending to an array can return a new slice
+func update(node any, tokens []string, pointer string, fn func(parent any, last string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return fn(node, tokens[0])
+	}
+	t := tokens[0]
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[t]
+		if !ok {
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+		updated, err := update(child, tokens[1:], pointer, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[t] = updated
+		return n, nil
+	case []any:
+		i, err := arrayIndex(t, len(n)-1)
+		if err != nil {
+			return nil, fmt.Errorf("path %q: %w", pointer, err)
+		}
+		updated, err := update(n[i], tokens[1:], pointer, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = updated
+		return n, nil
+	default:
+		return nil, fmt.Errorf("path %q does not exist", pointer)
+	}
+}
+
+// arrayIndex parses an array index token, which must be between 0 and limit
+func arrayIndex(token string, limit int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > limit {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// equal compares two decoded JSON values, treating numbers as equal by value
+func equal(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
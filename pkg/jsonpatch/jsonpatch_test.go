@@ -0,0 +1,271 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func assertJSONEqual(t *testing.T, want string, got []byte) {
+	t.Helper()
+	var w, g any
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid expected JSON: %v", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid result JSON: %v", err)
+	}
+	if !reflect.DeepEqual(w, g) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "add member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:  "add array element",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "append to array",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": "baz"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "remove member",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo": "bar"}`,
+		},
+		{
+			name:  "remove array element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "replace with false",
+			doc:   `{"ended": true}`,
+			patch: `[{"op": "replace", "path": "/ended", "value": false}]`,
+			want:  `{"ended": false}`,
+		},
+		{
+			name:  "replace whole document",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "replace", "path": "", "value": {"b": [2]}}]`,
+			want:  `{"b": [2]}`,
+		},
+		{
+			name:  "move nested",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "copy does not alias",
+			doc:   `{"a": {"x": 1}}`,
+			patch: `[{"op": "copy", "from": "/a", "path": "/b"}, {"op": "replace", "path": "/b/x", "value": 2}]`,
+			want:  `{"a": {"x": 1}, "b": {"x": 2}}`,
+		},
+		{
+			name:  "escaped pointer",
+			doc:   `{"a/b": 1, "m~n": 2}`,
+			patch: `[{"op": "remove", "path": "/a~1b"}, {"op": "replace", "path": "/m~0n", "value": 3}]`,
+			want:  `{"m~n": 3}`,
+		},
+		{
+			name:  "test passes",
+			doc:   `{"n": 1, "list": [1, "a"]}`,
+			patch: `[{"op": "test", "path": "/n", "value": 1.0}, {"op": "test", "path": "/list", "value": [1, "a"]}]`,
+			want:  `{"n": 1, "list": [1, "a"]}`,
+		},
+		{
+			name:  "add null value",
+			doc:   `{}`,
+			patch: `[{"op": "add", "path": "/a", "value": null}]`,
+			want:  `{"a": null}`,
+		},
+		{
+			name:    "test fails",
+			doc:     `{"n": 1}`,
+			patch:   `[{"op": "test", "path": "/n", "value": 2}]`,
+			wantErr: true,
+		},
+		{
+			name:    "replace missing member",
+			doc:     `{}`,
+			patch:   `[{"op": "replace", "path": "/a", "value": 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "remove missing member",
+			doc:     `{}`,
+			patch:   `[{"op": "remove", "path": "/a"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "add to missing parent",
+			doc:     `{}`,
+			patch:   `[{"op": "add", "path": "/a/b", "value": 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "index out of range",
+			doc:     `{"a": [1]}`,
+			patch:   `[{"op": "add", "path": "/a/2", "value": 1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "leading zero index",
+			doc:     `{"a": [1, 2]}`,
+			patch:   `[{"op": "remove", "path": "/a/01"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "move into own child",
+			doc:     `{"a": {"b": {}}}`,
+			patch:   `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			doc:     `{}`,
+			patch:   `[{"op": "add", "path": "/a"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown op",
+			doc:     `{}`,
+			patch:   `[{"op": "frobnicate", "path": "/a"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "not an array",
+			doc:     `{}`,
+			patch:   `{"op": "add", "path": "/a", "value": 1}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSONEqual(t, tt.want, got)
+		})
+	}
+}
+
+func TestApply_PreservesLargeIntegers(t *testing.T) {
+	got, err := Apply([]byte(`{"seed": 9007199254740993}`), []byte(`[{"op": "add", "path": "/x", "value": 1}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != `{"seed":9007199254740993,"x":1}` {
+		t.Errorf("expected seed to survive unchanged, got %s", got)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// Examples from RFC 7396 Appendix A
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSONEqual(t, tt.want, got)
+		})
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		modified string
+		want     string
+	}{
+		{"unchanged", `{"a": "b", "n": 1}`, `{"a": "b", "n": 1.0}`, `{}`},
+		{"replaced value", `{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{"added member", `{"a": "b"}`, `{"a": "b", "b": "c"}`, `{"b": "c"}`},
+		{"removed member", `{"a": "b", "b": "c"}`, `{"a": "b"}`, `{"b": null}`},
+		{"member set to null", `{"a": "b"}`, `{"a": null}`, `{"a": null}`},
+		{"null member unchanged", `{"a": null}`, `{}`, `{}`},
+		{"nested object", `{"a": {"b": "c", "d": "e"}}`, `{"a": {"b": "c", "d": "f"}}`, `{"a": {"d": "f"}}`},
+		{"array replaced whole", `{"a": [1, 2]}`, `{"a": [1, 2, 3]}`, `{"a": [1, 2, 3]}`},
+		{"from empty document", `{}`, `{"a": {"b": [1]}}`, `{"a": {"b": [1]}}`},
+		{"not an object", `["a"]`, `{"a": "b"}`, `{"a": "b"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := CreateMergePatch([]byte(tt.original), []byte(tt.modified))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSONEqual(t, tt.want, patch)
+
+			// Applying the patch must give back the modified document; null members are
+			// dropped from both, as a merge patch treats them as absent
+			got, err := MergePatch([]byte(tt.original), patch)
+			if err != nil {
+				t.Fatalf("unexpected error applying patch: %v", err)
+			}
+			got, _ = MergePatch([]byte(`{}`), got)
+			want, _ := MergePatch([]byte(`{}`), []byte(tt.modified))
+			assertJSONEqual(t, string(want), got)
+		})
+	}
+}
+
+func TestApply_TestFailedError(t *testing.T) {
+	_, err := Apply([]byte(`{"turn_counter": 3}`), []byte(`[{"op": "test", "path": "/turn_counter", "value": 4}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Errorf("expected ErrTestFailed, got %v", err)
+	}
+}
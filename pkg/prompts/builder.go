@@ -17,6 +17,9 @@ type Builder struct {
 	userMessage  string
 	userRole     string
 	historyLimit int
+	prefixes     *PrefixCache
+	meta         bool // ask for a narration trailer; see WithNarrationMeta
+	maxSentences int  // see WithMaxSentences
 	messages     []chat.ChatMessage
 }
 
@@ -53,6 +56,25 @@ func (b *Builder) WithHistoryLimit(limit int) *Builder {
 	return b
 }
 
+// WithPrefixCache reuses the system prompt prewarmed for this turn, if it is still valid.
+func (b *Builder) WithPrefixCache(prefixes *PrefixCache) *Builder {
+	b.prefixes = prefixes
+	return b
+}
+
+// WithNarrationMeta asks the narrator to end its reply with a <turn_meta> trailer.
+func (b *Builder) WithNarrationMeta(enabled bool) *Builder {
+	b.meta = enabled
+	return b
+}
+
+// WithMaxSentences asks the narrator to keep its reply to maxSentences sentences.
+// Zero asks nothing.
+func (b *Builder) WithMaxSentences(maxSentences int) *Builder {
+	b.maxSentences = maxSentences
+	return b
+}
+
 // Build constructs and returns the final message array for LLM consumption.
 func (b *Builder) Build() ([]chat.ChatMessage, error) {
 	if b.gs == nil {
@@ -63,84 +85,170 @@ func (b *Builder) Build() ([]chat.ChatMessage, error) {
 	}
 
 	b.messages = make([]chat.ChatMessage, 0)
-	if err := b.addSystemPrompt(); err != nil {
-		return nil, fmt.Errorf("error building system prompt: %w", err)
+	if b.gs.Conversation != nil {
+		// A side conversation replaces the world state and history with the NPC's own
+		msg, err := buildConversationMessage(b.gs, b.scenario)
+		if err != nil {
+			return nil, fmt.Errorf("error building conversation prompt: %w", err)
+		}
+		b.messages = append(b.messages, msg)
+		b.addHistory(b.gs.Conversation.History)
+	} else {
+		if err := b.addSystemPrompt(); err != nil {
+			return nil, fmt.Errorf("error building system prompt: %w", err)
+		}
+		b.addHistory(b.gs.ChatHistory)
 	}
-	b.addHistory()
+	b.addLore()
+	b.addSkillCheck()
+	b.addCombat()
 	b.addUserMessage()
 	b.addFinalPrompt()
+	b.addLengthPrompt()
+	b.addNarrationMetaPrompt()
 	return b.messages, nil
 }
 
-// addSystemPrompt builds the main system prompt from narrator, scenario, and state.
+// addSystemPrompt adds the main system prompt, from the prefix cache if it was prewarmed.
 func (b *Builder) addSystemPrompt() error {
-	var sb strings.Builder
-
-	// Build system prompt with embedded narrator and PC
-	systemPrompt := BuildSystemPrompt(b.gs.Narrator, b.gs.PC)
-	sb.WriteString(systemPrompt)
+	if msg, ok := b.prefixes.Get(b.gs, b.scenario); ok {
+		b.messages = append(b.messages, msg)
+		return nil
+	}
 
-	// Add rating prompt
-	sb.WriteString("\n\nContent Rating: " + b.scenario.Rating)
-	ratingPrompt := GetContentRatingPrompt(b.scenario.Rating)
-	if ratingPrompt != "" {
-		sb.WriteString(" (" + ratingPrompt + ")")
+	msg, err := buildSystemMessage(b.gs, b.scenario)
+	if err != nil {
+		return err
 	}
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+// buildSystemMessage builds the main system prompt from narrator, scenario, and state.
+// The narrator, PC, and rating come first and are marked as the cacheable prefix,
+// since they rarely change during a game.
+func buildSystemMessage(gs *state.GameState, s *scenario.Scenario) (chat.ChatMessage, error) {
+	var sb strings.Builder
+	writeSystemHead(&sb, gs, s)
+	cachePrefix := sb.Len()
 
 	// Add state context
-	statePrompt, err := GetStatePrompt(b.gs, b.scenario)
+	statePrompt, err := GetStatePrompt(gs, s)
 	if err != nil {
-		return fmt.Errorf("error generating state prompt: %w", err)
+		return chat.ChatMessage{}, fmt.Errorf("error generating state prompt: %w", err)
 	}
 	sb.WriteString("\n\n" + statePrompt.Content)
 
 	// Add contingency prompts
-	contingencyPrompts := b.gs.GetContingencyPrompts(b.scenario)
+	contingencyPrompts := gs.GetContingencyPrompts(s)
 	if len(contingencyPrompts) > 0 {
 		sb.WriteString("\n\nSome important storytelling guidelines:\n\n")
 		for i, prompt := range contingencyPrompts {
-			fmt.Fprintf(&sb, "%d. %s\n", i+1, prompt)
+			fmt.Fprintf(&sb, "%d. %s\n", i+1, gs.Interpolate(prompt))
 		}
 	}
 
-	b.messages = append(b.messages, chat.ChatMessage{
-		Role:    chat.ChatRoleSystem,
-		Content: sb.String(),
-	})
+	return chat.ChatMessage{
+		Role:        chat.ChatRoleSystem,
+		Content:     sb.String(),
+		CachePrefix: cachePrefix,
+	}, nil
+}
 
-	return nil
+// writeSystemHead writes the start of every system prompt: the narrator and PC
+// prompts, then the content rating
+func writeSystemHead(sb *strings.Builder, gs *state.GameState, s *scenario.Scenario) {
+	sb.WriteString(BuildSystemPrompt(gs.Narrator, gs.PC))
+	sb.WriteString("\n\nContent Rating: " + s.Rating)
+	if ratingPrompt := GetContentRatingPrompt(s.Rating); ratingPrompt != "" {
+		sb.WriteString(" (" + ratingPrompt + ")")
+	}
 }
 
 // addHistory adds windowed chat history to the message array.
-func (b *Builder) addHistory() {
-	if len(b.gs.ChatHistory) == 0 {
+func (b *Builder) addHistory(history []chat.ChatMessage) {
+	if len(history) == 0 {
 		return
 	}
 
 	// Window the history to the specified limit
-	if len(b.gs.ChatHistory) <= b.historyLimit {
-		b.messages = append(b.messages, b.gs.ChatHistory...)
+	if len(history) <= b.historyLimit {
+		b.messages = append(b.messages, history...)
 	} else {
-		b.messages = append(b.messages, b.gs.ChatHistory[len(b.gs.ChatHistory)-b.historyLimit:]...)
+		b.messages = append(b.messages, history[len(history)-b.historyLimit:]...)
+	}
+}
+
+// addLore adds the scenario's lore entries whose keywords appear in the user message
+// or the last few messages of history, so the narrator only gets lore that bears on
+// the moment.
+func (b *Builder) addLore() {
+	if len(b.scenario.Lore) == 0 {
+		return
+	}
+	history := b.gs.ChatHistory
+	if b.gs.Conversation != nil {
+		history = b.gs.Conversation.History
+	}
+	texts := []string{b.userMessage}
+	for _, m := range history[max(0, len(history)-LoreScanMessages):] {
+		texts = append(texts, m.Content)
+	}
+
+	ids := b.scenario.MatchLore(texts...)
+	if len(ids) == 0 {
+		return
 	}
+	var sb strings.Builder
+	sb.WriteString(LorePrompt + "\n<lore>\n")
+	for _, id := range ids {
+		entry := b.scenario.Lore[id]
+		fmt.Fprintf(&sb, "- %s: %s\n", entry.Title, entry.Text)
+	}
+	sb.WriteString("</lore>")
+
+	b.messages = append(b.messages, chat.ChatMessage{
+		Role:    chat.ChatRoleSystem,
+		Content: sb.String(),
+	})
+}
+
+// addSkillCheck tells the narrator the outcome of the check the last delta rolled
+func (b *Builder) addSkillCheck() {
+	check := b.gs.PendingCheck
+	if check == nil {
+		return
+	}
+	reason := check.Reason
+	if reason == "" {
+		reason = "an uncertain attempt"
+	}
+	b.messages = append(b.messages, chat.ChatMessage{
+		Role:    chat.ChatRoleSystem,
+		Content: fmt.Sprintf(SkillCheckPrompt, strings.ReplaceAll(check.Attribute, "_", " "), reason, check.Outcome()),
+	})
+}
+
+// addCombat puts the narrator in combat mode while there's a fight to narrate
+func (b *Builder) addCombat() {
+	if b.gs.Combat == nil {
+		return
+	}
+	b.messages = append(b.messages, chat.ChatMessage{
+		Role:    chat.ChatRoleSystem,
+		Content: CombatPrompt + "\n<combat>\n" + b.gs.Combat.Summary() + "\n</combat>",
+	})
 }
 
 // addUserMessage adds the current user message to the message array,
-// with the rules block appended. Base engine rules are always included;
-// if the narrator defines additional rules, they are appended after.
+// with the rules block appended; see ComposeRules.
 func (b *Builder) addUserMessage() {
 	if b.userMessage == "" {
 		return
 	}
 
-	allRules := make([]string, len(NarratorRules))
-	copy(allRules, NarratorRules)
-	if b.gs.Narrator != nil && len(b.gs.Narrator.Rules) > 0 {
-		allRules = append(allRules, b.gs.Narrator.Rules...)
-	}
-
 	content := b.userMessage
-	if rulesBlock := FormatRulesBlock(allRules); rulesBlock != "" {
+	if rulesBlock := FormatRulesBlock(ComposeRules(b.scenario, b.gs.Narrator)); rulesBlock != "" {
 		content += "\n\n" + rulesBlock
 	}
 
@@ -167,6 +275,28 @@ func (b *Builder) addFinalPrompt() {
 	})
 }
 
+// addLengthPrompt asks for a short reply when the narration has a sentence limit
+func (b *Builder) addLengthPrompt() {
+	if b.maxSentences <= 0 {
+		return
+	}
+	b.messages = append(b.messages, chat.ChatMessage{
+		Role:    chat.ChatRoleSystem,
+		Content: fmt.Sprintf(MaxSentencesPrompt, b.maxSentences),
+	})
+}
+
+// addNarrationMetaPrompt asks for the narration trailer, last so that it isn't forgotten
+func (b *Builder) addNarrationMetaPrompt() {
+	if !b.meta {
+		return
+	}
+	b.messages = append(b.messages, chat.ChatMessage{
+		Role:    chat.ChatRoleSystem,
+		Content: NarrationMetaPrompt,
+	})
+}
+
 // BuildMessages is a convenience function for the common case.
 // It creates a builder, sets all parameters, and builds the messages in one call.
 func BuildMessages(
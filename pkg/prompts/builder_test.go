@@ -1,11 +1,13 @@
 package prompts
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/jwebster45206/story-engine/pkg/actor"
 	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/combat"
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
 	"github.com/jwebster45206/story-engine/pkg/state"
@@ -352,6 +354,147 @@ func TestBuilder_Build_GameEnded(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_NarrationMeta(t *testing.T) {
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.Location = "start"
+	scenario := &scenario.Scenario{
+		Name:      "Test Scenario",
+		Story:     "A test adventure",
+		Locations: map[string]scenario.Location{"start": {Name: "start", Description: "Starting location"}},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		messages, err := New().
+			WithGameState(gs).
+			WithScenario(scenario).
+			WithUserMessage("Test", chat.ChatRoleUser).
+			WithNarrationMeta(enabled).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		last := messages[len(messages)-1]
+		if got := last.Content == NarrationMetaPrompt; got != enabled {
+			t.Errorf("enabled %t: expected the trailer prompt last to be %t, got last message %q", enabled, enabled, last.Content)
+		}
+	}
+}
+
+func TestBuilder_Build_MaxSentences(t *testing.T) {
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.Location = "start"
+	scenario := &scenario.Scenario{
+		Name:      "Test Scenario",
+		Story:     "A test adventure",
+		Locations: map[string]scenario.Location{"start": {Name: "start", Description: "Starting location"}},
+	}
+	lengthPrompt := fmt.Sprintf(MaxSentencesPrompt, 3)
+
+	for _, maxSentences := range []int{0, 3} {
+		messages, err := New().
+			WithGameState(gs).
+			WithScenario(scenario).
+			WithUserMessage("Test", chat.ChatRoleUser).
+			WithMaxSentences(maxSentences).
+			WithNarrationMeta(true).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// The trailer prompt stays last
+		got := messages[len(messages)-2].Content == lengthPrompt
+		if want := maxSentences > 0; got != want {
+			t.Errorf("max %d: expected the length prompt before the trailer prompt to be %t", maxSentences, want)
+		}
+	}
+}
+
+func TestBuilder_Build_SkillCheck(t *testing.T) {
+	scenario := &scenario.Scenario{
+		Name:      "Test Scenario",
+		Story:     "A test adventure",
+		Locations: map[string]scenario.Location{"start": {Name: "start", Description: "Starting location"}},
+	}
+	tests := []struct {
+		name  string
+		check *state.CheckResult
+		want  string
+	}{
+		{"no check", nil, ""},
+		{"success", &state.CheckResult{Attribute: "sleight_of_hand", DC: 15, Reason: "lift the key", Roll: 14, Modifier: 3, Total: 17, Success: true}, fmt.Sprintf(SkillCheckPrompt, "sleight of hand", "lift the key", "success")},
+		{"failure without reason", &state.CheckResult{Attribute: "stealth", DC: 15, Roll: 2, Total: 2}, fmt.Sprintf(SkillCheckPrompt, "stealth", "an uncertain attempt", "failure")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := state.NewGameState("test.json", nil, "test-model")
+			gs.Location = "start"
+			gs.PendingCheck = tt.check
+
+			messages, err := New().
+				WithGameState(gs).
+				WithScenario(scenario).
+				WithUserMessage("I keep going", chat.ChatRoleUser).
+				Build()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// The outcome comes just before the player's message
+			got := ""
+			if before := messages[len(messages)-2]; before.Role == chat.ChatRoleSystem && strings.HasPrefix(before.Content, "The game rolled") {
+				got = before.Content
+			}
+			if got != tt.want {
+				t.Errorf("Expected skill check prompt %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuilder_Build_Combat(t *testing.T) {
+	scenario := &scenario.Scenario{
+		Name:      "Test Scenario",
+		Story:     "A test adventure",
+		Locations: map[string]scenario.Location{"start": {Name: "start", Description: "Starting location"}},
+	}
+	fight := &combat.Combat{
+		Round: 1,
+		Combatants: []combat.Combatant{
+			{ID: combat.PCID, Name: "Korga", HP: 20, MaxHP: 20},
+			{ID: "bandit", Name: "Bandit", HP: 5, MaxHP: 9},
+		},
+		LastRound: []combat.Attack{{Attacker: combat.PCID, Target: "bandit", Roll: 14, Total: 19, Hit: true, Damage: 4}},
+	}
+
+	for _, inCombat := range []bool{false, true} {
+		gs := state.NewGameState("test.json", nil, "test-model")
+		gs.Location = "start"
+		if inCombat {
+			gs.Combat = fight
+		}
+		messages, err := New().
+			WithGameState(gs).
+			WithScenario(scenario).
+			WithUserMessage("I swing again", chat.ChatRoleUser).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// Combat mode comes just before the player's message
+		before := messages[len(messages)-2]
+		got := before.Role == chat.ChatRoleSystem && strings.HasPrefix(before.Content, CombatPrompt)
+		if got != inCombat {
+			t.Errorf("in combat %t: expected combat prompt %t, got %q", inCombat, inCombat, before.Content)
+		}
+		if inCombat && !strings.Contains(before.Content, "Korga hit Bandit") {
+			t.Errorf("Expected the last round in the combat prompt, got %q", before.Content)
+		}
+	}
+}
+
 func TestBuilder_Build_WithContingencyPrompts(t *testing.T) {
 	gs := state.NewGameState("test.json", nil, "test-model")
 	gs.Location = "start"
@@ -470,3 +613,105 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestBuilder_Build_Conversation(t *testing.T) {
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.Location = "tavern"
+	gs.WorldLocations["tavern"] = scenario.Location{Name: "Rusty Anchor", Description: "A smoky tavern."}
+	gs.NPCs["gibbs"] = actor.NPC{Name: "Gibbs", Type: "sailor", Disposition: "friendly", Description: "A grizzled first mate.", Location: "tavern"}
+	gs.NPCs["anne"] = actor.NPC{Name: "Anne", Description: "A quiet smuggler.", Location: "tavern"}
+	gs.ChatHistory = []chat.ChatMessage{
+		{Role: chat.ChatRoleUser, Content: "I enter the tavern"},
+		{Role: chat.ChatRoleAgent, Content: "Smoke hangs low."},
+	}
+	gs.NPCMemories = map[string][]chat.ChatMessage{
+		"gibbs": {{Role: chat.ChatRoleUser, Content: "Where is the ship?"}, {Role: chat.ChatRoleAgent, Content: "Gibbs: In the cove."}},
+	}
+	gs.Conversation = &state.Conversation{
+		NPCID:   "gibbs",
+		History: []chat.ChatMessage{{Role: chat.ChatRoleUser, Content: "Any news?"}, {Role: chat.ChatRoleAgent, Content: "Gibbs: Storm's coming."}},
+	}
+	s := &scenario.Scenario{Name: "Test", Story: "A pirate story", Rating: scenario.RatingPG}
+
+	messages, err := New().WithGameState(gs).WithScenario(s).WithUserMessage("What storm?", chat.ChatRoleUser).Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	system := messages[0].Content
+	for _, want := range []string{"talk with Gibbs", "A grizzled first mate.", "friendly", "Rusty Anchor", "Player: Where is the ship?", "Narrator: Gibbs: In the cove."} {
+		if !strings.Contains(system, want) {
+			t.Errorf("conversation prompt missing %q:\n%s", want, system)
+		}
+	}
+	for _, unwanted := range []string{"A quiet smuggler.", "A pirate story", "A smoky tavern."} {
+		if strings.Contains(system, unwanted) {
+			t.Errorf("conversation prompt should not contain %q", unwanted)
+		}
+	}
+
+	if len(messages) != 4 {
+		t.Fatalf("expected system, 2 conversation messages, and user message, got %d", len(messages))
+	}
+	if messages[1].Content != "Any news?" || messages[2].Content != "Gibbs: Storm's coming." {
+		t.Errorf("expected conversation history, got %+v", messages[1:3])
+	}
+}
+
+func TestBuilder_Build_Lore(t *testing.T) {
+	s := &scenario.Scenario{
+		Name:   "Test",
+		Rating: scenario.RatingPG,
+		Lore: map[string]scenario.LoreEntry{
+			"tortuga": {Title: "Tortuga", Keywords: []string{"tortuga"}, Text: "A pirate haven."},
+			"kraken":  {Title: "The Kraken", Keywords: []string{"kraken"}, Text: "A sea monster."},
+			"cove":    {Title: "Smuggler's Cove", Keywords: []string{"cove"}, Text: "A hidden inlet."},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		history     []string
+		userMessage string
+		want        []string
+		unwanted    []string
+	}{
+		{"user message", nil, "I ask about Tortuga", []string{"Tortuga: A pirate haven."}, []string{"Kraken", "Cove"}},
+		{"recent history", []string{"a", "b", "c", "The kraken surfaces."}, "I run", []string{"The Kraken: A sea monster."}, []string{"Tortuga"}},
+		{"old history ignored", []string{"We reach the cove.", "a", "b", "c", "d"}, "I wait", nil, []string{"Cove"}},
+		{"nothing relevant", []string{"The tide rolls in."}, "I wait", nil, []string{"<lore>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := state.NewGameState("test.json", nil, "test-model")
+			for _, h := range tt.history {
+				gs.ChatHistory = append(gs.ChatHistory, chat.ChatMessage{Role: chat.ChatRoleAgent, Content: h})
+			}
+			messages, err := New().WithGameState(gs).WithScenario(s).WithUserMessage(tt.userMessage, chat.ChatRoleUser).Build()
+			if err != nil {
+				t.Fatalf("Build() error: %v", err)
+			}
+
+			var all strings.Builder
+			for _, m := range messages {
+				if m.Role == chat.ChatRoleSystem {
+					all.WriteString(m.Content)
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(all.String(), want) {
+					t.Errorf("expected lore %q in system messages", want)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(all.String(), unwanted) {
+					t.Errorf("unexpected %q in system messages", unwanted)
+				}
+			}
+			if last := messages[len(messages)-1]; last.Role != chat.ChatRoleUser {
+				t.Errorf("expected the user message last, got %s", last.Role)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// buildConversationMessage builds the system prompt for a side conversation: the
+// narrator, PC, and rating as usual, then only the NPC's details and its memories of
+// earlier conversations in place of the world state.
+func buildConversationMessage(gs *state.GameState, s *scenario.Scenario) (chat.ChatMessage, error) {
+	npcID := gs.Conversation.NPCID
+	npc, ok := gs.NPCs[npcID]
+	if !ok {
+		return chat.ChatMessage{}, fmt.Errorf("NPC %s not found", npcID)
+	}
+	name := gs.NPCName(npcID)
+
+	var sb strings.Builder
+	writeSystemHead(&sb, gs, s)
+	sb.WriteString("\n\n" + fmt.Sprintf(ConversationPrompt, name))
+	sb.WriteString("\n\nAbout " + name + ":\n")
+	if npc.Type != "" {
+		sb.WriteString("- Type: " + npc.Type + "\n")
+	}
+	if npc.Disposition != "" {
+		sb.WriteString("- Disposition toward the player: " + npc.Disposition + "\n")
+	}
+	if npc.Description != "" {
+		sb.WriteString("- " + npc.Description + "\n")
+	}
+	if len(npc.Items) > 0 {
+		sb.WriteString("- Carries: " + strings.Join(gs.DisplayItems(npc.Items), ", ") + "\n")
+	}
+	if loc, ok := gs.WorldLocations[gs.Location]; ok && loc.Name != "" {
+		sb.WriteString("- Talking with the player at: " + loc.Name + "\n")
+	}
+
+	if memories := gs.NPCMemories[npcID]; len(memories) > 0 {
+		sb.WriteString("\nWhat " + name + " remembers of earlier conversations, oldest first:\n")
+		for _, m := range memories {
+			speaker := "Player"
+			if m.Role == chat.ChatRoleAgent {
+				speaker = "Narrator"
+			}
+			fmt.Fprintf(&sb, "%s: %s\n", speaker, m.Content)
+		}
+	}
+
+	return chat.ChatMessage{
+		Role:    chat.ChatRoleSystem,
+		Content: sb.String(),
+	}, nil
+}
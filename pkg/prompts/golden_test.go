@@ -0,0 +1,197 @@
+package prompts
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// Golden files pin the exact messages sent to the model. After an intended
+// prompt change, regenerate them and review the diff:
+//
+//	go test ./pkg/prompts -run TestBuild_Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+const (
+	goldenDir         = "testdata/golden"
+	goldenUserMessage = "I look around."
+)
+
+// goldenGameState is a gamestate fixture. State is overlaid onto a freshly
+// created game for the scenario and scene, so fixtures work with any scenario.
+type goldenGameState struct {
+	Narrator    *scenario.Narrator `json:"narrator,omitempty"`
+	PC          *actor.PCSpec      `json:"pc,omitempty"`
+	JustEntered bool               `json:"just_entered,omitempty"`
+	State       json.RawMessage    `json:"state"`
+}
+
+// TestBuild_Golden renders the full message array for every
+// scenario × scene × gamestate fixture and compares it to its golden file
+func TestBuild_Golden(t *testing.T) {
+	scenarios := loadGoldenFixtures[scenario.Scenario](t, "scenarios")
+	gamestates := loadGoldenFixtures[goldenGameState](t, "gamestates")
+
+	expected := make(map[string]bool)
+	for _, scenarioName := range slices.Sorted(maps.Keys(scenarios)) {
+		s := scenarios[scenarioName]
+		scenes := append([]string{""}, slices.Sorted(maps.Keys(s.Scenes))...)
+		for _, sceneName := range scenes {
+			for _, gsName := range slices.Sorted(maps.Keys(gamestates)) {
+				name := goldenCaseName(scenarioName, sceneName, gsName)
+				expected[name+".golden"] = true
+
+				t.Run(name, func(t *testing.T) {
+					gs := newGoldenGameState(t, s, sceneName, gamestates[gsName])
+					messages, err := New().
+						WithGameState(gs).
+						WithScenario(s).
+						WithUserMessage(goldenUserMessage, chat.ChatRoleUser).
+						Build()
+					if err != nil {
+						t.Fatalf("Build failed: %v", err)
+					}
+					compareGolden(t, filepath.Join(goldenDir, name+".golden"), renderGoldenMessages(messages))
+				})
+			}
+		}
+	}
+
+	// Golden files left behind by removed fixtures would never be checked again
+	files, err := filepath.Glob(filepath.Join(goldenDir, "*.golden"))
+	if err != nil {
+		t.Fatalf("Failed to list golden files: %v", err)
+	}
+	for _, f := range files {
+		if expected[filepath.Base(f)] {
+			continue
+		}
+		if *updateGolden {
+			if err := os.Remove(f); err != nil {
+				t.Errorf("Failed to remove stale golden file %s: %v", f, err)
+			}
+			continue
+		}
+		t.Errorf("Stale golden file %s has no matching fixture; rerun with -update to remove it", f)
+	}
+}
+
+// loadGoldenFixtures decodes every JSON file in testdata/golden/<kind>, keyed by file name without extension
+func loadGoldenFixtures[T any](t *testing.T, kind string) map[string]*T {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(goldenDir, kind, "*.json"))
+	if err != nil {
+		t.Fatalf("Failed to list %s fixtures: %v", kind, err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("No %s fixtures found in %s", kind, filepath.Join(goldenDir, kind))
+	}
+
+	fixtures := make(map[string]*T, len(files))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("Failed to read fixture %s: %v", f, err)
+		}
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			t.Fatalf("Failed to parse fixture %s: %v", f, err)
+		}
+		fixtures[strings.TrimSuffix(filepath.Base(f), ".json")] = &v
+	}
+	return fixtures
+}
+
+// newGoldenGameState creates a game the way the API does, loads the scene, and applies the fixture
+func newGoldenGameState(t *testing.T, s *scenario.Scenario, sceneName string, fixture *goldenGameState) *state.GameState {
+	t.Helper()
+	gs := state.NewGameState(s.FileName, fixture.Narrator, "golden-model")
+	maps.Copy(gs.NPCs, s.NPCs)
+	maps.Copy(gs.WorldLocations, s.Locations)
+	maps.Copy(gs.Vars, s.Vars)
+	gs.Location = s.OpeningLocation
+	gs.Inventory = slices.Clone(s.OpeningInventory)
+
+	if sceneName != "" {
+		if err := gs.LoadScene(s, sceneName); err != nil {
+			t.Fatalf("Failed to load scene %s: %v", sceneName, err)
+		}
+	}
+
+	if fixture.PC != nil {
+		pc, err := actor.NewPCFromSpec(fixture.PC)
+		if err != nil {
+			t.Fatalf("Failed to build PC: %v", err)
+		}
+		gs.PC = pc
+	}
+	if err := json.Unmarshal(fixture.State, gs); err != nil {
+		t.Fatalf("Failed to apply gamestate fixture: %v", err)
+	}
+	gs.JustEntered = fixture.JustEntered
+	return gs
+}
+
+// renderGoldenMessages formats messages with a header per message so diffs show which message changed
+func renderGoldenMessages(messages []chat.ChatMessage) string {
+	var sb strings.Builder
+	for i, m := range messages {
+		fmt.Fprintf(&sb, "=== message %d: %s ===\n%s\n", i, m.Role, m.Content)
+	}
+	return sb.String()
+}
+
+func goldenCaseName(scenarioName, sceneName, gsName string) string {
+	if sceneName == "" {
+		sceneName = "no_scene"
+	}
+	return scenarioName + "__" + sceneName + "__" + gsName
+}
+
+func compareGolden(t *testing.T, path string, got string) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("Failed to write golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file %s (run with -update to create it): %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("Prompt differs from %s (run with -update if the change is intended):\n%s", path, firstDifference(string(want), got))
+	}
+}
+
+// firstDifference describes the first line at which two renderings differ
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n  want: %q\n  got:  %q", i+1, w, g)
+		}
+	}
+	return "no line differs (trailing content only)"
+}
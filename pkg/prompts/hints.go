@@ -0,0 +1,103 @@
+package prompts
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// BuildHintMessages builds the messages asking the narrator for the player's next hint
+// in the current scene. The narrator is given the scene's story, the goals its
+// conditionals set that the player hasn't met, the hints already given, and the game
+// state, so each hint can be more direct than the last.
+func BuildHintMessages(gs *state.GameState, s *scenario.Scenario, budget int) ([]chat.ChatMessage, error) {
+	ps, err := ToBackgroundPromptState(gs).ForVersion(s.PromptStateVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to shape game state: %w", err)
+	}
+	stateJSON, err := json.Marshal(ps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal game state: %w", err)
+	}
+	given := gs.HintsGiven()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(HintPrompt, len(given)+1, budget))
+	scene, ok := s.Scenes[gs.SceneName]
+	if ok && scene.Story != "" {
+		sb.WriteString("\n\nScene: " + gs.Interpolate(scene.Story))
+	} else if s.Story != "" {
+		sb.WriteString("\n\nStory: " + gs.Interpolate(s.Story))
+	}
+	if goals := unmetGoals(scene, gs); len(goals) > 0 {
+		sb.WriteString("\n\nGoals the player hasn't reached:\n- " + strings.Join(goals, "\n- "))
+	}
+	if len(given) > 0 {
+		sb.WriteString("\n\nHints already given, oldest first:\n- " + strings.Join(given, "\n- "))
+	}
+	sb.WriteString("\n\nCurrent game state: " + string(stateJSON))
+
+	request := fmt.Sprintf("Give hint %d.", len(given)+1)
+	for i := len(gs.ChatHistory) - 1; i >= 0; i-- {
+		if gs.ChatHistory[i].Role == chat.ChatRoleAgent {
+			request = fmt.Sprintf("Latest narration:\n%s\n\n%s", gs.ChatHistory[i].Content, request)
+			break
+		}
+	}
+
+	return []chat.ChatMessage{
+		{Role: chat.ChatRoleSystem, Content: BuildSystemPrompt(gs.Narrator, gs.PC)},
+		{Role: chat.ChatRoleSystem, Content: sb.String()},
+		{Role: chat.ChatRoleUser, Content: request},
+	}, nil
+}
+
+// unmetGoals describes the scene's conditionals that move the story on, by changing
+// scene, ending the game, or starting a story event, and whose conditions aren't met.
+// Conditionals that fire once and already have are skipped.
+func unmetGoals(scene scenario.Scene, gs *state.GameState) []string {
+	var goals []string
+	for _, id := range slices.Sorted(maps.Keys(scene.Conditionals)) {
+		cond := scene.Conditionals[id]
+		if cond.Then.SceneChange == nil && cond.Then.GameEnded == nil && cond.Then.Prompt == nil {
+			continue
+		}
+		if _, fired := gs.FiredConditionals[id]; fired && cond.Fire != "" && cond.Fire != scenario.FireRepeatable {
+			continue
+		}
+		if conditionals.EvaluateWhen(cond.When, gs) {
+			continue
+		}
+		if needs := describeWhen(cond.When, gs); needs != "" {
+			goals = append(goals, id+": "+needs)
+		}
+	}
+	return goals
+}
+
+// describeWhen lists the parts of a when clause the game state doesn't yet meet.
+// Turn counters are left out, since the player can't act on them.
+func describeWhen(when conditionals.ConditionalWhen, gs *state.GameState) string {
+	var needs []string
+	for _, name := range slices.Sorted(maps.Keys(when.Vars)) {
+		if gs.Vars[name] != when.Vars[name] {
+			needs = append(needs, fmt.Sprintf("%s is %s", name, when.Vars[name]))
+		}
+	}
+	if when.Location != "" && when.Location != gs.Location {
+		needs = append(needs, "the player is at "+when.Location)
+	}
+	for _, id := range when.LoreKnown {
+		if !gs.KnowsLore(id) {
+			needs = append(needs, "the player has learned about "+id)
+		}
+	}
+	return strings.Join(needs, ", ")
+}
@@ -0,0 +1,77 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func TestBuildHintMessages(t *testing.T) {
+	prompt := "The ship sails."
+	ended := true
+	s := &scenario.Scenario{
+		Scenes: map[string]scenario.Scene{
+			"docks": {
+				Story: "Find a ship to leave port.",
+				Conditionals: map[string]scenario.Conditional{
+					"set_sail": {
+						When: conditionals.ConditionalWhen{Vars: map[string]string{"ship_hired": "true", "crew_paid": "true"}, Location: "pier"},
+						Then: conditionals.GameStateDelta{Prompt: &prompt},
+					},
+					"met_already": {
+						When: conditionals.ConditionalWhen{Vars: map[string]string{"crew_paid": "true"}},
+						Then: conditionals.GameStateDelta{GameEnded: &ended},
+					},
+					"fired_once": {
+						When: conditionals.ConditionalWhen{Vars: map[string]string{"storm": "true"}},
+						Then: conditionals.GameStateDelta{Prompt: &prompt},
+						Fire: scenario.FireOnce,
+					},
+					"bookkeeping": {
+						When: conditionals.ConditionalWhen{Vars: map[string]string{"gold_counted": "true"}},
+						Then: conditionals.GameStateDelta{SetVars: map[string]string{"rich": "true"}},
+					},
+				},
+			},
+		},
+	}
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.SceneName = "docks"
+	gs.Location = "tavern"
+	gs.Vars = map[string]string{"ship_hired": "false", "crew_paid": "true", "storm": "false"}
+	gs.FiredConditionals = map[string]state.ConditionalFiring{"fired_once": {Turn: 2, Scene: "docks", Count: 1}}
+	gs.ChatHistory = []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "The tavern is loud."}}
+	gs.RecordHint("Captains drink here.")
+
+	messages, err := BuildHintMessages(gs, s, 3)
+	if err != nil {
+		t.Fatalf("BuildHintMessages returned error: %v", err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+
+	content := messages[1].Content
+	for _, want := range []string{
+		"hint 2 of 3",
+		"Scene: Find a ship to leave port.",
+		"- set_sail: ship_hired is true, the player is at pier",
+		"- Captains drink here.",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected hint prompt to contain %q, got:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"met_already", "fired_once", "bookkeeping", "crew_paid is"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("Expected hint prompt not to contain %q, got:\n%s", unwanted, content)
+		}
+	}
+	if want := "Latest narration:\nThe tavern is loud.\n\nGive hint 2."; messages[2].Content != want {
+		t.Errorf("User message = %q, want %q", messages[2].Content, want)
+	}
+}
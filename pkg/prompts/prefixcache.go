@@ -0,0 +1,86 @@
+package prompts
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// DefaultPrefixCacheSize is the number of games whose next system prompt is kept
+const DefaultPrefixCacheSize = 1000
+
+// PrefixCache keeps the system prompt for each game's next turn, built ahead of time
+// once the previous turn's delta has been saved. The next turn then only appends the
+// history and the player's message. An entry is only used while the game and the
+// scenario are unchanged since it was built, going by their UpdatedAt times.
+type PrefixCache struct {
+	mu         sync.Mutex
+	entries    map[uuid.UUID]prefixEntry
+	maxEntries int
+}
+
+type prefixEntry struct {
+	gameUpdated     time.Time
+	scenarioUpdated time.Time
+	message         chat.ChatMessage
+}
+
+// NewPrefixCache creates a cache holding up to maxEntries games.
+// If maxEntries <= 0, DefaultPrefixCacheSize is used.
+func NewPrefixCache(maxEntries int) *PrefixCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultPrefixCacheSize
+	}
+	return &PrefixCache{
+		entries:    make(map[uuid.UUID]prefixEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+// Prewarm builds and stores the system prompt for the game's next turn. gs should be
+// the game as it was saved, so the prompt matches what a fresh load would build.
+// Games that have never been saved are skipped.
+func (c *PrefixCache) Prewarm(gs *state.GameState, s *scenario.Scenario) error {
+	if c == nil || gs == nil || s == nil || gs.UpdatedAt.IsZero() {
+		return nil
+	}
+	msg, err := buildSystemMessage(gs, s)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[gs.ID]; !ok && len(c.entries) >= c.maxEntries {
+		// Evict an arbitrary game; a miss only costs a rebuild
+		for id := range c.entries {
+			delete(c.entries, id)
+			break
+		}
+	}
+	c.entries[gs.ID] = prefixEntry{
+		gameUpdated:     gs.UpdatedAt,
+		scenarioUpdated: s.UpdatedAt,
+		message:         msg,
+	}
+	return nil
+}
+
+// Get returns the prewarmed system prompt for the game, if the game and scenario
+// haven't changed since it was built. Safe to call on a nil cache.
+func (c *PrefixCache) Get(gs *state.GameState, s *scenario.Scenario) (chat.ChatMessage, bool) {
+	if c == nil || gs == nil || s == nil || gs.UpdatedAt.IsZero() {
+		return chat.ChatMessage{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[gs.ID]
+	if !ok || !entry.gameUpdated.Equal(gs.UpdatedAt) || !entry.scenarioUpdated.Equal(s.UpdatedAt) {
+		return chat.ChatMessage{}, false
+	}
+	return entry.message, true
+}
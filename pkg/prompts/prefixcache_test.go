@@ -0,0 +1,95 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+func TestPrefixCache(t *testing.T) {
+	saved := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := &scenario.Scenario{Name: "Test", Story: "A test story", Rating: scenario.RatingPG}
+
+	tests := []struct {
+		name    string
+		change  func(gs *state.GameState, s *scenario.Scenario) *scenario.Scenario
+		wantHit bool
+	}{
+		{"unchanged game", func(gs *state.GameState, s *scenario.Scenario) *scenario.Scenario { return s }, true},
+		{"game saved again", func(gs *state.GameState, s *scenario.Scenario) *scenario.Scenario {
+			gs.UpdatedAt = saved.Add(time.Second)
+			return s
+		}, false},
+		{"scenario edited", func(gs *state.GameState, s *scenario.Scenario) *scenario.Scenario {
+			edited := *s
+			edited.UpdatedAt = saved
+			return &edited
+		}, false},
+		{"never saved", func(gs *state.GameState, s *scenario.Scenario) *scenario.Scenario {
+			gs.UpdatedAt = time.Time{}
+			return s
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := state.NewGameState("test.json", nil, "test-model")
+			gs.UpdatedAt = saved
+			cache := NewPrefixCache(0)
+			if err := cache.Prewarm(gs, s); err != nil {
+				t.Fatalf("Prewarm() error = %v", err)
+			}
+
+			next := tt.change(gs, s)
+			if _, ok := cache.Get(gs, next); ok != tt.wantHit {
+				t.Errorf("Get() hit = %v, want %v", ok, tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestPrefixCache_MatchesBuild(t *testing.T) {
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.UpdatedAt = time.Now()
+	s := &scenario.Scenario{Name: "Test", Story: "A test story", Rating: scenario.RatingPG}
+
+	cache := NewPrefixCache(0)
+	if err := cache.Prewarm(gs, s); err != nil {
+		t.Fatalf("Prewarm() error = %v", err)
+	}
+	cached, err := New().WithGameState(gs).WithScenario(s).WithUserMessage("Hello", chat.ChatRoleUser).WithPrefixCache(cache).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	fresh, err := New().WithGameState(gs).WithScenario(s).WithUserMessage("Hello", chat.ChatRoleUser).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if cached[0] != fresh[0] {
+		t.Errorf("prewarmed system prompt differs from a fresh build:\n%+v\n%+v", cached[0], fresh[0])
+	}
+	prefix := fresh[0].Content[:fresh[0].CachePrefix]
+	if !strings.HasSuffix(prefix, "("+ContentRatingPG+")") {
+		t.Errorf("cache prefix should end after the rating, got %q", prefix[max(0, len(prefix)-60):])
+	}
+}
+
+func TestPrefixCache_Eviction(t *testing.T) {
+	s := &scenario.Scenario{Name: "Test", Rating: scenario.RatingPG}
+	cache := NewPrefixCache(2)
+	for range 3 {
+		gs := state.NewGameState("test.json", nil, "test-model")
+		gs.UpdatedAt = time.Now()
+		if err := cache.Prewarm(gs, s); err != nil {
+			t.Fatalf("Prewarm() error = %v", err)
+		}
+	}
+	if len(cache.entries) != 2 {
+		t.Errorf("Expected 2 entries after eviction, got %d", len(cache.entries))
+	}
+}
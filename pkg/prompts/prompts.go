@@ -58,6 +58,87 @@ Monsters are listed in the WORLD STATE only when present at the player's locatio
 
 const GameEndSystemPrompt = `This user's session has ended. Regardless of the user's input, the game will not continue. Respond in a way that will wrap up the game in a narrative manner. End with a fancy "*.*.*.*.*.*. THE END .*.*.*.*.*.*" line, followed by instructions to use Ctrl+N to start a new game or Ctrl+C to exit.`
 
+// PersonalizedIntroPrompt asks the narrator to write an opening tailored to the player character.
+// The %s is replaced with the scenario's personalized_intro instructions.
+const PersonalizedIntroPrompt = `Write a brief personalized introduction for the player character described in the system prompt. Speak directly to the player in second person, ground the introduction in the current WORLD STATE, and end in a moment that invites the player's first action. Do not repeat the opening text already shown. Follow these additional instructions from the scenario author: %s`
+
+// ChoicesPrompt asks the backend model for suggested next actions in choices mode
+const ChoicesPrompt = `You suggest next actions for the player of a text adventure. Read the current game state and the latest narration, then output ONLY a JSON object of the form {"choices": ["...", "..."]}. No prose.
+
+RULES
+- Suggest between 2 and 4 distinct actions the player character could plausibly take next.
+- Write each action in first person as the player would type it, in 12 words or fewer. Example: "I open the iron gate."
+- Only reference locations, exits, items, and NPCs present in the game state or narration.
+- Do not suggest actions that end the game or break character.`
+
+// ConsistencyCheckPrompt asks the backend model where a narration contradicts the game state
+const ConsistencyCheckPrompt = `You check the narration of a text adventure against its game state, which is authoritative. Read the game state before the turn, the player's message, and the narration, then output ONLY a JSON object of the form {"issues": [{"kind": "...", "detail": "..."}]}. No prose. Output {"issues": []} when the narration is consistent.
+
+KINDS
+- item_not_held: the player character uses, shows, or gives an item that is not in their inventory. Items picked up in this narration are fine.
+- npc_not_present: an NPC who is not at the player's location speaks, acts, or is seen. NPCs who arrive in this narration are fine.
+- blocked_exit: the player character goes through an exit that is blocked or not listed for their location.
+
+RULES
+- Report only clear contradictions of the game state, not matters of taste or style.
+- Write each detail as one short sentence naming the item, NPC, or exit.`
+
+// NarrationRetryPrompt asks the narrator to rewrite a turn whose narration contradicted
+// the game state. The %s is replaced with the list of inconsistencies found.
+const NarrationRetryPrompt = `Your previous narration of this turn contradicted the game state, and has been discarded. Narrate the turn again, following the WORLD STATE. Avoid these mistakes:
+%s`
+
+// ContinuePrompt asks the narrator to carry on from the end of its last narration, which
+// may have been cut off, without a new player action
+const ContinuePrompt = `(Continue your last narration from exactly where it stopped, even mid-sentence. Do not repeat anything already written, do not act or speak for the player, and do not move the story past the moment the player should act.)`
+
+// NarrationMetaPrompt asks the narrator to end its reply with a structured trailer in
+// <turn_meta> tags, which is removed from the narration and stored with the turn
+const NarrationMetaPrompt = `After your narration, on a new line, add a <turn_meta> block with a JSON object describing the scene as it stands at the end of your narration, then stop:
+<turn_meta>{"mood": "one or two words", "npcs_present": ["names of characters in the scene"], "choices": ["two to four short actions the player might take next, in the first person"]}</turn_meta>
+The player never sees this block, so never refer to it in the narration.`
+
+// MaxSentencesPrompt asks the narrator to keep a reply short. The %d is the sentence limit.
+const MaxSentencesPrompt = `Keep your narration to %d sentences or fewer. Anything longer will be cut off.`
+
+// SkillCheckPrompt tells the narrator how a skill check the engine rolled turned out.
+// The %[1]s is the ability or skill, %[2]s what it decides, and %[3]s the outcome.
+const SkillCheckPrompt = `The game rolled a %[1]s check for the player's last attempt (%[2]s), and it was a %[3]s. Narrate this turn true to that outcome: a success goes the player's way, and a failure does not. Do not mention dice, rolls, or numbers.`
+
+// CombatPrompt puts the narrator in combat mode. The fight's summary follows it.
+const CombatPrompt = `A fight is under way, and the game decides every attack. Narrate the last round below as it happened, in initiative order, vividly and without dice or numbers. Do not add hits, misses, wounds, or defeats beyond these, and keep standing foes in the fight. If the fight is over, narrate its end; otherwise end your narration at the moment the player must choose their next move.`
+
+// EmbellishCommandPrompt asks the narrator to reword the answer to a server command
+// such as /inventory without changing any of its facts
+const EmbellishCommandPrompt = `The player has asked about their character outside of the story, and the game has already answered with the facts below. Retell these facts to the player in your narrator's voice, in one or two sentences. Do not add, remove, or change any item, location, or detail, and do not advance the story.`
+
+// HintPrompt asks the narrator for a hint toward the player's next goal. The %[1]d is
+// replaced with the hint's number and %[2]d with the scene's hint budget.
+const HintPrompt = `The player is stuck and has asked for hint %[1]d of %[2]d for this part of the story. Using the scene, goals, and game state below, write one hint of one or two sentences, in your narrator's voice, that points toward a goal the player hasn't reached. Each hint should be more direct than the ones already given: the first is a gentle nudge, and the last may plainly say what to do next. Do not repeat an earlier hint, look more than one step ahead, or advance the story.`
+
+// LoreScanMessages is how many recent messages are searched for lore keywords, besides
+// the player's new message
+const LoreScanMessages = 4
+
+// LorePrompt introduces the lore entries that bear on the current turn
+const LorePrompt = `Background lore for this moment. Draw on it to keep the world consistent, and reveal it only as far as the story calls for.`
+
+// ConversationPrompt focuses the narrator on a side conversation with one NPC.
+// Each %[1]s is replaced with the NPC's name.
+const ConversationPrompt = `The player has stepped aside to talk with %[1]s. Until the conversation ends, narrate only this conversation: answer as %[1]s, in their own voice, with brief narration of their manner. Keep the rest of the world in the background and do not move the player or start new events. Stay true to the details below and to what %[1]s remembers of earlier conversations with the player.`
+
+// TranslateInputPrompt asks for an English translation of a player's message so the
+// reducer, whose prompt and game state keys are English, can follow it
+const TranslateInputPrompt = `Translate the player's message into English for a text adventure engine. Keep the player's intent, and keep any names of people, places, and items exactly as written unless they have a common English form. Output ONLY the translation, with no notes or quotes.`
+
+// InputLanguageNote tells the reducer which language the player wrote in when their
+// message could not be translated. The %s is replaced with the language name.
+const InputLanguageNote = `The player wrote in %s. Interpret their message in that language, but write every value in the JSON output in English, matching the names used in the game state.`
+
+// ReducerPromptVersion identifies ReducerPrompt on the deltas it produces. Bump it when
+// the prompt changes, so deltas from before and after can be told apart.
+const ReducerPromptVersion = "builtin-3"
+
 // ReducerPrompt provides instructions for translating narrative to game state delta
 const ReducerPrompt = `You are a backend reducer. Read the latest narrative and current game state, then output ONLY a JSON object matching the provided schema. No prose.
 
@@ -70,6 +151,8 @@ OUTPUT SCHEMA (strict)
 - npc_events: array of { npc_id, set_location } (always required, may be empty)
 - set_vars: object (always required, may be empty)
 - game_ended: boolean (always required) 
+- check: object { attribute, dc, reason } or null when no check
+- attack: object { target } or null when the player doesn't attack
 
 GENERAL RULES
 - Do not invent scenes, locations, items, NPCs, or variables beyond those in the scenario.
@@ -98,6 +181,7 @@ NPC EVENTS
   • When an NPC is explicitly told to go somewhere and complies
 - Format: {"npc_id": "gibbs", "set_location": "sleepy_mermaid"}
 - Use canonical NPC IDs and location IDs from the scenario/state
+- NPCs in npc_roster are also at the player's location; their IDs are valid npc_id values
 - DO NOT track movements when:
   • NPCs are merely mentioned or thought about
   • Describing past events or speculation
@@ -117,6 +201,19 @@ VARIABLES
 - Set variables based on events in the player's most recent prompt and the narrator's response.
 - The narrator's response may override the player's prompt.
 
+SKILL CHECKS
+- Request a check only when the player attempts something risky whose outcome the narrative leaves uncertain.
+- attribute: an ability (strength, dexterity, constitution, intelligence, wisdom, charisma) or skill (e.g. stealth, athletics, persuasion).
+- dc: 10 easy, 15 moderate, 20 hard.
+- reason: a few words on what the roll decides.
+- The engine rolls the check and the next narration resolves it. Otherwise set check=null.
+- Example: "You start to creep past the sleeping guard..." → check:{attribute:"stealth", dc:15, reason:"sneak past the guard"}
+
+COMBAT
+- Only while the game state has a combat that is not over.
+- When the player attacks a foe this turn, set attack:{target} to that foe's combatant id.
+- Otherwise, including outside a fight, set attack=null. Never start or end fights.
+
 GAME END
 - true if narrative describes a definitive ending OR a rule ends the game this turn.
 - false otherwise.
@@ -242,8 +339,13 @@ func GetStatePrompt(gs *state.GameState, s *scenario.Scenario) (chat.ChatMessage
 		story += "\n\n" + scene.Story
 	}
 
+	ps, err := ToPromptState(gs).ForVersion(s.PromptStateVersion)
+	if err != nil {
+		return chat.ChatMessage{}, fmt.Errorf("error shaping prompt state: %w", err)
+	}
+
 	return chat.ChatMessage{
 		Role:    chat.ChatRoleSystem,
-		Content: fmt.Sprintf(StatePromptTemplate, story, ToPromptState(gs).ToString()),
+		Content: fmt.Sprintf(StatePromptTemplate, gs.Interpolate(story), ps.ToString()),
 	}, nil
 }
@@ -54,7 +54,7 @@ func TestGetStatePrompt(t *testing.T) {
 			check: check{
 				mustContain: []string{
 					"The user is roleplaying this scenario: A test adventure",
-					"<world_state>",
+					"<world_state version=\"3\">",
 					"<just_entered>false</just_entered>",
 					"<current_location>",
 					"Tortuga",
@@ -112,7 +112,7 @@ func TestGetStatePrompt(t *testing.T) {
 				mustContain: []string{
 					"The user is roleplaying this scenario: Overall pirate story",
 					"Find the shipwright",
-					"<world_state>",
+					"<world_state version=\"3\">",
 					"<current_location>",
 					"Tortuga",
 					"A bustling pirate port",
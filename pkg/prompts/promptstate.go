@@ -2,19 +2,32 @@ package prompts
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/combat"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
 	"github.com/jwebster45206/story-engine/pkg/state"
 )
 
+// When at least NPCSummaryThreshold NPCs share the player's location, only the
+// NPCDetailLimit most relevant of them are given in full; the rest are listed by name
+// in NPCRoster, so a crowded room doesn't balloon the prompt.
+const (
+	NPCSummaryThreshold = 10
+	NPCDetailLimit      = 5
+)
+
 // PromptState is a compact, location-scoped view of the game state
 // for LLM context. For background processing, Vars are also populated.
 type PromptState struct {
+	Version          int                          `json:"schema_version"`               // PromptStateVersion this state's shape follows
 	SceneName        string                       `json:"scene_name,omitempty"`         // Current scene name
 	NPCs             map[string]actor.NPC         `json:"npcs,omitempty"`               // Map of key NPCs
+	NPCRoster        map[string]string            `json:"npc_roster,omitempty"`         // Other NPCs at the player's location, ID → name
 	Monsters         map[string]actor.Monster     `json:"monsters,omitempty"`           // Monsters at current location
 	WorldLocations   map[string]scenario.Location `json:"locations,omitempty"`          // Current locations in the game world
 	Location         string                       `json:"user_location,omitempty"`      // User's current location
@@ -24,16 +37,12 @@ type PromptState struct {
 	TurnCounter      int                          `json:"turn_counter,omitempty"`       // Total number of successful chat interactions
 	SceneTurnCounter int                          `json:"scene_turn_counter,omitempty"` // Number of successful chat interactions in
 	JustEntered      bool                         `json:"just_entered,omitempty"`       // true on the first turn after a location change
+	Ambient          string                       `json:"ambient,omitempty"`            // This turn's ambient detail for the current location
+	Combat           *combat.Combat               `json:"combat,omitempty"`             // Fight in progress; only populated for background processing
 }
 
 func ToPromptState(gs *state.GameState) *PromptState {
-	// Filter NPCs: only include those in the same location as user OR marked as important
-	filteredNPCs := make(map[string]actor.NPC)
-	for name, npc := range gs.NPCs {
-		if npc.Location == gs.Location || npc.IsImportant {
-			filteredNPCs[name] = npc
-		}
-	}
+	filteredNPCs, roster := filterNPCs(gs)
 
 	// Filter Monsters: only include those in the current location
 	filteredMonsters := make(map[string]actor.Monster)
@@ -45,15 +54,75 @@ func ToPromptState(gs *state.GameState) *PromptState {
 		}
 	}
 
-	return &PromptState{
+	ps := &PromptState{
+		Version:        PromptStateVersion,
 		NPCs:           filteredNPCs,
+		NPCRoster:      roster,
 		Monsters:       filteredMonsters,
 		WorldLocations: filterLocations(gs.WorldLocations, gs.Location),
 		Location:       gs.Location,
 		Inventory:      gs.Inventory,
 		JustEntered:    gs.JustEntered,
+		Ambient:        gs.WorldLocations[gs.Location].AmbientDetail(gs.TurnCounter),
 		// Vars and counters intentionally excluded for user-facing prompts
 	}
+	ps.nameItems(gs)
+	return ps
+}
+
+// nameItems swaps catalogued item IDs for their display names, which is how the
+// narrator should write them. Lists are replaced, not edited, as they're shared with
+// the game state.
+func (ps *PromptState) nameItems(gs *state.GameState) {
+	if len(gs.ItemNames) == 0 {
+		return
+	}
+	ps.Inventory = gs.DisplayItems(ps.Inventory)
+	for id, npc := range ps.NPCs {
+		npc.Items = gs.DisplayItems(npc.Items)
+		ps.NPCs[id] = npc
+	}
+	for id, monster := range ps.Monsters {
+		monster.Items = gs.DisplayItems(monster.Items)
+		ps.Monsters[id] = monster
+	}
+	for key, loc := range ps.WorldLocations {
+		loc.Items = gs.DisplayItems(loc.Items)
+		ps.WorldLocations[key] = loc
+	}
+}
+
+// filterNPCs returns the NPCs to include in prompts: those at the user's location or
+// marked as important. When NPCSummaryThreshold or more are at the user's location, only
+// the NPCDetailLimit most relevant of those are included in full, and the rest are
+// returned as a roster of names by ID.
+func filterNPCs(gs *state.GameState) (map[string]actor.NPC, map[string]string) {
+	filtered := make(map[string]actor.NPC)
+	var present []string
+	for id, npc := range gs.NPCs {
+		if npc.Location == gs.Location {
+			present = append(present, id)
+		} else if npc.IsImportant {
+			filtered[id] = npc
+		}
+	}
+
+	ranked := present
+	if len(present) >= NPCSummaryThreshold {
+		ranked = gs.RankNPCs(present)
+	}
+	var roster map[string]string
+	for i, id := range ranked {
+		if len(present) < NPCSummaryThreshold || i < NPCDetailLimit {
+			filtered[id] = gs.NPCs[id]
+			continue
+		}
+		if roster == nil {
+			roster = make(map[string]string)
+		}
+		roster[id] = gs.NPCs[id].Name
+	}
+	return filtered, roster
 }
 
 // filterLocations returns locations that should be included in prompts:
@@ -83,13 +152,7 @@ func filterLocations(worldLocations map[string]scenario.Location, currentLocatio
 }
 
 func ToBackgroundPromptState(gs *state.GameState) *PromptState {
-	// Filter NPCs: only include those in the same location as user OR marked as important
-	filteredNPCs := make(map[string]actor.NPC)
-	for name, npc := range gs.NPCs {
-		if npc.Location == gs.Location || npc.IsImportant {
-			filteredNPCs[name] = npc
-		}
-	}
+	filteredNPCs, roster := filterNPCs(gs)
 
 	// Filter Monsters: only include those in the current location
 	filteredMonsters := make(map[string]actor.Monster)
@@ -101,9 +164,11 @@ func ToBackgroundPromptState(gs *state.GameState) *PromptState {
 		}
 	}
 
-	return &PromptState{
+	ps := &PromptState{
+		Version:          PromptStateVersion,
 		SceneName:        gs.SceneName,
 		NPCs:             filteredNPCs,
+		NPCRoster:        roster,
 		Monsters:         filteredMonsters,
 		WorldLocations:   filterLocations(gs.WorldLocations, gs.Location),
 		Location:         gs.Location,
@@ -113,8 +178,58 @@ func ToBackgroundPromptState(gs *state.GameState) *PromptState {
 		TurnCounter:      gs.TurnCounter,
 		SceneTurnCounter: gs.SceneTurnCounter,
 		JustEntered:      gs.JustEntered,
+		Combat:           gs.Combat,
 		// ContingencyPrompts are handled as separate system messages, not JSON data
 	}
+	ps.nameItems(gs)
+	return ps
+}
+
+// Compact returns a copy of the state without the prose and stats the reducer doesn't
+// need to track changes: descriptions, previews, contingency prompts, and NPC and
+// monster stats other than HP. Names, exits, items, positions, HP, and vars are kept. Monsters are only
+// listed once, at the top level.
+func (ps *PromptState) Compact() *PromptState {
+	compact := *ps
+
+	compact.NPCs = make(map[string]actor.NPC, len(ps.NPCs))
+	for id, npc := range ps.NPCs {
+		compact.NPCs[id] = actor.NPC{
+			Name:        npc.Name,
+			Type:        npc.Type,
+			Disposition: npc.Disposition,
+			IsImportant: npc.IsImportant,
+			Location:    npc.Location,
+			Following:   npc.Following,
+			Items:       npc.Items,
+			HP:          npc.HP,
+			MaxHP:       npc.MaxHP,
+		}
+	}
+
+	compact.Monsters = make(map[string]actor.Monster, len(ps.Monsters))
+	for id, monster := range ps.Monsters {
+		compact.Monsters[id] = actor.Monster{
+			ID:       monster.ID,
+			Name:     monster.Name,
+			Location: monster.Location,
+			HP:       monster.HP,
+			MaxHP:    monster.MaxHP,
+			Items:    monster.Items,
+		}
+	}
+
+	compact.WorldLocations = make(map[string]scenario.Location, len(ps.WorldLocations))
+	for key, loc := range ps.WorldLocations {
+		compact.WorldLocations[key] = scenario.Location{
+			Name:         loc.Name,
+			Exits:        loc.Exits,
+			BlockedExits: loc.BlockedExits,
+			Items:        loc.Items,
+			IsImportant:  loc.IsImportant,
+		}
+	}
+	return &compact
 }
 
 // ApplyPromptStateToGameState copies fields from a PromptState to a GameState.
@@ -139,15 +254,16 @@ func ApplyPromptStateToGameState(ps *PromptState, gs *state.GameState) {
 //
 // Example output:
 //
-//	<world_state>
+//	<world_state version="2">
 //	<just_entered>true</just_entered>
 //
 //	<current_location>
 //	Castle Hallway
 //	A long stone corridor.
+//	Ambient detail this turn: A draft makes the torches gutter.
 //
 //	Items here: key, map
-//	NPCs here: Guard
+//	NPCs here: Guard (AC: 16, HP: 11/11), Innkeeper
 //	Monsters here:
 //	- Giant Rat (AC: 12, HP: 7/7): A massive rat the size of a dog.
 //
@@ -176,7 +292,11 @@ func ApplyPromptStateToGameState(ps *PromptState, gs *state.GameState) {
 func (ps *PromptState) ToString() string {
 	var sb strings.Builder
 
-	sb.WriteString("<world_state>\n")
+	if ps.Version > 0 {
+		fmt.Fprintf(&sb, "<world_state version=\"%d\">\n", ps.Version)
+	} else {
+		sb.WriteString("<world_state>\n")
+	}
 	fmt.Fprintf(&sb, "<just_entered>%t</just_entered>\n\n", ps.JustEntered)
 
 	currentLoc, hasCurrent := ps.WorldLocations[ps.Location]
@@ -191,7 +311,7 @@ func (ps *PromptState) ToString() string {
 }
 
 // writeCurrentLocation renders the <current_location> block with name,
-// description, items here, NPCs here, monsters here, and exits.
+// description, this turn's ambient detail, items here, NPCs here, monsters here, and exits.
 func (ps *PromptState) writeCurrentLocation(sb *strings.Builder, currentLoc scenario.Location, hasCurrent bool) {
 	sb.WriteString("<current_location>\n")
 
@@ -207,6 +327,9 @@ func (ps *PromptState) writeCurrentLocation(sb *strings.Builder, currentLoc scen
 		sb.WriteString(currentLoc.Description)
 		sb.WriteString("\n")
 	}
+	if ps.Ambient != "" {
+		fmt.Fprintf(sb, "Ambient detail this turn: %s\n", ps.Ambient)
+	}
 
 	if len(currentLoc.Items) > 0 {
 		fmt.Fprintf(sb, "\nItems here: %s\n", strings.Join(currentLoc.Items, ", "))
@@ -214,7 +337,12 @@ func (ps *PromptState) writeCurrentLocation(sb *strings.Builder, currentLoc scen
 
 	presentNames := make([]string, 0)
 	for _, npc := range ps.NPCs {
-		if npc.Location == ps.Location {
+		if npc.Location != ps.Location {
+			continue
+		}
+		if npc.HasActor() {
+			presentNames = append(presentNames, fmt.Sprintf("%s (AC: %d, HP: %d/%d)", npc.Name, npc.AC, npc.HP, npc.MaxHP))
+		} else {
 			presentNames = append(presentNames, npc.Name)
 		}
 	}
@@ -222,6 +350,10 @@ func (ps *PromptState) writeCurrentLocation(sb *strings.Builder, currentLoc scen
 	if len(presentNames) > 0 {
 		fmt.Fprintf(sb, "NPCs here: %s\n", strings.Join(presentNames, ", "))
 	}
+	if len(ps.NPCRoster) > 0 {
+		rosterNames := slices.Sorted(maps.Values(ps.NPCRoster))
+		fmt.Fprintf(sb, "Also here, in the background: %s\n", strings.Join(rosterNames, ", "))
+	}
 
 	if len(ps.Monsters) > 0 {
 		monsterIDs := make([]string, 0, len(ps.Monsters))
@@ -1,11 +1,15 @@
 package prompts
 
 import (
+	"encoding/json"
+	"maps"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/jwebster45206/story-engine/pkg/actor"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
 )
 
 // requireContains is a small helper that fails the test with the full
@@ -462,3 +466,115 @@ func TestPromptState_ToString_RedirectTemplateMultiExit(t *testing.T) {
 	// Movement options use parenthesized form, sorted alphabetically by direction.
 	requireContains(t, result, "Movement: the player may only choose one of: east (East Room), north (North Room), south (South Room).")
 }
+
+func TestPromptState_Compact(t *testing.T) {
+	ps := &PromptState{
+		Location:  "tavern",
+		Inventory: []string{"lantern"},
+		Vars:      map[string]string{"paid": "false"},
+		NPCs: map[string]actor.NPC{
+			"gibbs": {Name: "Gibbs", Disposition: "friendly", Description: "A grizzled first mate with a long story.", Location: "tavern", Items: []string{"map"}, HP: 12, Attributes: map[string]int{"strength": 14}},
+		},
+		Monsters: map[string]actor.Monster{
+			"rat_1": {ID: "rat_1", Name: "Rat", Description: "A rat the size of a dog.", HP: 3, MaxHP: 3, CombatMods: map[string]int{"bite": 2}},
+		},
+		WorldLocations: map[string]scenario.Location{
+			"tavern": {
+				Name:         "The Rusty Anchor",
+				Description:  "A dimly lit tavern filled with the smell of ale and sea salt.",
+				Preview:      "A noisy tavern.",
+				Exits:        map[string]string{"north": "street"},
+				BlockedExits: map[string]string{"south": "The cellar door is locked."},
+				Items:        []string{"mug"},
+				Monsters:     map[string]*actor.Monster{"rat_1": {ID: "rat_1", Name: "Rat"}},
+			},
+		},
+	}
+
+	compact := ps.Compact()
+
+	full, _ := json.Marshal(ps)
+	small, _ := json.Marshal(compact)
+	if len(small) >= len(full) {
+		t.Errorf("compact state is %d bytes, full state %d", len(small), len(full))
+	}
+	got := string(small)
+	for _, want := range []string{`"user_location":"tavern"`, `"lantern"`, `"paid":"false"`, `"map"`, `"mug"`, `"north":"street"`, "cellar door is locked", `"hp":3`} {
+		requireContains(t, got, want)
+	}
+	for _, unwanted := range []string{"grizzled", "dimly lit", "noisy tavern", "size of a dog", "strength", "bite"} {
+		requireNotContains(t, got, unwanted)
+	}
+	if compact.WorldLocations["tavern"].Monsters != nil {
+		t.Error("monsters should only be listed at the top level")
+	}
+	if ps.WorldLocations["tavern"].Description == "" || ps.NPCs["gibbs"].Description == "" {
+		t.Error("Compact must not modify the original state")
+	}
+}
+
+func TestToPromptState_SummarizesCrowdedLocation(t *testing.T) {
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.Location = "tavern"
+	gs.TurnCounter = 12
+	gs.WorldLocations = map[string]scenario.Location{"tavern": {Name: "The Rusty Anchor"}}
+	gs.NPCs = map[string]actor.NPC{
+		"gibbs":    {Name: "Gibbs", Location: "tavern", Following: "pc"},
+		"calypso":  {Name: "Calypso", Location: "ship", IsImportant: true},
+		"barkeep":  {Name: "Barkeep", Location: "tavern", Items: []string{"rum"}},
+		"stranger": {Name: "Hooded Stranger", Location: "tavern", IsImportant: true},
+	}
+	for _, id := range []string{"drinker_1", "drinker_2", "drinker_3", "drinker_4", "drinker_5", "drinker_6", "drinker_7"} {
+		gs.NPCs[id] = actor.NPC{Name: "Drinker " + id[len(id)-1:], Location: "tavern", Description: "Nurses a mug of grog."}
+	}
+	gs.NPCInteractions = map[string]int{"drinker_3": 11}
+
+	for name, ps := range map[string]*PromptState{"narration": ToPromptState(gs), "background": ToBackgroundPromptState(gs)} {
+		t.Run(name, func(t *testing.T) {
+			for _, id := range []string{"gibbs", "calypso", "barkeep", "stranger", "drinker_3", "drinker_1"} {
+				if _, ok := ps.NPCs[id]; !ok {
+					t.Errorf("Expected %s in full detail, got %v", id, slices.Sorted(maps.Keys(ps.NPCs)))
+				}
+			}
+			wantRoster := map[string]string{"drinker_2": "Drinker 2", "drinker_4": "Drinker 4", "drinker_5": "Drinker 5", "drinker_6": "Drinker 6", "drinker_7": "Drinker 7"}
+			if !maps.Equal(ps.NPCRoster, wantRoster) {
+				t.Errorf("Expected roster %v, got %v", wantRoster, ps.NPCRoster)
+			}
+
+			result := ps.ToString()
+			requireContains(t, result, "NPCs here: Barkeep, Drinker 1, Drinker 3, Gibbs, Hooded Stranger")
+			requireContains(t, result, "Also here, in the background: Drinker 2, Drinker 4, Drinker 5, Drinker 6, Drinker 7")
+		})
+	}
+
+	// Below the threshold everyone present is given in full
+	for _, id := range []string{"drinker_4", "drinker_5", "drinker_6", "drinker_7"} {
+		delete(gs.NPCs, id)
+	}
+	if ps := ToPromptState(gs); len(ps.NPCRoster) != 0 || len(ps.NPCs) != 7 {
+		t.Errorf("Expected all 7 NPCs in full with no roster, got %d and roster %v", len(ps.NPCs), ps.NPCRoster)
+	}
+}
+
+func TestToPromptState_NamesCataloguedItems(t *testing.T) {
+	gs := state.NewGameState("test.json", nil, "test-model")
+	gs.Location = "tavern"
+	gs.Inventory = []string{"iron_key", "rope"}
+	gs.ItemNames = map[string]string{"iron_key": "Iron Key", "rum_bottle": "Bottle of Rum"}
+	gs.WorldLocations = map[string]scenario.Location{"tavern": {Name: "The Rusty Anchor", Items: []string{"rum_bottle"}}}
+	gs.NPCs = map[string]actor.NPC{"barkeep": {Name: "Barkeep", Location: "tavern", Items: []string{"rum_bottle"}}}
+
+	for name, ps := range map[string]*PromptState{"narration": ToPromptState(gs), "background": ToBackgroundPromptState(gs)} {
+		t.Run(name, func(t *testing.T) {
+			if want := []string{"Iron Key", "rope"}; !slices.Equal(ps.Inventory, want) {
+				t.Errorf("Expected inventory %v, got %v", want, ps.Inventory)
+			}
+			if want := []string{"Bottle of Rum"}; !slices.Equal(ps.WorldLocations["tavern"].Items, want) || !slices.Equal(ps.NPCs["barkeep"].Items, want) {
+				t.Errorf("Expected %v, got %v and %v", want, ps.WorldLocations["tavern"].Items, ps.NPCs["barkeep"].Items)
+			}
+		})
+	}
+	if gs.Inventory[0] != "iron_key" || gs.WorldLocations["tavern"].Items[0] != "rum_bottle" || gs.NPCs["barkeep"].Items[0] != "rum_bottle" {
+		t.Error("Naming items for the prompt must not modify the game state")
+	}
+}
@@ -0,0 +1,99 @@
+package prompts
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+)
+
+// PromptStateVersion is the version of the PromptState shape: its JSON fields and the
+// <world_state> block rendered from it. Bump it whenever either changes, and add a shim
+// to promptStateShims that turns the new shape back into the previous one, so prompts
+// tuned against an older shape keep seeing it.
+//
+// Versions:
+//   - 1: the original shape
+//   - 2: adds npc_roster, for locations crowded with NPCs, and ambient, the current
+//     location's ambient detail for the turn
+//   - 3: adds NPC stats, and the AC and HP of NPCs here that have hit points
+const PromptStateVersion = 3
+
+// promptStateShims turn a PromptState of version v into version v-1, by v
+var promptStateShims = map[int]func(ps *PromptState){
+	2: func(ps *PromptState) {
+		// Version 1 had no roster; NPCs summarized into it are listed by name instead
+		if len(ps.NPCRoster) > 0 {
+			npcs := make(map[string]actor.NPC, len(ps.NPCs)+len(ps.NPCRoster))
+			for id, npc := range ps.NPCs {
+				npcs[id] = npc
+			}
+			for id, name := range ps.NPCRoster {
+				npcs[id] = actor.NPC{Name: name, Location: ps.Location}
+			}
+			ps.NPCs = npcs
+			ps.NPCRoster = nil
+		}
+		ps.Ambient = ""
+	},
+	3: func(ps *PromptState) {
+		// Version 2 showed no NPC health; NPCs here were listed by name only
+		npcs := make(map[string]actor.NPC, len(ps.NPCs))
+		for id, npc := range ps.NPCs {
+			npc.Stats, npc.AC, npc.HP, npc.MaxHP = nil, 0, 0, 0
+			npcs[id] = npc
+		}
+		ps.NPCs = npcs
+	},
+}
+
+// ValidatePromptStateVersion checks that a scenario's prompt_state_version is one the
+// engine can still produce. 0 means the current version.
+func ValidatePromptStateVersion(version int) error {
+	if version < 0 || version > PromptStateVersion {
+		return fmt.Errorf("prompt_state_version %d must be between 1 and %d", version, PromptStateVersion)
+	}
+	return nil
+}
+
+// ForVersion returns the state in the shape of an earlier version, for scenarios whose
+// prompts were tuned against it. Version 0 or the current version returns ps unchanged.
+func (ps *PromptState) ForVersion(version int) (*PromptState, error) {
+	if err := ValidatePromptStateVersion(version); err != nil {
+		return nil, err
+	}
+	from := ps.Version
+	if from == 0 {
+		from = PromptStateVersion
+	}
+	if version == 0 || version == from {
+		return ps, nil
+	}
+	if version > from {
+		return nil, fmt.Errorf("prompt state version %d can't be converted to newer version %d", from, version)
+	}
+
+	converted := *ps
+	for v := from; v > version; v-- {
+		promptStateShims[v](&converted)
+	}
+	converted.Version = version
+	return &converted, nil
+}
+
+// PromptStateJSONForVersion converts a serialized PromptState to an earlier version,
+// for state that was serialized before the version it's needed in was known
+func PromptStateJSONForVersion(data []byte, version int) ([]byte, error) {
+	if version == 0 || version == PromptStateVersion {
+		return data, nil
+	}
+	var ps PromptState
+	if err := json.Unmarshal(data, &ps); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt state: %w", err)
+	}
+	converted, err := ps.ForVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(converted)
+}
@@ -0,0 +1,119 @@
+package prompts
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestPromptState_ForVersion(t *testing.T) {
+	ps := &PromptState{
+		Version:        PromptStateVersion,
+		Location:       "tavern",
+		WorldLocations: map[string]scenario.Location{"tavern": {Name: "The Rusty Anchor"}},
+		NPCs: map[string]actor.NPC{"gibbs": {
+			Name: "Gibbs", Location: "tavern", Description: "First mate.",
+			Stats: &actor.Stats5e{Strength: 14}, AC: 12, HP: 9, MaxHP: 11,
+		}},
+		NPCRoster: map[string]string{"drinker_1": "Drinker"},
+		Ambient:   "A fiddle plays.",
+	}
+
+	tests := []struct {
+		name        string
+		version     int
+		wantVersion int
+		wantErr     bool
+	}{
+		{name: "latest", version: 0, wantVersion: PromptStateVersion},
+		{name: "current", version: PromptStateVersion, wantVersion: PromptStateVersion},
+		{name: "version 2", version: 2, wantVersion: 2},
+		{name: "version 1", version: 1, wantVersion: 1},
+		{name: "future", version: PromptStateVersion + 1, wantErr: true},
+		{name: "negative", version: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ps.ForVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ForVersion(%d) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Version != tt.wantVersion {
+				t.Errorf("Expected version %d, got %d", tt.wantVersion, got.Version)
+			}
+		})
+	}
+
+	requireContains(t, ps.ToString(), "NPCs here: Gibbs (AC: 12, HP: 9/11)")
+
+	v2, err := ps.ForVersion(2)
+	if err != nil {
+		t.Fatalf("ForVersion(2) error: %v", err)
+	}
+	if npc := v2.NPCs["gibbs"]; npc.Stats != nil || npc.HP != 0 || npc.MaxHP != 0 || npc.AC != 0 {
+		t.Errorf("Expected version 2 to have no NPC stats, got %+v", npc)
+	}
+	requireContains(t, v2.ToString(), "NPCs here: Gibbs\n")
+
+	v1, err := ps.ForVersion(1)
+	if err != nil {
+		t.Fatalf("ForVersion(1) error: %v", err)
+	}
+	if len(v1.NPCRoster) != 0 || v1.Ambient != "" {
+		t.Errorf("Expected version 1 to have no roster or ambient detail, got %v and %q", v1.NPCRoster, v1.Ambient)
+	}
+	if npc := v1.NPCs["drinker_1"]; npc.Name != "Drinker" || npc.Location != "tavern" {
+		t.Errorf("Expected the roster folded into npcs, got %+v", v1.NPCs)
+	}
+	if v1.NPCs["gibbs"].Description != "First mate." {
+		t.Errorf("Expected NPCs given in full to be kept, got %+v", v1.NPCs["gibbs"])
+	}
+	result := v1.ToString()
+	requireContains(t, result, `<world_state version="1">`)
+	requireContains(t, result, "NPCs here: Drinker, Gibbs")
+	requireNotContains(t, result, "Ambient detail")
+
+	if len(ps.NPCRoster) != 1 || ps.Ambient == "" || len(ps.NPCs) != 1 || ps.NPCs["gibbs"].MaxHP != 11 {
+		t.Error("ForVersion must not modify the original state")
+	}
+}
+
+func TestPromptStateJSONForVersion(t *testing.T) {
+	data, err := json.Marshal(&PromptState{
+		Version:   PromptStateVersion,
+		Location:  "tavern",
+		NPCRoster: map[string]string{"drinker_1": "Drinker"},
+		Ambient:   "A fiddle plays.",
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal prompt state: %v", err)
+	}
+
+	if same, err := PromptStateJSONForVersion(data, 0); err != nil || string(same) != string(data) {
+		t.Errorf("Expected the latest version unchanged, got %s, %v", same, err)
+	}
+
+	v1, err := PromptStateJSONForVersion(data, 1)
+	if err != nil {
+		t.Fatalf("PromptStateJSONForVersion() error: %v", err)
+	}
+	got := string(v1)
+	requireContains(t, got, `"schema_version":1`)
+	requireContains(t, got, `"drinker_1":{"name":"Drinker"`)
+	for _, unwanted := range []string{"npc_roster", "ambient"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("Expected no %s in version 1, got %s", unwanted, got)
+		}
+	}
+
+	if _, err := PromptStateJSONForVersion([]byte("not json"), 1); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
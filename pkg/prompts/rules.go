@@ -0,0 +1,91 @@
+package prompts
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+// Sections of the <rules> block appended after the player's message
+const (
+	RulesEngine   = "engine"   // NarratorRules, always included
+	RulesRating   = "rating"   // RatingRules for the scenario's content rating
+	RulesScenario = "scenario" // the scenario author's rules
+	RulesNarrator = "narrator" // the narrator's rules
+)
+
+// DefaultRulesOrder is the order of the <rules> block's sections unless the scenario
+// sets its own. The model weighs the last reminders most, so the most specific come last.
+var DefaultRulesOrder = []string{RulesEngine, RulesRating, RulesScenario, RulesNarrator}
+
+// RatingRules are per-turn reminders of each content rating's limits. The rating's full
+// guidance is in the system prompt; these restate it where the model looks last.
+var RatingRules = map[string][]string{
+	scenario.RatingG:    {"Keep it gentle and child-friendly: no violence, frightening detail, romance, or rude words."},
+	scenario.RatingPG:   {"Keep language clean and peril mild — nothing graphic, cruel, or dark."},
+	scenario.RatingPG13: {"Keep swearing mild, and violence and romance within PG-13 limits."},
+}
+
+// ComposeRules returns the reminders for the <rules> block: the engine's rules, the
+// content rating's, the scenario author's, and the narrator's, in the scenario's
+// rules_order. Sections it leaves out follow in the default order, and a reminder
+// repeated in a later section is only included once.
+func ComposeRules(s *scenario.Scenario, narrator *scenario.Narrator) []string {
+	sections := map[string][]string{
+		RulesEngine:   NarratorRules,
+		RulesRating:   ratingRules(s.Rating),
+		RulesScenario: s.Rules,
+	}
+	if narrator != nil {
+		sections[RulesNarrator] = narrator.Rules
+	}
+
+	var rules []string
+	for _, section := range RulesOrder(s) {
+		for _, rule := range sections[section] {
+			if !slices.Contains(rules, rule) {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules
+}
+
+// RulesOrder returns the order of the <rules> block's sections for the scenario: its
+// rules_order, then any sections it leaves out in the default order. Unknown sections
+// are ignored.
+func RulesOrder(s *scenario.Scenario) []string {
+	order := make([]string, 0, len(DefaultRulesOrder))
+	for _, section := range append(slices.Clone(s.RulesOrder), DefaultRulesOrder...) {
+		if slices.Contains(DefaultRulesOrder, section) && !slices.Contains(order, section) {
+			order = append(order, section)
+		}
+	}
+	return order
+}
+
+// ValidateRulesOrder checks that a scenario's rules_order names each known section at
+// most once
+func ValidateRulesOrder(order []string) error {
+	for i, section := range order {
+		if !slices.Contains(DefaultRulesOrder, section) {
+			return fmt.Errorf("rules_order section %q must be one of %v", section, DefaultRulesOrder)
+		}
+		if slices.Contains(order[:i], section) {
+			return fmt.Errorf("rules_order section %q is listed more than once", section)
+		}
+	}
+	return nil
+}
+
+// ratingRules returns the reminders for a content rating, defaulting to PG-13's like
+// GetContentRatingPrompt
+func ratingRules(rating string) []string {
+	switch rating {
+	case scenario.RatingG, scenario.RatingPG, scenario.RatingPG13, scenario.RatingR:
+		return RatingRules[rating]
+	default:
+		return RatingRules[scenario.RatingPG13]
+	}
+}
@@ -0,0 +1,94 @@
+package prompts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestComposeRules(t *testing.T) {
+	narrator := &scenario.Narrator{Rules: []string{"Speak in riddles.", NarratorRules[0]}}
+
+	tests := []struct {
+		name     string
+		scenario *scenario.Scenario
+		narrator *scenario.Narrator
+		expected []string
+	}{
+		{
+			name:     "engine and rating rules",
+			scenario: &scenario.Scenario{Rating: scenario.RatingG},
+			expected: append(append([]string{}, NarratorRules...), RatingRules[scenario.RatingG]...),
+		},
+		{
+			name:     "no rating rules for R",
+			scenario: &scenario.Scenario{Rating: scenario.RatingR},
+			expected: NarratorRules,
+		},
+		{
+			name:     "unknown rating uses PG-13",
+			scenario: &scenario.Scenario{Rating: "NC-17"},
+			expected: append(append([]string{}, NarratorRules...), RatingRules[scenario.RatingPG13]...),
+		},
+		{
+			name:     "default order, repeated rule dropped",
+			scenario: &scenario.Scenario{Rating: scenario.RatingR, Rules: []string{"Never name the killer."}},
+			narrator: narrator,
+			expected: append(append([]string{}, NarratorRules...), "Never name the killer.", "Speak in riddles."),
+		},
+		{
+			name: "scenario order, omitted sections follow",
+			scenario: &scenario.Scenario{
+				Rating:     scenario.RatingPG,
+				Rules:      []string{"Never name the killer."},
+				RulesOrder: []string{RulesNarrator, RulesRating},
+			},
+			narrator: narrator,
+			expected: []string{
+				"Speak in riddles.", NarratorRules[0],
+				RatingRules[scenario.RatingPG][0],
+				NarratorRules[1], NarratorRules[2],
+				"Never name the killer.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComposeRules(tt.scenario, tt.narrator)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ComposeRules() =\n%q\nwant\n%q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateRulesOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		order       []string
+		expectedErr string
+	}{
+		{"empty", nil, ""},
+		{"partial", []string{RulesScenario, RulesEngine}, ""},
+		{"unknown section", []string{"author"}, "must be one of"},
+		{"repeated section", []string{RulesRating, RulesRating}, "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRulesOrder(tt.order)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
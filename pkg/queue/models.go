@@ -16,6 +16,15 @@ const (
 
 	// RequestTypeStoryEvent is a system-generated story event
 	RequestTypeStoryEvent RequestType = "story_event"
+
+	// RequestTypeRegenerate replays the last player turn for a new narration
+	RequestTypeRegenerate RequestType = "regenerate"
+
+	// RequestTypeChooseVariant makes one of a turn's narration candidates canon
+	RequestTypeChooseVariant RequestType = "choose_variant"
+
+	// RequestTypeContinue extends the last narration without a new player action
+	RequestTypeContinue RequestType = "continue"
 )
 
 // Request represents a unified request in the queue
@@ -23,10 +32,18 @@ type Request struct {
 	RequestID   string      `json:"request_id"`
 	Type        RequestType `json:"type"`
 	GameStateID uuid.UUID   `json:"game_state_id"`
+	Profile     string      `json:"profile,omitempty"` // Configuration profile that owns the game, if any
 
 	// Chat-specific fields
-	Message string `json:"message,omitempty"`
-	Actor   string `json:"actor,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Actor      string `json:"actor,omitempty"`
+	FreeAction bool   `json:"free_action,omitempty"` // Doesn't advance the turn counters
+	Embellish  bool   `json:"embellish,omitempty"`   // Narrator rewords answers to server commands
+	Variants   int    `json:"variants,omitempty"`    // Narration candidates to generate for the client to choose from
+	Variant    int    `json:"variant,omitempty"`     // Candidate chosen, for choose_variant requests
+
+	MaxTokens    int `json:"max_tokens,omitempty"`    // Narration token limit set by the request, overriding the scenario's
+	MaxSentences int `json:"max_sentences,omitempty"` // Narration sentence limit set by the request, overriding the scenario's
 
 	// Story event-specific fields
 	EventPrompt string `json:"event_prompt,omitempty"`
@@ -0,0 +1,75 @@
+package scenario
+
+import (
+	"maps"
+	"slices"
+)
+
+// BackfillIDs writes out the stable IDs a scenario's entries have implicitly, so their
+// keys and names can change from then on without breaking saved games. Each location
+// and NPC without an id gets its key in canonical form, or the base entry's ID for a
+// scene's override of one, and each item the scenario mentions that isn't catalogued
+// gets an entry in the item catalog under its name in canonical form. Games saved
+// before the backfill already use those IDs, once migrated; see
+// state.GameState.MigrateIDs.
+//
+// Items whose names differ only in case or punctuation are two items to the engine,
+// so they're left out of the catalog rather than merged, and returned, grouped, for
+// the author to sort out by hand. Call it on a scenario as read, not normalized.
+func (s *Scenario) BackfillIDs() [][]string {
+	locationIDs := make(map[string]string)
+	npcIDs := make(map[string]string)
+	backfillIDs(s.Locations, locationIDField, locationIDs)
+	backfillIDs(s.NPCs, npcIDField, npcIDs)
+	for _, scene := range s.Scenes {
+		backfillIDs(scene.Locations, locationIDField, locationIDs)
+		backfillIDs(scene.NPCs, npcIDField, npcIDs)
+	}
+
+	items := s.Items()
+	var skipped [][]string
+	similar := make(map[string]bool)
+	for _, names := range items.SimilarNames() {
+		skipped = append(skipped, names)
+		for _, name := range names {
+			similar[name] = true
+		}
+	}
+	for _, item := range items.Names() {
+		if similar[item] || items.Has(item, ItemCatalogued) || !items.Has(item, ItemPlaced, ItemGranted, ItemUsed, ItemCandidate, ItemDescribed) {
+			continue // reported, already catalogued, or only an old name
+		}
+		id := NormalizeKey(item)
+		if _, taken := s.ItemCatalog[id]; taken || id == "" {
+			skipped = append(skipped, []string{item})
+			continue
+		}
+		if s.ItemCatalog == nil {
+			s.ItemCatalog = make(map[string]Item)
+		}
+		s.ItemCatalog[id] = Item{Name: item}
+	}
+	return skipped
+}
+
+// backfillIDs sets the id field of each entry of m that lacks one: the ID already given
+// to the same key, for a scene's override, or else the key in canonical form. ids
+// records each canonical key → ID.
+func backfillIDs[V any](m map[string]V, id idField[V], ids map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		v := m[key]
+		canonical := NormalizeKey(key)
+		explicit := NormalizeKey(id.get(v))
+		switch known, ok := ids[canonical]; {
+		case explicit != "":
+		case ok:
+			m[key] = id.set(v, known)
+		default:
+			explicit = canonical
+			m[key] = id.set(v, canonical)
+		}
+		if _, ok := ids[canonical]; !ok {
+			ids[canonical] = explicit
+		}
+	}
+}
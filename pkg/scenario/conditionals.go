@@ -1,6 +1,12 @@
 package scenario
 
-import "github.com/jwebster45206/story-engine/pkg/conditionals"
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
 
 // EvaluateConditionals checks all conditionals for the current scene and returns triggered conditionals
 // Returns a map of conditional IDs to their conditionals
@@ -30,6 +36,34 @@ func (s *Scenario) EvaluateConditionals(gsView conditionals.GameStateView) map[s
 	return triggered
 }
 
+// SelectConditionals orders triggered conditionals by priority, highest first, with ties
+// broken by ID so the order is deterministic. Within an exclusivity group only the first
+// conditional is selected; suppressed maps each other group member to the one that won.
+func SelectConditionals(triggered map[string]Conditional) (selected []string, suppressed map[string]string) {
+	ids := slices.SortedFunc(maps.Keys(triggered), func(a, b string) int {
+		return cmp.Or(cmp.Compare(triggered[b].Priority, triggered[a].Priority), cmp.Compare(a, b))
+	})
+
+	groupWinners := make(map[string]string)
+	for _, id := range ids {
+		group := triggered[id].Group
+		if group == "" {
+			selected = append(selected, id)
+			continue
+		}
+		if winner, ok := groupWinners[group]; ok {
+			if suppressed == nil {
+				suppressed = make(map[string]string)
+			}
+			suppressed[id] = winner
+			continue
+		}
+		groupWinners[group] = id
+		selected = append(selected, id)
+	}
+	return selected, suppressed
+}
+
 // FilterContingencyPrompts returns only the prompts whose conditions are met
 // Prompts without conditions (When == nil) are always included
 func FilterContingencyPrompts(prompts []conditionals.ContingencyPrompt, gsView conditionals.GameStateView) []string {
@@ -1,6 +1,8 @@
 package scenario
 
 import (
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
@@ -13,6 +15,7 @@ type mockGameStateView struct {
 	sceneTurnCounter int
 	turnCounter      int
 	userLocation     string
+	lore             []string
 }
 
 func (m *mockGameStateView) GetSceneName() string       { return m.sceneName }
@@ -20,6 +23,7 @@ func (m *mockGameStateView) GetVars() map[string]string { return m.vars }
 func (m *mockGameStateView) GetSceneTurnCounter() int   { return m.sceneTurnCounter }
 func (m *mockGameStateView) GetTurnCounter() int        { return m.turnCounter }
 func (m *mockGameStateView) GetUserLocation() string    { return m.userLocation }
+func (m *mockGameStateView) KnowsLore(id string) bool   { return slices.Contains(m.lore, id) }
 
 func TestFilterContingencyPrompts(t *testing.T) {
 	tests := []struct {
@@ -159,6 +163,36 @@ func TestFilterContingencyPrompts(t *testing.T) {
 			},
 			expected: []string{},
 		},
+		{
+			name: "lore known condition satisfied",
+			prompts: []conditionals.ContingencyPrompt{
+				{
+					Prompt: "Show once both legends are known",
+					When: &conditionals.ConditionalWhen{
+						LoreKnown: []string{"sunken_bell", "drowned_king"},
+					},
+				},
+			},
+			gsView: &mockGameStateView{
+				lore: []string{"drowned_king", "sunken_bell"},
+			},
+			expected: []string{"Show once both legends are known"},
+		},
+		{
+			name: "lore known condition not satisfied",
+			prompts: []conditionals.ContingencyPrompt{
+				{
+					Prompt: "Show once both legends are known",
+					When: &conditionals.ConditionalWhen{
+						LoreKnown: []string{"sunken_bell", "drowned_king"},
+					},
+				},
+			},
+			gsView: &mockGameStateView{
+				lore: []string{"sunken_bell"},
+			},
+			expected: []string{},
+		},
 		{
 			name: "multiple prompts with different conditions",
 			prompts: []conditionals.ContingencyPrompt{
@@ -321,3 +355,59 @@ func TestScenario_EvaluateConditionals_WithPrompt(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectConditionals(t *testing.T) {
+	tests := []struct {
+		name               string
+		triggered          map[string]Conditional
+		expectedSelected   []string
+		expectedSuppressed map[string]string
+	}{
+		{
+			name:      "none triggered",
+			triggered: nil,
+		},
+		{
+			name: "priority then ID order",
+			triggered: map[string]Conditional{
+				"b_low":  {},
+				"a_low":  {},
+				"urgent": {Priority: 10},
+				"minor":  {Priority: -1},
+			},
+			expectedSelected: []string{"urgent", "a_low", "b_low", "minor"},
+		},
+		{
+			name: "only highest priority in a group",
+			triggered: map[string]Conditional{
+				"escape_cellar": {Group: "escape", Priority: 1},
+				"escape_roof":   {Group: "escape", Priority: 5},
+				"escape_window": {Group: "escape"},
+				"ring_bell":     {},
+			},
+			expectedSelected:   []string{"escape_roof", "ring_bell"},
+			expectedSuppressed: map[string]string{"escape_cellar": "escape_roof", "escape_window": "escape_roof"},
+		},
+		{
+			name: "group tie goes to earlier ID",
+			triggered: map[string]Conditional{
+				"ending_b": {Group: "ending"},
+				"ending_a": {Group: "ending"},
+			},
+			expectedSelected:   []string{"ending_a"},
+			expectedSuppressed: map[string]string{"ending_b": "ending_a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, suppressed := SelectConditionals(tt.triggered)
+			if !slices.Equal(selected, tt.expectedSelected) {
+				t.Errorf("Expected selected %v, got %v", tt.expectedSelected, selected)
+			}
+			if !maps.Equal(suppressed, tt.expectedSuppressed) {
+				t.Errorf("Expected suppressed %v, got %v", tt.expectedSuppressed, suppressed)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package scenario
+
+// DefaultHintBudget is how many hints a player may ask for in a scene that doesn't set
+// hint_budget, or in a scenario without scenes
+const DefaultHintBudget = 3
+
+// SceneHints returns the author-written hints for a scene and how many hints a player
+// may ask for there. A scene without hint_budget allows one hint per written hint, or
+// DefaultHintBudget if it has none; unknown scenes have no written hints.
+func (s *Scenario) SceneHints(sceneName string) ([]string, int) {
+	if s == nil {
+		return nil, DefaultHintBudget
+	}
+	scene, ok := s.Scenes[sceneName]
+	if !ok {
+		return nil, DefaultHintBudget
+	}
+	switch {
+	case scene.HintBudget < 0:
+		return scene.Hints, 0
+	case scene.HintBudget == 0 && len(scene.Hints) > 0:
+		return scene.Hints, len(scene.Hints)
+	case scene.HintBudget == 0:
+		return scene.Hints, DefaultHintBudget
+	}
+	return scene.Hints, scene.HintBudget
+}
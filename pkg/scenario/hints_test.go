@@ -0,0 +1,43 @@
+package scenario
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScenario_SceneHints(t *testing.T) {
+	s := &Scenario{
+		Scenes: map[string]Scene{
+			"written":  {Hints: []string{"Look up.", "Check the rafters.", "Climb the ladder."}},
+			"budgeted": {Hints: []string{"Look up."}, HintBudget: 5},
+			"none":     {HintBudget: -1},
+			"default":  {},
+		},
+	}
+
+	tests := []struct {
+		scene       string
+		wantWritten int
+		wantBudget  int
+	}{
+		{"written", 3, 3},
+		{"budgeted", 1, 5},
+		{"none", 0, 0},
+		{"default", 0, DefaultHintBudget},
+		{"missing", 0, DefaultHintBudget},
+		{"", 0, DefaultHintBudget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scene, func(t *testing.T) {
+			written, budget := s.SceneHints(tt.scene)
+			if len(written) != tt.wantWritten || budget != tt.wantBudget {
+				t.Errorf("SceneHints(%q) = %d hints, budget %d; want %d, %d", tt.scene, len(written), budget, tt.wantWritten, tt.wantBudget)
+			}
+		})
+	}
+
+	if written, _ := s.SceneHints("written"); !slices.Equal(written, s.Scenes["written"].Hints) {
+		t.Errorf("Expected written hints in order, got %v", written)
+	}
+}
@@ -0,0 +1,171 @@
+package scenario
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
+
+// Ways a scenario can mention an item, as reported in ItemRef
+const (
+	ItemPlaced     = "placed"     // Starts somewhere: opening inventory, or a location's, NPC's, or monster's items
+	ItemGranted    = "granted"    // Given to the player by a conditional's acquire item event
+	ItemUsed       = "used"       // Needed by a conditional: any other item event, or a clear_inventory filter
+	ItemDescribed  = "described"  // Has item_details
+	ItemCandidate  = "candidate"  // Listed in the scenario's inventory of potential items
+	ItemRenamedOld = "renamed"    // An old name in renamed.items
+	ItemCatalogued = "catalogued" // Has an entry in items
+)
+
+// Item is an entry in a scenario's item catalog. A catalogued item is stored in saved
+// games by its ID, so its display name can change freely, and references to it match
+// its ID or name in canonical form; see NormalizeKey. Items that aren't catalogued are
+// their own ID, matched exactly as written.
+type Item struct {
+	ID   string `json:"id,omitempty"` // Stable ID that saved games and references use; defaults to the map key
+	Name string `json:"name"`         // Display name, shown to the player and the narrator
+}
+
+// ItemRef is one place a scenario mentions an item
+type ItemRef struct {
+	Item  string // The item's name, as written
+	Field string // Where, e.g. "scenes.docks.conditionals.found_key.then.item_events[0]"
+	Kind  string // ItemPlaced, ItemGranted, ItemUsed, ItemDescribed, ItemCandidate, or ItemRenamedOld
+}
+
+// ItemRegistry is every item a scenario mentions, by ID. A catalogued item's references
+// are collected under its ID whether they use the ID or the name; any other item's name
+// is its ID, matched exactly, so "Rusty Key" and "rusty key" are two items.
+type ItemRegistry struct {
+	refs map[string][]ItemRef
+}
+
+// Items collects the scenario's item references, in a stable order
+func (s *Scenario) Items() *ItemRegistry {
+	r := &ItemRegistry{refs: make(map[string][]ItemRef)}
+	add := func(item, field, kind string) {
+		if strings.TrimSpace(item) == "" {
+			return
+		}
+		id := item
+		if kind != ItemRenamedOld {
+			id = s.catalogItem(item)
+		}
+		r.refs[id] = append(r.refs[id], ItemRef{Item: item, Field: field, Kind: kind})
+	}
+	addList := func(field, kind string, items []string) {
+		for i, item := range items {
+			add(item, fmt.Sprintf("%s[%d]", field, i), kind)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(s.ItemCatalog)) {
+		add(key, "items."+key, ItemCatalogued)
+	}
+	addList("opening_inventory", ItemPlaced, s.OpeningInventory)
+	addList("inventory", ItemCandidate, s.Inventory)
+	for _, item := range slices.Sorted(maps.Keys(s.ItemDetails)) {
+		add(item, "item_details."+item, ItemDescribed)
+	}
+	if s.Renamed != nil {
+		for _, item := range slices.Sorted(maps.Keys(s.Renamed.Items)) {
+			add(item, "renamed.items."+item, ItemRenamedOld)
+		}
+	}
+	addLocationItems(addList, "locations", s.Locations)
+	addNPCItems(addList, "npcs", s.NPCs)
+
+	for _, name := range slices.Sorted(maps.Keys(s.Scenes)) {
+		scene := s.Scenes[name]
+		prefix := "scenes." + name
+		addLocationItems(addList, prefix+".locations", scene.Locations)
+		addNPCItems(addList, prefix+".npcs", scene.NPCs)
+		for _, id := range slices.Sorted(maps.Keys(scene.Conditionals)) {
+			addDeltaItems(add, addList, prefix+".conditionals."+id+".then", scene.Conditionals[id].Then)
+		}
+	}
+	return r
+}
+
+func addLocationItems(addList func(field, kind string, items []string), field string, locations map[string]Location) {
+	for _, key := range slices.Sorted(maps.Keys(locations)) {
+		loc := locations[key]
+		addList(field+"."+key+".items", ItemPlaced, loc.Items)
+		for _, id := range slices.Sorted(maps.Keys(loc.Monsters)) {
+			if m := loc.Monsters[id]; m != nil {
+				addList(field+"."+key+".monsters."+id+".items", ItemPlaced, m.Items)
+			}
+		}
+	}
+}
+
+func addNPCItems(addList func(field, kind string, items []string), field string, npcs map[string]actor.NPC) {
+	for _, key := range slices.Sorted(maps.Keys(npcs)) {
+		addList(field+"."+key+".items", ItemPlaced, npcs[key].Items)
+	}
+}
+
+func addDeltaItems(add func(item, field, kind string), addList func(field, kind string, items []string), field string, then conditionals.GameStateDelta) {
+	for i, e := range then.ItemEvents {
+		kind := ItemUsed
+		if e.Action == "acquire" {
+			kind = ItemGranted
+		}
+		add(e.Item, fmt.Sprintf("%s.item_events[%d]", field, i), kind)
+	}
+	for i, f := range then.ClearInventory {
+		addList(fmt.Sprintf("%s.clear_inventory[%d].items", field, i), ItemUsed, f.Items)
+		addList(fmt.Sprintf("%s.clear_inventory[%d].except", field, i), ItemUsed, f.Except)
+	}
+}
+
+// Names returns every item mentioned, sorted
+func (r *ItemRegistry) Names() []string {
+	return slices.Sorted(maps.Keys(r.refs))
+}
+
+// Refs returns the places an item is mentioned
+func (r *ItemRegistry) Refs(item string) []ItemRef {
+	return r.refs[item]
+}
+
+// Has reports whether an item is mentioned in any of the given ways, or at all if none
+// are given
+func (r *ItemRegistry) Has(item string, kinds ...string) bool {
+	for _, ref := range r.refs[item] {
+		if len(kinds) == 0 || slices.Contains(kinds, ref.Kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Obtainable reports whether the player can come by an item: it starts somewhere, or a
+// conditional grants it
+func (r *ItemRegistry) Obtainable(item string) bool {
+	return r.Has(item, ItemPlaced, ItemGranted)
+}
+
+// SimilarNames groups item names that are the same once normalized but written
+// differently, e.g. "Rusty Key" and "rusty key", which are likely meant to be one item
+func (r *ItemRegistry) SimilarNames() [][]string {
+	byKey := make(map[string][]string)
+	for _, item := range r.Names() {
+		if !r.Has(item, ItemPlaced, ItemGranted, ItemUsed, ItemCandidate) {
+			continue // old names differ on purpose, and item details match any case
+		}
+		key := NormalizeKey(item)
+		byKey[key] = append(byKey[key], item)
+	}
+	var out [][]string
+	for _, key := range slices.Sorted(maps.Keys(byKey)) {
+		if len(byKey[key]) > 1 {
+			out = append(out, byKey[key])
+		}
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package scenario
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const itemsScenario = `{
+	"opening_inventory": ["lantern"],
+	"inventory": ["gold coin"],
+	"item_details": {"Lantern": "A dented brass lantern.", "crown": "A tarnished crown."},
+	"renamed": {"items": {"Old Lantern": "lantern"}},
+	"locations": {
+		"cellar": {"items": ["Rusty Key"], "monsters": {"rat_1": {"items": ["cheese"]}}}
+	},
+	"npcs": {"innkeeper": {"items": ["ale"]}},
+	"scenes": {
+		"inn": {
+			"npcs": {"guard": {"items": ["rusty key"]}},
+			"conditionals": {
+				"paid": {
+					"when": {"vars": {"paid": "true"}},
+					"then": {
+						"item_events": [
+							{"item": "gold coin", "action": "acquire"},
+							{"item": "ale", "action": "give", "from": {"type": "npc", "name": "innkeeper"}},
+							{"item": "map", "action": "use"}
+						],
+						"clear_inventory": [{"items": ["cheese"], "except": ["lantern"]}]
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestScenario_Items(t *testing.T) {
+	var s Scenario
+	if err := json.Unmarshal([]byte(itemsScenario), &s); err != nil {
+		t.Fatalf("Failed to unmarshal scenario: %v", err)
+	}
+	items := s.Items()
+
+	wantNames := []string{"Lantern", "Old Lantern", "Rusty Key", "ale", "cheese", "crown", "gold coin", "lantern", "map", "rusty key"}
+	if got := items.Names(); !reflect.DeepEqual(got, wantNames) {
+		t.Errorf("Names() = %v, want %v", got, wantNames)
+	}
+
+	wantRefs := []ItemRef{
+		{Item: "ale", Field: "npcs.innkeeper.items[0]", Kind: ItemPlaced},
+		{Item: "ale", Field: "scenes.inn.conditionals.paid.then.item_events[1]", Kind: ItemUsed},
+	}
+	if got := items.Refs("ale"); !reflect.DeepEqual(got, wantRefs) {
+		t.Errorf("Refs(ale) = %v, want %v", got, wantRefs)
+	}
+
+	tests := []struct {
+		item       string
+		obtainable bool
+	}{
+		{"lantern", true},   // opening inventory
+		{"Rusty Key", true}, // location
+		{"rusty key", true}, // scene NPC
+		{"cheese", true},    // monster
+		{"gold coin", true}, // granted by an acquire event
+		{"map", false},      // only used
+		{"crown", false},    // only described
+		{"Old Lantern", false},
+	}
+	for _, tt := range tests {
+		if got := items.Obtainable(tt.item); got != tt.obtainable {
+			t.Errorf("Obtainable(%q) = %v, want %v", tt.item, got, tt.obtainable)
+		}
+	}
+
+	if !items.Has("crown", ItemDescribed) || items.Has("crown", ItemPlaced) {
+		t.Errorf("Expected crown to be described only, got %v", items.Refs("crown"))
+	}
+	if !items.Has("lantern") || items.Has("sword") {
+		t.Error("Has() with no kinds should report whether the item is mentioned at all")
+	}
+
+	wantSimilar := [][]string{{"Rusty Key", "rusty key"}}
+	if got := items.SimilarNames(); !reflect.DeepEqual(got, wantSimilar) {
+		t.Errorf("SimilarNames() = %v, want %v", got, wantSimilar)
+	}
+}
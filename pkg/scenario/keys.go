@@ -0,0 +1,462 @@
+package scenario
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
+
+// Kinds of keyed scenario entries, as reported in KeyCollision
+const (
+	KeyKindLocation = "location"
+	KeyKindNPC      = "npc"
+	KeyKindScene    = "scene"
+	KeyKindItem     = "item"
+)
+
+// NormalizeKey returns the canonical form of a location, NPC, scene, or item ID: lower
+// snake_case with apostrophes dropped, so "Captain's Cabin", "captains-cabin", and
+// "captains_cabin" are the same key
+func NormalizeKey(s string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r == '\'' || r == '’':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pending && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pending = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			pending = true
+		}
+	}
+	return b.String()
+}
+
+// KeyCollision is a set of entries whose keys or display names normalize to the same
+// key. References to that key reach only the first entry in Keys.
+type KeyCollision struct {
+	Kind string   // KeyKindLocation, KeyKindNPC, KeyKindScene, or KeyKindItem
+	Key  string   // The canonical ID or display name they share
+	Keys []string // The entries' keys, as written
+}
+
+// Normalize rekeys the scenario's locations, NPCs, and catalogued items by their stable
+// ID (the id field, or else the key as written, in canonical form) and its scenes by
+// canonical key. It then resolves references to them (exits, opening location and
+// scene, NPC locations, followers and items, inventories, conditionals) by ID, key as
+// written, display name, or renamed ID, and builds the lookups used by GetLocation,
+// GetNPC, and ItemID. Storage calls it when a scenario is loaded, so the rest of the
+// engine can match IDs exactly. References that match nothing are left as written for
+// the validator to report.
+func (s *Scenario) Normalize() []KeyCollision {
+	var collisions []KeyCollision
+	locationIDs := make(map[string]string)
+	npcIDs := make(map[string]string)
+	itemIDs := make(map[string]string)
+	s.Locations = normalizeKeys(KeyKindLocation, s.Locations, locationIDField, locationIDs, &collisions)
+	s.NPCs = normalizeKeys(KeyKindNPC, s.NPCs, npcIDField, npcIDs, &collisions)
+	s.Scenes = normalizeKeys(KeyKindScene, s.Scenes, idField[Scene]{}, nil, &collisions)
+	for name, scene := range s.Scenes {
+		scene.Locations = normalizeKeys(KeyKindLocation, scene.Locations, locationIDField, locationIDs, &collisions)
+		scene.NPCs = normalizeKeys(KeyKindNPC, scene.NPCs, npcIDField, npcIDs, &collisions)
+		s.Scenes[name] = scene
+	}
+	s.ItemCatalog = normalizeKeys(KeyKindItem, s.ItemCatalog, itemIDField, itemIDs, &collisions)
+
+	locations := []map[string]Location{s.Locations}
+	npcs := []map[string]actor.NPC{s.NPCs}
+	for _, name := range slices.Sorted(maps.Keys(s.Scenes)) {
+		locations = append(locations, s.Scenes[name].Locations)
+		npcs = append(npcs, s.Scenes[name].NPCs)
+	}
+	s.locationNames = nameTable(KeyKindLocation, locations, locationIDs, func(l Location) string { return l.Name }, &collisions)
+	s.npcNames = nameTable(KeyKindNPC, npcs, npcIDs, func(n actor.NPC) string { return n.Name }, &collisions)
+	s.itemNames = nameTable(KeyKindItem, []map[string]Item{s.ItemCatalog}, itemIDs, func(i Item) string { return i.Name }, &collisions)
+
+	s.Renamed.normalize()
+	s.OpeningLocation = s.locationRef(s.OpeningLocation)
+	s.OpeningScene = s.sceneRef(s.OpeningScene)
+	s.Inventory = s.itemRefs(s.Inventory)
+	s.OpeningInventory = s.itemRefs(s.OpeningInventory)
+	s.ItemDetails = rekeyItems(s.ItemDetails, s.ItemID)
+	s.normalizeLocationRefs(s.Locations)
+	s.normalizeNPCRefs(s.NPCs)
+	s.normalizePromptRefs(s.ContingencyPrompts)
+	for name, scene := range s.Scenes {
+		s.normalizeLocationRefs(scene.Locations)
+		s.normalizeNPCRefs(scene.NPCs)
+		s.normalizePromptRefs(scene.ContingencyPrompts)
+		for id, c := range scene.Conditionals {
+			s.normalizeWhenRefs(&c.When)
+			s.normalizeDeltaRefs(&c.Then)
+			scene.Conditionals[id] = c
+		}
+		s.Scenes[name] = scene
+	}
+	return collisions
+}
+
+// idField reads and writes an entry's id field. Entries without one, like scenes, are
+// keyed by their canonical key.
+type idField[V any] struct {
+	get func(V) string
+	set func(V, string) V
+}
+
+var (
+	locationIDField = idField[Location]{
+		get: func(l Location) string { return l.ID },
+		set: func(l Location, id string) Location { l.ID = id; return l },
+	}
+	npcIDField = idField[actor.NPC]{
+		get: func(n actor.NPC) string { return n.ID },
+		set: func(n actor.NPC, id string) actor.NPC { n.ID = id; return n },
+	}
+	itemIDField = idField[Item]{
+		get: func(i Item) string { return i.ID },
+		set: func(i Item, id string) Item { i.ID = id; return i },
+	}
+)
+
+// normalizeKeys rekeys m by each entry's ID: its id field, or else the ID already given
+// to the same key (so a scene's override of a location or NPC joins the base entry), or
+// else its canonical key. ids records each canonical key as written → ID. When IDs
+// collide, the entry keyed by its ID as written wins, then the first key in sorted order.
+func normalizeKeys[V any](kind string, m map[string]V, id idField[V], ids map[string]string, collisions *[]KeyCollision) map[string]V {
+	if m == nil {
+		return nil
+	}
+	idOf := func(raw string) string {
+		if id.get != nil {
+			if explicit := NormalizeKey(id.get(m[raw])); explicit != "" {
+				return explicit
+			}
+		}
+		if known, ok := ids[NormalizeKey(raw)]; ok {
+			return known
+		}
+		return NormalizeKey(raw)
+	}
+	byID := make(map[string][]string)
+	for _, raw := range slices.Sorted(maps.Keys(m)) {
+		key := idOf(raw)
+		if raw == key {
+			byID[key] = append([]string{raw}, byID[key]...)
+		} else {
+			byID[key] = append(byID[key], raw)
+		}
+	}
+	out := make(map[string]V, len(byID))
+	for _, key := range slices.Sorted(maps.Keys(byID)) {
+		raws := byID[key]
+		v := m[raws[0]]
+		if id.set != nil {
+			v = id.set(v, key)
+		}
+		out[key] = v
+		if len(raws) > 1 {
+			*collisions = append(*collisions, KeyCollision{Kind: kind, Key: key, Keys: raws})
+		}
+		for _, raw := range raws {
+			if _, ok := ids[NormalizeKey(raw)]; !ok && ids != nil {
+				ids[NormalizeKey(raw)] = key
+			}
+		}
+	}
+	return out
+}
+
+// nameTable maps each ID, canonical key as written, and canonical display name to its
+// entry's ID, in that order of precedence, and the scenario's entries over its scenes'.
+// The same ID in the scenario and a scene is an override, not a collision.
+func nameTable[V any](kind string, all []map[string]V, ids map[string]string, name func(V) string, collisions *[]KeyCollision) map[string]string {
+	table := make(map[string]string)
+	for _, m := range all {
+		for key := range m {
+			table[key] = key
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(ids)) {
+		if _, ok := table[key]; !ok {
+			table[key] = ids[key]
+		}
+	}
+	claimed := make(map[string][]string) // canonical name -> IDs of entries whose names lost it
+	for _, m := range all {
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			canonical := NormalizeKey(name(m[key]))
+			if canonical == "" {
+				continue
+			}
+			switch owner, ok := table[canonical]; {
+			case !ok:
+				table[canonical] = key
+			case owner != key && !slices.Contains(claimed[canonical], key):
+				claimed[canonical] = append(claimed[canonical], key)
+			}
+		}
+	}
+	for _, canonical := range slices.Sorted(maps.Keys(claimed)) {
+		*collisions = append(*collisions, KeyCollision{
+			Kind: kind,
+			Key:  canonical,
+			Keys: append([]string{table[canonical]}, claimed[canonical]...),
+		})
+	}
+	return table
+}
+
+// lookupKey resolves a key or display name through a name table. Without a table, as
+// for a scenario that was never normalized, it falls back to comparing display names.
+func lookupKey[V any](m map[string]V, table map[string]string, keyOrName string, name func(V) string) (string, bool) {
+	canonical := NormalizeKey(keyOrName)
+	if canonical == "" {
+		return "", false
+	}
+	if _, ok := m[canonical]; ok {
+		return canonical, true
+	}
+	if table != nil {
+		key, ok := table[canonical]
+		if _, exists := m[key]; ok && exists {
+			return key, true
+		}
+		return "", false
+	}
+	for key, v := range m {
+		if NormalizeKey(name(v)) == canonical {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// LocationID resolves a reference to any of the scenario's locations, including its
+// scenes', by ID, key as written, or display name, to the location's ID. A key listed
+// in Renamed resolves to its new ID.
+func (s *Scenario) LocationID(ref string) (string, bool) {
+	return resolveRef(s.locationNames, ref, s.Renamed.Location)
+}
+
+// NPCID resolves a reference to any of the scenario's NPCs to the NPC's ID
+func (s *Scenario) NPCID(ref string) (string, bool) {
+	return resolveRef(s.npcNames, ref, s.Renamed.NPC)
+}
+
+func resolveRef(table map[string]string, ref string, renamed func(string) string) (string, bool) {
+	if id, ok := table[NormalizeKey(ref)]; ok {
+		return id, true
+	}
+	id, ok := table[renamed(NormalizeKey(ref))]
+	return id, ok
+}
+
+// ItemID resolves a reference to an item, by ID or display name, to the catalogued
+// item's ID. An item listed in Renamed resolves to its new name or ID. Items that
+// aren't catalogued are their own ID, so any other reference is returned as written.
+func (s *Scenario) ItemID(ref string) string {
+	if id := s.catalogItem(ref); id != ref {
+		return id
+	}
+	if to := s.Renamed.Item(ref); to != ref {
+		return s.catalogItem(to)
+	}
+	if key := NormalizeKey(ref); len(s.ItemCatalog) > 0 && s.Renamed.Item(key) != key {
+		return s.catalogItem(s.Renamed.Item(key))
+	}
+	return ref
+}
+
+// ItemNames returns the display name of each catalogued item, by ID
+func (s *Scenario) ItemNames() map[string]string {
+	if len(s.ItemCatalog) == 0 {
+		return nil
+	}
+	names := make(map[string]string, len(s.ItemCatalog))
+	for id, item := range s.ItemCatalog {
+		names[id] = item.Name
+	}
+	return names
+}
+
+// catalogItem resolves a reference to a catalogued item to its ID, or returns it as
+// written. Without a name table, as for a scenario that was never normalized, it falls
+// back to comparing keys, IDs, and display names.
+func (s *Scenario) catalogItem(ref string) string {
+	canonical := NormalizeKey(ref)
+	if canonical == "" || len(s.ItemCatalog) == 0 {
+		return ref
+	}
+	if s.itemNames != nil {
+		if id, ok := s.itemNames[canonical]; ok {
+			return id
+		}
+		return ref
+	}
+	for _, key := range slices.Sorted(maps.Keys(s.ItemCatalog)) {
+		item := s.ItemCatalog[key]
+		id := NormalizeKey(item.ID)
+		if id == "" {
+			id = NormalizeKey(key)
+		}
+		if canonical == id || canonical == NormalizeKey(key) || canonical == NormalizeKey(item.Name) {
+			return id
+		}
+	}
+	return ref
+}
+
+// itemRefs resolves each item in list to its ID, in place
+func (s *Scenario) itemRefs(list []string) []string {
+	for i, item := range list {
+		list[i] = s.ItemID(item)
+	}
+	return list
+}
+
+// rekeyItems moves each entry of m to its item's ID. When references collide, the
+// first in sorted order wins.
+func rekeyItems[V any](m map[string]V, itemID func(string) string) map[string]V {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]V, len(m))
+	for _, ref := range slices.Sorted(maps.Keys(m)) {
+		id := itemID(ref)
+		if _, ok := out[id]; !ok {
+			out[id] = m[ref]
+		}
+	}
+	return out
+}
+
+// locationRef resolves a reference to a location to its ID, or returns it as written
+func (s *Scenario) locationRef(ref string) string {
+	if id, ok := s.LocationID(ref); ok {
+		return id
+	}
+	return ref
+}
+
+// npcRef resolves a reference to an NPC to its ID, or returns it as written
+func (s *Scenario) npcRef(ref string) string {
+	if id, ok := s.NPCID(ref); ok {
+		return id
+	}
+	return ref
+}
+
+func (s *Scenario) sceneRef(ref string) string {
+	if key := NormalizeKey(ref); s.HasScene(key) {
+		return key
+	}
+	return ref
+}
+
+func (s *Scenario) normalizeLocationRefs(locations map[string]Location) {
+	for key, loc := range locations {
+		for dir, target := range loc.Exits {
+			loc.Exits[dir] = s.locationRef(target)
+		}
+		loc.Items = s.itemRefs(loc.Items)
+		for _, m := range loc.Monsters {
+			if m != nil {
+				m.Items = s.itemRefs(m.Items)
+			}
+		}
+		s.normalizePromptRefs(loc.ContingencyPrompts)
+		locations[key] = loc
+	}
+}
+
+func (s *Scenario) normalizeNPCRefs(npcs map[string]actor.NPC) {
+	for key, npc := range npcs {
+		if npc.Location != "" {
+			npc.Location = s.locationRef(npc.Location)
+		}
+		if npc.Following != "" && npc.Following != "pc" {
+			npc.Following = s.npcRef(npc.Following)
+		}
+		npc.Items = s.itemRefs(npc.Items)
+		s.normalizePromptRefs(npc.ContingencyPrompts)
+		npcs[key] = npc
+	}
+}
+
+func (s *Scenario) normalizePromptRefs(prompts []conditionals.ContingencyPrompt) {
+	for _, cp := range prompts {
+		if cp.When != nil {
+			s.normalizeWhenRefs(cp.When)
+		}
+	}
+}
+
+func (s *Scenario) normalizeWhenRefs(when *conditionals.ConditionalWhen) {
+	if when.Location != "" {
+		when.Location = s.locationRef(when.Location)
+	}
+}
+
+func (s *Scenario) normalizeDeltaRefs(then *conditionals.GameStateDelta) {
+	if then.UserLocation != "" {
+		then.UserLocation = s.locationRef(then.UserLocation)
+	}
+	if then.SceneChange != nil {
+		then.SceneChange.To = s.sceneRef(then.SceneChange.To)
+	}
+	for i := range then.ItemEvents {
+		then.ItemEvents[i].Item = s.ItemID(then.ItemEvents[i].Item)
+		for _, end := range []*struct {
+			Type string `json:"type"`
+			Name string `json:"name,omitempty"`
+		}{then.ItemEvents[i].From, then.ItemEvents[i].To} {
+			switch {
+			case end == nil:
+			case end.Type == "npc":
+				end.Name = s.npcRef(end.Name)
+			case end.Type == "location":
+				end.Name = s.locationRef(end.Name)
+			}
+		}
+	}
+	for i, f := range then.ClearInventory {
+		f.Items = s.itemRefs(f.Items)
+		f.Except = s.itemRefs(f.Except)
+		then.ClearInventory[i] = f
+	}
+	for i, e := range then.NPCEvents {
+		e.NPCID = s.npcRef(e.NPCID)
+		if e.SetLocation != nil {
+			loc := s.locationRef(*e.SetLocation)
+			e.SetLocation = &loc
+		}
+		if e.SetFollowing != nil && *e.SetFollowing != "" && *e.SetFollowing != "pc" {
+			following := s.npcRef(*e.SetFollowing)
+			e.SetFollowing = &following
+		}
+		then.NPCEvents[i] = e
+	}
+	for i, e := range then.MonsterEvents {
+		if e.Location != "" {
+			then.MonsterEvents[i].Location = s.locationRef(e.Location)
+		}
+		then.MonsterEvents[i].Items = s.itemRefs(e.Items)
+	}
+	for i, id := range then.RemoveNPCs {
+		then.RemoveNPCs[i] = s.npcRef(id)
+	}
+	if then.StartCombat != nil {
+		for i, foe := range then.StartCombat.Foes {
+			then.StartCombat.Foes[i] = s.npcRef(foe) // monster instance IDs are left as written
+		}
+	}
+}
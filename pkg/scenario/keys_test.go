@@ -0,0 +1,281 @@
+package scenario
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"tortuga", "tortuga"},
+		{"Tortuga", "tortuga"},
+		{"Black Pearl", "black_pearl"},
+		{"  captains-cabin ", "captains_cabin"},
+		{"Captain's Cabin", "captains_cabin"},
+		{"Captain’s Cabin", "captains_cabin"},
+		{"deck__2", "deck_2"},
+		{"_hold_", "hold"},
+		{"Dr. Jekyll & Mr. Hyde", "dr_jekyll_mr_hyde"},
+		{"", ""},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeKey(tt.input); got != tt.want {
+			t.Errorf("NormalizeKey(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestScenario_Normalize(t *testing.T) {
+	location := "Tortuga Docks"
+	following := "Gibbs"
+	s := &Scenario{
+		OpeningLocation: "Black Pearl",
+		OpeningScene:    "Shipwreck",
+		Locations: map[string]Location{
+			"Black_Pearl":   {Name: "The Black Pearl", Exits: map[string]string{"ashore": "Tortuga Docks"}},
+			"tortuga_docks": {Name: "Tortuga Docks", Exits: map[string]string{"aboard": "the black pearl"}},
+		},
+		NPCs: map[string]actor.NPC{
+			"Gibbs":  {Name: "Joshamee Gibbs", Location: "Black Pearl"},
+			"parrot": {Name: "Cotton's Parrot", Following: "Gibbs"},
+		},
+		Scenes: map[string]Scene{
+			"shipwreck": {
+				Locations: map[string]Location{"Reef": {Name: "Coral Reef"}},
+				Conditionals: map[string]Conditional{
+					"rescue": {
+						When: conditionals.ConditionalWhen{Location: "Coral Reef"},
+						Then: conditionals.GameStateDelta{
+							UserLocation: "the black pearl",
+							NPCEvents:    []conditionals.NPCEvent{{NPCID: "Joshamee Gibbs", SetLocation: &location, SetFollowing: &following}},
+							RemoveNPCs:   []string{"cottons parrot", "nobody"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if collisions := s.Normalize(); len(collisions) != 0 {
+		t.Errorf("Normalize() collisions = %+v, want none", collisions)
+	}
+
+	if got := slices.Sorted(maps.Keys(s.Locations)); !reflect.DeepEqual(got, []string{"black_pearl", "tortuga_docks"}) {
+		t.Errorf("location keys = %v", got)
+	}
+	if got := slices.Sorted(maps.Keys(s.NPCs)); !reflect.DeepEqual(got, []string{"gibbs", "parrot"}) {
+		t.Errorf("NPC keys = %v", got)
+	}
+	if s.OpeningLocation != "black_pearl" || s.OpeningScene != "shipwreck" {
+		t.Errorf("opening location, scene = %q, %q", s.OpeningLocation, s.OpeningScene)
+	}
+	if got := s.Locations["black_pearl"].Exits["ashore"]; got != "tortuga_docks" {
+		t.Errorf("exit ashore = %q, want tortuga_docks", got)
+	}
+	if got := s.Locations["tortuga_docks"].Exits["aboard"]; got != "black_pearl" {
+		t.Errorf("exit aboard = %q, want black_pearl", got)
+	}
+	if got := s.NPCs["gibbs"].Location; got != "black_pearl" {
+		t.Errorf("gibbs location = %q, want black_pearl", got)
+	}
+	if got := s.NPCs["parrot"].Following; got != "gibbs" {
+		t.Errorf("parrot following = %q, want gibbs", got)
+	}
+
+	c := s.Scenes["shipwreck"].Conditionals["rescue"]
+	if c.When.Location != "reef" {
+		t.Errorf("when.location = %q, want reef", c.When.Location)
+	}
+	if c.Then.UserLocation != "black_pearl" {
+		t.Errorf("then.user_location = %q, want black_pearl", c.Then.UserLocation)
+	}
+	e := c.Then.NPCEvents[0]
+	if e.NPCID != "gibbs" || *e.SetLocation != "tortuga_docks" || *e.SetFollowing != "gibbs" {
+		t.Errorf("npc event = %q, %q, %q", e.NPCID, *e.SetLocation, *e.SetFollowing)
+	}
+	if !reflect.DeepEqual(c.Then.RemoveNPCs, []string{"parrot", "nobody"}) {
+		t.Errorf("remove_npcs = %v, want [parrot nobody]", c.Then.RemoveNPCs)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+		found bool
+	}{
+		{"BLACK PEARL", "black_pearl", true},
+		{"The Black Pearl", "black_pearl", true},
+		{"Coral Reef", "", false}, // scene locations aren't scenario locations
+		{"Pearl", "", false},
+	}
+	for _, tt := range tests {
+		if key, found := s.GetLocation(tt.input); key != tt.want || found != tt.found {
+			t.Errorf("GetLocation(%q) = %q, %v, want %q, %v", tt.input, key, found, tt.want, tt.found)
+		}
+	}
+	if key, found := s.GetNPC("joshamee gibbs"); key != "gibbs" || !found {
+		t.Errorf("GetNPC(joshamee gibbs) = %q, %v, want gibbs, true", key, found)
+	}
+}
+
+func TestScenario_Normalize_Collisions(t *testing.T) {
+	s := &Scenario{
+		Locations: map[string]Location{
+			"Dock":   {Name: "Old Dock"},
+			"dock":   {Name: "New Dock"},
+			"harbor": {Name: "Dock"},
+			"quay":   {Name: "New Dock"},
+		},
+	}
+
+	want := []KeyCollision{
+		{Kind: KeyKindLocation, Key: "dock", Keys: []string{"dock", "Dock"}},     // same key
+		{Kind: KeyKindLocation, Key: "dock", Keys: []string{"dock", "harbor"}},   // name "Dock"
+		{Kind: KeyKindLocation, Key: "new_dock", Keys: []string{"dock", "quay"}}, // same name
+	}
+	got := s.Normalize()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %+v, want %+v", got, want)
+	}
+	if s.Locations["dock"].Name != "New Dock" {
+		t.Errorf("kept location %q, want the one keyed in canonical form", s.Locations["dock"].Name)
+	}
+}
+
+func TestScenario_Normalize_IDs(t *testing.T) {
+	var found conditionals.GameStateDelta
+	if err := json.Unmarshal([]byte(`{"item_events": [{"item": "Old Key", "action": "acquire"}], "clear_inventory": [{"except": ["storm lantern"]}]}`), &found); err != nil {
+		t.Fatalf("Failed to unmarshal delta: %v", err)
+	}
+	s := &Scenario{
+		OpeningLocation:  "Captain's Quarters",
+		OpeningInventory: []string{"rusty key", "Compass"},
+		Locations: map[string]Location{
+			"captains_cabin": {ID: "cabin", Name: "Captain's Quarters", Items: []string{"Lantern"}},
+			"deck":           {Name: "Main Deck", Exits: map[string]string{"below": "captains_cabin"}},
+		},
+		NPCs: map[string]actor.NPC{
+			"gibbs": {ID: "first_mate", Name: "Joshamee Gibbs", Location: "captains_cabin"},
+		},
+		ItemCatalog: map[string]Item{
+			"rusty_key": {Name: "Rusty Key"},
+			"Lantern":   {ID: "ship_lantern", Name: "Storm Lantern"},
+		},
+		ItemDetails: map[string]string{"Rusty Key": "Flaked with rust."},
+		Renamed:     &Renames{Items: map[string]string{"Old Key": "rusty_key"}},
+		Scenes: map[string]Scene{
+			"storm": {
+				Locations: map[string]Location{"captains_cabin": {Name: "Flooded Cabin"}},
+				NPCs:      map[string]actor.NPC{"gibbs": {Name: "Soaked Gibbs"}},
+				Conditionals: map[string]Conditional{
+					"found": {Then: found},
+				},
+			},
+		},
+	}
+	if collisions := s.Normalize(); len(collisions) != 0 {
+		t.Fatalf("Unexpected collisions: %+v", collisions)
+	}
+
+	storm := s.Scenes["storm"]
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"location keys", slices.Sorted(maps.Keys(s.Locations)), []string{"cabin", "deck"}},
+		{"location id", s.Locations["deck"].ID, "deck"},
+		{"exit", s.Locations["deck"].Exits["below"], "cabin"},
+		{"opening location", s.OpeningLocation, "cabin"},
+		{"npc key", s.NPCs["first_mate"].Name, "Joshamee Gibbs"},
+		{"npc location", s.NPCs["first_mate"].Location, "cabin"},
+		{"scene location override", storm.Locations["cabin"].ID, "cabin"},
+		{"scene npc override", storm.NPCs["first_mate"].ID, "first_mate"},
+		{"catalog keys", slices.Sorted(maps.Keys(s.ItemCatalog)), []string{"rusty_key", "ship_lantern"}},
+		{"opening inventory", s.OpeningInventory, []string{"rusty_key", "Compass"}},
+		{"location items", s.Locations["cabin"].Items, []string{"ship_lantern"}},
+		{"item details", slices.Sorted(maps.Keys(s.ItemDetails)), []string{"rusty_key"}},
+		{"renamed item event", storm.Conditionals["found"].Then.ItemEvents[0].Item, "rusty_key"},
+		{"clear inventory", storm.Conditionals["found"].Then.ClearInventory[0].Except, []string{"ship_lantern"}},
+		{"item names", s.ItemNames(), map[string]string{"rusty_key": "Rusty Key", "ship_lantern": "Storm Lantern"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	for ref, want := range map[string]string{"Captain's Quarters": "cabin", "captains_cabin": "cabin", "Flooded Cabin": "cabin", "cabin": "cabin"} {
+		if got, ok := s.LocationID(ref); !ok || got != want {
+			t.Errorf("LocationID(%q) = %q, %v; want %q", ref, got, ok, want)
+		}
+	}
+	if got, ok := s.NPCID("gibbs"); !ok || got != "first_mate" {
+		t.Errorf("NPCID(gibbs) = %q, %v; want first_mate", got, ok)
+	}
+}
+
+func TestScenario_BackfillIDs(t *testing.T) {
+	s := &Scenario{
+		OpeningInventory: []string{"Rusty Key", "Compass"},
+		Inventory:        []string{"Gold Coin", "gold coin"},
+		Locations: map[string]Location{
+			"Captain's Cabin": {Name: "Captain's Cabin", Items: []string{"Lantern"}},
+			"deck":            {ID: "main_deck", Name: "Main Deck"},
+		},
+		NPCs:        map[string]actor.NPC{"gibbs": {Name: "Gibbs"}},
+		ItemCatalog: map[string]Item{"lantern": {Name: "Lantern"}},
+		Scenes: map[string]Scene{
+			"storm": {
+				Locations: map[string]Location{"deck": {Name: "Storm-Lashed Deck"}, "hold": {Name: "Hold"}},
+				NPCs:      map[string]actor.NPC{"gibbs": {Name: "Soaked Gibbs"}},
+			},
+		},
+	}
+
+	skipped := s.BackfillIDs()
+	if want := [][]string{{"Gold Coin", "gold coin"}}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("Skipped %v, want %v", skipped, want)
+	}
+
+	storm := s.Scenes["storm"]
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"location id", s.Locations["Captain's Cabin"].ID, "captains_cabin"},
+		{"explicit id", s.Locations["deck"].ID, "main_deck"},
+		{"npc id", s.NPCs["gibbs"].ID, "gibbs"},
+		{"scene override", storm.Locations["deck"].ID, "main_deck"},
+		{"scene location", storm.Locations["hold"].ID, "hold"},
+		{"scene npc", storm.NPCs["gibbs"].ID, "gibbs"},
+		{"catalog", s.ItemCatalog, map[string]Item{
+			"lantern":   {Name: "Lantern"},
+			"rusty_key": {Name: "Rusty Key"},
+			"compass":   {Name: "Compass"},
+		}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// The backfilled scenario still loads with the same keys
+	if collisions := s.Normalize(); len(collisions) != 0 {
+		t.Errorf("Unexpected collisions: %+v", collisions)
+	}
+	if _, ok := s.Locations["captains_cabin"]; !ok {
+		t.Errorf("Expected captains_cabin, got %v", slices.Sorted(maps.Keys(s.Locations)))
+	}
+}
@@ -0,0 +1,35 @@
+package scenario
+
+// NarrationLength bounds the narrator's replies, for scenarios paced faster than the
+// default. Zero fields are unset.
+type NarrationLength struct {
+	MaxTokens    int `json:"max_tokens,omitempty"`    // Output tokens the provider may generate per reply; a reply that hits it is cut mid-sentence
+	MaxSentences int `json:"max_sentences,omitempty"` // Sentences the narrator is asked for; the reply is cut after this many
+}
+
+// Override returns l with each field that o sets replaced by o's
+func (l NarrationLength) Override(o NarrationLength) NarrationLength {
+	if o.MaxTokens > 0 {
+		l.MaxTokens = o.MaxTokens
+	}
+	if o.MaxSentences > 0 {
+		l.MaxSentences = o.MaxSentences
+	}
+	return l
+}
+
+// SceneNarrationLength returns the narration length for a scene: the scenario's, with
+// each field the scene sets overriding it
+func (s *Scenario) SceneNarrationLength(sceneName string) NarrationLength {
+	var length NarrationLength
+	if s == nil {
+		return length
+	}
+	if s.NarrationLength != nil {
+		length = *s.NarrationLength
+	}
+	if scene, ok := s.Scenes[sceneName]; ok && scene.NarrationLength != nil {
+		length = length.Override(*scene.NarrationLength)
+	}
+	return length
+}
@@ -7,7 +7,8 @@ import (
 
 // Location represents a place in the game world with exits and entry logic.
 type Location struct {
-	Name               string                           `json:"name"`                          // Also the key in the map.
+	ID                 string                           `json:"id,omitempty"`                  // Stable ID that saved games and references use; defaults to the map key
+	Name               string                           `json:"name"`                          // Display name; can change freely
 	Description        string                           `json:"description,omitempty"`         // Scene description
 	Preview            string                           `json:"preview,omitempty"`             // Short summary shown for adjacent locations (prevents description bleed)
 	Exits              map[string]string                `json:"exits,omitempty"`               // Direction → Location Key
@@ -16,4 +17,18 @@ type Location struct {
 	Monsters           map[string]*actor.Monster        `json:"monsters,omitempty"`            // Active monster instances at this location (instance ID → Monster)
 	IsImportant        bool                             `json:"important,omitempty"`           // whether this location is important to always show
 	ContingencyPrompts []conditionals.ContingencyPrompt `json:"contingency_prompts,omitempty"` // Location-specific prompts shown when at player location
+	Ambient            []string                         `json:"ambient,omitempty"`             // Ambient details, one shown per turn in rotation while the player is here
+}
+
+// AmbientDetail returns the ambient detail to show on the given turn. The details
+// rotate with the turn counter, so a long stay doesn't repeat the same one every turn
+// and the prompt carries only one at a time.
+func (l Location) AmbientDetail(turn int) string {
+	if len(l.Ambient) == 0 {
+		return ""
+	}
+	if turn < 0 {
+		turn = -turn
+	}
+	return l.Ambient[turn%len(l.Ambient)]
 }
@@ -0,0 +1,29 @@
+package scenario
+
+import "testing"
+
+func TestLocation_AmbientDetail(t *testing.T) {
+	loc := Location{Ambient: []string{"Gulls cry overhead.", "Rigging creaks.", "A bell clangs on the harbor."}}
+
+	tests := []struct {
+		name string
+		loc  Location
+		turn int
+		want string
+	}{
+		{"first turn", loc, 0, "Gulls cry overhead."},
+		{"next turn rotates", loc, 1, "Rigging creaks."},
+		{"wraps around", loc, 3, "Gulls cry overhead."},
+		{"later turn", loc, 8, "A bell clangs on the harbor."},
+		{"negative turn", loc, -1, "Rigging creaks."},
+		{"no ambient details", Location{}, 4, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loc.AmbientDetail(tt.turn); got != tt.want {
+				t.Errorf("AmbientDetail(%d) = %q, want %q", tt.turn, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package scenario
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LoreEntry is a piece of world lore. It is given to the narrator only on turns where
+// one of its keywords comes up, and players can read the entries they have come
+// across in the codex.
+type LoreEntry struct {
+	Title    string   `json:"title"`
+	Keywords []string `json:"keywords"` // Words or phrases that bring up the entry, matched as whole words ignoring case
+	Text     string   `json:"text"`
+}
+
+// MatchLore returns the IDs of lore entries with a keyword in any of texts, sorted
+func (s *Scenario) MatchLore(texts ...string) []string {
+	if len(s.Lore) == 0 {
+		return nil
+	}
+	lowered := make([]string, len(texts))
+	for i, t := range texts {
+		lowered[i] = strings.ToLower(t)
+	}
+
+	var ids []string
+	for _, id := range slices.Sorted(maps.Keys(s.Lore)) {
+		if s.Lore[id].matches(lowered) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// matches reports whether a keyword appears in any of the lowercased texts
+func (e LoreEntry) matches(texts []string) bool {
+	for _, kw := range e.Keywords {
+		kw = strings.ToLower(strings.TrimSpace(kw))
+		if kw == "" {
+			continue
+		}
+		for _, t := range texts {
+			if ContainsWord(t, kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ContainsWord reports whether word appears in text with no letter or digit directly
+// before or after it, so "ash" matches "the ash falls" but not "splash"
+func ContainsWord(text, word string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (i == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,39 @@
+package scenario
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScenario_MatchLore(t *testing.T) {
+	s := &Scenario{
+		Lore: map[string]LoreEntry{
+			"black_pearl": {Title: "The Black Pearl", Keywords: []string{"Black Pearl", "pearl"}},
+			"ash":         {Title: "Ash Fall", Keywords: []string{"ash"}},
+			"kraken":      {Title: "The Kraken", Keywords: []string{"kraken"}},
+			"blank":       {Title: "Blank", Keywords: []string{" "}},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		texts []string
+		want  []string
+	}{
+		{"no match", []string{"The tide rolls in."}, nil},
+		{"phrase ignoring case", []string{"You see the BLACK PEARL on the horizon."}, []string{"black_pearl"}},
+		{"whole words only", []string{"A splash, then pearls of foam."}, nil},
+		{"word at end of text", []string{"Grey ash"}, []string{"ash"}},
+		{"punctuation boundary", []string{"Ash, everywhere."}, []string{"ash"}},
+		{"several texts", []string{"The kraken stirs.", "Ash drifts down."}, []string{"ash", "kraken"}},
+		{"no texts", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.MatchLore(tt.texts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchLore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,31 @@
 package scenario
 
+import "strings"
+
 // Narrator defines the voice and style of the game narrator
 type Narrator struct {
 	ID          string   `json:"id"`                    // Unique identifier (e.g., "vincent_price", "classic", "comedic")
 	Name        string   `json:"name"`                  // Display name
 	Description string   `json:"description,omitempty"` // What this narrator style is like (not used in prompts)
+	Tone        string   `json:"tone,omitempty"`        // Short tone label for browsing, e.g. "lighthearted", "grim" (not used in prompts)
+	Ratings     []string `json:"ratings,omitempty"`     // Scenario content ratings this narrator suits; empty suits all
 	Prompts     []string `json:"prompts"`               // Voice and style instructions injected into the system prompt
 	Rules       []string `json:"rules,omitempty"`       // Per-turn constraints injected into the <rules> block after every user message
 }
 
+// SuitsRating reports whether the narrator suits scenarios with the given content rating
+func (n *Narrator) SuitsRating(rating string) bool {
+	if len(n.Ratings) == 0 {
+		return true
+	}
+	for _, r := range n.Ratings {
+		if strings.EqualFold(r, rating) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetPromptsAsString returns all narrator prompts joined with newlines and bullet points
 func (n *Narrator) GetPromptsAsString() string {
 	if len(n.Prompts) == 0 {
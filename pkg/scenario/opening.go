@@ -0,0 +1,18 @@
+package scenario
+
+// OpeningSequence defines a multi-message introduction rendered at game creation.
+// Messages appear in this order: scripted Messages, the optional personalized
+// intro generated by the LLM, then the optional Tutorial message.
+type OpeningSequence struct {
+	Messages          []string `json:"messages,omitempty"`           // Pre-scripted narrator messages, shown in order
+	PersonalizedIntro string   `json:"personalized_intro,omitempty"` // Instructions for an LLM-generated intro tailored to the PC
+	Tutorial          string   `json:"tutorial,omitempty"`           // Optional tutorial message explaining how to play
+}
+
+// HasContent returns true if the sequence defines at least one message
+func (o *OpeningSequence) HasContent() bool {
+	if o == nil {
+		return false
+	}
+	return len(o.Messages) > 0 || o.PersonalizedIntro != "" || o.Tutorial != ""
+}
@@ -0,0 +1,160 @@
+package scenario
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
+)
+
+// ratingOrder lists the scenario ratings from strictest to mildest
+var ratingOrder = []string{RatingG, RatingPG, RatingPG13, RatingR}
+
+// RatingLevel returns the rating's place in G, PG, PG-13, R, from 0. An empty rating is
+// played as PG-13. Unknown ratings return -1.
+func RatingLevel(rating string) int {
+	rating = strings.ToUpper(strings.TrimSpace(rating))
+	if rating == "" || rating == "PG13" {
+		rating = RatingPG13
+	}
+	return slices.Index(ratingOrder, rating)
+}
+
+// RatingFinding is a filtered word found in a scenario's text
+type RatingFinding struct {
+	Field     string `json:"field"`      // Where the word is, e.g. "scenes.harbor.story"
+	Word      string `json:"word"`       // The word as listed by the profanity filter
+	MinRating string `json:"min_rating"` // Strictest rating that allows the word
+}
+
+// RatingAnalysis compares a scenario's declared rating with the ratings its text allows
+type RatingAnalysis struct {
+	Rating    string          `json:"rating"`             // The declared rating, PG-13 if unset
+	Suggested string          `json:"suggested"`          // Strictest rating that allows all of the scenario's text
+	Findings  []RatingFinding `json:"findings,omitempty"` // Words that rule out stricter ratings
+}
+
+// Conflicts reports whether the declared rating is stricter than the text allows
+func (a RatingAnalysis) Conflicts() bool {
+	return RatingLevel(a.Rating) < RatingLevel(a.Suggested)
+}
+
+// Violations returns the findings the declared rating doesn't allow
+func (a RatingAnalysis) Violations() []RatingFinding {
+	var out []RatingFinding
+	for _, f := range a.Findings {
+		if RatingLevel(f.MinRating) > RatingLevel(a.Rating) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// AnalyzeRating checks the scenario's text against the filter's word lists and suggests
+// the strictest rating that allows it. Only words are checked, so a clean result doesn't
+// rule out mature themes.
+func (s *Scenario) AnalyzeRating(filter *textfilter.ProfanityFilter) RatingAnalysis {
+	a := RatingAnalysis{Rating: s.Rating, Suggested: RatingG}
+	if a.Rating == "" {
+		a.Rating = RatingPG13
+	}
+	for _, t := range s.Texts() {
+		for _, w := range filter.Flagged(t.Text) {
+			rating := ratingOrder[RatingLevel(w.MinRating)]
+			a.Findings = append(a.Findings, RatingFinding{Field: t.Field, Word: w.Word, MinRating: rating})
+			if RatingLevel(rating) > RatingLevel(a.Suggested) {
+				a.Suggested = rating
+			}
+		}
+	}
+	return a
+}
+
+// ScenarioText is a piece of author-written text shown to the narrator or the player
+type ScenarioText struct {
+	Field string // Where the text is, e.g. "locations.dock.description"
+	Text  string
+}
+
+// Texts returns the scenario's author-written text, in a stable order
+func (s *Scenario) Texts() []ScenarioText {
+	var out []ScenarioText
+	add := func(field, text string) {
+		if strings.TrimSpace(text) != "" {
+			out = append(out, ScenarioText{Field: field, Text: text})
+		}
+	}
+	addList := func(field string, texts []string) {
+		for i, text := range texts {
+			add(fmt.Sprintf("%s[%d]", field, i), text)
+		}
+	}
+
+	add("name", s.Name)
+	add("story", s.Story)
+	add("synopsis", s.Synopsis)
+	add("opening_prompt", s.OpeningPrompt)
+	if s.OpeningSequence != nil {
+		addList("opening_sequence.messages", s.OpeningSequence.Messages)
+		add("opening_sequence.personalized_intro", s.OpeningSequence.PersonalizedIntro)
+		add("opening_sequence.tutorial", s.OpeningSequence.Tutorial)
+	}
+	add("game_end_prompt", s.GameEndPrompt)
+	addList("rules", s.Rules)
+	addContingencyPrompts(add, "contingency_prompts", s.ContingencyPrompts)
+	addList("contingency_rules", s.ContingencyRules)
+	for _, item := range slices.Sorted(maps.Keys(s.ItemDetails)) {
+		add("item_details."+item, s.ItemDetails[item])
+	}
+	for _, id := range slices.Sorted(maps.Keys(s.Lore)) {
+		add("lore."+id+".title", s.Lore[id].Title)
+		add("lore."+id+".text", s.Lore[id].Text)
+	}
+	addLocationTexts(add, addList, "locations", s.Locations)
+	addNPCTexts(add, "npcs", s.NPCs)
+
+	for _, name := range slices.Sorted(maps.Keys(s.Scenes)) {
+		scene := s.Scenes[name]
+		prefix := "scenes." + name
+		add(prefix+".story", scene.Story)
+		add(prefix+".opening_prompt", scene.OpeningPrompt)
+		addContingencyPrompts(add, prefix+".contingency_prompts", scene.ContingencyPrompts)
+		addList(prefix+".contingency_rules", scene.ContingencyRules)
+		addList(prefix+".hints", scene.Hints)
+		addLocationTexts(add, addList, prefix+".locations", scene.Locations)
+		addNPCTexts(add, prefix+".npcs", scene.NPCs)
+		for _, id := range slices.Sorted(maps.Keys(scene.Conditionals)) {
+			if p := scene.Conditionals[id].Then.Prompt; p != nil {
+				add(prefix+".conditionals."+id+".then.prompt", *p)
+			}
+		}
+	}
+	return out
+}
+
+func addContingencyPrompts(add func(field, text string), field string, prompts []conditionals.ContingencyPrompt) {
+	for i, p := range prompts {
+		add(fmt.Sprintf("%s[%d]", field, i), p.Prompt)
+	}
+}
+
+func addLocationTexts(add func(field, text string), addList func(field string, texts []string), field string, locations map[string]Location) {
+	for _, key := range slices.Sorted(maps.Keys(locations)) {
+		loc := locations[key]
+		prefix := field + "." + key
+		add(prefix+".description", loc.Description)
+		add(prefix+".preview", loc.Preview)
+		addList(prefix+".ambient", loc.Ambient)
+		addContingencyPrompts(add, prefix+".contingency_prompts", loc.ContingencyPrompts)
+	}
+}
+
+func addNPCTexts(add func(field, text string), field string, npcs map[string]actor.NPC) {
+	for _, key := range slices.Sorted(maps.Keys(npcs)) {
+		add(field+"."+key+".description", npcs[key].Description)
+	}
+}
@@ -0,0 +1,83 @@
+package scenario
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
+)
+
+func TestRatingLevel(t *testing.T) {
+	tests := []struct {
+		rating string
+		want   int
+	}{
+		{RatingG, 0},
+		{RatingPG, 1},
+		{RatingPG13, 2},
+		{"pg13", 2},
+		{"", 2},
+		{RatingR, 3},
+		{"NC-17", -1},
+	}
+	for _, tt := range tests {
+		if got := RatingLevel(tt.rating); got != tt.want {
+			t.Errorf("RatingLevel(%q) = %d, want %d", tt.rating, got, tt.want)
+		}
+	}
+}
+
+func TestScenario_AnalyzeRating(t *testing.T) {
+	prompt := "The captain swears: shit!"
+	s := &Scenario{
+		Rating: RatingPG,
+		Story:  "A quiet voyage.",
+		Locations: map[string]Location{
+			"dock": {Description: "A damn fine dock."},
+		},
+		Scenes: map[string]Scene{
+			"storm": {
+				NPCs: map[string]actor.NPC{"mate": {Description: "A gruff mate."}},
+				Conditionals: map[string]Conditional{
+					"curse": {Then: conditionals.GameStateDelta{Prompt: &prompt}},
+				},
+			},
+		},
+	}
+	filter := textfilter.NewProfanityFilter()
+
+	got := s.AnalyzeRating(filter)
+	want := RatingAnalysis{
+		Rating:    RatingPG,
+		Suggested: RatingR,
+		Findings: []RatingFinding{
+			{Field: "locations.dock.description", Word: "damn", MinRating: RatingPG13},
+			{Field: "scenes.storm.conditionals.curse.then.prompt", Word: "shit", MinRating: RatingR},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("AnalyzeRating() = %+v, want %+v", got, want)
+	}
+	if !got.Conflicts() {
+		t.Errorf("Conflicts() = false, want true")
+	}
+	if v := got.Violations(); len(v) != 2 {
+		t.Errorf("Violations() = %v, want both findings", v)
+	}
+
+	s.Rating = ""
+	got = s.AnalyzeRating(filter)
+	if got.Rating != RatingPG13 {
+		t.Errorf("unset rating analyzed as %q, want %q", got.Rating, RatingPG13)
+	}
+	if v := got.Violations(); len(v) != 1 || v[0].Word != "shit" {
+		t.Errorf("Violations() at PG-13 = %v, want only 'shit'", v)
+	}
+
+	s.Rating = RatingR
+	if s.AnalyzeRating(filter).Conflicts() {
+		t.Errorf("Conflicts() at R = true, want false")
+	}
+}
@@ -0,0 +1,111 @@
+package scenario
+
+import (
+	"maps"
+	"slices"
+)
+
+// Renames records the location, NPC, and item IDs a scenario used to have, old → new.
+// A scenario that changes an ID keeps the old one here, so games saved against the old
+// ID are migrated when they're next loaded (see state.GameState.MigrateIDs) and
+// conditionals still written against it resolve to the new one.
+//
+// Display names, and the keys of entries with an id field, can change freely without
+// an entry here. An item that isn't in the scenario's item catalog is its own ID, so
+// renaming one needs an entry, or it's lost from saved games.
+type Renames struct {
+	Locations map[string]string `json:"locations,omitempty"` // Old location ID → new location ID
+	NPCs      map[string]string `json:"npcs,omitempty"`      // Old NPC ID → new NPC ID
+	Items     map[string]string `json:"items,omitempty"`     // Old item ID or name → new item ID or name
+}
+
+// IsEmpty reports whether there's nothing to rename
+func (r *Renames) IsEmpty() bool {
+	return r == nil || len(r.Locations) == 0 && len(r.NPCs) == 0 && len(r.Items) == 0
+}
+
+// Location returns the current ID for a location ID, following chains of renames.
+// IDs that weren't renamed are returned unchanged.
+func (r *Renames) Location(key string) string {
+	if r == nil {
+		return key
+	}
+	return followRename(r.Locations, key)
+}
+
+// NPC returns the current ID for an NPC ID, following chains of renames
+func (r *Renames) NPC(key string) string {
+	if r == nil {
+		return key
+	}
+	return followRename(r.NPCs, key)
+}
+
+// Item returns the current ID or name for an item, following chains of renames
+func (r *Renames) Item(name string) string {
+	if r == nil {
+		return name
+	}
+	return followRename(r.Items, name)
+}
+
+// followRename follows old → new through m. A cycle stops at the last name before it
+// repeats; the validator reports cycles.
+func followRename(m map[string]string, name string) string {
+	seen := map[string]bool{name: true}
+	for {
+		next, ok := m[name]
+		if !ok || next == "" || seen[next] {
+			return name
+		}
+		seen[next] = true
+		name = next
+	}
+}
+
+// Cycles returns the old names in each map whose renames run into a cycle, sorted,
+// keyed by KeyKindLocation, KeyKindNPC, or KeyKindItem
+func (r *Renames) Cycles() map[string][]string {
+	if r == nil {
+		return nil
+	}
+	out := make(map[string][]string)
+	for kind, m := range map[string]map[string]string{KeyKindLocation: r.Locations, KeyKindNPC: r.NPCs, KeyKindItem: r.Items} {
+		for _, old := range slices.Sorted(maps.Keys(m)) {
+			seen := map[string]bool{old: true}
+			for name, ok := m[old]; ok; name, ok = m[name] {
+				if seen[name] {
+					out[kind] = append(out[kind], old)
+					break
+				}
+				seen[name] = true
+			}
+		}
+	}
+	return out
+}
+
+// normalize puts the location and NPC IDs on both sides of each rename in canonical
+// form. Items are left as written, since only catalogued items are matched in
+// canonical form; see Scenario.ItemID.
+func (r *Renames) normalize() {
+	if r == nil {
+		return
+	}
+	r.Locations = normalizeRenameKeys(r.Locations)
+	r.NPCs = normalizeRenameKeys(r.NPCs)
+}
+
+func normalizeRenameKeys(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for _, old := range slices.Sorted(maps.Keys(m)) {
+		key := NormalizeKey(old)
+		if _, ok := out[key]; !ok {
+			out[key] = NormalizeKey(m[old])
+		}
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package scenario
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
+
+func TestRenames_Follow(t *testing.T) {
+	r := &Renames{
+		Locations: map[string]string{"dock": "pier", "pier": "harbor"},
+		NPCs:      map[string]string{"a": "b", "b": "a"},
+		Items:     map[string]string{"Rusty Key": "Iron Key"},
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"chain", r.Location("dock"), "harbor"},
+		{"not renamed", r.Location("tortuga"), "tortuga"},
+		{"cycle stops", r.NPC("a"), "b"},
+		{"item", r.Item("Rusty Key"), "Iron Key"},
+		{"nil renames", (*Renames)(nil).Location("dock"), "dock"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	want := map[string][]string{KeyKindNPC: {"a", "b"}}
+	if got := r.Cycles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles() = %v, want %v", got, want)
+	}
+}
+
+func TestScenario_Normalize_Renamed(t *testing.T) {
+	s := &Scenario{
+		OpeningLocation: "Old Dock",
+		Locations: map[string]Location{
+			"harbor": {Name: "Harbor", Exits: map[string]string{"north": "market"}},
+			"market": {Name: "Market", Exits: map[string]string{"south": "old_dock"}},
+		},
+		NPCs: map[string]actor.NPC{"joshamee": {Name: "Joshamee Gibbs"}},
+		Renamed: &Renames{
+			Locations: map[string]string{"Old Dock": "harbor"},
+			NPCs:      map[string]string{"gibbs": "joshamee"},
+			Items:     map[string]string{"Rusty Key": "Iron Key"},
+		},
+		Scenes: map[string]Scene{
+			"start": {
+				Conditionals: map[string]Conditional{
+					"arrive": {
+						When: conditionals.ConditionalWhen{Location: "old_dock"},
+						Then: conditionals.GameStateDelta{
+							RemoveNPCs:     []string{"gibbs"},
+							ClearInventory: []conditionals.InventoryFilter{{Items: []string{"Rusty Key"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+	s.Normalize()
+
+	if s.OpeningLocation != "harbor" {
+		t.Errorf("OpeningLocation = %q, want harbor", s.OpeningLocation)
+	}
+	if got := s.Locations["market"].Exits["south"]; got != "harbor" {
+		t.Errorf("market exit = %q, want harbor", got)
+	}
+	c := s.Scenes["start"].Conditionals["arrive"]
+	if c.When.Location != "harbor" {
+		t.Errorf("When.Location = %q, want harbor", c.When.Location)
+	}
+	if c.Then.RemoveNPCs[0] != "joshamee" {
+		t.Errorf("RemoveNPCs = %v, want [joshamee]", c.Then.RemoveNPCs)
+	}
+	if c.Then.ClearInventory[0].Items[0] != "Iron Key" {
+		t.Errorf("ClearInventory = %v, want Iron Key", c.Then.ClearInventory)
+	}
+	if s.Renamed.Location("old_dock") != "harbor" {
+		t.Errorf("Expected renamed location keys to be normalized, got %v", s.Renamed.Locations)
+	}
+}
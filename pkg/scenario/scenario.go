@@ -1,7 +1,7 @@
 package scenario
 
 import (
-	"strings"
+	"time"
 
 	"github.com/jwebster45206/story-engine/pkg/actor"
 	"github.com/jwebster45206/story-engine/pkg/conditionals"
@@ -12,15 +12,27 @@ type Scenario struct {
 	Name             string               `json:"name"`                        // Name of the scenario
 	FileName         string               `json:"file_name,omitempty"`         // Name of the file containing the scenario
 	Story            string               `json:"story,omitempty"`             // Brief description of the scenario
+	Synopsis         string               `json:"synopsis,omitempty"`          // Short player-facing pitch shown when browsing scenarios
 	Rating           string               `json:"rating,omitempty"`            // Content rating of the scenario
+	Tags             []string             `json:"tags,omitempty"`              // Genre and theme tags for browsing, e.g. "horror", "sci-fi"
+	Author           string               `json:"author,omitempty"`            // Scenario author
+	Version          string               `json:"version,omitempty"`           // Scenario version, e.g. "1.2.0"
+	EstimatedTurns   int                  `json:"estimated_turns,omitempty"`   // Rough number of turns to finish the scenario
 	NarratorID       string               `json:"narrator_id,omitempty"`       // Default narrator for this scenario
 	DefaultPC        string               `json:"default_pc,omitempty"`        // Default PC for this scenario
 	Temperature      *float64             `json:"temperature,omitempty"`       // LLM temperature (0.0–1.0); lower = on-rails, higher = creative
+	NarrationLength  *NarrationLength     `json:"narration_length,omitempty"`  // Bounds on each narration's length; see NarrationLength
+	ChoicesMode      bool                 `json:"choices_mode,omitempty"`      // Default for suggesting next actions after each narration turn
+	Aliases          map[string]string    `json:"aliases,omitempty"`           // Input shortcuts added to chat.DefaultAliases, e.g. "xyzzy": "say the magic word"
 	Locations        map[string]Location  `json:"locations,omitempty"`         // Map of location names to Location objects
+	ItemCatalog      map[string]Item      `json:"items,omitempty"`             // Items with a stable ID, by ID; see Item
 	Inventory        []string             `json:"inventory,omitempty"`         // Potential inventory items throughout the scenario
+	ItemDetails      map[string]string    `json:"item_details,omitempty"`      // Player-facing item descriptions, keyed by item ID or name; shown by /examine
+	Lore             map[string]LoreEntry `json:"lore,omitempty"`              // World lore by entry ID; see LoreEntry
 	NPCs             map[string]actor.NPC `json:"npcs,omitempty"`              // Map of NPC names to their data
-	Scenes           map[string]Scene     `json:"scenes"`                      // Map of scene names to Scene objectsOpeningPrompt    string              `json:"opening_prompt,omitempty"`    // Initial prompt to start the scenario
+	Scenes           map[string]Scene     `json:"scenes"`                      // Map of scene names to Scene objects
 	OpeningPrompt    string               `json:"opening_prompt,omitempty"`    // Initial prompt to start the scenario
+	OpeningSequence  *OpeningSequence     `json:"opening_sequence,omitempty"`  // Optional multi-message intro shown after the opening prompt
 	OpeningLocation  string               `json:"opening_location,omitempty"`  // Initial location for the user
 	OpeningInventory []string             `json:"opening_inventory,omitempty"` // Initial inventory items for the user
 	OpeningScene     string               `json:"opening_scene"`               // Which scene to start with
@@ -29,6 +41,18 @@ type Scenario struct {
 	ContingencyPrompts []conditionals.ContingencyPrompt `json:"contingency_prompts,omitempty"` // Conditional prompts for LLM
 	ContingencyRules   []string                         `json:"contingency_rules,omitempty"`   // Backend rules for LLM to follow
 	GameEndPrompt      string                           `json:"game_end_prompt,omitempty"`     // Optional instructions for writing a game ending
+	Rules              []string                         `json:"rules,omitempty"`               // Per-turn reminders added to the <rules> block after every user message
+	RulesOrder         []string                         `json:"rules_order,omitempty"`         // Order of the <rules> block's sections; see prompts.DefaultRulesOrder
+
+	Renamed *Renames `json:"renamed,omitempty"` // Keys and item names this scenario used to have, for migrating saved games; see Renames
+
+	PromptStateVersion int `json:"prompt_state_version,omitempty"` // Pins the game state's shape in prompts to this version, 0 for the latest; see prompts.PromptStateVersion
+
+	UpdatedAt time.Time `json:"-"` // When the scenario file was last modified; set by storage, not part of the file
+
+	locationNames map[string]string // Canonical location keys and display names → location key; see Normalize
+	npcNames      map[string]string // Canonical NPC keys and display names → NPC key; see Normalize
+	itemNames     map[string]string // Canonical item IDs and display names → item ID; see Normalize
 }
 
 const (
@@ -47,42 +71,16 @@ func (s *Scenario) HasScene(sceneName string) bool {
 	return exists
 }
 
-// GetLocation searches for a location by either its key (ID) or its display name
+// GetLocation searches for a location by either its key (ID) or its display name,
+// compared in canonical form; see NormalizeKey.
 // Returns the location key if found, and a boolean indicating success
 func (s *Scenario) GetLocation(keyOrName string) (string, bool) {
-	keyOrName = strings.ToLower(strings.TrimSpace(keyOrName))
-	if keyOrName == "" {
-		return "", false
-	}
-	// match exact key
-	if _, exists := s.Locations[keyOrName]; exists {
-		return keyOrName, true
-	}
-	// location name match
-	for key, loc := range s.Locations {
-		if strings.ToLower(loc.Name) == keyOrName {
-			return key, true
-		}
-	}
-	return "", false
+	return lookupKey(s.Locations, s.locationNames, keyOrName, func(l Location) string { return l.Name })
 }
 
-// GetNPC searches for an NPC by either its key (ID) or its display name
+// GetNPC searches for an NPC by either its key (ID) or its display name, compared in
+// canonical form; see NormalizeKey.
 // Returns the NPC key if found, and a boolean indicating success
 func (s *Scenario) GetNPC(keyOrName string) (string, bool) {
-	keyOrName = strings.ToLower(strings.TrimSpace(keyOrName))
-	if keyOrName == "" {
-		return "", false
-	}
-	// match exact key
-	if _, exists := s.NPCs[keyOrName]; exists {
-		return keyOrName, true
-	}
-	// NPC name match
-	for key, npc := range s.NPCs {
-		if strings.ToLower(npc.Name) == keyOrName {
-			return key, true
-		}
-	}
-	return "", false
+	return lookupKey(s.NPCs, s.npcNames, keyOrName, func(n actor.NPC) string { return n.Name })
 }
@@ -630,3 +630,83 @@ func TestScene_TemperatureField(t *testing.T) {
 		}
 	})
 }
+
+func TestScenario_SceneNarrationLength(t *testing.T) {
+	const raw = `{
+		"narration_length": {"max_tokens": 250, "max_sentences": 5},
+		"scenes": {
+			"chase": {"story": "run", "narration_length": {"max_sentences": 2}},
+			"calm": {"story": "rest"}
+		}
+	}`
+	var s Scenario
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+
+	tests := []struct {
+		scene string
+		want  NarrationLength
+	}{
+		{scene: "chase", want: NarrationLength{MaxTokens: 250, MaxSentences: 2}},
+		{scene: "calm", want: NarrationLength{MaxTokens: 250, MaxSentences: 5}},
+		{scene: "", want: NarrationLength{MaxTokens: 250, MaxSentences: 5}},
+	}
+	for _, tt := range tests {
+		if got := s.SceneNarrationLength(tt.scene); got != tt.want {
+			t.Errorf("SceneNarrationLength(%q) = %+v, want %+v", tt.scene, got, tt.want)
+		}
+	}
+
+	var empty *Scenario
+	if got := empty.SceneNarrationLength("chase"); got != (NarrationLength{}) {
+		t.Errorf("nil scenario SceneNarrationLength() = %+v, want unset", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Metadata summary tests
+// ---------------------------------------------------------------------------
+
+func TestScenario_Summary(t *testing.T) {
+	tests := []struct {
+		name         string
+		scenario     Scenario
+		wantSynopsis string
+	}{
+		{
+			name: "uses synopsis when set",
+			scenario: Scenario{
+				Name:           "Castle",
+				Story:          "A long prompt-facing description.",
+				Synopsis:       "Storm the castle.",
+				Rating:         RatingPG,
+				Tags:           []string{"horror"},
+				Author:         "Jane Doe",
+				Version:        "1.2.0",
+				EstimatedTurns: 40,
+			},
+			wantSynopsis: "Storm the castle.",
+		},
+		{
+			name:         "falls back to story",
+			scenario:     Scenario{Name: "Castle", Story: "A long prompt-facing description."},
+			wantSynopsis: "A long prompt-facing description.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.scenario.Summary("castle.json")
+			if got.FileName != "castle.json" || got.Name != tt.scenario.Name {
+				t.Errorf("unexpected name/file: %q %q", got.Name, got.FileName)
+			}
+			if got.Synopsis != tt.wantSynopsis {
+				t.Errorf("expected synopsis %q, got %q", tt.wantSynopsis, got.Synopsis)
+			}
+			if got.Author != tt.scenario.Author || got.Version != tt.scenario.Version || got.EstimatedTurns != tt.scenario.EstimatedTurns {
+				t.Errorf("metadata not copied: %+v", got)
+			}
+		})
+	}
+}
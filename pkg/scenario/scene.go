@@ -7,18 +7,33 @@ import (
 
 // Scene represents a single scene within a scenario with its own locations, NPCs, and rules
 type Scene struct {
-	Story              string                           `json:"story"`                  // Description of what happens in this scene
-	Temperature        *float64                         `json:"temperature,omitempty"`  // LLM temperature override for this scene (0.0–1.0); overrides scenario-level setting
-	Locations          map[string]Location              `json:"locations"`              // Map of location names to Location objects for this scene
-	NPCs               map[string]actor.NPC             `json:"npcs"`                   // Map of NPC names to their data for this scene
-	Vars               map[string]string                `json:"vars"`                   // Scene-specific variables
-	ContingencyPrompts []conditionals.ContingencyPrompt `json:"contingency_prompts"`    // Conditional prompts for LLM in this scene
-	ContingencyRules   []string                         `json:"contingency_rules"`      // Backend rules for LLM to follow in this scene
-	Conditionals       map[string]Conditional           `json:"conditionals,omitempty"` // Deterministic when/then rules (key = conditional ID)
+	Story              string                           `json:"story"`                      // Description of what happens in this scene
+	OpeningPrompt      string                           `json:"opening_prompt,omitempty"`   // Narrated as a story event the first time the scene is entered mid-game
+	Temperature        *float64                         `json:"temperature,omitempty"`      // LLM temperature override for this scene (0.0–1.0); overrides scenario-level setting
+	NarrationLength    *NarrationLength                 `json:"narration_length,omitempty"` // Narration length override for this scene; each field set overrides the scenario's
+	Locations          map[string]Location              `json:"locations"`                  // Map of location names to Location objects for this scene
+	NPCs               map[string]actor.NPC             `json:"npcs"`                       // Map of NPC names to their data for this scene
+	Vars               map[string]string                `json:"vars"`                       // Scene-specific variables
+	ContingencyPrompts []conditionals.ContingencyPrompt `json:"contingency_prompts"`        // Conditional prompts for LLM in this scene
+	ContingencyRules   []string                         `json:"contingency_rules"`          // Backend rules for LLM to follow in this scene
+	Conditionals       map[string]Conditional           `json:"conditionals,omitempty"`     // Deterministic when/then rules (key = conditional ID)
+	Hints              []string                         `json:"hints,omitempty"`            // Hints for players who are stuck, from vaguest to most direct
+	HintBudget         int                              `json:"hint_budget,omitempty"`      // Hints a player may ask for in this scene; see SceneHints. None when negative
 }
 
 // Conditional represents a deterministic rule to execute when conditions are met
 type Conditional struct {
-	When conditionals.ConditionalWhen `json:"when"` // Conditions that must be met
-	Then conditionals.GameStateDelta  `json:"then"` // Actions to execute when conditions are met
+	When     conditionals.ConditionalWhen `json:"when"`               // Conditions that must be met
+	Then     conditionals.GameStateDelta  `json:"then"`               // Actions to execute when conditions are met
+	Priority int                          `json:"priority,omitempty"` // Higher priority wins conflicts with other conditionals firing on the same turn
+	Group    string                       `json:"group,omitempty"`    // Exclusivity group; only the highest-priority triggered conditional in a group fires
+	Fire     string                       `json:"fire,omitempty"`     // Fire policy: FireOnce, FireOncePerScene, or FireRepeatable (default)
+	Cooldown int                          `json:"cooldown,omitempty"` // Repeatable only: turns to skip after firing before it can fire again
 }
+
+// Conditional fire policies. A conditional never fires twice in the same turn.
+const (
+	FireRepeatable   = "repeatable"     // Fires on every turn its When matches, subject to Cooldown
+	FireOnce         = "once"           // Fires at most once per game
+	FireOncePerScene = "once_per_scene" // Fires at most once each time its scene is entered
+)
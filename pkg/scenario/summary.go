@@ -0,0 +1,35 @@
+package scenario
+
+import "time"
+
+// ScenarioSummary is the browsing metadata for a scenario, without its game content
+type ScenarioSummary struct {
+	Name           string    `json:"name"`
+	FileName       string    `json:"file_name"`
+	Synopsis       string    `json:"synopsis,omitempty"` // Falls back to the story when no synopsis is set
+	Rating         string    `json:"rating,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	Author         string    `json:"author,omitempty"`
+	Version        string    `json:"version,omitempty"`
+	EstimatedTurns int       `json:"estimated_turns,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitzero"` // When the scenario file was last modified
+}
+
+// Summary returns the browsing metadata for the scenario stored in fileName
+func (s *Scenario) Summary(fileName string) ScenarioSummary {
+	synopsis := s.Synopsis
+	if synopsis == "" {
+		synopsis = s.Story
+	}
+	return ScenarioSummary{
+		Name:           s.Name,
+		FileName:       fileName,
+		Synopsis:       synopsis,
+		Rating:         s.Rating,
+		Tags:           s.Tags,
+		Author:         s.Author,
+		Version:        s.Version,
+		EstimatedTurns: s.EstimatedTurns,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
@@ -0,0 +1,119 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// jsonLines maps each JSON path in a document to the line its value is on, for paths
+// in the form Finding.Path uses
+type jsonLines struct {
+	data  []byte
+	lines map[string]int
+}
+
+// indexLines indexes a document's paths. A document that isn't valid JSON indexes as
+// far as it parses.
+func indexLines(data []byte) *jsonLines {
+	idx := &jsonLines{data: data, lines: make(map[string]int)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	_ = idx.walk(dec, "", idx.lineAt(idx.nextToken(0)))
+	return idx
+}
+
+// walk indexes the value at the decoder's position under path, on the given line: its
+// key's line for an object entry, otherwise the line the value starts on
+func (idx *jsonLines) walk(dec *json.Decoder, path string, line int) error {
+	idx.lines[path] = line
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			keyLine := idx.lineAt(idx.nextToken(dec.InputOffset()))
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			child := fmt.Sprint(key)
+			if path != "" {
+				child = path + "." + child
+			}
+			if err := idx.walk(dec, child, keyLine); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			elemLine := idx.lineAt(idx.nextToken(dec.InputOffset()))
+			if err := idx.walk(dec, fmt.Sprintf("%s[%d]", path, i), elemLine); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	}
+	return err
+}
+
+// nextToken skips whitespace, commas, and colons from off to the next token
+func (idx *jsonLines) nextToken(off int64) int64 {
+	for off < int64(len(idx.data)) && strings.IndexByte(" \t\r\n,:", idx.data[off]) >= 0 {
+		off++
+	}
+	return off
+}
+
+func (idx *jsonLines) lineAt(off int64) int {
+	if off < 0 || off > int64(len(idx.data)) {
+		return 0
+	}
+	return bytes.Count(idx.data[:off], []byte("\n")) + 1
+}
+
+// Line returns the line of the value at path, or of its nearest indexed parent
+func (idx *jsonLines) Line(path string) int {
+	for path != "" {
+		if line, ok := idx.lines[path]; ok {
+			return line
+		}
+		cut := strings.LastIndexAny(path, ".[")
+		if cut < 0 {
+			break
+		}
+		path = path[:cut]
+	}
+	return 0
+}
+
+// syntaxLine returns the line a JSON decoding error points at, if it says
+func syntaxLine(data []byte, err error) int {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return (&jsonLines{data: data}).lineAt(syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return (&jsonLines{data: data}).lineAt(typeErr.Offset)
+	}
+	return 0
+}
+
+// unknownFieldLine returns the line of the field an unknown field error names. The
+// error doesn't say where the field is, but the decoder stops just past it, at stop.
+func unknownFieldLine(data []byte, err error, stop int64) int {
+	name, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok || stop > int64(len(data)) {
+		return 0
+	}
+	off := bytes.LastIndex(data[:stop], []byte(name))
+	if off < 0 {
+		off = int(stop)
+	}
+	return (&jsonLines{data: data}).lineAt(int64(off))
+}
@@ -0,0 +1,55 @@
+package validate
+
+// Rule IDs name the check behind a finding, so tools can filter findings or link to
+// their documentation. They are stable: a check's message may change, its rule won't.
+const (
+	// The document
+	RuleRead       = "read"        // the file can be read
+	RuleFilename   = "filename"    // lowercase snake_case with a .json extension
+	RuleJSONSyntax = "json_syntax" // the file is valid JSON
+	RuleStrictJSON = "strict_json" // no unknown fields, and every value has the field's type
+
+	// Scenario-wide
+	RuleMetadata           = "metadata"             // tags, version, estimated_turns, and synopsis
+	RuleRating             = "rating"               // rating is G, PG, PG-13, or R
+	RuleRatingLanguage     = "rating_language"      // the text's language fits the rating
+	RuleNarrationLength    = "narration_length"     // narration_length bounds
+	RuleRules              = "rules"                // rules and rules_order
+	RulePromptStateVersion = "prompt_state_version" // prompt_state_version is known
+	RuleIDFormat           = "id_format"            // keys are lowercase snake_case
+	RuleKeyCollision       = "key_collision"        // keys and names stay distinct once normalized
+	RuleRenames            = "renames"              // renamed entries point from a gone key to a live one
+	RulePlaceholder        = "placeholder"          // only placeholders the engine resolves
+
+	// Locations, NPCs, and monsters
+	RuleLocationReference = "location_reference" // exits and locations lead somewhere defined
+	RuleBlockedExit       = "blocked_exit"       // blocked exits are exits
+	RuleAmbient           = "ambient"            // ambient lines aren't blank
+	RuleNPCStats          = "npc_stats"          // hp and max_hp
+	RuleFollowing         = "npc_following"      // following names an NPC or the PC
+	RuleMonsterPlacement  = "monster_placement"  // monsters placed in locations
+	RuleMonsterEvent      = "monster_event"      // spawn and despawn events
+
+	// Items
+	RuleItemPlacement  = "item_placement"  // an item starts in one place
+	RuleItemObtainable = "item_obtainable" // items conditionals need can be had
+	RuleItemDetails    = "item_details"    // item_details describe items in the scenario
+	RuleItemNames      = "item_names"      // item names are written one way
+
+	// Lore
+	RuleLore          = "lore"           // entries have a title, text, and keywords
+	RuleLoreReference = "lore_reference" // references name a defined entry
+
+	// Scenes and conditionals
+	RuleOpeningPrompt     = "opening_prompt"       // scenes' opening prompts
+	RuleHints             = "hints"                // hints and hint_budget
+	RulePriority          = "conditional_priority" // priorities in a group are distinct
+	RuleFire              = "conditional_fire"     // fire and cooldown
+	RuleConditionalWhen   = "conditional_when"     // when has a condition
+	RuleConditionalThen   = "conditional_then"     // then has an action
+	RuleConditionalPrompt = "conditional_prompt"   // prompts aren't empty
+	RuleCheck             = "conditional_check"    // skill checks' attribute and dc
+	RuleVarName           = "var_name"             // variable names are lowercase snake_case
+	RuleClearInventory    = "clear_inventory"      // clear_inventory filters
+	RuleCombat            = "combat"               // start_combat and end_combat
+)
@@ -0,0 +1,1104 @@
+// Package validate checks scenarios against the rules the engine relies on: ID formats,
+// references between locations, NPCs, items, scenes, and lore, conditionals that can
+// fire, and a rating that fits the scenario's language. cmd/validate runs it on files;
+// the API runs it on uploaded scenarios.
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/prompts"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+	"github.com/jwebster45206/story-engine/pkg/textfilter"
+)
+
+// Finding severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is one problem found in a scenario
+type Finding struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"` // 1-based; 0 when the problem isn't tied to one place
+	Path     string `json:"path,omitempty"` // JSON path of the value, e.g. "scenes.intro.conditionals.arrive"
+	Rule     string `json:"rule"`           // ID of the check that found it; see the Rule constants
+	Severity string `json:"severity"`       // SeverityError or SeverityWarning
+	Message  string `json:"message"`
+}
+
+// Error formats the finding as "file:line: path: message (rule)", leaving out what
+// isn't known
+func (f Finding) Error() string {
+	var b strings.Builder
+	if f.File != "" {
+		b.WriteString(f.File)
+		if f.Line > 0 {
+			fmt.Fprintf(&b, ":%d", f.Line)
+		}
+		b.WriteString(": ")
+	}
+	if f.Path != "" {
+		b.WriteString(f.Path + ": ")
+	}
+	b.WriteString(f.Message)
+	if f.Rule != "" {
+		b.WriteString(" (" + f.Rule + ")")
+	}
+	return b.String()
+}
+
+// Result is what validating a scenario found. Errors make the scenario invalid;
+// warnings are worth a look but don't.
+type Result struct {
+	Errors   []Finding `json:"errors"`
+	Warnings []Finding `json:"warnings"`
+}
+
+// Valid reports whether no errors were found
+func (r *Result) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Err returns the errors found joined into one error, or nil if the scenario is
+// valid. Each is a Finding, which errors.As can recover.
+func (r *Result) Err() error {
+	errs := make([]error, len(r.Errors))
+	for i, f := range r.Errors {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
+}
+
+// File validates a scenario file: its name, its JSON, and the scenario in it
+func File(filename string) *Result {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		v := &validator{}
+		if v.validateFilename(filename) {
+			v.addError(RuleRead, fmt.Sprintf("failed to read file %s: %v", filename, err))
+		}
+		return v.result(filename, nil)
+	}
+	return JSON(filename, data)
+}
+
+// JSON validates a scenario document as if it were stored as filename: the filename,
+// strict decoding with no unknown fields, and the scenario's rules. Findings carry the
+// line they're on where it can be told.
+func JSON(filename string, data []byte) *Result {
+	v := &validator{}
+	if v.validateFilename(filename) {
+		v.validateDocument(filename, data)
+	}
+	return v.result(filename, data)
+}
+
+// Scenario validates a decoded scenario. Its keys are normalized along the way, as
+// storage does when loading it.
+func Scenario(s *scenario.Scenario) *Result {
+	v := &validator{}
+	v.validateScenario(s)
+	return v.result("", nil)
+}
+
+type validator struct {
+	errors   []Finding
+	warnings []Finding                     // reported but don't fail validation
+	path     string                        // JSON path of what's being checked, for findings; see at
+	lore     map[string]scenario.LoreEntry // the scenario's lore, for checking references
+}
+
+// result returns the findings, with their file and, from data, their lines
+func (v *validator) result(filename string, data []byte) *Result {
+	idx := indexLines(data)
+	for _, findings := range [][]Finding{v.errors, v.warnings} {
+		for i := range findings {
+			findings[i].File = filename
+			if findings[i].Line == 0 && findings[i].Path != "" {
+				findings[i].Line = idx.Line(findings[i].Path)
+			}
+		}
+	}
+	return &Result{Errors: v.errors, Warnings: v.warnings}
+}
+
+// at sets the JSON path that findings are reported against until the returned func
+// restores the previous one
+func (v *validator) at(path string) func() {
+	prev := v.path
+	v.path = path
+	return func() { v.path = prev }
+}
+
+// validateFilename checks a scenario's filename, returning false if it's unusable
+func (v *validator) validateFilename(filename string) bool {
+	baseName := filepath.Base(filename)
+	if !strings.HasSuffix(baseName, ".json") {
+		v.addError(RuleFilename, fmt.Sprintf("scenario file must have .json extension: %s", baseName))
+		return false
+	}
+
+	nameWithoutExt := strings.TrimSuffix(baseName, ".json")
+	if !isValidScenarioFilename(nameWithoutExt) {
+		v.addError(RuleFilename, fmt.Sprintf("scenario filename '%s' must be lowercase snake_case (e.g., my_scenario.json, not my-scenario.json or MyScenario.json)", baseName))
+		return false
+	}
+	return true
+}
+
+// validateDocument decodes a scenario strictly and validates it
+func (v *validator) validateDocument(filename string, data []byte) {
+	if !json.Valid(data) {
+		var probe any
+		err := json.Unmarshal(data, &probe)
+		v.errors = append(v.errors, Finding{Line: syntaxLine(data, err), Rule: RuleJSONSyntax, Severity: SeverityError,
+			Message: fmt.Sprintf("file %s contains invalid JSON: %v", filename, err)})
+		return
+	}
+
+	var s scenario.Scenario
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&s); err != nil {
+		line := syntaxLine(data, err)
+		if line == 0 {
+			line = unknownFieldLine(data, err, decoder.InputOffset())
+		}
+		v.errors = append(v.errors, Finding{Line: line, Rule: RuleStrictJSON, Severity: SeverityError,
+			Message: fmt.Sprintf("file %s failed strict JSON unmarshaling: %v", filename, err)})
+		return
+	}
+
+	v.validateScenario(&s)
+}
+
+func (v *validator) validateScenario(s *scenario.Scenario) {
+	v.validateMetadata(s)
+	v.validateNarrationLength("scenario", s.NarrationLength)
+	v.validateLore(s)
+	v.validateRules(s)
+	v.atField("story", func() { v.validatePlaceholders("story", s.Story) })
+	v.atField("opening_prompt", func() { v.validatePlaceholders("opening_prompt", s.OpeningPrompt) })
+
+	// Validate opening_scene ID
+	v.atField("opening_scene", func() { v.validateIDFormat("opening_scene", s.OpeningScene) })
+
+	// Validate location IDs and their contingency prompts
+	for locationID, location := range s.Locations {
+		restore := v.at("locations." + locationID)
+		v.validateIDFormat("location ID", locationID)
+		v.validateIDFormat("location id", location.ID)
+		v.validateLocationMonsters(location.Monsters, locationID, "scenario")
+		v.validateAmbient(location.Ambient, locationID)
+		for _, cp := range location.ContingencyPrompts {
+			v.validateContingencyPrompt(&cp)
+		}
+		restore()
+	}
+
+	// Validate NPC IDs and their contingency prompts
+	for npcID, npc := range s.NPCs {
+		restore := v.at("npcs." + npcID)
+		v.validateIDFormat("NPC ID", npcID)
+		v.validateIDFormat("NPC id", npc.ID)
+		v.validateNPCStats(npcID, npc)
+		for _, cp := range npc.ContingencyPrompts {
+			v.validateContingencyPrompt(&cp)
+		}
+		restore()
+	}
+
+	// Validate the item catalog's IDs and names
+	for key, item := range s.ItemCatalog {
+		restore := v.at("items." + key)
+		v.validateIDFormat("item ID", key)
+		v.validateIDFormat("item id", item.ID)
+		if strings.TrimSpace(item.Name) == "" {
+			v.addError(RuleItemNames, fmt.Sprintf("item '%s' has no name", key))
+		}
+		restore()
+	}
+
+	// Validate scene IDs and their contents
+	for sceneID, scene := range s.Scenes {
+		restore := v.at("scenes." + sceneID)
+		v.validateIDFormat("scene ID", sceneID)
+		v.validateScene(&scene, sceneID, s.OpeningScene)
+		restore()
+	}
+
+	for _, cp := range s.ContingencyPrompts {
+		v.validateContingencyPrompt(&cp)
+	}
+
+	// Validate NPC following field references
+	v.validateFollowingReferences(s)
+
+	// Validate exits, blocked exits, and item placement across locations
+	v.validateLocationConsistency(s)
+
+	v.atField("renamed", func() { v.validateRenames(s) })
+
+	// Last, as it rewrites the scenario's keys the way storage does when loading it
+	v.validateKeyCollisions(s)
+}
+
+// validateRenames checks the renamed table: each old ID must be gone from the
+// scenario, so saved games can't be migrated away from a live entry, and each new ID
+// or item name must exist
+func (v *validator) validateRenames(s *scenario.Scenario) {
+	r := s.Renamed
+	if r.IsEmpty() {
+		return
+	}
+
+	locations := make(map[string]bool)
+	npcs := make(map[string]bool)
+	items := make(map[string]bool)
+	addItems := func(list []string) {
+		for _, item := range list {
+			items[item] = true
+		}
+	}
+	addWorld := func(locs map[string]scenario.Location, people map[string]actor.NPC) {
+		for key, loc := range locs {
+			locations[scenario.NormalizeKey(key)] = true
+			if loc.ID != "" {
+				locations[scenario.NormalizeKey(loc.ID)] = true
+			}
+			addItems(loc.Items)
+		}
+		for key, npc := range people {
+			npcs[scenario.NormalizeKey(key)] = true
+			if npc.ID != "" {
+				npcs[scenario.NormalizeKey(npc.ID)] = true
+			}
+			addItems(npc.Items)
+		}
+	}
+	addWorld(s.Locations, s.NPCs)
+	for _, scene := range s.Scenes {
+		addWorld(scene.Locations, scene.NPCs)
+	}
+	addItems(s.Inventory)
+	addItems(s.OpeningInventory)
+	for item := range s.ItemDetails {
+		items[item] = true
+	}
+	for key, item := range s.ItemCatalog {
+		addItems([]string{key, scenario.NormalizeKey(key), item.ID, scenario.NormalizeKey(item.ID), item.Name})
+	}
+
+	check := func(kind string, renames map[string]string, exists map[string]bool, normalize func(string) string) {
+		for _, old := range slices.Sorted(maps.Keys(renames)) {
+			if exists[normalize(old)] {
+				v.addError(RuleRenames, fmt.Sprintf("renamed %s '%s' still exists; a renamed key can't be reused", kind, old))
+			}
+			if to := renames[old]; !exists[normalize(to)] {
+				v.addError(RuleRenames, fmt.Sprintf("renamed %s '%s' → '%s': '%s' is not defined", kind, old, to, to))
+			}
+		}
+	}
+	same := func(name string) string { return name }
+	check("location", r.Locations, locations, scenario.NormalizeKey)
+	check("NPC", r.NPCs, npcs, scenario.NormalizeKey)
+	check(scenario.KeyKindItem, r.Items, items, same)
+
+	cycles := r.Cycles()
+	for _, kind := range slices.Sorted(maps.Keys(cycles)) {
+		v.addError(RuleRenames, fmt.Sprintf("renamed %ss %s run in a cycle", kind, strings.Join(cycles[kind], ", ")))
+	}
+}
+
+// validateKeyCollisions warns about entries whose keys or display names are the same
+// once normalized (e.g. key "dock" and another location named "Dock"), as references
+// can reach only one of them
+func (v *validator) validateKeyCollisions(s *scenario.Scenario) {
+	for _, c := range s.Normalize() {
+		keys := make([]string, len(c.Keys))
+		for i, key := range c.Keys {
+			keys[i] = "'" + key + "'"
+		}
+		v.addWarning(RuleKeyCollision, fmt.Sprintf("%ss %s share the key or name '%s' once normalized; references to it reach only '%s'",
+			c.Kind, strings.Join(keys, ", "), c.Key, c.Keys[0]))
+	}
+}
+
+// validateMetadata checks the browsing metadata: rating, tags, version, and length
+func (v *validator) validateMetadata(s *scenario.Scenario) {
+	switch s.Rating {
+	case "", scenario.RatingG, scenario.RatingPG, scenario.RatingPG13, scenario.RatingR:
+	default:
+		v.addErrorAt(RuleRating, "rating", fmt.Sprintf("rating '%s' must be one of G, PG, PG-13, R", s.Rating))
+	}
+	v.validateRating(s)
+
+	seenTags := make(map[string]bool)
+	for i, tag := range s.Tags {
+		field := fmt.Sprintf("tags[%d]", i)
+		if !validTagRegex.MatchString(tag) {
+			v.addErrorAt(RuleMetadata, field, fmt.Sprintf("tag '%s' should be lowercase, using hyphens between words (e.g., sci-fi)", tag))
+		}
+		if seenTags[tag] {
+			v.addErrorAt(RuleMetadata, field, fmt.Sprintf("tag '%s' is listed more than once", tag))
+		}
+		seenTags[tag] = true
+	}
+
+	if s.Version != "" && !validVersionRegex.MatchString(s.Version) {
+		v.addErrorAt(RuleMetadata, "version", fmt.Sprintf("version '%s' should be in major.minor or major.minor.patch form (e.g., 1.0.0)", s.Version))
+	}
+
+	if s.EstimatedTurns < 0 {
+		v.addErrorAt(RuleMetadata, "estimated_turns", fmt.Sprintf("estimated_turns must not be negative, got %d", s.EstimatedTurns))
+	}
+
+	if len([]rune(s.Synopsis)) > maxSynopsisLength {
+		v.addErrorAt(RuleMetadata, "synopsis", fmt.Sprintf("synopsis is %d characters - keep it under %d and put longer descriptions in 'story'", len([]rune(s.Synopsis)), maxSynopsisLength))
+	}
+}
+
+// validateRating checks the scenario's text against the built-in profanity lists, so a
+// scenario rated stricter than its language isn't run on a model censored for that rating
+func (v *validator) validateRating(s *scenario.Scenario) {
+	if scenario.RatingLevel(s.Rating) < 0 {
+		return
+	}
+	analysis := s.AnalyzeRating(textfilter.NewProfanityFilter())
+	if !analysis.Conflicts() {
+		return
+	}
+	var found []string
+	for _, f := range analysis.Violations() {
+		found = append(found, fmt.Sprintf("'%s' in %s", f.Word, f.Field))
+	}
+	v.addError(RuleRatingLanguage, fmt.Sprintf("rating '%s' is stricter than the scenario's language allows; rate it %s or reword %s",
+		analysis.Rating, analysis.Suggested, strings.Join(found, ", ")))
+}
+
+// validateNPCStats checks an NPC's hit points can build a d20 actor. NPCs from
+// templates are checked as written; their template's values aren't known here.
+func (v *validator) validateNPCStats(npcID string, npc actor.NPC) {
+	switch {
+	case npc.MaxHP < 0:
+		v.addError(RuleNPCStats, fmt.Sprintf("NPC '%s' max_hp must not be negative, got %d", npcID, npc.MaxHP))
+	case npc.HP < 0:
+		v.addError(RuleNPCStats, fmt.Sprintf("NPC '%s' hp must not be negative, got %d", npcID, npc.HP))
+	case npc.HP > 0 && npc.MaxHP == 0 && npc.TemplateID == "":
+		v.addError(RuleNPCStats, fmt.Sprintf("NPC '%s' has hp but no max_hp", npcID))
+	case npc.MaxHP > 0 && npc.HP > npc.MaxHP:
+		v.addError(RuleNPCStats, fmt.Sprintf("NPC '%s' hp %d is more than its max_hp %d", npcID, npc.HP, npc.MaxHP))
+	}
+}
+
+// validateNarrationLength checks narration_length's bounds are within what a request may set
+func (v *validator) validateNarrationLength(context string, length *scenario.NarrationLength) {
+	if length == nil {
+		return
+	}
+	if length.MaxTokens < 0 || length.MaxTokens > chat.MaxNarrationTokens {
+		v.addError(RuleNarrationLength, fmt.Sprintf("%s narration_length max_tokens must be between 0 and %d, got %d", context, chat.MaxNarrationTokens, length.MaxTokens))
+	}
+	if length.MaxSentences < 0 {
+		v.addError(RuleNarrationLength, fmt.Sprintf("%s narration_length max_sentences must not be negative, got %d", context, length.MaxSentences))
+	}
+}
+
+func (v *validator) validateScene(scene *scenario.Scene, sceneID string, openingScene string) {
+	v.validateNarrationLength(fmt.Sprintf("scene %s", sceneID), scene.NarrationLength)
+	v.validatePlaceholders(fmt.Sprintf("scene %s story", sceneID), scene.Story)
+	v.validatePlaceholders(fmt.Sprintf("scene %s opening_prompt", sceneID), scene.OpeningPrompt)
+	if scene.OpeningPrompt != "" {
+		if strings.TrimSpace(scene.OpeningPrompt) == "" {
+			v.addError(RuleOpeningPrompt, fmt.Sprintf("scene %s has empty opening_prompt", sceneID))
+		} else if sceneID == openingScene {
+			v.addWarning(RuleOpeningPrompt, fmt.Sprintf("scene %s is the opening scene, so its opening_prompt is never narrated - use the scenario's opening_prompt instead", sceneID))
+		}
+	}
+
+	// Validate location IDs and their contingency prompts within the scene
+	prefix := "scenes." + sceneID
+	for locationID, location := range scene.Locations {
+		restore := v.at(prefix + ".locations." + locationID)
+		v.validateIDFormat("scene location ID", locationID)
+		v.validateIDFormat("scene location id", location.ID)
+		v.validateLocationMonsters(location.Monsters, locationID, fmt.Sprintf("scene %s", sceneID))
+		v.validateAmbient(location.Ambient, locationID)
+		for _, cp := range location.ContingencyPrompts {
+			v.validateContingencyPrompt(&cp)
+		}
+		restore()
+	}
+
+	// Validate NPC IDs and their contingency prompts within the scene
+	for npcID, npc := range scene.NPCs {
+		restore := v.at(prefix + ".npcs." + npcID)
+		v.validateIDFormat("scene NPC ID", npcID)
+		v.validateIDFormat("scene NPC id", npc.ID)
+		v.validateNPCStats(npcID, npc)
+		for _, cp := range npc.ContingencyPrompts {
+			v.validateContingencyPrompt(&cp)
+		}
+		restore()
+	}
+
+	// Validate conditional keys (map keys are the conditional IDs)
+	groupPriorities := make(map[string]map[int][]string) // group -> priority -> conditional keys
+	for conditionalKey, conditional := range scene.Conditionals {
+		restore := v.at(prefix + ".conditionals." + conditionalKey)
+		v.validateIDFormat("conditional key", conditionalKey)
+		v.validateConditional(&conditional, sceneID, conditionalKey)
+		if conditional.Group != "" {
+			v.validateIDFormat("conditional group", conditional.Group)
+			if groupPriorities[conditional.Group] == nil {
+				groupPriorities[conditional.Group] = make(map[int][]string)
+			}
+			groupPriorities[conditional.Group][conditional.Priority] = append(groupPriorities[conditional.Group][conditional.Priority], conditionalKey)
+		}
+		restore()
+	}
+
+	// Ties in a group fall back to ID order, which is rarely what the author meant
+	for _, group := range slices.Sorted(maps.Keys(groupPriorities)) {
+		for _, priority := range slices.Sorted(maps.Keys(groupPriorities[group])) {
+			if keys := groupPriorities[group][priority]; len(keys) > 1 {
+				slices.Sort(keys)
+				v.addWarning(RulePriority, fmt.Sprintf("scene %s: conditionals %s in group '%s' share priority %d; ties are broken by conditional ID", sceneID, strings.Join(keys, ", "), group, priority))
+			}
+		}
+	}
+
+	for _, cp := range scene.ContingencyPrompts {
+		v.validateContingencyPrompt(&cp)
+	}
+
+	for i, hint := range scene.Hints {
+		if strings.TrimSpace(hint) == "" {
+			v.addErrorAt(RuleHints, fmt.Sprintf("%s.hints[%d]", prefix, i), fmt.Sprintf("scene %s hint %d is blank", sceneID, i))
+		}
+	}
+	if scene.HintBudget > 0 && len(scene.Hints) > scene.HintBudget {
+		v.addWarning(RuleHints, fmt.Sprintf("scene %s has %d hints but a hint_budget of %d, so the last %d are never given", sceneID, len(scene.Hints), scene.HintBudget, len(scene.Hints)-scene.HintBudget))
+	}
+	if scene.HintBudget < 0 && len(scene.Hints) > 0 {
+		v.addWarning(RuleHints, fmt.Sprintf("scene %s has hints but a negative hint_budget, which turns hints off", sceneID))
+	}
+}
+
+func (v *validator) validateConditional(conditional *scenario.Conditional, sceneID string, conditionalKey string) {
+	v.validateConditionalWhen(&conditional.When, fmt.Sprintf("conditional %s in scene %s", conditionalKey, sceneID), conditionalKey)
+
+	switch conditional.Fire {
+	case "", scenario.FireRepeatable:
+	case scenario.FireOnce, scenario.FireOncePerScene:
+		if conditional.Cooldown != 0 {
+			v.addWarning(RuleFire, fmt.Sprintf("conditional %s in scene %s has a cooldown, which only applies to repeatable conditionals", conditionalKey, sceneID))
+		}
+	default:
+		v.addError(RuleFire, fmt.Sprintf("conditional %s in scene %s has fire '%s' - must be one of once, once_per_scene, repeatable", conditionalKey, sceneID, conditional.Fire))
+	}
+	if conditional.Cooldown < 0 {
+		v.addError(RuleFire, fmt.Sprintf("conditional %s in scene %s has negative cooldown %d", conditionalKey, sceneID, conditional.Cooldown))
+	}
+
+	// Validate Then clause has at least one action
+	actionCount := 0
+	if conditional.Then.SceneChange != nil && conditional.Then.SceneChange.To != "" {
+		v.validateIDFormat("conditional then scene", conditional.Then.SceneChange.To)
+		actionCount++
+	}
+	if conditional.Then.GameEnded != nil {
+		actionCount++
+	}
+	if conditional.Then.Prompt != nil {
+		if strings.TrimSpace(*conditional.Then.Prompt) == "" {
+			v.addError(RuleConditionalPrompt, fmt.Sprintf("conditional %s in scene %s has empty prompt", conditionalKey, sceneID))
+		}
+		actionCount++
+	}
+	if check := conditional.Then.Check; check != nil {
+		attribute := actor.NormalizeAttribute(check.Attribute)
+		if attribute == "" {
+			v.addError(RuleCheck, fmt.Sprintf("conditional %s in scene %s has a check with no attribute", conditionalKey, sceneID))
+		} else if !actor.IsCheckAttribute(attribute) {
+			v.addWarning(RuleCheck, fmt.Sprintf("conditional %s in scene %s checks '%s', which is not an ability or skill; PCs without it roll with no bonus", conditionalKey, sceneID, check.Attribute))
+		}
+		if check.DC < conditionals.MinDC || check.DC > conditionals.MaxDC {
+			v.addError(RuleCheck, fmt.Sprintf("conditional %s in scene %s has check dc %d - must be between %d and %d", conditionalKey, sceneID, check.DC, conditionals.MinDC, conditionals.MaxDC))
+		}
+		if check.Var != "" && !isValidVariableName(check.Var) {
+			v.addError(RuleVarName, fmt.Sprintf("conditional %s in scene %s has invalid variable name '%s' in then.check.var - should be lowercase snake_case", conditionalKey, sceneID, check.Var))
+		}
+		actionCount++
+	}
+	if len(conditional.Then.SetVars) > 0 {
+		for varName := range conditional.Then.SetVars {
+			if !isValidVariableName(varName) {
+				v.addError(RuleVarName, fmt.Sprintf("conditional %s in scene %s has invalid variable name '%s' in then.set_vars - should be lowercase snake_case", conditionalKey, sceneID, varName))
+			}
+		}
+		actionCount++
+	}
+	if len(conditional.Then.ItemEvents) > 0 {
+		actionCount++
+	}
+	if len(conditional.Then.NPCEvents) > 0 {
+		for _, npcEvent := range conditional.Then.NPCEvents {
+			// Validate NPC ID format
+			if npcEvent.NPCID != "" {
+				v.validateIDFormat("npc_event npc_id", npcEvent.NPCID)
+			}
+
+			// Validate location if set
+			if npcEvent.SetLocation != nil && *npcEvent.SetLocation != "" {
+				v.validateIDFormat("npc_event set_location", *npcEvent.SetLocation)
+			}
+
+			// Validate following if set
+			if npcEvent.SetFollowing != nil {
+				following := *npcEvent.SetFollowing
+				if following != "" && following != "pc" {
+					v.validateIDFormat("npc_event set_following", following)
+				}
+			}
+		}
+		actionCount++
+	}
+	if len(conditional.Then.MonsterEvents) > 0 {
+		for i, monsterEvent := range conditional.Then.MonsterEvents {
+			v.validateMonsterEvent(&monsterEvent, fmt.Sprintf("conditional %s in scene %s, monster_event %d", conditionalKey, sceneID, i))
+		}
+		actionCount++
+	}
+	if conditional.Then.UserLocation != "" {
+		v.validateIDFormat("conditional then user_location", conditional.Then.UserLocation)
+		actionCount++
+	}
+	if len(conditional.Then.RemoveVars) > 0 {
+		for _, varName := range conditional.Then.RemoveVars {
+			if !isValidVariableName(varName) {
+				v.addError(RuleVarName, fmt.Sprintf("conditional %s in scene %s has invalid variable name '%s' in then.remove_vars - should be lowercase snake_case", conditionalKey, sceneID, varName))
+			}
+		}
+		actionCount++
+	}
+	if len(conditional.Then.ClearInventory) > 0 {
+		for i, filter := range conditional.Then.ClearInventory {
+			for _, item := range filter.Items {
+				if slices.Contains(filter.Except, item) {
+					v.addWarning(RuleClearInventory, fmt.Sprintf("conditional %s in scene %s, clear_inventory %d lists '%s' in both items and except; it will be kept", conditionalKey, sceneID, i, item))
+				}
+			}
+		}
+		actionCount++
+	}
+	if len(conditional.Then.RemoveNPCs) > 0 {
+		for _, npcID := range conditional.Then.RemoveNPCs {
+			v.validateIDFormat("remove_npcs npc_id", npcID)
+		}
+		actionCount++
+	}
+	if start := conditional.Then.StartCombat; start != nil {
+		if len(start.Foes) == 0 {
+			v.addError(RuleCombat, fmt.Sprintf("conditional %s in scene %s has start_combat with no foes", conditionalKey, sceneID))
+		}
+		for _, foe := range start.Foes {
+			v.validateIDFormat("start_combat foe", foe)
+		}
+		if conditional.Then.EndCombat {
+			v.addWarning(RuleCombat, fmt.Sprintf("conditional %s in scene %s both ends and starts combat; the old fight ends and the new one starts", conditionalKey, sceneID))
+		}
+		actionCount++
+	}
+	if conditional.Then.EndCombat {
+		actionCount++
+	}
+	if len(conditional.Then.UnlockLore) > 0 {
+		for _, loreID := range conditional.Then.UnlockLore {
+			v.validateLoreReference(fmt.Sprintf("conditional %s in scene %s then.unlock_lore", conditionalKey, sceneID), loreID)
+		}
+		actionCount++
+	}
+
+	if actionCount == 0 {
+		v.addError(RuleConditionalThen, fmt.Sprintf("conditional %s in scene %s has no action in 'then' clause", conditionalKey, sceneID))
+	}
+}
+
+func (v *validator) validateContingencyPrompt(cp *conditionals.ContingencyPrompt) {
+	v.validatePlaceholders("contingency prompt", cp.Prompt)
+	if cp.When != nil {
+		v.validateConditionalWhen(cp.When, "contingency prompt", cp.Prompt)
+	}
+}
+
+func (v *validator) validateConditionalWhen(when *conditionals.ConditionalWhen, context string, prompt string) {
+	if len(when.Vars) == 0 && when.SceneTurnCounter == nil && when.TurnCounter == nil &&
+		when.Location == "" && when.MinSceneTurns == nil && when.MinTurns == nil && len(when.LoreKnown) == 0 {
+		v.addError(RuleConditionalWhen, fmt.Sprintf("%s has empty 'when' clause - no conditions specified (%s)", context, prompt))
+		return
+	}
+
+	if len(when.Vars) > 0 {
+		for varName := range when.Vars {
+			if !isValidVariableName(varName) {
+				v.addError(RuleVarName, fmt.Sprintf("%s has invalid variable name '%s' - should be lowercase snake_case", context, varName))
+			}
+		}
+	}
+
+	if when.Location != "" {
+		v.validateIDFormat("when location", when.Location)
+	}
+
+	for _, loreID := range when.LoreKnown {
+		v.validateLoreReference(context+" lore_known", loreID)
+	}
+}
+
+// validateLore checks lore entry IDs and contents
+func (v *validator) validateLore(s *scenario.Scenario) {
+	v.lore = s.Lore
+	for _, loreID := range slices.Sorted(maps.Keys(s.Lore)) {
+		entry := s.Lore[loreID]
+		restore := v.at("lore." + loreID)
+		v.validateIDFormat("lore ID", loreID)
+		if strings.TrimSpace(entry.Title) == "" || strings.TrimSpace(entry.Text) == "" {
+			v.addError(RuleLore, fmt.Sprintf("lore entry '%s' needs a title and text", loreID))
+		}
+		if len(entry.Keywords) == 0 {
+			v.addWarning(RuleLore, fmt.Sprintf("lore entry '%s' has no keywords, so only conditionals can unlock it", loreID))
+		}
+		restore()
+	}
+}
+
+// validateRules checks the scenario's per-turn reminders and the order of the <rules> block
+func (v *validator) validateRules(s *scenario.Scenario) {
+	for i, rule := range s.Rules {
+		if strings.TrimSpace(rule) == "" {
+			v.addErrorAt(RuleRules, fmt.Sprintf("rules[%d]", i), fmt.Sprintf("rules[%d] is empty", i))
+		}
+	}
+	if err := prompts.ValidateRulesOrder(s.RulesOrder); err != nil {
+		v.addError(RuleRules, err.Error())
+	}
+	if err := prompts.ValidatePromptStateVersion(s.PromptStateVersion); err != nil {
+		v.addError(RulePromptStateVersion, err.Error())
+	}
+}
+
+// validatePlaceholders checks that authored text only uses placeholders the engine resolves
+func (v *validator) validatePlaceholders(context, text string) {
+	for _, placeholder := range state.UnknownPlaceholders(text) {
+		v.addError(RulePlaceholder, fmt.Sprintf("%s uses unknown placeholder %s (use {{vars.name}}, {{pc.name}}, or {{location.name}})", context, placeholder))
+	}
+}
+
+// validateLoreReference checks that a lore ID names an entry in the scenario's lore
+func (v *validator) validateLoreReference(context, loreID string) {
+	if _, ok := v.lore[loreID]; !ok {
+		v.addError(RuleLoreReference, fmt.Sprintf("%s references undefined lore entry '%s'", context, loreID))
+	}
+}
+
+func (v *validator) validateIDFormat(fieldName, id string) {
+	if id == "" {
+		return
+	}
+
+	if !isValidID(id) {
+		v.addError(RuleIDFormat, fmt.Sprintf("%s '%s' should be lowercase snake_case", fieldName, id))
+	}
+}
+
+func (v *validator) addError(rule, msg string) {
+	v.errors = append(v.errors, Finding{Path: v.path, Rule: rule, Severity: SeverityError, Message: msg})
+}
+
+func (v *validator) addWarning(rule, msg string) {
+	v.warnings = append(v.warnings, Finding{Path: v.path, Rule: rule, Severity: SeverityWarning, Message: msg})
+}
+
+// addErrorAt adds an error about the value at a JSON path
+func (v *validator) addErrorAt(rule, path, msg string) {
+	defer v.at(path)()
+	v.addError(rule, msg)
+}
+
+// addWarningAt adds a warning about the value at a JSON path
+func (v *validator) addWarningAt(rule, path, msg string) {
+	defer v.at(path)()
+	v.addWarning(rule, msg)
+}
+
+// atField runs check with findings reported against the value at a JSON path
+func (v *validator) atField(path string, check func()) {
+	defer v.at(path)()
+	check()
+}
+
+// validateFollowingReferences checks that NPC 'following' fields reference valid targets
+func (v *validator) validateFollowingReferences(s *scenario.Scenario) {
+	// Collect all NPC IDs and names from scenario level
+	allNPCs := make(map[string]string) // map[id]name
+	for npcID, npc := range s.NPCs {
+		allNPCs[npcID] = npc.Name
+	}
+	for _, scene := range s.Scenes {
+		for npcID, npc := range scene.NPCs {
+			allNPCs[npcID] = npc.Name
+		}
+	}
+
+	for npcID, npc := range s.NPCs {
+		v.atField("npcs."+npcID+".following", func() { v.validateNPCFollowing(npcID, npc.Following, allNPCs) })
+	}
+	for sceneID, scene := range s.Scenes {
+		for npcID, npc := range scene.NPCs {
+			v.atField("scenes."+sceneID+".npcs."+npcID+".following", func() {
+				v.validateNPCFollowing(fmt.Sprintf("%s (scene: %s)", npcID, sceneID), npc.Following, allNPCs)
+			})
+		}
+	}
+}
+
+func (v *validator) validateNPCFollowing(npcContext string, following string, allNPCs map[string]string) {
+	if following == "" || strings.ToLower(following) == "pc" {
+		return
+	}
+	if !isValidID(following) {
+		v.addError(RuleFollowing, fmt.Sprintf("NPC '%s' has invalid 'following' field '%s' - must be a valid NPC ID/name or 'pc'", npcContext, following))
+		return
+	}
+	for npcID, npcName := range allNPCs {
+		if npcID == following || npcName == following {
+			return // Valid reference found
+		}
+	}
+	v.addError(RuleFollowing, fmt.Sprintf("NPC '%s' has invalid 'following' field '%s' - must be 'pc' or a valid NPC ID/name", npcContext, following))
+}
+
+var (
+	validIDRegex       = regexp.MustCompile(`^[a-z][a-z0-9_]*[a-z0-9]$|^[a-z]$`)
+	validVarRegex      = regexp.MustCompile(`^[a-z][a-z0-9_]*[a-z0-9]$|^[a-z]$`)
+	validFilenameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*[a-z0-9]$|^[a-z]$`)
+	validTagRegex      = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	validVersionRegex  = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+)
+
+const maxSynopsisLength = 280
+
+func isValidID(id string) bool {
+	return validIDRegex.MatchString(id)
+}
+
+func isValidVariableName(name string) bool {
+	return validVarRegex.MatchString(name)
+}
+
+func isValidScenarioFilename(name string) bool {
+	// Allow 'x.' prefix for experimental scenarios
+	name = strings.TrimPrefix(name, "x.")
+	return validFilenameRegex.MatchString(name)
+}
+
+// validateLocationMonsters validates monsters in a location
+func (v *validator) validateLocationMonsters(monsters map[string]*actor.Monster, locationID string, context string) {
+	for instanceID, monster := range monsters {
+		// Validate instance ID format
+		v.validateIDFormat(fmt.Sprintf("monster instance ID in location %s (%s)", locationID, context), instanceID)
+
+		// Validate required fields
+		if monster.TemplateID == "" {
+			v.addError(RuleMonsterPlacement, fmt.Sprintf("monster '%s' in location %s (%s) is missing required field 'template_id'", instanceID, locationID, context))
+		} else {
+			// Validate template ID format
+			v.validateIDFormat(fmt.Sprintf("monster template_id for instance %s in location %s", instanceID, locationID), monster.TemplateID)
+		}
+
+		// Warn if ID doesn't match instance ID (optional consistency check)
+		if monster.ID != "" && monster.ID != instanceID {
+			v.addError(RuleMonsterPlacement, fmt.Sprintf("monster '%s' in location %s (%s) has mismatched ID field '%s' - should match instance ID or be omitted", instanceID, locationID, context, monster.ID))
+		}
+
+		// Warn if Location field is set (it will be set automatically from map placement)
+		if monster.Location != "" && monster.Location != locationID {
+			v.addError(RuleMonsterPlacement, fmt.Sprintf("monster '%s' in location %s (%s) has location field set to '%s' - this will be overridden by the map location", instanceID, locationID, context, monster.Location))
+		}
+	}
+}
+
+// validateMonsterEvent validates a monster event in a conditional
+func (v *validator) validateMonsterEvent(event *conditionals.MonsterEvent, context string) {
+	// Validate action
+	if event.Action != "spawn" && event.Action != "despawn" {
+		v.addError(RuleMonsterEvent, fmt.Sprintf("%s has invalid action '%s' - must be 'spawn' or 'despawn'", context, event.Action))
+		return
+	}
+
+	// Validate instance ID
+	if event.InstanceID == "" {
+		v.addError(RuleMonsterEvent, fmt.Sprintf("%s is missing required field 'instance_id'", context))
+	} else {
+		v.validateIDFormat(fmt.Sprintf("monster instance_id in %s", context), event.InstanceID)
+	}
+
+	// For spawn actions, validate required fields
+	if event.Action == "spawn" {
+		if event.Template == "" {
+			v.addError(RuleMonsterEvent, fmt.Sprintf("%s with action 'spawn' is missing required field 'template'", context))
+		} else {
+			v.validateIDFormat(fmt.Sprintf("monster template in %s", context), event.Template)
+		}
+
+		if event.Location == "" {
+			v.addError(RuleMonsterEvent, fmt.Sprintf("%s with action 'spawn' is missing required field 'location'", context))
+		} else {
+			v.validateIDFormat(fmt.Sprintf("monster location in %s", context), event.Location)
+		}
+	}
+
+	// For despawn actions, template and location should not be set
+	if event.Action == "despawn" {
+		if event.Template != "" {
+			v.addError(RuleMonsterEvent, fmt.Sprintf("%s with action 'despawn' should not have 'template' field set", context))
+		}
+		if event.Location != "" {
+			v.addError(RuleMonsterEvent, fmt.Sprintf("%s with action 'despawn' should not have 'location' field set", context))
+		}
+	}
+}
+
+// validateAmbient checks that none of a location's ambient details is blank
+func (v *validator) validateAmbient(ambient []string, locationID string) {
+	for i, detail := range ambient {
+		if strings.TrimSpace(detail) == "" {
+			v.addError(RuleAmbient, fmt.Sprintf("location '%s' ambient[%d] is empty", locationID, i))
+		}
+	}
+}
+
+// validateLocationConsistency checks that exits, the opening location, and conditional
+// user_location targets lead to locations the player can be in at the time, that blocked
+// exits match a declared exit, and that no item starts in more than one place
+func (v *validator) validateLocationConsistency(s *scenario.Scenario) {
+	// While a scene is loaded the world holds the scenario's locations and the scene's
+	// (see GameState.LoadScene), so a reference outside both leads nowhere until the scene
+	// defining it loads. That can be deliberate, so it's a warning; a location defined
+	// nowhere is an error.
+	exitDirections := make(map[string]map[string]bool) // location ID → every direction it declares
+	addExits := func(locationID string, loc scenario.Location) {
+		if exitDirections[locationID] == nil {
+			exitDirections[locationID] = make(map[string]bool)
+		}
+		for direction := range loc.Exits {
+			exitDirections[locationID][direction] = true
+		}
+	}
+	for locationID, loc := range s.Locations {
+		addExits(locationID, loc)
+	}
+	for _, scene := range s.Scenes {
+		for locationID, loc := range scene.Locations {
+			addExits(locationID, loc)
+		}
+	}
+
+	// checkRef reports a location reference that isn't in the scenario or any of the
+	// given scenes, and returns false if it isn't
+	checkRef := func(ref, what string, sceneIDs ...string) bool {
+		if _, ok := s.Locations[ref]; ok {
+			return true
+		}
+		for _, sceneID := range sceneIDs {
+			if _, ok := s.Scenes[sceneID].Locations[ref]; ok {
+				return true
+			}
+		}
+		var definedIn []string
+		for _, sceneID := range slices.Sorted(maps.Keys(s.Scenes)) {
+			if _, ok := s.Scenes[sceneID].Locations[ref]; ok {
+				definedIn = append(definedIn, sceneID)
+			}
+		}
+		if len(definedIn) == 0 {
+			v.addError(RuleLocationReference, fmt.Sprintf("%s leads to undefined location '%s'", what, ref))
+		} else {
+			v.addWarning(RuleLocationReference, fmt.Sprintf("%s leads to location '%s', which is only defined in scene %s, so it leads nowhere until that scene loads",
+				what, ref, strings.Join(definedIn, ", ")))
+		}
+		return false
+	}
+
+	checkExits := func(locations map[string]scenario.Location, context, field string, sceneIDs ...string) {
+		for _, locationID := range slices.Sorted(maps.Keys(locations)) {
+			loc := locations[locationID]
+			for _, direction := range slices.Sorted(maps.Keys(loc.Exits)) {
+				v.atField(field+"."+locationID+".exits."+direction, func() {
+					checkRef(loc.Exits[direction], fmt.Sprintf("location '%s' (%s) exit '%s'", locationID, context, direction), sceneIDs...)
+				})
+			}
+			// A blocked direction with no exit anywhere is allowed (it narrates a dead end),
+			// but is often a misspelled exit
+			for _, direction := range slices.Sorted(maps.Keys(loc.BlockedExits)) {
+				if !exitDirections[locationID][direction] {
+					v.addWarningAt(RuleBlockedExit, field+"."+locationID+".blocked_exits."+direction,
+						fmt.Sprintf("location '%s' (%s) blocks '%s', which is not one of its exits", locationID, context, direction))
+				}
+			}
+		}
+	}
+	checkExits(s.Locations, "scenario", "locations")
+	for _, sceneID := range slices.Sorted(maps.Keys(s.Scenes)) {
+		checkExits(s.Scenes[sceneID].Locations, "scene "+sceneID, "scenes."+sceneID+".locations", sceneID)
+	}
+
+	if s.OpeningLocation != "" {
+		v.atField("opening_location", func() { checkRef(s.OpeningLocation, "opening_location", s.OpeningScene) })
+	}
+
+	// A conditional's scene change loads before it moves the player
+	for _, sceneID := range slices.Sorted(maps.Keys(s.Scenes)) {
+		conds := s.Scenes[sceneID].Conditionals
+		for _, key := range slices.Sorted(maps.Keys(conds)) {
+			then := conds[key].Then
+			if then.UserLocation == "" {
+				continue
+			}
+			scopes := []string{sceneID}
+			if then.SceneChange != nil && then.SceneChange.To != "" {
+				scopes = []string{then.SceneChange.To}
+			}
+			v.atField("scenes."+sceneID+".conditionals."+key+".then.user_location", func() {
+				checkRef(then.UserLocation, fmt.Sprintf("conditional %s in scene %s then user_location", key, sceneID), scopes...)
+			})
+		}
+	}
+
+	v.validateItemPlacement(s)
+	v.validateItemReferences(s)
+}
+
+// validateItemPlacement flags items that start in more than one place. Items are
+// singletons, so the engine keeps only one copy: inventory wins over NPCs, and NPCs
+// over locations. Each scene is checked with its locations and NPCs layered over the
+// scenario's, as they are when the scene loads.
+func (v *validator) validateItemPlacement(s *scenario.Scenario) {
+	type world struct {
+		name      string
+		locations map[string]scenario.Location
+		npcs      map[string]actor.NPC
+		inventory []string
+	}
+
+	var worlds []world
+	if len(s.Scenes) == 0 || s.OpeningScene == "" {
+		worlds = append(worlds, world{name: "scenario", locations: s.Locations, npcs: s.NPCs, inventory: s.OpeningInventory})
+	}
+	for _, sceneID := range slices.Sorted(maps.Keys(s.Scenes)) {
+		scene := s.Scenes[sceneID]
+		w := world{
+			name:      "scene " + sceneID,
+			locations: maps.Clone(s.Locations),
+			npcs:      maps.Clone(s.NPCs),
+		}
+		if w.locations == nil {
+			w.locations = make(map[string]scenario.Location)
+		}
+		if w.npcs == nil {
+			w.npcs = make(map[string]actor.NPC)
+		}
+		maps.Copy(w.locations, scene.Locations)
+		maps.Copy(w.npcs, scene.NPCs)
+		if sceneID == s.OpeningScene {
+			w.inventory = s.OpeningInventory
+		}
+		worlds = append(worlds, w)
+	}
+
+	// The same duplicate usually shows up in every scene; report it once, naming the
+	// scenes only when it doesn't
+	found := make(map[string][]string) // message → world names
+	var messages []string
+	for _, w := range worlds {
+		holders := make(map[string][]string) // item → places, in priority order
+		for _, item := range w.inventory {
+			holders[s.ItemID(item)] = append(holders[s.ItemID(item)], "opening inventory")
+		}
+		for _, npcID := range slices.Sorted(maps.Keys(w.npcs)) {
+			for _, item := range w.npcs[npcID].Items {
+				holders[s.ItemID(item)] = append(holders[s.ItemID(item)], fmt.Sprintf("NPC '%s'", npcID))
+			}
+		}
+		for _, locationID := range slices.Sorted(maps.Keys(w.locations)) {
+			for _, item := range w.locations[locationID].Items {
+				holders[s.ItemID(item)] = append(holders[s.ItemID(item)], fmt.Sprintf("location '%s'", locationID))
+			}
+		}
+
+		for _, item := range slices.Sorted(maps.Keys(holders)) {
+			places := holders[item]
+			if len(places) < 2 {
+				continue
+			}
+			msg := fmt.Sprintf("item '%s' is in more than one place (%s); only the one in %s is kept",
+				item, strings.Join(places, ", "), places[0])
+			if found[msg] == nil {
+				messages = append(messages, msg)
+			}
+			found[msg] = append(found[msg], w.name)
+		}
+	}
+
+	for _, msg := range messages {
+		if len(found[msg]) < len(worlds) {
+			msg += " in " + strings.Join(found[msg], ", ")
+		}
+		v.addWarning(RuleItemPlacement, msg)
+	}
+}
+
+// validateItemReferences checks that the items the scenario mentions line up: a
+// conditional that needs an item the player can never come by won't fire as written,
+// item details for an item that's never anywhere are dead weight, and names that differ
+// only in case or punctuation are two separate items to the engine, unless the item is
+// catalogued.
+func (v *validator) validateItemReferences(s *scenario.Scenario) {
+	items := s.Items()
+	for _, item := range items.Names() {
+		if items.Obtainable(item) {
+			continue
+		}
+		for _, ref := range items.Refs(item) {
+			if ref.Kind == scenario.ItemUsed {
+				v.addWarningAt(RuleItemObtainable, ref.Field, fmt.Sprintf("%s references item '%s', which is never placed or granted", ref.Field, item))
+			}
+		}
+		if items.Has(item, scenario.ItemDescribed) && !slices.ContainsFunc(items.Names(), func(other string) bool {
+			// /examine matches item details to items in any case
+			return strings.EqualFold(other, item) && items.Has(other, scenario.ItemPlaced, scenario.ItemGranted, scenario.ItemUsed, scenario.ItemCandidate)
+		}) {
+			v.addWarningAt(RuleItemDetails, "item_details."+item, fmt.Sprintf("item_details describes item '%s', which is never placed, granted, or listed in inventory", item))
+		}
+	}
+	for _, names := range items.SimilarNames() {
+		v.addWarning(RuleItemNames, fmt.Sprintf("items '%s' differ only in case or punctuation; the engine treats them as different items", strings.Join(names, "', '")))
+	}
+}
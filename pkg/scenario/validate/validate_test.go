@@ -0,0 +1,194 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+const tinyTale = `{
+  "name": "Tiny Tale",
+  "story": "A short story.",
+  "rating": "G",
+  "opening_location": "hall",
+  "locations": {
+    "hall": {"name": "Hall", "description": "A quiet hall.", "exits": {"north": "yard"}},
+    "yard": {"name": "Yard", "description": "An empty yard.", "exits": {"south": "hall"}}
+  }
+}`
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		filename  string
+		data      string
+		wantValid bool
+		wantError string // substring of an error's message
+		wantLine  int    // line of that error, if not 0
+		wantPath  string // path of that error, if set
+		wantRule  string // rule of that error, if set
+	}{
+		{
+			name:      "valid",
+			filename:  "tiny_tale.json",
+			data:      tinyTale,
+			wantValid: true,
+		},
+		{
+			name:      "filename not snake_case",
+			filename:  "Tiny-Tale.json",
+			data:      tinyTale,
+			wantError: "must be lowercase snake_case",
+			wantRule:  RuleFilename,
+		},
+		{
+			name:      "invalid JSON",
+			filename:  "tiny_tale.json",
+			data:      "{\n  \"name\": \"Tiny Tale\",\n",
+			wantError: "contains invalid JSON",
+			wantRule:  RuleJSONSyntax,
+		},
+		{
+			name:      "unknown field",
+			filename:  "tiny_tale.json",
+			data:      strings.Replace(tinyTale, `"story"`, `"stroy"`, 1),
+			wantError: `unknown field "stroy"`,
+			wantLine:  3,
+			wantRule:  RuleStrictJSON,
+		},
+		{
+			name:      "exit to an undefined location",
+			filename:  "tiny_tale.json",
+			data:      strings.Replace(tinyTale, `{"north": "yard"}`, `{"north": "garden"}`, 1),
+			wantError: "undefined location 'garden'",
+			wantLine:  7,
+			wantPath:  "locations.hall.exits.north",
+			wantRule:  RuleLocationReference,
+		},
+		{
+			name:      "location id not snake_case",
+			filename:  "tiny_tale.json",
+			data:      strings.Replace(tinyTale, `"hall": {"name"`, `"hall": {"id": "Great Hall", "name"`, 1),
+			wantError: "location id 'Great Hall' should be lowercase snake_case",
+			wantPath:  "locations.hall",
+			wantRule:  RuleIDFormat,
+		},
+		{
+			name:      "catalogued item without a name",
+			filename:  "tiny_tale.json",
+			data:      strings.Replace(tinyTale, `"rating": "G",`, `"rating": "G", "items": {"lantern": {"name": ""}},`, 1),
+			wantError: "item 'lantern' has no name",
+			wantPath:  "items.lantern",
+			wantRule:  RuleItemNames,
+		},
+		{
+			name:      "language stricter than the rating",
+			filename:  "tiny_tale.json",
+			data:      strings.Replace(tinyTale, "A short story.", "A short damn story.", 1),
+			wantError: "rating 'G' is stricter than the scenario's language allows",
+			wantRule:  RuleRatingLanguage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := JSON(tt.filename, []byte(tt.data))
+			if result.Valid() != tt.wantValid {
+				t.Fatalf("Valid() = %v, want %v; errors: %v", result.Valid(), tt.wantValid, result.Errors)
+			}
+			if tt.wantError == "" {
+				return
+			}
+			for _, f := range result.Errors {
+				if !strings.Contains(f.Message, tt.wantError) {
+					continue
+				}
+				if f.File != tt.filename || f.Severity != SeverityError {
+					t.Errorf("Expected an error in %s, got %+v", tt.filename, f)
+				}
+				if tt.wantLine != 0 && f.Line != tt.wantLine {
+					t.Errorf("Expected line %d, got %d", tt.wantLine, f.Line)
+				}
+				if tt.wantPath != "" && f.Path != tt.wantPath {
+					t.Errorf("Expected path %q, got %q", tt.wantPath, f.Path)
+				}
+				if tt.wantRule != "" && f.Rule != tt.wantRule {
+					t.Errorf("Expected rule %q, got %q", tt.wantRule, f.Rule)
+				}
+				return
+			}
+			t.Errorf("Expected an error containing %q, got %v", tt.wantError, result.Errors)
+		})
+	}
+}
+
+func TestScenario(t *testing.T) {
+	s := &scenario.Scenario{
+		Name:            "Tiny Tale",
+		OpeningLocation: "hall",
+		Locations: map[string]scenario.Location{
+			"hall": {Name: "Hall", Items: []string{"lantern"}},
+		},
+		ItemDetails: map[string]string{"crown": "A tarnished crown."},
+	}
+	result := Scenario(s)
+	if !result.Valid() {
+		t.Fatalf("Expected the scenario to be valid, got %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Path != "item_details.crown" {
+		t.Errorf("Expected one warning about item_details.crown, got %v", result.Warnings)
+	}
+	if result.Warnings[0].Line != 0 {
+		t.Errorf("Expected no line without a document, got %d", result.Warnings[0].Line)
+	}
+}
+
+func TestResult_Err(t *testing.T) {
+	result := JSON("tiny_tale.json", []byte(strings.Replace(tinyTale, `{"north": "yard"}`, `{"north": "garden"}`, 1)))
+	err := result.Err()
+	if err == nil {
+		t.Fatal("Expected an error for an undefined exit")
+	}
+	want := "tiny_tale.json:7: locations.hall.exits.north: location 'hall' (scenario) exit 'north' leads to undefined location 'garden' (location_reference)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	var f Finding
+	if !errors.As(err, &f) || f.Rule != RuleLocationReference {
+		t.Errorf("Expected errors.As to recover the finding, got %+v", f)
+	}
+
+	if err := JSON("tiny_tale.json", []byte(tinyTale)).Err(); err != nil {
+		t.Errorf("Expected no error for a valid scenario, got %v", err)
+	}
+}
+
+func TestIndexLines(t *testing.T) {
+	data := []byte(`{
+  "a": {
+    "b": [
+      1,
+      {"c": true}
+    ]
+  }
+}`)
+	idx := indexLines(data)
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"a", 2},
+		{"a.b", 3},
+		{"a.b[0]", 4},
+		{"a.b[1].c", 5},
+		{"a.b[1].missing", 5}, // falls back to the nearest parent
+		{"nothing", 0},
+	}
+	for _, tt := range tests {
+		if got := idx.Line(tt.path); got != tt.want {
+			t.Errorf("Line(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
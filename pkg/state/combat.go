@@ -0,0 +1,119 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/combat"
+)
+
+// ErrNoCombat is returned when a combat round is played with no fight in progress
+var ErrNoCombat = errors.New("no fight in progress")
+
+// StartCombat starts a fight between the PC and foes, given as NPC IDs or monster
+// instance IDs, replacing any finished fight. Initiative is rolled from the game's seed.
+func (gs *GameState) StartCombat(reason string, foes []string) error {
+	if gs.Combat != nil && !gs.Combat.Over() {
+		return errors.New("a fight is already in progress")
+	}
+	pc, err := combat.FromPC(gs.PC)
+	if err != nil {
+		return err
+	}
+	combatants := []combat.Combatant{pc}
+	for _, id := range foes {
+		var foe combat.Combatant
+		if npc, ok := gs.NPCs[id]; ok {
+			foe, err = combat.FromNPC(id, npc)
+		} else if m := gs.findMonster(id); m != nil {
+			foe, err = combat.FromMonster(m)
+		} else {
+			err = fmt.Errorf("unknown foe %s", id)
+		}
+		if err != nil {
+			return err
+		}
+		combatants = append(combatants, foe)
+	}
+
+	c, err := combat.Start(reason, combatants, newRoller(gs, "initiative"))
+	if err != nil {
+		return err
+	}
+	gs.Combat = c
+	return nil
+}
+
+// PlayCombatRound plays a round of the fight, with the PC attacking target, or no one
+// if target is empty. HP lost is written back to the PC, NPCs, and monsters, and
+// defeated monsters are despawned. Returns ErrNoCombat if no fight is in progress.
+func (gs *GameState) PlayCombatRound(target string) error {
+	if gs.Combat == nil || gs.Combat.Over() {
+		return ErrNoCombat
+	}
+	if target != "" {
+		foe := gs.Combat.Find(target)
+		if foe == nil {
+			return fmt.Errorf("%w: %s", combat.ErrUnknownCombatant, target)
+		}
+		target = foe.ID
+	}
+	if err := gs.Combat.PlayRound(target, newRoller(gs, "combat")); err != nil {
+		return err
+	}
+	gs.syncCombatHP()
+	return nil
+}
+
+// EndCombat ends the fight, if any
+func (gs *GameState) EndCombat() {
+	gs.Combat = nil
+}
+
+// CombatNarrated is called once a narration has told the fight's last round: the round
+// is dropped, and a finished fight ends
+func (gs *GameState) CombatNarrated() {
+	if gs.Combat == nil {
+		return
+	}
+	if gs.Combat.Over() {
+		gs.Combat = nil
+		return
+	}
+	gs.Combat.LastRound = nil
+}
+
+// syncCombatHP writes the combatants' HP back to the actors they stand for
+func (gs *GameState) syncCombatHP() {
+	for _, cb := range gs.Combat.Combatants {
+		switch cb.Kind {
+		case combat.KindPC:
+			if gs.PC != nil && gs.PC.Actor != nil {
+				gs.PC.Actor.SubHP(gs.PC.Actor.HP() - cb.HP)
+			}
+		case combat.KindNPC:
+			if npc, ok := gs.NPCs[cb.ID]; ok {
+				npc.HP = cb.HP
+				gs.NPCs[cb.ID] = npc
+			}
+		case combat.KindMonster:
+			if m := gs.findMonster(cb.ID); m != nil {
+				m.HP = cb.HP
+				if m.IsDefeated() {
+					gs.DespawnMonster(cb.ID)
+				}
+			}
+		}
+	}
+}
+
+// findMonster returns the active monster instance with the ID, or nil
+func (gs *GameState) findMonster(instanceID string) *actor.Monster {
+	for _, loc := range gs.WorldLocations {
+		if m, ok := loc.Monsters[instanceID]; ok && m != nil {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package state
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/combat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func combatGame(t *testing.T) *GameState {
+	t.Helper()
+	pc, err := actor.NewPCFromSpec(&actor.PCSpec{
+		ID:              "korga",
+		Name:            "Korga",
+		Stats:           actor.Stats5e{Strength: 16, Dexterity: 14},
+		MaxHP:           500,
+		AC:              1,
+		CombatModifiers: map[string]int{"strength": 30},
+	})
+	if err != nil {
+		t.Fatalf("NewPCFromSpec() error = %v", err)
+	}
+	return &GameState{
+		Seed:     11,
+		PC:       pc,
+		Location: "bridge",
+		NPCs: map[string]actor.NPC{
+			"bandit":    {Name: "Bandit", Location: "bridge", AC: 12, HP: 9, MaxHP: 9, CombatMods: map[string]int{"club": 2}},
+			"innkeeper": {Name: "Innkeeper", Location: "inn"},
+		},
+		WorldLocations: map[string]scenario.Location{
+			"bridge": {Name: "Bridge", Monsters: map[string]*actor.Monster{
+				"wolf_1": {ID: "wolf_1", Name: "Wolf", AC: 11, HP: 2, MaxHP: 2, Items: []string{"wolf pelt"}, DropItemsOnDefeat: true},
+			}},
+		},
+	}
+}
+
+func TestGameState_StartCombat(t *testing.T) {
+	tests := []struct {
+		name    string
+		foes    []string
+		wantErr bool
+	}{
+		{"NPC and monster", []string{"bandit", "wolf_1"}, false},
+		{"unknown foe", []string{"dragon"}, true},
+		{"NPC without hit points", []string{"innkeeper"}, true},
+		{"no foes", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := combatGame(t)
+			err := gs.StartCombat("ambush", tt.foes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StartCombat() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if gs.Combat != nil {
+					t.Errorf("Expected no fight, got %+v", gs.Combat)
+				}
+				return
+			}
+			if len(gs.Combat.Combatants) != len(tt.foes)+1 {
+				t.Errorf("Expected the PC and %d foes, got %+v", len(tt.foes), gs.Combat.Combatants)
+			}
+			if err := gs.StartCombat("again", tt.foes); err == nil {
+				t.Error("Expected an error starting a second fight")
+			}
+		})
+	}
+}
+
+func TestGameState_PlayCombatRound(t *testing.T) {
+	gs := combatGame(t)
+	if err := gs.PlayCombatRound("wolf"); err != ErrNoCombat {
+		t.Fatalf("Expected ErrNoCombat, got %v", err)
+	}
+	if err := gs.StartCombat("ambush", []string{"bandit", "wolf_1"}); err != nil {
+		t.Fatalf("StartCombat() error = %v", err)
+	}
+
+	// Foes can be named as the narrator knows them
+	for !gs.Combat.Over() {
+		target := "Wolf"
+		if gs.Combat.Get("wolf_1").Defeated() {
+			target = "Bandit"
+		}
+		if err := gs.PlayCombatRound(target); err != nil {
+			t.Fatalf("PlayCombatRound() error = %v", err)
+		}
+		if gs.Combat.Round > 50 {
+			t.Fatal("Expected the fight to be decided")
+		}
+	}
+
+	if gs.Combat.Outcome != combat.OutcomeVictory {
+		t.Fatalf("Expected victory, got %s", gs.Combat.Outcome)
+	}
+	if got := gs.PC.Actor.HP(); got != gs.Combat.PC().HP {
+		t.Errorf("Expected PC HP %d, got %d", gs.Combat.PC().HP, got)
+	}
+	if got := gs.NPCs["bandit"].HP; got != 0 {
+		t.Errorf("Expected the bandit at 0 HP, got %d", got)
+	}
+	if _, ok := gs.WorldLocations["bridge"].Monsters["wolf_1"]; ok {
+		t.Error("Expected the defeated wolf to be despawned")
+	}
+	if items := gs.WorldLocations["bridge"].Items; len(items) != 1 || items[0] != "wolf pelt" {
+		t.Errorf("Expected the wolf's pelt to drop, got %v", items)
+	}
+
+	// The narration of the last round ends a finished fight
+	gs.CombatNarrated()
+	if gs.Combat != nil {
+		t.Errorf("Expected the fight to end once narrated, got %+v", gs.Combat)
+	}
+}
+
+func TestDeltaWorker_Combat(t *testing.T) {
+	gs := combatGame(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// A conditional starts the fight; applying again for the cascade doesn't restart it
+	start := &conditionals.GameStateDelta{StartCombat: &conditionals.CombatStart{Foes: []string{"Bandit"}, Reason: "ambush"}}
+	worker := NewDeltaWorker(gs, start, nil, logger)
+	for range 2 {
+		if err := worker.Apply(); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+	}
+	if gs.Combat == nil || gs.Combat.Get("bandit") == nil {
+		t.Fatalf("Expected a fight with the bandit, got %+v", gs.Combat)
+	}
+
+	// The model's attack plays one round
+	attack := &conditionals.GameStateDelta{Attack: &conditionals.CombatAttack{Target: "bandit"}}
+	worker = NewDeltaWorker(gs, attack, nil, logger)
+	if issues := worker.Validate(); len(issues) != 0 {
+		t.Fatalf("Expected a valid attack, got %+v", issues)
+	}
+	for range 2 {
+		if err := worker.Apply(); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+	}
+	if gs.Combat.Round != 1 {
+		t.Errorf("Expected one round played, got %d", gs.Combat.Round)
+	}
+
+	// A conditional ends it
+	end := &conditionals.GameStateDelta{EndCombat: true}
+	if err := NewDeltaWorker(gs, end, nil, logger).Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if gs.Combat != nil {
+		t.Errorf("Expected the fight to end, got %+v", gs.Combat)
+	}
+}
+
+func TestDeltaWorker_ValidateAttack(t *testing.T) {
+	tests := []struct {
+		name       string
+		fight      bool
+		target     string
+		wantTarget string
+		wantIssue  string
+	}{
+		{"no fight", false, "bandit", "", "no fight in progress"},
+		{"unknown foe", true, "dragon", "", "unknown foe"},
+		{"PC is no foe", true, "Korga", "", "unknown foe"},
+		{"foe by name", true, "BANDIT", "bandit", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := combatGame(t)
+			if tt.fight {
+				if err := gs.StartCombat("", []string{"bandit"}); err != nil {
+					t.Fatalf("StartCombat() error = %v", err)
+				}
+			}
+			delta := &conditionals.GameStateDelta{
+				Attack:      &conditionals.CombatAttack{Target: tt.target},
+				StartCombat: &conditionals.CombatStart{Foes: []string{"wolf_1"}},
+			}
+			issues := NewDeltaWorker(gs, delta, nil, nil).Validate()
+
+			// start_combat is for conditionals only
+			if len(issues) == 0 || issues[len(issues)-1].Path != "start_combat" || delta.StartCombat != nil {
+				t.Errorf("Expected start_combat dropped, got %+v", issues)
+			}
+			if tt.wantIssue == "" {
+				if len(issues) != 1 || delta.Attack == nil || delta.Attack.Target != tt.wantTarget {
+					t.Errorf("Expected attack on %s, got %+v and issues %+v", tt.wantTarget, delta.Attack, issues)
+				}
+				return
+			}
+			if issues[0].Reason != tt.wantIssue || delta.Attack != nil {
+				t.Errorf("Expected attack dropped for %q, got %+v", tt.wantIssue, issues)
+			}
+		})
+	}
+}
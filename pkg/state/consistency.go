@@ -0,0 +1,24 @@
+package state
+
+// Kinds of NarrationIssue
+const (
+	IssueItemNotHeld   = "item_not_held"   // the player used or had an item they don't hold
+	IssueNPCNotPresent = "npc_not_present" // an NPC who isn't at the player's location appeared or acted
+	IssueBlockedExit   = "blocked_exit"    // the player went through a blocked or missing exit
+)
+
+// NarrationIssue is a place where a turn's narration contradicts the game state,
+// found by the consistency check
+type NarrationIssue struct {
+	Kind   string `json:"kind"`   // IssueItemNotHeld, IssueNPCNotPresent, or IssueBlockedExit
+	Detail string `json:"detail"` // What the narration got wrong, e.g. "The player lights the lantern, which they don't hold"
+}
+
+// ValidNarrationIssueKind reports whether kind is one of the kinds of NarrationIssue
+func ValidNarrationIssueKind(kind string) bool {
+	switch kind {
+	case IssueItemNotHeld, IssueNPCNotPresent, IssueBlockedExit:
+		return true
+	}
+	return false
+}
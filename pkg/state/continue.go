@@ -0,0 +1,54 @@
+package state
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+)
+
+// ErrNoNarration is returned by ExtendNarration when the latest message isn't a narration
+var ErrNoNarration = errors.New("no narration to continue")
+
+// LastNarration returns the latest message of the chat history, or of the side
+// conversation's history while one is open, if it is a narration; nil otherwise
+func (gs *GameState) LastNarration() *chat.ChatMessage {
+	history := gs.ChatHistory
+	if gs.Conversation != nil {
+		history = gs.Conversation.History
+	}
+	if len(history) == 0 || history[len(history)-1].Role != chat.ChatRoleAgent {
+		return nil
+	}
+	return &history[len(history)-1]
+}
+
+// ExtendNarration appends more narration to the latest one, as when the narrator is
+// asked to continue it, and marks it incomplete or not by how the continuation ended.
+// No turn is added. Returns ErrNoNarration if the latest message isn't a narration.
+func (gs *GameState) ExtendNarration(more string, incomplete bool) error {
+	last := gs.LastNarration()
+	if last == nil {
+		return ErrNoNarration
+	}
+	last.Content = JoinNarration(last.Content, strings.TrimRight(more, "\n"))
+	last.Incomplete = incomplete
+	return nil
+}
+
+// JoinNarration joins a continuation to the narration it continues, with a space
+// unless there is already whitespace at the seam or the continuation starts with
+// punctuation, such as one that finishes a cut-off sentence
+func JoinNarration(narration, more string) string {
+	if narration == "" || more == "" {
+		return narration + more
+	}
+	last, _ := utf8.DecodeLastRuneInString(narration)
+	first, _ := utf8.DecodeRuneInString(more)
+	if unicode.IsSpace(last) || unicode.IsSpace(first) || unicode.IsPunct(first) {
+		return narration + more
+	}
+	return narration + " " + more
+}
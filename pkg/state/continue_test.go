@@ -0,0 +1,80 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+)
+
+func TestGameState_ExtendNarration(t *testing.T) {
+	tests := []struct {
+		name         string
+		history      []chat.ChatMessage
+		conversation bool
+		more         string
+		incomplete   bool
+		expected     string
+		expectedErr  error
+	}{
+		{
+			name:     "finishes a sentence",
+			history:  []chat.ChatMessage{{Role: chat.ChatRoleUser, Content: "Look"}, {Role: chat.ChatRoleAgent, Content: "The fog lifts", Incomplete: true}},
+			more:     ", and the harbor appears.\n",
+			expected: "The fog lifts, and the harbor appears.",
+		},
+		{
+			name:       "adds a sentence",
+			history:    []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "The fog lifts."}},
+			more:       "Gulls cry",
+			incomplete: true,
+			expected:   "The fog lifts. Gulls cry",
+		},
+		{
+			name:     "keeps a paragraph break",
+			history:  []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "The fog lifts."}},
+			more:     "\n\nGulls cry.",
+			expected: "The fog lifts.\n\nGulls cry.",
+		},
+		{
+			name:         "side conversation",
+			history:      []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "Gibbs nods"}},
+			conversation: true,
+			more:         "slowly.",
+			expected:     "Gibbs nods slowly.",
+		},
+		{
+			name:        "player message last",
+			history:     []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "The fog lifts."}, {Role: chat.ChatRoleUser, Content: "Look"}},
+			more:        "More.",
+			expectedErr: ErrNoNarration,
+		},
+		{name: "empty history", more: "More.", expectedErr: ErrNoNarration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := NewGameState("test.json", nil, "test-model")
+			if tt.conversation {
+				gs.Conversation = &Conversation{NPCID: "gibbs", History: tt.history}
+			} else {
+				gs.ChatHistory = tt.history
+			}
+
+			err := gs.ExtendNarration(tt.more, tt.incomplete)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			last := gs.LastNarration()
+			if last.Content != tt.expected {
+				t.Errorf("Expected narration %q, got %q", tt.expected, last.Content)
+			}
+			if last.Incomplete != tt.incomplete {
+				t.Errorf("Expected incomplete %v, got %v", tt.incomplete, last.Incomplete)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package state
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+)
+
+// ConversationMemoryLimit is the number of messages an NPC remembers from past
+// conversations with the player
+const ConversationMemoryLimit = 20
+
+// Conversation is a side conversation with one NPC. While it is open, turns are
+// narrated with only that NPC's details and memories and are kept in their own
+// history, so the main story thread is untouched until the conversation ends.
+type Conversation struct {
+	NPCID     string             `json:"npc_id"`
+	StartTurn int                `json:"start_turn"`        // TurnCounter when the conversation started
+	History   []chat.ChatMessage `json:"history,omitempty"` // Messages exchanged in this conversation
+}
+
+// FindNPCsHere finds NPCs at the player's location by ID or name, ignoring case, and
+// returns their IDs. An exact match is returned alone; otherwise every NPC whose name
+// contains name is returned.
+func (gs *GameState) FindNPCsHere(name string) []string {
+	name = strings.TrimSpace(name)
+	var partial []string
+	for _, id := range slices.Sorted(maps.Keys(gs.NPCs)) {
+		npc := gs.NPCs[id]
+		if npc.Location != gs.Location {
+			continue
+		}
+		if strings.EqualFold(id, name) || strings.EqualFold(npc.Name, name) {
+			return []string{id}
+		}
+		if strings.Contains(strings.ToLower(npc.Name), strings.ToLower(name)) {
+			partial = append(partial, id)
+		}
+	}
+	return partial
+}
+
+// StartConversation opens a side conversation with the NPC, ending any other one first
+func (gs *GameState) StartConversation(npcID string) error {
+	if _, ok := gs.NPCs[npcID]; !ok {
+		return fmt.Errorf("NPC %s not found", npcID)
+	}
+	if gs.Conversation != nil {
+		gs.EndConversation()
+	}
+	gs.Conversation = &Conversation{NPCID: npcID, StartTurn: gs.TurnCounter}
+	return nil
+}
+
+// NPCName returns the NPC's display name, or its ID if it has none
+func (gs *GameState) NPCName(npcID string) string {
+	if npc, ok := gs.NPCs[npcID]; ok && npc.Name != "" {
+		return npc.Name
+	}
+	return npcID
+}
+
+// EndConversation closes the side conversation, if any, and returns the NPC's ID.
+// If anything was said, the NPC keeps it as memories and the main history records
+// that the conversation took place, so the narrator picks the story up from there.
+func (gs *GameState) EndConversation() (string, bool) {
+	conv := gs.Conversation
+	if conv == nil {
+		return "", false
+	}
+	gs.Conversation = nil
+
+	if len(conv.History) == 0 {
+		return conv.NPCID, true
+	}
+
+	if gs.NPCMemories == nil {
+		gs.NPCMemories = make(map[string][]chat.ChatMessage)
+	}
+	memories := append(gs.NPCMemories[conv.NPCID], conv.History...)
+	if len(memories) > ConversationMemoryLimit {
+		memories = memories[len(memories)-ConversationMemoryLimit:]
+	}
+	gs.NPCMemories[conv.NPCID] = memories
+	gs.noteNPCInteraction(conv.NPCID)
+
+	name := gs.NPCName(conv.NPCID)
+	gs.ChatHistory = append(gs.ChatHistory,
+		chat.ChatMessage{Role: chat.ChatRoleUser, Content: fmt.Sprintf("I talk with %s for a while.", name)},
+		chat.ChatMessage{Role: chat.ChatRoleAgent, Content: fmt.Sprintf("You finish your conversation with %s.", name)},
+	)
+	return conv.NPCID, true
+}
@@ -0,0 +1,97 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+)
+
+func TestFindNPCsHere(t *testing.T) {
+	gs := &GameState{
+		Location: "dock",
+		NPCs: map[string]actor.NPC{
+			"gibbs":        {Name: "Gibbs", Location: "dock"},
+			"old_tom":      {Name: "Old Tom", Location: "dock"},
+			"tom_thumb":    {Name: "Tom Thumb", Location: "dock"},
+			"harbormaster": {Name: "Harbormaster", Location: "office"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"gibbs", []string{"gibbs"}},
+		{"GIBBS", []string{"gibbs"}},
+		{"old_tom", []string{"old_tom"}},
+		{"tom", []string{"old_tom", "tom_thumb"}},
+		{"Old Tom", []string{"old_tom"}},
+		{"harbormaster", nil},
+		{"anne", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gs.FindNPCsHere(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindNPCsHere(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConversation_StartAndEnd(t *testing.T) {
+	gs := &GameState{
+		TurnCounter: 5,
+		NPCs:        map[string]actor.NPC{"gibbs": {Name: "Gibbs"}},
+		ChatHistory: []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "The tide turns."}},
+	}
+
+	if err := gs.StartConversation("anne"); err == nil {
+		t.Error("expected error for unknown NPC")
+	}
+	if err := gs.StartConversation("gibbs"); err != nil {
+		t.Fatalf("StartConversation() error: %v", err)
+	}
+	if gs.Conversation == nil || gs.Conversation.NPCID != "gibbs" || gs.Conversation.StartTurn != 5 {
+		t.Fatalf("unexpected conversation: %+v", gs.Conversation)
+	}
+
+	for i := 0; i < ConversationMemoryLimit; i++ {
+		gs.Conversation.History = append(gs.Conversation.History, chat.ChatMessage{Role: chat.ChatRoleUser, Content: string(rune('a' + i))})
+	}
+	gs.NPCMemories = map[string][]chat.ChatMessage{"gibbs": {{Role: chat.ChatRoleUser, Content: "oldest"}}}
+
+	npcID, ok := gs.EndConversation()
+	if !ok || npcID != "gibbs" || gs.Conversation != nil {
+		t.Fatalf("EndConversation() = %q, %v; conversation %+v", npcID, ok, gs.Conversation)
+	}
+	memories := gs.NPCMemories["gibbs"]
+	if len(memories) != ConversationMemoryLimit || memories[0].Content != "a" {
+		t.Errorf("expected the last %d messages as memories, got %d starting %q", ConversationMemoryLimit, len(memories), memories[0].Content)
+	}
+	if len(gs.ChatHistory) != 3 || gs.ChatHistory[2].Content != "You finish your conversation with Gibbs." {
+		t.Errorf("expected the conversation recorded in the main history, got %+v", gs.ChatHistory)
+	}
+	if gs.NPCInteractions["gibbs"] != 5 {
+		t.Errorf("expected the conversation to count as an interaction, got %v", gs.NPCInteractions)
+	}
+
+	if _, ok := gs.EndConversation(); ok {
+		t.Error("expected no conversation to end")
+	}
+}
+
+func TestConversation_EndWithoutMessages(t *testing.T) {
+	gs := &GameState{NPCs: map[string]actor.NPC{"gibbs": {Name: "Gibbs"}}}
+	if err := gs.StartConversation("gibbs"); err != nil {
+		t.Fatalf("StartConversation() error: %v", err)
+	}
+	if _, ok := gs.EndConversation(); !ok {
+		t.Fatal("expected the conversation to end")
+	}
+	if len(gs.ChatHistory) != 0 || gs.NPCMemories != nil {
+		t.Errorf("empty conversation should leave no trace, got history %+v, memories %+v", gs.ChatHistory, gs.NPCMemories)
+	}
+}
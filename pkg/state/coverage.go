@@ -0,0 +1,117 @@
+package state
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+// Coverage reports which of a scenario's scenes, conditionals, and story events a set of
+// playthroughs reached, like code coverage for narrative content. Content that no
+// playthrough reached is either untested or unreachable.
+type Coverage struct {
+	Games        int          `json:"games"`        // Playthroughs counted
+	Scenes       CoverageKind `json:"scenes"`       // By scene name
+	Conditionals CoverageKind `json:"conditionals"` // By "scene/conditional"
+	StoryEvents  CoverageKind `json:"story_events"` // By story event ID, as in FiredStoryEvents
+}
+
+// CoverageKind is the coverage of one kind of content
+type CoverageKind struct {
+	Hit   int            `json:"hit"`   // Items reached by at least one playthrough
+	Total int            `json:"total"` // Items in the scenario
+	Items []CoverageItem `json:"items"` // Every item, sorted by ID
+}
+
+// CoverageItem is one piece of content and how many playthroughs reached it
+type CoverageItem struct {
+	ID   string `json:"id"`
+	Hits int    `json:"hits"` // Playthroughs that reached it; 0 when never hit
+}
+
+// NewCoverage measures the scenario's coverage by the given playthroughs, each the game
+// state at its end. Games only record where they are and what has fired, so a scene
+// counts as visited when the game started or ended in it, fired a conditional in it,
+// or narrated its opening prompt.
+func NewCoverage(s *scenario.Scenario, games ...*GameState) *Coverage {
+	scenes := make(map[string]int)
+	conds := make(map[string]int)
+	events := make(map[string]int)
+
+	for name, scene := range s.Scenes {
+		scenes[name] = 0
+		for id, c := range scene.Conditionals {
+			conds[name+"/"+id] = 0
+			if c.Then.Prompt != nil {
+				events[id] = 0
+			}
+		}
+		if name != s.OpeningScene && strings.TrimSpace(scene.OpeningPrompt) != "" {
+			events[sceneOpeningEventPrefix+name] = 0
+		}
+	}
+
+	for _, gs := range games {
+		if gs == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		visit := func(counts map[string]int, id string) {
+			if _, ok := counts[id]; ok && !seen[id] {
+				counts[id]++
+				seen[id] = true
+			}
+		}
+		visit(scenes, s.OpeningScene)
+		visit(scenes, gs.SceneName)
+		for id, firing := range gs.FiredConditionals {
+			visit(scenes, firing.Scene)
+			visit(conds, firing.Scene+"/"+id)
+		}
+		for _, id := range gs.FiredStoryEvents {
+			visit(events, id)
+			if scene, ok := strings.CutPrefix(id, sceneOpeningEventPrefix); ok {
+				visit(scenes, scene)
+			}
+		}
+	}
+
+	return &Coverage{
+		Games:        len(games),
+		Scenes:       newCoverageKind(scenes),
+		Conditionals: newCoverageKind(conds),
+		StoryEvents:  newCoverageKind(events),
+	}
+}
+
+func newCoverageKind(counts map[string]int) CoverageKind {
+	k := CoverageKind{Total: len(counts), Items: make([]CoverageItem, 0, len(counts))}
+	for _, id := range slices.Sorted(maps.Keys(counts)) {
+		k.Items = append(k.Items, CoverageItem{ID: id, Hits: counts[id]})
+		if counts[id] > 0 {
+			k.Hit++
+		}
+	}
+	return k
+}
+
+// Percent returns the share of items hit, from 0 to 100. A kind with no items is fully covered.
+func (k CoverageKind) Percent() float64 {
+	if k.Total == 0 {
+		return 100
+	}
+	return float64(k.Hit) * 100 / float64(k.Total)
+}
+
+// Missed returns the IDs of the items no playthrough reached
+func (k CoverageKind) Missed() []string {
+	var missed []string
+	for _, item := range k.Items {
+		if item.Hits == 0 {
+			missed = append(missed, item.ID)
+		}
+	}
+	return missed
+}
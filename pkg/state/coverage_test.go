@@ -0,0 +1,133 @@
+package state
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestNewCoverage(t *testing.T) {
+	prompt := "The kraken rises."
+	s := &scenario.Scenario{
+		OpeningScene: "docks",
+		Scenes: map[string]scenario.Scene{
+			"docks": {
+				OpeningPrompt: "Narrated by the scenario's opening instead.",
+				Conditionals: map[string]scenario.Conditional{
+					"board_ship": {},
+					"pay_toll":   {},
+				},
+			},
+			"sea": {
+				OpeningPrompt: "The harbor falls away behind you.",
+				Conditionals: map[string]scenario.Conditional{
+					"kraken": {Then: conditionals.GameStateDelta{Prompt: &prompt}},
+				},
+			},
+			"island": {},
+		},
+	}
+
+	boarded := &GameState{
+		SceneName: "sea",
+		FiredConditionals: map[string]ConditionalFiring{
+			"board_ship": {Turn: 3, Scene: "docks", Count: 1},
+		},
+		FiredStoryEvents: []string{"scene_opening:sea"},
+	}
+	kraken := &GameState{
+		SceneName: "sea",
+		FiredConditionals: map[string]ConditionalFiring{
+			"board_ship": {Turn: 2, Scene: "docks", Count: 1},
+			"kraken":     {Turn: 5, Scene: "sea", Count: 2},
+		},
+		FiredStoryEvents: []string{"scene_opening:sea", "kraken", "unknown_event"},
+	}
+	stayed := &GameState{SceneName: "docks"}
+
+	cov := NewCoverage(s, boarded, kraken, stayed)
+
+	if cov.Games != 3 {
+		t.Errorf("Expected 3 games, got %d", cov.Games)
+	}
+
+	tests := []struct {
+		name       string
+		kind       CoverageKind
+		wantHits   map[string]int
+		wantMissed []string
+	}{
+		{
+			name:       "scenes",
+			kind:       cov.Scenes,
+			wantHits:   map[string]int{"docks": 3, "sea": 2, "island": 0},
+			wantMissed: []string{"island"},
+		},
+		{
+			name:       "conditionals",
+			kind:       cov.Conditionals,
+			wantHits:   map[string]int{"docks/board_ship": 2, "docks/pay_toll": 0, "sea/kraken": 1},
+			wantMissed: []string{"docks/pay_toll"},
+		},
+		{
+			name:     "story events",
+			kind:     cov.StoryEvents,
+			wantHits: map[string]int{"kraken": 1, "scene_opening:sea": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.kind.Total != len(tt.wantHits) {
+				t.Errorf("Expected %d items, got %d: %+v", len(tt.wantHits), tt.kind.Total, tt.kind.Items)
+			}
+			hit := 0
+			for _, item := range tt.kind.Items {
+				want, ok := tt.wantHits[item.ID]
+				if !ok {
+					t.Errorf("Unexpected item %q", item.ID)
+					continue
+				}
+				if item.Hits != want {
+					t.Errorf("Expected %q to be hit %d times, got %d", item.ID, want, item.Hits)
+				}
+				if item.Hits > 0 {
+					hit++
+				}
+			}
+			if tt.kind.Hit != hit {
+				t.Errorf("Expected %d hit, got %d", hit, tt.kind.Hit)
+			}
+			if !slices.IsSortedFunc(tt.kind.Items, func(a, b CoverageItem) int { return strings.Compare(a.ID, b.ID) }) {
+				t.Errorf("Expected items sorted by ID, got %+v", tt.kind.Items)
+			}
+			if missed := tt.kind.Missed(); !slices.Equal(missed, tt.wantMissed) {
+				t.Errorf("Expected missed %v, got %v", tt.wantMissed, missed)
+			}
+		})
+	}
+}
+
+func TestCoverageKind_Percent(t *testing.T) {
+	tests := []struct {
+		name string
+		kind CoverageKind
+		want float64
+	}{
+		{"none hit", CoverageKind{Hit: 0, Total: 4}, 0},
+		{"some hit", CoverageKind{Hit: 1, Total: 4}, 25},
+		{"all hit", CoverageKind{Hit: 4, Total: 4}, 100},
+		{"nothing to hit", CoverageKind{}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.kind.Percent(); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package state
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/combat"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
+
+// What Validate did with an invalid piece of a delta
+const (
+	IssueDropped  = "dropped"  // removed from the delta
+	IssueRepaired = "repaired" // corrected and kept
+)
+
+// DeltaIssue describes a piece of a model-produced delta that failed validation
+type DeltaIssue struct {
+	Path   string `json:"path"`            // Field path, e.g. "item_events[1].to.name"
+	Value  string `json:"value,omitempty"` // Offending value as the model sent it
+	Reason string `json:"reason"`
+	Fix    string `json:"fix"` // IssueDropped or IssueRepaired
+}
+
+// Validate checks the delta against the enums and required fields of
+// conditionals.DeltaSchema and against the scenes, locations, NPCs, monsters
+// and vars of the game. Pieces with an obvious fix are repaired; the rest are
+// dropped, so Apply never sees half-valid model output. Call it before
+// ApplyVars and Apply, and before merging conditionals, whose deltas are
+// authored by the scenario and trusted.
+func (dw *DeltaWorker) Validate() []DeltaIssue {
+	if dw.delta == nil || dw.gs == nil {
+		return nil
+	}
+
+	var issues []DeltaIssue
+	report := func(path, value, reason, fix string) {
+		issues = append(issues, DeltaIssue{Path: path, Value: value, Reason: reason, Fix: fix})
+	}
+	d := dw.delta
+
+	// Apply loads a new scene before anything else, so references are checked
+	// against the game as it will be once the scene's locations, NPCs and vars are in
+	ref := dw
+	if d.SceneChange != nil {
+		to := d.SceneChange.To
+		if to != "" && to != dw.gs.SceneName {
+			if dw.scenario == nil || !dw.scenario.HasScene(to) {
+				report("scene_change.to", to, "unknown scene", IssueDropped)
+				d.SceneChange = nil
+			} else if preview, err := dw.gs.DeepCopy(); err == nil && preview.LoadScene(dw.scenario, to) == nil {
+				ref = &DeltaWorker{gs: preview, scenario: dw.scenario}
+			}
+		}
+	}
+
+	if d.UserLocation != "" {
+		if _, ok := ref.findLocationKey(d.UserLocation); !ok {
+			report("user_location", d.UserLocation, "unknown location", IssueDropped)
+			d.UserLocation = ""
+		}
+	}
+
+	itemEvents := d.ItemEvents[:0:0]
+	for i, e := range d.ItemEvents {
+		path := fmt.Sprintf("item_events[%d]", i)
+
+		e.Item = strings.TrimSpace(e.Item)
+		if e.Item == "" {
+			report(path+".item", "", "item is required", IssueDropped)
+			continue
+		}
+
+		action := strings.ToLower(strings.TrimSpace(e.Action))
+		if !slices.Contains(conditionals.ItemActions, action) {
+			report(path+".action", e.Action, "action must be one of "+strings.Join(conditionals.ItemActions, ", "), IssueDropped)
+			continue
+		}
+		if action != e.Action {
+			report(path+".action", e.Action, "action must be lowercase", IssueRepaired)
+			e.Action = action
+		}
+
+		// Transfers take the item from wherever it is, so a bad source is only noise
+		if e.From != nil {
+			if reason := ref.holderProblem(e.From); reason != "" {
+				report(path+".from", e.From.Type+":"+e.From.Name, reason, IssueRepaired)
+				e.From = nil
+			}
+		}
+
+		if e.To != nil {
+			if reason := ref.holderProblem(e.To); reason != "" {
+				if action != conditionals.ItemActionDrop {
+					report(path+".to", e.To.Type+":"+e.To.Name, reason, IssueDropped)
+					continue
+				}
+				// A drop without a destination lands at the player's location
+				report(path+".to", e.To.Type+":"+e.To.Name, reason, IssueRepaired)
+				e.To = nil
+			}
+		}
+		if e.To == nil && (action == conditionals.ItemActionGive || action == conditionals.ItemActionMove) {
+			report(path+".to", "", "destination is required for "+action, IssueDropped)
+			continue
+		}
+
+		itemEvents = append(itemEvents, e)
+	}
+	if d.ItemEvents != nil {
+		d.ItemEvents = itemEvents
+	}
+
+	npcEvents := d.NPCEvents[:0:0]
+	for i, e := range d.NPCEvents {
+		path := fmt.Sprintf("npc_events[%d]", i)
+
+		if _, ok := ref.findNPCKey(e.NPCID); !ok {
+			report(path+".npc_id", e.NPCID, "unknown NPC", IssueDropped)
+			continue
+		}
+		if e.SetLocation != nil {
+			if _, ok := ref.findLocationKey(*e.SetLocation); !ok {
+				report(path+".set_location", *e.SetLocation, "unknown location", IssueRepaired)
+				e.SetLocation = nil
+			}
+		}
+		if e.SetFollowing != nil {
+			following := strings.TrimSpace(*e.SetFollowing)
+			if following != "" && following != "pc" {
+				if _, ok := ref.findNPCKey(following); !ok {
+					report(path+".set_following", *e.SetFollowing, `following must be "pc", an NPC, or empty`, IssueRepaired)
+					e.SetFollowing = nil
+				}
+			}
+		}
+		npcEvents = append(npcEvents, e)
+	}
+	if d.NPCEvents != nil {
+		d.NPCEvents = npcEvents
+	}
+
+	monsterEvents := d.MonsterEvents[:0:0]
+	for i, e := range d.MonsterEvents {
+		path := fmt.Sprintf("monster_events[%d]", i)
+
+		if strings.TrimSpace(e.InstanceID) == "" {
+			report(path+".instance_id", "", "instance_id is required", IssueDropped)
+			continue
+		}
+		switch e.Action {
+		case conditionals.MonsterEventSpawn:
+			if strings.TrimSpace(e.Template) == "" {
+				report(path+".template", "", "template is required for spawn", IssueDropped)
+				continue
+			}
+			if _, ok := ref.findLocationKey(e.Location); !ok {
+				report(path+".location", e.Location, "unknown location", IssueDropped)
+				continue
+			}
+		case conditionals.MonsterEventDespawn:
+			if !ref.hasMonster(e.InstanceID) {
+				report(path+".instance_id", e.InstanceID, "unknown monster instance", IssueDropped)
+				continue
+			}
+		default:
+			report(path+".action", string(e.Action), "action must be spawn or despawn", IssueDropped)
+			continue
+		}
+		monsterEvents = append(monsterEvents, e)
+	}
+	if d.MonsterEvents != nil {
+		d.MonsterEvents = monsterEvents
+	}
+
+	if c := d.Check; c != nil {
+		attribute := actor.NormalizeAttribute(c.Attribute)
+		if _, ok := ref.gs.PC.CheckModifier(attribute); !ok {
+			report("check.attribute", c.Attribute, "unknown ability or skill", IssueDropped)
+			d.Check = nil
+		} else {
+			if c.DC < conditionals.MinDC || c.DC > conditionals.MaxDC {
+				report("check.dc", fmt.Sprint(c.DC), fmt.Sprintf("dc must be between %d and %d", conditionals.MinDC, conditionals.MaxDC), IssueRepaired)
+				c.DC = min(max(c.DC, conditionals.MinDC), conditionals.MaxDC)
+			}
+			if c.Var != "" {
+				report("check.var", c.Var, "only allowed in scenario conditionals", IssueRepaired)
+				c.Var = ""
+			}
+			c.Attribute = attribute
+		}
+	}
+
+	if a := d.Attack; a != nil {
+		c := dw.gs.Combat
+		var foe *combat.Combatant
+		if c != nil {
+			foe = c.Find(a.Target)
+		}
+		switch {
+		case c == nil || c.Over():
+			report("attack", a.Target, "no fight in progress", IssueDropped)
+			d.Attack = nil
+		case foe == nil || foe.ID == combat.PCID:
+			report("attack.target", a.Target, "unknown foe", IssueDropped)
+			d.Attack = nil
+		case foe.Defeated():
+			report("attack.target", a.Target, "foe already defeated", IssueDropped)
+			d.Attack = nil
+		default:
+			a.Target = foe.ID
+		}
+	}
+
+	// Removals are for scenario conditionals; the reducer's schema doesn't offer them
+	if len(d.RemoveVars) > 0 {
+		report("remove_vars", strings.Join(d.RemoveVars, ", "), "only allowed in scenario conditionals", IssueDropped)
+		d.RemoveVars = nil
+	}
+	if len(d.ClearInventory) > 0 {
+		report("clear_inventory", "", "only allowed in scenario conditionals", IssueDropped)
+		d.ClearInventory = nil
+	}
+	if len(d.RemoveNPCs) > 0 {
+		report("remove_npcs", strings.Join(d.RemoveNPCs, ", "), "only allowed in scenario conditionals", IssueDropped)
+		d.RemoveNPCs = nil
+	}
+	if len(d.UnlockLore) > 0 {
+		report("unlock_lore", strings.Join(d.UnlockLore, ", "), "only allowed in scenario conditionals", IssueDropped)
+		d.UnlockLore = nil
+	}
+	if d.StartCombat != nil {
+		report("start_combat", strings.Join(d.StartCombat.Foes, ", "), "only allowed in scenario conditionals", IssueDropped)
+		d.StartCombat = nil
+	}
+	if d.EndCombat {
+		report("end_combat", "", "only allowed in scenario conditionals", IssueDropped)
+		d.EndCombat = false
+	}
+
+	// The reducer may only update variables that already exist
+	for _, k := range slices.Sorted(maps.Keys(d.SetVars)) {
+		if _, ok := ref.gs.Vars[toSnakeCase(strings.ToLower(k))]; !ok {
+			report("set_vars."+k, d.SetVars[k], "unknown variable", IssueDropped)
+			delete(d.SetVars, k)
+		}
+	}
+
+	return issues
+}
+
+// holderProblem returns why an item event's from/to can't be resolved, or "" if it can.
+// The type is lowercased in place.
+func (dw *DeltaWorker) holderProblem(h *itemHolder) string {
+	h.Type = strings.ToLower(strings.TrimSpace(h.Type))
+	switch h.Type {
+	case conditionals.HolderPlayer:
+		return ""
+	case conditionals.HolderNPC:
+		if _, ok := dw.findNPCKey(h.Name); !ok {
+			return "unknown NPC"
+		}
+	case conditionals.HolderLocation:
+		if _, ok := dw.findLocationKey(h.Name); !ok {
+			return "unknown location"
+		}
+	default:
+		return "type must be one of " + strings.Join(conditionals.HolderTypes, ", ")
+	}
+	return ""
+}
+
+// hasMonster reports whether a monster instance is active at any location
+func (dw *DeltaWorker) hasMonster(instanceID string) bool {
+	for _, loc := range dw.gs.WorldLocations {
+		if _, ok := loc.Monsters[instanceID]; ok {
+			return true
+		}
+	}
+	return false
+}
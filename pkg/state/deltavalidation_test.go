@@ -0,0 +1,155 @@
+package state
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestDeltaWorker_Validate(t *testing.T) {
+	tests := []struct {
+		name           string
+		delta          string
+		expectedIssues []DeltaIssue
+		expectedDelta  string
+	}{
+		{
+			name:          "valid delta unchanged",
+			delta:         `{"user_location": "Cellar", "item_events": [{"item": "key", "action": "acquire", "from": {"type": "location", "name": "cellar"}}], "npc_events": [{"npc_id": "smith", "set_following": "pc"}], "set_vars": {"door_open": "true"}}`,
+			expectedDelta: `{"user_location": "Cellar", "item_events": [{"item": "key", "action": "acquire", "from": {"type": "location", "name": "cellar"}}], "npc_events": [{"npc_id": "smith", "set_following": "pc"}], "set_vars": {"door_open": "true"}}`,
+		},
+		{
+			name:  "unknown location and scene dropped",
+			delta: `{"user_location": "moon", "scene_change": {"to": "finale", "reason": "why not"}}`,
+			expectedIssues: []DeltaIssue{
+				{Path: "scene_change.to", Value: "finale", Reason: "unknown scene", Fix: IssueDropped},
+				{Path: "user_location", Value: "moon", Reason: "unknown location", Fix: IssueDropped},
+			},
+			expectedDelta: `{"user_location": ""}`,
+		},
+		{
+			name:  "item events repaired or dropped",
+			delta: `{"user_location": "hall", "item_events": [{"item": "", "action": "acquire"}, {"item": "coin", "action": "steal"}, {"item": "coin", "action": "Give", "to": {"type": "npc", "name": "Stranger"}}, {"item": "lamp", "action": "drop", "to": {"type": "shelf"}}, {"item": "key", "action": "acquire", "from": {"type": "npc", "name": "Smith"}}, {"item": "coin", "action": "move"}]}`,
+			expectedIssues: []DeltaIssue{
+				{Path: "item_events[0].item", Reason: "item is required", Fix: IssueDropped},
+				{Path: "item_events[1].action", Value: "steal", Reason: "action must be one of acquire, give, drop, move, use", Fix: IssueDropped},
+				{Path: "item_events[2].action", Value: "Give", Reason: "action must be lowercase", Fix: IssueRepaired},
+				{Path: "item_events[2].to", Value: "npc:Stranger", Reason: "unknown NPC", Fix: IssueDropped},
+				{Path: "item_events[3].to", Value: "shelf:", Reason: "type must be one of player, npc, location", Fix: IssueRepaired},
+				{Path: "item_events[5].to", Reason: "destination is required for move", Fix: IssueDropped},
+			},
+			expectedDelta: `{"user_location": "hall", "item_events": [{"item": "lamp", "action": "drop"}, {"item": "key", "action": "acquire", "from": {"type": "npc", "name": "Smith"}}]}`,
+		},
+		{
+			name:  "NPC and monster references checked",
+			delta: `{"user_location": "hall", "npc_events": [{"npc_id": "ghost", "set_location": "hall"}, {"npc_id": "Smith", "set_location": "forge", "set_following": "ghost"}], "monster_events": [{"action": "despawn", "instance_id": "rat_1"}, {"action": "despawn", "instance_id": "rat_2"}, {"action": "spawn", "instance_id": "bat_1", "location": "cellar"}, {"action": "summon", "instance_id": "imp_1"}]}`,
+			expectedIssues: []DeltaIssue{
+				{Path: "npc_events[0].npc_id", Value: "ghost", Reason: "unknown NPC", Fix: IssueDropped},
+				{Path: "npc_events[1].set_location", Value: "forge", Reason: "unknown location", Fix: IssueRepaired},
+				{Path: "npc_events[1].set_following", Value: "ghost", Reason: `following must be "pc", an NPC, or empty`, Fix: IssueRepaired},
+				{Path: "monster_events[1].instance_id", Value: "rat_2", Reason: "unknown monster instance", Fix: IssueDropped},
+				{Path: "monster_events[2].template", Reason: "template is required for spawn", Fix: IssueDropped},
+				{Path: "monster_events[3].action", Value: "summon", Reason: "action must be spawn or despawn", Fix: IssueDropped},
+			},
+			expectedDelta: `{"user_location": "hall", "npc_events": [{"npc_id": "Smith"}], "monster_events": [{"action": "despawn", "instance_id": "rat_1"}]}`,
+		},
+		{
+			name:  "unknown vars dropped",
+			delta: `{"user_location": "hall", "set_vars": {"door_open": "true", "dragon_slain": "true", "Bell Rung": "true"}}`,
+			expectedIssues: []DeltaIssue{
+				{Path: "set_vars.dragon_slain", Value: "true", Reason: "unknown variable", Fix: IssueDropped},
+			},
+			expectedDelta: `{"user_location": "hall", "set_vars": {"door_open": "true", "Bell Rung": "true"}}`,
+		},
+		{
+			name:  "conditional-only removals dropped",
+			delta: `{"user_location": "hall", "remove_vars": ["door_open"], "clear_inventory": [{}], "remove_npcs": ["smith"]}`,
+			expectedIssues: []DeltaIssue{
+				{Path: "remove_vars", Value: "door_open", Reason: "only allowed in scenario conditionals", Fix: IssueDropped},
+				{Path: "clear_inventory", Reason: "only allowed in scenario conditionals", Fix: IssueDropped},
+				{Path: "remove_npcs", Value: "smith", Reason: "only allowed in scenario conditionals", Fix: IssueDropped},
+			},
+			expectedDelta: `{"user_location": "hall"}`,
+		},
+		{
+			name:  "conditional-only lore unlock dropped",
+			delta: `{"user_location": "hall", "unlock_lore": ["old_war"]}`,
+			expectedIssues: []DeltaIssue{
+				{Path: "unlock_lore", Value: "old_war", Reason: "only allowed in scenario conditionals", Fix: IssueDropped},
+			},
+			expectedDelta: `{"user_location": "hall"}`,
+		},
+		{
+			name:  "skill checks repaired or dropped",
+			delta: `{"user_location": "hall", "check": {"attribute": "Sleight of Hand", "dc": 40, "reason": "lift the key", "var": "door_open"}}`,
+			expectedIssues: []DeltaIssue{
+				{Path: "check.dc", Value: "40", Reason: "dc must be between 1 and 30", Fix: IssueRepaired},
+				{Path: "check.var", Value: "door_open", Reason: "only allowed in scenario conditionals", Fix: IssueRepaired},
+			},
+			expectedDelta: `{"user_location": "hall", "check": {"attribute": "sleight_of_hand", "dc": 30, "reason": "lift the key"}}`,
+		},
+		{
+			name:  "unknown check attribute dropped",
+			delta: `{"user_location": "hall", "check": {"attribute": "lockpicking", "dc": 10}}`,
+			expectedIssues: []DeltaIssue{
+				{Path: "check.attribute", Value: "lockpicking", Reason: "unknown ability or skill", Fix: IssueDropped},
+			},
+			expectedDelta: `{"user_location": "hall"}`,
+		},
+		{
+			name:          "scene change brings in its locations and vars",
+			delta:         `{"user_location": "crypt", "scene_change": {"to": "descent", "reason": "stairs"}, "set_vars": {"torch_lit": "true"}}`,
+			expectedDelta: `{"user_location": "crypt", "scene_change": {"to": "descent", "reason": "stairs"}, "set_vars": {"torch_lit": "true"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &GameState{
+				Location: "hall",
+				Vars:     map[string]string{"door_open": "false", "bell_rung": "false"},
+				NPCs: map[string]actor.NPC{
+					"smith": {Name: "Smith", Location: "hall"},
+				},
+				WorldLocations: map[string]scenario.Location{
+					"hall":   {Name: "Great Hall", Monsters: map[string]*actor.Monster{"rat_1": {ID: "rat_1"}}},
+					"cellar": {Name: "Cellar", Items: []string{"key"}},
+				},
+			}
+			s := &scenario.Scenario{
+				Scenes: map[string]scenario.Scene{
+					"descent": {
+						Locations: map[string]scenario.Location{"crypt": {Name: "Crypt"}},
+						Vars:      map[string]string{"torch_lit": "false"},
+					},
+				},
+			}
+
+			var delta, expected conditionals.GameStateDelta
+			if err := json.Unmarshal([]byte(tt.delta), &delta); err != nil {
+				t.Fatalf("Failed to parse delta: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.expectedDelta), &expected); err != nil {
+				t.Fatalf("Failed to parse expected delta: %v", err)
+			}
+
+			worker := NewDeltaWorker(gs, &delta, s, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			issues := worker.Validate()
+
+			if !reflect.DeepEqual(issues, tt.expectedIssues) {
+				t.Errorf("Expected issues:\n%+v\ngot:\n%+v", tt.expectedIssues, issues)
+			}
+			got, _ := json.Marshal(delta)
+			want, _ := json.Marshal(expected)
+			if string(got) != string(want) {
+				t.Errorf("Expected delta %s, got %s", want, got)
+			}
+		})
+	}
+}
@@ -21,19 +21,19 @@ type MonsterStorage interface {
 	GetMonster(ctx context.Context, templateID string) (*actor.Monster, error)
 }
 
+// itemHolder is a type alias for an item event's from/to struct
+type itemHolder = struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
 // itemEvent is a type alias for the ItemEvents struct to avoid repetition
 type itemEvent = struct {
-	Item   string `json:"item"`
-	Action string `json:"action"`
-	From   *struct {
-		Type string `json:"type"`
-		Name string `json:"name,omitempty"`
-	} `json:"from,omitempty"`
-	To *struct {
-		Type string `json:"type"`
-		Name string `json:"name,omitempty"`
-	} `json:"to,omitempty"`
-	Consumed *bool `json:"consumed,omitempty"`
+	Item     string      `json:"item"`
+	Action   string      `json:"action"`
+	From     *itemHolder `json:"from,omitempty"`
+	To       *itemHolder `json:"to,omitempty"`
+	Consumed *bool       `json:"consumed,omitempty"`
 }
 
 // DeltaWorker encapsulates the logic for applying deltas to game state,
@@ -46,6 +46,12 @@ type DeltaWorker struct {
 	queue    ChatQueue
 	storage  MonsterStorage
 	ctx      context.Context
+	rolled   *conditionals.SkillCheck   // check already rolled; conditionals apply the delta again
+	started  *conditionals.CombatStart  // fight already started
+	attacked *conditionals.CombatAttack // combat round already played
+	ended    bool                       // fight already ended
+	key      string                     // idempotency key; see DeltaKey
+	claimed  bool                       // key already recorded; conditionals apply again
 }
 
 // NewDeltaWorker creates a new delta worker for applying state changes
@@ -80,6 +86,20 @@ func (dw *DeltaWorker) WithContext(ctx context.Context) *DeltaWorker {
 	return dw
 }
 
+// WithIdempotencyKey sets the key that marks the delta as applied, so it's applied to
+// the game only once however often its turn is retried; see DeltaKey
+// Returns the DeltaWorker for method chaining
+func (dw *DeltaWorker) WithIdempotencyKey(key string) *DeltaWorker {
+	dw.key = key
+	return dw
+}
+
+// AlreadyApplied reports whether the game has already applied the delta with the
+// worker's idempotency key. Check it before changing anything else for the turn.
+func (dw *DeltaWorker) AlreadyApplied() bool {
+	return !dw.claimed && dw.gs != nil && dw.gs.HasAppliedDelta(dw.key)
+}
+
 // ApplyVars applies variable updates from the delta to the game state with snake_case conversion
 func (dw *DeltaWorker) ApplyVars() {
 	if dw.delta == nil {
@@ -93,11 +113,17 @@ func (dw *DeltaWorker) ApplyVars() {
 		}
 		dw.gs.Vars[snake] = v
 	}
+
+	// Removals win over sets in the same delta
+	for _, k := range dw.delta.RemoveVars {
+		delete(dw.gs.Vars, toSnakeCase(strings.ToLower(k)))
+	}
 }
 
 // MergeConditionals evaluates conditionals once and merges triggered conditionals into the delta
 // Also handles prompt-based actions (story events, etc.) by queuing them
-// Returns a map of triggered conditional IDs to their conditionals for logging purposes
+// Conditionals whose fire policy rules them out this turn are skipped, and those that fire are recorded
+// in FiredConditionals. Returns a map of triggered conditional IDs to their conditionals for logging purposes
 // Note: This only evaluates conditionals ONCE. For cascading conditionals, the caller should
 // call this method repeatedly after applying the delta to game state.
 func (dw *DeltaWorker) MergeConditionals() map[string]scenario.Conditional {
@@ -106,20 +132,154 @@ func (dw *DeltaWorker) MergeConditionals() map[string]scenario.Conditional {
 	}
 
 	triggeredConditionals := dw.scenario.EvaluateConditionals(dw.gs)
+	maps.DeleteFunc(triggeredConditionals, func(conditionalID string, conditional scenario.Conditional) bool {
+		return !dw.canFire(conditionalID, conditional)
+	})
 	if len(triggeredConditionals) == 0 {
 		return nil
 	}
 
+	selected, suppressed := scenario.SelectConditionals(triggeredConditionals)
+	for _, conditionalID := range slices.Sorted(maps.Keys(suppressed)) {
+		if dw.logger != nil {
+			dw.logger.Info("Conditional suppressed by exclusivity group",
+				"game_state_id", dw.gs.ID.String(),
+				"conditional_id", conditionalID,
+				"group", triggeredConditionals[conditionalID].Group,
+				"winner", suppressed[conditionalID])
+		}
+	}
+
+	// Merge highest priority first; a lower-priority conditional can't override what a higher one set
 	triggered := make(map[string]scenario.Conditional)
-	for conditionalID, conditional := range triggeredConditionals {
+	claims := make(map[string]fieldClaim)
+	for _, conditionalID := range selected {
+		conditional := triggeredConditionals[conditionalID]
 		triggered[conditionalID] = conditional
+		dw.recordFiring(conditionalID)
+		then := dw.resolveConflicts(conditional.Then, conditionalID, triggeredConditionals, claims)
 		// Merge into the existing delta
-		dw.mergeDelta(&conditional.Then, conditionalID)
+		dw.mergeDelta(&then, conditionalID)
 	}
 
 	return triggered
 }
 
+// canFire reports whether a conditional's fire policy allows it to fire this turn
+func (dw *DeltaWorker) canFire(conditionalID string, conditional scenario.Conditional) bool {
+	last, fired := dw.gs.FiredConditionals[conditionalID]
+	if !fired {
+		return true
+	}
+	if last.Turn == dw.gs.TurnCounter {
+		return false
+	}
+
+	switch conditional.Fire {
+	case scenario.FireOnce:
+		return false
+	case scenario.FireOncePerScene:
+		// The scene was entered SceneTurnCounter turns ago; fire again only on a new visit
+		sceneEntered := dw.gs.TurnCounter - dw.gs.SceneTurnCounter
+		return last.Scene != dw.gs.SceneName || last.Turn < sceneEntered
+	default:
+		return dw.gs.TurnCounter-last.Turn > conditional.Cooldown
+	}
+}
+
+// recordFiring marks a conditional as fired this turn
+func (dw *DeltaWorker) recordFiring(conditionalID string) {
+	if dw.gs.FiredConditionals == nil {
+		dw.gs.FiredConditionals = make(map[string]ConditionalFiring)
+	}
+	last := dw.gs.FiredConditionals[conditionalID]
+	dw.gs.FiredConditionals[conditionalID] = ConditionalFiring{
+		Turn:  dw.gs.TurnCounter,
+		Scene: dw.gs.SceneName,
+		Count: last.Count + 1,
+	}
+}
+
+// fieldClaim records which conditional set a delta field during one MergeConditionals pass
+type fieldClaim struct {
+	conditionalID string
+	value         string
+}
+
+// resolveConflicts returns a copy of a conditional's delta without the fields a
+// higher-priority conditional has already claimed with a different value, and
+// claims the fields it keeps. Each dropped field is logged with the winner.
+func (dw *DeltaWorker) resolveConflicts(then conditionals.GameStateDelta, conditionalID string, triggered map[string]scenario.Conditional, claims map[string]fieldClaim) conditionals.GameStateDelta {
+	// keep reports whether this conditional may set a field, claiming it if so
+	keep := func(field, value string) bool {
+		claim, claimed := claims[field]
+		if !claimed {
+			claims[field] = fieldClaim{conditionalID: conditionalID, value: value}
+			return true
+		}
+		if claim.value == value {
+			return true
+		}
+		if dw.logger != nil {
+			reason := "higher priority"
+			if triggered[claim.conditionalID].Priority == triggered[conditionalID].Priority {
+				reason = "same priority, earlier conditional ID"
+			}
+			dw.logger.Info("Conditional conflict resolved",
+				"game_state_id", dw.gs.ID.String(),
+				"field", field,
+				"winner", claim.conditionalID,
+				"winner_value", claim.value,
+				"loser", conditionalID,
+				"loser_value", value,
+				"reason", reason)
+		}
+		return false
+	}
+
+	if then.SceneChange != nil && then.SceneChange.To != "" && !keep("scene_change", then.SceneChange.To) {
+		then.SceneChange = nil
+	}
+	if then.GameEnded != nil && !keep("game_ended", fmt.Sprint(*then.GameEnded)) {
+		then.GameEnded = nil
+	}
+	if then.UserLocation != "" && !keep("user_location", then.UserLocation) {
+		then.UserLocation = ""
+	}
+	if then.Check != nil && !keep("check", fmt.Sprintf("%s:%d", then.Check.Attribute, then.Check.DC)) {
+		then.Check = nil
+	}
+	if then.StartCombat != nil && !keep("combat", "start:"+strings.Join(then.StartCombat.Foes, ",")) {
+		then.StartCombat = nil
+	}
+	if then.EndCombat && !keep("combat", "end") {
+		then.EndCombat = false
+	}
+	if len(then.SetVars) > 0 {
+		setVars := make(map[string]string, len(then.SetVars))
+		for _, k := range slices.Sorted(maps.Keys(then.SetVars)) {
+			if keep("set_vars."+toSnakeCase(strings.ToLower(k)), then.SetVars[k]) {
+				setVars[k] = then.SetVars[k]
+			}
+		}
+		then.SetVars = setVars
+	}
+	if len(then.NPCEvents) > 0 {
+		npcEvents := make([]conditionals.NPCEvent, 0, len(then.NPCEvents))
+		for _, e := range then.NPCEvents {
+			if e.SetLocation != nil && !keep("npc_events."+e.NPCID+".set_location", *e.SetLocation) {
+				e.SetLocation = nil
+			}
+			if e.SetFollowing != nil && !keep("npc_events."+e.NPCID+".set_following", *e.SetFollowing) {
+				e.SetFollowing = nil
+			}
+			npcEvents = append(npcEvents, e)
+		}
+		then.NPCEvents = npcEvents
+	}
+	return then
+}
+
 // mergeDelta merges a conditional's delta into the worker's delta, with special handling for prompts
 func (dw *DeltaWorker) mergeDelta(conditionalDelta *conditionals.GameStateDelta, conditionalID string) {
 	if conditionalDelta == nil {
@@ -142,6 +302,19 @@ func (dw *DeltaWorker) mergeDelta(conditionalDelta *conditionals.GameStateDelta,
 		dw.delta.GameEnded = conditionalDelta.GameEnded
 	}
 
+	// Merge skill check, overriding the model's
+	if conditionalDelta.Check != nil {
+		dw.delta.Check = conditionalDelta.Check
+	}
+
+	// Merge combat, a start overriding any previous one
+	if conditionalDelta.StartCombat != nil {
+		dw.delta.StartCombat = conditionalDelta.StartCombat
+	}
+	if conditionalDelta.EndCombat {
+		dw.delta.EndCombat = true
+	}
+
 	// Merge user location, overriding any previous value
 	if conditionalDelta.UserLocation != "" {
 		dw.delta.UserLocation = conditionalDelta.UserLocation
@@ -170,6 +343,20 @@ func (dw *DeltaWorker) mergeDelta(conditionalDelta *conditionals.GameStateDelta,
 		dw.delta.MonsterEvents = append(dw.delta.MonsterEvents, conditionalDelta.MonsterEvents...)
 	}
 
+	// Merge removals
+	if len(conditionalDelta.RemoveVars) > 0 {
+		dw.delta.RemoveVars = append(dw.delta.RemoveVars, conditionalDelta.RemoveVars...)
+	}
+	if len(conditionalDelta.ClearInventory) > 0 {
+		dw.delta.ClearInventory = append(dw.delta.ClearInventory, conditionalDelta.ClearInventory...)
+	}
+	if len(conditionalDelta.RemoveNPCs) > 0 {
+		dw.delta.RemoveNPCs = append(dw.delta.RemoveNPCs, conditionalDelta.RemoveNPCs...)
+	}
+	if len(conditionalDelta.UnlockLore) > 0 {
+		dw.delta.UnlockLore = append(dw.delta.UnlockLore, conditionalDelta.UnlockLore...)
+	}
+
 	// Handle prompt - any prompt in a conditional is treated as a story event
 	if conditionalDelta.Prompt != nil {
 		prompt := *conditionalDelta.Prompt
@@ -214,6 +401,7 @@ func (dw *DeltaWorker) queueStoryEvent(conditionalID string, eventText string) {
 		RequestID:   uuid.New().String(),
 		Type:        queue.RequestTypeStoryEvent,
 		GameStateID: dw.gs.ID,
+		Profile:     dw.gs.Profile,
 		EventPrompt: eventText,
 		EnqueuedAt:  time.Now(),
 	}
@@ -243,8 +431,35 @@ func (dw *DeltaWorker) queueStoryEvent(conditionalID string, eventText string) {
 	}
 }
 
-// Apply applies the delta to the game state (scene changes, items, location, game end)
+// sceneOpeningEventPrefix prefixes a scene name to form the story event ID of its opening prompt
+const sceneOpeningEventPrefix = "scene_opening:"
+
+// queueSceneOpening queues a newly entered scene's opening prompt as a story event,
+// once per game, so each act starts with a narrated beat
+func (dw *DeltaWorker) queueSceneOpening(sceneName string) {
+	prompt := strings.TrimSpace(dw.scenario.Scenes[sceneName].OpeningPrompt)
+	if prompt == "" {
+		return
+	}
+	eventID := sceneOpeningEventPrefix + sceneName
+	if dw.hasStoryEventFired(eventID) {
+		return
+	}
+	dw.queueStoryEvent(eventID, dw.gs.Interpolate(prompt))
+}
+
+// Apply applies the delta to the game state (scene changes, items, location, game end).
+// With an idempotency key, the first call records it, and returns ErrDeltaAlreadyApplied
+// without changing anything if the game already has it.
 func (dw *DeltaWorker) Apply() error {
+	if dw.key != "" && !dw.claimed && dw.gs != nil {
+		if dw.gs.HasAppliedDelta(dw.key) {
+			return ErrDeltaAlreadyApplied
+		}
+		dw.gs.recordAppliedDelta(dw.key)
+		dw.claimed = true
+	}
+
 	if dw.delta == nil {
 		// No delta this turn - location cannot have changed.
 		if dw.gs != nil {
@@ -263,63 +478,45 @@ func (dw *DeltaWorker) Apply() error {
 		// TODO: Add scene key/name disambiguation similar to locations
 		// Scenes should have snake_case keys (e.g., "shipwright") and display names (e.g., "The Shipwright")
 		// Use GetScene(keyOrName) helper to resolve both formats
-		dw.delta.SceneChange.To != dw.gs.SceneName && dw.scenario.HasScene(dw.delta.SceneChange.To) {
+		dw.delta.SceneChange.To != dw.gs.SceneName && dw.scenario != nil && dw.scenario.HasScene(dw.delta.SceneChange.To) {
 		err := dw.gs.LoadScene(dw.scenario, dw.delta.SceneChange.To)
 		if err != nil {
 			return fmt.Errorf("failed to load scene: %w", err)
 		}
 		dw.gs.SceneName = dw.delta.SceneChange.To
+		dw.queueSceneOpening(dw.delta.SceneChange.To)
 	}
 
 	// Handle location change
 	if dw.delta.UserLocation != "" {
-		locationKey := strings.ToLower(strings.TrimSpace(dw.delta.UserLocation))
-
-		// Check if location exists in current game world
-		if _, found := dw.gs.WorldLocations[locationKey]; found {
+		if locationKey, found := dw.findLocationKey(dw.delta.UserLocation); found {
 			// Update to the location key (ID), not the display name
-			if dw.gs.Location != locationKey {
-				if dw.logger != nil {
-					dw.logger.Info("Location changed",
-						"from", dw.gs.Location,
-						"to", locationKey,
-						"input", dw.delta.UserLocation)
-				}
+			if dw.gs.Location != locationKey && dw.logger != nil {
+				dw.logger.Info("Location changed",
+					"from", dw.gs.Location,
+					"to", locationKey,
+					"input", dw.delta.UserLocation)
 			}
 			dw.gs.Location = locationKey
 		} else {
-			// Try matching by location name
-			found := false
-			for key, loc := range dw.gs.WorldLocations {
-				if strings.ToLower(loc.Name) == locationKey {
-					if dw.gs.Location != key {
-						if dw.logger != nil {
-							dw.logger.Info("Location changed",
-								"from", dw.gs.Location,
-								"to", key,
-								"input", dw.delta.UserLocation)
-						}
-					}
-					dw.gs.Location = key
-					found = true
-					break
-				}
-			}
-
-			if !found {
-				dw.logger.Warn("Could not find location",
-					"input", dw.delta.UserLocation,
-					"current", dw.gs.Location)
-			}
+			dw.logger.Warn("Could not find location",
+				"input", dw.delta.UserLocation,
+				"current", dw.gs.Location)
 		}
 	}
 
-	// Handle item events
-	// TODO: Add item key/name disambiguation for all item operations
-	// Items should have snake_case keys (e.g., "skeleton_key") and display names (e.g., "Skeleton Key")
-	// Affects: AcquireItem, DropItem, GiveItem, MoveItem, UseItem
-	// Consider adding GetItem(keyOrName) helper to resolve both formats
+	// Clear inventory before item events, so a conditional can swap the player's kit
+	for _, filter := range dw.delta.ClearInventory {
+		dw.clearInventory(filter)
+	}
+
+	// Handle item events. The narrator names items by display name; the game holds
+	// catalogued items by ID.
 	for _, itemEvent := range dw.delta.ItemEvents {
+		if strings.TrimSpace(itemEvent.Item) == "" {
+			continue
+		}
+		itemEvent.Item = dw.gs.ItemID(itemEvent.Item)
 		switch itemEvent.Action {
 		case "acquire":
 			dw.handleAcquireItem(itemEvent)
@@ -339,11 +536,40 @@ func (dw *DeltaWorker) Apply() error {
 		dw.handleNPCEvent(npcEvent)
 	}
 
+	// Remove NPCs after their events, so an event can't bring one back
+	for _, npcID := range dw.delta.RemoveNPCs {
+		dw.removeNPC(npcID)
+	}
+
+	// Unlock lore granted by conditionals
+	dw.gs.DiscoverLore(dw.delta.UnlockLore...)
+
 	// Handle Monster events
 	for _, monsterEvent := range dw.delta.MonsterEvents {
 		dw.handleMonsterEvent(monsterEvent)
 	}
 
+	// Fights end and start before a round is played, each once, though the delta is
+	// applied again for conditionals
+	if dw.delta.EndCombat && !dw.ended {
+		dw.ended = true
+		dw.endCombat()
+	}
+	if dw.delta.StartCombat != nil && dw.delta.StartCombat != dw.started {
+		dw.started = dw.delta.StartCombat
+		dw.startCombat(*dw.delta.StartCombat)
+	}
+	if dw.delta.Attack != nil && dw.delta.Attack != dw.attacked {
+		dw.attacked = dw.delta.Attack
+		dw.playCombatRound(dw.delta.Attack.Target)
+	}
+
+	// Roll a requested skill check once, though the delta is applied again for conditionals
+	if dw.delta.Check != nil && dw.delta.Check != dw.rolled {
+		dw.rolled = dw.delta.Check
+		dw.rollCheck(*dw.delta.Check)
+	}
+
 	// TODO: Evaluate monster defeats (auto-despawn defeated monsters)
 	// This runs after all delta operations to catch any HP changes
 	// dw.gs.EvaluateDefeats()
@@ -368,6 +594,95 @@ func (dw *DeltaWorker) Apply() error {
 	return nil
 }
 
+// startCombat starts a fight with foes named by NPC or monster instance ID
+func (dw *DeltaWorker) startCombat(start conditionals.CombatStart) {
+	foes := make([]string, 0, len(start.Foes))
+	for _, foe := range start.Foes {
+		if npcKey, ok := dw.findNPCKey(foe); ok {
+			foe = npcKey
+		}
+		foes = append(foes, foe)
+	}
+	if err := dw.gs.StartCombat(start.Reason, foes); err != nil {
+		if dw.logger != nil {
+			dw.logger.Warn("Failed to start combat",
+				"error", err,
+				"game_state_id", dw.gs.ID.String(),
+				"foes", foes)
+		}
+		return
+	}
+	if dw.logger != nil {
+		dw.logger.Info("Combat started",
+			"game_state_id", dw.gs.ID.String(),
+			"foes", foes,
+			"reason", start.Reason)
+	}
+}
+
+// endCombat ends the fight in progress, if any
+func (dw *DeltaWorker) endCombat() {
+	if dw.gs.Combat == nil {
+		return
+	}
+	dw.gs.EndCombat()
+	if dw.logger != nil {
+		dw.logger.Info("Combat ended", "game_state_id", dw.gs.ID.String())
+	}
+}
+
+// playCombatRound plays a round of the fight with the PC attacking target
+func (dw *DeltaWorker) playCombatRound(target string) {
+	if err := dw.gs.PlayCombatRound(target); err != nil {
+		if dw.logger != nil {
+			dw.logger.Warn("Failed to play combat round",
+				"error", err,
+				"game_state_id", dw.gs.ID.String(),
+				"target", target)
+		}
+		return
+	}
+	if dw.logger != nil {
+		dw.logger.Info("Combat round played",
+			"game_state_id", dw.gs.ID.String(),
+			"round", dw.gs.Combat.Round,
+			"attacks", len(dw.gs.Combat.LastRound),
+			"outcome", dw.gs.Combat.Outcome)
+	}
+}
+
+// rollCheck rolls a skill check for the next narration, and sets its var to the outcome
+func (dw *DeltaWorker) rollCheck(check conditionals.SkillCheck) {
+	result, err := dw.gs.RollCheck(check)
+	if err != nil {
+		if dw.logger != nil {
+			dw.logger.Warn("Failed to roll skill check",
+				"error", err,
+				"game_state_id", dw.gs.ID.String(),
+				"attribute", check.Attribute)
+		}
+		return
+	}
+	dw.gs.PendingCheck = result
+
+	if check.Var != "" {
+		if dw.gs.Vars == nil {
+			dw.gs.Vars = make(map[string]string)
+		}
+		dw.gs.Vars[toSnakeCase(strings.ToLower(check.Var))] = result.Outcome()
+	}
+	if dw.logger != nil {
+		dw.logger.Info("Skill check rolled",
+			"game_state_id", dw.gs.ID.String(),
+			"attribute", result.Attribute,
+			"dc", result.DC,
+			"roll", result.Roll,
+			"modifier", result.Modifier,
+			"total", result.Total,
+			"success", result.Success)
+	}
+}
+
 // handleAcquireItem adds an item to player inventory
 func (dw *DeltaWorker) handleAcquireItem(itemEvent itemEvent) {
 	itemExists := false
@@ -389,50 +704,38 @@ func (dw *DeltaWorker) handleAcquireItem(itemEvent itemEvent) {
 	}
 }
 
-// handleDropItem removes an item from player inventory
+// handleDropItem moves an item from player inventory to the destination,
+// or to the player's current location if none is given
 func (dw *DeltaWorker) handleDropItem(itemEvent itemEvent) {
-	for i, invItem := range dw.gs.Inventory {
-		if invItem == itemEvent.Item {
-			dw.gs.Inventory = append(dw.gs.Inventory[:i], dw.gs.Inventory[i+1:]...)
-			break
-		}
-	}
-	// Add to destination if specified
-	if itemEvent.To != nil {
-		dw.addItemToDestination(itemEvent.Item, itemEvent.To)
+	to := itemEvent.To
+	if to == nil {
+		to = &itemHolder{Type: "location", Name: dw.gs.Location}
 	}
+	dw.addItemToDestination(itemEvent.Item, to)
 }
 
 // handleGiveItem transfers an item between entities
 func (dw *DeltaWorker) handleGiveItem(itemEvent itemEvent) {
-	// Remove from source
-	if itemEvent.From != nil {
-		dw.removeItemFromSource(itemEvent.Item, itemEvent.From)
-	} else {
-		// Default to removing from player inventory if no source specified
-		for i, invItem := range dw.gs.Inventory {
-			if invItem == itemEvent.Item {
-				dw.gs.Inventory = append(dw.gs.Inventory[:i], dw.gs.Inventory[i+1:]...)
-				break
-			}
-		}
-	}
-	// Add to destination
-	if itemEvent.To != nil {
-		dw.addItemToDestination(itemEvent.Item, itemEvent.To)
-	}
+	dw.transferItem(itemEvent)
 }
 
 // handleMoveItem moves an item from one location/entity to another
 func (dw *DeltaWorker) handleMoveItem(itemEvent itemEvent) {
-	// Remove from source
-	if itemEvent.From != nil {
-		dw.removeItemFromSource(itemEvent.Item, itemEvent.From)
-	}
-	// Add to destination
-	if itemEvent.To != nil {
-		dw.addItemToDestination(itemEvent.Item, itemEvent.To)
+	dw.transferItem(itemEvent)
+}
+
+// transferItem moves an item to the event's destination. The item is taken from
+// wherever it currently is, so a wrong or missing source can't duplicate it.
+func (dw *DeltaWorker) transferItem(itemEvent itemEvent) {
+	if itemEvent.To == nil {
+		if dw.logger != nil {
+			dw.logger.Warn("Ignoring item event without a destination",
+				"item", itemEvent.Item,
+				"action", itemEvent.Action)
+		}
+		return
 	}
+	dw.addItemToDestination(itemEvent.Item, itemEvent.To)
 }
 
 // handleUseItem uses an item and potentially consumes it
@@ -453,22 +756,50 @@ func (dw *DeltaWorker) handleUseItem(itemEvent itemEvent) {
 	}
 }
 
-// handleNPCEvent processes an NPC state change event
-func (dw *DeltaWorker) handleNPCEvent(event conditionals.NPCEvent) {
-	npcKey := strings.ToLower(strings.TrimSpace(event.NPCID))
-	npc, npcExists := dw.gs.NPCs[npcKey]
-	if !npcExists {
-		// Try matching by NPC name
-		for key, n := range dw.gs.NPCs {
-			if strings.ToLower(n.Name) == npcKey {
-				npcKey = key
-				npc = n
-				npcExists = true
-				break
-			}
+// clearInventory removes the items matching the filter from player inventory
+func (dw *DeltaWorker) clearInventory(filter conditionals.InventoryFilter) {
+	// Matched by display name, so contains reads the way the player sees the items
+	filter.Items = dw.gs.DisplayItems(dw.gs.ItemIDs(filter.Items))
+	filter.Except = dw.gs.DisplayItems(dw.gs.ItemIDs(filter.Except))
+	var removed []string
+	dw.gs.Inventory = slices.DeleteFunc(dw.gs.Inventory, func(item string) bool {
+		if filter.Matches(dw.gs.ItemName(item)) {
+			removed = append(removed, item)
+			return true
 		}
+		return false
+	})
+	if len(removed) > 0 && dw.logger != nil {
+		dw.logger.Info("Inventory cleared", "items", removed)
 	}
+}
 
+// removeNPC removes an NPC and its items from the game. NPCs following it stop following.
+func (dw *DeltaWorker) removeNPC(npcID string) {
+	npcKey, ok := dw.findNPCKey(npcID)
+	if !ok {
+		if dw.logger != nil {
+			dw.logger.Warn("NPC not found for removal", "npc_id", npcID)
+		}
+		return
+	}
+	delete(dw.gs.NPCs, npcKey)
+
+	for key, npc := range dw.gs.NPCs {
+		if npc.Following == npcKey {
+			npc.Following = ""
+			dw.gs.NPCs[key] = npc
+		}
+	}
+
+	if dw.logger != nil {
+		dw.logger.Info("NPC removed", "npc", npcKey)
+	}
+}
+
+// handleNPCEvent processes an NPC state change event
+func (dw *DeltaWorker) handleNPCEvent(event conditionals.NPCEvent) {
+	npcKey, npcExists := dw.findNPCKey(event.NPCID)
 	if !npcExists {
 		if dw.logger != nil {
 			dw.logger.Warn("NPC not found for event",
@@ -477,25 +808,12 @@ func (dw *DeltaWorker) handleNPCEvent(event conditionals.NPCEvent) {
 		return
 	}
 
+	npc := dw.gs.NPCs[npcKey]
 	modified := false
 
 	// Handle location change
 	if event.SetLocation != nil {
-		locationKey := strings.ToLower(strings.TrimSpace(*event.SetLocation))
-		_, locationExists := dw.gs.WorldLocations[locationKey]
-
-		if !locationExists {
-			// Try matching by location name
-			for key, loc := range dw.gs.WorldLocations {
-				if strings.ToLower(loc.Name) == locationKey {
-					locationKey = key
-					locationExists = true
-					break
-				}
-			}
-		}
-
-		if locationExists {
+		if locationKey, locationExists := dw.findLocationKey(*event.SetLocation); locationExists {
 			oldLocation := npc.Location
 			npc.Location = locationKey
 			modified = true
@@ -520,22 +838,12 @@ func (dw *DeltaWorker) handleNPCEvent(event conditionals.NPCEvent) {
 		// Validate following target
 		if following != "" && following != "pc" {
 			// Should be a valid NPC ID
-			_, exists := dw.gs.NPCs[following]
-			if !exists {
-				// Try case-insensitive match
-				found := false
-				for key, n := range dw.gs.NPCs {
-					if strings.EqualFold(n.Name, following) {
-						following = key
-						found = true
-						break
-					}
-				}
-				if !found && dw.logger != nil {
-					dw.logger.Warn("Following target not found",
-						"npc", npcKey,
-						"following", following)
-				}
+			if key, found := dw.findNPCKey(following); found {
+				following = key
+			} else if dw.logger != nil {
+				dw.logger.Warn("Following target not found",
+					"npc", npcKey,
+					"following", following)
 			}
 		}
 
@@ -577,21 +885,7 @@ func (dw *DeltaWorker) handleMonsterSpawn(event conditionals.MonsterEvent) {
 		return
 	}
 
-	// Normalize location key
-	locationKey := strings.ToLower(strings.TrimSpace(event.Location))
-	_, locationExists := dw.gs.WorldLocations[locationKey]
-
-	if !locationExists {
-		// Try matching by location name
-		for key, loc := range dw.gs.WorldLocations {
-			if strings.ToLower(loc.Name) == locationKey {
-				locationKey = key
-				locationExists = true
-				break
-			}
-		}
-	}
-
+	locationKey, locationExists := dw.findLocationKey(event.Location)
 	if !locationExists {
 		dw.logger.Warn("Cannot spawn monster: location not found",
 			"instance_id", event.InstanceID,
@@ -677,120 +971,111 @@ func (dw *DeltaWorker) handleMonsterDespawn(event conditionals.MonsterEvent) {
 }
 
 // removeItemFromSource removes an item from the specified source
-func (dw *DeltaWorker) removeItemFromSource(item string, from *struct {
-	Type string `json:"type"`
-	Name string `json:"name,omitempty"`
-}) {
+func (dw *DeltaWorker) removeItemFromSource(item string, from *itemHolder) {
 	gs := dw.gs
 	switch from.Type {
 	case "player":
 		// Remove from player inventory
-		for i, invItem := range gs.Inventory {
-			if invItem == item {
-				gs.Inventory = append(gs.Inventory[:i], gs.Inventory[i+1:]...)
-				break
-			}
-		}
+		gs.Inventory = slices.DeleteFunc(gs.Inventory, func(i string) bool { return i == item })
 	case "location":
-		// Remove from location
-		for key, loc := range gs.WorldLocations {
-			if loc.Name == from.Name {
-				for i, invItem := range loc.Items {
-					if invItem == item {
-						loc.Items = append(loc.Items[:i], loc.Items[i+1:]...)
-						gs.WorldLocations[key] = loc // Write back
-						break
-					}
-				}
-				break
-			}
+		if key, ok := dw.findLocationKey(from.Name); ok {
+			loc := gs.WorldLocations[key]
+			loc.Items = slices.DeleteFunc(loc.Items, func(i string) bool { return i == item })
+			gs.WorldLocations[key] = loc // Write back
 		}
 	case "npc":
-		// Remove from NPC
-		npcKey := strings.ToLower(strings.TrimSpace(from.Name))
-
-		// Try to find NPC in game state by key first
-		if npc, ok := gs.NPCs[npcKey]; ok {
-			for i, invItem := range npc.Items {
-				if invItem == item {
-					npc.Items = append(npc.Items[:i], npc.Items[i+1:]...)
-					gs.NPCs[npcKey] = npc // Write back
-					break
-				}
-			}
-			return
-		}
-
-		// Try matching by NPC name
-		for key, npc := range gs.NPCs {
-			if strings.ToLower(npc.Name) == npcKey {
-				for i, invItem := range npc.Items {
-					if invItem == item {
-						npc.Items = append(npc.Items[:i], npc.Items[i+1:]...)
-						gs.NPCs[key] = npc // Write back
-						break
-					}
-				}
-				break
-			}
+		if key, ok := dw.findNPCKey(from.Name); ok {
+			npc := gs.NPCs[key]
+			npc.Items = slices.DeleteFunc(npc.Items, func(i string) bool { return i == item })
+			gs.NPCs[key] = npc // Write back
 		}
 	}
 }
 
-// addItemToDestination adds an item to the specified destination
-func (dw *DeltaWorker) addItemToDestination(item string, to *struct {
-	Type string `json:"type"`
-	Name string `json:"name,omitempty"`
-}) {
+// addItemToDestination moves an item to the specified destination, taking it from
+// every other holder. If the destination can't be resolved, nothing changes, so
+// the item is never lost.
+func (dw *DeltaWorker) addItemToDestination(item string, to *itemHolder) {
 	gs := dw.gs
+	var locationKey, npcKey string
+	resolved := false
 	switch to.Type {
 	case "player":
-		// Add to player inventory (check for duplicates)
-		itemExists := slices.Contains(gs.Inventory, item)
-		if !itemExists {
-			if gs.Inventory == nil {
-				gs.Inventory = make([]string, 0)
-			}
-			gs.Inventory = append(gs.Inventory, item)
-		}
+		resolved = true
 	case "location":
-		// Add to location
-		for key, loc := range gs.WorldLocations {
-			if loc.Name == to.Name {
-				if loc.Items == nil {
-					loc.Items = make([]string, 0)
-				}
-				loc.Items = append(loc.Items, item)
-				gs.WorldLocations[key] = loc // Write back
-				break
-			}
+		locationKey, resolved = dw.findLocationKey(to.Name)
+	case "npc":
+		npcKey, resolved = dw.findNPCKey(to.Name)
+	}
+	if !resolved {
+		if dw.logger != nil {
+			dw.logger.Warn("Could not find item destination",
+				"item", item,
+				"type", to.Type,
+				"name", to.Name)
 		}
+		return
+	}
+
+	dw.takeItem(item)
+	switch to.Type {
+	case "player":
+		gs.Inventory = append(gs.Inventory, item)
+	case "location":
+		loc := gs.WorldLocations[locationKey]
+		loc.Items = append(loc.Items, item)
+		gs.WorldLocations[locationKey] = loc // Write back
 	case "npc":
-		// Add to NPC
-		npcKey := strings.ToLower(strings.TrimSpace(to.Name))
+		npc := gs.NPCs[npcKey]
+		npc.Items = append(npc.Items, item)
+		gs.NPCs[npcKey] = npc // Write back
+	}
+}
 
-		// Try to find NPC in game state by key first
-		if npc, ok := gs.NPCs[npcKey]; ok {
-			if npc.Items == nil {
-				npc.Items = make([]string, 0)
-			}
-			npc.Items = append(npc.Items, item)
-			gs.NPCs[npcKey] = npc // Write back
-			return
+// takeItem removes every copy of an item from player inventory, NPCs and locations
+func (dw *DeltaWorker) takeItem(item string) {
+	gs := dw.gs
+	isItem := func(i string) bool { return i == item }
+	gs.Inventory = slices.DeleteFunc(gs.Inventory, isItem)
+	for key, npc := range gs.NPCs {
+		if slices.Contains(npc.Items, item) {
+			npc.Items = slices.DeleteFunc(npc.Items, isItem)
+			gs.NPCs[key] = npc
 		}
+	}
+	for key, loc := range gs.WorldLocations {
+		if slices.Contains(loc.Items, item) {
+			loc.Items = slices.DeleteFunc(loc.Items, isItem)
+			gs.WorldLocations[key] = loc
+		}
+	}
+}
 
-		// Try matching by NPC name
-		for key, npc := range gs.NPCs {
-			if strings.ToLower(npc.Name) == npcKey {
-				if npc.Items == nil {
-					npc.Items = make([]string, 0)
-				}
-				npc.Items = append(npc.Items, item)
-				gs.NPCs[key] = npc // Write back
-				break
-			}
+// findLocationKey resolves a location by key or display name, in canonical form; see
+// scenario.NormalizeKey. Scenario keys are canonical once loaded, so keys match exactly.
+func (dw *DeltaWorker) findLocationKey(name string) (string, bool) {
+	return findKey(dw.gs.WorldLocations, name, func(l scenario.Location) string { return l.Name })
+}
+
+// findNPCKey resolves an NPC by key or display name, in canonical form
+func (dw *DeltaWorker) findNPCKey(name string) (string, bool) {
+	return findKey(dw.gs.NPCs, name, func(n actor.NPC) string { return n.Name })
+}
+
+func findKey[V any](m map[string]V, keyOrName string, name func(V) string) (string, bool) {
+	key := scenario.NormalizeKey(keyOrName)
+	if key == "" {
+		return "", false
+	}
+	if _, ok := m[key]; ok {
+		return key, true
+	}
+	for k, v := range m {
+		if scenario.NormalizeKey(name(v)) == key {
+			return k, true
 		}
 	}
+	return "", false
 }
 
 // toSnakeCase converts a string to lower snake_case
@@ -839,18 +1124,7 @@ func (dw *DeltaWorker) syncFollowingNPCs() {
 				targetLocation = dw.gs.Location
 			} else {
 				// Following another NPC
-				followedNPC, exists := dw.gs.NPCs[npc.Following]
-				if !exists {
-					// Try case-insensitive match
-					for _, n := range dw.gs.NPCs {
-						if strings.EqualFold(n.Name, npc.Following) {
-							followedNPC = n
-							exists = true
-							break
-						}
-					}
-				}
-
+				followedKey, exists := dw.findNPCKey(npc.Following)
 				if !exists {
 					if dw.logger != nil {
 						dw.logger.Warn("NPC following target not found",
@@ -860,7 +1134,7 @@ func (dw *DeltaWorker) syncFollowingNPCs() {
 					continue
 				}
 
-				targetLocation = followedNPC.Location
+				targetLocation = dw.gs.NPCs[followedKey].Location
 			}
 
 			// Update NPC location if it differs from target
@@ -0,0 +1,188 @@
+package state
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestDeltaWorker_MergeConditionals_Conflicts(t *testing.T) {
+	tests := []struct {
+		name              string
+		conditionals      string
+		expectedTriggered []string
+		expectedDelta     string
+	}{
+		{
+			name:              "higher priority scene change wins",
+			conditionals:      `{"to_dungeon": {"when": {"vars": {"alarm": "true"}}, "then": {"scene_change": {"to": "dungeon"}}}, "to_escape": {"when": {"vars": {"alarm": "true"}}, "then": {"scene_change": {"to": "escape"}, "set_vars": {"fled": "true"}}, "priority": 5}}`,
+			expectedTriggered: []string{"to_dungeon", "to_escape"},
+			expectedDelta:     `{"scene_change": {"to": "escape", "reason": "conditional"}, "set_vars": {"fled": "true"}}`,
+		},
+		{
+			name:              "same priority goes to earlier ID",
+			conditionals:      `{"b_ending": {"when": {"vars": {"alarm": "true"}}, "then": {"game_ended": false, "user_location": "hall"}}, "a_ending": {"when": {"vars": {"alarm": "true"}}, "then": {"game_ended": true}}}`,
+			expectedTriggered: []string{"a_ending", "b_ending"},
+			expectedDelta:     `{"user_location": "hall", "game_ended": true}`,
+		},
+		{
+			name:              "conflicting vars and NPC events resolved per field",
+			conditionals:      `{"guard_leaves": {"when": {"vars": {"alarm": "true"}}, "then": {"set_vars": {"guard_here": "false", "gate_open": "true"}, "npc_events": [{"npc_id": "guard", "set_location": "yard", "set_following": ""}]}, "priority": 1}, "guard_stays": {"when": {"vars": {"alarm": "true"}}, "then": {"set_vars": {"Guard Here": "true", "gate_open": "true"}, "npc_events": [{"npc_id": "guard", "set_location": "hall", "set_following": "pc"}]}}}`,
+			expectedTriggered: []string{"guard_leaves", "guard_stays"},
+			expectedDelta:     `{"npc_events": [{"npc_id": "guard", "set_location": "yard", "set_following": ""}, {"npc_id": "guard"}], "set_vars": {"guard_here": "false", "gate_open": "true"}}`,
+		},
+		{
+			name:              "exclusivity group fires only the winner",
+			conditionals:      `{"escape_roof": {"when": {"vars": {"alarm": "true"}}, "then": {"set_vars": {"route": "roof"}}, "group": "escape", "priority": 2}, "escape_cellar": {"when": {"vars": {"alarm": "true"}}, "then": {"set_vars": {"cellar_used": "true"}}, "group": "escape"}}`,
+			expectedTriggered: []string{"escape_roof"},
+			expectedDelta:     `{"set_vars": {"route": "roof"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conds map[string]scenario.Conditional
+			if err := json.Unmarshal([]byte(tt.conditionals), &conds); err != nil {
+				t.Fatalf("Failed to parse conditionals: %v", err)
+			}
+			var expected conditionals.GameStateDelta
+			if err := json.Unmarshal([]byte(tt.expectedDelta), &expected); err != nil {
+				t.Fatalf("Failed to parse expected delta: %v", err)
+			}
+
+			gs := &GameState{
+				SceneName: "keep",
+				Location:  "hall",
+				Vars:      map[string]string{"alarm": "true"},
+				NPCs:      map[string]actor.NPC{"guard": {Name: "Guard", Location: "hall"}},
+				WorldLocations: map[string]scenario.Location{
+					"hall": {Name: "Great Hall"},
+					"yard": {Name: "Yard"},
+				},
+			}
+			s := &scenario.Scenario{Scenes: map[string]scenario.Scene{"keep": {Conditionals: conds}}}
+
+			var delta conditionals.GameStateDelta
+			worker := NewDeltaWorker(gs, &delta, s, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			triggered := worker.MergeConditionals()
+
+			if got := slices.Sorted(maps.Keys(triggered)); !slices.Equal(got, tt.expectedTriggered) {
+				t.Errorf("Expected triggered %v, got %v", tt.expectedTriggered, got)
+			}
+			got, _ := json.Marshal(delta)
+			want, _ := json.Marshal(expected)
+			if string(got) != string(want) {
+				t.Errorf("Expected delta %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestDeltaWorker_MergeConditionals_FirePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		fire     string
+		cooldown int
+		turns    [][2]int // Turn counter and scene turn counter for each call; When always matches
+		expected []bool
+	}{
+		{
+			name:     "repeatable fires every turn",
+			turns:    [][2]int{{1, 1}, {2, 2}, {3, 3}},
+			expected: []bool{true, true, true},
+		},
+		{
+			name:     "never twice in one turn",
+			turns:    [][2]int{{1, 1}, {1, 1}, {2, 2}},
+			expected: []bool{true, false, true},
+		},
+		{
+			name:     "cooldown skips turns",
+			cooldown: 2,
+			turns:    [][2]int{{1, 1}, {2, 2}, {3, 3}, {4, 4}},
+			expected: []bool{true, false, false, true},
+		},
+		{
+			name:     "once per game",
+			fire:     scenario.FireOnce,
+			turns:    [][2]int{{1, 1}, {2, 2}, {9, 0}},
+			expected: []bool{true, false, false},
+		},
+		{
+			name:     "once per scene visit",
+			fire:     scenario.FireOncePerScene,
+			turns:    [][2]int{{1, 1}, {2, 2}, {5, 1}, {6, 2}},
+			expected: []bool{true, false, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conds := map[string]scenario.Conditional{
+				"alarm_bell": {
+					When:     conditionals.ConditionalWhen{Vars: map[string]string{"alarm": "true"}},
+					Then:     conditionals.GameStateDelta{SetVars: map[string]string{"bell_rung": "true"}},
+					Fire:     tt.fire,
+					Cooldown: tt.cooldown,
+				},
+			}
+			s := &scenario.Scenario{Scenes: map[string]scenario.Scene{"keep": {Conditionals: conds}}}
+			gs := &GameState{SceneName: "keep", Vars: map[string]string{"alarm": "true"}}
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			for i, turn := range tt.turns {
+				gs.TurnCounter, gs.SceneTurnCounter = turn[0], turn[1]
+				triggered := NewDeltaWorker(gs, &conditionals.GameStateDelta{}, s, logger).MergeConditionals()
+				if _, fired := triggered["alarm_bell"]; fired != tt.expected[i] {
+					t.Errorf("Call %d (turn %v): expected fired=%v, got %v", i, turn, tt.expected[i], fired)
+				}
+			}
+		})
+	}
+}
+
+func TestDeltaWorker_UnlockLore(t *testing.T) {
+	conds := map[string]scenario.Conditional{
+		"read_ledger": {
+			When: conditionals.ConditionalWhen{Vars: map[string]string{"ledger_read": "true"}},
+			Then: conditionals.GameStateDelta{UnlockLore: []string{"smuggling_ring"}},
+		},
+		"name_the_culprit": {
+			When: conditionals.ConditionalWhen{LoreKnown: []string{"smuggling_ring", "harbor_master"}},
+			Then: conditionals.GameStateDelta{SetVars: map[string]string{"culprit_known": "true"}},
+		},
+	}
+	s := &scenario.Scenario{Scenes: map[string]scenario.Scene{"docks": {Conditionals: conds}}}
+	gs := &GameState{
+		SceneName:      "docks",
+		Vars:           map[string]string{"ledger_read": "true", "culprit_known": "false"},
+		DiscoveredLore: []string{"harbor_master"},
+	}
+	worker := NewDeltaWorker(gs, &conditionals.GameStateDelta{}, s, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// The unlock lands first; the lore-gated conditional fires on the next pass
+	for _, expected := range []string{"read_ledger", "name_the_culprit"} {
+		triggered := worker.MergeConditionals()
+		if _, ok := triggered[expected]; !ok {
+			t.Fatalf("Expected %s to trigger, got %v", expected, slices.Sorted(maps.Keys(triggered)))
+		}
+		worker.ApplyVars()
+		if err := worker.Apply(); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+
+	if !slices.Equal(gs.DiscoveredLore, []string{"harbor_master", "smuggling_ring"}) {
+		t.Errorf("Expected lore [harbor_master smuggling_ring], got %v", gs.DiscoveredLore)
+	}
+	if gs.Vars["culprit_known"] != "true" {
+		t.Errorf("Expected culprit_known=true, got %q", gs.Vars["culprit_known"])
+	}
+}
@@ -0,0 +1,159 @@
+package state
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+var (
+	fuzzLocations = map[string]string{"hall": "Great Hall", "cellar": "Cellar", "garden": "Walled Garden", "tower": "Tower"}
+	fuzzNPCs      = map[string]string{"guard": "Guard", "smith": "Old Smith", "witch": "Witch"}
+	fuzzItems     = []string{"key", "sword", "lamp", "coin", "map", "rope"}
+)
+
+// FuzzDeltaWorkerApply applies arbitrary deltas, as a model might return them,
+// to randomized game states, validating them first for even seeds. Items must
+// stay singletons and must only disappear when a "use" event consumes them.
+func FuzzDeltaWorkerApply(f *testing.F) {
+	seeds := []string{
+		`{}`,
+		`{"user_location": "cellar"}`,
+		`{"user_location": "Walled Garden", "item_events": [{"item": "lamp", "action": "drop"}]}`,
+		`{"item_events": [{"item": "key", "action": "acquire", "from": {"type": "location", "name": "Cellar"}}]}`,
+		`{"item_events": [{"item": "coin", "action": "give", "from": {"type": "player"}, "to": {"type": "npc", "name": "Old Smith"}}]}`,
+		`{"item_events": [{"item": "sword", "action": "give", "to": {"type": "npc", "name": "nobody"}}]}`,
+		`{"item_events": [{"item": "map", "action": "move", "from": {"type": "npc", "name": "witch"}, "to": {"type": "location", "name": "tower"}}]}`,
+		`{"item_events": [{"item": "rope", "action": "move", "from": {"type": "location", "name": "Tower"}}]}`,
+		`{"item_events": [{"item": "lamp", "action": "use", "consumed": true}, {"item": "key", "action": "drop", "to": {"type": "location"}}]}`,
+		`{"item_events": [{"item": "", "action": "acquire"}, {"item": "coin", "action": "steal"}]}`,
+		`{"npc_events": [{"npc_id": "guard", "set_following": "pc"}, {"npc_id": "witch", "set_following": "guard"}, {"npc_id": "guard", "set_following": "witch"}]}`,
+		`{"npc_events": [{"npc_id": "Old Smith", "set_location": "nowhere"}, {"npc_id": "ghost", "set_location": "hall"}]}`,
+		`{"scene_change": {"to": "finale"}, "monster_events": [{"action": "despawn", "instance_id": "rat_1"}], "game_ended": true}`,
+	}
+	for i, s := range seeds {
+		f.Add([]byte(s), int64(i))
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	f.Fuzz(func(t *testing.T, deltaJSON []byte, seed int64) {
+		var delta conditionals.GameStateDelta
+		if err := json.Unmarshal(deltaJSON, &delta); err != nil {
+			t.Skip()
+		}
+
+		gs := newFuzzGameState(seed)
+		before := fuzzItemCounts(gs)
+
+		// Validated deltas must stay valid, and Apply must cope with unvalidated ones too
+		worker := NewDeltaWorker(gs, &delta, &scenario.Scenario{}, logger)
+		if seed%2 == 0 {
+			worker.Validate()
+			if issues := worker.Validate(); len(issues) > 0 {
+				t.Errorf("Validated delta still has issues: %v", issues)
+			}
+		}
+		if err := worker.Apply(); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+
+		consumed := make(map[string]bool)
+		for _, e := range delta.ItemEvents {
+			if e.Action == "use" && e.Consumed != nil && *e.Consumed {
+				consumed[e.Item] = true
+			}
+		}
+		after := fuzzItemCounts(gs)
+		for item, n := range after {
+			if n > 1 {
+				t.Errorf("Item %q held %d times: inventory=%v npcs=%v locations=%v", item, n, gs.Inventory, gs.NPCs, gs.WorldLocations)
+			}
+		}
+		// Conditional-only removals take items out of the game on purpose
+		removals := len(delta.ClearInventory) > 0 || len(delta.RemoveNPCs) > 0
+		for item := range before {
+			if after[item] == 0 && !consumed[item] && !removals {
+				t.Errorf("Item %q was lost", item)
+			}
+		}
+		if _, ok := gs.WorldLocations[gs.Location]; !ok {
+			t.Errorf("Player moved to unknown location %q", gs.Location)
+		}
+		for key, npc := range gs.NPCs {
+			if _, ok := gs.WorldLocations[npc.Location]; !ok {
+				t.Errorf("NPC %s moved to unknown location %q", key, npc.Location)
+			}
+		}
+	})
+}
+
+// newFuzzGameState builds a small world from the seed. Every item starts in at most one place.
+func newFuzzGameState(seed int64) *GameState {
+	r := rand.New(rand.NewPCG(uint64(seed), 0))
+	locationKeys := slices.Sorted(maps.Keys(fuzzLocations))
+	pick := func() string { return locationKeys[r.IntN(len(locationKeys))] }
+
+	gs := NewGameState("fuzz.json", nil, "fuzz-model")
+	gs.Location = pick()
+	// Sorted keys keep the world reproducible for a given seed
+	for _, key := range slices.Sorted(maps.Keys(fuzzLocations)) {
+		loc := scenario.Location{Name: fuzzLocations[key], Exits: map[string]string{}}
+		if r.IntN(2) == 0 {
+			loc.Exits["north"] = pick()
+		}
+		gs.WorldLocations[key] = loc
+	}
+	npcKeys := slices.Sorted(maps.Keys(fuzzNPCs))
+	for _, key := range npcKeys {
+		npc := actor.NPC{Name: fuzzNPCs[key], Location: pick()}
+		if r.IntN(4) == 0 {
+			npc.Following = "pc"
+		}
+		gs.NPCs[key] = npc
+	}
+
+	for _, item := range fuzzItems {
+		switch r.IntN(4) {
+		case 0:
+			gs.Inventory = append(gs.Inventory, item)
+		case 1:
+			key := npcKeys[r.IntN(len(npcKeys))]
+			npc := gs.NPCs[key]
+			npc.Items = append(npc.Items, item)
+			gs.NPCs[key] = npc
+		case 2:
+			key := pick()
+			loc := gs.WorldLocations[key]
+			loc.Items = append(loc.Items, item)
+			gs.WorldLocations[key] = loc
+		}
+	}
+	return gs
+}
+
+// fuzzItemCounts counts how many times each item appears across inventory, NPCs, and locations
+func fuzzItemCounts(gs *GameState) map[string]int {
+	counts := make(map[string]int)
+	for _, item := range gs.Inventory {
+		counts[item]++
+	}
+	for _, npc := range gs.NPCs {
+		for _, item := range npc.Items {
+			counts[item]++
+		}
+	}
+	for _, loc := range gs.WorldLocations {
+		for _, item := range loc.Items {
+			counts[item]++
+		}
+	}
+	return counts
+}
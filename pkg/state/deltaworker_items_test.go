@@ -0,0 +1,105 @@
+package state
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestDeltaWorker_ItemEvents(t *testing.T) {
+	tests := []struct {
+		name              string
+		itemEvents        string
+		expectedInventory []string
+		expectedNPCItems  map[string][]string
+		expectedLocItems  map[string][]string
+	}{
+		{
+			name:              "drop defaults to current location",
+			itemEvents:        `[{"item": "lamp", "action": "drop"}]`,
+			expectedInventory: []string{"coin"},
+			expectedLocItems:  map[string][]string{"hall": {"lamp"}, "cellar": {"key"}},
+		},
+		{
+			name:              "location resolved by key or name",
+			itemEvents:        `[{"item": "lamp", "action": "drop", "to": {"type": "location", "name": "cellar"}}, {"item": "key", "action": "acquire", "from": {"type": "location", "name": "Cellar"}}]`,
+			expectedInventory: []string{"coin", "key"},
+			expectedLocItems:  map[string][]string{"cellar": {"lamp"}},
+		},
+		{
+			name:              "give to unknown NPC keeps item",
+			itemEvents:        `[{"item": "coin", "action": "give", "to": {"type": "npc", "name": "Stranger"}}]`,
+			expectedInventory: []string{"lamp", "coin"},
+			expectedLocItems:  map[string][]string{"cellar": {"key"}},
+		},
+		{
+			name:              "move without destination keeps item",
+			itemEvents:        `[{"item": "key", "action": "move", "from": {"type": "location", "name": "Cellar"}}]`,
+			expectedInventory: []string{"lamp", "coin"},
+			expectedLocItems:  map[string][]string{"cellar": {"key"}},
+		},
+		{
+			name:              "give with wrong source does not duplicate",
+			itemEvents:        `[{"item": "key", "action": "give", "from": {"type": "npc", "name": "Smith"}, "to": {"type": "npc", "name": "Smith"}}]`,
+			expectedInventory: []string{"lamp", "coin"},
+			expectedNPCItems:  map[string][]string{"smith": {"key"}},
+		},
+		{
+			name:              "catalogued item resolved by display name",
+			itemEvents:        `[{"item": "Iron Key", "action": "acquire", "from": {"type": "location", "name": "Cellar"}}]`,
+			expectedInventory: []string{"lamp", "coin", "key"},
+		},
+		{
+			name:              "blank item ignored",
+			itemEvents:        `[{"item": " ", "action": "acquire"}]`,
+			expectedInventory: []string{"lamp", "coin"},
+			expectedLocItems:  map[string][]string{"cellar": {"key"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &GameState{
+				Location:  "hall",
+				Inventory: []string{"lamp", "coin"},
+				ItemNames: map[string]string{"key": "Iron Key"},
+				NPCs: map[string]actor.NPC{
+					"smith": {Name: "Smith", Location: "hall"},
+				},
+				WorldLocations: map[string]scenario.Location{
+					"hall":   {Name: "Great Hall"},
+					"cellar": {Name: "Cellar", Items: []string{"key"}},
+				},
+			}
+			var delta conditionals.GameStateDelta
+			if err := json.Unmarshal([]byte(`{"item_events": `+tt.itemEvents+`}`), &delta); err != nil {
+				t.Fatalf("Failed to parse delta: %v", err)
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			if err := NewDeltaWorker(gs, &delta, &scenario.Scenario{}, logger).Apply(); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+
+			if !slices.Equal(gs.Inventory, tt.expectedInventory) {
+				t.Errorf("Expected inventory %v, got %v", tt.expectedInventory, gs.Inventory)
+			}
+			for key, npc := range gs.NPCs {
+				if !slices.Equal(npc.Items, tt.expectedNPCItems[key]) {
+					t.Errorf("Expected NPC %s items %v, got %v", key, tt.expectedNPCItems[key], npc.Items)
+				}
+			}
+			for key, loc := range gs.WorldLocations {
+				if !slices.Equal(loc.Items, tt.expectedLocItems[key]) {
+					t.Errorf("Expected location %s items %v, got %v", key, tt.expectedLocItems[key], loc.Items)
+				}
+			}
+		})
+	}
+}
@@ -296,6 +296,40 @@ func TestDeltaWorker_HandleNPCEvent_CaseInsensitiveMatching(t *testing.T) {
 	}
 }
 
+func TestDeltaWorker_HandleNPCEvent_CanonicalMatching(t *testing.T) {
+	gs := &GameState{
+		NPCs: map[string]actor.NPC{
+			"harbor_master": {Name: "Harbor Master", Location: "docks"},
+		},
+		WorldLocations: map[string]scenario.Location{
+			"docks":          {Name: "Docks"},
+			"captains_cabin": {Name: "Captain's Cabin"},
+		},
+	}
+
+	delta := &conditionals.GameStateDelta{
+		UserLocation: "Captain’s Cabin", // Curly apostrophe
+		NPCEvents: []conditionals.NPCEvent{
+			{
+				NPCID:       "harbor-master",             // Key written with a hyphen
+				SetLocation: stringPtr("captains cabin"), // Key written with a space
+			},
+		},
+	}
+
+	dw := NewDeltaWorker(gs, delta, nil, nil)
+	if err := dw.Apply(); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if gs.Location != "captains_cabin" {
+		t.Errorf("Expected location captains_cabin, got %s", gs.Location)
+	}
+	if gs.NPCs["harbor_master"].Location != "captains_cabin" {
+		t.Errorf("Expected harbor_master location captains_cabin, got %s", gs.NPCs["harbor_master"].Location)
+	}
+}
+
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
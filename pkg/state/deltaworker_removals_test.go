@@ -0,0 +1,139 @@
+package state
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestDeltaWorker_Removals(t *testing.T) {
+	tests := []struct {
+		name              string
+		delta             string
+		expectedVars      []string
+		expectedInventory []string
+		expectedNPCs      []string
+		expectedFollowing map[string]string
+	}{
+		{
+			name:              "remove vars by any casing",
+			delta:             `{"remove_vars": ["bribe_offered", "Guard Suspicious", "never_set"]}`,
+			expectedVars:      []string{"door_open"},
+			expectedInventory: []string{"sword", "rusty key", "gold key", "wedding ring"},
+			expectedNPCs:      []string{"guard", "smith"},
+			expectedFollowing: map[string]string{"guard": "smith"},
+		},
+		{
+			name:              "remove wins over set in same delta",
+			delta:             `{"set_vars": {"door_open": "true"}, "remove_vars": ["door_open"]}`,
+			expectedVars:      []string{"bribe_offered", "guard_suspicious"},
+			expectedInventory: []string{"sword", "rusty key", "gold key", "wedding ring"},
+			expectedNPCs:      []string{"guard", "smith"},
+			expectedFollowing: map[string]string{"guard": "smith"},
+		},
+		{
+			name:              "empty filter clears everything",
+			delta:             `{"clear_inventory": [{}]}`,
+			expectedVars:      []string{"bribe_offered", "door_open", "guard_suspicious"},
+			expectedInventory: nil,
+			expectedNPCs:      []string{"guard", "smith"},
+			expectedFollowing: map[string]string{"guard": "smith"},
+		},
+		{
+			name:              "filters combine contains and except",
+			delta:             `{"clear_inventory": [{"contains": "KEY", "except": ["gold key"]}, {"items": ["sword", "wedding ring"], "except": ["wedding ring"]}]}`,
+			expectedVars:      []string{"bribe_offered", "door_open", "guard_suspicious"},
+			expectedInventory: []string{"gold key", "wedding ring"},
+			expectedNPCs:      []string{"guard", "smith"},
+			expectedFollowing: map[string]string{"guard": "smith"},
+		},
+		{
+			name:              "clear runs before item events",
+			delta:             `{"clear_inventory": [{}], "item_events": [{"item": "prison uniform", "action": "acquire"}]}`,
+			expectedVars:      []string{"bribe_offered", "door_open", "guard_suspicious"},
+			expectedInventory: []string{"prison uniform"},
+			expectedNPCs:      []string{"guard", "smith"},
+			expectedFollowing: map[string]string{"guard": "smith"},
+		},
+		{
+			name:              "remove NPC by name and release followers",
+			delta:             `{"remove_npcs": ["Old Smith", "ghost"]}`,
+			expectedVars:      []string{"bribe_offered", "door_open", "guard_suspicious"},
+			expectedInventory: []string{"sword", "rusty key", "gold key", "wedding ring"},
+			expectedNPCs:      []string{"guard"},
+			expectedFollowing: map[string]string{"guard": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &GameState{
+				Location:  "hall",
+				Vars:      map[string]string{"door_open": "false", "bribe_offered": "true", "guard_suspicious": "true"},
+				Inventory: []string{"sword", "rusty key", "gold key", "wedding ring"},
+				NPCs: map[string]actor.NPC{
+					"smith": {Name: "Old Smith", Location: "hall", Items: []string{"hammer"}},
+					"guard": {Name: "Guard", Location: "hall", Following: "smith"},
+				},
+				WorldLocations: map[string]scenario.Location{
+					"hall": {Name: "Great Hall"},
+				},
+			}
+			var delta conditionals.GameStateDelta
+			if err := json.Unmarshal([]byte(tt.delta), &delta); err != nil {
+				t.Fatalf("Failed to parse delta: %v", err)
+			}
+
+			worker := NewDeltaWorker(gs, &delta, &scenario.Scenario{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			worker.ApplyVars()
+			if err := worker.Apply(); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+
+			if vars := slices.Sorted(maps.Keys(gs.Vars)); !slices.Equal(vars, tt.expectedVars) {
+				t.Errorf("Expected vars %v, got %v", tt.expectedVars, vars)
+			}
+			if !slices.Equal(gs.Inventory, tt.expectedInventory) {
+				t.Errorf("Expected inventory %v, got %v", tt.expectedInventory, gs.Inventory)
+			}
+			if npcs := slices.Sorted(maps.Keys(gs.NPCs)); !slices.Equal(npcs, tt.expectedNPCs) {
+				t.Errorf("Expected NPCs %v, got %v", tt.expectedNPCs, npcs)
+			}
+			for key, following := range tt.expectedFollowing {
+				if got := gs.NPCs[key].Following; got != following {
+					t.Errorf("Expected NPC %s following %q, got %q", key, following, got)
+				}
+			}
+		})
+	}
+}
+
+func TestInventoryFilter_Matches(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   conditionals.InventoryFilter
+		item     string
+		expected bool
+	}{
+		{name: "empty matches all", filter: conditionals.InventoryFilter{}, item: "sword", expected: true},
+		{name: "listed item", filter: conditionals.InventoryFilter{Items: []string{"sword"}}, item: "sword", expected: true},
+		{name: "unlisted item", filter: conditionals.InventoryFilter{Items: []string{"sword"}}, item: "lamp", expected: false},
+		{name: "contains ignores case", filter: conditionals.InventoryFilter{Contains: "Key"}, item: "rusty key", expected: true},
+		{name: "except wins", filter: conditionals.InventoryFilter{Items: []string{"sword"}, Except: []string{"sword"}}, item: "sword", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.item); got != tt.expected {
+				t.Errorf("Expected Matches(%q) = %v, got %v", tt.item, tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package state
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/queue"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+type recordingQueue struct {
+	requests []*queue.Request
+}
+
+func (q *recordingQueue) GetFormattedEvents(ctx context.Context, gameID uuid.UUID) (string, error) {
+	return "", nil
+}
+
+func (q *recordingQueue) Clear(ctx context.Context, gameID uuid.UUID) error {
+	return nil
+}
+
+func (q *recordingQueue) EnqueueRequest(ctx context.Context, req *queue.Request) error {
+	q.requests = append(q.requests, req)
+	return nil
+}
+
+func TestDeltaWorker_SceneOpeningPrompt(t *testing.T) {
+	s := &scenario.Scenario{
+		Scenes: map[string]scenario.Scene{
+			"arrival": {Story: "You arrive."},
+			"storm":   {Story: "A storm hits.", OpeningPrompt: "Thunder splits the sky as the storm breaks over the harbor."},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name           string
+		fromScene      string
+		toScene        string
+		fired          []string
+		expectedPrompt string
+	}{
+		{
+			name:           "entering scene queues its opening",
+			fromScene:      "arrival",
+			toScene:        "storm",
+			expectedPrompt: "Thunder splits the sky as the storm breaks over the harbor.",
+		},
+		{
+			name:      "scene without opening queues nothing",
+			fromScene: "storm",
+			toScene:   "arrival",
+		},
+		{
+			name:      "opening only narrated once",
+			fromScene: "arrival",
+			toScene:   "storm",
+			fired:     []string{"scene_opening:storm"},
+		},
+		{
+			name:      "staying in scene queues nothing",
+			fromScene: "storm",
+			toScene:   "storm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := NewGameState("test.json", nil, "test-model")
+			gs.SceneName = tt.fromScene
+			gs.FiredStoryEvents = tt.fired
+			q := &recordingQueue{}
+			delta := &conditionals.GameStateDelta{
+				SceneChange: &struct {
+					To     string `json:"to"`
+					Reason string `json:"reason"`
+				}{To: tt.toScene},
+			}
+
+			if err := NewDeltaWorker(gs, delta, s, logger).WithQueue(q).Apply(); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+
+			if tt.expectedPrompt == "" {
+				if len(q.requests) != 0 {
+					t.Errorf("Expected no story events, got %d", len(q.requests))
+				}
+				return
+			}
+			if len(q.requests) != 1 {
+				t.Fatalf("Expected 1 story event, got %d", len(q.requests))
+			}
+			req := q.requests[0]
+			if req.Type != queue.RequestTypeStoryEvent || req.EventPrompt != tt.expectedPrompt {
+				t.Errorf("Expected story event %q, got %s %q", tt.expectedPrompt, req.Type, req.EventPrompt)
+			}
+			if !slices.Contains(gs.FiredStoryEvents, "scene_opening:"+tt.toScene) {
+				t.Errorf("Expected scene opening to be marked as fired, got %v", gs.FiredStoryEvents)
+			}
+		})
+	}
+}
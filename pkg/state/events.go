@@ -0,0 +1,90 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/jsonpatch"
+)
+
+// Game event kinds, describing what caused a save
+const (
+	EventCreated  = "created"   // New game
+	EventTurn     = "turn"      // Narration and chat history for a player turn or story event
+	EventContinue = "continued" // Last narration continued, without a new turn
+	EventDelta    = "delta"     // Background gamestate delta and the conditionals it fired
+	EventCommand  = "command"   // Slash command such as /inventory or /hint
+	EventPatch    = "patch"     // Edit through the API
+	EventPaused   = "paused"
+	EventResumed  = "resumed"
+	EventForked   = "forked"   // First event after the log was copied from another game
+	EventImported = "imported" // Game restored from a save file
+	EventRewound  = "rewound"  // Last player turn undone, so it can be regenerated
+	EventRestored = "restored" // Game rolled back to a named snapshot
+	EventNarrator = "narrator" // Narrator switched mid-session
+	EventSaved    = "saved"    // Any other save
+)
+
+// GameEvent is one entry in a game's append-only event log. Each save records the
+// change it made as a JSON Merge Patch (RFC 7396) against the previous state, so the
+// state after any event can be rebuilt with Project.
+type GameEvent struct {
+	Seq               int                          `json:"seq"`                          // 1-based position in the log
+	Kind              string                       `json:"kind"`                         // What caused the save; see the Event constants
+	Turn              int                          `json:"turn"`                         // Turn counter after the event
+	At                time.Time                    `json:"at"`                           // When the event was recorded
+	Delta             *conditionals.GameStateDelta `json:"delta,omitempty"`              // Delta applied, for delta events
+	ConditionalsFired []string                     `json:"conditionals_fired,omitempty"` // Conditionals fired by the delta, in order
+	Reducer           string                       `json:"reducer,omitempty"`            // Version of the reducer prompt that produced the delta
+	ForkedFrom        string                       `json:"forked_from,omitempty"`        // Game the log was copied from, for forked events
+	Patch             json.RawMessage              `json:"patch"`                        // Merge patch from the previous state
+}
+
+type gameEventContextKey struct{}
+
+// WithGameEvent returns a context whose game state saves are recorded in the event log
+// as ev. Storage fills in the sequence number, time, turn and patch.
+func WithGameEvent(ctx context.Context, ev GameEvent) context.Context {
+	return context.WithValue(ctx, gameEventContextKey{}, ev)
+}
+
+// GameEventFromContext returns the event set by WithGameEvent, or a saved event if none
+func GameEventFromContext(ctx context.Context) GameEvent {
+	if ev, ok := ctx.Value(gameEventContextKey{}).(GameEvent); ok {
+		return ev
+	}
+	return GameEvent{Kind: EventSaved}
+}
+
+// ProjectJSON applies the patches of events in order, starting from an empty
+// document, and returns the resulting game state JSON
+func ProjectJSON(events []GameEvent) ([]byte, error) {
+	doc := []byte("{}")
+	for _, ev := range events {
+		var err error
+		if doc, err = jsonpatch.MergePatch(doc, ev.Patch); err != nil {
+			return nil, fmt.Errorf("failed to apply event %d: %w", ev.Seq, err)
+		}
+	}
+	return doc, nil
+}
+
+// Project rebuilds the game state after the last of events, which must start at the
+// beginning of the log
+func Project(events []GameEvent) (*GameState, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no events to project")
+	}
+	doc, err := ProjectJSON(events)
+	if err != nil {
+		return nil, err
+	}
+	var gs GameState
+	if err := json.Unmarshal(doc, &gs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal projected game state: %w", err)
+	}
+	return &gs, nil
+}
@@ -0,0 +1,96 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/jsonpatch"
+)
+
+// recordEvents builds an event log from successive saves of a game state
+func recordEvents(t *testing.T, saves ...*GameState) []GameEvent {
+	t.Helper()
+	prev := []byte("{}")
+	var events []GameEvent
+	for i, gs := range saves {
+		data, err := json.Marshal(gs)
+		if err != nil {
+			t.Fatalf("failed to marshal save %d: %v", i, err)
+		}
+		patch, err := jsonpatch.CreateMergePatch(prev, data)
+		if err != nil {
+			t.Fatalf("failed to diff save %d: %v", i, err)
+		}
+		events = append(events, GameEvent{Seq: i + 1, Kind: EventSaved, Turn: gs.TurnCounter, Patch: patch})
+		prev = data
+	}
+	return events
+}
+
+func TestProject(t *testing.T) {
+	first := NewGameState("test.json", nil, "test-model")
+	first.Location = "hall"
+	first.Inventory = []string{"rope"}
+	first.Vars = map[string]string{"door_open": "false"}
+
+	second, err := first.DeepCopy()
+	if err != nil {
+		t.Fatalf("DeepCopy failed: %v", err)
+	}
+	second.IncrementTurnCounters()
+	second.Location = "cellar"
+	second.Inventory = append(second.Inventory, "lantern")
+	second.Vars["door_open"] = "true"
+
+	third, err := second.DeepCopy()
+	if err != nil {
+		t.Fatalf("DeepCopy failed: %v", err)
+	}
+	third.IncrementTurnCounters()
+	third.Inventory = nil
+	third.Vars = nil
+	third.IsEnded = true
+
+	events := recordEvents(t, first, second, third)
+
+	tests := []struct {
+		name   string
+		events []GameEvent
+		want   *GameState
+	}{
+		{"first event", events[:1], first},
+		{"time travel", events[:2], second},
+		{"whole log", events, third},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Project(tt.events)
+			if err != nil {
+				t.Fatalf("Project failed: %v", err)
+			}
+			wantJSON, _ := json.Marshal(tt.want)
+			gotJSON, _ := json.Marshal(got)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("projected state differs\nwant: %s\ngot:  %s", wantJSON, gotJSON)
+			}
+		})
+	}
+
+	if _, err := Project(nil); err == nil {
+		t.Error("expected an error projecting an empty log")
+	}
+}
+
+func TestGameEventFromContext(t *testing.T) {
+	if got := GameEventFromContext(context.Background()).Kind; got != EventSaved {
+		t.Errorf("expected %q without an event, got %q", EventSaved, got)
+	}
+
+	ctx := WithGameEvent(context.Background(), GameEvent{Kind: EventDelta, ConditionalsFired: []string{"storm"}})
+	ev := GameEventFromContext(ctx)
+	if ev.Kind != EventDelta || len(ev.ConditionalsFired) != 1 {
+		t.Errorf("expected the delta event from the context, got %+v", ev)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jwebster45206/story-engine/pkg/actor"
 	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/combat"
 	"github.com/jwebster45206/story-engine/pkg/scenario"
 )
 
@@ -16,24 +18,54 @@ import (
 type GameState struct {
 	ID                 uuid.UUID                    `json:"id"`                           // Unique ID per session
 	ModelName          string                       `json:"model_name,omitempty" `        // Name of the large language model driving gameplay
+	Profile            string                       `json:"profile,omitempty"`            // Configuration profile that owns this game, if any
+	APIKeyID           string                       `json:"api_key_id,omitempty"`         // ID of the API key that created this game; its spend is charged to that key
 	Scenario           string                       `json:"scenario,omitempty" `          // Filename of the scenario being played. Ex: "foo_scenario.json"
 	SceneName          string                       `json:"scene_name,omitempty" `        // Current scene name in the scenario, if applicable
-	Narrator           *scenario.Narrator           `json:"narrator,omitempty"`           // Embedded narrator for this game session (loaded once at creation)
+	Narrator           *scenario.Narrator           `json:"narrator,omitempty"`           // Embedded narrator for this game session (loaded at creation; switched with POST /v1/gamestate/{id}/narrator)
 	PC                 *actor.PC                    `json:"pc,omitempty"`                 // Player Character for this game session
 	NPCs               map[string]actor.NPC         `json:"npcs,omitempty" `              // All NPCs in the game world
 	WorldLocations     map[string]scenario.Location `json:"locations,omitempty" `         // Current locations in the game world
 	Location           string                       `json:"user_location,omitempty" `     // Current location in the game world
-	Inventory          []string                     `json:"user_inventory,omitempty" `    // User's inventory items
+	Inventory          []string                     `json:"user_inventory,omitempty" `    // User's inventory items, by item ID
+	ItemNames          map[string]string            `json:"item_names,omitempty"`         // Display names of the scenario's catalogued items, by item ID; see ItemName
 	ChatHistory        []chat.ChatMessage           `json:"chat_history,omitempty" `      // Conversation history
 	TurnCounter        int                          `json:"turn_counter" `                // Total number of successful chat interactions
 	SceneTurnCounter   int                          `json:"scene_turn_counter" `          // Number of successful chat interactions in current scene
 	Vars               map[string]string            `json:"vars,omitempty"`               // Game variables (e.g. flags, counters)
 	FiredStoryEvents   []string                     `json:"fired_story_events,omitempty"` // IDs of story events that have already fired (never fire twice)
+	FiredConditionals  map[string]ConditionalFiring `json:"fired_conditionals,omitempty"` // Last firing of each conditional, by conditional ID, for fire policies
+	Seed               int64                        `json:"seed"`                         // Seed for the game's random numbers; see Rand
 	IsEnded            bool                         `json:"is_ended"`                     // true when the game is over
+	Paused             bool                         `json:"paused,omitempty"`             // true while paused: chats are rejected and story events are held
+	PausedAt           *time.Time                   `json:"paused_at,omitempty"`          // When the game was paused
+	PauseReason        string                       `json:"pause_reason,omitempty"`       // Optional reason shown to players while paused
+	HeldStoryEvents    []string                     `json:"held_story_events,omitempty"`  // Story event prompts that came due while paused, queued again on resume
+	ChoicesMode        bool                         `json:"choices_mode,omitempty"`       // true to suggest 2-4 next actions after each narration turn
+	Spectators         bool                         `json:"spectators,omitempty"`         // true to mirror narration to the public, delayed spectator feed
 	ContingencyPrompts []string                     `json:"contingency_prompts,omitempty"`
+	TurnReceipts       []TurnReceipt                `json:"turn_receipts,omitempty"`  // Recent per-turn change summaries, oldest first
+	AppliedDeltas      []string                     `json:"applied_deltas,omitempty"` // Idempotency keys of recently applied deltas, oldest first; see DeltaKey
 	CreatedAt          time.Time                    `json:"created_at" `
 	UpdatedAt          time.Time                    `json:"updated_at" `
 
+	// A side conversation with one NPC, and the NPCs' memories of earlier ones; see Conversation
+	Conversation *Conversation                 `json:"conversation,omitempty"`
+	NPCMemories  map[string][]chat.ChatMessage `json:"npc_memories,omitempty"` // By NPC ID, oldest first
+
+	DiscoveredLore []string `json:"discovered_lore,omitempty"` // IDs of scenario lore entries the player has come across, in order
+
+	NPCInteractions map[string]int `json:"npc_interactions,omitempty"` // TurnCounter when the player last dealt with each NPC, by NPC ID; see NPCRelevance
+
+	Hints map[string][]string `json:"hints,omitempty"` // Hints given to the player, by scene, oldest first
+
+	Trial *Trial `json:"trial,omitempty"` // Set for preview games, limited to a few turns; see Trial
+
+	LastTurn        *LastTurn        `json:"last_turn,omitempty"`        // State before the latest player turn, for regenerating it; see LastTurn
+	PendingVariants *PendingVariants `json:"pending_variants,omitempty"` // Narration candidates waiting for the client's choice; see PendingVariants
+	PendingCheck    *CheckResult     `json:"pending_check,omitempty"`    // Skill check rolled by the last delta, for the next narration to resolve; see RollCheck
+	Combat          *combat.Combat   `json:"combat,omitempty"`           // Fight in progress, or just finished and not yet narrated; see StartCombat
+
 	// JustEntered is true on the first turn after a location change.
 	// Transient: set by the delta worker when Apply() changes Location,
 	// cleared on the next Apply() that does not change Location. Not
@@ -42,11 +74,19 @@ type GameState struct {
 	JustEntered bool `json:"-"`
 }
 
+// ConditionalFiring records when a conditional last fired
+type ConditionalFiring struct {
+	Turn  int    `json:"turn"`  // TurnCounter when it last fired
+	Scene string `json:"scene"` // Scene it fired in
+	Count int    `json:"count"` // Times fired this game
+}
+
 func NewGameState(scenarioFileName string, narrator *scenario.Narrator, modelName string) *GameState {
 	return &GameState{
 		ID:                 uuid.New(),
 		ModelName:          modelName,
 		Scenario:           scenarioFileName,
+		Seed:               NewSeed(),
 		Narrator:           narrator, // Embed full narrator object
 		ChatHistory:        make([]chat.ChatMessage, 0),
 		TurnCounter:        0,
@@ -61,6 +101,37 @@ func NewGameState(scenarioFileName string, narrator *scenario.Narrator, modelNam
 	}
 }
 
+// Summary returns a compact snapshot of the game state for chat responses.
+// previousInventory is the inventory at the start of the turn; any items
+// present now but not then are reported as NewItems.
+func (gs *GameState) Summary(previousInventory []string) *chat.StateSummary {
+	had := make(map[string]bool, len(previousInventory))
+	for _, item := range previousInventory {
+		had[item] = true
+	}
+
+	var newItems []string
+	for _, item := range gs.Inventory {
+		if !had[item] {
+			newItems = append(newItems, gs.ItemName(item))
+		}
+	}
+
+	summary := &chat.StateSummary{
+		Location:         gs.Location,
+		SceneName:        gs.SceneName,
+		TurnCounter:      gs.TurnCounter,
+		SceneTurnCounter: gs.SceneTurnCounter,
+		IsEnded:          gs.IsEnded,
+		NewItems:         newItems,
+	}
+	if gs.Trial != nil {
+		left := gs.Trial.TurnsLeft()
+		summary.TrialTurnsLeft = &left
+	}
+	return summary
+}
+
 func (gs *GameState) Validate() error {
 	if gs.Scenario == "" {
 		return fmt.Errorf("scenario.file_name is required")
@@ -98,8 +169,9 @@ func (gs *GameState) GetContingencyPrompts(s *scenario.Scenario) []string {
 		}
 	}
 
-	// NPC-level contingency prompts
-	for _, npc := range gs.NPCs {
+	// NPC-level contingency prompts, in NPC ID order so prompts are stable between turns
+	for _, id := range slices.Sorted(maps.Keys(gs.NPCs)) {
+		npc := gs.NPCs[id]
 		// Only include prompts for NPCs at the player's current location
 		if npc.Location != gs.Location {
 			continue
@@ -176,8 +248,9 @@ func (gs *GameState) LoadScene(s *scenario.Scenario, sceneName string) error {
 	}
 
 	// Copy NPCs from scene
-	if scene.NPCs != nil {
-		maps.Copy(gs.NPCs, scene.NPCs)
+	for key, npc := range scene.NPCs {
+		npc.InitHP()
+		gs.NPCs[key] = npc
 	}
 
 	// Remove any NPCs that are not in the global scenario NPCs,
@@ -200,6 +273,21 @@ func (gs *GameState) LoadScene(s *scenario.Scenario, sceneName string) error {
 	return nil
 }
 
+// TurnKind says whether an interaction consumes a game turn
+type TurnKind string
+
+const (
+	TurnPlayer TurnKind = "player" // A player action; advances the turn counters
+	TurnFree   TurnKind = "free"   // A free action the client asked not to count, e.g. checking inventory
+	TurnSystem TurnKind = "system" // Narration driven by the engine, such as a story event
+)
+
+// AdvancesTurn reports whether an interaction of this kind increments the turn counters.
+// Free and system turns still apply their deltas and conditionals, within the current turn.
+func (k TurnKind) AdvancesTurn() bool {
+	return k == TurnPlayer
+}
+
 // IncrementTurnCounters increments both the turn counter and scene turn counter
 // after a successful chat interaction.
 func (gs *GameState) IncrementTurnCounters() {
@@ -207,49 +295,74 @@ func (gs *GameState) IncrementTurnCounters() {
 	gs.SceneTurnCounter++
 }
 
+// Pause stops the game taking turns until Resume. Pausing a paused game only updates the reason.
+func (gs *GameState) Pause(reason string) {
+	if !gs.Paused {
+		now := time.Now()
+		gs.Paused = true
+		gs.PausedAt = &now
+	}
+	if reason != "" {
+		gs.PauseReason = reason
+	}
+}
+
+// Resume unpauses the game and returns the story events held while it was paused, oldest first
+func (gs *GameState) Resume() []string {
+	held := gs.HeldStoryEvents
+	gs.Paused = false
+	gs.PausedAt = nil
+	gs.PauseReason = ""
+	gs.HeldStoryEvents = nil
+	return held
+}
+
+// PausedMessage is the player-facing explanation for a chat sent to a paused game
+func (gs *GameState) PausedMessage() string {
+	if gs.PauseReason != "" {
+		return "This game is paused (" + gs.PauseReason + "). Please try again once it resumes."
+	}
+	return "This game is paused. Please try again once it resumes."
+}
+
 // NormalizeItems enforces item singletons by removing duplicate items across:
 // - User inventory (highest priority)
 // - NPC items (second priority)
 // - Location items (lowest priority)
 // If an item exists in multiple places, it is removed from the lower priority locations.
+// Ties within a tier go to the first holder in key order.
 func (gs *GameState) NormalizeItems() {
 	if gs == nil {
 		return
 	}
 
-	// Create a set of items in user inventory for fast lookup
-	userItems := make(map[string]bool)
-	for _, item := range gs.Inventory {
-		userItems[item] = true
-	}
-
-	// Remove duplicates from NPCs and enforce singletons within NPC collection
-	npcItems := make(map[string]bool)
-	for npcName, npc := range gs.NPCs {
-		var filteredItems []string
-		for _, item := range npc.Items {
-			// Keep item only if it's not in user inventory and not already claimed by another NPC
-			if !userItems[item] && !npcItems[item] {
-				filteredItems = append(filteredItems, item)
-				npcItems[item] = true
+	// Items already claimed by a higher-priority holder. NPCs and locations are
+	// visited in key order so the outcome doesn't depend on map iteration.
+	claimed := make(map[string]bool)
+	claim := func(items []string) []string {
+		var kept []string
+		for _, item := range items {
+			if !claimed[item] {
+				kept = append(kept, item)
+				claimed[item] = true
 			}
 		}
-		// Update the NPC in the map
-		npc.Items = filteredItems
+		return kept
+	}
+
+	if len(gs.Inventory) > 0 {
+		gs.Inventory = claim(gs.Inventory)
+	}
+
+	for _, npcName := range slices.Sorted(maps.Keys(gs.NPCs)) {
+		npc := gs.NPCs[npcName]
+		npc.Items = claim(npc.Items)
 		gs.NPCs[npcName] = npc
 	}
 
-	// Remove duplicates from locations
-	for locName, location := range gs.WorldLocations {
-		var filteredItems []string
-		for _, item := range location.Items {
-			// Keep item only if it's not in user inventory or with NPCs
-			if !userItems[item] && !npcItems[item] {
-				filteredItems = append(filteredItems, item)
-			}
-		}
-		// Update the location in the map
-		location.Items = filteredItems
+	for _, locName := range slices.Sorted(maps.Keys(gs.WorldLocations)) {
+		location := gs.WorldLocations[locName]
+		location.Items = claim(location.Items)
 		gs.WorldLocations[locName] = location
 	}
 }
@@ -295,6 +408,7 @@ func (gs *GameState) SpawnMonster(template *actor.Monster, monsterDef *actor.Mon
 	if m == nil {
 		return nil
 	}
+	m.Items = gs.ItemIDs(m.Items) // templates name their items
 
 	loc.Monsters[monsterDef.ID] = m
 	gs.WorldLocations[location] = loc
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/jwebster45206/story-engine/pkg/actor"
@@ -464,6 +465,52 @@ func TestGameState_IncrementTurnCounters(t *testing.T) {
 	}
 }
 
+func TestGameState_Summary(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	gs.Location = "cellar"
+	gs.SceneName = "act1"
+	gs.TurnCounter = 5
+	gs.SceneTurnCounter = 2
+	gs.Inventory = []string{"lantern", "skeleton key", "rope"}
+
+	tests := []struct {
+		name              string
+		previousInventory []string
+		expectedNewItems  []string
+	}{
+		{
+			name:              "no new items",
+			previousInventory: []string{"lantern", "skeleton key", "rope"},
+			expectedNewItems:  nil,
+		},
+		{
+			name:              "one new item",
+			previousInventory: []string{"lantern", "rope"},
+			expectedNewItems:  []string{"skeleton key"},
+		},
+		{
+			name:              "everything new",
+			previousInventory: nil,
+			expectedNewItems:  []string{"lantern", "skeleton key", "rope"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := gs.Summary(tt.previousInventory)
+			if summary.Location != "cellar" || summary.SceneName != "act1" {
+				t.Errorf("Expected location cellar and scene act1, got %q and %q", summary.Location, summary.SceneName)
+			}
+			if summary.TurnCounter != 5 || summary.SceneTurnCounter != 2 {
+				t.Errorf("Expected turn counters 5/2, got %d/%d", summary.TurnCounter, summary.SceneTurnCounter)
+			}
+			if !stringSlicesEqual(summary.NewItems, tt.expectedNewItems) {
+				t.Errorf("Expected new items %v, got %v", tt.expectedNewItems, summary.NewItems)
+			}
+		})
+	}
+}
+
 func TestGameState_GetContingencyPrompts_WithNPCs(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -757,3 +804,34 @@ func TestDeltaWorker_StoryEventFiringPrevention(t *testing.T) {
 		})
 	}
 }
+
+func TestGameState_PauseResume(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+
+	gs.Pause("")
+	if !gs.Paused || gs.PausedAt == nil {
+		t.Fatalf("Expected game to be paused with a timestamp, got paused=%v at=%v", gs.Paused, gs.PausedAt)
+	}
+	pausedAt := *gs.PausedAt
+	if msg := gs.PausedMessage(); msg != "This game is paused. Please try again once it resumes." {
+		t.Errorf("Unexpected paused message: %q", msg)
+	}
+
+	// Pausing again keeps the original timestamp and updates the reason
+	gs.Pause("maintenance")
+	if !gs.PausedAt.Equal(pausedAt) {
+		t.Errorf("Expected paused_at to stay %v, got %v", pausedAt, gs.PausedAt)
+	}
+	if !strings.Contains(gs.PausedMessage(), "maintenance") {
+		t.Errorf("Expected the reason in the paused message, got %q", gs.PausedMessage())
+	}
+
+	gs.HeldStoryEvents = []string{"first", "second"}
+	held := gs.Resume()
+	if !stringSlicesEqual(held, []string{"first", "second"}) {
+		t.Errorf("Expected held events in order, got %v", held)
+	}
+	if gs.Paused || gs.PausedAt != nil || gs.PauseReason != "" || gs.HeldStoryEvents != nil {
+		t.Errorf("Expected pause state to be cleared, got %+v", gs)
+	}
+}
@@ -0,0 +1,14 @@
+package state
+
+// HintsGiven returns the hints given to the player in the current scene, oldest first
+func (gs *GameState) HintsGiven() []string {
+	return gs.Hints[gs.SceneName]
+}
+
+// RecordHint records a hint given to the player in the current scene
+func (gs *GameState) RecordHint(hint string) {
+	if gs.Hints == nil {
+		gs.Hints = make(map[string][]string)
+	}
+	gs.Hints[gs.SceneName] = append(gs.Hints[gs.SceneName], hint)
+}
@@ -0,0 +1,46 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MaxAppliedDeltas is the number of most recent delta keys kept on a game state. A retry
+// or redelivery comes soon after the first attempt, so a short memory is enough.
+const MaxAppliedDeltas = 32
+
+// ErrDeltaAlreadyApplied is returned by DeltaWorker.Apply when the game has already
+// applied the delta with the worker's idempotency key
+var ErrDeltaAlreadyApplied = errors.New("delta already applied")
+
+// DeltaKey returns the idempotency key for a turn's delta: the ID of the request that
+// played the turn, which stays the same when the request is retried or redelivered.
+// Without a request ID, player turns fall back to the turn counter they started from;
+// other turns don't advance it, so they get no key.
+func DeltaKey(requestID string, turn int, kind TurnKind) string {
+	switch {
+	case requestID != "":
+		return "request:" + requestID
+	case kind == TurnPlayer:
+		return fmt.Sprintf("turn:%d", turn)
+	default:
+		return ""
+	}
+}
+
+// HasAppliedDelta reports whether the delta with this key has been applied
+func (gs *GameState) HasAppliedDelta(key string) bool {
+	return key != "" && slices.Contains(gs.AppliedDeltas, key)
+}
+
+// recordAppliedDelta remembers a delta's key, keeping only the most recent MaxAppliedDeltas
+func (gs *GameState) recordAppliedDelta(key string) {
+	if key == "" || gs.HasAppliedDelta(key) {
+		return
+	}
+	gs.AppliedDeltas = append(gs.AppliedDeltas, key)
+	if len(gs.AppliedDeltas) > MaxAppliedDeltas {
+		gs.AppliedDeltas = gs.AppliedDeltas[len(gs.AppliedDeltas)-MaxAppliedDeltas:]
+	}
+}
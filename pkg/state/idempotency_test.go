@@ -0,0 +1,76 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
+
+func TestDeltaKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		turn      int
+		kind      TurnKind
+		want      string
+	}{
+		{"request ID", "abc", 4, TurnPlayer, "request:abc"},
+		{"request ID for a story event", "abc", 4, TurnSystem, "request:abc"},
+		{"player turn without request", "", 4, TurnPlayer, "turn:4"},
+		{"free action without request", "", 4, TurnFree, ""},
+		{"story event without request", "", 4, TurnSystem, ""},
+	}
+	for _, tt := range tests {
+		if got := DeltaKey(tt.requestID, tt.turn, tt.kind); got != tt.want {
+			t.Errorf("%s: DeltaKey() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGameState_RecordAppliedDelta(t *testing.T) {
+	gs := &GameState{}
+	for i := range MaxAppliedDeltas + 5 {
+		gs.recordAppliedDelta(fmt.Sprintf("turn:%d", i))
+	}
+	gs.recordAppliedDelta(fmt.Sprintf("turn:%d", MaxAppliedDeltas+4)) // already recorded
+
+	if len(gs.AppliedDeltas) != MaxAppliedDeltas {
+		t.Fatalf("Expected %d keys, got %d", MaxAppliedDeltas, len(gs.AppliedDeltas))
+	}
+	if gs.HasAppliedDelta("turn:0") || !gs.HasAppliedDelta("turn:5") {
+		t.Errorf("Expected the oldest keys to be dropped, got %v", gs.AppliedDeltas)
+	}
+	if gs.HasAppliedDelta("") {
+		t.Error("Expected an empty key never to count as applied")
+	}
+}
+
+func TestDeltaWorker_IdempotencyKey(t *testing.T) {
+	gs := &GameState{Vars: make(map[string]string)}
+	delta := &conditionals.GameStateDelta{ItemEvents: []itemEvent{{Item: "lantern", Action: "acquire"}}}
+
+	first := NewDeltaWorker(gs, delta, nil, nil).WithIdempotencyKey("request:abc")
+	if first.AlreadyApplied() {
+		t.Fatal("Expected a new key not to be applied")
+	}
+	if err := first.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	// Conditionals apply through the same worker again
+	if err := first.Apply(); err != nil {
+		t.Fatalf("Expected the same worker to apply again, got %v", err)
+	}
+
+	retry := NewDeltaWorker(gs, delta, nil, nil).WithIdempotencyKey("request:abc")
+	if !retry.AlreadyApplied() {
+		t.Error("Expected the retried delta to report it was applied")
+	}
+	if err := retry.Apply(); !errors.Is(err, ErrDeltaAlreadyApplied) {
+		t.Errorf("Expected ErrDeltaAlreadyApplied, got %v", err)
+	}
+	if len(gs.Inventory) != 1 {
+		t.Errorf("Expected the item acquired once, got %v", gs.Inventory)
+	}
+}
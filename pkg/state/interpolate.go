@@ -0,0 +1,57 @@
+package state
+
+import (
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern matches {{namespace.name}} placeholders in authored text
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z]+)\.([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// Interpolate resolves placeholders in authored text from the game state:
+//   - {{vars.x}} is the value of the variable x, or empty if it isn't set
+//   - {{pc.name}} is the player character's name
+//   - {{location.name}} is the name of the player's current location
+//
+// Unrecognized placeholders are left as they are.
+func (gs *GameState) Interpolate(text string) string {
+	if gs == nil || !strings.Contains(text, "{{") {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := placeholderPattern.FindStringSubmatch(match)
+		if value, ok := gs.placeholderValue(m[1], m[2]); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// UnknownPlaceholders returns the placeholders in text that Interpolate doesn't recognize
+func UnknownPlaceholders(text string) []string {
+	var unknown []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if _, ok := (&GameState{}).placeholderValue(m[1], m[2]); !ok {
+			unknown = append(unknown, m[0])
+		}
+	}
+	return unknown
+}
+
+func (gs *GameState) placeholderValue(namespace, name string) (string, bool) {
+	switch {
+	case namespace == "vars":
+		return gs.Vars[name], true
+	case namespace == "pc" && name == "name":
+		if gs.PC == nil || gs.PC.Spec == nil {
+			return "", true
+		}
+		return gs.PC.Spec.Name, true
+	case namespace == "location" && name == "name":
+		if loc, ok := gs.WorldLocations[gs.Location]; ok && loc.Name != "" {
+			return loc.Name, true
+		}
+		return gs.Location, true
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestGameState_Interpolate(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	gs.Vars = map[string]string{"gold": "12", "ship_name": "Black Pearl"}
+	gs.PC = &actor.PC{Spec: &actor.PCSpec{ID: "captain", Name: "Jack Sparrow"}}
+	gs.Location = "tortuga_docks"
+	gs.WorldLocations = map[string]scenario.Location{
+		"tortuga_docks": {Name: "Tortuga Docks"},
+		"hold":          {},
+	}
+
+	tests := []struct {
+		name     string
+		location string
+		text     string
+		want     string
+	}{
+		{name: "no placeholders", text: "The tide is turning.", want: "The tide is turning."},
+		{name: "var", text: "The purse holds {{vars.gold}} coins.", want: "The purse holds 12 coins."},
+		{name: "unset var", text: "Rum left: {{vars.rum}}.", want: "Rum left: ."},
+		{name: "pc and location", text: "{{pc.name}} stands on {{location.name}}.", want: "Jack Sparrow stands on Tortuga Docks."},
+		{name: "spaces inside braces", text: "The {{ vars.ship_name }} waits.", want: "The Black Pearl waits."},
+		{name: "unnamed location", location: "hold", text: "Below, in {{location.name}}.", want: "Below, in hold."},
+		{name: "unknown placeholder kept", text: "{{pc.age}} and {{npc.name}}", want: "{{pc.age}} and {{npc.name}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs.Location = "tortuga_docks"
+			if tt.location != "" {
+				gs.Location = tt.location
+			}
+			if got := gs.Interpolate(tt.text); got != tt.want {
+				t.Errorf("Interpolate(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGameState_InterpolateWithoutPC(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	if got := gs.Interpolate("Welcome, {{pc.name}}."); got != "Welcome, ." {
+		t.Errorf("Expected an empty PC name without a PC, got %q", got)
+	}
+}
+
+func TestUnknownPlaceholders(t *testing.T) {
+	got := UnknownPlaceholders("{{vars.gold}} {{pc.name}} {{pc.class}} {{location.name}} {{weather.today}}")
+	want := []string{"{{pc.class}}", "{{weather.today}}"}
+	if !slices.Equal(got, want) {
+		t.Errorf("UnknownPlaceholders() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package state
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+// ItemName returns an item's display name. Items the scenario doesn't catalog are
+// their own name.
+func (gs *GameState) ItemName(id string) string {
+	if name := gs.ItemNames[id]; name != "" {
+		return name
+	}
+	return id
+}
+
+// DisplayItems returns the display names of a list of items, in a new slice
+func (gs *GameState) DisplayItems(ids []string) []string {
+	if ids == nil {
+		return nil
+	}
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = gs.ItemName(id)
+	}
+	return names
+}
+
+// ItemID resolves a reference to a catalogued item, by ID or by display name in
+// canonical form (see scenario.NormalizeKey), to its ID. The narrator and players name
+// items by display name, so anything that changes the game's items resolves them
+// first. Items that aren't catalogued are returned as written.
+func (gs *GameState) ItemID(ref string) string {
+	if len(gs.ItemNames) == 0 {
+		return ref
+	}
+	if _, ok := gs.ItemNames[ref]; ok {
+		return ref
+	}
+	canonical := scenario.NormalizeKey(ref)
+	if _, ok := gs.ItemNames[canonical]; ok && canonical != "" {
+		return canonical
+	}
+	for _, id := range slices.Sorted(maps.Keys(gs.ItemNames)) {
+		if canonical != "" && scenario.NormalizeKey(gs.ItemNames[id]) == canonical {
+			return id
+		}
+	}
+	return ref
+}
+
+// ItemIDs resolves each item in a list to its ID, in a new slice
+func (gs *GameState) ItemIDs(refs []string) []string {
+	if refs == nil {
+		return nil
+	}
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = gs.ItemID(ref)
+	}
+	return ids
+}
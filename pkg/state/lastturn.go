@@ -0,0 +1,64 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrNoLastTurn is returned by RewindLastTurn when there is no player turn to rewind
+var ErrNoLastTurn = errors.New("no player turn to regenerate")
+
+// LastTurn is the game state from before the latest player turn, kept so the turn can be
+// narrated again. A story event after the turn clears it, as the turn is no longer last.
+type LastTurn struct {
+	Message    string     `json:"message"`               // Player's message, as sent to the narrator
+	FreeAction bool       `json:"free_action,omitempty"` // The turn didn't advance the turn counters
+	HistoryLen int        `json:"history_len"`           // Messages in the chat history before the turn
+	State      *GameState `json:"state"`                 // Game state before the turn, without its chat history
+}
+
+// RememberTurn keeps the game state from before a turn so that it can be regenerated.
+// Call it before the turn's messages are added. Story events forget the last turn instead.
+func (gs *GameState) RememberTurn(message string, kind TurnKind) error {
+	if kind == TurnSystem {
+		gs.LastTurn = nil
+		return nil
+	}
+
+	// The chat history and any earlier snapshot are left out, so snapshots don't nest
+	history, last := gs.ChatHistory, gs.LastTurn
+	gs.ChatHistory, gs.LastTurn = nil, nil
+	before, err := gs.DeepCopy()
+	gs.ChatHistory, gs.LastTurn = history, last
+	if err != nil {
+		return fmt.Errorf("failed to snapshot game state: %w", err)
+	}
+
+	gs.LastTurn = &LastTurn{
+		Message:    message,
+		FreeAction: kind == TurnFree,
+		HistoryLen: len(history),
+		State:      before,
+	}
+	return nil
+}
+
+// RewindLastTurn restores the game state from before the latest player turn, dropping
+// the turn's messages and everything its delta changed, and returns the turn so it can
+// be played again. Returns ErrNoLastTurn if there is none.
+func (gs *GameState) RewindLastTurn() (*LastTurn, error) {
+	turn := gs.LastTurn
+	if turn == nil || turn.State == nil || len(gs.ChatHistory) < turn.HistoryLen {
+		return nil, ErrNoLastTurn
+	}
+
+	restored, err := turn.State.DeepCopy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore game state: %w", err)
+	}
+	restored.ChatHistory = slices.Clone(gs.ChatHistory[:turn.HistoryLen])
+	restored.UpdatedAt = gs.UpdatedAt
+	*gs = *restored
+	return turn, nil
+}
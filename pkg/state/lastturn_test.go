@@ -0,0 +1,76 @@
+package state
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/chat"
+)
+
+func TestGameState_RewindLastTurn(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	gs.Location = "docks"
+	gs.Inventory = []string{"rope"}
+	gs.ChatHistory = []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "You stand on the docks."}}
+
+	if _, err := gs.RewindLastTurn(); !errors.Is(err, ErrNoLastTurn) {
+		t.Fatalf("Expected ErrNoLastTurn before any turn, got %v", err)
+	}
+
+	// Play a turn: the narration is added, then the delta moves the player and takes the rope
+	if err := gs.RememberTurn("Jack: I sail to the cove", TurnFree); err != nil {
+		t.Fatalf("RememberTurn() error: %v", err)
+	}
+	gs.ChatHistory = append(gs.ChatHistory,
+		chat.ChatMessage{Role: chat.ChatRoleUser, Content: "Jack: I sail to the cove"},
+		chat.ChatMessage{Role: chat.ChatRoleAgent, Content: "A dud narration."})
+	gs.Location = "cove"
+	gs.Inventory = nil
+	gs.TurnCounter++
+
+	turn, err := gs.RewindLastTurn()
+	if err != nil {
+		t.Fatalf("RewindLastTurn() error: %v", err)
+	}
+	if turn.Message != "Jack: I sail to the cove" || !turn.FreeAction {
+		t.Errorf("Expected the rewound turn's message and kind, got %+v", turn)
+	}
+	if gs.Location != "docks" || !slices.Equal(gs.Inventory, []string{"rope"}) || gs.TurnCounter != 0 {
+		t.Errorf("Expected the state from before the turn, got location %q, inventory %v, turn %d", gs.Location, gs.Inventory, gs.TurnCounter)
+	}
+	if len(gs.ChatHistory) != 1 {
+		t.Errorf("Expected the turn's messages dropped, got %v", gs.ChatHistory)
+	}
+	if gs.LastTurn != nil {
+		t.Errorf("Expected no snapshot after rewinding, got %+v", gs.LastTurn)
+	}
+}
+
+func TestGameState_RememberTurn(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	gs.ChatHistory = []chat.ChatMessage{{Role: chat.ChatRoleAgent, Content: "Welcome."}}
+
+	if err := gs.RememberTurn("Look around", TurnPlayer); err != nil {
+		t.Fatalf("RememberTurn() error: %v", err)
+	}
+	if err := gs.RememberTurn("Look again", TurnPlayer); err != nil {
+		t.Fatalf("RememberTurn() error: %v", err)
+	}
+	if gs.LastTurn == nil || gs.LastTurn.Message != "Look again" || gs.LastTurn.HistoryLen != 1 {
+		t.Fatalf("Expected the latest turn remembered, got %+v", gs.LastTurn)
+	}
+	if gs.LastTurn.State.LastTurn != nil || gs.LastTurn.State.ChatHistory != nil {
+		t.Error("Expected the snapshot without an earlier snapshot or the chat history")
+	}
+	if len(gs.ChatHistory) != 1 {
+		t.Errorf("Expected the chat history kept, got %v", gs.ChatHistory)
+	}
+
+	if err := gs.RememberTurn("A storm rolls in", TurnSystem); err != nil {
+		t.Fatalf("RememberTurn() error: %v", err)
+	}
+	if gs.LastTurn != nil {
+		t.Error("Expected a story event to forget the last turn")
+	}
+}
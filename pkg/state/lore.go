@@ -0,0 +1,22 @@
+package state
+
+import "slices"
+
+// DiscoverLore adds lore entries to those the player has come across and returns the
+// IDs that were new
+func (gs *GameState) DiscoverLore(ids ...string) []string {
+	var found []string
+	for _, id := range ids {
+		if id == "" || slices.Contains(gs.DiscoveredLore, id) {
+			continue
+		}
+		gs.DiscoveredLore = append(gs.DiscoveredLore, id)
+		found = append(found, id)
+	}
+	return found
+}
+
+// KnowsLore reports whether the player has discovered the lore entry
+func (gs *GameState) KnowsLore(id string) bool {
+	return slices.Contains(gs.DiscoveredLore, id)
+}
@@ -0,0 +1,146 @@
+package state
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/jwebster45206/story-engine/pkg/combat"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+// MigrateIDs rewrites the game's references to scenes, locations, NPCs, and items as
+// the IDs that the scenario is normalized to on load (see scenario.Scenario.Normalize),
+// so games saved with display-cased keys such as "Tortuga", with keys an entry's id
+// field has since replaced, or with the names of items now in the scenario's item
+// catalog, still match their scenario. Anything the scenario has renamed (see
+// scenario.Renames) is followed too. That covers the player's scene, location and
+// inventory, location keys, exits and items, NPC keys, locations, followers and items,
+// monster locations and items, combatants, conversations, NPC memories, and hints and
+// conditional firings by scene. It also refreshes the catalogued items' display names.
+// It's safe to call on every load, and reports whether anything changed.
+//
+// When a migrated entry's new key is already in the game, the game's copy under the old
+// key wins, since it carries the player's progress.
+func (gs *GameState) MigrateIDs(s *scenario.Scenario) bool {
+	if gs == nil {
+		return false
+	}
+	if s == nil {
+		s = &scenario.Scenario{}
+	}
+	changed := false
+	set := func(p *string, v string) {
+		if *p != v {
+			*p = v
+			changed = true
+		}
+	}
+	items := func(list []string) {
+		for i, item := range list {
+			set(&list[i], s.ItemID(item))
+		}
+	}
+	locationKey := func(key string) string {
+		if key == "" {
+			return ""
+		}
+		if id, ok := s.LocationID(key); ok {
+			return id
+		}
+		return s.Renamed.Location(scenario.NormalizeKey(key))
+	}
+	npcKey := func(key string) string {
+		if key == "" || key == "pc" {
+			return key
+		}
+		if id, ok := s.NPCID(key); ok {
+			return id
+		}
+		return s.Renamed.NPC(scenario.NormalizeKey(key))
+	}
+	sceneKey := func(key string) string {
+		if key == "" {
+			return ""
+		}
+		return scenario.NormalizeKey(key)
+	}
+
+	if names := s.ItemNames(); !maps.Equal(gs.ItemNames, names) {
+		gs.ItemNames = names
+		changed = true
+	}
+	set(&gs.SceneName, sceneKey(gs.SceneName))
+	gs.Hints = rekey(gs.Hints, sceneKey, &changed)
+	for id, firing := range gs.FiredConditionals {
+		if to := sceneKey(firing.Scene); to != firing.Scene {
+			firing.Scene = to
+			gs.FiredConditionals[id] = firing
+			changed = true
+		}
+	}
+
+	set(&gs.Location, locationKey(gs.Location))
+	items(gs.Inventory)
+
+	gs.WorldLocations = rekey(gs.WorldLocations, locationKey, &changed)
+	for key, loc := range gs.WorldLocations {
+		set(&loc.ID, locationKey(key))
+		for dir, target := range loc.Exits {
+			if to := locationKey(target); to != target {
+				loc.Exits[dir] = to
+				changed = true
+			}
+		}
+		items(loc.Items)
+		for _, m := range loc.Monsters {
+			if m == nil {
+				continue
+			}
+			set(&m.Location, locationKey(m.Location))
+			items(m.Items)
+		}
+		gs.WorldLocations[key] = loc
+	}
+
+	gs.NPCs = rekey(gs.NPCs, npcKey, &changed)
+	for key, npc := range gs.NPCs {
+		set(&npc.ID, npcKey(key))
+		set(&npc.Location, locationKey(npc.Location))
+		set(&npc.Following, npcKey(npc.Following))
+		items(npc.Items)
+		gs.NPCs[key] = npc
+	}
+	gs.NPCMemories = rekey(gs.NPCMemories, npcKey, &changed)
+	gs.NPCInteractions = rekey(gs.NPCInteractions, npcKey, &changed)
+	if gs.Conversation != nil {
+		set(&gs.Conversation.NPCID, npcKey(gs.Conversation.NPCID))
+	}
+	if gs.Combat != nil {
+		for i, c := range gs.Combat.Combatants {
+			if c.Kind == combat.KindNPC {
+				set(&gs.Combat.Combatants[i].ID, npcKey(c.ID))
+			}
+		}
+	}
+	return changed
+}
+
+// rekey moves each entry of m to its renamed key. An entry already under its new key
+// gives way to one renamed into it.
+func rekey[V any](m map[string]V, rename func(string) string, changed *bool) map[string]V {
+	if m == nil {
+		return nil
+	}
+	var moved []string
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		if rename(key) != key {
+			moved = append(moved, key)
+		}
+	}
+	for _, key := range moved {
+		m[rename(key)] = m[key]
+		delete(m, key)
+		*changed = true
+	}
+	return m
+}
@@ -0,0 +1,202 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/chat"
+	"github.com/jwebster45206/story-engine/pkg/combat"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestGameState_MigrateIDs(t *testing.T) {
+	r := &scenario.Renames{
+		Locations: map[string]string{"dock": "harbor"},
+		NPCs:      map[string]string{"gibbs": "joshamee"},
+		Items:     map[string]string{"Rusty Key": "Iron Key"},
+	}
+	gs := &GameState{
+		Location:  "dock",
+		Inventory: []string{"Rusty Key", "Compass"},
+		WorldLocations: map[string]scenario.Location{
+			"dock": {Name: "Dock", Exits: map[string]string{"north": "market"}, Items: []string{"Rope"}},
+			"market": {
+				Name:     "Market",
+				Exits:    map[string]string{"south": "dock"},
+				Monsters: map[string]*actor.Monster{"rat_1": {ID: "rat_1", Location: "dock", Items: []string{"Rusty Key"}}},
+			},
+		},
+		NPCs: map[string]actor.NPC{
+			"gibbs":  {Name: "Gibbs", Location: "dock", Items: []string{"Rusty Key"}},
+			"parrot": {Name: "Parrot", Location: "market", Following: "gibbs"},
+		},
+		NPCMemories:     map[string][]chat.ChatMessage{"gibbs": {{Role: chat.ChatRoleUser, Content: "Ahoy"}}},
+		NPCInteractions: map[string]int{"gibbs": 3},
+		Conversation:    &Conversation{NPCID: "gibbs"},
+		Combat: &combat.Combat{Combatants: []combat.Combatant{
+			{ID: "pc", Kind: combat.KindPC},
+			{ID: "gibbs", Kind: combat.KindNPC},
+		}},
+	}
+
+	s := &scenario.Scenario{Renamed: r}
+	if !gs.MigrateIDs(s) {
+		t.Fatal("Expected MigrateIDs to report a change")
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"location", gs.Location, "harbor"},
+		{"inventory", gs.Inventory, []string{"Iron Key", "Compass"}},
+		{"location keys", len(gs.WorldLocations["harbor"].Exits), 1},
+		{"exits", gs.WorldLocations["market"].Exits["south"], "harbor"},
+		{"monster location", gs.WorldLocations["market"].Monsters["rat_1"].Location, "harbor"},
+		{"monster items", gs.WorldLocations["market"].Monsters["rat_1"].Items, []string{"Iron Key"}},
+		{"npc key", gs.NPCs["joshamee"].Name, "Gibbs"},
+		{"npc location", gs.NPCs["joshamee"].Location, "harbor"},
+		{"npc items", gs.NPCs["joshamee"].Items, []string{"Iron Key"}},
+		{"following", gs.NPCs["parrot"].Following, "joshamee"},
+		{"memories", len(gs.NPCMemories["joshamee"]), 1},
+		{"interactions", gs.NPCInteractions["joshamee"], 3},
+		{"conversation", gs.Conversation.NPCID, "joshamee"},
+		{"combatant", gs.Combat.Combatants[1].ID, "joshamee"},
+		{"pc combatant", gs.Combat.Combatants[0].ID, "pc"},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if _, ok := gs.WorldLocations["dock"]; ok {
+		t.Error("Expected the old location key to be gone")
+	}
+	if _, ok := gs.NPCs["gibbs"]; ok {
+		t.Error("Expected the old NPC key to be gone")
+	}
+
+	if gs.MigrateIDs(s) {
+		t.Error("Expected a second migration to change nothing")
+	}
+}
+
+func TestGameState_MigrateIDs_NoRenames(t *testing.T) {
+	gs := &GameState{Location: "dock"}
+	if gs.MigrateIDs(nil) || gs.MigrateIDs(&scenario.Scenario{Renamed: &scenario.Renames{}}) {
+		t.Error("Expected no change without renames")
+	}
+}
+
+func TestGameState_MigrateIDs_DisplayCasedSave(t *testing.T) {
+	// A custom scenario written with display-cased keys, normalized on load
+	s := &scenario.Scenario{
+		Locations: map[string]scenario.Location{
+			"Tortuga":         {Name: "Tortuga", Exits: map[string]string{"aboard": "Captain's Cabin"}},
+			"Captain's Cabin": {Name: "Captain's Cabin", Exits: map[string]string{"ashore": "Tortuga"}},
+		},
+		NPCs:   map[string]actor.NPC{"Gibbs": {Name: "Gibbs", Location: "Tortuga"}},
+		Scenes: map[string]scenario.Scene{"Shore Leave": {Story: "Drink up"}},
+	}
+	s.Normalize()
+
+	// A game saved before the scenario's keys were normalized
+	gs := &GameState{
+		SceneName: "Shore Leave",
+		Location:  "Tortuga",
+		WorldLocations: map[string]scenario.Location{
+			"Tortuga":         {Name: "Tortuga", Exits: map[string]string{"aboard": "Captain's Cabin"}},
+			"Captain's Cabin": {Name: "Captain's Cabin", Exits: map[string]string{"ashore": "Tortuga"}},
+		},
+		NPCs:              map[string]actor.NPC{"Gibbs": {Name: "Gibbs", Location: "Tortuga", Following: "pc"}},
+		NPCInteractions:   map[string]int{"Gibbs": 2},
+		Hints:             map[string][]string{"Shore Leave": {"Try the tavern"}},
+		FiredConditionals: map[string]ConditionalFiring{"rum": {Turn: 1, Scene: "Shore Leave", Count: 1}},
+	}
+
+	if !gs.MigrateIDs(s) {
+		t.Fatal("Expected MigrateIDs to report a change")
+	}
+	if _, ok := s.Scenes[gs.SceneName]; !ok {
+		t.Errorf("Scene %q is not in the scenario", gs.SceneName)
+	}
+	if _, ok := s.Locations[gs.Location]; !ok {
+		t.Errorf("Location %q is not in the scenario", gs.Location)
+	}
+	for key, loc := range gs.WorldLocations {
+		if _, ok := s.Locations[key]; !ok {
+			t.Errorf("Location key %q is not in the scenario", key)
+		}
+		for dir, target := range loc.Exits {
+			if _, ok := s.Locations[target]; !ok {
+				t.Errorf("Exit %s of %s leads to %q, which is not in the scenario", dir, key, target)
+			}
+		}
+	}
+	for key, npc := range gs.NPCs {
+		if _, ok := s.NPCs[key]; !ok {
+			t.Errorf("NPC key %q is not in the scenario", key)
+		}
+		if npc.Location != "tortuga" || npc.Following != "pc" {
+			t.Errorf("NPC %s: got location %q following %q", key, npc.Location, npc.Following)
+		}
+	}
+	if gs.NPCInteractions["gibbs"] != 2 {
+		t.Errorf("Expected interactions under the NPC's key, got %v", gs.NPCInteractions)
+	}
+	if len(gs.Hints["shore_leave"]) != 1 || gs.FiredConditionals["rum"].Scene != "shore_leave" {
+		t.Errorf("Expected hints and firings under the scene's key, got %v and %v", gs.Hints, gs.FiredConditionals)
+	}
+
+	if gs.MigrateIDs(s) {
+		t.Error("Expected a second migration to change nothing")
+	}
+}
+
+func TestGameState_MigrateIDs_BackfilledIDs(t *testing.T) {
+	// The scenario gained id fields and an item catalog after the game was saved
+	s := &scenario.Scenario{
+		Locations: map[string]scenario.Location{
+			"captains_cabin": {ID: "cabin", Name: "Captain's Quarters", Items: []string{"Rusty Key"}},
+		},
+		NPCs:        map[string]actor.NPC{"gibbs": {ID: "first_mate", Name: "Mr. Gibbs", Location: "captains_cabin"}},
+		ItemCatalog: map[string]scenario.Item{"rusty_key": {Name: "Rusty Key"}},
+		Scenes:      map[string]scenario.Scene{"shore_leave": {Story: "Drink up"}},
+	}
+	s.Normalize()
+
+	gs := &GameState{
+		Location:       "captains_cabin",
+		Inventory:      []string{"rusty key", "Compass"},
+		WorldLocations: map[string]scenario.Location{"captains_cabin": {Name: "Captain's Cabin"}},
+		NPCs:           map[string]actor.NPC{"gibbs": {Name: "Gibbs", Location: "captains_cabin"}},
+	}
+	if !gs.MigrateIDs(s) {
+		t.Fatal("Expected MigrateIDs to report a change")
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"location", gs.Location, "cabin"},
+		{"location id", gs.WorldLocations["cabin"].ID, "cabin"},
+		{"npc id", gs.NPCs["first_mate"].ID, "first_mate"},
+		{"npc location", gs.NPCs["first_mate"].Location, "cabin"},
+		{"inventory", gs.Inventory, []string{"rusty_key", "Compass"}},
+		{"item name", gs.ItemName("rusty_key"), "Rusty Key"},
+		{"uncatalogued item name", gs.ItemName("Compass"), "Compass"},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if gs.MigrateIDs(s) {
+		t.Error("Expected a second migration to change nothing")
+	}
+}
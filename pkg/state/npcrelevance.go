@@ -0,0 +1,105 @@
+package state
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+// Relevance weights for NPCRelevance
+const (
+	npcRelevanceInteraction = 50 // for dealing with the player this turn, less 5 for each turn since
+	npcRelevanceFollowing   = 40 // for following the player
+	npcRelevanceImportant   = 30 // for being marked important to the story
+	npcRelevanceQuest       = 10 // for carrying items or NPC-specific prompts, as quest NPCs do
+)
+
+// NoteNPCInteractions records this turn as the last interaction with each NPC at the
+// player's location that is named in any of texts, by full name or by the last word of
+// its name ("Hollis" for "Mr. Hollis"), and returns their IDs sorted.
+func (gs *GameState) NoteNPCInteractions(texts ...string) []string {
+	lowered := make([]string, len(texts))
+	for i, t := range texts {
+		lowered[i] = strings.ToLower(t)
+	}
+
+	var ids []string
+	for _, id := range slices.Sorted(maps.Keys(gs.NPCs)) {
+		npc := gs.NPCs[id]
+		if npc.Location != gs.Location || !mentionsNPC(npc, lowered) {
+			continue
+		}
+		gs.noteNPCInteraction(id)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// noteNPCInteraction records this turn as the last interaction with the NPC
+func (gs *GameState) noteNPCInteraction(id string) {
+	if gs.NPCInteractions == nil {
+		gs.NPCInteractions = make(map[string]int)
+	}
+	gs.NPCInteractions[id] = gs.TurnCounter
+}
+
+// NPCRelevance scores how much an NPC matters to the player right now, for deciding
+// which NPCs get full detail in the prompt when many share a location. Recent
+// interaction counts most, fading over ten turns, then following the player, being
+// important to the story, and carrying quest items or prompts.
+func (gs *GameState) NPCRelevance(id string) int {
+	npc, ok := gs.NPCs[id]
+	if !ok {
+		return 0
+	}
+	score := 0
+	if turn, ok := gs.NPCInteractions[id]; ok {
+		score += max(0, npcRelevanceInteraction-5*(gs.TurnCounter-turn))
+	}
+	if npc.Following == "pc" {
+		score += npcRelevanceFollowing
+	}
+	if npc.IsImportant {
+		score += npcRelevanceImportant
+	}
+	if len(npc.Items) > 0 || len(npc.ContingencyPrompts) > 0 {
+		score += npcRelevanceQuest
+	}
+	return score
+}
+
+// RankNPCs returns the NPC IDs ordered from most to least relevant, by ID among equals
+func (gs *GameState) RankNPCs(ids []string) []string {
+	ranked := slices.Clone(ids)
+	slices.SortFunc(ranked, func(a, b string) int {
+		if c := cmp.Compare(gs.NPCRelevance(b), gs.NPCRelevance(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return ranked
+}
+
+// mentionsNPC reports whether any of the lowercased texts names the NPC
+func mentionsNPC(npc actor.NPC, texts []string) bool {
+	name := strings.ToLower(strings.TrimSpace(npc.Name))
+	if name == "" {
+		return false
+	}
+	names := []string{name}
+	if words := strings.Fields(name); len(words) > 1 {
+		names = append(names, strings.Trim(words[len(words)-1], ".,"))
+	}
+	for _, t := range texts {
+		for _, n := range names {
+			if n != "" && scenario.ContainsWord(t, n) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package state
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+)
+
+func TestGameState_NoteNPCInteractions(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	gs.Location = "foyer"
+	gs.TurnCounter = 4
+	gs.NPCs = map[string]actor.NPC{
+		"butler":   {Name: "Mr. Hollis", Location: "foyer"},
+		"cook":     {Name: "Mrs. Pike", Location: "kitchen"},
+		"gardener": {Name: "Old Tom", Location: "foyer"},
+		"maid":     {Name: "Agnes", Location: "foyer"},
+	}
+
+	got := gs.NoteNPCInteractions("I ask Hollis about the cellar.", "Mrs. Pike shouts from the kitchen. OLD TOM grunts.")
+	if want := []string{"butler", "gardener"}; !slices.Equal(got, want) {
+		t.Errorf("NoteNPCInteractions() = %v, want %v", got, want)
+	}
+	if want := map[string]int{"butler": 4, "gardener": 4}; !maps.Equal(gs.NPCInteractions, want) {
+		t.Errorf("NPCInteractions = %v, want %v", gs.NPCInteractions, want)
+	}
+
+	gs.TurnCounter = 6
+	if got := gs.NoteNPCInteractions("Agnestine is not a name here."); got != nil {
+		t.Errorf("Expected only whole-word matches, got %v", got)
+	}
+}
+
+func TestGameState_NPCRelevance(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	gs.TurnCounter = 10
+	gs.NPCs = map[string]actor.NPC{
+		"talked_now":  {Name: "A"},
+		"talked_long": {Name: "B"},
+		"follower":    {Name: "C", Following: "pc"},
+		"important":   {Name: "D", IsImportant: true},
+		"merchant":    {Name: "E", Items: []string{"rope"}},
+		"extra":       {Name: "F"},
+	}
+	gs.NPCInteractions = map[string]int{"talked_now": 10, "talked_long": 0}
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"talked_now", 50},
+		{"talked_long", 0},
+		{"follower", 40},
+		{"important", 30},
+		{"merchant", 10},
+		{"extra", 0},
+		{"missing", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := gs.NPCRelevance(tt.id); got != tt.want {
+				t.Errorf("NPCRelevance(%q) = %d, want %d", tt.id, got, tt.want)
+			}
+		})
+	}
+
+	ranked := gs.RankNPCs(slices.Sorted(maps.Keys(gs.NPCs)))
+	want := []string{"talked_now", "follower", "important", "merchant", "extra", "talked_long"}
+	if !slices.Equal(ranked, want) {
+		t.Errorf("RankNPCs() = %v, want %v", ranked, want)
+	}
+}
@@ -0,0 +1,24 @@
+package state
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+)
+
+// maxSeed keeps generated seeds within the range JSON clients can represent exactly
+const maxSeed = 1 << 53
+
+// NewSeed returns a random seed for a new game
+func NewSeed() int64 {
+	return rand.Int64N(maxSeed)
+}
+
+// Rand returns a random number generator for the current turn. The sequence depends only on
+// the game's seed, its turn counter, and the stream name, so a game replayed from the same
+// seed sees the same rolls. Use a distinct stream name for each kind of randomness
+// (e.g. "dice", "weather") so that adding rolls of one kind doesn't shift another.
+func (gs *GameState) Rand(stream string) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(stream))
+	return rand.New(rand.NewPCG(uint64(gs.Seed), h.Sum64()^uint64(gs.TurnCounter)))
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func rolls(gs *GameState, stream string) []int {
+	r := gs.Rand(stream)
+	out := make([]int, 5)
+	for i := range out {
+		out[i] = r.IntN(1000)
+	}
+	return out
+}
+
+func equalRolls(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGameState_Rand(t *testing.T) {
+	base := &GameState{Seed: 42, TurnCounter: 3}
+
+	tests := []struct {
+		name   string
+		other  *GameState
+		stream string
+		same   bool
+	}{
+		{
+			name:   "same seed, turn, and stream repeat",
+			other:  &GameState{Seed: 42, TurnCounter: 3},
+			stream: "dice",
+			same:   true,
+		},
+		{
+			name:   "different seed",
+			other:  &GameState{Seed: 43, TurnCounter: 3},
+			stream: "dice",
+		},
+		{
+			name:   "different turn",
+			other:  &GameState{Seed: 42, TurnCounter: 4},
+			stream: "dice",
+		},
+		{
+			name:   "different stream",
+			other:  &GameState{Seed: 42, TurnCounter: 3},
+			stream: "weather",
+		},
+	}
+
+	want := rolls(base, "dice")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rolls(tt.other, tt.stream)
+			if equalRolls(want, got) != tt.same {
+				t.Errorf("rolls %v vs %v: expected same=%v", want, got, tt.same)
+			}
+		})
+	}
+}
+
+func TestGameState_SeedSurvivesRoundTrip(t *testing.T) {
+	gs := NewGameState("test.json", nil, "model")
+	if gs.Seed < 0 || gs.Seed >= maxSeed {
+		t.Fatalf("generated seed %d out of range", gs.Seed)
+	}
+
+	data, err := json.Marshal(gs)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var loaded GameState
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if loaded.Seed != gs.Seed {
+		t.Errorf("expected seed %d, got %d", gs.Seed, loaded.Seed)
+	}
+	if !equalRolls(rolls(gs, "dice"), rolls(&loaded, "dice")) {
+		t.Error("expected the same rolls after a round trip")
+	}
+}
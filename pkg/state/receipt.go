@@ -0,0 +1,217 @@
+package state
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// MaxTurnReceipts is the number of most recent turn receipts kept on a game state
+const MaxTurnReceipts = 50
+
+// TurnReceipt is a compact, machine-readable summary of what changed
+// when the background delta for a turn was applied.
+type TurnReceipt struct {
+	Turn              int               `json:"turn"`                         // Turn counter after the delta was applied
+	ItemsGained       []string          `json:"items_gained,omitempty"`       // Items added to the user's inventory
+	ItemsLost         []string          `json:"items_lost,omitempty"`         // Items removed from the user's inventory
+	VarsChanged       map[string]string `json:"vars_changed,omitempty"`       // Vars that were set or changed, with new values
+	NPCsMoved         map[string]string `json:"npcs_moved,omitempty"`         // NPC ID to new location
+	LocationChanged   string            `json:"location_changed,omitempty"`   // New user location, if it changed
+	SceneChanged      string            `json:"scene_changed,omitempty"`      // New scene, if it changed
+	ConditionalsFired []string          `json:"conditionals_fired,omitempty"` // IDs of scene conditionals that triggered
+	LoreDiscovered    []string          `json:"lore_discovered,omitempty"`    // Lore entry IDs added to the player's codex
+	GameEnded         bool              `json:"game_ended,omitempty"`         // True if this turn ended the game
+	SkillCheck        *CheckResult      `json:"skill_check,omitempty"`        // Skill check rolled for the next narration
+	DeltaIssues       []DeltaIssue      `json:"delta_issues,omitempty"`       // Parts of the model's delta that were repaired or dropped
+	NarrationIssues   []NarrationIssue  `json:"narration_issues,omitempty"`   // Places the narration contradicted the game state, if checked
+	Reducer           string            `json:"reducer,omitempty"`            // Version of the reducer prompt that produced the delta
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+// NewTurnReceipt compares game state before and after a delta and records the differences
+func NewTurnReceipt(before, after *GameState, conditionalsFired []string) *TurnReceipt {
+	r := &TurnReceipt{
+		Turn:      after.TurnCounter,
+		CreatedAt: time.Now(),
+	}
+
+	for _, item := range after.Inventory {
+		if !slices.Contains(before.Inventory, item) {
+			r.ItemsGained = append(r.ItemsGained, item)
+		}
+	}
+	for _, item := range before.Inventory {
+		if !slices.Contains(after.Inventory, item) {
+			r.ItemsLost = append(r.ItemsLost, item)
+		}
+	}
+
+	for k, v := range after.Vars {
+		if old, ok := before.Vars[k]; !ok || old != v {
+			if r.VarsChanged == nil {
+				r.VarsChanged = make(map[string]string)
+			}
+			r.VarsChanged[k] = v
+		}
+	}
+
+	for id, npc := range after.NPCs {
+		if prev, ok := before.NPCs[id]; ok && prev.Location != npc.Location {
+			if r.NPCsMoved == nil {
+				r.NPCsMoved = make(map[string]string)
+			}
+			r.NPCsMoved[id] = npc.Location
+		}
+	}
+
+	if after.Location != before.Location {
+		r.LocationChanged = after.Location
+	}
+	if after.SceneName != before.SceneName {
+		r.SceneChanged = after.SceneName
+	}
+	if after.IsEnded && !before.IsEnded {
+		r.GameEnded = true
+	}
+
+	if after.PendingCheck != nil && (before.PendingCheck == nil || *before.PendingCheck != *after.PendingCheck) {
+		check := *after.PendingCheck
+		r.SkillCheck = &check
+	}
+
+	for _, id := range after.DiscoveredLore {
+		if !slices.Contains(before.DiscoveredLore, id) {
+			r.LoreDiscovered = append(r.LoreDiscovered, id)
+		}
+	}
+
+	if len(conditionalsFired) > 0 {
+		r.ConditionalsFired = slices.Clone(conditionalsFired)
+		slices.Sort(r.ConditionalsFired)
+	}
+
+	return r
+}
+
+// IsEmpty returns true if the receipt records no changes
+func (r *TurnReceipt) IsEmpty() bool {
+	return len(r.ItemsGained) == 0 &&
+		len(r.ItemsLost) == 0 &&
+		len(r.VarsChanged) == 0 &&
+		len(r.NPCsMoved) == 0 &&
+		r.LocationChanged == "" &&
+		r.SceneChanged == "" &&
+		len(r.ConditionalsFired) == 0 &&
+		len(r.LoreDiscovered) == 0 &&
+		!r.GameEnded &&
+		r.SkillCheck == nil &&
+		len(r.DeltaIssues) == 0 &&
+		len(r.NarrationIssues) == 0
+}
+
+// AddTurnReceipt records a receipt, replacing any existing receipt for the same turn
+// and keeping only the most recent MaxTurnReceipts
+func (gs *GameState) AddTurnReceipt(r *TurnReceipt) {
+	if r == nil {
+		return
+	}
+	gs.TurnReceipts = slices.DeleteFunc(gs.TurnReceipts, func(existing TurnReceipt) bool {
+		return existing.Turn == r.Turn
+	})
+	gs.TurnReceipts = append(gs.TurnReceipts, *r)
+	if len(gs.TurnReceipts) > MaxTurnReceipts {
+		gs.TurnReceipts = gs.TurnReceipts[len(gs.TurnReceipts)-MaxTurnReceipts:]
+	}
+}
+
+// MergeTurnReceipt folds a receipt into the one already recorded for its turn, if any.
+// Used for free and system turns, which don't advance the turn counter.
+func (gs *GameState) MergeTurnReceipt(r *TurnReceipt) {
+	if r == nil {
+		return
+	}
+	if existing, ok := gs.GetTurnReceipt(r.Turn); ok {
+		r = existing.merge(r)
+	}
+	gs.AddTurnReceipt(r)
+}
+
+// merge combines this receipt with a later one for the same turn. Items gained and then
+// lost (or lost and then regained) within the turn cancel out.
+func (r *TurnReceipt) merge(next *TurnReceipt) *TurnReceipt {
+	m := &TurnReceipt{
+		Turn:            next.Turn,
+		ItemsGained:     netItems(r.ItemsGained, next.ItemsGained, r.ItemsLost, next.ItemsLost),
+		ItemsLost:       netItems(r.ItemsLost, next.ItemsLost, r.ItemsGained, next.ItemsGained),
+		LocationChanged: r.LocationChanged,
+		SceneChanged:    r.SceneChanged,
+		GameEnded:       r.GameEnded || next.GameEnded,
+		SkillCheck:      r.SkillCheck,
+		LoreDiscovered:  append(slices.Clone(r.LoreDiscovered), next.LoreDiscovered...),
+		DeltaIssues:     append(slices.Clone(r.DeltaIssues), next.DeltaIssues...),
+		NarrationIssues: append(slices.Clone(r.NarrationIssues), next.NarrationIssues...),
+		Reducer:         r.Reducer,
+		CreatedAt:       next.CreatedAt,
+	}
+	if next.Reducer != "" {
+		m.Reducer = next.Reducer
+	}
+	if next.LocationChanged != "" {
+		m.LocationChanged = next.LocationChanged
+	}
+	if next.SceneChanged != "" {
+		m.SceneChanged = next.SceneChanged
+	}
+	if next.SkillCheck != nil {
+		m.SkillCheck = next.SkillCheck
+	}
+	if len(r.VarsChanged)+len(next.VarsChanged) > 0 {
+		m.VarsChanged = maps.Clone(r.VarsChanged)
+		if m.VarsChanged == nil {
+			m.VarsChanged = make(map[string]string)
+		}
+		maps.Copy(m.VarsChanged, next.VarsChanged)
+	}
+	if len(r.NPCsMoved)+len(next.NPCsMoved) > 0 {
+		m.NPCsMoved = maps.Clone(r.NPCsMoved)
+		if m.NPCsMoved == nil {
+			m.NPCsMoved = make(map[string]string)
+		}
+		maps.Copy(m.NPCsMoved, next.NPCsMoved)
+	}
+	for _, id := range append(slices.Clone(r.ConditionalsFired), next.ConditionalsFired...) {
+		if !slices.Contains(m.ConditionalsFired, id) {
+			m.ConditionalsFired = append(m.ConditionalsFired, id)
+		}
+	}
+	slices.Sort(m.ConditionalsFired)
+	return m
+}
+
+// netItems returns the items in earlier that later didn't undo, followed by the items in later
+// that didn't undo earlier. The opposite lists hold the reverse changes of each receipt.
+func netItems(earlier, later, earlierOpposite, laterOpposite []string) []string {
+	var out []string
+	for _, item := range earlier {
+		if !slices.Contains(laterOpposite, item) {
+			out = append(out, item)
+		}
+	}
+	for _, item := range later {
+		if !slices.Contains(earlierOpposite, item) && !slices.Contains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// GetTurnReceipt returns the receipt for the given turn, if one was recorded
+func (gs *GameState) GetTurnReceipt(turn int) (*TurnReceipt, bool) {
+	for i := range gs.TurnReceipts {
+		if gs.TurnReceipts[i].Turn == turn {
+			return &gs.TurnReceipts[i], true
+		}
+	}
+	return nil, false
+}
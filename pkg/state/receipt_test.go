@@ -0,0 +1,202 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+)
+
+func TestNewTurnReceipt(t *testing.T) {
+	before := NewGameState("test.json", nil, "test-model")
+	before.Location = "hall"
+	before.SceneName = "act1"
+	before.Inventory = []string{"rope", "lantern"}
+	before.Vars = map[string]string{"door_open": "false", "visited": "true"}
+	before.NPCs = map[string]actor.NPC{
+		"guard": {Name: "Guard", Location: "hall"},
+		"cat":   {Name: "Cat", Location: "cellar"},
+	}
+	before.DiscoveredLore = []string{"old_war"}
+
+	after, err := before.DeepCopy()
+	if err != nil {
+		t.Fatalf("DeepCopy failed: %v", err)
+	}
+	after.TurnCounter = 3
+	after.Location = "cellar"
+	after.SceneName = "act2"
+	after.Inventory = []string{"lantern", "skeleton key"}
+	after.Vars["door_open"] = "true"
+	after.Vars["found_key"] = "true"
+	guard := after.NPCs["guard"]
+	guard.Location = "cellar"
+	after.NPCs["guard"] = guard
+	after.DiscoverLore("cellar_ghost")
+
+	r := NewTurnReceipt(before, after, []string{"open_door", "enter_cellar"})
+
+	if r.Turn != 3 {
+		t.Errorf("Expected turn 3, got %d", r.Turn)
+	}
+	if !stringSlicesEqual(r.ItemsGained, []string{"skeleton key"}) {
+		t.Errorf("Expected items gained [skeleton key], got %v", r.ItemsGained)
+	}
+	if !stringSlicesEqual(r.ItemsLost, []string{"rope"}) {
+		t.Errorf("Expected items lost [rope], got %v", r.ItemsLost)
+	}
+	if len(r.VarsChanged) != 2 || r.VarsChanged["door_open"] != "true" || r.VarsChanged["found_key"] != "true" {
+		t.Errorf("Expected door_open and found_key changed, got %v", r.VarsChanged)
+	}
+	if len(r.NPCsMoved) != 1 || r.NPCsMoved["guard"] != "cellar" {
+		t.Errorf("Expected guard moved to cellar, got %v", r.NPCsMoved)
+	}
+	if r.LocationChanged != "cellar" || r.SceneChanged != "act2" {
+		t.Errorf("Expected location cellar and scene act2, got %q and %q", r.LocationChanged, r.SceneChanged)
+	}
+	if !stringSlicesEqual(r.ConditionalsFired, []string{"enter_cellar", "open_door"}) {
+		t.Errorf("Expected sorted conditionals, got %v", r.ConditionalsFired)
+	}
+	if !stringSlicesEqual(r.LoreDiscovered, []string{"cellar_ghost"}) {
+		t.Errorf("Expected lore discovered [cellar_ghost], got %v", r.LoreDiscovered)
+	}
+	if r.IsEmpty() {
+		t.Error("Expected receipt to be non-empty")
+	}
+}
+
+func TestNewTurnReceipt_NoChanges(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	gs.Inventory = []string{"rope"}
+	r := NewTurnReceipt(gs, gs, nil)
+	if !r.IsEmpty() {
+		t.Errorf("Expected empty receipt, got %+v", r)
+	}
+}
+
+func TestNewTurnReceipt_SkillCheck(t *testing.T) {
+	check := &CheckResult{Attribute: "stealth", DC: 15, Roll: 12, Modifier: 3, Total: 15, Success: true, Turn: 2}
+	tests := []struct {
+		name   string
+		before *CheckResult
+		after  *CheckResult
+		want   bool
+	}{
+		{"no check", nil, nil, false},
+		{"new check", nil, check, true},
+		{"same check still pending", check, check, false},
+		{"another check", &CheckResult{Attribute: "stealth", DC: 15, Roll: 4, Turn: 1}, check, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := &GameState{PendingCheck: tt.before}
+			after := &GameState{PendingCheck: tt.after}
+			r := NewTurnReceipt(before, after, nil)
+			if got := r.SkillCheck != nil; got != tt.want {
+				t.Errorf("Expected skill check recorded %t, got %+v", tt.want, r.SkillCheck)
+			}
+			if r.IsEmpty() == tt.want {
+				t.Errorf("Expected IsEmpty %t", !tt.want)
+			}
+		})
+	}
+}
+
+func TestGameState_AddTurnReceipt(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+
+	for turn := 1; turn <= MaxTurnReceipts+5; turn++ {
+		gs.AddTurnReceipt(&TurnReceipt{Turn: turn})
+	}
+	if len(gs.TurnReceipts) != MaxTurnReceipts {
+		t.Fatalf("Expected %d receipts, got %d", MaxTurnReceipts, len(gs.TurnReceipts))
+	}
+	if gs.TurnReceipts[0].Turn != 6 {
+		t.Errorf("Expected oldest kept receipt to be turn 6, got %d", gs.TurnReceipts[0].Turn)
+	}
+
+	// Replacing a turn keeps a single receipt for it
+	gs.AddTurnReceipt(&TurnReceipt{Turn: 10, ItemsGained: []string{"key"}})
+	r, ok := gs.GetTurnReceipt(10)
+	if !ok {
+		t.Fatal("Expected receipt for turn 10")
+	}
+	if !stringSlicesEqual(r.ItemsGained, []string{"key"}) {
+		t.Errorf("Expected replaced receipt, got %+v", r)
+	}
+	if len(gs.TurnReceipts) != MaxTurnReceipts {
+		t.Errorf("Expected %d receipts after replace, got %d", MaxTurnReceipts, len(gs.TurnReceipts))
+	}
+
+	if _, ok := gs.GetTurnReceipt(1); ok {
+		t.Error("Expected turn 1 receipt to be trimmed")
+	}
+}
+
+func TestGameState_MergeTurnReceipt(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *TurnReceipt
+		next     *TurnReceipt
+		want     TurnReceipt
+	}{
+		{
+			name: "no existing receipt",
+			next: &TurnReceipt{Turn: 3, ItemsGained: []string{"key"}},
+			want: TurnReceipt{Turn: 3, ItemsGained: []string{"key"}},
+		},
+		{
+			name:     "combines changes",
+			existing: &TurnReceipt{Turn: 3, ItemsGained: []string{"key"}, VarsChanged: map[string]string{"a": "1"}, ConditionalsFired: []string{"z"}, LoreDiscovered: []string{"old_war"}},
+			next:     &TurnReceipt{Turn: 3, ItemsLost: []string{"rope"}, VarsChanged: map[string]string{"a": "2", "b": "1"}, LocationChanged: "hall", ConditionalsFired: []string{"y", "z"}, LoreDiscovered: []string{"cellar_ghost"}},
+			want: TurnReceipt{
+				Turn:              3,
+				ItemsGained:       []string{"key"},
+				ItemsLost:         []string{"rope"},
+				VarsChanged:       map[string]string{"a": "2", "b": "1"},
+				LocationChanged:   "hall",
+				ConditionalsFired: []string{"y", "z"},
+				LoreDiscovered:    []string{"old_war", "cellar_ghost"},
+			},
+		},
+		{
+			name:     "gained then lost cancels out",
+			existing: &TurnReceipt{Turn: 3, ItemsGained: []string{"key", "coin"}, SceneChanged: "docks"},
+			next:     &TurnReceipt{Turn: 3, ItemsLost: []string{"key"}, GameEnded: true},
+			want:     TurnReceipt{Turn: 3, ItemsGained: []string{"coin"}, SceneChanged: "docks", GameEnded: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := NewGameState("test.json", nil, "test-model")
+			gs.AddTurnReceipt(&TurnReceipt{Turn: 2, ItemsGained: []string{"map"}})
+			if tt.existing != nil {
+				gs.AddTurnReceipt(tt.existing)
+			}
+
+			gs.MergeTurnReceipt(tt.next)
+
+			got, ok := gs.GetTurnReceipt(tt.want.Turn)
+			if !ok {
+				t.Fatalf("Expected receipt for turn %d", tt.want.Turn)
+			}
+			if !stringSlicesEqual(got.ItemsGained, tt.want.ItemsGained) || !stringSlicesEqual(got.ItemsLost, tt.want.ItemsLost) {
+				t.Errorf("Expected items +%v -%v, got +%v -%v", tt.want.ItemsGained, tt.want.ItemsLost, got.ItemsGained, got.ItemsLost)
+			}
+			if fmt.Sprint(got.VarsChanged) != fmt.Sprint(tt.want.VarsChanged) {
+				t.Errorf("Expected vars %v, got %v", tt.want.VarsChanged, got.VarsChanged)
+			}
+			if !stringSlicesEqual(got.ConditionalsFired, tt.want.ConditionalsFired) {
+				t.Errorf("Expected conditionals %v, got %v", tt.want.ConditionalsFired, got.ConditionalsFired)
+			}
+			if got.LocationChanged != tt.want.LocationChanged || got.SceneChanged != tt.want.SceneChanged || got.GameEnded != tt.want.GameEnded {
+				t.Errorf("Expected location %q scene %q ended %v, got %q %q %v",
+					tt.want.LocationChanged, tt.want.SceneChanged, tt.want.GameEnded, got.LocationChanged, got.SceneChanged, got.GameEnded)
+			}
+			if prev, ok := gs.GetTurnReceipt(2); !ok || !stringSlicesEqual(prev.ItemsGained, []string{"map"}) {
+				t.Errorf("Expected the turn 2 receipt to be untouched, got %+v", prev)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+// SaveFileFormat is the version of the save file layout written by NewSaveFile
+const SaveFileFormat = 1
+
+// SaveFile is a portable save of one game, for moving it between servers. The narrator
+// and PC travel inside the game state, so they needn't be installed where the save is
+// imported; the scenario must be.
+type SaveFile struct {
+	Format     int              `json:"format"` // SaveFileFormat when written
+	ExportedAt time.Time        `json:"exported_at"`
+	Scenario   SaveFileScenario `json:"scenario"`
+	GameState  *GameState       `json:"gamestate"`
+}
+
+// SaveFileScenario identifies the scenario a save was played with
+type SaveFileScenario struct {
+	File    string `json:"file"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// NewSaveFile packs a copy of the game into a save file. Fields that only mean something
+// on this server, the owning profile and API key, are left out.
+func NewSaveFile(gs *GameState, s *scenario.Scenario) (*SaveFile, error) {
+	saved, err := gs.DeepCopy()
+	if err != nil {
+		return nil, err
+	}
+	saved.Profile = ""
+	saved.APIKeyID = ""
+
+	return &SaveFile{
+		Format:     SaveFileFormat,
+		ExportedAt: time.Now(),
+		Scenario: SaveFileScenario{
+			File:    gs.Scenario,
+			Name:    s.Name,
+			Version: s.Version,
+		},
+		GameState: saved,
+	}, nil
+}
+
+// Validate checks that the save file can be read by this version of the engine
+func (f *SaveFile) Validate() error {
+	if f.Format < 1 || f.Format > SaveFileFormat {
+		return fmt.Errorf("unsupported save file format %d (supported: 1 to %d)", f.Format, SaveFileFormat)
+	}
+	if f.GameState == nil {
+		return fmt.Errorf("save file has no game state")
+	}
+	if f.GameState.Scenario == "" {
+		return fmt.Errorf("save file's game state has no scenario")
+	}
+	if f.Scenario.File != f.GameState.Scenario {
+		return fmt.Errorf("save file is for scenario %q but its game state is for %q", f.Scenario.File, f.GameState.Scenario)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package state
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func TestNewSaveFile(t *testing.T) {
+	gs := NewGameState("pirate.json", &scenario.Narrator{ID: "salty"}, "test-model")
+	gs.Profile = "kids"
+	gs.APIKeyID = "key-1"
+	gs.Location = "docks"
+
+	save, err := NewSaveFile(gs, &scenario.Scenario{Name: "Pirates", Version: "1.2.0"})
+	if err != nil {
+		t.Fatalf("NewSaveFile failed: %v", err)
+	}
+	if save.Format != SaveFileFormat {
+		t.Errorf("Expected format %d, got %d", SaveFileFormat, save.Format)
+	}
+	if save.Scenario != (SaveFileScenario{File: "pirate.json", Name: "Pirates", Version: "1.2.0"}) {
+		t.Errorf("Unexpected scenario: %+v", save.Scenario)
+	}
+	if save.GameState.Profile != "" || save.GameState.APIKeyID != "" {
+		t.Errorf("Expected the profile and API key to be left out, got %q and %q", save.GameState.Profile, save.GameState.APIKeyID)
+	}
+	if save.GameState.Location != "docks" || save.GameState.Narrator == nil {
+		t.Errorf("Expected the game state with its narrator, got %+v", save.GameState)
+	}
+	if gs.Profile != "kids" {
+		t.Error("Expected the original game state to be unchanged")
+	}
+	if err := save.Validate(); err != nil {
+		t.Errorf("Expected a new save file to be valid, got %v", err)
+	}
+}
+
+func TestSaveFile_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		save    SaveFile
+		wantErr string
+	}{
+		{"valid", SaveFile{Format: 1, Scenario: SaveFileScenario{File: "a.json"}, GameState: &GameState{Scenario: "a.json"}}, ""},
+		{"missing format", SaveFile{Scenario: SaveFileScenario{File: "a.json"}, GameState: &GameState{Scenario: "a.json"}}, "unsupported save file format"},
+		{"newer format", SaveFile{Format: SaveFileFormat + 1, Scenario: SaveFileScenario{File: "a.json"}, GameState: &GameState{Scenario: "a.json"}}, "unsupported save file format"},
+		{"no game state", SaveFile{Format: 1, Scenario: SaveFileScenario{File: "a.json"}}, "no game state"},
+		{"no scenario", SaveFile{Format: 1, GameState: &GameState{}}, "has no scenario"},
+		{"scenario mismatch", SaveFile{Format: 1, Scenario: SaveFileScenario{File: "a.json"}, GameState: &GameState{Scenario: "b.json"}}, "is for scenario"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.save.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
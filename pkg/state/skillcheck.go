@@ -0,0 +1,64 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/jwebster45206/d20"
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
+
+// Skill check outcomes, as set in a check's var
+const (
+	CheckSuccess = "success"
+	CheckFailure = "failure"
+)
+
+// CheckResult is a skill check rolled for the PC. It waits in PendingCheck until the
+// next narration, which resolves it.
+type CheckResult struct {
+	Attribute string `json:"attribute"`        // Ability or skill checked, e.g. "stealth"
+	DC        int    `json:"dc"`               // Difficulty class to meet
+	Reason    string `json:"reason,omitempty"` // What the roll decides
+	Roll      int    `json:"roll"`             // The d20's face
+	Modifier  int    `json:"modifier"`         // The PC's bonus for the attribute; see actor.PC.CheckModifier
+	Total     int    `json:"total"`            // Roll plus modifier
+	Success   bool   `json:"success"`          // Total met the DC
+	Turn      int    `json:"turn"`             // TurnCounter when it was rolled
+}
+
+// Outcome returns CheckSuccess or CheckFailure
+func (r *CheckResult) Outcome() string {
+	if r.Success {
+		return CheckSuccess
+	}
+	return CheckFailure
+}
+
+// newRoller returns a d20 roller for a stream of the game's random numbers; see Rand
+func newRoller(gs *GameState, stream string) *d20.Roller {
+	return d20.NewRoller(gs.Rand(stream).Int64())
+}
+
+// RollCheck rolls a d20 skill check for the PC, adding the PC's d20.Actor modifier for
+// the attribute, or nothing for attributes the PC doesn't have. The roll comes from the
+// game's seed, like every other roll; see Rand.
+func (gs *GameState) RollCheck(check conditionals.SkillCheck) (*CheckResult, error) {
+	attribute := actor.NormalizeAttribute(check.Attribute)
+	modifier, _ := gs.PC.CheckModifier(attribute)
+
+	outcome, err := newRoller(gs, "skill_check").Dice(1, 20).WithModifier(attribute, modifier).Roll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll %s check: %w", attribute, err)
+	}
+	return &CheckResult{
+		Attribute: attribute,
+		DC:        check.DC,
+		Reason:    check.Reason,
+		Roll:      outcome.DiceRolls[0],
+		Modifier:  modifier,
+		Total:     outcome.Value,
+		Success:   outcome.Value >= check.DC,
+		Turn:      gs.TurnCounter,
+	}, nil
+}
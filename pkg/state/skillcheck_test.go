@@ -0,0 +1,100 @@
+package state
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+)
+
+func checkPC(t *testing.T) *actor.PC {
+	t.Helper()
+	pc, err := actor.NewPCFromSpec(&actor.PCSpec{
+		ID:    "rogue",
+		Stats: actor.Stats5e{Dexterity: 16, Strength: 8},
+		MaxHP: 20,
+		AC:    14,
+	})
+	if err != nil {
+		t.Fatalf("NewPCFromSpec() error = %v", err)
+	}
+	return pc
+}
+
+func TestGameState_RollCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		check     conditionals.SkillCheck
+		attribute string
+		modifier  int
+	}{
+		{"ability", conditionals.SkillCheck{Attribute: "Dexterity", DC: 12}, "dexterity", 3},
+		{"skill uses its ability", conditionals.SkillCheck{Attribute: "stealth", DC: 15, Reason: "sneak past the guard"}, "stealth", 3},
+		{"negative modifier", conditionals.SkillCheck{Attribute: "athletics", DC: 10}, "athletics", -1},
+		{"unknown attribute adds nothing", conditionals.SkillCheck{Attribute: "Lock Picking", DC: 20}, "lock_picking", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &GameState{Seed: 7, TurnCounter: 4, PC: checkPC(t)}
+			result, err := gs.RollCheck(tt.check)
+			if err != nil {
+				t.Fatalf("RollCheck() error = %v", err)
+			}
+
+			if result.Attribute != tt.attribute || result.Modifier != tt.modifier {
+				t.Errorf("Expected %s with modifier %d, got %s with %d", tt.attribute, tt.modifier, result.Attribute, result.Modifier)
+			}
+			if result.Roll < 1 || result.Roll > 20 {
+				t.Errorf("Expected a d20 roll, got %d", result.Roll)
+			}
+			if result.Total != result.Roll+result.Modifier {
+				t.Errorf("Expected total %d, got %d", result.Roll+result.Modifier, result.Total)
+			}
+			if result.Success != (result.Total >= tt.check.DC) {
+				t.Errorf("Expected success %t for total %d against DC %d", !result.Success, result.Total, tt.check.DC)
+			}
+			if result.DC != tt.check.DC || result.Reason != tt.check.Reason || result.Turn != 4 {
+				t.Errorf("Expected DC, reason and turn to be kept, got %+v", result)
+			}
+
+			// The roll comes from the game's seed
+			again, err := gs.RollCheck(tt.check)
+			if err != nil {
+				t.Fatalf("RollCheck() error = %v", err)
+			}
+			if *again != *result {
+				t.Errorf("Expected the same roll from the same state, got %+v and %+v", result, again)
+			}
+		})
+	}
+}
+
+func TestDeltaWorker_Check(t *testing.T) {
+	gs := &GameState{Seed: 7, TurnCounter: 2, PC: checkPC(t), Vars: map[string]string{}}
+	delta := &conditionals.GameStateDelta{
+		Check: &conditionals.SkillCheck{Attribute: "stealth", DC: 15, Var: "Guard Alerted"},
+	}
+
+	worker := NewDeltaWorker(gs, delta, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := worker.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	result := gs.PendingCheck
+	if result == nil {
+		t.Fatal("Expected a pending check")
+	}
+	if got := gs.Vars["guard_alerted"]; got != result.Outcome() {
+		t.Errorf("Expected guard_alerted = %q, got %q", result.Outcome(), got)
+	}
+
+	// Conditionals apply the delta again, which mustn't roll again
+	gs.PendingCheck = nil
+	if err := worker.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if gs.PendingCheck != nil {
+		t.Errorf("Expected the check to be rolled once, got %+v", gs.PendingCheck)
+	}
+}
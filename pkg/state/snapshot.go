@@ -0,0 +1,90 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxSnapshots is how many named snapshots a game can keep; saving over an existing
+// name doesn't count against it
+const MaxSnapshots = 10
+
+// maxSnapshotNameLength bounds snapshot names, which are shown in save slot lists
+const maxSnapshotNameLength = 64
+
+// Snapshot is a named copy of a game, saved so the player can roll back to it
+type Snapshot struct {
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	Turn      int        `json:"turn"`                    // TurnCounter when the snapshot was taken
+	SceneName string     `json:"scene_name,omitempty"`    // Scene when the snapshot was taken
+	Location  string     `json:"user_location,omitempty"` // Player's location when the snapshot was taken
+	GameState *GameState `json:"gamestate,omitempty"`     // Omitted when listing snapshots
+}
+
+// NewSnapshot takes a named snapshot of a copy of the game
+func NewSnapshot(name string, gs *GameState) (*Snapshot, error) {
+	name = strings.TrimSpace(name)
+	if err := ValidateSnapshotName(name); err != nil {
+		return nil, err
+	}
+	saved, err := gs.DeepCopy()
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		Name:      name,
+		CreatedAt: time.Now(),
+		Turn:      gs.TurnCounter,
+		SceneName: gs.SceneName,
+		Location:  gs.Location,
+		GameState: saved,
+	}, nil
+}
+
+// ValidateSnapshotName checks that a snapshot name is 1 to 64 letters, digits,
+// spaces, hyphens, or underscores
+func ValidateSnapshotName(name string) error {
+	if name == "" {
+		return fmt.Errorf("snapshot name is required")
+	}
+	if len(name) > maxSnapshotNameLength {
+		return fmt.Errorf("snapshot name must be at most %d characters", maxSnapshotNameLength)
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '-' && r != '_' {
+			return fmt.Errorf("snapshot name may only contain letters, digits, spaces, hyphens, and underscores")
+		}
+	}
+	return nil
+}
+
+// Summary returns the snapshot without its game state, for listing
+func (s *Snapshot) Summary() Snapshot {
+	summary := *s
+	summary.GameState = nil
+	return summary
+}
+
+// Restore rolls the game back to the snapshot. The game keeps its identity and
+// owner, and its pause state, since pausing is managed apart from play.
+func (s *Snapshot) Restore(gs *GameState) (*GameState, error) {
+	if s.GameState == nil {
+		return nil, fmt.Errorf("snapshot %q has no game state", s.Name)
+	}
+	restored, err := s.GameState.DeepCopy()
+	if err != nil {
+		return nil, err
+	}
+	restored.ID = gs.ID
+	restored.Profile = gs.Profile
+	restored.APIKeyID = gs.APIKeyID
+	restored.CreatedAt = gs.CreatedAt
+	restored.Paused = gs.Paused
+	restored.PausedAt = gs.PausedAt
+	restored.PauseReason = gs.PauseReason
+	restored.HeldStoryEvents = gs.HeldStoryEvents
+	return restored, nil
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSnapshotName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"simple", "Before the storm", false},
+		{"digits, hyphens, and underscores", "slot-1_b", false},
+		{"empty", "", true},
+		{"punctuation", "save/../1", true},
+		{"too long", strings.Repeat("a", 65), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSnapshotName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSnapshotName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSnapshot_Restore(t *testing.T) {
+	gs := NewGameState("pirate.json", nil, "test-model")
+	gs.Location = "docks"
+	gs.TurnCounter = 3
+	gs.Inventory = []string{"map"}
+
+	snap, err := NewSnapshot("  At the docks ", gs)
+	if err != nil {
+		t.Fatalf("NewSnapshot failed: %v", err)
+	}
+	if snap.Name != "At the docks" || snap.Turn != 3 || snap.Location != "docks" {
+		t.Errorf("Unexpected snapshot: %+v", snap.Summary())
+	}
+	if snap.Summary().GameState != nil {
+		t.Error("Expected the summary to leave out the game state")
+	}
+
+	// Play on, and pause the game
+	gs.Location = "ship"
+	gs.TurnCounter = 7
+	gs.Inventory = append(gs.Inventory, "cutlass")
+	gs.Profile = "kids"
+	gs.Pause("dinner")
+
+	restored, err := snap.Restore(gs)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored.ID != gs.ID || restored.Profile != "kids" {
+		t.Errorf("Expected the game to keep its identity, got %v and %q", restored.ID, restored.Profile)
+	}
+	if restored.Location != "docks" || restored.TurnCounter != 3 || len(restored.Inventory) != 1 {
+		t.Errorf("Expected the snapshot's progress, got %+v", restored)
+	}
+	if !restored.Paused || restored.PauseReason != "dinner" {
+		t.Error("Expected the game's pause state to be kept")
+	}
+	if snap.GameState.Location != "docks" {
+		t.Error("Expected the snapshot to be unchanged by a restore")
+	}
+}
+
+func TestNewSnapshot_InvalidName(t *testing.T) {
+	gs := NewGameState("pirate.json", nil, "test-model")
+	if _, err := NewSnapshot("   ", gs); err == nil {
+		t.Error("Expected a blank name to be rejected")
+	}
+}
@@ -0,0 +1,166 @@
+package state
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+// FieldError describes a field of a game state that failed validation
+type FieldError struct {
+	Field  string `json:"field"`           // Field path, e.g. "npcs.gibbs.location"
+	Value  string `json:"value,omitempty"` // Offending value
+	Reason string `json:"reason"`
+}
+
+func (e FieldError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s (%q)", e.Field, e.Reason, e.Value)
+}
+
+// ValidateAgainstScenario checks that the game's scene, locations, NPCs, items
+// and vars all exist in its scenario, and that fields referring to each other
+// agree: the player and NPCs stand at locations in the world, exits lead to
+// locations in the world, followers follow someone present, counters are
+// consistent, and no item has two holders. It reports every problem rather
+// than stopping at the first. Unlike DeltaWorker.Validate it changes nothing.
+func (gs *GameState) ValidateAgainstScenario(s *scenario.Scenario) []FieldError {
+	if gs == nil || s == nil {
+		return nil
+	}
+
+	var errs []FieldError
+	report := func(field, value, reason string) {
+		errs = append(errs, FieldError{Field: field, Value: value, Reason: reason})
+	}
+
+	if gs.SceneName != "" && !s.HasScene(gs.SceneName) {
+		report("scene_name", gs.SceneName, "unknown scene")
+	}
+
+	// Everything the scenario can put into a game, across all scenes
+	locations := maps.Clone(s.Locations)
+	npcs := maps.Clone(s.NPCs)
+	vars := maps.Clone(s.Vars)
+	if locations == nil {
+		locations = make(map[string]scenario.Location)
+	}
+	if npcs == nil {
+		npcs = make(map[string]actor.NPC)
+	}
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	items := make(map[string]bool)
+	addItems := func(list []string) {
+		for _, item := range list {
+			items[strings.ToLower(s.ItemID(item))] = true
+		}
+	}
+	addItems(slices.Collect(maps.Keys(s.ItemCatalog)))
+	addItems(s.Inventory)
+	addItems(s.OpeningInventory)
+	for _, scene := range s.Scenes {
+		maps.Copy(locations, scene.Locations)
+		maps.Copy(npcs, scene.NPCs)
+		maps.Copy(vars, scene.Vars)
+		for _, c := range scene.Conditionals {
+			maps.Copy(vars, c.Then.SetVars)
+			for _, e := range c.Then.ItemEvents {
+				items[strings.ToLower(e.Item)] = true
+			}
+		}
+	}
+	for _, loc := range locations {
+		addItems(loc.Items)
+	}
+	for _, npc := range npcs {
+		addItems(npc.Items)
+	}
+	if gs.PC != nil && gs.PC.Spec != nil {
+		addItems(gs.PC.Spec.Inventory)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(gs.WorldLocations)) {
+		if _, ok := locations[key]; !ok {
+			report("locations."+key, "", "unknown location")
+		}
+	}
+	if gs.Location != "" {
+		if _, ok := gs.WorldLocations[gs.Location]; !ok {
+			report("user_location", gs.Location, "not a location in the game world")
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(gs.WorldLocations)) {
+		loc := gs.WorldLocations[key]
+		for _, dir := range slices.Sorted(maps.Keys(loc.Exits)) {
+			if _, ok := gs.WorldLocations[loc.Exits[dir]]; !ok {
+				report("locations."+key+".exits."+dir, loc.Exits[dir], "exit leads to a location not in the game world")
+			}
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(gs.NPCs)) {
+		npc := gs.NPCs[key]
+		if _, ok := npcs[key]; !ok {
+			report("npcs."+key, "", "unknown NPC")
+		}
+		if npc.Location != "" {
+			if _, ok := gs.WorldLocations[npc.Location]; !ok {
+				report("npcs."+key+".location", npc.Location, "not a location in the game world")
+			}
+		}
+		if f := npc.Following; f != "" && f != "pc" {
+			if _, ok := gs.NPCs[f]; !ok || f == key {
+				report("npcs."+key+".following", f, `following must be "pc", another NPC in the game, or empty`)
+			}
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(gs.Vars)) {
+		if _, ok := vars[k]; !ok {
+			report("vars."+k, gs.Vars[k], "undeclared variable")
+		}
+	}
+
+	// Every item has at most one holder; see NormalizeItems
+	holders := make(map[string]string)
+	checkItems := func(field string, list []string) {
+		for i, item := range list {
+			path := fmt.Sprintf("%s[%d]", field, i)
+			if !items[strings.ToLower(item)] {
+				report(path, item, "unknown item")
+			}
+			if other, ok := holders[item]; ok {
+				report(path, item, "item is also held by "+other)
+				continue
+			}
+			holders[item] = field
+		}
+	}
+	checkItems("user_inventory", gs.Inventory)
+	for _, key := range slices.Sorted(maps.Keys(gs.NPCs)) {
+		checkItems("npcs."+key+".items", gs.NPCs[key].Items)
+	}
+	for _, key := range slices.Sorted(maps.Keys(gs.WorldLocations)) {
+		checkItems("locations."+key+".items", gs.WorldLocations[key].Items)
+	}
+
+	if gs.TurnCounter < 0 {
+		report("turn_counter", fmt.Sprint(gs.TurnCounter), "must not be negative")
+	}
+	if gs.SceneTurnCounter < 0 {
+		report("scene_turn_counter", fmt.Sprint(gs.SceneTurnCounter), "must not be negative")
+	}
+	if gs.SceneTurnCounter > gs.TurnCounter {
+		report("scene_turn_counter", fmt.Sprint(gs.SceneTurnCounter), "must not exceed turn_counter")
+	}
+
+	return errs
+}
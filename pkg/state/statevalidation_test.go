@@ -0,0 +1,147 @@
+package state
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/actor"
+	"github.com/jwebster45206/story-engine/pkg/conditionals"
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+)
+
+func validationScenario(t *testing.T) *scenario.Scenario {
+	var lantern conditionals.GameStateDelta
+	if err := json.Unmarshal([]byte(`{"set_vars": {"lantern_lit": "true"}, "item_events": [{"item": "lantern", "action": "acquire"}]}`), &lantern); err != nil {
+		t.Fatalf("failed to unmarshal delta: %v", err)
+	}
+
+	return &scenario.Scenario{
+		Name:      "Harbor",
+		Inventory: []string{"rope"},
+		Locations: map[string]scenario.Location{
+			"dock":   {Name: "Dock", Exits: map[string]string{"north": "tavern"}, Items: []string{"crate"}},
+			"tavern": {Name: "Tavern", Exits: map[string]string{"south": "dock"}},
+		},
+		NPCs: map[string]actor.NPC{
+			"gibbs": {Name: "Gibbs", Location: "dock", Items: []string{"map"}},
+		},
+		Vars: map[string]string{"has_ship": "false"},
+		Scenes: map[string]scenario.Scene{
+			"arrival": {
+				Locations: map[string]scenario.Location{"cove": {Name: "Cove"}},
+				NPCs:      map[string]actor.NPC{"parrot": {Name: "Parrot", Location: "cove"}},
+				Vars:      map[string]string{"tide": "low"},
+				Conditionals: map[string]scenario.Conditional{
+					"light_lantern": {Then: lantern},
+				},
+			},
+		},
+	}
+}
+
+func TestGameState_ValidateAgainstScenario(t *testing.T) {
+	s := validationScenario(t)
+
+	tests := []struct {
+		name   string
+		modify func(gs *GameState)
+		fields []string // expected FieldError fields, in order
+	}{
+		{
+			name:   "valid game",
+			modify: func(gs *GameState) {},
+		},
+		{
+			name: "scene content and conditional items and vars are known",
+			modify: func(gs *GameState) {
+				gs.WorldLocations["cove"] = s.Scenes["arrival"].Locations["cove"]
+				gs.NPCs["parrot"] = s.Scenes["arrival"].NPCs["parrot"]
+				gs.Vars["tide"] = "high"
+				gs.Vars["lantern_lit"] = "true"
+				gs.Inventory = []string{"Lantern"}
+			},
+		},
+		{
+			name:   "unknown scene",
+			modify: func(gs *GameState) { gs.SceneName = "finale" },
+			fields: []string{"scene_name"},
+		},
+		{
+			name:   "location not in world",
+			modify: func(gs *GameState) { gs.Location = "cove" },
+			fields: []string{"user_location"},
+		},
+		{
+			name: "unknown location and dangling exit",
+			modify: func(gs *GameState) {
+				gs.WorldLocations["attic"] = scenario.Location{Name: "Attic", Exits: map[string]string{"down": "cellar"}}
+			},
+			fields: []string{"locations.attic", "locations.attic.exits.down"},
+		},
+		{
+			name: "unknown NPC at unknown location",
+			modify: func(gs *GameState) {
+				gs.NPCs["ghost"] = actor.NPC{Name: "Ghost", Location: "crypt"}
+			},
+			fields: []string{"npcs.ghost", "npcs.ghost.location"},
+		},
+		{
+			name: "NPC following someone absent",
+			modify: func(gs *GameState) {
+				gibbs := gs.NPCs["gibbs"]
+				gibbs.Following = "parrot"
+				gs.NPCs["gibbs"] = gibbs
+			},
+			fields: []string{"npcs.gibbs.following"},
+		},
+		{
+			name:   "undeclared var",
+			modify: func(gs *GameState) { gs.Vars["gold"] = "10" },
+			fields: []string{"vars.gold"},
+		},
+		{
+			name:   "unknown item",
+			modify: func(gs *GameState) { gs.Inventory = []string{"rope", "cutlass"} },
+			fields: []string{"user_inventory[1]"},
+		},
+		{
+			name:   "item with two holders",
+			modify: func(gs *GameState) { gs.Inventory = []string{"map"} },
+			fields: []string{"npcs.gibbs.items[0]"},
+		},
+		{
+			name: "scene turns exceed total turns",
+			modify: func(gs *GameState) {
+				gs.TurnCounter = 2
+				gs.SceneTurnCounter = 3
+			},
+			fields: []string{"scene_turn_counter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := NewGameState("harbor.json", nil, "model")
+			if err := gs.LoadScene(s, "arrival"); err != nil {
+				t.Fatalf("failed to load scene: %v", err)
+			}
+			// Start from the scenario-level world only
+			gs.WorldLocations = map[string]scenario.Location{"dock": s.Locations["dock"], "tavern": s.Locations["tavern"]}
+			gs.NPCs = map[string]actor.NPC{"gibbs": s.NPCs["gibbs"]}
+			gs.Vars = map[string]string{"has_ship": "false"}
+			gs.Location = "dock"
+			gs.Inventory = []string{"rope"}
+			tt.modify(gs)
+
+			errs := gs.ValidateAgainstScenario(s)
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Field)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("expected errors for %v, got %v", tt.fields, errs)
+			}
+		})
+	}
+}
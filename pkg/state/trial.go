@@ -0,0 +1,45 @@
+package state
+
+import "fmt"
+
+// Trial limits a preview game, so a storefront can let players sample a scenario
+// cheaply. Every narrated player turn counts, free actions included; server commands
+// and story events don't. Preview games can't be saved, forked, or paused.
+type Trial struct {
+	MaxTurns  int `json:"max_turns"`  // Narrated turns the preview allows
+	TurnsUsed int `json:"turns_used"` // Narrated turns played so far
+}
+
+// TurnsLeft returns how many narrated turns the preview has left
+func (t *Trial) TurnsLeft() int {
+	return max(t.MaxTurns-t.TurnsUsed, 0)
+}
+
+// Exhausted reports whether the preview has used all its turns
+func (t *Trial) Exhausted() bool {
+	return t.TurnsUsed >= t.MaxTurns
+}
+
+// Watermark is the notice shown after the narration of the preview's next turn
+func (t *Trial) Watermark() string {
+	turn := min(t.TurnsUsed+1, t.MaxTurns)
+	if turn == t.MaxTurns {
+		return fmt.Sprintf("[Preview: turn %d of %d. This is the end of the preview.]", turn, t.MaxTurns)
+	}
+	return fmt.Sprintf("[Preview: turn %d of %d]", turn, t.MaxTurns)
+}
+
+// EndedMessage is the player-facing explanation for a chat sent once the preview is over
+func (t *Trial) EndedMessage() string {
+	return fmt.Sprintf("This preview has ended after %d turns. Start a full game to keep playing.", t.MaxTurns)
+}
+
+// IsTrial reports whether the game is a preview
+func (gs *GameState) IsTrial() bool {
+	return gs.Trial != nil
+}
+
+// TrialExhausted reports whether the game is a preview that has used all its turns
+func (gs *GameState) TrialExhausted() bool {
+	return gs.Trial != nil && gs.Trial.Exhausted()
+}
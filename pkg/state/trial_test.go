@@ -0,0 +1,53 @@
+package state
+
+import "testing"
+
+func TestTrial(t *testing.T) {
+	tests := []struct {
+		name          string
+		trial         Trial
+		wantLeft      int
+		wantExhausted bool
+		wantWatermark string
+	}{
+		{name: "fresh", trial: Trial{MaxTurns: 3}, wantLeft: 3, wantWatermark: "[Preview: turn 1 of 3]"},
+		{name: "last turn next", trial: Trial{MaxTurns: 3, TurnsUsed: 2}, wantLeft: 1, wantWatermark: "[Preview: turn 3 of 3. This is the end of the preview.]"},
+		{name: "used up", trial: Trial{MaxTurns: 3, TurnsUsed: 3}, wantLeft: 0, wantExhausted: true, wantWatermark: "[Preview: turn 3 of 3. This is the end of the preview.]"},
+		{name: "over the limit", trial: Trial{MaxTurns: 3, TurnsUsed: 5}, wantLeft: 0, wantExhausted: true, wantWatermark: "[Preview: turn 3 of 3. This is the end of the preview.]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.trial.TurnsLeft(); got != tt.wantLeft {
+				t.Errorf("TurnsLeft() = %d, want %d", got, tt.wantLeft)
+			}
+			if got := tt.trial.Exhausted(); got != tt.wantExhausted {
+				t.Errorf("Exhausted() = %v, want %v", got, tt.wantExhausted)
+			}
+			if got := tt.trial.Watermark(); got != tt.wantWatermark {
+				t.Errorf("Watermark() = %q, want %q", got, tt.wantWatermark)
+			}
+		})
+	}
+}
+
+func TestGameState_TrialStatus(t *testing.T) {
+	gs := NewGameState("test.json", nil, "test-model")
+	if gs.IsTrial() || gs.TrialExhausted() {
+		t.Error("Expected a full game not to be a preview")
+	}
+	if summary := gs.Summary(nil); summary.TrialTurnsLeft != nil {
+		t.Errorf("Expected no trial turns in a full game's summary, got %d", *summary.TrialTurnsLeft)
+	}
+
+	gs.Trial = &Trial{MaxTurns: 2, TurnsUsed: 1}
+	if !gs.IsTrial() || gs.TrialExhausted() {
+		t.Error("Expected a preview with turns left")
+	}
+	if summary := gs.Summary(nil); summary.TrialTurnsLeft == nil || *summary.TrialTurnsLeft != 1 {
+		t.Errorf("Expected 1 trial turn left in the summary, got %v", summary.TrialTurnsLeft)
+	}
+	gs.Trial.TurnsUsed++
+	if !gs.TrialExhausted() {
+		t.Error("Expected the preview to be exhausted")
+	}
+}
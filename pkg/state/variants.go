@@ -0,0 +1,31 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoPendingVariants is returned by ChooseVariant when no turn is waiting for a choice
+var ErrNoPendingVariants = errors.New("no narration variants to choose from")
+
+// PendingVariants are narration candidates for a player turn, waiting for the client to
+// choose which becomes canon. The turn isn't in the chat history and its delta hasn't run
+// until then. Any other turn discards them.
+type PendingVariants struct {
+	Message    string   `json:"message"`               // Player's message, as sent to the narrator
+	FreeAction bool     `json:"free_action,omitempty"` // The turn won't advance the turn counters
+	Candidates []string `json:"candidates"`            // Narrations to choose from, in order
+}
+
+// ChooseVariant returns the pending turn and its chosen narration, by index into the
+// candidates. The variants stay pending until the turn is played.
+func (gs *GameState) ChooseVariant(index int) (*PendingVariants, string, error) {
+	pending := gs.PendingVariants
+	if pending == nil || len(pending.Candidates) == 0 {
+		return nil, "", ErrNoPendingVariants
+	}
+	if index < 0 || index >= len(pending.Candidates) {
+		return nil, "", fmt.Errorf("variant %d out of range: choose from 0 to %d", index, len(pending.Candidates)-1)
+	}
+	return pending, pending.Candidates[index], nil
+}
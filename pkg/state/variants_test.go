@@ -0,0 +1,46 @@
+package state
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGameState_ChooseVariant(t *testing.T) {
+	pending := &PendingVariants{Message: "I open the chest", Candidates: []string{"Gold!", "Spiders!"}}
+
+	tests := []struct {
+		name    string
+		pending *PendingVariants
+		index   int
+		want    string
+		wantErr bool
+	}{
+		{name: "first", pending: pending, index: 0, want: "Gold!"},
+		{name: "second", pending: pending, index: 1, want: "Spiders!"},
+		{name: "out of range", pending: pending, index: 2, wantErr: true},
+		{name: "negative", pending: pending, index: -1, wantErr: true},
+		{name: "nothing pending", index: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := NewGameState("test.json", nil, "test-model")
+			gs.PendingVariants = tt.pending
+
+			turn, got, err := gs.ChooseVariant(tt.index)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChooseVariant(%d) error = %v, wantErr %v", tt.index, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want || turn.Message != "I open the chest" {
+				t.Errorf("ChooseVariant(%d) = %q for %q, want %q", tt.index, got, turn.Message, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := NewGameState("test.json", nil, "test-model").ChooseVariant(0); !errors.Is(err, ErrNoPendingVariants) {
+		t.Errorf("Expected ErrNoPendingVariants, got %v", err)
+	}
+}
@@ -20,11 +20,13 @@ type MockStorage struct {
 	pcSpecs    map[string]*actor.PCSpec
 	monsters   map[string]*actor.Monster
 	npcs       map[string]*actor.NPC
+	snapshots  map[uuid.UUID][]*state.Snapshot
 	pingError  error
 }
 
 // Ensure MockStorage implements Storage interface
 var _ Storage = (*MockStorage)(nil)
+var _ Snapshots = (*MockStorage)(nil)
 
 // NewMockStorage creates a new mock storage
 func NewMockStorage() *MockStorage {
@@ -35,6 +37,7 @@ func NewMockStorage() *MockStorage {
 		pcSpecs:    make(map[string]*actor.PCSpec),
 		monsters:   make(map[string]*actor.Monster),
 		npcs:       make(map[string]*actor.NPC),
+		snapshots:  make(map[uuid.UUID][]*state.Snapshot),
 	}
 }
 
@@ -95,9 +98,58 @@ func (m *MockStorage) DeleteGameState(ctx context.Context, id uuid.UUID) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	delete(m.gamestates, id)
+	delete(m.snapshots, id)
 	return nil
 }
 
+// SaveSnapshot mocks saving a snapshot, replacing any with the same name
+func (m *MockStorage) SaveSnapshot(ctx context.Context, id uuid.UUID, snap *state.Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.snapshots[id][:0:0]
+	for _, s := range m.snapshots[id] {
+		if s.Name != snap.Name {
+			kept = append(kept, s)
+		}
+	}
+	m.snapshots[id] = append(kept, snap)
+	return nil
+}
+
+// ListSnapshots mocks listing a game's snapshots, oldest first
+func (m *MockStorage) ListSnapshots(ctx context.Context, id uuid.UUID) ([]state.Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	snapshots := make([]state.Snapshot, 0, len(m.snapshots[id]))
+	for _, s := range m.snapshots[id] {
+		snapshots = append(snapshots, s.Summary())
+	}
+	return snapshots, nil
+}
+
+// LoadSnapshot mocks loading a snapshot by name
+func (m *MockStorage) LoadSnapshot(ctx context.Context, id uuid.UUID, name string) (*state.Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.snapshots[id] {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return nil, nil // Return nil for not found
+}
+
+// ListGameStateIDs mocks listing gamestate IDs
+func (m *MockStorage) ListGameStateIDs(ctx context.Context) ([]uuid.UUID, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]uuid.UUID, 0, len(m.gamestates))
+	for id := range m.gamestates {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 // ListScenarios mocks listing scenarios
 func (m *MockStorage) ListScenarios(ctx context.Context) (map[string]string, error) {
 	m.mu.RLock()
@@ -123,6 +175,25 @@ func (m *MockStorage) GetScenario(ctx context.Context, filename string) (*scenar
 	return s, nil
 }
 
+// SaveScenario mocks saving a scenario
+func (m *MockStorage) SaveScenario(ctx context.Context, filename string, s *scenario.Scenario) error {
+	if s == nil || filename == "" {
+		return errors.New("scenario filename is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scenarios[filename] = s
+	return nil
+}
+
+// DeleteScenario mocks deleting a scenario
+func (m *MockStorage) DeleteScenario(ctx context.Context, filename string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.scenarios, filename)
+	return nil
+}
+
 // AddScenario adds a scenario to the mock storage (for testing)
 func (m *MockStorage) AddScenario(filename string, s *scenario.Scenario) {
 	m.mu.Lock()
@@ -158,6 +229,25 @@ func (m *MockStorage) ListNarrators(ctx context.Context) ([]string, error) {
 	return result, nil
 }
 
+// SaveNarrator mocks saving a narrator
+func (m *MockStorage) SaveNarrator(ctx context.Context, n *scenario.Narrator) error {
+	if n == nil || n.ID == "" {
+		return errors.New("narrator ID is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.narrators[n.ID] = n
+	return nil
+}
+
+// DeleteNarrator mocks deleting a narrator
+func (m *MockStorage) DeleteNarrator(ctx context.Context, narratorID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.narrators, narratorID)
+	return nil
+}
+
 // AddNarrator adds a narrator to the mock storage (for testing)
 func (m *MockStorage) AddNarrator(narratorID string, n *scenario.Narrator) {
 	m.mu.Lock()
@@ -189,6 +279,25 @@ func (m *MockStorage) ListPCs(ctx context.Context) ([]string, error) {
 	return result, nil
 }
 
+// SavePCSpec mocks saving a PC spec
+func (m *MockStorage) SavePCSpec(ctx context.Context, spec *actor.PCSpec) error {
+	if spec == nil || spec.ID == "" {
+		return errors.New("PC ID is required")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pcSpecs[spec.ID] = spec
+	return nil
+}
+
+// DeletePCSpec mocks deleting a PC spec
+func (m *MockStorage) DeletePCSpec(ctx context.Context, pcID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pcSpecs, pcID)
+	return nil
+}
+
 // AddPCSpec adds a PC spec to the mock storage (for testing)
 func (m *MockStorage) AddPCSpec(pcID string, spec *actor.PCSpec) {
 	m.mu.Lock()
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"reflect"
 
 	"github.com/google/uuid"
 	"github.com/jwebster45206/story-engine/pkg/actor"
@@ -9,39 +11,136 @@ import (
 	"github.com/jwebster45206/story-engine/pkg/state"
 )
 
-// Storage defines a unified interface for all storage operations
-// This interface combines gamestate persistence (Redis) with resource loading (filesystem)
+// ErrContentLocked is returned when content can't be written because it is verified
+// against an integrity manifest, which the change would no longer match
+var ErrContentLocked = errors.New("content is locked by its integrity manifest")
+
+// ErrContentBundled is returned when content can't be deleted because it is a file in
+// the data directory, and the storage keeps content saved through the API elsewhere
+var ErrContentBundled = errors.New("content is bundled in the data directory")
+
+// Storage is the full storage a game needs: game states, the content they are
+// played from, and health. Components that need less should depend on one of the
+// narrower interfaces below, so the pieces can come from different backends.
 type Storage interface {
-	// Health and lifecycle
+	Health
+	GameStateStore
+	ContentStore
+}
+
+// Health covers a storage backend's lifecycle
+type Health interface {
 	Ping(ctx context.Context) error
 	Close() error
+}
 
-	// GameState operations (Redis-backed)
+// GameStateStore persists game states
+type GameStateStore interface {
 	SaveGameState(ctx context.Context, id uuid.UUID, gs *state.GameState) error
 	LoadGameState(ctx context.Context, id uuid.UUID) (*state.GameState, error)
 	DeleteGameState(ctx context.Context, id uuid.UUID) error
+	ListGameStateIDs(ctx context.Context) ([]uuid.UUID, error)
+}
 
-	// Scenario operations (filesystem-backed)
+// ContentStore serves the authored content games are played from: scenarios,
+// narrators, PCs, monsters, and NPCs
+type ContentStore interface {
+	// Scenario operations
 	ListScenarios(ctx context.Context) (map[string]string, error)
 	GetScenario(ctx context.Context, filename string) (*scenario.Scenario, error)
+	SaveScenario(ctx context.Context, filename string, s *scenario.Scenario) error
+	DeleteScenario(ctx context.Context, filename string) error
 
-	// Narrator operations (filesystem-backed)
+	// Narrator operations
 	GetNarrator(ctx context.Context, narratorID string) (*scenario.Narrator, error)
 	ListNarrators(ctx context.Context) ([]string, error)
+	SaveNarrator(ctx context.Context, n *scenario.Narrator) error
+	DeleteNarrator(ctx context.Context, narratorID string) error
 
-	// PC operations (filesystem-backed, returns PCSpec not PC)
+	// PC operations (returns PCSpec not PC)
 	// GetPCSpec loads a PC spec from storage but does NOT construct the d20.Actor
 	// Use actor.NewPCFromSpec to build the full PC from the returned spec
 	GetPCSpec(ctx context.Context, pcID string) (*actor.PCSpec, error)
 	ListPCs(ctx context.Context) ([]string, error)
+	SavePCSpec(ctx context.Context, spec *actor.PCSpec) error
+	DeletePCSpec(ctx context.Context, pcID string) error
 
-	// Monster operations (filesystem-backed, returns Monster template)
+	// Monster operations (returns Monster template)
 	// Use actor.NewMonster to create instances from the template
 	GetMonster(ctx context.Context, templateID string) (*actor.Monster, error)
 	ListMonsters(ctx context.Context) (map[string]string, error) // map[name]templateID
 
-	// NPC operations (filesystem-backed, returns NPC template from data/npcs/)
+	// NPC operations (returns NPC template from data/npcs/)
 	// Use actor.NewNPCFromTemplate to merge template with scenario overrides
 	GetNPC(ctx context.Context, templateID string) (*actor.NPC, error)
 	ListNPCs(ctx context.Context) (map[string]string, error) // map[name]templateID
 }
+
+// Combine returns a Storage keeping game states in games and reading content from
+// content, e.g. game states in a database and content from the filesystem. Ping and
+// Close reach each part that implements Health.
+func Combine(games GameStateStore, content ContentStore) Storage {
+	return combined{GameStateStore: games, ContentStore: content}
+}
+
+type combined struct {
+	GameStateStore
+	ContentStore
+}
+
+func (c combined) Ping(ctx context.Context) error {
+	for _, part := range c.parts() {
+		if err := part.Ping(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c combined) Close() error {
+	var errs []error
+	for _, part := range c.parts() {
+		errs = append(errs, part.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// parts returns the parts that implement Health, once each
+func (c combined) parts() []Health {
+	var parts []Health
+	if h, ok := c.GameStateStore.(Health); ok {
+		parts = append(parts, h)
+	}
+	if h, ok := c.ContentStore.(Health); ok && !samePart(c.ContentStore, c.GameStateStore) {
+		parts = append(parts, h)
+	}
+	return parts
+}
+
+// samePart reports whether both parts are the same backend, without panicking on
+// values that can't be compared
+func samePart(a, b any) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t != nil && t.Comparable() && a == b
+}
+
+// EventLog is implemented by storage that can record game state saves as an
+// append-only event log. Logs are empty when event sourcing is turned off.
+type EventLog interface {
+	// ListGameEvents returns a game's event log, oldest first
+	ListGameEvents(ctx context.Context, id uuid.UUID) ([]state.GameEvent, error)
+	// ForkGameState copies the first seq events of a game's log to a new game with
+	// ID newID and returns the new game's state
+	ForkGameState(ctx context.Context, id uuid.UUID, seq int, newID uuid.UUID) (*state.GameState, error)
+}
+
+// Snapshots is implemented by storage that can keep named snapshots of a game, for
+// save slots. A game's snapshots expire and are deleted along with it.
+type Snapshots interface {
+	// SaveSnapshot saves a snapshot of a game, replacing any with the same name
+	SaveSnapshot(ctx context.Context, id uuid.UUID, snap *state.Snapshot) error
+	// ListSnapshots returns a game's snapshots without their game states, oldest first
+	ListSnapshots(ctx context.Context, id uuid.UUID) ([]state.Snapshot, error)
+	// LoadSnapshot returns a game's snapshot by name, or nil if there is none
+	LoadSnapshot(ctx context.Context, id uuid.UUID, name string) (*state.Snapshot, error)
+}
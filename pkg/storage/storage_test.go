@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jwebster45206/story-engine/pkg/scenario"
+	"github.com/jwebster45206/story-engine/pkg/state"
+)
+
+// contentOnly is a ContentStore without Health
+type contentOnly struct {
+	ContentStore
+}
+
+func TestCombine(t *testing.T) {
+	pingErr := errors.New("unreachable")
+
+	tests := []struct {
+		name        string
+		parts       func() (GameStateStore, ContentStore)
+		wantPingErr error
+	}{
+		{
+			name: "separate backends",
+			parts: func() (GameStateStore, ContentStore) {
+				return NewMockStorage(), NewMockStorage()
+			},
+		},
+		{
+			name: "game state backend down",
+			parts: func() (GameStateStore, ContentStore) {
+				games := NewMockStorage()
+				games.SetPingError(pingErr)
+				return games, NewMockStorage()
+			},
+			wantPingErr: pingErr,
+		},
+		{
+			name: "content backend down",
+			parts: func() (GameStateStore, ContentStore) {
+				content := NewMockStorage()
+				content.SetPingError(pingErr)
+				return NewMockStorage(), content
+			},
+			wantPingErr: pingErr,
+		},
+		{
+			name: "content without health",
+			parts: func() (GameStateStore, ContentStore) {
+				return NewMockStorage(), contentOnly{NewMockStorage()}
+			},
+		},
+		{
+			name: "same backend for both",
+			parts: func() (GameStateStore, ContentStore) {
+				both := NewMockStorage()
+				return both, both
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Combine(tt.parts())
+			if err := s.Ping(context.Background()); !errors.Is(err, tt.wantPingErr) {
+				t.Errorf("Expected ping error %v, got %v", tt.wantPingErr, err)
+			}
+			if err := s.Close(); err != nil {
+				t.Errorf("Expected close to succeed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCombine_RoutesToParts(t *testing.T) {
+	ctx := context.Background()
+	games := NewMockStorage()
+	content := NewMockStorage()
+	content.AddScenario("pirate.json", &scenario.Scenario{Name: "Pirate"})
+	s := Combine(games, content)
+
+	gs := state.NewGameState("pirate.json", nil, "test_model")
+	if err := s.SaveGameState(ctx, gs.ID, gs); err != nil {
+		t.Fatalf("Failed to save gamestate: %v", err)
+	}
+	if loaded, _ := games.LoadGameState(ctx, gs.ID); loaded == nil {
+		t.Error("Expected the game state in the game state store")
+	}
+	if loaded, _ := content.LoadGameState(ctx, gs.ID); loaded != nil {
+		t.Error("Expected no game state in the content store")
+	}
+
+	scenarios, err := s.ListScenarios(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list scenarios: %v", err)
+	}
+	if len(scenarios) != 1 {
+		t.Errorf("Expected the content store's scenario, got %v", scenarios)
+	}
+}
@@ -1,7 +1,9 @@
 package textfilter
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -64,34 +66,92 @@ var swearWordReplacements = map[string]string{
 	"douchebag":    "jerk",
 }
 
-// ProfanityFilter handles filtering and replacement of profanity
+// pg13AllowedWords are built-in words acceptable in PG13 content, filtered at PG and G only
+var pg13AllowedWords = map[string]bool{
+	"damn":     true,
+	"hell":     true,
+	"ass":      true,
+	"crap":     true,
+	"goddamn":  true,
+	"christ":   true,
+	"asshole":  true,
+	"jackass":  true,
+	"smartass": true,
+	"badass":   true,
+	"bastard":  true,
+	"bullshit": true,
+}
+
+// BaseLocale is the locale of the built-in word list, which is applied in every locale
+const BaseLocale = "en"
+
+// censored replaces words listed without a replacement
+const censored = "[censored]"
+
+// ratingLevels orders the filtered content ratings from the strictest. A word filtered
+// at one rating is filtered at every stricter rating too; other ratings aren't filtered.
+var ratingLevels = map[string]int{
+	"G":    0,
+	"PG":   1,
+	"PG13": 2,
+}
+
+// leetspeak maps the digits and symbols commonly swapped for letters back to the letters
+var leetspeak = map[byte]byte{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's',
+}
+
+// filterWord is one word the filter replaces
+type filterWord struct {
+	word        string
+	replacement string
+	maxLevel    int // strictness level of the mildest rating the word is filtered at; see ratingLevels
+	regex       *regexp.Regexp
+}
+
+// ProfanityFilter handles filtering and replacement of profanity. It starts with the
+// built-in English list; more words can be listed per locale and rating, and words
+// allowed at milder ratings.
 type ProfanityFilter struct {
-	regexes map[string]*regexp.Regexp
+	words     map[string]map[string]*filterWord // by locale, then word
+	locale    string                            // locale whose words apply alongside the base list; see ForLocale
+	leetspeak bool
 }
 
 // NewProfanityFilter creates a new profanity filter
 func NewProfanityFilter() *ProfanityFilter {
 	pf := &ProfanityFilter{
-		regexes: make(map[string]*regexp.Regexp),
+		words:  map[string]map[string]*filterWord{BaseLocale: {}},
+		locale: BaseLocale,
 	}
 
-	// Pre-compile regex patterns for each swear word
 	for _, word := range swearWords {
-		// Create a regex that matches the word with word boundaries and handles plurals
-		pattern := `\b` + regexp.QuoteMeta(word)
-
-		// Add optional 's' for pluralizable words (nouns, not phrases)
-		if canBePluralized(word) {
-			pattern += `s?` // Optional 's' for plural
+		maxLevel := ratingLevels["PG13"]
+		if pg13AllowedWords[word] {
+			maxLevel = ratingLevels["PG"]
 		}
-
-		pattern += `\b`
-		pf.regexes[word] = regexp.MustCompile(`(?i)` + pattern)
+		pf.words[BaseLocale][word] = newFilterWord(word, swearWordReplacements[word], maxLevel, canBePluralized(word))
 	}
 
 	return pf
 }
 
+// newFilterWord compiles the pattern for a word, matched on word boundaries and
+// optionally followed by a plural "s"
+func newFilterWord(word, replacement string, maxLevel int, plural bool) *filterWord {
+	pattern := `\b` + regexp.QuoteMeta(word)
+	if plural {
+		pattern += `s?`
+	}
+	pattern += `\b`
+	return &filterWord{
+		word:        word,
+		replacement: replacement,
+		maxLevel:    maxLevel,
+		regex:       regexp.MustCompile(`(?i)` + pattern),
+	}
+}
+
 // canBePluralized determines if a word can have an 's' added for plural form
 func canBePluralized(word string) bool {
 	// Words that are typically nouns and can be pluralized
@@ -119,50 +179,183 @@ func canBePluralized(word string) bool {
 	return pluralizableWords[word]
 }
 
+// AddWords lists words to filter in a locale at a rating, and so at every stricter
+// rating, each mapped to its replacement; an empty replacement masks the word as
+// "[censored]". A word already listed keeps the mildest rating it is filtered at.
+func (pf *ProfanityFilter) AddWords(locale, contentRating string, words map[string]string) error {
+	level, ok := ratingLevel(contentRating)
+	if !ok {
+		return fmt.Errorf("rating %q is not filtered; use G, PG or PG13", contentRating)
+	}
+	list := pf.localeWords(locale)
+	for word, replacement := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			return fmt.Errorf("empty word in the %s list for %s", normalizeLocale(locale), contentRating)
+		}
+		if replacement == "" {
+			replacement = censored
+		}
+		if existing, ok := list[word]; ok {
+			level = max(level, existing.maxLevel)
+		}
+		list[word] = newFilterWord(word, replacement, level, !strings.Contains(word, " "))
+	}
+	return nil
+}
+
+// AllowWords stops filtering words of a locale at a rating and every milder one, e.g.
+// allowing "crap" at PG still filters it at G
+func (pf *ProfanityFilter) AllowWords(locale, contentRating string, words []string) error {
+	level, ok := ratingLevel(contentRating)
+	if !ok {
+		return fmt.Errorf("rating %q is not filtered; use G, PG or PG13", contentRating)
+	}
+	list := pf.localeWords(locale)
+	for _, word := range words {
+		if existing, ok := list[strings.ToLower(strings.TrimSpace(word))]; ok {
+			existing.maxLevel = min(existing.maxLevel, level-1)
+		}
+	}
+	return nil
+}
+
+// WithLeetspeak also catches words spelled with digits and symbols, such as "sh1t" or
+// "@ss". A match must keep at least one letter, so plain numbers are left alone.
+func (pf *ProfanityFilter) WithLeetspeak(enabled bool) *ProfanityFilter {
+	pf.leetspeak = enabled
+	return pf
+}
+
+// ForLocale returns a filter applying the words of a locale, e.g. "es" or "pt-BR", as
+// well as the built-in list. The filters share their word lists.
+func (pf *ProfanityFilter) ForLocale(locale string) *ProfanityFilter {
+	scoped := *pf
+	scoped.locale = normalizeLocale(locale)
+	return &scoped
+}
+
+// Locales returns the locales with word lists, sorted
+func (pf *ProfanityFilter) Locales() []string {
+	locales := make([]string, 0, len(pf.words))
+	for locale := range pf.words {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Leetspeak reports whether the filter catches words spelled with digits and symbols
+func (pf *ProfanityFilter) Leetspeak() bool {
+	return pf.leetspeak
+}
+
 // FilterText replaces profanity in the input text based on content rating
 func (pf *ProfanityFilter) FilterText(text string, contentRating string) string {
-	rating := strings.ToUpper(strings.TrimSpace(contentRating))
-
 	// No filtering for unknown/empty ratings or mature content
-	if rating != "G" && rating != "PG" && rating != "PG13" && rating != "PG-13" {
+	level, ok := ratingLevel(contentRating)
+	if !ok {
 		return text
 	}
 
 	result := text
+	for _, w := range pf.activeWords() {
+		if level <= w.maxLevel {
+			result = pf.replace(result, w)
+		}
+	}
+	return result
+}
 
-	// Define words that are acceptable in PG13 content
-	pg13AllowedWords := map[string]bool{
-		"damn":     true,
-		"hell":     true,
-		"ass":      true,
-		"crap":     true,
-		"goddamn":  true,
-		"christ":   true,
-		"asshole":  true,
-		"jackass":  true,
-		"smartass": true,
-		"badass":   true,
-		"bastard":  true,
-		"bullshit": true,
-	}
-
-	// Replace each swear word with its family-friendly alternative
-	for _, word := range swearWords {
-		// Skip filtering for PG13-allowed words if rating is PG13
-		if (rating == "PG13" || rating == "PG-13") && pg13AllowedWords[word] {
+// activeWords returns the words of the base list and the filter's locale, longest
+// first so phrases such as "jesus christ" are replaced before the words within them
+func (pf *ProfanityFilter) activeWords() []*filterWord {
+	byWord := make(map[string]*filterWord, len(pf.words[BaseLocale]))
+	for word, w := range pf.words[BaseLocale] {
+		byWord[word] = w
+	}
+	if pf.locale != BaseLocale {
+		for word, w := range pf.words[pf.locale] {
+			byWord[word] = w
+		}
+	}
+
+	words := make([]*filterWord, 0, len(byWord))
+	for _, w := range byWord {
+		words = append(words, w)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if len(words[i].word) != len(words[j].word) {
+			return len(words[i].word) > len(words[j].word)
+		}
+		return words[i].word < words[j].word
+	})
+	return words
+}
+
+// replace replaces each match of a word, keeping the case of the original. With
+// leetspeak on, words are matched against a copy of the text with leetspeak letters
+// restored, which has the same length, so matches map straight back to the text.
+func (pf *ProfanityFilter) replace(text string, w *filterWord) string {
+	haystack := text
+	if pf.leetspeak {
+		haystack = normalizeLeetspeak(text)
+	}
+	locs := w.regex.FindAllStringIndex(haystack, -1)
+	if locs == nil {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range locs {
+		match := text[loc[0]:loc[1]]
+		if !strings.ContainsFunc(match, unicode.IsLetter) {
 			continue
 		}
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(preserveCase(match, w.replacement))
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
 
-		if regex, exists := pf.regexes[word]; exists {
-			if replacement, hasReplacement := swearWordReplacements[word]; hasReplacement {
-				result = regex.ReplaceAllStringFunc(result, func(match string) string {
-					return preserveCase(match, replacement)
-				})
-			}
+// normalizeLeetspeak replaces leetspeak digits and symbols with the letters they stand for
+func normalizeLeetspeak(text string) string {
+	b := []byte(text)
+	for i, c := range b {
+		if letter, ok := leetspeak[c]; ok {
+			b[i] = letter
 		}
 	}
+	return string(b)
+}
 
-	return result
+func (pf *ProfanityFilter) localeWords(locale string) map[string]*filterWord {
+	locale = normalizeLocale(locale)
+	if pf.words[locale] == nil {
+		pf.words[locale] = make(map[string]*filterWord)
+	}
+	return pf.words[locale]
+}
+
+// ratingLevel returns the strictness level of a filtered rating
+func ratingLevel(contentRating string) (int, bool) {
+	rating := strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(contentRating)), "-", "")
+	level, ok := ratingLevels[rating]
+	return level, ok
+}
+
+// normalizeLocale reduces a locale to its language, e.g. "pt-BR" to "pt"; empty means
+// the base locale
+func normalizeLocale(locale string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(locale)), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	if lang == "" {
+		return BaseLocale
+	}
+	return lang
 }
 
 // preserveCase applies the case pattern of the original word to the replacement
@@ -217,13 +410,11 @@ func preserveCase(original, replacement string) string {
 	return string(result)
 }
 
-// ContainsProfanity checks if the text contains any profanity
+// ContainsProfanity checks if the text contains any profanity, at any rating
 func (pf *ProfanityFilter) ContainsProfanity(text string) bool {
-	for _, word := range swearWords {
-		if regex, exists := pf.regexes[word]; exists {
-			if regex.MatchString(text) {
-				return true
-			}
+	for _, w := range pf.activeWords() {
+		if w.maxLevel >= 0 && pf.replace(text, w) != text {
+			return true
 		}
 	}
 	return false
@@ -235,3 +235,51 @@ func TestProfanityFilter_Integration(t *testing.T) {
 		t.Errorf("PG filtered input should not contain profanity")
 	}
 }
+
+func TestProfanityFilter_WordLists(t *testing.T) {
+	filter := NewProfanityFilter().WithLeetspeak(true)
+	if err := filter.AddWords("en", "PG", map[string]string{"blimey": "gosh", "bloody": ""}); err != nil {
+		t.Fatalf("AddWords() error = %v", err)
+	}
+	if err := filter.AddWords("es", "PG13", map[string]string{"mierda": "caramba"}); err != nil {
+		t.Fatalf("AddWords() error = %v", err)
+	}
+	if err := filter.AllowWords("en", "PG", []string{"crap"}); err != nil {
+		t.Fatalf("AllowWords() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		locale        string
+		input         string
+		contentRating string
+		expected      string
+	}{
+		{"added word", "en", "Blimey, the bloody mast!", "PG", "Gosh, the [censored] mast!"},
+		{"added word allowed at milder rating", "en", "Blimey!", "PG13", "Blimey!"},
+		{"allowed word", "en", "What a load of crap.", "PG", "What a load of crap."},
+		{"allowed word still filtered at stricter rating", "en", "What a load of crap.", "G", "What a load of crud."},
+		{"leetspeak", "en", "This sh1t, this $hit, this 5hit.", "PG13", "This shoot, this shoot, this shoot."},
+		{"leetspeak in caps", "en", "SH1T!", "PG", "SHOOT!"},
+		{"numbers left alone", "en", "The chest holds 455 coins.", "G", "The chest holds 455 coins."},
+		{"locale list", "es-MX", "¡Mierda! What the hell?", "PG", "¡Caramba! What the heck?"},
+		{"other locale's list not applied", "en", "¡Mierda!", "PG", "¡Mierda!"},
+		{"locale list unfiltered at R", "es", "¡Mierda!", "R", "¡Mierda!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := filter.ForLocale(tt.locale).FilterText(tt.input, tt.contentRating)
+			if result != tt.expected {
+				t.Errorf("FilterText() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	if err := filter.AddWords("en", "R", map[string]string{"zounds": ""}); err == nil {
+		t.Errorf("AddWords() at an unfiltered rating should fail")
+	}
+	if got := filter.Locales(); len(got) != 2 || got[0] != "en" || got[1] != "es" {
+		t.Errorf("Locales() = %v, want [en es]", got)
+	}
+}
@@ -0,0 +1,200 @@
+package textfilter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// segmentChars is the length after which a line without a newline is split at the
+// next sentence end, so streamed narration isn't held back a whole paragraph at a time
+const segmentChars = 160
+
+// Step transforms one segment of narration: a line without its newline, or part of a
+// long line. lineStart is false for the second and later parts of a long line.
+// Returning false drops the segment, and its newline with it.
+type Step func(segment string, lineStart bool) (string, bool)
+
+// Pipeline post-processes narration by running each segment through its steps in order.
+// Segments are cut at the same places however the text arrives, so streaming a response
+// chunk by chunk with Write and Flush gives the same result as Process on the whole text.
+// Steps may keep state between segments, so a Pipeline is used for one response only.
+type Pipeline struct {
+	steps        []Step
+	maxChars     int
+	maxSentences int
+	buf          string
+	lineStart    bool
+	written      int
+	sentences    int
+	stopped      bool
+	trailer      *trailer // see WithTrailer
+}
+
+// NewPipeline creates a pipeline that applies steps in order
+func NewPipeline(steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps, lineStart: true}
+}
+
+// WithMaxLength cuts the narration at the last sentence end before maxChars characters.
+// Zero means no limit.
+func (p *Pipeline) WithMaxLength(maxChars int) *Pipeline {
+	p.maxChars = maxChars
+	return p
+}
+
+// WithMaxSentences ends the narration after maxSentences sentences. Zero means no limit.
+func (p *Pipeline) WithMaxSentences(maxSentences int) *Pipeline {
+	p.maxSentences = maxSentences
+	return p
+}
+
+// Process post-processes a complete response
+func (p *Pipeline) Process(text string) string {
+	return p.Write(text) + p.Flush()
+}
+
+// Write adds a streamed chunk and returns the processed text of every segment it completes
+func (p *Pipeline) Write(chunk string) string {
+	p.buf += chunk
+	var out strings.Builder
+	for {
+		segment, rest, endsLine, ok := nextSegment(p.buf)
+		if !ok {
+			break
+		}
+		p.buf = rest
+		out.WriteString(p.emit(segment, endsLine))
+	}
+	return out.String()
+}
+
+// Flush processes whatever is left once the stream has ended
+func (p *Pipeline) Flush() string {
+	segment := p.buf
+	p.buf = ""
+	if segment == "" {
+		return ""
+	}
+	return p.emit(segment, false)
+}
+
+// nextSegment finds the first complete segment in buf: up to a newline, or up to the
+// first sentence end at least segmentChars into the line. Cut points depend only on the
+// text before them, which keeps streamed and whole-text results identical.
+func nextSegment(buf string) (segment, rest string, endsLine, ok bool) {
+	for i := 0; i < len(buf); i++ {
+		switch {
+		case buf[i] == '\n':
+			return buf[:i], buf[i+1:], true, true
+		case i >= segmentChars && isSentenceEnd(buf[i]) && i+1 < len(buf) && buf[i+1] == ' ':
+			return buf[:i+2], buf[i+2:], false, true
+		}
+	}
+	return "", buf, false, false
+}
+
+func isSentenceEnd(c byte) bool {
+	return c == '.' || c == '!' || c == '?'
+}
+
+// emit runs one segment through the steps and the length limits
+func (p *Pipeline) emit(segment string, endsLine bool) string {
+	lineStart := p.lineStart
+	p.lineStart = endsLine
+	if p.stopped {
+		// The trailer comes after the prose, so it is still collected past the limit
+		if p.trailer != nil {
+			p.trailer.step(segment, lineStart)
+		}
+		return ""
+	}
+
+	text, keep := segment, true
+	for _, step := range p.steps {
+		if text, keep = step(text, lineStart); !keep {
+			return ""
+		}
+	}
+	if endsLine {
+		text += "\n"
+	}
+
+	if p.maxSentences > 0 {
+		ends := sentenceEnds(text)
+		if left := p.maxSentences - p.sentences; len(ends) >= left {
+			text = text[:ends[left-1]]
+			p.stopped = true
+		}
+		p.sentences += len(ends)
+	}
+	if p.maxChars > 0 {
+		n := utf8.RuneCountInString(text)
+		if p.written+n > p.maxChars {
+			text = truncate(text, p.maxChars-p.written)
+			p.stopped = true
+		}
+		p.written += utf8.RuneCountInString(text)
+	}
+	return text
+}
+
+// sentenceEnds returns the offset just past each sentence that ends in text: a run of
+// '.', '!' or '?', with any closing quotes or brackets, followed by whitespace or the end
+// of the text. Segments end at a newline or after a sentence end's space, so the end of a
+// segment is the end of a sentence too.
+func sentenceEnds(text string) []int {
+	var ends []int
+	for i := 0; i < len(text); i++ {
+		if !isSentenceEnd(text[i]) {
+			continue
+		}
+		j := i + 1
+		for j < len(text) {
+			if isSentenceEnd(text[j]) {
+				j++
+			} else if n := closerLen(text[j:]); n > 0 {
+				j += n
+			} else {
+				break
+			}
+		}
+		if j == len(text) || text[j] == ' ' || text[j] == '\n' || text[j] == '\t' {
+			ends = append(ends, j)
+		}
+		i = j - 1
+	}
+	return ends
+}
+
+// closers are the quotes and brackets that may close a sentence after its punctuation
+var closers = []string{`"`, "'", ")", "]", "*", "\u201d", "\u2019"}
+
+// closerLen returns the length in bytes of the closer at the start of s, or 0 if none
+func closerLen(s string) int {
+	for _, c := range closers {
+		if strings.HasPrefix(s, c) {
+			return len(c)
+		}
+	}
+	return 0
+}
+
+// truncate shortens text to at most maxChars characters, preferring to end at a
+// sentence, then at a word
+func truncate(text string, maxChars int) string {
+	if maxChars <= 0 {
+		return ""
+	}
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	cut := string(runes[:maxChars])
+	if i := strings.LastIndexAny(cut, ".!?"); i > 0 {
+		return cut[:i+1]
+	}
+	if i := strings.LastIndexAny(cut, " \n"); i > 0 {
+		return strings.TrimRight(cut[:i], " \n")
+	}
+	return cut
+}
@@ -0,0 +1,202 @@
+package textfilter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNarrationPipeline_Process(t *testing.T) {
+	filter := NewProfanityFilter()
+
+	tests := []struct {
+		name     string
+		input    string
+		rating   string
+		maxChars int
+		expected string
+	}{
+		{
+			name:     "plain narration unchanged",
+			input:    "The tide rolls in.\n\nGibbs: Aye, captain.",
+			rating:   "R",
+			expected: "The tide rolls in.\n\nGibbs: Aye, captain.",
+		},
+		{
+			name:     "echoed prompt block removed",
+			input:    "The door creaks.\n<world_state>\n{\"location\": \"dock\"}\n</world_state>\nA gull cries.",
+			rating:   "R",
+			expected: "The door creaks.\nA gull cries.",
+		},
+		{
+			name:     "inline prompt tag removed",
+			input:    "You stand on the dock. <current_location>Dock</current_location>",
+			rating:   "R",
+			expected: "You stand on the dock. ",
+		},
+		{
+			name:     "OOC line and aside removed",
+			input:    "The lantern flickers.\n(OOC: I should describe the room.)\nShadows dance [OOC: spooky] on the wall.",
+			rating:   "R",
+			expected: "The lantern flickers.\nShadows dance on the wall.",
+		},
+		{
+			name:     "speaker prefixes normalized",
+			input:    "**Gibbs:** Aye.\n*Anne*: No.\nBosun : Quiet!",
+			rating:   "R",
+			expected: "Gibbs: Aye.\nAnne: No.\nBosun: Quiet!",
+		},
+		{
+			name:     "profanity filtered by rating",
+			input:    "Gibbs: What the hell?",
+			rating:   "G",
+			expected: "Gibbs: What the heck?",
+		},
+		{
+			name:     "cut at sentence before max length",
+			input:    "The ship lists. Water pours in. Everyone runs.",
+			rating:   "R",
+			maxChars: 35,
+			expected: "The ship lists. Water pours in.",
+		},
+		{
+			name:     "cut at word without sentence end",
+			input:    "the ship lists and water pours in",
+			rating:   "R",
+			maxChars: 20,
+			expected: "the ship lists and",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NarrationPipeline(filter, tt.rating, tt.maxChars).Process(tt.input)
+			if got != tt.expected {
+				t.Errorf("Process() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPipeline_StreamMatchesProcess(t *testing.T) {
+	filter := NewProfanityFilter()
+	long := strings.Repeat("The crew hauls on the lines and sings of home. ", 8)
+	input := "**Gibbs:** Damn the weather.\n<rules>\nStay in character.\n</rules>\n" +
+		long + "\n(Note: the storm is coming.)\nAnne : Hold fast! [OOC: tension rising] The mast groans."
+
+	for _, limit := range []struct{ maxChars, maxSentences int }{{0, 0}, {300, 0}, {0, 6}} {
+		maxChars := limit.maxChars
+		want := NarrationPipeline(filter, "PG", maxChars).WithMaxSentences(limit.maxSentences).Process(input)
+		for _, size := range []int{1, 3, 7, 16, 64, len(input)} {
+			p := NarrationPipeline(filter, "PG", maxChars).WithMaxSentences(limit.maxSentences)
+			var got strings.Builder
+			for i := 0; i < len(input); i += size {
+				got.WriteString(p.Write(input[i:min(i+size, len(input))]))
+			}
+			got.WriteString(p.Flush())
+			if got.String() != want {
+				t.Errorf("max %d chars %d sentences, chunk size %d: streamed %q, want %q", maxChars, limit.maxSentences, size, got.String(), want)
+			}
+		}
+	}
+}
+
+func TestPipeline_WithMaxSentences(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		maxSentences int
+		expected     string
+	}{
+		{
+			name:     "no limit",
+			input:    "The ship lists. Water pours in. Everyone runs.",
+			expected: "The ship lists. Water pours in. Everyone runs.",
+		},
+		{
+			name:         "cut after the last allowed sentence",
+			input:        "The ship lists. Water pours in! Everyone runs?",
+			maxSentences: 2,
+			expected:     "The ship lists. Water pours in!",
+		},
+		{
+			name:         "sentences counted across lines",
+			input:        "The ship lists.\n\nGibbs: \"Abandon ship!\" Everyone runs.",
+			maxSentences: 2,
+			expected:     "The ship lists.\n\nGibbs: \"Abandon ship!\"",
+		},
+		{
+			name:         "ellipsis and decimals are one sentence",
+			input:        "The map is 1.5 leagues wide... Gibbs frowns. Anne laughs.",
+			maxSentences: 2,
+			expected:     "The map is 1.5 leagues wide... Gibbs frowns.",
+		},
+		{
+			name:         "fewer sentences than the limit",
+			input:        "The ship lists",
+			maxSentences: 3,
+			expected:     "The ship lists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewPipeline().WithMaxSentences(tt.maxSentences).Process(tt.input)
+			if got != tt.expected {
+				t.Errorf("Process() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPipeline_WriteReleasesCompleteLines(t *testing.T) {
+	p := NewPipeline(StripMeta())
+	if got := p.Write("The fog lifts"); got != "" {
+		t.Errorf("Write() before newline = %q, want it held back", got)
+	}
+	if got := p.Write(".\nA bell"); got != "The fog lifts.\n" {
+		t.Errorf("Write() = %q, want first line", got)
+	}
+	if got := p.Flush(); got != "A bell" {
+		t.Errorf("Flush() = %q, want remainder", got)
+	}
+}
+
+func TestPipeline_WithTrailer(t *testing.T) {
+	filter := NewProfanityFilter()
+	trailer := `{"mood": "tense", "npcs_present": ["Gibbs"]}`
+	tests := []struct {
+		name      string
+		input     string
+		maxChars  int
+		wantText  string
+		wantMeta  string
+		chunkSize int
+	}{
+		{"block on its own lines", "The storm breaks.\n<turn_meta>\n" + trailer + "\n</turn_meta>", 0, "The storm breaks.\n", trailer, 0},
+		{"inline block", "The storm breaks. <turn_meta>" + trailer + "</turn_meta>", 0, "The storm breaks. ", trailer, 0},
+		{"streamed", "The storm breaks.\n<turn_meta>\n" + trailer + "\n</turn_meta>", 0, "The storm breaks.\n", trailer, 5},
+		{"past the length limit", "The storm breaks. The mast groans.\n<turn_meta>" + trailer + "</turn_meta>", 20, "The storm breaks.", trailer, 0},
+		{"no trailer", "The storm breaks.", 0, "The storm breaks.", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NarrationPipeline(filter, "PG", tt.maxChars).WithTrailer(TrailerTag)
+			var got string
+			if tt.chunkSize == 0 {
+				got = p.Process(tt.input)
+			} else {
+				for i := 0; i < len(tt.input); i += tt.chunkSize {
+					got += p.Write(tt.input[i:min(i+tt.chunkSize, len(tt.input))])
+				}
+				got += p.Flush()
+			}
+			if got != tt.wantText {
+				t.Errorf("Expected narration %q, got %q", tt.wantText, got)
+			}
+			if p.Trailer() != tt.wantMeta {
+				t.Errorf("Expected trailer %q, got %q", tt.wantMeta, p.Trailer())
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package textfilter
+
+import (
+	"slices"
+	"strings"
+)
+
+// ratingNames are the content ratings by strictness level, strictest first. R, past the
+// last level, filters nothing.
+var ratingNames = []string{"G", "PG", "PG13", "R"}
+
+// FlaggedWord is a listed word found in a text
+type FlaggedWord struct {
+	Word      string // The word as listed
+	MinRating string // Strictest rating that leaves the word unfiltered: "PG", "PG13", or "R"
+}
+
+// Flagged returns the listed words found in text that some rating filters, sorted by word
+func (pf *ProfanityFilter) Flagged(text string) []FlaggedWord {
+	var flagged []FlaggedWord
+	for _, w := range pf.activeWords() {
+		if w.maxLevel >= 0 && pf.replace(text, w) != text {
+			flagged = append(flagged, FlaggedWord{Word: w.word, MinRating: ratingNames[w.maxLevel+1]})
+		}
+	}
+	slices.SortFunc(flagged, func(a, b FlaggedWord) int { return strings.Compare(a.Word, b.Word) })
+	return flagged
+}
+
+// MinRating returns the strictest rating at which text needs no filtering: "G", "PG",
+// "PG13", or "R"
+func (pf *ProfanityFilter) MinRating(text string) string {
+	level := 0
+	for _, f := range pf.Flagged(text) {
+		level = max(level, slices.Index(ratingNames, f.MinRating))
+	}
+	return ratingNames[level]
+}
@@ -0,0 +1,39 @@
+package textfilter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProfanityFilter_Flagged(t *testing.T) {
+	filter := NewProfanityFilter().WithLeetspeak(true)
+	if err := filter.AddWords("en", "G", map[string]string{"blimey": ""}); err != nil {
+		t.Fatalf("AddWords() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    []FlaggedWord
+		wantMin string
+	}{
+		{"clean", "The tide rolls in.", nil, "G"},
+		{"G-only word", "Blimey, a ship!", []FlaggedWord{{"blimey", "PG"}}, "PG"},
+		{"PG13-allowed word", "Damn the torpedoes.", []FlaggedWord{{"damn", "PG13"}}, "PG13"},
+		{"mature word", "Oh, shit.", []FlaggedWord{{"shit", "R"}}, "R"},
+		{"leetspeak", "Oh, sh1t.", []FlaggedWord{{"shit", "R"}}, "R"},
+		{"several, sorted", "Damn it, blimey, shit.", []FlaggedWord{{"blimey", "PG"}, {"damn", "PG13"}, {"shit", "R"}}, "R"},
+		{"whole words only", "Hello, Shell.", nil, "G"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Flagged(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Flagged() = %v, want %v", got, tt.want)
+			}
+			if got := filter.MinRating(tt.text); got != tt.wantMin {
+				t.Errorf("MinRating() = %q, want %q", got, tt.wantMin)
+			}
+		})
+	}
+}
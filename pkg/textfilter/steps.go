@@ -0,0 +1,130 @@
+package textfilter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// promptTags are the XML-style tags the engine uses to structure prompts. Narrators
+// occasionally echo them, along with their contents.
+var promptTags = []string{
+	"rules", "world_state", "world_state_rules", "current_location",
+	"adjacent_previews", "npcs_elsewhere", "user_inventory", "just_entered", "lore",
+}
+
+var (
+	// promptElement matches a complete prompt tag element within one segment
+	promptElement = map[string]*regexp.Regexp{}
+	// promptOpen matches the opening tag of an element that continues past the segment
+	promptOpen = map[string]*regexp.Regexp{}
+	// promptClose matches a closing tag
+	promptClose = map[string]*regexp.Regexp{}
+)
+
+func init() {
+	for _, tag := range promptTags {
+		promptElement[tag] = regexp.MustCompile(`(?is)<` + tag + `\b[^>]*>.*?</` + tag + `>`)
+		promptOpen[tag] = regexp.MustCompile(`(?i)<` + tag + `\b[^>]*>`)
+		promptClose[tag] = regexp.MustCompile(`(?i)</` + tag + `>`)
+	}
+}
+
+// StripPromptMarkers removes prompt tags the narrator echoed, such as <rules> or
+// <world_state>, together with everything between the opening and closing tag, even
+// across lines. Segments left empty are dropped.
+func StripPromptMarkers() Step {
+	inside := "" // tag whose element is still open from an earlier segment
+	return func(segment string, _ bool) (string, bool) {
+		text := segment
+		if inside != "" {
+			loc := promptClose[inside].FindStringIndex(text)
+			if loc == nil {
+				return "", false
+			}
+			text = text[loc[1]:]
+			inside = ""
+		}
+
+		for _, tag := range promptTags {
+			text = promptElement[tag].ReplaceAllString(text, "")
+		}
+		for _, tag := range promptTags {
+			if loc := promptOpen[tag].FindStringIndex(text); loc != nil {
+				text = text[:loc[0]]
+				inside = tag
+				break
+			}
+		}
+		for _, tag := range promptTags {
+			text = promptClose[tag].ReplaceAllString(text, "")
+		}
+
+		if text != segment && strings.TrimSpace(text) == "" {
+			return "", false
+		}
+		return text, true
+	}
+}
+
+var (
+	// metaLine matches a whole line of out-of-character commentary
+	metaLine = regexp.MustCompile(`(?i)^\s*(?:[(\[]\s*(?:ooc|out of character|note|author'?s note|narrator'?s note)\b.*[)\]]|(?:ooc|out of character)\s*:.*|as an ai\b.*|as a language model\b.*)\s*$`)
+	// metaInline matches bracketed out-of-character asides within a line
+	metaInline = regexp.MustCompile(`(?i)\s*[(\[]\s*(?:ooc|out of character)\s*:[^)\]]*[)\]]`)
+)
+
+// StripMeta removes out-of-character commentary: lines such as "(OOC: ...)",
+// "[Note: ...]", or "As an AI ...", and bracketed OOC asides within a line.
+func StripMeta() Step {
+	return func(segment string, lineStart bool) (string, bool) {
+		if lineStart && metaLine.MatchString(segment) {
+			return "", false
+		}
+		text := metaInline.ReplaceAllString(segment, "")
+		if text != segment && strings.TrimSpace(text) == "" {
+			return "", false
+		}
+		return text, true
+	}
+}
+
+var (
+	// markdownSpeaker matches a bold or italic speaker prefix: "**Gibbs:**", "**Gibbs**:", "*Gibbs:*"
+	markdownSpeaker = regexp.MustCompile(`^(\s*)\*{1,2}([A-Z][\w'’.\- ]{0,30}?)\s*(?::\*{1,2}|\*{1,2}\s*:)\s*`)
+	// spacedSpeaker matches a speaker prefix with a space before the colon: "Gibbs :"
+	spacedSpeaker = regexp.MustCompile(`^(\s*)([A-Z][\w'’.\-]*(?: [A-Z][\w'’.\-]*)?)\s+:\s*`)
+)
+
+// NormalizeSpeakers rewrites speaker prefixes at the start of a line to the plain
+// "Name: " form, e.g. "**Gibbs:**" or "Gibbs :" become "Gibbs: ".
+func NormalizeSpeakers() Step {
+	return func(segment string, lineStart bool) (string, bool) {
+		if !lineStart {
+			return segment, true
+		}
+		if markdownSpeaker.MatchString(segment) {
+			return markdownSpeaker.ReplaceAllString(segment, "$1$2: "), true
+		}
+		return spacedSpeaker.ReplaceAllString(segment, "$1$2: "), true
+	}
+}
+
+// FilterProfanity replaces profanity according to the content rating; see FilterText
+func FilterProfanity(filter *ProfanityFilter, contentRating string) Step {
+	return func(segment string, _ bool) (string, bool) {
+		return filter.FilterText(segment, contentRating), true
+	}
+}
+
+// NarrationPipeline returns the standard post-processing for narration: prompt markers
+// and out-of-character commentary are removed, speaker prefixes normalized, and
+// profanity filtered for the content rating. maxChars limits the length; zero means
+// no limit.
+func NarrationPipeline(filter *ProfanityFilter, contentRating string, maxChars int) *Pipeline {
+	return NewPipeline(
+		StripPromptMarkers(),
+		StripMeta(),
+		NormalizeSpeakers(),
+		FilterProfanity(filter, contentRating),
+	).WithMaxLength(maxChars)
+}
@@ -0,0 +1,76 @@
+package textfilter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TrailerTag wraps the structured block a narrator appends after its prose when asked to
+const TrailerTag = "turn_meta"
+
+// trailer removes a tagged block from narration, across segments, and keeps its contents
+type trailer struct {
+	open   *regexp.Regexp
+	close  *regexp.Regexp
+	inside bool
+	text   strings.Builder
+}
+
+func newTrailer(tag string) *trailer {
+	return &trailer{
+		open:  regexp.MustCompile(`(?i)<` + regexp.QuoteMeta(tag) + `\b[^>]*>`),
+		close: regexp.MustCompile(`(?i)</` + regexp.QuoteMeta(tag) + `>`),
+	}
+}
+
+// step keeps the text outside the block and collects the text inside it. Segments left
+// empty are dropped.
+func (t *trailer) step(segment string, _ bool) (string, bool) {
+	var kept strings.Builder
+	rest := segment
+	for rest != "" {
+		if t.inside {
+			loc := t.close.FindStringIndex(rest)
+			if loc == nil {
+				t.text.WriteString(rest + "\n")
+				rest = ""
+				break
+			}
+			t.text.WriteString(rest[:loc[0]])
+			rest = rest[loc[1]:]
+			t.inside = false
+			continue
+		}
+		loc := t.open.FindStringIndex(rest)
+		if loc == nil {
+			kept.WriteString(rest)
+			break
+		}
+		kept.WriteString(rest[:loc[0]])
+		rest = rest[loc[1]:]
+		t.inside = true
+	}
+
+	text := kept.String()
+	if text != segment && strings.TrimSpace(text) == "" {
+		return "", false
+	}
+	return text, true
+}
+
+// WithTrailer removes the narrator's <tag> block from the narration before the other
+// steps run, keeping its contents for Trailer
+func (p *Pipeline) WithTrailer(tag string) *Pipeline {
+	p.trailer = newTrailer(tag)
+	p.steps = append([]Step{p.trailer.step}, p.steps...)
+	return p
+}
+
+// Trailer returns the contents of the block removed by WithTrailer, once the narration
+// has been processed. Empty if there was none.
+func (p *Pipeline) Trailer() string {
+	if p.trailer == nil {
+		return ""
+	}
+	return strings.TrimSpace(p.trailer.text.String())
+}